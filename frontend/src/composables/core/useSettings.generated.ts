@@ -49,6 +49,7 @@ export function generateInitialSettings(): SettingsData {
     deepl_api_key: settingsDefaults.deepl_api_key,
     deepl_endpoint: settingsDefaults.deepl_endpoint,
     default_view_mode: settingsDefaults.default_view_mode,
+    feed_auto_rediscover: settingsDefaults.feed_auto_rediscover,
     feed_drawer_expanded: settingsDefaults.feed_drawer_expanded,
     feed_drawer_pinned: settingsDefaults.feed_drawer_pinned,
     freshrss_api_password: settingsDefaults.freshrss_api_password,
@@ -60,6 +61,7 @@ export function generateInitialSettings(): SettingsData {
     freshrss_username: settingsDefaults.freshrss_username,
     full_text_fetch_enabled: settingsDefaults.full_text_fetch_enabled,
     google_translate_endpoint: settingsDefaults.google_translate_endpoint,
+    host_refresh_spacing_ms: settingsDefaults.host_refresh_spacing_ms,
     hover_mark_as_read: settingsDefaults.hover_mark_as_read,
     idle_conn_timeout_seconds: settingsDefaults.idle_conn_timeout_seconds,
     image_gallery_enabled: settingsDefaults.image_gallery_enabled,
@@ -70,6 +72,7 @@ export function generateInitialSettings(): SettingsData {
     max_article_age_days: settingsDefaults.max_article_age_days,
     max_cache_size_mb: settingsDefaults.max_cache_size_mb,
     max_concurrent_refreshes: settingsDefaults.max_concurrent_refreshes,
+    max_concurrent_refreshes_per_host: settingsDefaults.max_concurrent_refreshes_per_host,
     max_conns_per_host: settingsDefaults.max_conns_per_host,
     max_idle_conns: settingsDefaults.max_idle_conns,
     max_idle_conns_per_host: settingsDefaults.max_idle_conns_per_host,
@@ -94,6 +97,7 @@ export function generateInitialSettings(): SettingsData {
     proxy_type: settingsDefaults.proxy_type,
     proxy_username: settingsDefaults.proxy_username,
     refresh_mode: settingsDefaults.refresh_mode,
+    respect_robots_txt: settingsDefaults.respect_robots_txt,
     retry_timeout_seconds: settingsDefaults.retry_timeout_seconds,
     rsshub_api_key: settingsDefaults.rsshub_api_key,
     rsshub_enabled: settingsDefaults.rsshub_enabled,
@@ -164,6 +168,7 @@ export function parseSettingsData(data: Record<string, string>): SettingsData {
     deepl_api_key: data.deepl_api_key || settingsDefaults.deepl_api_key,
     deepl_endpoint: data.deepl_endpoint || settingsDefaults.deepl_endpoint,
     default_view_mode: data.default_view_mode || settingsDefaults.default_view_mode,
+    feed_auto_rediscover: data.feed_auto_rediscover === 'true',
     feed_drawer_expanded: data.feed_drawer_expanded === 'true',
     feed_drawer_pinned: data.feed_drawer_pinned === 'true',
     freshrss_api_password: data.freshrss_api_password || settingsDefaults.freshrss_api_password,
@@ -175,6 +180,7 @@ export function parseSettingsData(data: Record<string, string>): SettingsData {
     freshrss_username: data.freshrss_username || settingsDefaults.freshrss_username,
     full_text_fetch_enabled: data.full_text_fetch_enabled === 'true',
     google_translate_endpoint: data.google_translate_endpoint || settingsDefaults.google_translate_endpoint,
+    host_refresh_spacing_ms: data.host_refresh_spacing_ms || settingsDefaults.host_refresh_spacing_ms,
     hover_mark_as_read: data.hover_mark_as_read === 'true',
     idle_conn_timeout_seconds: parseInt(data.idle_conn_timeout_seconds) || settingsDefaults.idle_conn_timeout_seconds,
     image_gallery_enabled: data.image_gallery_enabled === 'true',
@@ -185,6 +191,7 @@ export function parseSettingsData(data: Record<string, string>): SettingsData {
     max_article_age_days: parseInt(data.max_article_age_days) || settingsDefaults.max_article_age_days,
     max_cache_size_mb: parseInt(data.max_cache_size_mb) || settingsDefaults.max_cache_size_mb,
     max_concurrent_refreshes: data.max_concurrent_refreshes || settingsDefaults.max_concurrent_refreshes,
+    max_concurrent_refreshes_per_host: data.max_concurrent_refreshes_per_host || settingsDefaults.max_concurrent_refreshes_per_host,
     max_conns_per_host: parseInt(data.max_conns_per_host) || settingsDefaults.max_conns_per_host,
     max_idle_conns: parseInt(data.max_idle_conns) || settingsDefaults.max_idle_conns,
     max_idle_conns_per_host: parseInt(data.max_idle_conns_per_host) || settingsDefaults.max_idle_conns_per_host,
@@ -209,6 +216,7 @@ export function parseSettingsData(data: Record<string, string>): SettingsData {
     proxy_type: data.proxy_type || settingsDefaults.proxy_type,
     proxy_username: data.proxy_username || settingsDefaults.proxy_username,
     refresh_mode: data.refresh_mode || settingsDefaults.refresh_mode,
+    respect_robots_txt: data.respect_robots_txt === 'true',
     retry_timeout_seconds: parseInt(data.retry_timeout_seconds) || settingsDefaults.retry_timeout_seconds,
     rsshub_api_key: data.rsshub_api_key || settingsDefaults.rsshub_api_key,
     rsshub_enabled: data.rsshub_enabled === 'true',
@@ -279,6 +287,7 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     deepl_api_key: settingsRef.value.deepl_api_key ?? settingsDefaults.deepl_api_key,
     deepl_endpoint: settingsRef.value.deepl_endpoint ?? settingsDefaults.deepl_endpoint,
     default_view_mode: settingsRef.value.default_view_mode ?? settingsDefaults.default_view_mode,
+    feed_auto_rediscover: (settingsRef.value.feed_auto_rediscover ?? settingsDefaults.feed_auto_rediscover).toString(),
     freshrss_api_password: settingsRef.value.freshrss_api_password ?? settingsDefaults.freshrss_api_password,
     freshrss_auto_sync_interval: (settingsRef.value.freshrss_auto_sync_interval ?? settingsDefaults.freshrss_auto_sync_interval).toString(),
     freshrss_enabled: (settingsRef.value.freshrss_enabled ?? settingsDefaults.freshrss_enabled).toString(),
@@ -288,6 +297,7 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     freshrss_username: settingsRef.value.freshrss_username ?? settingsDefaults.freshrss_username,
     full_text_fetch_enabled: (settingsRef.value.full_text_fetch_enabled ?? settingsDefaults.full_text_fetch_enabled).toString(),
     google_translate_endpoint: settingsRef.value.google_translate_endpoint ?? settingsDefaults.google_translate_endpoint,
+    host_refresh_spacing_ms: settingsRef.value.host_refresh_spacing_ms ?? settingsDefaults.host_refresh_spacing_ms,
     hover_mark_as_read: (settingsRef.value.hover_mark_as_read ?? settingsDefaults.hover_mark_as_read).toString(),
     idle_conn_timeout_seconds: (settingsRef.value.idle_conn_timeout_seconds ?? settingsDefaults.idle_conn_timeout_seconds).toString(),
     image_gallery_enabled: (settingsRef.value.image_gallery_enabled ?? settingsDefaults.image_gallery_enabled).toString(),
@@ -297,6 +307,7 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     max_article_age_days: (settingsRef.value.max_article_age_days ?? settingsDefaults.max_article_age_days).toString(),
     max_cache_size_mb: (settingsRef.value.max_cache_size_mb ?? settingsDefaults.max_cache_size_mb).toString(),
     max_concurrent_refreshes: settingsRef.value.max_concurrent_refreshes ?? settingsDefaults.max_concurrent_refreshes,
+    max_concurrent_refreshes_per_host: settingsRef.value.max_concurrent_refreshes_per_host ?? settingsDefaults.max_concurrent_refreshes_per_host,
     max_conns_per_host: (settingsRef.value.max_conns_per_host ?? settingsDefaults.max_conns_per_host).toString(),
     max_idle_conns: (settingsRef.value.max_idle_conns ?? settingsDefaults.max_idle_conns).toString(),
     max_idle_conns_per_host: (settingsRef.value.max_idle_conns_per_host ?? settingsDefaults.max_idle_conns_per_host).toString(),
@@ -321,6 +332,7 @@ export function buildAutoSavePayload(settingsRef: Ref<SettingsData>): Record<str
     proxy_type: settingsRef.value.proxy_type ?? settingsDefaults.proxy_type,
     proxy_username: settingsRef.value.proxy_username ?? settingsDefaults.proxy_username,
     refresh_mode: settingsRef.value.refresh_mode ?? settingsDefaults.refresh_mode,
+    respect_robots_txt: (settingsRef.value.respect_robots_txt ?? settingsDefaults.respect_robots_txt).toString(),
     retry_timeout_seconds: (settingsRef.value.retry_timeout_seconds ?? settingsDefaults.retry_timeout_seconds).toString(),
     rsshub_api_key: settingsRef.value.rsshub_api_key ?? settingsDefaults.rsshub_api_key,
     rsshub_enabled: (settingsRef.value.rsshub_enabled ?? settingsDefaults.rsshub_enabled).toString(),
@@ -41,6 +41,7 @@ export interface SettingsData {
   deepl_api_key: string;
   deepl_endpoint: string;
   default_view_mode: string;
+  feed_auto_rediscover: boolean;
   feed_drawer_expanded: boolean;
   feed_drawer_pinned: boolean;
   freshrss_api_password: string;
@@ -52,6 +53,7 @@ export interface SettingsData {
   freshrss_username: string;
   full_text_fetch_enabled: boolean;
   google_translate_endpoint: string;
+  host_refresh_spacing_ms: string;
   hover_mark_as_read: boolean;
   idle_conn_timeout_seconds: number;
   image_gallery_enabled: boolean;
@@ -62,6 +64,7 @@ export interface SettingsData {
   max_article_age_days: number;
   max_cache_size_mb: number;
   max_concurrent_refreshes: string;
+  max_concurrent_refreshes_per_host: string;
   max_conns_per_host: number;
   max_idle_conns: number;
   max_idle_conns_per_host: number;
@@ -86,6 +89,7 @@ export interface SettingsData {
   proxy_type: string;
   proxy_username: string;
   refresh_mode: string;
+  respect_robots_txt: boolean;
   retry_timeout_seconds: number;
   rsshub_api_key: string;
   rsshub_enabled: boolean;
@@ -14,6 +14,8 @@ import (
 type PriorityLevel int
 
 const (
+	// PriorityBackground is for automated requests, such as rule actions, that yield to all others
+	PriorityBackground PriorityLevel = -1
 	// PriorityNormal is the default priority for background requests
 	PriorityNormal PriorityLevel = 0
 	// PriorityHigh is for user-initiated requests on selected articles
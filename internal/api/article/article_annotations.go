@@ -0,0 +1,195 @@
+package article
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+const (
+	defaultHighlightsLimit = 50
+	maxHighlightsLimit     = 500
+)
+
+// HandleArticleTags gets or replaces the tags of an article.
+// @Summary      Article tags
+// @Description  GET returns the tags of an article (?article_id=). PUT replaces them with the given tag IDs.
+// @Tags         articles
+// @Accept       json
+// @Produce      json
+// @Param        article_id  query     int     false  "Article ID (GET)"
+// @Param        request     body      object  false  "Article ID and tag IDs (PUT): {\"article_id\": 1, \"tag_ids\": [1, 2]}"
+// @Success      200  {array}   models.Tag  "Tags of the article"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      404  {object}  map[string]string  "Article not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/tags [get]
+func HandleArticleTags(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	userID, ok := core.GetUserIDFromRequest(r)
+	if !ok {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+
+	var articleID int64
+	switch r.Method {
+	case http.MethodGet:
+		id, err := strconv.ParseInt(r.URL.Query().Get("article_id"), 10, 64)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		articleID = id
+
+	case http.MethodPut:
+		var req struct {
+			ArticleID int64   `json:"article_id"`
+			TagIDs    []int64 `json:"tag_ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ArticleID <= 0 {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := h.DB.SetArticleTagsForUser(userID, req.ArticleID, req.TagIDs); errors.Is(err, sqlite.ErrArticleNotFound) {
+			response.Error(w, err, http.StatusNotFound)
+			return
+		} else if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		articleID = req.ArticleID
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	tags, err := h.DB.GetArticleTagsForUser(userID, articleID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, tags)
+}
+
+// HandleArticleHighlights manages highlights and their notes.
+// @Summary      Article highlights
+// @Description  GET returns the highlights of an article (?article_id=) or all highlights, newest first (?limit=&offset=).
+// @Description  POST creates a highlight, PUT updates its color and note (?id=), DELETE removes it (?id=).
+// @Tags         articles
+// @Accept       json
+// @Produce      json
+// @Param        article_id  query     int                      false  "Article ID (GET)"
+// @Param        id          query     int                      false  "Highlight ID (PUT, DELETE)"
+// @Param        limit       query     int                      false  "Maximum highlights when listing all (default 50, max 500)"
+// @Param        offset      query     int                      false  "Offset when listing all"
+// @Param        request     body      models.ArticleHighlight  false  "Highlight (POST, PUT)"
+// @Success      200  {array}   models.ArticleHighlight  "Highlights"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      404  {object}  map[string]string  "Article or highlight not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/highlights [get]
+func HandleArticleHighlights(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	userID, ok := core.GetUserIDFromRequest(r)
+	if !ok {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		var highlights []models.ArticleHighlight
+		var err error
+		if idStr := r.URL.Query().Get("article_id"); idStr != "" {
+			articleID, parseErr := strconv.ParseInt(idStr, 10, 64)
+			if parseErr != nil {
+				response.Error(w, parseErr, http.StatusBadRequest)
+				return
+			}
+			highlights, err = h.DB.GetArticleHighlights(userID, articleID)
+		} else {
+			limit := defaultHighlightsLimit
+			if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+				limit = l
+			}
+			if limit > maxHighlightsLimit {
+				limit = maxHighlightsLimit
+			}
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			if offset < 0 {
+				offset = 0
+			}
+			highlights, err = h.DB.GetHighlightsForUser(userID, limit, offset)
+		}
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, highlights)
+
+	case http.MethodPost:
+		var hl models.ArticleHighlight
+		if err := json.NewDecoder(r.Body).Decode(&hl); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		hl.Text = strings.TrimSpace(hl.Text)
+		if hl.ArticleID <= 0 || hl.Text == "" || hl.StartOffset < 0 || hl.EndOffset < hl.StartOffset {
+			response.Error(w, errors.New("article_id, text and a valid offset range are required"), http.StatusBadRequest)
+			return
+		}
+		hl.UserID = userID
+		id, err := h.DB.CreateHighlight(&hl)
+		if errors.Is(err, sqlite.ErrArticleNotFound) {
+			response.Error(w, err, http.StatusNotFound)
+			return
+		} else if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		hl.ID = id
+		response.JSON(w, hl)
+
+	case http.MethodPut, http.MethodDelete:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+
+		if r.Method == http.MethodPut {
+			var req struct {
+				Color string `json:"color"`
+				Note  string `json:"note"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				response.Error(w, err, http.StatusBadRequest)
+				return
+			}
+			err = h.DB.UpdateHighlight(userID, id, req.Color, req.Note)
+		} else {
+			err = h.DB.DeleteHighlight(userID, id)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, errors.New("highlight not found"), http.StatusNotFound)
+			return
+		} else if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, map[string]bool{"success": true})
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
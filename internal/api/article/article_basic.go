@@ -22,6 +22,7 @@ import (
 // @Param        category  query     string  false  "Filter by category name"
 // @Param        page      query     int     false  "Page number (default: 1)"  minimum(1)
 // @Param        limit     query     int     false  "Items per page (default: 50, max: 500)"  minimum(1)  maximum(500)
+// @Param        collapse_duplicates  query  bool  false  "Show one article per near-duplicate cluster"
 // @Success      200  {array}   models.Article  "List of articles"
 // @Failure      500  {object}  map[string]string  "Internal server error"
 // @Router       /articles [get]
@@ -70,7 +71,9 @@ func HandleArticles(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 	showHiddenStr, _ := h.DB.GetSettingForUser(userID, "show_hidden_articles")
 	showHidden := showHiddenStr == "true"
 
-	articles, err := h.DB.GetArticlesForUser(userID, filter, feedID, category, showHidden, limit, offset)
+	collapseDuplicates := r.URL.Query().Get("collapse_duplicates") == "true"
+
+	articles, err := h.DB.GetArticlesForUser(userID, filter, feedID, category, showHidden, collapseDuplicates, limit, offset)
 	if err != nil {
 		response.Error(w, err, http.StatusInternalServerError)
 		return
@@ -267,3 +270,35 @@ func HandleImageGalleryArticles(h *core.Handler, w http.ResponseWriter, r *http.
 	}
 	response.JSON(w, articles)
 }
+
+// HandleArticleDuplicates returns the near-duplicates of an article from other feeds or outlets.
+// @Summary      Get near-duplicate articles
+// @Description  List the other articles in the near-duplicate cluster of an article, oldest first
+// @Tags         articles
+// @Produce      json
+// @Param        id   query     int64   true  "Article ID"
+// @Success      200  {array}   models.Article  "Near-duplicate articles"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/duplicates [get]
+func HandleArticleDuplicates(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	userID, ok := core.GetUserIDFromRequest(r)
+	if !ok {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	articles, err := h.DB.GetClusterArticlesForUser(userID, id)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, articles)
+}
@@ -179,7 +179,7 @@ func HandleMarkAllAsRead(h *core.Handler, w http.ResponseWriter, r *http.Request
 
 	// Perform immediate sync to FreshRSS if needed
 	if len(syncReqs) > 0 {
-		go performImmediateBulkSync(h, syncReqs)
+		go PerformImmediateBulkSync(h, syncReqs)
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -523,7 +523,7 @@ func HandleMarkRelativeToArticle(h *core.Handler, w http.ResponseWriter, r *http
 
 	// Perform immediate sync to FreshRSS if needed
 	if len(syncReqs) > 0 {
-		go performImmediateBulkSync(h, syncReqs)
+		go PerformImmediateBulkSync(h, syncReqs)
 	}
 
 	response.JSON(w, map[string]interface{}{
@@ -532,8 +532,8 @@ func HandleMarkRelativeToArticle(h *core.Handler, w http.ResponseWriter, r *http
 	})
 }
 
-// performImmediateBulkSync performs immediate sync for multiple articles to FreshRSS in a background goroutine
-func performImmediateBulkSync(h *core.Handler, syncReqs []sqlite.SyncRequest) {
+// PerformImmediateBulkSync performs immediate sync for multiple articles to FreshRSS in a background goroutine
+func PerformImmediateBulkSync(h *core.Handler, syncReqs []sqlite.SyncRequest) {
 	// Check if FreshRSS is enabled and configured
 	enabled, _ := h.DB.GetSetting("freshrss_enabled")
 	if enabled != "true" {
@@ -1,6 +1,7 @@
 package article
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
@@ -123,8 +124,9 @@ func HandleFetchFullArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Fetch full content
-	fullContent, err := h.FetchFullArticleContent(article.URL)
+	// Fetch full content with the feed's credentials
+	feed, _ := h.DB.GetFeedByID(article.FeedID)
+	fullContent, err := h.FetchFullArticleContentForFeed(article.URL, feed)
 	if err != nil {
 		log.Printf("Error fetching full article content: %v", err)
 		response.Error(w, err, http.StatusInternalServerError)
@@ -132,9 +134,8 @@ func HandleFetchFullArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 	}
 
 	// Get feed URL to use as referer for image proxying
-	feed, err := h.DB.GetFeedByID(article.FeedID)
 	var feedURL string
-	if err == nil && feed != nil {
+	if feed != nil {
 		feedURL = feed.URL
 	}
 
@@ -144,6 +145,33 @@ func HandleFetchFullArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 	})
 }
 
+// FetchFullText fetches the full text of an article from its original page and stores it
+// as the article content, as done by the rules "fetch_full_text" action
+func FetchFullText(h *core.Handler, articleID int64) error {
+	article, err := h.DB.GetArticleByID(articleID)
+	if err != nil {
+		return err
+	}
+	if article == nil || article.URL == "" {
+		return nil
+	}
+
+	// Respect the global setting, as for manual fetches
+	fullTextEnabledStr, _ := h.DB.GetSetting("full_text_fetch_enabled")
+	if fullTextEnabledStr != "true" {
+		return fmt.Errorf("full-text fetching is not enabled")
+	}
+
+	feed, _ := h.DB.GetFeedByID(article.FeedID)
+	fullContent, err := h.FetchFullArticleContentForFeed(article.URL, feed)
+	if err != nil {
+		return err
+	}
+
+	h.ContentCache.Set(articleID, fullContent)
+	return h.DB.SetArticleFullText(articleID, fullContent)
+}
+
 // HandleExtractAllImages extracts all image URLs from article content
 // @Summary      Extract all images from article
 // @Description  Extract all image URLs from article content (including relative URLs resolved to absolute)
@@ -53,38 +53,78 @@ func HandleExportToObsidian(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Check if Obsidian integration is enabled
-	obsidianEnabled, _ := h.DB.GetSetting("obsidian_enabled")
-	if obsidianEnabled != "true" {
+	// Check that Obsidian integration is enabled with a valid vault
+	vaultPath, err := obsidianVaultPath(h)
+	if err != nil {
 		response.Error(w, nil, http.StatusBadRequest)
 		return
 	}
 
+	filePath, err := writeObsidianNote(h, *article, loadExportAnnotations(h, r, article.ID), vaultPath)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	response.JSON(w, map[string]string{
+		"success":   "true",
+		"file_path": filePath,
+		"message":   "Article exported to Obsidian successfully",
+	})
+}
+
+// ExportArticleToObsidian writes an article to the Obsidian vault for a user, as done by
+// the rules "export_obsidian" action, and returns the path of the note
+func ExportArticleToObsidian(h *core.Handler, userID, articleID int64) (string, error) {
+	article, err := h.DB.GetArticleByID(articleID)
+	if err != nil {
+		return "", err
+	}
+	if article == nil {
+		return "", fmt.Errorf("article %d not found", articleID)
+	}
+
+	vaultPath, err := obsidianVaultPath(h)
+	if err != nil {
+		return "", err
+	}
+	return writeObsidianNote(h, *article, loadExportAnnotationsForUser(h, userID, articleID), vaultPath)
+}
+
+// obsidianVaultPath returns the vault path if Obsidian integration is enabled and the vault is a directory
+func obsidianVaultPath(h *core.Handler) (string, error) {
+	obsidianEnabled, _ := h.DB.GetSetting("obsidian_enabled")
+	if obsidianEnabled != "true" {
+		return "", fmt.Errorf("obsidian integration is not enabled")
+	}
+
 	// Get vault path (required for direct file access)
 	vaultPath, _ := h.DB.GetSetting("obsidian_vault_path")
 	if vaultPath == "" {
-		response.Error(w, nil, http.StatusBadRequest)
-		return
+		return "", fmt.Errorf("obsidian vault path is not configured")
 	}
 
 	// Validate vault path exists and is a directory
-	if info, err := os.Stat(vaultPath); os.IsNotExist(err) {
-		response.Error(w, nil, http.StatusBadRequest)
-		return
+	if info, err := os.Stat(vaultPath); err != nil {
+		return "", err
 	} else if !info.IsDir() {
-		response.Error(w, nil, http.StatusBadRequest)
-		return
+		return "", fmt.Errorf("obsidian vault path is not a directory")
 	}
+	return vaultPath, nil
+}
 
+// writeObsidianNote writes an article as a Markdown note to the vault and returns its path
+func writeObsidianNote(h *core.Handler, article models.Article, annotations exportAnnotations, vaultPath string) (string, error) {
 	// Get article content
-	content, _, err := h.GetArticleContent(int64(req.ArticleID))
+	content, _, err := h.GetArticleContent(article.ID)
 	if err != nil {
 		// If content fetch fails, continue with empty content
 		content = ""
 	}
 
 	// Generate Markdown content
-	markdownContent := generateObsidianMarkdown(*article, content)
+	markdownContent := generateObsidianMarkdown(article, content, annotations)
 
 	// Generate filename (sanitize title)
 	filename := sanitizeFilename(article.Title)
@@ -98,20 +138,13 @@ func HandleExportToObsidian(h *core.Handler, w http.ResponseWriter, r *http.Requ
 
 	// Write file to Obsidian vault
 	if err := os.WriteFile(filePath, []byte(markdownContent), 0644); err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
-		return
+		return "", err
 	}
-
-	// Return success response
-	response.JSON(w, map[string]string{
-		"success":   "true",
-		"file_path": filePath,
-		"message":   "Article exported to Obsidian successfully",
-	})
+	return filePath, nil
 }
 
 // generateObsidianMarkdown converts an article to Markdown format for Obsidian
-func generateObsidianMarkdown(article models.Article, content string) string {
+func generateObsidianMarkdown(article models.Article, content string, annotations exportAnnotations) string {
 	var sb strings.Builder
 
 	// Front matter - exclude URL to avoid URI parsing issues
@@ -119,7 +152,7 @@ func generateObsidianMarkdown(article models.Article, content string) string {
 	sb.WriteString(fmt.Sprintf("title: \"%s\"\n", escapeYamlString(article.Title)))
 	sb.WriteString(fmt.Sprintf("feed: \"%s\"\n", escapeYamlString(article.FeedTitle)))
 	sb.WriteString(fmt.Sprintf("published: \"%s\"\n", article.PublishedAt.Format(time.RFC3339)))
-	sb.WriteString(fmt.Sprintf("tags: [rss, %s]\n", sanitizeTag(article.FeedTitle)))
+	sb.WriteString(fmt.Sprintf("tags: [%s]\n", frontMatterTags(article.FeedTitle, annotations.Tags)))
 	sb.WriteString("---\n\n")
 
 	// Title
@@ -137,6 +170,8 @@ func generateObsidianMarkdown(article models.Article, content string) string {
 		sb.WriteString("\n\n")
 	}
 
+	writeHighlightsMarkdown(&sb, annotations.Highlights)
+
 	// Add metadata at the end
 	sb.WriteString("---\n")
 	sb.WriteString(fmt.Sprintf("**Added to Obsidian:** %s\n", time.Now().Format("2006-01-02 15:04:05")))
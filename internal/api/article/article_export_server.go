@@ -4,6 +4,7 @@ package article
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"net/http"
@@ -61,7 +62,7 @@ func HandleExportToObsidian(h *core.Handler, w http.ResponseWriter, r *http.Requ
 	}
 
 	// Generate Markdown content
-	markdownContent := generateObsidianMarkdown(*article, content)
+	markdownContent := generateObsidianMarkdown(*article, content, loadExportAnnotations(h, r, article.ID))
 
 	// Generate filename (sanitize title)
 	filename := sanitizeFilename(article.Title)
@@ -86,15 +87,24 @@ func HandleExportToObsidian(h *core.Handler, w http.ResponseWriter, r *http.Requ
 	}
 }
 
+// errServerExport is returned by automatic exports, which need the local file system
+// or integrations that are only available in desktop mode
+var errServerExport = errors.New("automatic export is not available in server mode")
+
+// ExportArticleToObsidian is not available in server mode, where notes are downloaded instead
+func ExportArticleToObsidian(h *core.Handler, userID, articleID int64) (string, error) {
+	return "", errServerExport
+}
+
 // generateObsidianMarkdown converts an article to Markdown format for Obsidian
-func generateObsidianMarkdown(article models.Article, content string) string {
+func generateObsidianMarkdown(article models.Article, content string, annotations exportAnnotations) string {
 	var sb strings.Builder
 
 	sb.WriteString("---\n")
 	sb.WriteString(fmt.Sprintf("title: \"%s\"\n", escapeYamlString(article.Title)))
 	sb.WriteString(fmt.Sprintf("feed: \"%s\"\n", escapeYamlString(article.FeedTitle)))
 	sb.WriteString(fmt.Sprintf("published: \"%s\"\n", article.PublishedAt.Format(time.RFC3339)))
-	sb.WriteString(fmt.Sprintf("tags: [rss, %s]\n", sanitizeTag(article.FeedTitle)))
+	sb.WriteString(fmt.Sprintf("tags: [%s]\n", frontMatterTags(article.FeedTitle, annotations.Tags)))
 	sb.WriteString("---\n\n")
 
 	sb.WriteString(fmt.Sprintf("# %s\n\n", article.Title))
@@ -107,6 +117,8 @@ func generateObsidianMarkdown(article models.Article, content string) string {
 		sb.WriteString("\n\n")
 	}
 
+	writeHighlightsMarkdown(&sb, annotations.Highlights)
+
 	sb.WriteString("---\n")
 	sb.WriteString(fmt.Sprintf("**Added to Obsidian:** %s\n", time.Now().Format("2006-01-02 15:04:05")))
 	sb.WriteString(fmt.Sprintf("**Article ID:** %d\n", article.ID))
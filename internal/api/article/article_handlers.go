@@ -6,46 +6,19 @@ import (
 	"log"
 	"net/http"
 	"sort"
-	"strings"
 	"time"
 
 	"MavenRSS/internal/feed"
 	"MavenRSS/internal/api/core"
 	"MavenRSS/internal/api/response"
 	"MavenRSS/internal/models"
-	"MavenRSS/internal/rsshub"
+	"MavenRSS/internal/query"
 )
 
 // GetFeedType returns the type code of a feed
 // Possible values: "regular", "freshrss", "rsshub", "script", "xpath", "email"
 func GetFeedType(feed *models.Feed) string {
-	// Check FreshRSS
-	if feed.IsFreshRSSSource {
-		return "freshrss"
-	}
-
-	// Check RSSHub
-	if rsshub.IsRSSHubURL(feed.URL) {
-		return "rsshub"
-	}
-
-	// Check custom script
-	if feed.ScriptPath != "" {
-		return "script"
-	}
-
-	// Check email
-	if feed.Type == "email" {
-		return "email"
-	}
-
-	// Check XPath
-	if feed.Type == "HTML+XPath" || feed.Type == "XML+XPath" {
-		return "xpath"
-	}
-
-	// Default: regular RSS/Atom feed
-	return "regular"
+	return query.FeedType(feed)
 }
 
 // HandleProgress returns the current fetch progress with statistics.
@@ -157,7 +130,7 @@ func HandleTaskDetails(h *core.Handler, w http.ResponseWriter, r *http.Request)
 
 // HandleFilteredArticles returns articles filtered by advanced conditions from the database.
 // @Summary      Get filtered articles
-// @Description  Retrieve articles with advanced filtering conditions
+// @Description  Retrieve articles matching a query (or legacy filtering conditions)
 // @Tags         articles
 // @Accept       json
 // @Produce      json
@@ -178,6 +151,12 @@ func HandleFilteredArticles(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	expr, err := req.Expression()
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
 	// Set default pagination values
 	page := req.Page
 	if page < 1 {
@@ -192,108 +171,22 @@ func HandleFilteredArticles(h *core.Handler, w http.ResponseWriter, r *http.Requ
 	showHiddenStr, _ := h.DB.GetSetting("show_hidden_articles")
 	showHidden := showHiddenStr == "true"
 
-	// Get all articles from database
-	// Note: Using a high limit to fetch all articles for filtering
-	// For very large datasets, consider implementing database-level filtering
-	articles, err := h.DB.GetArticles("", 0, "", showHidden, 50000, 0)
+	// Fetch the matching articles, prefiltered by the database
+	// Note: Using a high limit to fetch all matches for pagination
+	articles, err := QueryArticles(h, expr, 0, showHidden, 50000)
 	if err != nil {
 		response.Error(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	// Get feeds for category lookup
-	feeds, err := h.DB.GetFeeds()
-	if err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
-		return
-	}
-
-	// Create maps of feed ID to feed data
-	feedCategories := make(map[int64]string)
-	feedTypes := make(map[int64]string)
-	feedIsImageMode := make(map[int64]bool)
-	feedTags := make(map[int64][]string)
-	feedArticlesPerMonth := make(map[int64]float64)
-	feedLastUpdateStatus := make(map[int64]string)
-
-	for _, feed := range feeds {
-		feedCategories[feed.ID] = feed.Category
-		feedTypes[feed.ID] = GetFeedType(&feed)
-		feedIsImageMode[feed.ID] = feed.IsImageMode
-		feedArticlesPerMonth[feed.ID] = feed.ArticlesPerMonth
-		feedLastUpdateStatus[feed.ID] = feed.LastUpdateStatus
-
-		// Build tag names list for this feed
-		tags, _ := h.DB.GetFeedTags(feed.ID)
-		tagNames := make([]string, len(tags))
-		for i, tag := range tags {
-			tagNames[i] = tag.Name
-		}
-		feedTags[feed.ID] = tagNames
-	}
-
-	// Check if any filter condition requires article content
-	needsArticleContent := false
-	for _, condition := range req.Conditions {
-		if condition.Field == "article_content" {
-			needsArticleContent = true
-			break
+	if req.CollapseDuplicates {
+		articles, err = CollapseDuplicates(h, articles)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
 		}
 	}
 
-	// Build article content map if needed
-	articleContents := make(map[int64]string)
-	if needsArticleContent {
-		// Collect article IDs
-		articleIDs := make([]int64, len(articles))
-		for i, article := range articles {
-			articleIDs[i] = article.ID
-		}
-
-		// Build placeholders for SQL query
-		placeholders := make([]string, len(articleIDs))
-		args := make([]interface{}, len(articleIDs))
-		for i, id := range articleIDs {
-			placeholders[i] = "?"
-			args[i] = id
-		}
-
-		// Query all article contents at once
-		query := `SELECT article_id, content FROM article_contents WHERE article_id IN (` + strings.Join(placeholders, ",") + `)`
-		rows, err := h.DB.Query(query, args...)
-		if err == nil {
-			defer rows.Close()
-			for rows.Next() {
-				var articleID int64
-				var content string
-				if err := rows.Scan(&articleID, &content); err == nil {
-					articleContents[articleID] = content
-				}
-			}
-		}
-	}
-
-	// Apply filter conditions
-	if len(req.Conditions) > 0 {
-		var filteredArticles []models.Article
-		for _, article := range articles {
-			if evaluateArticleConditions(
-				article,
-				req.Conditions,
-				feedCategories,
-				feedTypes,
-				feedIsImageMode,
-				feedTags,
-				feedArticlesPerMonth,
-				feedLastUpdateStatus,
-				articleContents,
-			) {
-				filteredArticles = append(filteredArticles, article)
-			}
-		}
-		articles = filteredArticles
-	}
-
 	// Apply pagination
 	total := len(articles)
 	offset := (page - 1) * limit
@@ -324,6 +217,44 @@ func HandleFilteredArticles(h *core.Handler, w http.ResponseWriter, r *http.Requ
 	response.JSON(w, resp)
 }
 
+// CollapseDuplicates keeps one article per near-duplicate cluster, the earliest published,
+// and sets its DuplicateCount to the number of collapsed members. Articles are expected newest first.
+func CollapseDuplicates(h *core.Handler, articles []models.Article) ([]models.Article, error) {
+	articleIDs := make([]int64, len(articles))
+	for i, article := range articles {
+		articleIDs[i] = article.ID
+	}
+	clusters, err := h.DB.GetArticleClusterIDs(articleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk oldest first so each cluster is represented by its earliest member
+	representative := make(map[int64]int)
+	keep := make([]bool, len(articles))
+	for i := len(articles) - 1; i >= 0; i-- {
+		clusterID, ok := clusters[articles[i].ID]
+		if !ok {
+			keep[i] = true
+			continue
+		}
+		if idx, seen := representative[clusterID]; seen {
+			articles[idx].DuplicateCount++
+			continue
+		}
+		representative[clusterID] = i
+		keep[i] = true
+	}
+
+	collapsed := make([]models.Article, 0, len(articles))
+	for i, article := range articles {
+		if keep[i] {
+			collapsed = append(collapsed, article)
+		}
+	}
+	return collapsed, nil
+}
+
 // HandleRefreshArticle refreshes a single article's content, translation and summary.
 // @Summary      Refresh article
 // @Description  Force refresh a single article's content, translation and summary
@@ -0,0 +1,105 @@
+package article
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	"MavenRSS/internal/store/sqlite"
+)
+
+// maxSearchLimit caps the page size of full-text search results
+const maxSearchLimit = 200
+
+// SearchResponse is the response of the full-text search endpoint
+type SearchResponse struct {
+	Results []sqlite.ArticleSearchResult `json:"results"`
+	Total   int                          `json:"total"`
+	Page    int                          `json:"page"`
+	Limit   int                          `json:"limit"`
+}
+
+// HandleSearchArticles runs a ranked full-text search over the user's articles.
+// @Summary      Full-text search articles
+// @Description  Search titles, translated titles, summaries, cached content and authors using the full-text index.
+// @Description  Supports "phrases", prefix*, AND/OR/NOT, -excluded terms, parentheses and column:term (title, translated_title, summary, content, author, transcript).
+// @Tags         articles
+// @Accept       json
+// @Produce      json
+// @Param        q            query     string  true   "Search query"
+// @Param        feed_id      query     int64   false  "Restrict to a feed ID"
+// @Param        category     query     string  false  "Restrict to a category (including sub-categories)"
+// @Param        unread_only  query     bool    false  "Only unread articles"
+// @Param        page         query     int     false  "Page number (default: 1)"  minimum(1)
+// @Param        limit        query     int     false  "Items per page (default: 50, max: 200)"  minimum(1)  maximum(200)
+// @Success      200  {object}  article.SearchResponse  "Ranked results with highlighted snippets"
+// @Failure      400  {object}  map[string]string  "Bad request (missing or invalid query)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /articles/search [get]
+func HandleSearchArticles(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := core.GetUserIDFromRequest(r)
+	if !ok {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	q := query.Get("q")
+	if q == "" {
+		response.Error(w, fmt.Errorf("q is required"), http.StatusBadRequest)
+		return
+	}
+
+	page := 1
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	limit := 50
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	opts := sqlite.ArticleSearchOptions{
+		Query:      q,
+		Category:   query.Get("category"),
+		UnreadOnly: query.Get("unread_only") == "true",
+		Limit:      limit,
+		Offset:     (page - 1) * limit,
+	}
+	if feedIDStr := query.Get("feed_id"); feedIDStr != "" {
+		feedID, err := strconv.ParseInt(feedIDStr, 10, 64)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		opts.FeedID = feedID
+	}
+
+	results, total, err := h.DB.SearchArticlesForUser(userID, opts)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrInvalidSearchQuery) {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, SearchResponse{
+		Results: results,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	})
+}
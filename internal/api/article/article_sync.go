@@ -81,7 +81,7 @@ func HandleMarkReadWithImmediateSync(h *core.Handler, w http.ResponseWriter, r *
 
 	// Immediately sync to FreshRSS if needed
 	if syncReq != nil {
-		go performImmediateSync(h, syncReq)
+		go PerformImmediateSync(h, syncReq)
 	}
 }
 
@@ -140,12 +140,13 @@ func HandleToggleFavoriteWithImmediateSync(h *core.Handler, w http.ResponseWrite
 
 	// Immediately sync to FreshRSS if needed
 	if syncReq != nil {
-		go performImmediateSync(h, syncReq)
+		go PerformImmediateSync(h, syncReq)
 	}
 }
 
-// performImmediateSync performs an immediate sync to FreshRSS in a background goroutine
-func performImmediateSync(h *core.Handler, syncReq *sqlite.SyncRequest) {
+// PerformImmediateSync performs an immediate sync to FreshRSS in a background goroutine.
+// It is shared by every API surface that changes read/star state (web UI, Google Reader API).
+func PerformImmediateSync(h *core.Handler, syncReq *sqlite.SyncRequest) {
 	// Check if FreshRSS is enabled and configured
 	enabled, _ := h.DB.GetSetting("freshrss_enabled")
 	if enabled != "true" {
@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"regexp"
 	"strings"
@@ -182,42 +183,102 @@ func HandleExportToNotion(h *core.Handler, w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Check if Notion integration is enabled
+	// Check that Notion integration is enabled and configured
+	apiKey, pageID, err := notionConfig(h)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	pageURL, appendErr, err := createArticlePage(h, *article, loadExportAnnotations(h, r, article.ID), apiKey, pageID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if appendErr != nil {
+		// Page was created but some content failed to append
+		// Still return success but mention the issue
+		response.JSON(w, map[string]string{
+			"success":  "true",
+			"page_url": pageURL,
+			"message":  fmt.Sprintf("Article exported but some content may be missing: %v", appendErr),
+		})
+		return
+	}
+
+	// Return success response
+	response.JSON(w, map[string]string{
+		"success":  "true",
+		"page_url": pageURL,
+		"message":  "Article exported to Notion successfully",
+	})
+}
+
+// ExportArticleToNotion creates a Notion page for an article of a user, as done by
+// the rules "export_notion" action, and returns the URL of the page
+func ExportArticleToNotion(h *core.Handler, userID, articleID int64) (string, error) {
+	article, err := h.DB.GetArticleByID(articleID)
+	if err != nil {
+		return "", err
+	}
+	if article == nil {
+		return "", fmt.Errorf("article %d not found", articleID)
+	}
+
+	apiKey, pageID, err := notionConfig(h)
+	if err != nil {
+		return "", err
+	}
+
+	pageURL, appendErr, err := createArticlePage(h, *article, loadExportAnnotationsForUser(h, userID, articleID), apiKey, pageID)
+	if err != nil {
+		return "", err
+	}
+	if appendErr != nil {
+		log.Printf("Article %d exported to Notion but some content may be missing: %v", articleID, appendErr)
+	}
+	return pageURL, nil
+}
+
+// notionConfig returns the API key and the normalized parent page ID if Notion integration is enabled
+func notionConfig(h *core.Handler) (apiKey, pageID string, err error) {
 	notionEnabled, _ := h.DB.GetSetting("notion_enabled")
 	if notionEnabled != "true" {
-		response.Error(w, fmt.Errorf("notion integration is not enabled"), http.StatusBadRequest)
-		return
+		return "", "", fmt.Errorf("notion integration is not enabled")
 	}
 
 	// Get API key (encrypted setting)
-	apiKey, _ := h.DB.GetEncryptedSetting("notion_api_key")
+	apiKey, _ = h.DB.GetEncryptedSetting("notion_api_key")
 	if apiKey == "" {
-		response.Error(w, fmt.Errorf("notion API key is not configured"), http.StatusBadRequest)
-		return
+		return "", "", fmt.Errorf("notion API key is not configured")
 	}
 
 	// Get parent page ID
-	pageID, _ := h.DB.GetSetting("notion_page_id")
+	pageID, _ = h.DB.GetSetting("notion_page_id")
 	if pageID == "" {
-		response.Error(w, fmt.Errorf("notion page ID is not configured"), http.StatusBadRequest)
-		return
+		return "", "", fmt.Errorf("notion page ID is not configured")
 	}
 
 	// Normalize page ID (remove hyphens if present)
-	pageID = strings.ReplaceAll(pageID, "-", "")
+	return apiKey, strings.ReplaceAll(pageID, "-", ""), nil
+}
 
+// createArticlePage creates a Notion page with the article content and highlights.
+// appendErr reports content that failed to be appended after the page was created.
+func createArticlePage(h *core.Handler, article models.Article, annotations exportAnnotations, apiKey, pageID string) (pageURL string, appendErr error, err error) {
 	// Get article content
-	content, _, err := h.GetArticleContent(int64(req.ArticleID))
+	content, _, err := h.GetArticleContent(article.ID)
 	if err != nil {
 		// If content fetch fails, continue with empty content
 		content = ""
 	}
 
-	// Convert content to Notion blocks
+	// Convert content to Notion blocks, followed by the user's highlights
 	contentBlocks := htmlToNotionBlocks(content)
+	contentBlocks = append(contentBlocks, buildHighlightBlocks(annotations.Highlights)...)
 
 	// Build initial page with metadata (max 100 blocks including metadata)
-	metadataBlocks := buildMetadataBlocks(*article)
+	metadataBlocks := buildMetadataBlocks(article)
 	initialBlocks := metadataBlocks
 
 	// Calculate how many content blocks we can add to initial request
@@ -249,31 +310,30 @@ func HandleExportToNotion(h *core.Handler, w http.ResponseWriter, r *http.Reques
 	proxyURL := buildNotionProxyURL(h)
 	pageURL, createdPageID, err := createNotionPage(apiKey, notionRequest, proxyURL)
 	if err != nil {
-		response.Error(w, err, http.StatusInternalServerError)
-		return
+		return "", nil, err
 	}
 
 	// If there are remaining content blocks, append them in batches
 	if len(contentBlocks) > 0 {
-		err = appendBlocksInBatches(apiKey, createdPageID, contentBlocks, proxyURL)
-		if err != nil {
-			// Page was created but some content failed to append
-			// Still return success but mention the issue
-			response.JSON(w, map[string]string{
-				"success":  "true",
-				"page_url": pageURL,
-				"message":  fmt.Sprintf("Article exported but some content may be missing: %v", err),
-			})
-			return
-		}
+		appendErr = appendBlocksInBatches(apiKey, createdPageID, contentBlocks, proxyURL)
 	}
+	return pageURL, appendErr, nil
+}
 
-	// Return success response
-	response.JSON(w, map[string]string{
-		"success":  "true",
-		"page_url": pageURL,
-		"message":  "Article exported to Notion successfully",
-	})
+// buildHighlightBlocks creates a Highlights section with a quote per highlight and its note
+func buildHighlightBlocks(highlights []models.ArticleHighlight) []NotionBlock {
+	if len(highlights) == 0 {
+		return nil
+	}
+
+	blocks := []NotionBlock{createHeading2Block("Highlights")}
+	for _, hl := range highlights {
+		blocks = append(blocks, createQuoteBlock(strings.TrimSpace(hl.Text)))
+		if note := strings.TrimSpace(hl.Note); note != "" {
+			blocks = append(blocks, createParagraphBlock("**Note:** "+note))
+		}
+	}
+	return blocks
 }
 
 // buildMetadataBlocks creates metadata blocks for the article
@@ -59,7 +59,7 @@ func HandleExportToNotion(h *core.Handler, w http.ResponseWriter, r *http.Reques
 	}
 
 	// Generate Markdown content
-	markdownContent := generateNotionMarkdown(*article, content)
+	markdownContent := generateNotionMarkdown(*article, content, loadExportAnnotations(h, r, article.ID))
 
 	// Generate filename (sanitize title)
 	filename := sanitizeFilename(article.Title)
@@ -78,15 +78,20 @@ func HandleExportToNotion(h *core.Handler, w http.ResponseWriter, r *http.Reques
 	fmt.Println(logMsg)
 }
 
+// ExportArticleToNotion is not available in server mode, where pages are downloaded instead
+func ExportArticleToNotion(h *core.Handler, userID, articleID int64) (string, error) {
+	return "", errServerExport
+}
+
 // generateNotionMarkdown converts an article to Markdown format for Notion
-func generateNotionMarkdown(article models.Article, content string) string {
+func generateNotionMarkdown(article models.Article, content string, annotations exportAnnotations) string {
 	var sb strings.Builder
 
 	sb.WriteString("---\n")
 	sb.WriteString(fmt.Sprintf("title: \"%s\"\n", escapeYamlString(article.Title)))
 	sb.WriteString(fmt.Sprintf("feed: \"%s\"\n", escapeYamlString(article.FeedTitle)))
 	sb.WriteString(fmt.Sprintf("published: \"%s\"\n", article.PublishedAt.Format(time.RFC3339)))
-	sb.WriteString(fmt.Sprintf("tags: [rss, %s]\n", sanitizeTag(article.FeedTitle)))
+	sb.WriteString(fmt.Sprintf("tags: [%s]\n", frontMatterTags(article.FeedTitle, annotations.Tags)))
 	sb.WriteString("---\n\n")
 
 	sb.WriteString(fmt.Sprintf("# %s\n\n", article.Title))
@@ -99,6 +104,8 @@ func generateNotionMarkdown(article models.Article, content string) string {
 		sb.WriteString("\n\n")
 	}
 
+	writeHighlightsMarkdown(&sb, annotations.Highlights)
+
 	sb.WriteString("---\n")
 	sb.WriteString(fmt.Sprintf("**Exported:** %s\n", time.Now().Format("2006-01-02 15:04:05")))
 	sb.WriteString(fmt.Sprintf("**Article ID:** %d\n", article.ID))
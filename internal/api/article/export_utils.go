@@ -1,11 +1,80 @@
 package article
 
 import (
+	"fmt"
+	"net/http"
 	"strings"
 
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/models"
+
 	md "github.com/JohannesKaufmann/html-to-markdown"
 )
 
+// exportAnnotations holds the user's tags and highlights of an exported article
+type exportAnnotations struct {
+	Tags       []string
+	Highlights []models.ArticleHighlight
+}
+
+// loadExportAnnotations loads the tags and highlights of an article for the requesting user.
+// Without a user in the request (desktop mode), annotations of all users are included.
+// Errors are ignored so that the export still succeeds without annotations.
+func loadExportAnnotations(h *core.Handler, r *http.Request, articleID int64) exportAnnotations {
+	userID, _ := core.GetUserIDFromRequest(r)
+	return loadExportAnnotationsForUser(h, userID, articleID)
+}
+
+// loadExportAnnotationsForUser loads the tags and highlights of an article for a user,
+// or for all users when userID is 0
+func loadExportAnnotationsForUser(h *core.Handler, userID, articleID int64) exportAnnotations {
+	var annotations exportAnnotations
+
+	if userID > 0 {
+		if tags, err := h.DB.GetArticleTagsForUser(userID, articleID); err == nil {
+			for _, tag := range tags {
+				annotations.Tags = append(annotations.Tags, tag.Name)
+			}
+		}
+	} else if names, err := h.DB.GetArticleTagNames([]int64{articleID}); err == nil {
+		annotations.Tags = names[articleID]
+	}
+
+	if highlights, err := h.DB.GetArticleHighlights(userID, articleID); err == nil {
+		annotations.Highlights = highlights
+	}
+	return annotations
+}
+
+// frontMatterTags builds the tags list of the front matter from the feed name and article tags
+func frontMatterTags(feedName string, articleTags []string) string {
+	tags := []string{"rss", sanitizeTag(feedName)}
+	for _, tag := range articleTags {
+		if t := sanitizeTag(tag); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return strings.Join(tags, ", ")
+}
+
+// writeHighlightsMarkdown appends a Highlights section with each highlight quoted and its note below
+func writeHighlightsMarkdown(sb *strings.Builder, highlights []models.ArticleHighlight) {
+	if len(highlights) == 0 {
+		return
+	}
+
+	sb.WriteString("## Highlights\n\n")
+	for _, hl := range highlights {
+		for _, line := range strings.Split(strings.TrimSpace(hl.Text), "\n") {
+			sb.WriteString(fmt.Sprintf("> %s\n", strings.TrimSpace(line)))
+		}
+		if note := strings.TrimSpace(hl.Note); note != "" {
+			sb.WriteString(fmt.Sprintf("\n**Note:** %s\n", note))
+		}
+		sb.WriteString("\n")
+	}
+}
+
 // sanitizeFilename creates a safe filename from a title
 func sanitizeFilename(title string) string {
 	invalidChars := []string{"<", ">", ":", "\"", "|", "?", "*", "\\", "/"}
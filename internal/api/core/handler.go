@@ -3,13 +3,11 @@
 package core
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
 	"html"
 	"log"
-	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
@@ -21,6 +19,7 @@ import (
 	"MavenRSS/internal/store/sqlite"
 	"MavenRSS/internal/discovery"
 	"MavenRSS/internal/feed"
+	"MavenRSS/internal/fulltext"
 	"MavenRSS/internal/models"
 	svc "MavenRSS/internal/service"
 	"MavenRSS/internal/statistics"
@@ -29,8 +28,6 @@ import (
 	"MavenRSS/internal/utils/textutil"
 	"MavenRSS/internal/utils/urlutil"
 
-	"codeberg.org/readeck/go-readability/v2"
-
 	"github.com/mmcdole/gofeed"
 )
 
@@ -190,6 +187,12 @@ func (h *Handler) GetArticleContent(articleID int64) (string, bool, error) {
 
 // FetchFullArticleContent fetches the full article content from the original URL using readability.
 func (h *Handler) FetchFullArticleContent(pageURL string) (string, error) {
+	return h.FetchFullArticleContentForFeed(pageURL, nil)
+}
+
+// FetchFullArticleContentForFeed fetches the full content of an article of a feed,
+// sending the feed's credentials, cookies and custom headers. The feed may be nil.
+func (h *Handler) FetchFullArticleContentForFeed(pageURL string, articleFeed *models.Feed) (string, error) {
 	// Build proxy URL if enabled
 	var proxyURL string
 	proxyEnabled, _ := h.DB.GetSetting("proxy_enabled")
@@ -206,65 +209,34 @@ func (h *Handler) FetchFullArticleContent(pageURL string) (string, error) {
 	}
 
 	// Use our own HTTP client with proxy support
-	userAgent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
-	httpClient := httputil.GetPooledUserAgentClient(proxyURL, 30*time.Second, userAgent)
-
-	// Fetch the page first using our HTTP client
-	req, err := http.NewRequest("GET", pageURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
-	}
-
-	// Add browser-like headers to bypass anti-bot protections
-	// Note: Don't set Accept-Encoding - let Go's http.Transport handle it automatically
-	// This ensures proper gzip/deflate decompression is applied
-	req.Header.Set("User-Agent", userAgent)
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9,zh-CN;q=0.8,zh;q=0.7")
-	req.Header.Set("DNT", "1")
-	req.Header.Set("Connection", "keep-alive")
-	req.Header.Set("Upgrade-Insecure-Requests", "1")
-	req.Header.Set("Sec-Fetch-Dest", "document")
-	req.Header.Set("Sec-Fetch-Mode", "navigate")
-	req.Header.Set("Sec-Fetch-Site", "none")
-	req.Header.Set("Sec-Fetch-User", "?1")
-	req.Header.Set("Cache-Control", "max-age=0")
-
-	log.Printf("[FetchFullArticleContent] Fetching URL: %s", pageURL)
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		log.Printf("[FetchFullArticleContent] Fetch error: %v", err)
-		return "", fmt.Errorf("fetch page: %w", err)
-	}
-	defer resp.Body.Close()
-
-	log.Printf("[FetchFullArticleContent] Response status: %d", resp.StatusCode)
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	// Parse the page URL for relative links
-	parsedURL, err := url.Parse(pageURL)
-	if err != nil {
-		return "", fmt.Errorf("parse URL: %w", err)
+	httpClient := httputil.GetPooledUserAgentClient(proxyURL, 30*time.Second, fulltext.BrowserUserAgent)
+	var siteRules []fulltext.SiteRule
+	if articleFeed != nil {
+		httpClient = feed.WithFeedAuth(httpClient, *articleFeed)
+		var err error
+		if siteRules, err = fulltext.LoadSiteRules(h.DB, articleFeed.UserID); err != nil {
+			log.Printf("[FetchFullArticleContent] Error loading site rules: %v", err)
+		}
 	}
 
-	// Use FromReader to parse with our own fetched content
-	article, err := readability.FromReader(resp.Body, parsedURL)
-	if err != nil {
-		log.Printf("[FetchFullArticleContent] Readability error: %v", err)
-		return "", fmt.Errorf("readability parse: %w", err)
+	// Scraping respects robots.txt when enabled
+	if respectRobots, _ := h.DB.GetSetting("respect_robots_txt"); respectRobots == "true" {
+		if err := httputil.GetRobotsChecker().Check(context.Background(), httpClient, pageURL); err != nil {
+			log.Printf("[FetchFullArticleContent] Not fetching %s: %v", pageURL, err)
+			return "", err
+		}
 	}
 
-	// Render the article content as HTML
-	var buf bytes.Buffer
-	err = article.RenderHTML(&buf)
+	// Extract the content with the site rule of the page, or readability
+	log.Printf("[FetchFullArticleContent] Fetching URL: %s", pageURL)
+	result, err := fulltext.Extract(context.Background(), httpClient, pageURL, fulltext.MatchSiteRule(siteRules, pageURL))
 	if err != nil {
-		return "", fmt.Errorf("render HTML: %w", err)
+		log.Printf("[FetchFullArticleContent] Fetch error: %v", err)
+		return "", err
 	}
 
 	// Remove duplicate content blocks
-	content := removeDuplicateContent(buf.String())
+	content := removeDuplicateContent(result.Content)
 
 	// Proxy images in the content if media proxy is enabled
 	// This ensures images work correctly even with anti-hotlinking protection
@@ -275,7 +247,7 @@ func (h *Handler) FetchFullArticleContent(pageURL string) (string, error) {
 		content = h.ProxyImagesInHTMLContent(content, pageURL)
 	}
 
-	log.Printf("[FetchFullArticleContent] Successfully fetched article content, original length: %d, after dedup: %d", buf.Len(), len(content))
+	log.Printf("[FetchFullArticleContent] Successfully fetched article content, original length: %d, after dedup: %d", len(result.Content), len(content))
 	return content, nil
 }
 
@@ -10,6 +10,7 @@ import (
 	"MavenRSS/internal/cache"
 	"MavenRSS/internal/config"
 	"MavenRSS/internal/models"
+	"MavenRSS/internal/rules"
 	"MavenRSS/internal/utils/fileutil"
 )
 
@@ -42,6 +43,9 @@ func (h *Handler) StartBackgroundScheduler(ctx context.Context) {
 		}
 	}()
 
+	// Re-apply scheduled rules every night
+	go rules.NewEngine(h.DB).RunSchedule(ctx)
+
 	// Check if running in server mode
 	if fileutil.IsServerMode() {
 		log.Println("Running in server mode - using multi-user scheduler")
@@ -219,7 +223,7 @@ func (h *Handler) triggerGlobalRefresh(ctx context.Context, intelligentMode bool
 	if intelligentMode {
 			// In intelligent mode, schedule each feed individually with calculated intervals
 			calculator := h.Fetcher.GetIntelligentRefreshCalculator()
-			for _, feed := range refreshableFeeds {
+			for _, feed := range h.Fetcher.FilterPushCoveredFeeds(refreshableFeeds) {
 				interval := calculator.CalculateInterval(feed)
 				staggerDelay := h.Fetcher.GetStaggeredDelay(feed.ID, len(refreshableFeeds))
 
@@ -256,6 +260,9 @@ func (h *Handler) scheduleIndividualFeeds(ctx context.Context, intelligentMode b
 
 	calculator := h.Fetcher.GetIntelligentRefreshCalculator()
 
+	// Feeds kept up to date by WebSub pushes are only polled as a safety net
+	feeds = h.Fetcher.FilterPushCoveredFeeds(feeds)
+
 	for _, feed := range feeds {
 		// Skip feeds using global setting (RefreshInterval == 0)
 		if feed.RefreshInterval == 0 {
@@ -533,7 +540,7 @@ func (h *Handler) triggerUserRefresh(ctx context.Context, userID int64, intellig
 	if intelligentMode {
 			// In intelligent mode, schedule each feed individually with calculated intervals
 			calculator := h.Fetcher.GetIntelligentRefreshCalculator()
-			for _, feed := range refreshableFeeds {
+			for _, feed := range h.Fetcher.FilterPushCoveredFeeds(refreshableFeeds) {
 				interval := calculator.CalculateInterval(feed)
 				staggerDelay := h.Fetcher.GetStaggeredDelay(feed.ID, len(refreshableFeeds))
 
@@ -563,6 +570,9 @@ func (h *Handler) scheduleUserIndividualFeeds(ctx context.Context, userID int64,
 
 	calculator := h.Fetcher.GetIntelligentRefreshCalculator()
 
+	// Feeds kept up to date by WebSub pushes are only polled as a safety net
+	feeds = h.Fetcher.FilterPushCoveredFeeds(feeds)
+
 	for _, feed := range feeds {
 		// Skip feeds using global setting (RefreshInterval == 0)
 		if feed.RefreshInterval == 0 {
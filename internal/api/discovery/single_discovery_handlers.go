@@ -14,7 +14,8 @@ import (
 
 // HandleDiscoverBlogs discovers blogs from a feed's friend links.
 // @Summary      Discover blogs from feed
-// @Description  Discover new blogs by analyzing friend links from a specific feed's RSS content
+// @Description  Discover new blogs by analyzing friend links from a specific feed's RSS content.
+// @Description  Blogs publishing JSON Feed or h-feed are reported with the feed_type to subscribe with.
 // @Tags         discovery
 // @Accept       json
 // @Produce      json
@@ -0,0 +1,169 @@
+package feed
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	ff "MavenRSS/internal/feed"
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/utils/httputil"
+)
+
+// maxCookiesUpload caps the size of an uploaded cookies.txt file
+const maxCookiesUpload = 512 * 1024
+
+// getUserFeed returns the feed of the id query parameter, writing the error response when it fails
+func getUserFeed(h *core.Handler, w http.ResponseWriter, r *http.Request, userID int64) *models.Feed {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return nil
+	}
+	feed, err := h.DB.GetFeedByIDForUser(userID, id)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return nil
+	}
+	if feed == nil {
+		response.Error(w, nil, http.StatusNotFound)
+		return nil
+	}
+	return feed
+}
+
+// HandleFeedAuth gets or sets the HTTP authentication of a feed.
+// @Summary      Get or set feed authentication
+// @Description  GET returns the credentials, cookies and custom headers sent when fetching the feed
+// @Description  and its articles, with the secrets redacted. POST replaces them; secrets sent back
+// @Description  redacted ("********") keep their stored value, and an empty body removes them.
+// @Description  They are stored encrypted.
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        id       query     int64            true   "Feed ID"
+// @Param        request  body      models.FeedAuth  false  "Authentication (POST)"
+// @Success      200  {object}  models.FeedAuth  "Redacted authentication"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid feed ID or authentication)"
+// @Failure      404  {object}  map[string]string  "Feed not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/auth [get]
+// @Router       /feeds/auth [post]
+func HandleFeedAuth(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	userID, ok := core.GetUserIDFromRequest(r)
+	if !ok {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		feed := getUserFeed(h, w, r, userID)
+		if feed == nil {
+			return
+		}
+		auth := feed.Auth.Redacted()
+		if auth == nil {
+			auth = &models.FeedAuth{}
+		}
+		response.JSON(w, auth)
+
+	case http.MethodPost:
+		feed := getUserFeed(h, w, r, userID)
+		if feed == nil {
+			return
+		}
+		var auth models.FeedAuth
+		if err := json.NewDecoder(r.Body).Decode(&auth); err != nil && err != io.EOF {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		ff.MergeRedactedSecrets(&auth, feed.Auth)
+		if err := ff.ValidateFeedAuth(&auth); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := h.DB.SetFeedAuth(feed.ID, &auth); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, auth.Redacted())
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleImportFeedCookies imports the cookies of a feed from a Netscape cookies.txt file.
+// @Summary      Import feed cookies
+// @Description  Replace the cookies sent when fetching a feed and its articles with the cookies
+// @Description  of a Netscape cookies.txt file, as exported by browser extensions. Expired cookies
+// @Description  are skipped.
+// @Tags         feeds
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id    query     int64  true   "Feed ID"
+// @Param        file  formData  file   false  "cookies.txt file (or the raw request body)"
+// @Success      200  {object}  map[string]int  "Number of cookies imported (cookies)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid feed ID or cookies file)"
+// @Failure      404  {object}  map[string]string  "Feed not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/auth/cookies [post]
+func HandleImportFeedCookies(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := core.GetUserIDFromRequest(r)
+	if !ok {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+
+	feed := getUserFeed(h, w, r, userID)
+	if feed == nil {
+		return
+	}
+
+	var file io.Reader = r.Body
+	if strings.Contains(r.Header.Get("Content-Type"), "multipart/form-data") {
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+		file = f
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxCookiesUpload+1))
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if len(data) > maxCookiesUpload {
+		response.Error(w, nil, http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	cookies, err := httputil.ParseNetscapeCookies(strings.NewReader(string(data)))
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	auth := &models.FeedAuth{}
+	if feed.Auth != nil {
+		*auth = *feed.Auth
+	}
+	auth.Cookies = string(data)
+	if err := h.DB.SetFeedAuth(feed.ID, auth); err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, map[string]int{"cookies": len(cookies)})
+}
@@ -9,6 +9,7 @@ import (
 
 	"MavenRSS/internal/api/core"
 	"MavenRSS/internal/api/response"
+	ff "MavenRSS/internal/feed"
 	"MavenRSS/internal/models"
 	"MavenRSS/internal/rsshub"
 	"MavenRSS/internal/utils/httputil"
@@ -44,6 +45,7 @@ func HandleFeeds(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		feeds[i].Tags = tags
 		// Clear sensitive password fields before sending to frontend
 		feeds[i].EmailPassword = ""
+		feeds[i].Auth = feeds[i].Auth.Redacted()
 	}
 
 	response.JSON(w, feeds)
@@ -100,6 +102,15 @@ func HandleAddFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		EmailUsername   string `json:"email_username"`
 		EmailPassword   string `json:"email_password"`
 		EmailFolder     string `json:"email_folder"`
+		EmailSenders    string `json:"email_senders"`
+		// HTTP authentication, cookies and custom headers
+		Auth *models.FeedAuth `json:"auth"`
+		// Fetch the full text of new articles on ingest
+		FetchFullText bool `json:"fetch_full_text"`
+		// Next pages of JSON+JSONPath and XPath feeds, and item pages of XPath feeds
+		Pagination *models.FeedPagination `json:"pagination"`
+		// Patterns ignored when comparing the pages of watch feeds, one per line
+		WatchIgnore string `json:"watch_ignore"`
 		// Tags
 		Tags []int64 `json:"tags"`
 	}
@@ -107,10 +118,46 @@ func HandleAddFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		response.Error(w, err, http.StatusBadRequest)
 		return
 	}
+	if err := ff.ValidateFeedAuth(req.Auth); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if req.Type == models.FeedTypeWatch {
+		if err := ff.ValidateWatchFeed(&models.Feed{XPathItem: req.XPathItem, WatchIgnore: req.WatchIgnore}); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Type == "HTML+XPath" || req.Type == "XML+XPath" {
+		if err := ff.ValidateXPathPagination(req.Pagination); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+	}
 
 	// Normalize the URL to ensure it has a protocol
 	req.URL = urlutil.NormalizeFeedURL(req.URL)
 
+	if req.Type == models.FeedTypeJSONPath {
+		if err := ff.ValidateJSONPathFeed(&models.Feed{
+			URL:                 req.URL,
+			XPathItem:           req.XPathItem,
+			XPathItemTitle:      req.XPathItemTitle,
+			XPathItemContent:    req.XPathItemContent,
+			XPathItemUri:        req.XPathItemUri,
+			XPathItemAuthor:     req.XPathItemAuthor,
+			XPathItemTimestamp:  req.XPathItemTimestamp,
+			XPathItemTimeFormat: req.XPathItemTimeFormat,
+			XPathItemThumbnail:  req.XPathItemThumbnail,
+			XPathItemCategories: req.XPathItemCategories,
+			XPathItemUid:        req.XPathItemUid,
+			Pagination:          req.Pagination,
+		}); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Determine the feed URL to check for duplicates
 	feedURL := req.URL
 	if req.ScriptPath != "" {
@@ -198,6 +245,37 @@ func HandleAddFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Auth != nil {
+		if err := h.DB.SetFeedAuth(feedID, req.Auth); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	if req.FetchFullText {
+		if err := h.DB.SetFeedFetchFullText(feedID, true); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	if req.EmailSenders != "" {
+		if err := h.DB.SetFeedEmailSenders(feedID, req.EmailSenders); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	if req.Pagination != nil {
+		if err := h.DB.SetFeedPagination(feedID, req.Pagination); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	if req.WatchIgnore != "" {
+		if err := h.DB.SetFeedWatchIgnore(feedID, req.WatchIgnore); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
 	// Set tags for the feed
 	if len(req.Tags) > 0 {
 		if err := h.DB.SetFeedTags(feedID, req.Tags); err != nil {
@@ -303,12 +381,19 @@ func HandleUpdateFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		ArticleViewMode     string `json:"article_view_mode"`
 		AutoExpandContent   string `json:"auto_expand_content"`
 		// Email/Newsletter fields
-		EmailAddress    string `json:"email_address"`
-		EmailIMAPServer string `json:"email_imap_server"`
-		EmailIMAPPort   int    `json:"email_imap_port"`
-		EmailUsername   string `json:"email_username"`
-		EmailPassword   string `json:"email_password"`
-		EmailFolder     string `json:"email_folder"`
+		EmailAddress    string  `json:"email_address"`
+		EmailIMAPServer string  `json:"email_imap_server"`
+		EmailIMAPPort   int     `json:"email_imap_port"`
+		EmailUsername   string  `json:"email_username"`
+		EmailPassword   string  `json:"email_password"`
+		EmailFolder     string  `json:"email_folder"`
+		EmailSenders    *string `json:"email_senders"`
+		// Fetch the full text of new articles on ingest, unchanged when omitted
+		FetchFullText *bool `json:"fetch_full_text"`
+		// Next pages of JSON+JSONPath and XPath feeds and item pages of XPath feeds, unchanged when omitted
+		Pagination *models.FeedPagination `json:"pagination"`
+		// Patterns ignored when comparing the pages of watch feeds, unchanged when omitted
+		WatchIgnore *string `json:"watch_ignore"`
 		// Tags
 		Tags []int64 `json:"tags"`
 	}
@@ -330,6 +415,45 @@ func HandleUpdateFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		response.Error(w, nil, http.StatusNotFound)
 		return
 	}
+	watchIgnore := currentFeed.WatchIgnore
+	if req.WatchIgnore != nil {
+		watchIgnore = *req.WatchIgnore
+	}
+	if req.Type == models.FeedTypeWatch {
+		if err := ff.ValidateWatchFeed(&models.Feed{XPathItem: req.XPathItem, WatchIgnore: watchIgnore}); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Type == "HTML+XPath" || req.Type == "XML+XPath" {
+		if err := ff.ValidateXPathPagination(req.Pagination); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Type == models.FeedTypeJSONPath {
+		pagination := currentFeed.Pagination
+		if req.Pagination != nil {
+			pagination = req.Pagination
+		}
+		if err := ff.ValidateJSONPathFeed(&models.Feed{
+			URL:                 req.URL,
+			XPathItem:           req.XPathItem,
+			XPathItemTitle:      req.XPathItemTitle,
+			XPathItemContent:    req.XPathItemContent,
+			XPathItemUri:        req.XPathItemUri,
+			XPathItemAuthor:     req.XPathItemAuthor,
+			XPathItemTimestamp:  req.XPathItemTimestamp,
+			XPathItemTimeFormat: req.XPathItemTimeFormat,
+			XPathItemThumbnail:  req.XPathItemThumbnail,
+			XPathItemCategories: req.XPathItemCategories,
+			XPathItemUid:        req.XPathItemUid,
+			Pagination:          pagination,
+		}); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+	}
 
 	// Validate RSSHub URL if provided
 	if req.URL != "" && rsshub.IsRSSHubURL(req.URL) {
@@ -467,6 +591,38 @@ func HandleUpdateFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.FetchFullText != nil {
+		if err := h.DB.SetFeedFetchFullText(req.ID, *req.FetchFullText); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	if req.EmailSenders != nil {
+		if err := h.DB.SetFeedEmailSenders(req.ID, *req.EmailSenders); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	if req.Pagination != nil {
+		if err := h.DB.SetFeedPagination(req.ID, req.Pagination); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	if req.WatchIgnore != nil {
+		if err := h.DB.SetFeedWatchIgnore(req.ID, watchIgnore); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	// A watched page compared differently starts over from a new snapshot
+	if req.Type == models.FeedTypeWatch && (currentFeed.Type != req.Type || currentFeed.XPathItem != req.XPathItem || currentFeed.WatchIgnore != watchIgnore) {
+		if err := h.DB.SetFeedWatchSnapshot(req.ID, "", ""); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
 	// Update tags for the feed
 	if req.Tags != nil {
 		if err := h.DB.SetFeedTags(req.ID, req.Tags); err != nil {
@@ -0,0 +1,124 @@
+package feed
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	"MavenRSS/internal/store/sqlite"
+)
+
+// defaultHealthDays is the default number of days of fetches the health is computed from
+const defaultHealthDays = 7
+
+// HandleFeedHealth returns the health of the user's feeds computed from the fetch log.
+// @Summary      Get feed health
+// @Description  Get the success rate, p95 latency, consecutive failures and last successful fetch of each feed,
+// @Description  and the same statistics aggregated over all feeds. Rates and latencies cover the last days.
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        feed_id  query     int64  false  "Only this feed"
+// @Param        days     query     int    false  "Days of fetches (default: 7, max: 30)"  minimum(1)  maximum(30)
+// @Success      200  {object}  map[string]interface{}  "Feed health (summary, feeds)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid feed_id or days)"
+// @Failure      404  {object}  map[string]string  "Feed not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/health [get]
+func HandleFeedHealth(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	feedID, err := optionalFeedID(query.Get("feed_id"))
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	days := defaultHealthDays
+	if daysStr := query.Get("days"); daysStr != "" {
+		days, err = strconv.Atoi(daysStr)
+		if err != nil || days < 1 || days > int(sqlite.FeedFetchLogRetention/(24*time.Hour)) {
+			response.Error(w, nil, http.StatusBadRequest)
+			return
+		}
+	}
+
+	userID, _ := core.GetUserIDFromRequest(r)
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	feeds, summary, err := h.DB.GetFeedHealth(userID, feedID, since)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if feedID > 0 && len(feeds) == 0 {
+		response.Error(w, nil, http.StatusNotFound)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"summary": summary,
+		"feeds":   feeds,
+	})
+}
+
+// HandleFeedFetchLog lists the most recent fetches of the user's feeds.
+// @Summary      List feed fetches
+// @Description  Get the fetch log, newest first: timings, HTTP status, bytes, 304 responses, new and updated items,
+// @Description  error class and proxy of every fetch, optionally filtered by feed
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        feed_id  query     int64  false  "Only fetches of this feed"
+// @Param        page     query     int    false  "Page number (default: 1)"  minimum(1)
+// @Param        limit    query     int    false  "Items per page (default: 50, max: 200)"  minimum(1)  maximum(200)
+// @Success      200  {array}   sqlite.FeedFetchLog  "Fetches"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid feed_id)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/fetch-log [get]
+func HandleFeedFetchLog(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	feedID, err := optionalFeedID(query.Get("feed_id"))
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	page := 1
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	limit := 50
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	userID, _ := core.GetUserIDFromRequest(r)
+	logs, err := h.DB.GetFeedFetchLogs(userID, feedID, limit, (page-1)*limit)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, logs)
+}
+
+// optionalFeedID parses an optional feed ID, 0 when empty
+func optionalFeedID(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
@@ -0,0 +1,97 @@
+package feed
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	ff "MavenRSS/internal/feed"
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/utils/urlutil"
+)
+
+// jsonPathPreviewItem is an item extracted by the preview of a JSON+JSONPath feed
+type jsonPathPreviewItem struct {
+	Title      string     `json:"title"`
+	Link       string     `json:"link"`
+	Content    string     `json:"content,omitempty"`
+	Author     string     `json:"author,omitempty"`
+	Published  *time.Time `json:"published,omitempty"`
+	Thumbnail  string     `json:"thumbnail,omitempty"`
+	Categories []string   `json:"categories,omitempty"`
+	UID        string     `json:"uid"`
+}
+
+// HandlePreviewJSONPathFeed fetches a JSON+JSONPath feed before it is saved and returns the extracted items.
+// @Summary      Preview a JSON+JSONPath feed
+// @Description  Fetch a JSON API with the JSONPath expressions of a feed (xpath_item for the items, xpath_item_title,
+// @Description  xpath_item_uri for the link, xpath_item_content, xpath_item_author, xpath_item_timestamp and
+// @Description  xpath_item_time_format, xpath_item_thumbnail, xpath_item_categories, xpath_item_uid), following the
+// @Description  pagination, and return the items as they would be saved. The feed's auth and proxy settings are used.
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.Feed  true  "Feed URL, JSONPath expressions, pagination, auth and proxy"
+// @Success      200  {object}  map[string]interface{}  "Extracted items (items, count)"
+// @Failure      400  {object}  map[string]string  "Bad request (missing URL or invalid JSONPath)"
+// @Failure      502  {object}  map[string]string  "The API could not be fetched or parsed"
+// @Router       /feeds/jsonpath/preview [post]
+func HandlePreviewJSONPathFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := core.GetUserIDFromRequest(r)
+	if !ok {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+
+	var feed models.Feed
+	if err := json.NewDecoder(r.Body).Decode(&feed); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	feed.ID = 0
+	feed.UserID = userID
+	feed.URL = urlutil.NormalizeFeedURL(feed.URL)
+	if err := ff.ValidateFeedAuth(feed.Auth); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := ff.ValidateJSONPathFeed(&feed); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	parsed, err := h.Fetcher.PreviewJSONPathFeed(r.Context(), feed)
+	if err != nil {
+		response.Error(w, err, http.StatusBadGateway)
+		return
+	}
+
+	items := make([]jsonPathPreviewItem, 0, len(parsed.Items))
+	for _, item := range parsed.Items {
+		preview := jsonPathPreviewItem{
+			Title:      item.Title,
+			Link:       item.Link,
+			Content:    item.Content,
+			Published:  item.PublishedParsed,
+			Categories: item.Categories,
+			UID:        item.GUID,
+		}
+		if item.Author != nil {
+			preview.Author = item.Author.Name
+		}
+		if item.Image != nil {
+			preview.Thumbnail = item.Image.URL
+		}
+		items = append(items, preview)
+	}
+	response.JSON(w, map[string]interface{}{
+		"items": items,
+		"count": len(items),
+	})
+}
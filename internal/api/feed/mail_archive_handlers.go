@@ -0,0 +1,71 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	ff "MavenRSS/internal/feed"
+)
+
+// HandleImportMailArchive imports the newsletters of a local mbox file or Maildir directory.
+// @Summary      Import a mail archive
+// @Description  Import the newsletters of an mbox file, Maildir, or directory of them, with a feed per
+// @Description  mailing list (List-Id) or sender. Messages are deduplicated by Message-Id, so importing an
+// @Description  archive again only adds new messages. Watched archives are read again on each refresh.
+// @Description  In server mode the archive must be inside MRRSS_MAIL_ARCHIVE_DIR.
+// @Tags         email
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "path, category, watch"
+// @Success      200  {object}  map[string]interface{}  "Imported feeds (feeds)"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      403  {object}  map[string]string  "Archive outside the allowed directory"
+// @Failure      404  {object}  map[string]string  "Archive not found"
+// @Failure      503  {object}  map[string]string  "Mail archive import not enabled"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/mail-archive [post]
+func HandleImportMailArchive(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := core.GetUserIDFromRequest(r)
+	if !ok {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Path     string `json:"path"`
+		Category string `json:"category"`
+		Watch    bool   `json:"watch"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if req.Path == "" {
+		response.Error(w, errors.New("path is required"), http.StatusBadRequest)
+		return
+	}
+
+	feeds, err := h.Fetcher.ImportMailArchive(r.Context(), userID, req.Path, req.Category, req.Watch)
+	if err != nil {
+		switch {
+		case errors.Is(err, ff.ErrMailArchivesDisabled):
+			response.Error(w, err, http.StatusServiceUnavailable)
+		case errors.Is(err, ff.ErrMailArchiveOutsideRoot):
+			response.Error(w, err, http.StatusForbidden)
+		case errors.Is(err, os.ErrNotExist):
+			response.Error(w, err, http.StatusNotFound)
+		default:
+			response.Error(w, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	response.JSON(w, map[string]interface{}{"feeds": feeds})
+}
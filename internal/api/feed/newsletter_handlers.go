@@ -0,0 +1,58 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	ff "MavenRSS/internal/feed"
+)
+
+// HandleAddNewsletter creates a newsletter feed with an address of the built-in mail receiver.
+// @Summary      Add a newsletter address
+// @Description  Create an email feed with a new address (feed-<token>@domain) received by the server's
+// @Description  SMTP/LMTP receiver. Newsletters sent to it become articles; those of other mailing lists
+// @Description  (by List-Id) sent to the same address get a feed of their own. Requires MRRSS_MAIL_DOMAIN.
+// @Tags         email
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  false  "title, category"
+// @Success      200  {object}  map[string]interface{}  "Created feed (feed_id, address)"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      503  {object}  map[string]string  "Mail receiver not enabled"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/newsletter [post]
+func HandleAddNewsletter(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := core.GetUserIDFromRequest(r)
+	if !ok {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Title    string `json:"title"`
+		Category string `json:"category"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	feedID, address, err := h.Fetcher.AddReceivedEmailSubscription(userID, req.Title, req.Category)
+	if err != nil {
+		if errors.Is(err, ff.ErrMailReceiverDisabled) {
+			response.Error(w, err, http.StatusServiceUnavailable)
+			return
+		}
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, map[string]interface{}{"feed_id": feedID, "address": address})
+}
@@ -0,0 +1,120 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	ff "MavenRSS/internal/feed"
+)
+
+// rediscoverTimeout bounds the homepage discovery of a manual rediscovery
+const rediscoverTimeout = 2 * time.Minute
+
+// HandleRediscoverFeed reruns feed discovery on the homepage of a feed to replace its URL.
+// @Summary      Rediscover a feed
+// @Description  Look for the feed on the website's homepage and move the feed to the URL found,
+// @Description  e.g. for feeds paused after HTTP 410. The change is recorded in the URL changes
+// @Description  and the feed is refreshed.
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        id   query     int64   true  "Feed ID"
+// @Success      200  {object}  map[string]string  "New feed URL (url)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid feed ID)"
+// @Failure      404  {object}  map[string]string  "Feed not found, or no replacement feed found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/rediscover [post]
+func HandleRediscoverFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := core.GetUserIDFromRequest(r)
+	if !ok {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	feed, err := h.DB.GetFeedByIDForUser(userID, id)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if feed == nil {
+		response.Error(w, nil, http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), rediscoverTimeout)
+	defer cancel()
+	newURL, err := h.Fetcher.RediscoverFeed(ctx, *feed)
+	if errors.Is(err, ff.ErrNoReplacementFeed) {
+		response.Error(w, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// Refresh the feed from its new URL (manual = queue head)
+	if updated, err := h.DB.GetFeedByID(id); err == nil && updated != nil {
+		go h.Fetcher.FetchSingleFeed(context.Background(), *updated, true)
+	}
+
+	response.JSON(w, map[string]string{"url": newURL})
+}
+
+// HandleFeedURLChanges lists the automatic URL changes of the user's feeds.
+// @Summary      List feed URL changes
+// @Description  Get the audit log of feed URLs changed after permanent redirects (moved)
+// @Description  or by rediscovery (discovered), newest first
+// @Tags         feeds
+// @Accept       json
+// @Produce      json
+// @Param        feed_id  query     int64  false  "Only changes of this feed"
+// @Param        limit    query     int    false  "Maximum number of changes (default: 50, max: 200)"  minimum(1)  maximum(200)
+// @Success      200  {array}   sqlite.FeedURLChange  "URL changes"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid feed_id)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /feeds/url-changes [get]
+func HandleFeedURLChanges(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	feedID, err := optionalFeedID(query.Get("feed_id"))
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	limit := 50
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	userID, _ := core.GetUserIDFromRequest(r)
+	changes, err := h.DB.GetFeedURLChanges(userID, feedID, limit)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, changes)
+}
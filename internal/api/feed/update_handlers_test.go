@@ -2,11 +2,14 @@ package feed_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http/httptest"
 	"testing"
 
 	fh "MavenRSS/internal/api/feed"
+	"MavenRSS/internal/auth"
+	"MavenRSS/internal/middleware"
 	"MavenRSS/internal/models"
 )
 
@@ -41,3 +44,29 @@ func TestHandleUpdateFeed_ValidAndInvalid(t *testing.T) {
 		t.Fatalf("expected 400 for invalid payload, got %d", w2.Result().StatusCode)
 	}
 }
+
+func TestHandleFeeds_InvalidJSONPath(t *testing.T) {
+	h := setupHandler(t)
+	ctx := context.WithValue(context.Background(), middleware.UserContextKey, &auth.Claims{UserID: 1, Username: "admin"})
+
+	id, err := h.DB.AddFeed(&models.Feed{Title: "api", URL: "http://example.com/api", Type: models.FeedTypeJSONPath, XPathItem: "$.items"})
+	if err != nil {
+		t.Fatalf("AddFeed error: %v", err)
+	}
+
+	payload := map[string]interface{}{"url": "http://example.com/other", "type": models.FeedTypeJSONPath, "xpath_item": "$.items[0"}
+	body, _ := json.Marshal(payload)
+	w := httptest.NewRecorder()
+	fh.HandleAddFeed(h, w, httptest.NewRequest("POST", "/api/feeds/add", bytes.NewReader(body)).WithContext(ctx))
+	if w.Code != 400 {
+		t.Errorf("expected 400 when adding an invalid JSONPath feed, got %d", w.Code)
+	}
+
+	payload = map[string]interface{}{"id": id, "title": "api", "url": "http://example.com/api", "type": models.FeedTypeJSONPath, "xpath_item": "$.items", "xpath_item_title": "$..["}
+	body, _ = json.Marshal(payload)
+	w = httptest.NewRecorder()
+	fh.HandleUpdateFeed(h, w, httptest.NewRequest("POST", "/api/feeds/update", bytes.NewReader(body)).WithContext(ctx))
+	if w.Code != 400 {
+		t.Errorf("expected 400 when updating a JSONPath feed with an invalid expression, got %d", w.Code)
+	}
+}
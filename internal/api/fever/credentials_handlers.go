@@ -0,0 +1,90 @@
+package fever
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+)
+
+// minPasswordLength is the minimum length of a Fever password
+const minPasswordLength = 8
+
+// APIKey computes the Fever API key for a username and password, as clients do.
+func APIKey(username, password string) string {
+	sum := md5.Sum([]byte(username + ":" + password))
+	return hex.EncodeToString(sum[:])
+}
+
+// HandleFeverCredentials manages the Fever password of the current user.
+// Fever clients send md5("username:password"), so a dedicated password is used
+// instead of the account password, which is never stored in a reversible form.
+// @Summary      Manage Fever API credentials
+// @Description  GET returns whether Fever access is enabled, POST sets a Fever password, DELETE disables Fever access
+// @Tags         fever
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  false  "Fever password (password) for POST"
+// @Success      200  {object}  map[string]interface{}  "Fever status (enabled, username)"
+// @Failure      400  {object}  map[string]string  "Bad request (password too short)"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /fever/credentials [get]
+func HandleFeverCredentials(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	claims, ok := core.GetUserFromRequest(r)
+	if !ok {
+		response.Error(w, fmt.Errorf("unauthorized"), http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		enabled, err := h.DB.HasFeverAPIKey(claims.UserID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, map[string]interface{}{
+			"enabled":  enabled,
+			"username": claims.Username,
+		})
+
+	case http.MethodPost:
+		var req struct {
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if len(req.Password) < minPasswordLength {
+			response.Error(w, fmt.Errorf("password must be at least %d characters", minPasswordLength), http.StatusBadRequest)
+			return
+		}
+		if err := h.DB.SetFeverAPIKey(claims.UserID, APIKey(claims.Username, req.Password)); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, map[string]interface{}{
+			"enabled":  true,
+			"username": claims.Username,
+		})
+
+	case http.MethodDelete:
+		if err := h.DB.DeleteFeverAPIKey(claims.UserID); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, map[string]interface{}{
+			"enabled":  false,
+			"username": claims.Username,
+		})
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
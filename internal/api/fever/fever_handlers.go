@@ -0,0 +1,410 @@
+// Package fever implements the Fever API (https://feedafever.com/api) for legacy RSS clients.
+package fever
+
+import (
+	"errors"
+	"hash/crc32"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/api/article"
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+// apiVersion is the Fever API version reported to clients
+const apiVersion = 3
+
+// maxItems is the number of items returned per "items" request, as defined by the Fever API
+const maxItems = 50
+
+// group is a Fever group, mapped from a feed category
+type group struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+// feedsGroup lists the feeds belonging to a group
+type feedsGroup struct {
+	GroupID int64  `json:"group_id"`
+	FeedIDs string `json:"feed_ids"`
+}
+
+// feverFeed is a Fever feed
+type feverFeed struct {
+	ID                int64  `json:"id"`
+	FaviconID         int64  `json:"favicon_id"`
+	Title             string `json:"title"`
+	URL               string `json:"url"`
+	SiteURL           string `json:"site_url"`
+	IsSpark           int    `json:"is_spark"`
+	LastUpdatedOnTime int64  `json:"last_updated_on_time"`
+}
+
+// item is a Fever item
+type item struct {
+	ID            int64  `json:"id"`
+	FeedID        int64  `json:"feed_id"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	HTML          string `json:"html"`
+	URL           string `json:"url"`
+	IsSaved       int    `json:"is_saved"`
+	IsRead        int    `json:"is_read"`
+	CreatedOnTime int64  `json:"created_on_time"`
+}
+
+// HandleFever serves every Fever API call on a single endpoint.
+// Clients authenticate with api_key = md5("username:password") using the Fever password set in the user's settings.
+// @Summary      Fever API
+// @Description  Fever compatible API for legacy RSS clients (groups, feeds, items, unread_item_ids, saved_item_ids, mark)
+// @Tags         fever
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        api_key  formData  string  true   "md5 of username:fever_password"
+// @Param        api      query     string  true   "Must be present"
+// @Success      200  {object}  map[string]interface{}  "Fever response (auth is 0 when the key is invalid)"
+// @Router       /fever.php [post]
+func HandleFever(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"api_version": apiVersion,
+		"auth":        0,
+	}
+
+	userID, err := h.DB.GetUserIDByFeverAPIKey(r.Form.Get("api_key"))
+	if err != nil || r.Form.Get("api_key") == "" {
+		response.JSON(w, resp)
+		return
+	}
+	resp["auth"] = 1
+
+	feeds, err := h.DB.GetFeedsForUser(userID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	resp["last_refreshed_on_time"] = lastRefreshed(feeds)
+
+	// Writes are applied before reads so that a combined request returns fresh state
+	if r.Form.Has("mark") {
+		if err := handleMark(h, userID, feeds, r); err != nil {
+			if errors.Is(err, sqlite.ErrArticleNotFound) {
+				response.Error(w, err, http.StatusNotFound)
+				return
+			}
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if r.Form.Has("groups") {
+		resp["groups"] = buildGroups(feeds)
+		resp["feeds_groups"] = buildFeedsGroups(feeds)
+	}
+
+	if r.Form.Has("feeds") {
+		resp["feeds"] = buildFeeds(feeds)
+		resp["feeds_groups"] = buildFeedsGroups(feeds)
+	}
+
+	if r.Form.Has("favicons") {
+		// Feed icons are served by URL elsewhere; Fever expects inline data, which we don't store
+		resp["favicons"] = []interface{}{}
+	}
+
+	if r.Form.Has("links") {
+		// Hot links are a Fever-specific feature with no equivalent here
+		resp["links"] = []interface{}{}
+	}
+
+	if r.Form.Has("items") {
+		items, err := getItems(h, userID, r)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		total, err := h.DB.GetTotalArticleCount(userID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		resp["items"] = items
+		resp["total_items"] = total
+	}
+
+	if r.Form.Has("unread_item_ids") {
+		ids, err := itemIDs(h, userID, sqlite.StreamQuery{ExcludeRead: true})
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		resp["unread_item_ids"] = ids
+	}
+
+	if r.Form.Has("saved_item_ids") {
+		ids, err := itemIDs(h, userID, sqlite.StreamQuery{OnlyStarred: true})
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		resp["saved_item_ids"] = ids
+	}
+
+	response.JSON(w, resp)
+}
+
+// groupID derives a stable Fever group ID from a category name.
+// Group 0 is reserved by Fever for the "Kindling" super group.
+func groupID(category string) int64 {
+	return int64(crc32.ChecksumIEEE([]byte(category))&0x7fffffff) + 1
+}
+
+// lastRefreshed returns the most recent feed update time as a Unix timestamp.
+func lastRefreshed(feeds []models.Feed) int64 {
+	var latest time.Time
+	for _, f := range feeds {
+		if f.LastUpdated.After(latest) {
+			latest = f.LastUpdated
+		}
+	}
+	if latest.IsZero() {
+		return 0
+	}
+	return latest.Unix()
+}
+
+// buildGroups returns one group per category, sorted by name.
+func buildGroups(feeds []models.Feed) []group {
+	seen := make(map[string]bool)
+	groups := []group{}
+	for _, f := range feeds {
+		if f.Category == "" || seen[f.Category] {
+			continue
+		}
+		seen[f.Category] = true
+		groups = append(groups, group{ID: groupID(f.Category), Title: f.Category})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Title < groups[j].Title })
+	return groups
+}
+
+// buildFeedsGroups returns the comma-separated feed IDs of every group.
+func buildFeedsGroups(feeds []models.Feed) []feedsGroup {
+	byCategory := make(map[string][]string)
+	var categories []string
+	for _, f := range feeds {
+		if f.Category == "" {
+			continue
+		}
+		if _, ok := byCategory[f.Category]; !ok {
+			categories = append(categories, f.Category)
+		}
+		byCategory[f.Category] = append(byCategory[f.Category], strconv.FormatInt(f.ID, 10))
+	}
+	sort.Strings(categories)
+
+	result := make([]feedsGroup, 0, len(categories))
+	for _, c := range categories {
+		result = append(result, feedsGroup{GroupID: groupID(c), FeedIDs: strings.Join(byCategory[c], ",")})
+	}
+	return result
+}
+
+// buildFeeds converts feeds to their Fever representation.
+func buildFeeds(feeds []models.Feed) []feverFeed {
+	result := make([]feverFeed, 0, len(feeds))
+	for _, f := range feeds {
+		var updated int64
+		if !f.LastUpdated.IsZero() {
+			updated = f.LastUpdated.Unix()
+		}
+		result = append(result, feverFeed{
+			ID:                f.ID,
+			FaviconID:         f.ID,
+			Title:             f.Title,
+			URL:               f.URL,
+			SiteURL:           f.Link,
+			LastUpdatedOnTime: updated,
+		})
+	}
+	return result
+}
+
+// getItems handles the since_id, max_id and with_ids parameters of an "items" request.
+func getItems(h *core.Handler, userID int64, r *http.Request) ([]item, error) {
+	q := sqlite.FeverItemQuery{Limit: maxItems}
+	if withIDs := r.Form.Get("with_ids"); withIDs != "" {
+		q.WithIDs = parseIDList(withIDs)
+		if len(q.WithIDs) > maxItems {
+			q.WithIDs = q.WithIDs[:maxItems]
+		}
+		if len(q.WithIDs) == 0 {
+			return []item{}, nil
+		}
+	} else if maxID, err := strconv.ParseInt(r.Form.Get("max_id"), 10, 64); err == nil && maxID > 0 {
+		q.MaxID = maxID
+	} else if sinceID, err := strconv.ParseInt(r.Form.Get("since_id"), 10, 64); err == nil && sinceID > 0 {
+		q.SinceID = sinceID
+	}
+
+	articles, err := h.DB.GetFeverItemsForUser(userID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+	}
+	contents, err := h.DB.GetArticleContentsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]item, 0, len(articles))
+	for _, a := range articles {
+		html := contents[a.ID]
+		if html == "" {
+			html = a.Summary
+		}
+		items = append(items, item{
+			ID:            a.ID,
+			FeedID:        a.FeedID,
+			Title:         a.Title,
+			Author:        a.Author,
+			HTML:          html,
+			URL:           a.URL,
+			IsSaved:       boolToInt(a.IsFavorite),
+			IsRead:        boolToInt(a.IsRead),
+			CreatedOnTime: a.PublishedAt.Unix(),
+		})
+	}
+	return items, nil
+}
+
+// itemIDs returns the comma-separated IDs of every article matching the query.
+func itemIDs(h *core.Handler, userID int64, q sqlite.StreamQuery) (string, error) {
+	refs, err := h.DB.GetStreamItemRefsForUser(userID, q)
+	if err != nil {
+		return "", err
+	}
+	ids := make([]string, len(refs))
+	for i, ref := range refs {
+		ids[i] = strconv.FormatInt(ref.ID, 10)
+	}
+	return strings.Join(ids, ","), nil
+}
+
+// handleMark applies a mark=item|feed|group request.
+func handleMark(h *core.Handler, userID int64, feeds []models.Feed, r *http.Request) error {
+	id, err := strconv.ParseInt(r.Form.Get("id"), 10, 64)
+	if err != nil {
+		return errors.New("invalid id")
+	}
+	as := r.Form.Get("as")
+
+	switch r.Form.Get("mark") {
+	case "item":
+		return markItem(h, userID, id, as)
+	case "feed", "group":
+		if as != "read" {
+			return errors.New("feeds and groups can only be marked as read")
+		}
+		var q sqlite.StreamQuery
+		if r.Form.Get("mark") == "feed" {
+			q.FeedID = id
+		} else if id > 0 {
+			category := categoryForGroup(feeds, id)
+			if category == "" {
+				return errors.New("unknown group")
+			}
+			q.Category = category
+		}
+		if before, err := strconv.ParseInt(r.Form.Get("before"), 10, 64); err == nil && before > 0 {
+			q.OlderThan = time.Unix(before, 0)
+		}
+		syncReqs, err := h.DB.MarkStreamAsReadWithSyncForUser(userID, q)
+		if err != nil {
+			return err
+		}
+		if len(syncReqs) > 0 {
+			go article.PerformImmediateBulkSync(h, syncReqs)
+		}
+		return nil
+	default:
+		return errors.New("invalid mark target")
+	}
+}
+
+// markItem changes the read or saved state of a single item.
+func markItem(h *core.Handler, userID, id int64, as string) error {
+	var syncReq *sqlite.SyncRequest
+	var err error
+
+	switch as {
+	case "read", "unread":
+		syncReq, err = h.DB.MarkArticleReadWithSyncForUser(userID, id, as == "read")
+	case "saved", "unsaved":
+		a, getErr := h.DB.GetArticleByIDForUser(userID, id)
+		if getErr != nil {
+			return getErr
+		}
+		if a == nil {
+			return sqlite.ErrArticleNotFound
+		}
+		if a.IsFavorite == (as == "saved") {
+			return nil
+		}
+		syncReq, err = h.DB.ToggleFavoriteWithSyncForUser(userID, id)
+	default:
+		return errors.New("invalid mark state")
+	}
+	if err != nil {
+		return err
+	}
+
+	if syncReq != nil {
+		go article.PerformImmediateSync(h, syncReq)
+	}
+	log.Printf("[Fever] Marked article %d as %s for user %d", id, as, userID)
+	return nil
+}
+
+// categoryForGroup maps a Fever group ID back to its category name.
+func categoryForGroup(feeds []models.Feed, id int64) string {
+	for _, f := range feeds {
+		if f.Category != "" && groupID(f.Category) == id {
+			return f.Category
+		}
+	}
+	return ""
+}
+
+// parseIDList parses a comma-separated list of IDs, skipping invalid entries.
+func parseIDList(raw string) []int64 {
+	var ids []int64
+	for _, part := range strings.Split(raw, ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,121 @@
+package fever
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/api/core"
+	ff "MavenRSS/internal/feed"
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+func setupHandler(t *testing.T) *core.Handler {
+	t.Helper()
+	db, err := sqlite.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	return core.NewHandler(db, ff.NewFetcher(db), nil, nil)
+}
+
+func callFever(t *testing.T, h *core.Handler, query string, form url.Values) map[string]interface{} {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/fever.php?"+query, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	HandleFever(h, w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return resp
+}
+
+func TestHandleFever_Auth(t *testing.T) {
+	h := setupHandler(t)
+
+	resp := callFever(t, h, "api", url.Values{"api_key": {APIKey("admin", "wrong")}})
+	if resp["auth"].(float64) != 0 {
+		t.Fatalf("expected auth=0 for unknown key, got %v", resp["auth"])
+	}
+
+	if err := h.DB.SetFeverAPIKey(1, APIKey("admin", "fever-pass")); err != nil {
+		t.Fatalf("SetFeverAPIKey: %v", err)
+	}
+	resp = callFever(t, h, "api", url.Values{"api_key": {APIKey("admin", "fever-pass")}})
+	if resp["auth"].(float64) != 1 || resp["api_version"].(float64) != apiVersion {
+		t.Fatalf("unexpected auth response: %v", resp)
+	}
+}
+
+func TestHandleFever_ItemsAndMark(t *testing.T) {
+	h := setupHandler(t)
+	key := APIKey("admin", "fever-pass")
+	if err := h.DB.SetFeverAPIKey(1, key); err != nil {
+		t.Fatalf("SetFeverAPIKey: %v", err)
+	}
+
+	feedID, err := h.DB.AddFeed(&models.Feed{Title: "F", URL: "http://example.com/rss", Category: "News"})
+	if err != nil {
+		t.Fatalf("AddFeed: %v", err)
+	}
+	articles := []*models.Article{
+		{FeedID: feedID, Title: "a1", URL: "http://example.com/1", PublishedAt: time.Now().Add(-time.Hour)},
+		{FeedID: feedID, Title: "a2", URL: "http://example.com/2", PublishedAt: time.Now()},
+	}
+	if err := h.DB.SaveArticles(context.Background(), articles); err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+
+	resp := callFever(t, h, "api&groups&feeds", url.Values{"api_key": {key}})
+	groups := resp["groups"].([]interface{})
+	if len(groups) != 1 || groups[0].(map[string]interface{})["title"] != "News" {
+		t.Fatalf("unexpected groups: %v", groups)
+	}
+	if len(resp["feeds"].([]interface{})) != 1 {
+		t.Fatalf("unexpected feeds: %v", resp["feeds"])
+	}
+
+	resp = callFever(t, h, "api&items&since_id=0", url.Values{"api_key": {key}})
+	items := resp["items"].([]interface{})
+	if len(items) != 2 || resp["total_items"].(float64) != 2 {
+		t.Fatalf("expected 2 items, got %v", resp)
+	}
+	firstID := int64(items[0].(map[string]interface{})["id"].(float64))
+
+	callFever(t, h, "api&mark=item&as=read&id="+jsonID(firstID), url.Values{"api_key": {key}})
+	callFever(t, h, "api&mark=item&as=saved&id="+jsonID(firstID), url.Values{"api_key": {key}})
+
+	resp = callFever(t, h, "api&unread_item_ids&saved_item_ids", url.Values{"api_key": {key}})
+	if strings.Contains(resp["unread_item_ids"].(string), jsonID(firstID)) {
+		t.Errorf("item %d should no longer be unread: %v", firstID, resp["unread_item_ids"])
+	}
+	if resp["saved_item_ids"] != jsonID(firstID) {
+		t.Errorf("expected saved item %d, got %v", firstID, resp["saved_item_ids"])
+	}
+
+	group := groupID("News")
+	callFever(t, h, "api&mark=group&as=read&id="+jsonID(group), url.Values{"api_key": {key}})
+	resp = callFever(t, h, "api&unread_item_ids", url.Values{"api_key": {key}})
+	if resp["unread_item_ids"] != "" {
+		t.Errorf("expected no unread items, got %v", resp["unread_item_ids"])
+	}
+}
+
+func jsonID(id int64) string {
+	b, _ := json.Marshal(id)
+	return string(b)
+}
@@ -8,6 +8,7 @@ import (
 	"MavenRSS/internal/api/core"
 	"MavenRSS/internal/api/response"
 	"MavenRSS/internal/models"
+	"MavenRSS/internal/query"
 )
 
 // HandleSavedFilters handles CRUD operations for saved filters
@@ -19,7 +20,7 @@ import (
 // @Success      200  {array}   models.SavedFilter  "List of saved filters"
 // @Router       /saved-filters [get]
 // @Summary      Create a new saved filter
-// @Description  Create a new article filter with custom conditions
+// @Description  Create a new article filter from a query or legacy conditions
 // @Tags         filters
 // @Accept       json
 // @Produce      json
@@ -60,6 +61,10 @@ func HandleSavedFilters(h *core.Handler, w http.ResponseWriter, r *http.Request)
 			response.Error(w, nil, http.StatusBadRequest)
 			return
 		}
+		if _, err := query.ParseStored(req.Conditions); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
 
 		// Check if filter with same name already exists
 		existingFilters, err := h.DB.GetSavedFilters()
@@ -136,6 +141,11 @@ func HandleSavedFilter(h *core.Handler, w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		if _, err := query.ParseStored(req.Conditions); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+
 		filter := &models.SavedFilter{
 			ID:         id,
 			Name:       req.Name,
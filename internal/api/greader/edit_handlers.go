@@ -0,0 +1,116 @@
+package greader
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"MavenRSS/internal/api/article"
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/store/sqlite"
+)
+
+// handleEditTag adds or removes the read and starred states on items.
+// Parameters: i (item IDs, repeatable), a (tag to add), r (tag to remove).
+func (gh *Handler) handleEditTag(w http.ResponseWriter, r *http.Request) {
+	userID, _ := core.GetUserIDFromRequest(r)
+
+	ids := parseItemIDs(r.Form["i"])
+	if len(ids) == 0 {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	for _, id := range ids {
+		for _, add := range r.Form["a"] {
+			if err := gh.applyTag(userID, id, normalizeStreamID(add), true); err != nil {
+				gh.writeEditError(w, id, err)
+				return
+			}
+		}
+		for _, remove := range r.Form["r"] {
+			if err := gh.applyTag(userID, id, normalizeStreamID(remove), false); err != nil {
+				gh.writeEditError(w, id, err)
+				return
+			}
+		}
+	}
+
+	writeOK(w)
+}
+
+// applyTag sets a single state tag on an article and triggers FreshRSS sync when needed.
+func (gh *Handler) applyTag(userID, id int64, tagID string, add bool) error {
+	var syncReq *sqlite.SyncRequest
+	var err error
+
+	switch tagID {
+	case streamRead:
+		syncReq, err = gh.core.DB.MarkArticleReadWithSyncForUser(userID, id, add)
+	case streamKeptUnread:
+		syncReq, err = gh.core.DB.MarkArticleReadWithSyncForUser(userID, id, !add)
+	case streamStarred:
+		a, getErr := gh.core.DB.GetArticleByIDForUser(userID, id)
+		if getErr != nil {
+			return getErr
+		}
+		if a == nil {
+			return sqlite.ErrArticleNotFound
+		}
+		if a.IsFavorite == add {
+			return nil
+		}
+		syncReq, err = gh.core.DB.ToggleFavoriteWithSyncForUser(userID, id)
+	default:
+		// Labels on individual items are not supported; ignore like other servers do
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if syncReq != nil {
+		go article.PerformImmediateSync(gh.core, syncReq)
+	}
+	return nil
+}
+
+// writeEditError maps edit errors to HTTP responses.
+func (gh *Handler) writeEditError(w http.ResponseWriter, id int64, err error) {
+	if errors.Is(err, sqlite.ErrArticleNotFound) {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	log.Printf("[GReader] edit-tag failed for article %d: %v", id, err)
+	http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+}
+
+// handleMarkAllAsRead marks every unread item of a stream as read.
+// Parameters: s (stream ID), ts (optional, only items older than this microsecond timestamp).
+func (gh *Handler) handleMarkAllAsRead(w http.ResponseWriter, r *http.Request) {
+	userID, _ := core.GetUserIDFromRequest(r)
+
+	var q sqlite.StreamQuery
+	if err := applyStreamID(&q, r.Form.Get("s")); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	if ts, err := strconv.ParseInt(r.Form.Get("ts"), 10, 64); err == nil && ts > 0 {
+		q.OlderThan = time.UnixMicro(ts)
+	}
+
+	syncReqs, err := gh.core.DB.MarkStreamAsReadWithSyncForUser(userID, q)
+	if err != nil {
+		log.Printf("[GReader] mark-all-as-read failed: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if len(syncReqs) > 0 {
+		go article.PerformImmediateBulkSync(gh.core, syncReqs)
+	}
+
+	writeOK(w)
+}
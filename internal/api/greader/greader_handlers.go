@@ -0,0 +1,193 @@
+// Package greader implements a Google Reader compatible API (the "greader.php" dialect
+// popularised by FreshRSS) so that third-party clients such as Reeder, FeedMe and
+// NetNewsWire can sync directly against MavenRSS in server mode.
+package greader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	"MavenRSS/internal/auth"
+	"MavenRSS/internal/middleware"
+)
+
+// Handler serves the Google Reader API.
+// It is mounted below /api/greader.php and performs its own authentication,
+// since GReader clients send "Authorization: GoogleLogin auth=<token>" instead of a Bearer token.
+type Handler struct {
+	core       *core.Handler
+	jwtManager *auth.JWTManager
+	mux        *http.ServeMux
+}
+
+// NewHandler creates a Google Reader API handler backed by the given core handler.
+func NewHandler(h *core.Handler, jwtManager *auth.JWTManager) *Handler {
+	gh := &Handler{
+		core:       h,
+		jwtManager: jwtManager,
+		mux:        http.NewServeMux(),
+	}
+
+	gh.mux.HandleFunc("/accounts/ClientLogin", gh.handleClientLogin)
+
+	gh.mux.HandleFunc("/reader/api/0/token", gh.requireAuth(gh.handleToken))
+	gh.mux.HandleFunc("/reader/api/0/user-info", gh.requireAuth(gh.handleUserInfo))
+	gh.mux.HandleFunc("/reader/api/0/subscription/list", gh.requireAuth(gh.handleSubscriptionList))
+	gh.mux.HandleFunc("/reader/api/0/tag/list", gh.requireAuth(gh.handleTagList))
+	gh.mux.HandleFunc("/reader/api/0/unread-count", gh.requireAuth(gh.handleUnreadCount))
+	gh.mux.HandleFunc("/reader/api/0/stream/items/ids", gh.requireAuth(gh.handleStreamItemIDs))
+	gh.mux.HandleFunc("/reader/api/0/stream/items/contents", gh.requireAuth(gh.handleStreamItemContents))
+	gh.mux.HandleFunc("/reader/api/0/stream/contents", gh.requireAuth(gh.handleStreamContents))
+	gh.mux.HandleFunc("/reader/api/0/stream/contents/{stream...}", gh.requireAuth(gh.handleStreamContents))
+	gh.mux.HandleFunc("/reader/api/0/edit-tag", gh.requireAuth(gh.requireActionToken(gh.handleEditTag)))
+	gh.mux.HandleFunc("/reader/api/0/mark-all-as-read", gh.requireAuth(gh.requireActionToken(gh.handleMarkAllAsRead)))
+
+	return gh
+}
+
+// ServeHTTP dispatches Google Reader API requests.
+func (gh *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	gh.mux.ServeHTTP(w, r)
+}
+
+// googleLoginToken extracts the token from an "Authorization: GoogleLogin auth=<token>" header.
+func googleLoginToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	const prefix = "GoogleLogin auth="
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+	return ""
+}
+
+// actionToken derives the short-lived "T" token that clients echo back on write requests.
+func actionToken(authToken string) string {
+	sum := sha256.Sum256([]byte("greader-action:" + authToken))
+	return hex.EncodeToString(sum[:])[:57]
+}
+
+// requireAuth validates the GoogleLogin token and stores the user claims in the request context.
+func (gh *Handler) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := googleLoginToken(r)
+		if token == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := gh.jwtManager.ValidateToken(token)
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), middleware.UserContextKey, claims)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// requireActionToken rejects write requests that carry a stale "T" token.
+// Requests without a T parameter are accepted, as not every client sends one.
+func (gh *Handler) requireActionToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		if t := r.Form.Get("T"); t != "" && t != actionToken(googleLoginToken(r)) {
+			w.Header().Set("X-Reader-Google-Bad-Token", "true")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleClientLogin authenticates a user with username (or email) and password
+// and returns a long-lived API token.
+func (gh *Handler) handleClientLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	login := r.Form.Get("Email")
+	password := r.Form.Get("Passwd")
+	if login == "" || password == "" {
+		http.Error(w, "Error=BadAuthentication", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := gh.core.DB.GetUserByUsername(login)
+	if err != nil {
+		user, err = gh.core.DB.GetUserByEmail(login)
+	}
+	if err != nil || user.Status != "active" || !auth.CheckPassword(password, user.PasswordHash) {
+		log.Printf("[GReader] ClientLogin failed for %q", login)
+		http.Error(w, "Error=BadAuthentication", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := gh.jwtManager.GenerateAPIToken(user.ID, user.Username, string(user.Role))
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Form.Get("output") == "json" {
+		response.JSON(w, map[string]string{"SID": token, "LSID": "null", "Auth": token})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "SID=%s\nLSID=null\nAuth=%s\n", token, token)
+}
+
+// handleToken returns the action token required by write requests.
+func (gh *Handler) handleToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, actionToken(googleLoginToken(r)))
+}
+
+// userInfo is the response of the user-info endpoint
+type userInfo struct {
+	UserID        string `json:"userId"`
+	UserName      string `json:"userName"`
+	UserProfileID string `json:"userProfileId"`
+	UserEmail     string `json:"userEmail"`
+}
+
+// handleUserInfo returns basic information about the authenticated user.
+func (gh *Handler) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	claims, _ := core.GetUserFromRequest(r)
+	user, err := gh.core.DB.GetUserByID(claims.UserID)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id := fmt.Sprintf("%d", user.ID)
+	response.JSON(w, userInfo{
+		UserID:        id,
+		UserName:      user.Username,
+		UserProfileID: id,
+		UserEmail:     user.Email,
+	})
+}
+
+// writeOK writes the plain-text "OK" acknowledgement used by write endpoints.
+func writeOK(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "OK")
+}
@@ -0,0 +1,231 @@
+package greader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/auth"
+	ff "MavenRSS/internal/feed"
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+func setupHandler(t *testing.T) (*Handler, int64) {
+	t.Helper()
+	db, err := sqlite.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+
+	hash, err := auth.HashPassword("secret-pass")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	userID, err := db.CreateUser(&models.User{
+		Username:     "reader",
+		Email:        "reader@example.com",
+		PasswordHash: hash,
+		Role:         models.RoleUser,
+		Status:       "active",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	h := core.NewHandler(db, ff.NewFetcher(db), nil, nil)
+	return NewHandler(h, auth.NewJWTManager("test-secret")), userID
+}
+
+func login(t *testing.T, gh *Handler) string {
+	t.Helper()
+	form := url.Values{"Email": {"reader"}, "Passwd": {"secret-pass"}}
+	req := httptest.NewRequest(http.MethodPost, "/accounts/ClientLogin", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	gh.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ClientLogin: expected 200, got %d", w.Code)
+	}
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if strings.HasPrefix(line, "Auth=") {
+			return strings.TrimPrefix(line, "Auth=")
+		}
+	}
+	t.Fatalf("ClientLogin: no Auth token in %q", w.Body.String())
+	return ""
+}
+
+func TestParseItemID(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want int64
+	}{
+		{"42", 42},
+		{"tag:google.com,2005:reader/item/000000000000002a", 42},
+		{formatItemID(123456), 123456},
+	}
+	for _, tt := range tests {
+		got, err := parseItemID(tt.raw)
+		if err != nil {
+			t.Fatalf("parseItemID(%q) error: %v", tt.raw, err)
+		}
+		if got != tt.want {
+			t.Errorf("parseItemID(%q) = %d, want %d", tt.raw, got, tt.want)
+		}
+	}
+
+	if _, err := parseItemID("tag:google.com,2005:reader/item/xyz"); err == nil {
+		t.Error("expected error for invalid hex item id")
+	}
+}
+
+func TestBuildStreamQuery(t *testing.T) {
+	form := url.Values{
+		"xt": {"user/1001/state/com.google/read"},
+		"n":  {"50"},
+		"r":  {"o"},
+		"c":  {"100"},
+	}
+	q, err := buildStreamQuery("user/-/label/Tech", form, maxStreamContents)
+	if err != nil {
+		t.Fatalf("buildStreamQuery error: %v", err)
+	}
+	if q.Category != "Tech" || !q.ExcludeRead || q.Limit != 50 || !q.OldestFirst || q.Offset != 100 {
+		t.Errorf("unexpected query: %+v", q)
+	}
+
+	// ot returns the items newer than a time, nt the items older than a time
+	q, err = buildStreamQuery("user/-/state/com.google/reading-list", url.Values{"ot": {"1700000000"}, "nt": {"1700086400"}}, maxStreamContents)
+	if err != nil {
+		t.Fatalf("buildStreamQuery error: %v", err)
+	}
+	if q.NewerThan.Unix() != 1700000000 || q.OlderThan.Unix() != 1700086400 {
+		t.Errorf("unexpected time range: newer than %v, older than %v", q.NewerThan, q.OlderThan)
+	}
+
+	if _, err := buildStreamQuery("feed/abc", url.Values{}, maxStreamContents); err == nil {
+		t.Error("expected error for invalid feed stream")
+	}
+}
+
+func TestClientLogin_BadPassword(t *testing.T) {
+	gh, _ := setupHandler(t)
+
+	form := url.Values{"Email": {"reader"}, "Passwd": {"wrong"}}
+	req := httptest.NewRequest(http.MethodPost, "/accounts/ClientLogin", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	gh.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+}
+
+func TestStreamAndEditTag(t *testing.T) {
+	gh, userID := setupHandler(t)
+	db := gh.core.DB
+
+	feedID, err := db.AddFeedForUser(userID, &models.Feed{Title: "Feed", URL: "http://example.com/rss", Category: "Tech"})
+	if err != nil {
+		t.Fatalf("AddFeedForUser: %v", err)
+	}
+	articles := []*models.Article{
+		{UserID: userID, FeedID: feedID, Title: "first", URL: "http://example.com/1", PublishedAt: time.Now().Add(-time.Hour)},
+		{UserID: userID, FeedID: feedID, Title: "second", URL: "http://example.com/2", PublishedAt: time.Now()},
+	}
+	if err := db.SaveArticles(context.Background(), articles); err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+
+	token := login(t, gh)
+	do := func(method, target string, form url.Values) *httptest.ResponseRecorder {
+		var req *http.Request
+		if form != nil {
+			req = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			req = httptest.NewRequest(method, target, nil)
+		}
+		req.Header.Set("Authorization", "GoogleLogin auth="+token)
+		w := httptest.NewRecorder()
+		gh.ServeHTTP(w, req)
+		return w
+	}
+
+	// Unread IDs in the label stream
+	w := do(http.MethodGet, "/reader/api/0/stream/items/ids?s=user/-/label/Tech&xt=user/-/state/com.google/read&n=10", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("stream/items/ids: expected 200, got %d", w.Code)
+	}
+	var ids struct {
+		ItemRefs []itemRef `json:"itemRefs"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&ids); err != nil {
+		t.Fatalf("decode ids: %v", err)
+	}
+	if len(ids.ItemRefs) != 2 {
+		t.Fatalf("expected 2 unread items, got %d", len(ids.ItemRefs))
+	}
+
+	// Mark the newest as read and starred
+	tok := do(http.MethodGet, "/reader/api/0/token", nil).Body.String()
+	w = do(http.MethodPost, "/reader/api/0/edit-tag", url.Values{
+		"i": {ids.ItemRefs[0].ID},
+		"a": {"user/-/state/com.google/read", "user/-/state/com.google/starred"},
+		"T": {strings.TrimSpace(tok)},
+	})
+	if w.Code != http.StatusOK || w.Body.String() != "OK" {
+		t.Fatalf("edit-tag: expected 200 OK, got %d %q", w.Code, w.Body.String())
+	}
+
+	// A stale action token is rejected
+	w = do(http.MethodPost, "/reader/api/0/edit-tag", url.Values{"i": {ids.ItemRefs[1].ID}, "a": {streamRead}, "T": {"stale"}})
+	if w.Code != http.StatusUnauthorized || w.Header().Get("X-Reader-Google-Bad-Token") != "true" {
+		t.Fatalf("expected bad token rejection, got %d", w.Code)
+	}
+
+	// Starred stream contents contain exactly the edited item
+	w = do(http.MethodGet, "/reader/api/0/stream/contents/user/-/state/com.google/starred", nil)
+	var contents streamContents
+	if err := json.NewDecoder(w.Body).Decode(&contents); err != nil {
+		t.Fatalf("decode contents: %v", err)
+	}
+	if len(contents.Items) != 1 || contents.Items[0].Title != "second" {
+		t.Fatalf("unexpected starred items: %+v", contents.Items)
+	}
+
+	// Mark the rest of the feed as read
+	w = do(http.MethodPost, "/reader/api/0/mark-all-as-read", url.Values{"s": {feedStreamID(feedID)}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("mark-all-as-read: expected 200, got %d", w.Code)
+	}
+	counts, err := db.GetUnreadCountsForAllFeeds(userID)
+	if err != nil {
+		t.Fatalf("GetUnreadCountsForAllFeeds: %v", err)
+	}
+	if counts[feedID] != 0 {
+		t.Errorf("expected no unread articles, got %d", counts[feedID])
+	}
+}
+
+func TestRequireAuth(t *testing.T) {
+	gh, _ := setupHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/reader/api/0/subscription/list", nil)
+	req.Header.Set("Authorization", "GoogleLogin auth=invalid")
+	w := httptest.NewRecorder()
+	gh.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+}
@@ -0,0 +1,423 @@
+package greader
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	"MavenRSS/internal/models"
+)
+
+// category is a label reference attached to subscriptions
+type category struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+// subscription describes a feed in the subscription list
+type subscription struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Categories []category `json:"categories"`
+	URL        string     `json:"url"`
+	HTMLURL    string     `json:"htmlUrl"`
+	IconURL    string     `json:"iconUrl"`
+}
+
+// tag describes a state or label in the tag list
+type tag struct {
+	ID   string `json:"id"`
+	Type string `json:"type,omitempty"`
+}
+
+// unreadCount is a single entry of the unread-count response
+type unreadCount struct {
+	ID                      string `json:"id"`
+	Count                   int    `json:"count"`
+	NewestItemTimestampUsec string `json:"newestItemTimestampUsec"`
+}
+
+// itemRef is a single entry of the stream/items/ids response
+type itemRef struct {
+	ID              string   `json:"id"`
+	DirectStreamIDs []string `json:"directStreamIds"`
+	TimestampUsec   string   `json:"timestampUsec"`
+}
+
+// link is an href entry in stream items
+type link struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+// itemOrigin identifies the feed an item belongs to
+type itemOrigin struct {
+	StreamID string `json:"streamId"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"htmlUrl"`
+}
+
+// itemContent holds the HTML body of an item
+type itemContent struct {
+	Direction string `json:"direction"`
+	Content   string `json:"content"`
+}
+
+// item is a single entry in stream contents
+type item struct {
+	ID            string      `json:"id"`
+	CrawlTimeMsec string      `json:"crawlTimeMsec"`
+	TimestampUsec string      `json:"timestampUsec"`
+	Published     int64       `json:"published"`
+	Updated       int64       `json:"updated"`
+	Title         string      `json:"title"`
+	Author        string      `json:"author,omitempty"`
+	Canonical     []link      `json:"canonical"`
+	Alternate     []link      `json:"alternate"`
+	Enclosure     []link      `json:"enclosure,omitempty"`
+	Categories    []string    `json:"categories"`
+	Origin        itemOrigin  `json:"origin"`
+	Summary       itemContent `json:"summary"`
+}
+
+// streamContents is the response of the stream/contents endpoints
+type streamContents struct {
+	ID           string `json:"id"`
+	Updated      int64  `json:"updated"`
+	Items        []item `json:"items"`
+	Continuation string `json:"continuation,omitempty"`
+}
+
+// feedCategories returns the label references for a feed category.
+func feedCategories(f *models.Feed) []category {
+	if f == nil || f.Category == "" {
+		return []category{}
+	}
+	return []category{{ID: labelStreamID(f.Category), Label: f.Category}}
+}
+
+// feedsByID loads the user's feeds keyed by ID.
+func (gh *Handler) feedsByID(userID int64) (map[int64]*models.Feed, []models.Feed, error) {
+	feeds, err := gh.core.DB.GetFeedsForUser(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[int64]*models.Feed, len(feeds))
+	for i := range feeds {
+		byID[feeds[i].ID] = &feeds[i]
+	}
+	return byID, feeds, nil
+}
+
+// handleSubscriptionList returns the user's feeds.
+func (gh *Handler) handleSubscriptionList(w http.ResponseWriter, r *http.Request) {
+	userID, _ := core.GetUserIDFromRequest(r)
+	_, feeds, err := gh.feedsByID(userID)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	subs := make([]subscription, 0, len(feeds))
+	for i := range feeds {
+		f := &feeds[i]
+		subs = append(subs, subscription{
+			ID:         feedStreamID(f.ID),
+			Title:      f.Title,
+			Categories: feedCategories(f),
+			URL:        f.URL,
+			HTMLURL:    f.Link,
+			IconURL:    f.ImageURL,
+		})
+	}
+
+	response.JSON(w, map[string]interface{}{"subscriptions": subs})
+}
+
+// handleTagList returns the starred state and every category as a folder.
+func (gh *Handler) handleTagList(w http.ResponseWriter, r *http.Request) {
+	userID, _ := core.GetUserIDFromRequest(r)
+	_, feeds, err := gh.feedsByID(userID)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	tags := []tag{{ID: streamStarred}}
+	seen := make(map[string]bool)
+	var categories []string
+	for _, f := range feeds {
+		if f.Category != "" && !seen[f.Category] {
+			seen[f.Category] = true
+			categories = append(categories, f.Category)
+		}
+	}
+	sort.Strings(categories)
+	for _, c := range categories {
+		tags = append(tags, tag{ID: labelStreamID(c), Type: "folder"})
+	}
+
+	response.JSON(w, map[string]interface{}{"tags": tags})
+}
+
+// handleUnreadCount returns unread counts per feed, per label and for the reading list.
+func (gh *Handler) handleUnreadCount(w http.ResponseWriter, r *http.Request) {
+	userID, _ := core.GetUserIDFromRequest(r)
+	_, feeds, err := gh.feedsByID(userID)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	counts, err := gh.core.DB.GetUnreadCountsForAllFeeds(userID)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	var total int
+	var newest time.Time
+	labelCounts := make(map[string]int)
+	labelNewest := make(map[string]time.Time)
+	result := []unreadCount{}
+
+	for _, f := range feeds {
+		count := counts[f.ID]
+		if count == 0 {
+			continue
+		}
+		var latest time.Time
+		if f.LatestArticleTime != nil {
+			latest = *f.LatestArticleTime
+		}
+		result = append(result, unreadCount{
+			ID:                      feedStreamID(f.ID),
+			Count:                   count,
+			NewestItemTimestampUsec: usec(latest),
+		})
+		total += count
+		if latest.After(newest) {
+			newest = latest
+		}
+		if f.Category != "" {
+			labelCounts[f.Category] += count
+			if latest.After(labelNewest[f.Category]) {
+				labelNewest[f.Category] = latest
+			}
+		}
+	}
+
+	for label, count := range labelCounts {
+		result = append(result, unreadCount{
+			ID:                      labelStreamID(label),
+			Count:                   count,
+			NewestItemTimestampUsec: usec(labelNewest[label]),
+		})
+	}
+	result = append(result, unreadCount{
+		ID:                      streamReadingList,
+		Count:                   total,
+		NewestItemTimestampUsec: usec(newest),
+	})
+
+	response.JSON(w, map[string]interface{}{
+		"max":          total,
+		"unreadcounts": result,
+	})
+}
+
+// handleStreamItemIDs returns the IDs of items in a stream.
+func (gh *Handler) handleStreamItemIDs(w http.ResponseWriter, r *http.Request) {
+	userID, _ := core.GetUserIDFromRequest(r)
+	_ = r.ParseForm()
+
+	q, err := buildStreamQuery(r.Form.Get("s"), r.Form, maxStreamIDs)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	refs, err := gh.core.DB.GetStreamItemRefsForUser(userID, q)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	itemRefs := make([]itemRef, 0, len(refs))
+	for _, ref := range refs {
+		itemRefs = append(itemRefs, itemRef{
+			ID:              strconv.FormatInt(ref.ID, 10),
+			DirectStreamIDs: []string{},
+			TimestampUsec:   usec(ref.PublishedAt),
+		})
+	}
+
+	resp := map[string]interface{}{"itemRefs": itemRefs}
+	if len(refs) == q.Limit {
+		resp["continuation"] = strconv.Itoa(q.Offset + len(refs))
+	}
+	response.JSON(w, resp)
+}
+
+// handleStreamContents returns full items for a stream given in the path or the "s" parameter.
+func (gh *Handler) handleStreamContents(w http.ResponseWriter, r *http.Request) {
+	userID, _ := core.GetUserIDFromRequest(r)
+	_ = r.ParseForm()
+
+	streamID := r.PathValue("stream")
+	if streamID == "" {
+		streamID = r.Form.Get("s")
+	}
+	if streamID == "" {
+		streamID = streamReadingList
+	}
+
+	q, err := buildStreamQuery(streamID, r.Form, maxStreamContents)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	refs, err := gh.core.DB.GetStreamItemRefsForUser(userID, q)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	ids := make([]int64, len(refs))
+	for i, ref := range refs {
+		ids[i] = ref.ID
+	}
+	items, err := gh.buildItems(userID, ids)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	resp := streamContents{
+		ID:      normalizeStreamID(streamID),
+		Updated: time.Now().Unix(),
+		Items:   items,
+	}
+	if len(refs) == q.Limit {
+		resp.Continuation = strconv.Itoa(q.Offset + len(refs))
+	}
+	response.JSON(w, resp)
+}
+
+// handleStreamItemContents returns full items for an explicit list of item IDs ("i" parameters).
+func (gh *Handler) handleStreamItemContents(w http.ResponseWriter, r *http.Request) {
+	userID, _ := core.GetUserIDFromRequest(r)
+	_ = r.ParseForm()
+
+	ids, err := gh.core.DB.FilterArticleIDsForUser(userID, parseItemIDs(r.Form["i"]))
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	items, err := gh.buildItems(userID, ids)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, streamContents{
+		ID:      streamReadingList,
+		Updated: time.Now().Unix(),
+		Items:   items,
+	})
+}
+
+// buildItems loads articles, cached contents and feeds and converts them to stream items.
+// The order of ids is preserved.
+func (gh *Handler) buildItems(userID int64, ids []int64) ([]item, error) {
+	items := make([]item, 0, len(ids))
+	if len(ids) == 0 {
+		return items, nil
+	}
+
+	articles, err := gh.core.DB.GetArticlesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := gh.core.DB.GetArticleContentsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	feeds, _, err := gh.feedsByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*models.Article, len(articles))
+	for i := range articles {
+		byID[articles[i].ID] = &articles[i]
+	}
+
+	for _, id := range ids {
+		a, ok := byID[id]
+		if !ok {
+			continue
+		}
+		items = append(items, toItem(a, feeds[a.FeedID], contents[id]))
+	}
+	return items, nil
+}
+
+// toItem converts an article to a Google Reader item.
+func toItem(a *models.Article, f *models.Feed, content string) item {
+	categories := []string{streamReadingList}
+	if a.IsRead {
+		categories = append(categories, streamRead)
+	}
+	if a.IsFavorite {
+		categories = append(categories, streamStarred)
+	}
+
+	origin := itemOrigin{StreamID: feedStreamID(a.FeedID), Title: a.FeedTitle}
+	if f != nil {
+		origin.HTMLURL = f.Link
+		if f.Category != "" {
+			categories = append(categories, labelStreamID(f.Category))
+		}
+	}
+
+	if content == "" {
+		content = a.Summary
+	}
+
+	var enclosures []link
+	if a.ImageURL != "" {
+		enclosures = append(enclosures, link{Href: a.ImageURL, Type: "image/*"})
+	}
+	if a.AudioURL != "" {
+		enclosures = append(enclosures, link{Href: a.AudioURL, Type: "audio/*"})
+	}
+
+	return item{
+		ID:            formatItemID(a.ID),
+		CrawlTimeMsec: strconv.FormatInt(a.PublishedAt.UnixMilli(), 10),
+		TimestampUsec: usec(a.PublishedAt),
+		Published:     a.PublishedAt.Unix(),
+		Updated:       a.PublishedAt.Unix(),
+		Title:         a.Title,
+		Author:        a.Author,
+		Canonical:     []link{{Href: a.URL}},
+		Alternate:     []link{{Href: a.URL, Type: "text/html"}},
+		Enclosure:     enclosures,
+		Categories:    categories,
+		Origin:        origin,
+		Summary:       itemContent{Direction: "ltr", Content: content},
+	}
+}
+
+// usec formats a time as a microsecond timestamp string.
+func usec(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixMicro(), 10)
+}
@@ -0,0 +1,159 @@
+package greader
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/store/sqlite"
+)
+
+// Well-known Google Reader stream and tag identifiers
+const (
+	streamReadingList = "user/-/state/com.google/reading-list"
+	streamRead        = "user/-/state/com.google/read"
+	streamStarred     = "user/-/state/com.google/starred"
+	streamKeptUnread  = "user/-/state/com.google/kept-unread"
+	labelPrefix       = "user/-/label/"
+	feedPrefix        = "feed/"
+	itemIDPrefix      = "tag:google.com,2005:reader/item/"
+)
+
+// Page size limits for stream requests
+const (
+	defaultStreamItems = 20
+	maxStreamContents  = 1000
+	maxStreamIDs       = 10000
+)
+
+// userIDPattern matches the user segment of a stream ID ("user/1001/..." or "user/-/...")
+var userIDPattern = regexp.MustCompile(`^user/[^/]+/`)
+
+// normalizeStreamID rewrites user-specific stream IDs to the "user/-/" form.
+func normalizeStreamID(streamID string) string {
+	return userIDPattern.ReplaceAllString(streamID, "user/-/")
+}
+
+// formatItemID returns the long form item ID used in stream contents.
+func formatItemID(id int64) string {
+	return fmt.Sprintf("%s%016x", itemIDPrefix, uint64(id))
+}
+
+// parseItemID accepts both the long hexadecimal form and the short decimal form.
+func parseItemID(raw string) (int64, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, itemIDPrefix) {
+		id, err := strconv.ParseUint(strings.TrimPrefix(raw, itemIDPrefix), 16, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid item id %q", raw)
+		}
+		return int64(id), nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid item id %q", raw)
+	}
+	return id, nil
+}
+
+// parseItemIDs parses a list of item IDs, skipping invalid entries.
+func parseItemIDs(raw []string) []int64 {
+	ids := make([]int64, 0, len(raw))
+	for _, r := range raw {
+		if id, err := parseItemID(r); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// feedStreamID returns the stream ID for a feed.
+func feedStreamID(feedID int64) string {
+	return feedPrefix + strconv.FormatInt(feedID, 10)
+}
+
+// labelStreamID returns the stream ID for a category label.
+func labelStreamID(category string) string {
+	return labelPrefix + category
+}
+
+// applyStreamID narrows a stream query to the given stream.
+func applyStreamID(q *sqlite.StreamQuery, streamID string) error {
+	streamID = normalizeStreamID(streamID)
+	switch {
+	case streamID == "" || streamID == streamReadingList:
+		// All articles
+	case streamID == streamStarred:
+		q.OnlyStarred = true
+	case streamID == streamRead:
+		q.OnlyRead = true
+	case streamID == streamKeptUnread:
+		q.ExcludeRead = true
+	case strings.HasPrefix(streamID, labelPrefix):
+		q.Category = strings.TrimPrefix(streamID, labelPrefix)
+		if q.Category == "" {
+			return fmt.Errorf("empty label in stream %q", streamID)
+		}
+	case strings.HasPrefix(streamID, feedPrefix):
+		feedID, err := strconv.ParseInt(strings.TrimPrefix(streamID, feedPrefix), 10, 64)
+		if err != nil || feedID <= 0 {
+			return fmt.Errorf("unknown feed stream %q", streamID)
+		}
+		q.FeedID = feedID
+	default:
+		return fmt.Errorf("unsupported stream %q", streamID)
+	}
+	return nil
+}
+
+// buildStreamQuery translates the common stream request parameters
+// (s, xt, it, n, r, ot, nt, c) into a StreamQuery.
+func buildStreamQuery(streamID string, form url.Values, maxItems int) (sqlite.StreamQuery, error) {
+	var q sqlite.StreamQuery
+	if err := applyStreamID(&q, streamID); err != nil {
+		return q, err
+	}
+
+	for _, xt := range form["xt"] {
+		switch normalizeStreamID(xt) {
+		case streamRead:
+			q.ExcludeRead = true
+		case streamStarred:
+			q.ExcludeStar = true
+		}
+	}
+	for _, it := range form["it"] {
+		switch normalizeStreamID(it) {
+		case streamRead:
+			q.OnlyRead = true
+		case streamStarred:
+			q.OnlyStarred = true
+		}
+	}
+
+	q.Limit = defaultStreamItems
+	if n, err := strconv.Atoi(form.Get("n")); err == nil && n > 0 {
+		q.Limit = n
+	}
+	if q.Limit > maxItems {
+		q.Limit = maxItems
+	}
+
+	q.OldestFirst = form.Get("r") == "o"
+
+	// ot is the oldest time of the items returned, nt the newest one
+	if ot, err := strconv.ParseInt(form.Get("ot"), 10, 64); err == nil && ot > 0 {
+		q.NewerThan = time.Unix(ot, 0)
+	}
+	if nt, err := strconv.ParseInt(form.Get("nt"), 10, 64); err == nil && nt > 0 {
+		q.OlderThan = time.Unix(nt, 0)
+	}
+	if c, err := strconv.Atoi(form.Get("c")); err == nil && c > 0 {
+		q.Offset = c
+	}
+
+	return q, nil
+}
@@ -144,6 +144,11 @@ func HandleOPMLImport(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 				f.XPathItemAuthor, f.XPathItemTimestamp, f.XPathItemTimeFormat,
 				f.XPathItemThumbnail, f.XPathItemCategories, f.XPathItemUid,
 			)
+		} else if f.Type == models.FeedTypeJSONPath {
+			// JSON APIs use the same FreshRSS attributes for their JSONPath expressions
+			feedID, err = h.Fetcher.AddJSONPathSubscription(context.Background(), &f)
+		} else if f.Type == models.FeedTypeWatch {
+			feedID, err = h.Fetcher.AddWatchSubscription(&f)
 		} else {
 			feedID, err = h.Fetcher.ImportSubscription(f.Title, f.URL, f.Category)
 		}
@@ -344,6 +349,11 @@ func HandleOPMLImportDialog(h *core.Handler, w http.ResponseWriter, r *http.Requ
 				f.XPathItemAuthor, f.XPathItemTimestamp, f.XPathItemTimeFormat,
 				f.XPathItemThumbnail, f.XPathItemCategories, f.XPathItemUid,
 			)
+		} else if f.Type == models.FeedTypeJSONPath {
+			// JSON APIs use the same FreshRSS attributes for their JSONPath expressions
+			feedID, err = h.Fetcher.AddJSONPathSubscription(context.Background(), &f)
+		} else if f.Type == models.FeedTypeWatch {
+			feedID, err = h.Fetcher.AddWatchSubscription(&f)
 		} else {
 			feedID, err = h.Fetcher.ImportSubscription(f.Title, f.URL, f.Category)
 		}
@@ -0,0 +1,340 @@
+// Package output publishes curated streams of a user's articles as Atom,
+// RSS 2.0 and JSON Feed documents at unguessable URLs, so they can be shared
+// with people who don't have an account.
+package output
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"MavenRSS/internal/api/article"
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/query"
+	"MavenRSS/internal/store/sqlite"
+)
+
+const (
+	defaultMaxItems = 50
+	maxMaxItems     = 500
+	// Number of recent articles selected by the SQL prefilter of a saved filter
+	savedFilterScanLimit = 5000
+)
+
+// HandleOutputFeeds manages the output feeds of the current user.
+// @Summary      Manage output feeds
+// @Description  GET lists output feeds, POST creates one, PUT regenerates its token (?id=), DELETE removes it (?id=)
+// @Tags         output
+// @Accept       json
+// @Produce      json
+// @Param        id       query     int                 false  "Output feed ID (PUT, DELETE)"
+// @Param        request  body      models.OutputFeed   false  "Output feed definition (POST)"
+// @Success      200  {array}   models.OutputFeed  "Output feeds"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid source)"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      404  {object}  map[string]string  "Output feed not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /output-feeds [get]
+func HandleOutputFeeds(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	claims, ok := core.GetUserFromRequest(r)
+	if !ok {
+		response.Error(w, fmt.Errorf("unauthorized"), http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		feeds, err := h.DB.GetOutputFeedsForUser(claims.UserID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, feeds)
+
+	case http.MethodPost:
+		var req models.OutputFeed
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := validateSource(h, &req); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if req.MaxItems <= 0 {
+			req.MaxItems = defaultMaxItems
+		}
+		if req.MaxItems > maxMaxItems {
+			req.MaxItems = maxMaxItems
+		}
+		token, err := generateToken()
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		req.ID = 0
+		req.UserID = claims.UserID
+		req.Token = token
+		id, err := h.DB.CreateOutputFeed(&req)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		req.ID = id
+		response.JSON(w, req)
+
+	case http.MethodPut, http.MethodDelete:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			response.Error(w, fmt.Errorf("invalid output feed id"), http.StatusBadRequest)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			err = h.DB.DeleteOutputFeed(claims.UserID, id)
+		} else {
+			var token string
+			if token, err = generateToken(); err == nil {
+				err = h.DB.UpdateOutputFeedToken(claims.UserID, id, token)
+			}
+		}
+		if err == sql.ErrNoRows {
+			response.Error(w, fmt.Errorf("output feed not found"), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		feeds, err := h.DB.GetOutputFeedsForUser(claims.UserID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, feeds)
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleOutputFeed serves an output feed in the requested format.
+// The token in the URL is the only credential, so unknown tokens get a plain 404.
+// @Summary      Get an output feed
+// @Description  Render an output feed as Atom, RSS 2.0 or JSON Feed 1.1
+// @Tags         output
+// @Produce      xml
+// @Produce      json
+// @Param        token   path  string  true  "Output feed token"
+// @Param        format  path  string  true  "Feed format (atom, rss, json)"
+// @Success      200  {string}  string  "Feed document"
+// @Failure      404  {string}  string  "Not found"
+// @Router       /output/{token}/{format} [get]
+func HandleOutputFeed(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.PathValue("format")
+	render, ok := renderers[format]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	feed, err := h.DB.GetOutputFeedByToken(r.PathValue("token"))
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if feed == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	articles, err := selectArticles(h, feed)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	ids := make([]int64, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+	}
+	contents, err := h.DB.GetArticleContentsByIDs(ids)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]item, len(articles))
+	for i, a := range articles {
+		items[i] = newItem(feed, a, contents[a.ID])
+	}
+
+	doc := document{
+		Title:   feedTitle(feed),
+		SelfURL: requestBaseURL(r) + "/api/output/" + feed.Token + "/" + format,
+		Items:   items,
+	}
+	body, contentType, err := render(doc)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "max-age=300")
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(body)
+}
+
+// validateSource checks that the source of an output feed exists
+func validateSource(h *core.Handler, f *models.OutputFeed) error {
+	switch f.SourceType {
+	case sqlite.OutputSourceFavorites, sqlite.OutputSourceReadLater:
+		f.SourceValue = ""
+		return nil
+
+	case sqlite.OutputSourceCategory:
+		f.SourceValue = strings.TrimSpace(f.SourceValue)
+		if f.SourceValue == "" {
+			return fmt.Errorf("category is required")
+		}
+		return nil
+
+	case sqlite.OutputSourceTag:
+		id, err := strconv.ParseInt(f.SourceValue, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid tag id")
+		}
+		tag, err := h.DB.GetTagByID(id)
+		if err != nil {
+			return err
+		}
+		if tag == nil {
+			return fmt.Errorf("tag not found")
+		}
+		return nil
+
+	case sqlite.OutputSourceSavedFilter:
+		id, err := strconv.ParseInt(f.SourceValue, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid saved filter id")
+		}
+		filter, err := h.DB.GetSavedFilterByID(id)
+		if err != nil {
+			return err
+		}
+		if filter == nil {
+			return fmt.Errorf("saved filter not found")
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown source type %q", f.SourceType)
+	}
+}
+
+// selectArticles returns the articles of an output feed, newest first
+func selectArticles(h *core.Handler, f *models.OutputFeed) ([]models.Article, error) {
+	limit := f.MaxItems
+	if limit <= 0 {
+		limit = defaultMaxItems
+	}
+
+	switch f.SourceType {
+	case sqlite.OutputSourceFavorites:
+		return h.DB.GetArticlesForUser(f.UserID, "favorites", 0, "", false, false, limit, 0)
+
+	case sqlite.OutputSourceReadLater:
+		return h.DB.GetArticlesForUser(f.UserID, "readLater", 0, "", false, false, limit, 0)
+
+	case sqlite.OutputSourceCategory:
+		return h.DB.GetArticlesForUser(f.UserID, "", 0, f.SourceValue, false, false, limit, 0)
+
+	case sqlite.OutputSourceTag:
+		tagID, _ := strconv.ParseInt(f.SourceValue, 10, 64)
+		ids, err := h.DB.GetArticleIDsByTagForUser(f.UserID, tagID, limit)
+		if err != nil {
+			return nil, err
+		}
+		articles, err := h.DB.GetArticlesByIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(articles, func(i, j int) bool {
+			return articles[i].PublishedAt.After(articles[j].PublishedAt)
+		})
+		return articles, nil
+
+	case sqlite.OutputSourceSavedFilter:
+		filterID, _ := strconv.ParseInt(f.SourceValue, 10, 64)
+		filter, err := h.DB.GetSavedFilterByID(filterID)
+		if err != nil || filter == nil {
+			// A deleted filter leaves an empty feed rather than a broken one
+			return []models.Article{}, err
+		}
+		expr, err := query.ParseStored(filter.Conditions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid saved filter conditions: %w", err)
+		}
+		articles, err := article.QueryArticles(h, expr, f.UserID, false, savedFilterScanLimit)
+		if err != nil {
+			return nil, err
+		}
+		if len(articles) > limit {
+			articles = articles[:limit]
+		}
+		return articles, nil
+	}
+
+	return []models.Article{}, nil
+}
+
+// feedTitle returns the title of an output feed, falling back to its source
+func feedTitle(f *models.OutputFeed) string {
+	if f.Title != "" {
+		return f.Title
+	}
+	switch f.SourceType {
+	case sqlite.OutputSourceFavorites:
+		return "Favorites"
+	case sqlite.OutputSourceReadLater:
+		return "Read Later"
+	case sqlite.OutputSourceCategory:
+		return f.SourceValue
+	}
+	return "MavenRSS"
+}
+
+// requestBaseURL returns the scheme and host the request was made to
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// generateToken returns a random token for output feed URLs
+func generateToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
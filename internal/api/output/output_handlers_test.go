@@ -0,0 +1,175 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/auth"
+	ff "MavenRSS/internal/feed"
+	"MavenRSS/internal/middleware"
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+func setupHandler(t *testing.T) *core.Handler {
+	t.Helper()
+	db, err := sqlite.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	return core.NewHandler(db, ff.NewFetcher(db), nil, nil)
+}
+
+func seedArticles(t *testing.T, h *core.Handler) []models.Article {
+	t.Helper()
+	feedID, err := h.DB.AddFeed(&models.Feed{Title: "Blog", URL: "http://example.com/rss", Category: "Tech"})
+	if err != nil {
+		t.Fatalf("AddFeed: %v", err)
+	}
+	articles := []*models.Article{
+		{FeedID: feedID, Title: "Older", URL: "http://example.com/1", PublishedAt: time.Now().Add(-time.Hour)},
+		{FeedID: feedID, Title: "Newer", URL: "http://example.com/2", PublishedAt: time.Now(), TranslatedTitle: "Plus récent", Summary: "<p>Short summary</p>"},
+	}
+	if err := h.DB.SaveArticles(context.Background(), articles); err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+	saved, err := h.DB.GetArticles("", feedID, "", false, 10, 0)
+	if err != nil || len(saved) != 2 {
+		t.Fatalf("expected 2 articles, got %d (%v)", len(saved), err)
+	}
+	return saved
+}
+
+func getOutput(h *core.Handler, token, format string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/output/"+token+"/"+format, nil)
+	req.SetPathValue("token", token)
+	req.SetPathValue("format", format)
+	w := httptest.NewRecorder()
+	HandleOutputFeed(h, w, req)
+	return w
+}
+
+func TestHandleOutputFeeds_Manage(t *testing.T) {
+	h := setupHandler(t)
+	ctx := context.WithValue(context.Background(), middleware.UserContextKey, &auth.Claims{UserID: 1, Username: "admin"})
+
+	call := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body)).WithContext(ctx)
+		w := httptest.NewRecorder()
+		HandleOutputFeeds(h, w, req)
+		return w
+	}
+
+	if w := call(http.MethodPost, "/api/output-feeds", `{"source_type":"tag","source_value":"42"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown tag to be rejected, got %d", w.Code)
+	}
+
+	w := call(http.MethodPost, "/api/output-feeds", `{"title":"Tech","source_type":"category","source_value":"Tech","max_items":1000}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	var created models.OutputFeed
+	json.NewDecoder(w.Body).Decode(&created)
+	if created.ID == 0 || len(created.Token) != 48 || created.UserID != 1 || created.MaxItems != maxMaxItems {
+		t.Fatalf("unexpected output feed %+v", created)
+	}
+
+	// Regenerating the token revokes the old URL
+	id := strconv.FormatInt(created.ID, 10)
+	if w := call(http.MethodPut, "/api/output-feeds?id="+id, ""); w.Code != http.StatusOK {
+		t.Fatalf("regenerate: %d", w.Code)
+	}
+	if w := getOutput(h, created.Token, "atom"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected old token to be revoked, got %d", w.Code)
+	}
+
+	if w := call(http.MethodDelete, "/api/output-feeds?id="+id, ""); w.Code != http.StatusOK {
+		t.Fatalf("delete: %d", w.Code)
+	}
+	if w := call(http.MethodDelete, "/api/output-feeds?id="+id, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for deleted feed, got %d", w.Code)
+	}
+}
+
+func TestHandleOutputFeed_Formats(t *testing.T) {
+	h := setupHandler(t)
+	articles := seedArticles(t, h)
+	for _, a := range articles {
+		if err := h.DB.SetArticleFavoriteForUser(1, a.ID, true); err != nil {
+			t.Fatalf("SetArticleFavoriteForUser: %v", err)
+		}
+	}
+
+	feed := &models.OutputFeed{UserID: 1, Token: "tok", SourceType: sqlite.OutputSourceFavorites, IncludeTranslations: true, IncludeSummaries: true, MaxItems: 10}
+	if _, err := h.DB.CreateOutputFeed(feed); err != nil {
+		t.Fatalf("CreateOutputFeed: %v", err)
+	}
+
+	w := getOutput(h, "tok", "atom")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/atom+xml") {
+		t.Fatalf("atom: %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	var atom atomFeed
+	if err := xml.Unmarshal(w.Body.Bytes(), &atom); err != nil {
+		t.Fatalf("invalid atom: %v", err)
+	}
+	if atom.Title != "Favorites" || len(atom.Entries) != 2 || atom.Entries[0].Title != "Plus récent" ||
+		atom.Entries[0].Summary == nil || atom.Entries[0].Summary.Body != "Short summary" {
+		t.Fatalf("unexpected atom feed: %s", w.Body.String())
+	}
+
+	w = getOutput(h, "tok", "rss")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<guid isPermaLink=\"true\">http://example.com/2</guid>") {
+		t.Fatalf("unexpected rss feed: %s", w.Body.String())
+	}
+
+	w = getOutput(h, "tok", "json")
+	var jf jsonFeed
+	if err := json.Unmarshal(w.Body.Bytes(), &jf); err != nil {
+		t.Fatalf("invalid json feed: %v", err)
+	}
+	if jf.Version != "https://jsonfeed.org/version/1.1" || len(jf.Items) != 2 || jf.Items[1].Title != "Older" || jf.Items[1].ContentText == "" {
+		t.Fatalf("unexpected json feed: %s", w.Body.String())
+	}
+
+	if w := getOutput(h, "tok", "pdf"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected unknown format to 404, got %d", w.Code)
+	}
+}
+
+func TestHandleOutputFeed_SavedFilter(t *testing.T) {
+	h := setupHandler(t)
+	seedArticles(t, h)
+
+	res, err := h.DB.Exec(`INSERT INTO saved_filters (user_id, name, conditions, position, created_at, updated_at)
+		VALUES (1, 'Older', ?, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`[{"field":"article_title","operator":"contains","value":"older"}]`)
+	if err != nil {
+		t.Fatalf("insert saved filter: %v", err)
+	}
+	filterID, _ := res.LastInsertId()
+	feed := &models.OutputFeed{UserID: 1, Token: "filtered", SourceType: sqlite.OutputSourceSavedFilter, SourceValue: strconv.FormatInt(filterID, 10), MaxItems: 10}
+	if _, err := h.DB.CreateOutputFeed(feed); err != nil {
+		t.Fatalf("CreateOutputFeed: %v", err)
+	}
+
+	w := getOutput(h, "filtered", "json")
+	var jf jsonFeed
+	if err := json.Unmarshal(w.Body.Bytes(), &jf); err != nil {
+		t.Fatalf("invalid json feed: %v", err)
+	}
+	if len(jf.Items) != 1 || jf.Items[0].Title != "Older" {
+		t.Fatalf("unexpected items: %s", w.Body.String())
+	}
+}
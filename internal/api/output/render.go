@@ -0,0 +1,278 @@
+package output
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strconv"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/utils/textutil"
+)
+
+// document is a format independent output feed
+type document struct {
+	Title   string
+	SelfURL string
+	Items   []item
+}
+
+// item is a format independent output feed entry
+type item struct {
+	ID          string
+	Title       string
+	URL         string
+	Author      string
+	Content     string // HTML
+	Summary     string // Plain text AI summary
+	ImageURL    string
+	FeedTitle   string
+	PublishedAt time.Time
+}
+
+// renderers maps URL formats to their renderer
+var renderers = map[string]func(document) ([]byte, string, error){
+	"atom": renderAtom,
+	"rss":  renderRSS,
+	"json": renderJSONFeed,
+}
+
+// newItem builds the entry for an article, honouring the feed's options
+func newItem(f *models.OutputFeed, a models.Article, content string) item {
+	it := item{
+		ID:          a.URL,
+		Title:       a.Title,
+		URL:         a.URL,
+		Author:      a.Author,
+		Content:     content,
+		ImageURL:    a.ImageURL,
+		FeedTitle:   a.FeedTitle,
+		PublishedAt: a.PublishedAt,
+	}
+	if it.ID == "" {
+		it.ID = "mavenrss:article:" + strconv.FormatInt(a.ID, 10)
+	}
+	if f.IncludeTranslations && a.TranslatedTitle != "" {
+		it.Title = a.TranslatedTitle
+	}
+	if f.IncludeSummaries && a.Summary != "" {
+		it.Summary = textutil.StripHTML(a.Summary)
+	}
+	if it.PublishedAt.IsZero() {
+		it.PublishedAt = time.Now()
+	}
+	return it
+}
+
+// updated returns the date of the most recent item
+func (d document) updated() time.Time {
+	var latest time.Time
+	for _, it := range d.Items {
+		if it.PublishedAt.After(latest) {
+			latest = it.PublishedAt
+		}
+	}
+	if latest.IsZero() {
+		latest = time.Now()
+	}
+	return latest
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Link    []atomLink  `xml:"link"`
+	Updated string      `xml:"updated"`
+	Author  *atomPerson `xml:"author,omitempty"`
+	Summary *atomText   `xml:"summary,omitempty"`
+	Content *atomText   `xml:"content,omitempty"`
+}
+
+func renderAtom(d document) ([]byte, string, error) {
+	feed := atomFeed{
+		Title:   d.Title,
+		ID:      d.SelfURL,
+		Updated: d.updated().UTC().Format(time.RFC3339),
+		Link:    []atomLink{{Rel: "self", Href: d.SelfURL, Type: "application/atom+xml"}},
+	}
+	for _, it := range d.Items {
+		entry := atomEntry{
+			Title:   it.Title,
+			ID:      it.ID,
+			Updated: it.PublishedAt.UTC().Format(time.RFC3339),
+		}
+		if it.URL != "" {
+			entry.Link = []atomLink{{Rel: "alternate", Href: it.URL}}
+		}
+		if it.Author != "" {
+			entry.Author = &atomPerson{Name: it.Author}
+		}
+		if it.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Body: it.Summary}
+		}
+		if it.Content != "" {
+			entry.Content = &atomText{Type: "html", Body: it.Content}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return marshalXML(feed, "application/atom+xml; charset=utf-8")
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Content string     `xml:"xmlns:content,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title          string  `xml:"title"`
+	Link           string  `xml:"link,omitempty"`
+	GUID           rssGUID `xml:"guid"`
+	PubDate        string  `xml:"pubDate"`
+	Author         string  `xml:"author,omitempty"`
+	Description    string  `xml:"description,omitempty"`
+	ContentEncoded string  `xml:"content:encoded,omitempty"`
+}
+
+func renderRSS(d document) ([]byte, string, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Content: "http://purl.org/rss/1.0/modules/content/",
+		Channel: rssChannel{
+			Title:         d.Title,
+			Link:          d.SelfURL,
+			Description:   d.Title,
+			LastBuildDate: d.updated().UTC().Format(time.RFC1123Z),
+			AtomLink:      atomLink{Rel: "self", Href: d.SelfURL, Type: "application/rss+xml"},
+		},
+	}
+	for _, it := range d.Items {
+		ri := rssItem{
+			Title:          it.Title,
+			Link:           it.URL,
+			GUID:           rssGUID{IsPermaLink: it.ID == it.URL, Value: it.ID},
+			PubDate:        it.PublishedAt.UTC().Format(time.RFC1123Z),
+			Author:         it.Author,
+			Description:    it.Summary,
+			ContentEncoded: it.Content,
+		}
+		if ri.Description == "" {
+			ri.Description = it.Content
+		}
+		feed.Channel.Items = append(feed.Channel.Items, ri)
+	}
+	return marshalXML(feed, "application/rss+xml; charset=utf-8")
+}
+
+func marshalXML(v interface{}, contentType string) ([]byte, string, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, "", err
+	}
+	return append([]byte(xml.Header), body...), contentType, nil
+}
+
+// jsonFeed is a JSON Feed 1.1 document (https://jsonfeed.org/version/1.1)
+type jsonFeed struct {
+	Version string         `json:"version"`
+	Title   string         `json:"title"`
+	FeedURL string         `json:"feed_url"`
+	Items   []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url,omitempty"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	ContentText   string           `json:"content_text,omitempty"`
+	Summary       string           `json:"summary,omitempty"`
+	Image         string           `json:"image,omitempty"`
+	DatePublished string           `json:"date_published"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+func renderJSONFeed(d document) ([]byte, string, error) {
+	feed := jsonFeed{
+		Version: "https://jsonfeed.org/version/1.1",
+		Title:   d.Title,
+		FeedURL: d.SelfURL,
+		Items:   make([]jsonFeedItem, 0, len(d.Items)),
+	}
+	for _, it := range d.Items {
+		ji := jsonFeedItem{
+			ID:            it.ID,
+			URL:           it.URL,
+			Title:         it.Title,
+			ContentHTML:   it.Content,
+			Summary:       it.Summary,
+			Image:         it.ImageURL,
+			DatePublished: it.PublishedAt.UTC().Format(time.RFC3339),
+		}
+		// Items must have content_html or content_text
+		if ji.ContentHTML == "" {
+			ji.ContentText = it.Summary
+			if ji.ContentText == "" {
+				ji.ContentText = it.Title
+			}
+		}
+		if it.Author != "" {
+			ji.Authors = []jsonFeedAuthor{{Name: it.Author}}
+		}
+		if it.FeedTitle != "" {
+			ji.Tags = []string{it.FeedTitle}
+		}
+		feed.Items = append(feed.Items, ji)
+	}
+	body, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, "", err
+	}
+	return body, "application/feed+json; charset=utf-8", nil
+}
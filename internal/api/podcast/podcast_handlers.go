@@ -0,0 +1,170 @@
+// Package podcast serves podcast episode metadata and keeps each user's
+// listening progress on the server, so playback resumes across devices.
+package podcast
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+const (
+	defaultInProgressLimit = 20
+	maxInProgressLimit     = 100
+	// Remaining seconds under which an episode counts as completed
+	completionThreshold = 30
+)
+
+// HandleEpisode returns the podcast metadata of an article and the user's playback position.
+// @Summary      Get podcast episode
+// @Description  Returns duration, size, MIME type, artwork, chapters and transcript of an episode, with the current user's playback position
+// @Tags         podcast
+// @Produce      json
+// @Param        article_id  query     int  true  "Article ID"
+// @Success      200  {object}  map[string]interface{}  "episode and position (null if never played)"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      404  {object}  map[string]string  "Article or episode not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /podcast/episode [get]
+func HandleEpisode(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	userID, ok := core.GetUserIDFromRequest(r)
+	if !ok {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	articleID, err := strconv.ParseInt(r.URL.Query().Get("article_id"), 10, 64)
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	article, err := h.DB.GetArticleByIDForUser(userID, articleID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if article == nil {
+		response.Error(w, sqlite.ErrArticleNotFound, http.StatusNotFound)
+		return
+	}
+
+	episode, err := h.DB.GetPodcastEpisode(articleID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	if episode == nil {
+		response.Error(w, errors.New("article is not a podcast episode"), http.StatusNotFound)
+		return
+	}
+
+	position, err := h.DB.GetPlaybackPosition(userID, articleID)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"episode":  episode,
+		"position": position,
+	})
+}
+
+// HandlePlayback saves and restores playback positions.
+// @Summary      Podcast playback position
+// @Description  GET ?article_id= returns the position in an episode, GET without it lists unfinished episodes (most recent first), POST saves a position
+// @Tags         podcast
+// @Accept       json
+// @Produce      json
+// @Param        article_id  query     int                      false  "Article ID (GET)"
+// @Param        limit       query     int                      false  "Maximum unfinished episodes to list (default 20, max 100)"
+// @Param        request     body      models.PlaybackPosition  false  "Position to save (POST)"
+// @Success      200  {object}  models.PlaybackPosition  "Playback position (null if never played)"
+// @Failure      400  {object}  map[string]string  "Bad request"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      404  {object}  map[string]string  "Article not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /podcast/playback [get]
+func HandlePlayback(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	userID, ok := core.GetUserIDFromRequest(r)
+	if !ok {
+		response.Error(w, nil, http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if idStr := r.URL.Query().Get("article_id"); idStr != "" {
+			articleID, err := strconv.ParseInt(idStr, 10, 64)
+			if err != nil {
+				response.Error(w, err, http.StatusBadRequest)
+				return
+			}
+			position, err := h.DB.GetPlaybackPosition(userID, articleID)
+			if err != nil {
+				response.Error(w, err, http.StatusInternalServerError)
+				return
+			}
+			response.JSON(w, position)
+			return
+		}
+
+		limit := defaultInProgressLimit
+		if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+			limit = l
+		}
+		if limit > maxInProgressLimit {
+			limit = maxInProgressLimit
+		}
+		positions, err := h.DB.GetInProgressPlayback(userID, limit)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, positions)
+
+	case http.MethodPost:
+		var pos models.PlaybackPosition
+		if err := json.NewDecoder(r.Body).Decode(&pos); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if pos.ArticleID <= 0 || pos.PositionSeconds < 0 || pos.DurationSeconds < 0 {
+			response.Error(w, errors.New("article_id and a non-negative position are required"), http.StatusBadRequest)
+			return
+		}
+		if pos.DurationSeconds > 0 && pos.DurationSeconds-pos.PositionSeconds <= completionThreshold {
+			pos.Completed = true
+		}
+
+		if err := h.DB.SavePlaybackPosition(userID, &pos); errors.Is(err, sqlite.ErrArticleNotFound) {
+			response.Error(w, err, http.StatusNotFound)
+			return
+		} else if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		saved, err := h.DB.GetPlaybackPosition(userID, pos.ArticleID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, saved)
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,97 @@
+package podcast
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/auth"
+	ff "MavenRSS/internal/feed"
+	"MavenRSS/internal/middleware"
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+func setupEpisode(t *testing.T) (*core.Handler, int64) {
+	t.Helper()
+	db, err := sqlite.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	h := core.NewHandler(db, ff.NewFetcher(db), nil, nil)
+
+	feedID, err := h.DB.AddFeed(&models.Feed{Title: "Show", URL: "http://example.com/podcast.xml"})
+	if err != nil {
+		t.Fatalf("AddFeed: %v", err)
+	}
+	articles := []*models.Article{{FeedID: feedID, Title: "Episode 1", URL: "http://example.com/1", AudioURL: "http://example.com/1.mp3", PublishedAt: time.Now()}}
+	if err := h.DB.SaveArticles(context.Background(), articles); err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+	saved, err := h.DB.GetArticles("", feedID, "", false, 10, 0)
+	if err != nil || len(saved) != 1 {
+		t.Fatalf("expected 1 article, got %d (%v)", len(saved), err)
+	}
+	ep := &models.PodcastEpisode{ArticleID: saved[0].ID, AudioURL: "http://example.com/1.mp3", DurationSeconds: 600,
+		Chapters: []models.PodcastChapter{{StartTime: 0, Title: "Intro"}}}
+	if err := h.DB.SavePodcastEpisode(ep); err != nil {
+		t.Fatalf("SavePodcastEpisode: %v", err)
+	}
+	return h, saved[0].ID
+}
+
+func TestHandlePlayback(t *testing.T) {
+	h, articleID := setupEpisode(t)
+	ctx := context.WithValue(context.Background(), middleware.UserContextKey, &auth.Claims{UserID: 1, Username: "admin"})
+	id := strconv.FormatInt(articleID, 10)
+
+	call := func(handler func(*core.Handler, http.ResponseWriter, *http.Request), method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body)).WithContext(ctx)
+		w := httptest.NewRecorder()
+		handler(h, w, req)
+		return w
+	}
+
+	w := call(HandleEpisode, http.MethodGet, "/api/podcast/episode?article_id="+id, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("episode: %d %s", w.Code, w.Body.String())
+	}
+	var episode struct {
+		Episode  models.PodcastEpisode    `json:"episode"`
+		Position *models.PlaybackPosition `json:"position"`
+	}
+	json.NewDecoder(w.Body).Decode(&episode)
+	if episode.Episode.DurationSeconds != 600 || len(episode.Episode.Chapters) != 1 || episode.Position != nil {
+		t.Fatalf("unexpected episode: %+v", episode)
+	}
+
+	if w := call(HandlePlayback, http.MethodPost, "/api/podcast/playback", `{"article_id":`+id+`,"position_seconds":120.5,"duration_seconds":600}`); w.Code != http.StatusOK {
+		t.Fatalf("save: %d %s", w.Code, w.Body.String())
+	}
+	var positions []models.PlaybackPosition
+	json.NewDecoder(call(HandlePlayback, http.MethodGet, "/api/podcast/playback", "").Body).Decode(&positions)
+	if len(positions) != 1 || positions[0].PositionSeconds != 120.5 || positions[0].Completed {
+		t.Fatalf("unexpected in-progress episodes: %+v", positions)
+	}
+
+	// Positions near the end mark the episode as completed
+	w = call(HandlePlayback, http.MethodPost, "/api/podcast/playback", `{"article_id":`+id+`,"position_seconds":590,"duration_seconds":600}`)
+	var pos models.PlaybackPosition
+	json.NewDecoder(w.Body).Decode(&pos)
+	if !pos.Completed {
+		t.Fatalf("expected episode to be completed: %+v", pos)
+	}
+
+	if w := call(HandlePlayback, http.MethodPost, "/api/podcast/playback", `{"article_id":99999,"position_seconds":1}`); w.Code != http.StatusNotFound {
+		t.Fatalf("expected unknown article to 404, got %d", w.Code)
+	}
+}
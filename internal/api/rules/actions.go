@@ -0,0 +1,48 @@
+package rules
+
+import (
+	"fmt"
+
+	"MavenRSS/internal/api/article"
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/summary"
+	"MavenRSS/internal/api/translation"
+	"MavenRSS/internal/rules"
+)
+
+// actionServices performs the rule actions that use the AI, translation,
+// full-text and export features of the handler
+type actionServices struct {
+	h *core.Handler
+}
+
+// RegisterActionServices makes the summarize, translate, fetch_full_text and
+// export rule actions available
+func RegisterActionServices(h *core.Handler) {
+	rules.SetServices(&actionServices{h: h})
+}
+
+func (s *actionServices) Summarize(userID, articleID int64) error {
+	return summary.SummarizeArticle(s.h, userID, articleID)
+}
+
+func (s *actionServices) Translate(userID, articleID int64) error {
+	return translation.TranslateArticle(s.h, userID, articleID)
+}
+
+func (s *actionServices) FetchFullText(userID, articleID int64) error {
+	return article.FetchFullText(s.h, articleID)
+}
+
+func (s *actionServices) Export(userID, articleID int64, target string) error {
+	var err error
+	switch target {
+	case "obsidian":
+		_, err = article.ExportArticleToObsidian(s.h, userID, articleID)
+	case "notion":
+		_, err = article.ExportArticleToNotion(s.h, userID, articleID)
+	default:
+		err = fmt.Errorf("unknown export target %s", target)
+	}
+	return err
+}
@@ -1,8 +1,11 @@
 package rules
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"MavenRSS/internal/api/core"
 	"MavenRSS/internal/api/response"
@@ -11,13 +14,15 @@ import (
 
 // HandleApplyRule applies a rule to matching articles
 // @Summary      Apply rule to articles
-// @Description  Apply a rule with conditions and actions to matching articles (mark as read, favorite, etc.)
+// @Description  Apply a rule with conditions and actions to the user's matching articles (mark as read, favorite, etc.).
+// @Description  With dry_run, the matching article IDs are returned and no action is performed.
 // @Tags         rules
 // @Accept       json
 // @Produce      json
-// @Param        rule  body      rules.Rule  true  "Rule definition (conditions and actions)"
-// @Success      200  {object}  map[string]interface{}  "Application result (success, affected count)"
-// @Failure      400  {object}  map[string]string  "Bad request (invalid rule or no actions)"
+// @Param        rule     body      rules.Rule  true   "Rule definition (query or conditions, and actions)"
+// @Param        dry_run  query     bool        false  "Only return the matching article IDs"
+// @Success      200  {object}  map[string]interface{}  "Application result (success, affected count, or dry_run and article_ids)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid rule, query or no actions)"
 // @Failure      500  {object}  map[string]string  "Internal server error"
 // @Router       /rules/apply [post]
 func HandleApplyRule(h *core.Handler, w http.ResponseWriter, r *http.Request) {
@@ -32,12 +37,28 @@ func HandleApplyRule(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(rule.Actions) == 0 {
-		response.Error(w, nil, http.StatusBadRequest)
+	if err := rules.ValidateRule(rule); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
 		return
 	}
+	rule.UserID = requestUserID(r)
 
 	engine := rules.NewEngine(h.DB)
+	if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run")); dryRun {
+		ids, err := engine.PreviewRule(rule)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, map[string]interface{}{
+			"success":     true,
+			"dry_run":     true,
+			"article_ids": ids,
+			"affected":    len(ids),
+		})
+		return
+	}
+
 	affected, err := engine.ApplyRule(rule)
 	if err != nil {
 		response.Error(w, err, http.StatusInternalServerError)
@@ -49,3 +70,115 @@ func HandleApplyRule(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		"affected": affected,
 	})
 }
+
+// HandleRules lists, saves and deletes the rules of the user
+// @Summary      Manage rules
+// @Description  GET lists the user's rules in execution order. POST creates a rule (without id) or updates one. DELETE removes the rule given by id.
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Param        rule  body      rules.Rule  false  "Rule to save (POST)"
+// @Param        id    query     int64       false  "Rule to delete (DELETE)"
+// @Success      200  {array}   rules.Rule  "Rules (GET), the saved rule (POST)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid rule or id)"
+// @Failure      404  {object}  map[string]string  "Rule not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /rules [get]
+func HandleRules(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	engine := rules.NewEngine(h.DB)
+	userID := requestUserID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := engine.LoadRules(userID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, list)
+
+	case http.MethodPost:
+		var rule rules.Rule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := rules.ValidateRule(rule); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		rule.UserID = userID
+		if err := engine.SaveRule(&rule); err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, rule)
+
+	case http.MethodDelete:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := engine.DeleteRule(userID, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(w, err, http.StatusNotFound)
+				return
+			}
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, map[string]bool{"success": true})
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleRuleExecutions lists the most recent rule executions of the user
+// @Summary      List rule executions
+// @Description  Get the log of actions performed by the user's rules, newest first, optionally filtered by rule.
+// @Description  The source of an execution is "fetch", "manual" or "schedule".
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Param        rule_id  query     int64  false  "Only executions of this rule"
+// @Param        page     query     int    false  "Page number (default: 1)"  minimum(1)
+// @Param        limit    query     int    false  "Items per page (default: 50, max: 200)"  minimum(1)  maximum(200)
+// @Success      200  {array}   sqlite.RuleExecution  "Rule executions"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid rule_id)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /rules/executions [get]
+func HandleRuleExecutions(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	var ruleID int64
+	if ruleIDStr := query.Get("rule_id"); ruleIDStr != "" {
+		id, err := strconv.ParseInt(ruleIDStr, 10, 64)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		ruleID = id
+	}
+
+	limit, offset := pageParams(query)
+	executions, err := h.DB.GetRuleExecutions(requestUserID(r), ruleID, limit, offset)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, executions)
+}
+
+// requestUserID returns the user of the request, or the default user in single-user mode
+func requestUserID(r *http.Request) int64 {
+	if userID, ok := core.GetUserIDFromRequest(r); ok {
+		return userID
+	}
+	return 1
+}
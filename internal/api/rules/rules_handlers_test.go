@@ -39,3 +39,25 @@ func TestHandleApplyRule_NoActions(t *testing.T) {
 		t.Fatalf("expected %d got %d", http.StatusBadRequest, rr.Code)
 	}
 }
+
+func TestHandleApplyRule_InvalidQuery(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/rules/apply", bytes.NewReader([]byte(`{"name":"r","query":"title:(","actions":["favorite"]}`)))
+	rr := httptest.NewRecorder()
+
+	HandleApplyRule(nil, rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandleApplyRule_InvalidSchedule(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/rules/apply", bytes.NewReader([]byte(`{"name":"r","actions":["favorite"],"schedule":"hourly"}`)))
+	rr := httptest.NewRecorder()
+
+	HandleApplyRule(nil, rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+)
+
+// HandleWebhookDeliveries lists the most recent webhook deliveries of the user
+// @Summary      List webhook deliveries
+// @Description  Get the delivery log of the rules "webhook" action, newest first, optionally filtered by rule
+// @Tags         rules
+// @Accept       json
+// @Produce      json
+// @Param        rule_id  query     int64  false  "Only deliveries of this rule"
+// @Param        page     query     int    false  "Page number (default: 1)"  minimum(1)
+// @Param        limit    query     int    false  "Items per page (default: 50, max: 200)"  minimum(1)  maximum(200)
+// @Success      200  {array}   sqlite.WebhookDelivery  "Webhook deliveries"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid rule_id)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /rules/webhooks/deliveries [get]
+func HandleWebhookDeliveries(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	var ruleID int64
+	if ruleIDStr := query.Get("rule_id"); ruleIDStr != "" {
+		id, err := strconv.ParseInt(ruleIDStr, 10, 64)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		ruleID = id
+	}
+
+	limit, offset := pageParams(query)
+	deliveries, err := h.DB.GetWebhookDeliveries(requestUserID(r), ruleID, limit, offset)
+	if err != nil {
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, deliveries)
+}
+
+// pageParams returns the limit and offset of the page and limit query parameters
+// (default: first page of 50 items, at most 200)
+func pageParams(query url.Values) (int, int) {
+	page := 1
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	limit := 50
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	return limit, (page - 1) * limit
+}
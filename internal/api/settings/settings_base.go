@@ -53,6 +53,7 @@ var AllSettings = []SettingDef{
 	{Key: "deepl_api_key", Encrypted: true},
 	{Key: "deepl_endpoint", Encrypted: false},
 	{Key: "default_view_mode", Encrypted: false},
+	{Key: "feed_auto_rediscover", Encrypted: false},
 	{Key: "feed_drawer_expanded", Encrypted: false},
 	{Key: "feed_drawer_pinned", Encrypted: false},
 	{Key: "freshrss_api_password", Encrypted: true},
@@ -64,6 +65,7 @@ var AllSettings = []SettingDef{
 	{Key: "freshrss_username", Encrypted: false},
 	{Key: "full_text_fetch_enabled", Encrypted: false},
 	{Key: "google_translate_endpoint", Encrypted: false},
+	{Key: "host_refresh_spacing_ms", Encrypted: false},
 	{Key: "hover_mark_as_read", Encrypted: false},
 	{Key: "idle_conn_timeout_seconds", Encrypted: false},
 	{Key: "image_gallery_enabled", Encrypted: false},
@@ -74,6 +76,7 @@ var AllSettings = []SettingDef{
 	{Key: "max_article_age_days", Encrypted: false},
 	{Key: "max_cache_size_mb", Encrypted: false},
 	{Key: "max_concurrent_refreshes", Encrypted: false},
+	{Key: "max_concurrent_refreshes_per_host", Encrypted: false},
 	{Key: "max_conns_per_host", Encrypted: false},
 	{Key: "max_idle_conns", Encrypted: false},
 	{Key: "max_idle_conns_per_host", Encrypted: false},
@@ -98,6 +101,7 @@ var AllSettings = []SettingDef{
 	{Key: "proxy_type", Encrypted: false},
 	{Key: "proxy_username", Encrypted: true},
 	{Key: "refresh_mode", Encrypted: false},
+	{Key: "respect_robots_txt", Encrypted: false},
 	{Key: "retry_timeout_seconds", Encrypted: false},
 	{Key: "rsshub_api_key", Encrypted: true},
 	{Key: "rsshub_enabled", Encrypted: false},
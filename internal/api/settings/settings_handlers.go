@@ -10,6 +10,7 @@ import (
 	"MavenRSS/internal/api/core"
 	"MavenRSS/internal/api/response"
 	"MavenRSS/internal/middleware"
+	"MavenRSS/internal/rules"
 	"MavenRSS/internal/translation"
 	"MavenRSS/internal/utils/httputil"
 )
@@ -187,6 +188,12 @@ func SaveSettingsForUser(h *core.Handler, userID int64, settings map[string]stri
 func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 	userID, ok := core.GetUserIDFromRequest(r)
 
+	// Rules are stored per user in their own table; without a user they belong to the default user
+	rulesOwner := int64(1)
+	if ok {
+		rulesOwner = userID
+	}
+
 	// Get user information for sensitive settings filtering
 	isAdmin := isAdmin(r)
 	hasInherited := false
@@ -207,6 +214,7 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		} else {
 			settings = GetAllSettings(h)
 		}
+		settings["rules"] = rulesSetting(h, rulesOwner)
 		response.JSON(w, settings)
 
 	case http.MethodPost:
@@ -217,6 +225,20 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Rules are saved to the rules table rather than as a setting
+		if rulesJSON, okRules := req["rules"]; okRules {
+			list, err := rules.ParseRulesJSON(rulesJSON)
+			if err != nil {
+				response.Error(w, err, http.StatusBadRequest)
+				return
+			}
+			if err := rules.NewEngine(h.DB).SaveRules(rulesOwner, list); err != nil {
+				response.Error(w, err, http.StatusInternalServerError)
+				return
+			}
+			delete(req, "rules")
+		}
+
 		// Check if we're disabling FreshRSS
 		if newEnabled, okFresh := req["freshrss_enabled"]; okFresh {
 			var oldEnabled string
@@ -327,9 +349,21 @@ func HandleSettings(h *core.Handler, w http.ResponseWriter, r *http.Request) {
 		} else {
 			settingsUpdated = GetAllSettings(h)
 		}
+		settingsUpdated["rules"] = rulesSetting(h, rulesOwner)
 		response.JSON(w, settingsUpdated)
 
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
+
+// rulesSetting returns the rules of a user in the JSON format of the "rules" setting
+func rulesSetting(h *core.Handler, userID int64) string {
+	list, err := rules.NewEngine(h.DB).LoadRules(userID)
+	if err != nil {
+		log.Printf("Warning: Failed to load rules of user %d: %v", userID, err)
+		return "[]"
+	}
+	data, _ := json.Marshal(list)
+	return string(data)
+}
@@ -89,3 +89,26 @@ func TestHandleSettings_POST(t *testing.T) {
 		t.Fatalf("expected deepl_api_key decrypted to be deadbeef, got %s", dec)
 	}
 }
+
+func TestHandleSettings_POSTInvalidRules(t *testing.T) {
+	h := setupHandlerWithDB(t)
+
+	for _, rules := range []string{
+		`[{"id": 1, "name": "no actions", "enabled": true, "query": "title:go"}]`,
+		`[{"id": 1, "name": "bad query", "enabled": true, "query": "title:(go", "actions": ["favorite"]}]`,
+		`[{"id": 1, "name": "no webhook", "enabled": true, "actions": ["webhook"]}]`,
+	} {
+		body, _ := json.Marshal(map[string]string{"rules": rules})
+		req := httptest.NewRequest(http.MethodPost, "/api/settings", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		HandleSettings(h, w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s, got %d", rules, w.Code)
+		}
+	}
+	if saved, _ := h.DB.GetRulesForUser(1); len(saved) != 0 {
+		t.Errorf("expected no saved rules, got %d", len(saved))
+	}
+}
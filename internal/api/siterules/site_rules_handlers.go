@@ -0,0 +1,260 @@
+// Package siterules contains the handlers managing the full-text extraction rules of sites.
+package siterules
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	"MavenRSS/internal/fulltext"
+	"MavenRSS/internal/utils/httputil"
+)
+
+const (
+	// maxSiteConfigUpload caps the size of an imported site config file
+	maxSiteConfigUpload = 256 * 1024
+	// siteRuleTestTimeout bounds the extraction of a test URL
+	siteRuleTestTimeout = 60 * time.Second
+)
+
+// HandleSiteRules lists, saves and deletes the full-text site rules of the user
+// @Summary      Manage full-text site rules
+// @Description  GET lists the user's site rules. POST creates a rule (without id, replacing the rule of the same host) or updates one.
+// @Description  DELETE removes the rule given by id. Site rules tell how to extract the full text of the articles of a site.
+// @Tags         site-rules
+// @Accept       json
+// @Produce      json
+// @Param        rule  body      fulltext.SiteRule  false  "Rule to save (POST)"
+// @Param        id    query     int64              false  "Rule to delete (DELETE)"
+// @Success      200  {array}   fulltext.SiteRule  "Rules (GET), the saved rule (POST)"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid rule or id)"
+// @Failure      404  {object}  map[string]string  "Rule not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /site-rules [get]
+func HandleSiteRules(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := fulltext.LoadSiteRules(h.DB, userID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, list)
+
+	case http.MethodPost:
+		var rule fulltext.SiteRule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		saveSiteRule(h, w, userID, &rule)
+
+	case http.MethodDelete:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if err := h.DB.DeleteSiteRuleForUser(userID, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				response.Error(w, err, http.StatusNotFound)
+				return
+			}
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, map[string]bool{"success": true})
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleImportSiteConfig imports a site rule in the ftr-site-config text format
+// @Summary      Import a site config
+// @Description  Create or replace the site rule of a host from a file in the ftr-site-config format of Full-Text RSS
+// @Description  (body, strip, strip_id_or_class, next_page_link, single_page_link and test_url directives, plus
+// @Description  "rewrite_url: <regexp> => <replacement>"). The host defaults to the file name, e.g. "example.com.txt".
+// @Tags         site-rules
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        host  query     string  false  "Host of the rule"
+// @Param        file  formData  file    false  "Site config file (or the raw request body)"
+// @Success      200  {object}  fulltext.SiteRule  "Imported rule"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid host or site config)"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Router       /site-rules/import [post]
+func HandleImportSiteConfig(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+
+	host := r.URL.Query().Get("host")
+	var file io.Reader = r.Body
+	if strings.Contains(r.Header.Get("Content-Type"), "multipart/form-data") {
+		f, header, err := r.FormFile("file")
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+		file = f
+		if host == "" {
+			host = filepath.Base(header.Filename)
+		}
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxSiteConfigUpload+1))
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if len(data) > maxSiteConfigUpload {
+		response.Error(w, nil, http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	rule, err := fulltext.ParseSiteConfig(host, bytes.NewReader(data))
+	if err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	saveSiteRule(h, w, requestUserID(r), rule)
+}
+
+// HandleTestSiteRule extracts the content of a sample article page
+// @Summary      Test a site rule
+// @Description  Extract the full text of a page with a site rule and return it without storing anything.
+// @Description  The rule is the one given, else the stored rule with the given id, else the stored rule matching the URL.
+// @Description  The URL defaults to the test URL of the rule.
+// @Tags         site-rules
+// @Accept       json
+// @Produce      json
+// @Param        request  body      object  true  "url, and rule or id"
+// @Success      200  {object}  fulltext.Result  "Extracted content"
+// @Failure      400  {object}  map[string]string  "Bad request (invalid rule or URL)"
+// @Failure      404  {object}  map[string]string  "Rule not found"
+// @Failure      502  {object}  map[string]string  "Page could not be fetched or extracted"
+// @Router       /site-rules/test [post]
+func HandleTestSiteRule(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+		return
+	}
+	userID := requestUserID(r)
+
+	var req struct {
+		URL  string             `json:"url"`
+		ID   int64              `json:"id"`
+		Rule *fulltext.SiteRule `json:"rule"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	rule := req.Rule
+	if rule != nil {
+		if err := rule.Validate(); err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+	} else if req.ID > 0 {
+		list, err := fulltext.LoadSiteRules(h.DB, userID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		for i := range list {
+			if list[i].ID == req.ID {
+				rule = &list[i]
+			}
+		}
+		if rule == nil {
+			response.Error(w, nil, http.StatusNotFound)
+			return
+		}
+	}
+
+	pageURL := req.URL
+	if pageURL == "" && rule != nil {
+		pageURL = rule.TestURL
+	}
+	if u, err := url.Parse(pageURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		response.Error(w, errors.New("a http(s) URL is required"), http.StatusBadRequest)
+		return
+	}
+	if rule == nil {
+		list, err := fulltext.LoadSiteRules(h.DB, userID)
+		if err != nil {
+			response.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+		rule = fulltext.MatchSiteRule(list, pageURL)
+	}
+
+	// Fetch as the feed refreshes do, with the global proxy
+	var proxyURL string
+	if proxyEnabled, _ := h.DB.GetSetting("proxy_enabled"); proxyEnabled == "true" {
+		proxyType, _ := h.DB.GetSetting("proxy_type")
+		proxyHost, _ := h.DB.GetSetting("proxy_host")
+		proxyPort, _ := h.DB.GetSetting("proxy_port")
+		proxyUsername, _ := h.DB.GetEncryptedSetting("proxy_username")
+		proxyPassword, _ := h.DB.GetEncryptedSetting("proxy_password")
+		proxyURL = httputil.BuildProxyURL(proxyType, proxyHost, proxyPort, proxyUsername, proxyPassword)
+	}
+	client := httputil.GetPooledUserAgentClient(proxyURL, 30*time.Second, fulltext.BrowserUserAgent)
+
+	ctx, cancel := context.WithTimeout(r.Context(), siteRuleTestTimeout)
+	defer cancel()
+	if respectRobots, _ := h.DB.GetSetting("respect_robots_txt"); respectRobots == "true" {
+		if err := httputil.GetRobotsChecker().Check(ctx, client, pageURL); err != nil {
+			response.Error(w, err, http.StatusBadGateway)
+			return
+		}
+	}
+	result, err := fulltext.Extract(ctx, client, pageURL, rule)
+	if err != nil {
+		response.Error(w, err, http.StatusBadGateway)
+		return
+	}
+	response.JSON(w, result)
+}
+
+// saveSiteRule validates and stores a rule, writing the saved rule or the error
+func saveSiteRule(h *core.Handler, w http.ResponseWriter, userID int64, rule *fulltext.SiteRule) {
+	if err := rule.Validate(); err != nil {
+		response.Error(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := fulltext.SaveSiteRule(h.DB, userID, rule); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.Error(w, err, http.StatusNotFound)
+			return
+		}
+		response.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, rule)
+}
+
+// requestUserID returns the authenticated user, or the default user in single-user mode
+func requestUserID(r *http.Request) int64 {
+	if userID, ok := core.GetUserIDFromRequest(r); ok {
+		return userID
+	}
+	return 1
+}
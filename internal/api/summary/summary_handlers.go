@@ -2,9 +2,11 @@ package summary
 
 import (
 	"encoding/json"
+	"html"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"MavenRSS/internal/ai"
 	"MavenRSS/internal/api/core"
@@ -135,15 +137,45 @@ func HandleSummarizeArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	priority := ai.PriorityNormal
+	if req.HighPriority {
+		priority = ai.PriorityHigh
+	}
+	result, limitReached, usedFallback := generateSummary(h, userID, content, summaryLength, priority)
+
+	// Cache the summary in the database
+	if err := h.DB.UpdateArticleSummary(req.ArticleID, result.Summary); err != nil {
+		log.Printf("Failed to cache summary for article %d: %v", req.ArticleID, err)
+		// Don't fail the request if caching fails
+	}
+
+	// Convert markdown summary to HTML (for all summaries, not just AI)
+	htmlSummary := textutil.ConvertMarkdownToHTML(result.Summary)
+
+	resp := map[string]interface{}{
+		"summary":        result.Summary,
+		"html":           htmlSummary,
+		"sentence_count": result.SentenceCount,
+		"is_too_short":   result.IsTooShort,
+		"limit_reached":  limitReached,
+		"thinking":       result.Thinking,
+	}
+	if usedFallback {
+		resp["used_fallback"] = true
+	}
+
+	response.JSON(w, resp)
+}
+
+// generateSummary summarizes content with the user's summary provider. AI summaries fall
+// back to the local algorithm when the usage limit is reached or the request fails.
+func generateSummary(h *core.Handler, userID int64, content string, summaryLength summary.SummaryLength, priority ai.PriorityLevel) (result summary.SummaryResult, limitReached, usedFallback bool) {
 	// Get summary provider from settings (with default)
 	provider, err := h.DB.GetSettingWithFallback(userID, "summary_provider")
 	if err != nil || provider == "" {
 		provider = "local" // Default to local algorithm
 	}
 
-	var result summary.SummaryResult
-	usedFallback := false
-	limitReached := false
 
 	if provider == "ai" {
 		// Check if AI usage limit is reached - fallback to local if so
@@ -156,11 +188,7 @@ func HandleSummarizeArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		} else {
 			// Use AI summarization
 			// Apply rate limiting for AI requests with priority
-			if req.HighPriority {
-				h.AITracker.WaitForRateLimitWithPriority(ai.PriorityHigh, userID)
-			} else {
-				h.AITracker.WaitForRateLimitWithPriority(ai.PriorityNormal, userID)
-			}
+			h.AITracker.WaitForRateLimitWithPriority(priority, userID)
 
 			// Try to get AI config from ProfileProvider first
 			var apiKey, endpoint, model string
@@ -229,28 +257,35 @@ func HandleSummarizeArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		result = summarizer.Summarize(content, summaryLength)
 	}
 
-	// Cache the summary in the database
-	if err := h.DB.UpdateArticleSummary(req.ArticleID, result.Summary); err != nil {
-		log.Printf("Failed to cache summary for article %d: %v", req.ArticleID, err)
-		// Don't fail the request if caching fails
-	}
+	return result, limitReached, usedFallback
+}
 
-	// Convert markdown summary to HTML (for all summaries, not just AI)
-	htmlSummary := textutil.ConvertMarkdownToHTML(result.Summary)
+// SummarizeArticle generates and caches the summary of an article that has none yet,
+// as done by the rules "summarize" action. AI requests are made with background priority.
+func SummarizeArticle(h *core.Handler, userID, articleID int64) error {
+	article, err := h.DB.GetArticleByID(articleID)
+	if err != nil || article == nil {
+		return err
+	}
+	if article.Summary != "" && article.Summary != "<no content>" {
+		return nil
+	}
 
-	resp := map[string]interface{}{
-		"summary":        result.Summary,
-		"html":           htmlSummary,
-		"sentence_count": result.SentenceCount,
-		"is_too_short":   result.IsTooShort,
-		"limit_reached":  limitReached,
-		"thinking":       result.Thinking,
+	content, err := getArticleContent(h, articleID, "")
+	if err != nil || content == "" {
+		return err
 	}
-	if usedFallback {
-		resp["used_fallback"] = true
+
+	summaryLength := summary.Medium
+	switch length, _ := h.DB.GetSettingWithFallback(userID, "summary_length"); length {
+	case "short":
+		summaryLength = summary.Short
+	case "long":
+		summaryLength = summary.Long
 	}
 
-	response.JSON(w, resp)
+	result, _, _ := generateSummary(h, userID, content, summaryLength, ai.PriorityBackground)
+	return h.DB.UpdateArticleSummary(articleID, result.Summary)
 }
 
 // getArticleContent fetches the content of an article by ID, or uses provided content
@@ -262,7 +297,22 @@ func getArticleContent(h *core.Handler, articleID int64, providedContent string)
 
 	// Otherwise, fetch from database/cache
 	content, _, err := h.GetArticleContent(articleID)
-	return content, err
+	if err != nil {
+		return content, err
+	}
+
+	// Podcast episodes are summarised from their transcript as well as their show notes
+	transcript, err := h.DB.GetPodcastTranscript(articleID)
+	if err != nil || transcript == "" {
+		return content, nil
+	}
+	var b strings.Builder
+	b.WriteString(content)
+	b.WriteString("<h2>Transcript</h2>")
+	for _, para := range strings.Split(transcript, "\n\n") {
+		b.WriteString("<p>" + html.EscapeString(para) + "</p>")
+	}
+	return b.String(), nil
 }
 
 // HandleClearSummaries clears all cached summaries from the database.
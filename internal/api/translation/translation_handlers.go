@@ -204,47 +204,85 @@ func HandleTranslateArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 	}
 
 	// Step 2: Proceed with translation
+	priority := ai.PriorityNormal
+	if req.Preemptive {
+		priority = ai.PriorityPreemptive
+	} else if req.HighPriority {
+		priority = ai.PriorityHigh
+	}
+	translatedTitle, limitReached, translateErr := translateTitle(h, userID, req.Title, req.TargetLang, priority)
+	if translateErr != nil {
+		response.Error(w, translateErr, http.StatusInternalServerError)
+		return
+	}
+
+	// Step 3: Post-translation check - if translation equals original, it was already in target language
+	// This provides a safety net in case pre-translation detection was inaccurate
+	if translatedTitle == req.Title {
+		// Still update DB with the "translated" text (which is the original)
+		if updateErr := h.DB.UpdateArticleTranslation(req.ArticleID, translatedTitle); updateErr != nil {
+			response.Error(w, updateErr, http.StatusInternalServerError)
+			return
+		}
+		response.JSON(w, map[string]interface{}{
+			"translated_title": translatedTitle,
+			"limit_reached":    limitReached,
+			"skipped":          true, // Indicate no actual translation was performed
+			"reason":           "translation_equals_original",
+		})
+		return
+	}
+
+	// Update the article with the translated title only if not skipping persistence
+	if !req.SkipPersistence {
+		if updateErr := h.DB.UpdateArticleTranslation(req.ArticleID, translatedTitle); updateErr != nil {
+			response.Error(w, updateErr, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"translated_title": translatedTitle,
+		"limit_reached":    limitReached,
+		"skipped":          false, // Translation was performed
+	})
+}
+
+// translateTitle translates an article title with the user's translation provider.
+// AI translations fall back to the non-AI provider when the usage limit is reached
+// or the request fails.
+func translateTitle(h *core.Handler, userID int64, title, targetLang string, priority ai.PriorityLevel) (translatedTitle string, limitReached bool, translateErr error) {
 	// Check if we should use AI translation or other provider
 	provider, _ := h.DB.GetSettingWithFallback(userID, "translation_provider")
 	isAIProvider := provider == "ai"
 
-	var translatedTitle string
-	var translateErr error
-	var limitReached = false
-
 	if isAIProvider {
 		// Check if AI usage limit is reached
 		if isAILimitReached(h, userID) {
 			log.Printf("AI usage limit reached for article translation, falling back to non-AI provider")
 			limitReached = true
 			// Fall back to non-AI provider gracefully
-			translatedTitle, translateErr = translation.TranslateMarkdownPreservingStructure(req.Title, h.Translator, req.TargetLang)
+			translatedTitle, translateErr = translation.TranslateMarkdownPreservingStructure(title, h.Translator, targetLang)
 		} else {
 			// Apply rate limiting for AI requests with priority
-			if req.Preemptive {
-				h.AITracker.WaitForRateLimitWithPriority(ai.PriorityPreemptive, userID)
-			} else if req.HighPriority {
-				h.AITracker.WaitForRateLimitWithPriority(ai.PriorityHigh, userID)
-			} else {
-				h.AITracker.WaitForRateLimitWithPriority(ai.PriorityNormal, userID)
-			}
+			h.AITracker.WaitForRateLimitWithPriority(priority, userID)
 
 			// Create AI translator directly with user-specific settings
 			aiTranslator, err := getAITranslatorForUser(h, userID)
 			if err != nil {
 				log.Printf("Failed to create AI translator, falling back to non-AI: %v", err)
-				translatedTitle, translateErr = translation.TranslateMarkdownPreservingStructure(req.Title, h.Translator, req.TargetLang)
+				translatedTitle, translateErr = translation.TranslateMarkdownPreservingStructure(title, h.Translator, targetLang)
 			} else {
 				// Use markdown-preserving translation for better list structure
-				translatedTitle, translateErr = translation.TranslateMarkdownAIPrompt(req.Title, aiTranslator, req.TargetLang)
+				translatedTitle, translateErr = translation.TranslateMarkdownAIPrompt(title, aiTranslator, targetLang)
 
 				// If AI fails, fall back to non-AI provider gracefully
 				if translateErr != nil {
 					log.Printf("AI translation failed, falling back to non-AI: %v", translateErr)
-					translatedTitle, translateErr = translation.TranslateMarkdownPreservingStructure(req.Title, h.Translator, req.TargetLang)
+					translatedTitle, translateErr = translation.TranslateMarkdownPreservingStructure(title, h.Translator, targetLang)
 				} else {
 					// Track AI usage only on success
-					inputTokens := ai.EstimateTokens(req.Title)
+					inputTokens := ai.EstimateTokens(title)
 					outputTokens := ai.EstimateTokens(translatedTitle)
 					totalTokens := inputTokens + outputTokens
 					addAIUsage(h, userID, totalTokens)
@@ -255,51 +293,45 @@ func HandleTranslateArticle(h *core.Handler, w http.ResponseWriter, r *http.Requ
 		// If even the fallback fails, return error
 		if translateErr != nil {
 			log.Printf("Translation failed even with fallback: %v", translateErr)
-			response.Error(w, translateErr, http.StatusInternalServerError)
-			return
 		}
 	} else {
 		// Non-AI provider, use original logic with h.Translator
-		translatedTitle, translateErr = translation.TranslateMarkdownPreservingStructure(req.Title, h.Translator, req.TargetLang)
+		translatedTitle, translateErr = translation.TranslateMarkdownPreservingStructure(title, h.Translator, targetLang)
 		
 		// If translation fails, return error
 		if translateErr != nil {
 			log.Printf("Translation failed for provider %s: %v", provider, translateErr)
-			response.Error(w, translateErr, http.StatusInternalServerError)
-			return
 		}
 	}
 
-	// Step 3: Post-translation check - if translation equals original, it was already in target language
-	// This provides a safety net in case pre-translation detection was inaccurate
-	if translatedTitle == req.Title {
-		// Still update DB with the "translated" text (which is the original)
-		if updateErr := h.DB.UpdateArticleTranslation(req.ArticleID, translatedTitle); updateErr != nil {
-			response.Error(w, updateErr, http.StatusInternalServerError)
-			return
-		}
-		response.JSON(w, map[string]interface{}{
-			"translated_title": translatedTitle,
-			"limit_reached":    limitReached,
-			"skipped":          true, // Indicate no actual translation was performed
-			"reason":           "translation_equals_original",
-		})
-		return
+	return translatedTitle, limitReached, translateErr
+}
+
+// TranslateArticle translates the title of an article that has no translation yet to the
+// user's target language, as done by the rules "translate" action. AI requests are made
+// with background priority.
+func TranslateArticle(h *core.Handler, userID, articleID int64) error {
+	article, err := h.DB.GetArticleByID(articleID)
+	if err != nil || article == nil {
+		return err
+	}
+	if article.TranslatedTitle != "" && article.TranslatedTitle != article.Title {
+		return nil
 	}
 
-	// Update the article with the translated title only if not skipping persistence
-	if !req.SkipPersistence {
-		if updateErr := h.DB.UpdateArticleTranslation(req.ArticleID, translatedTitle); updateErr != nil {
-			response.Error(w, updateErr, http.StatusInternalServerError)
-			return
-		}
+	targetLang, _ := h.DB.GetSettingWithFallback(userID, "target_language")
+	if targetLang == "" || article.Title == "" {
+		return nil
+	}
+	if !translation.GetLanguageDetector().ShouldTranslate(article.Title, targetLang) {
+		return h.DB.UpdateArticleTranslation(articleID, article.Title)
 	}
 
-	response.JSON(w, map[string]interface{}{
-		"translated_title": translatedTitle,
-		"limit_reached":    limitReached,
-		"skipped":          false, // Translation was performed
-	})
+	translatedTitle, _, err := translateTitle(h, userID, article.Title, targetLang, ai.PriorityBackground)
+	if err != nil {
+		return err
+	}
+	return h.DB.UpdateArticleTranslation(articleID, translatedTitle)
 }
 
 // HandleClearTranslations clears all translated titles from the database.
@@ -0,0 +1,76 @@
+package websub
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/response"
+	"MavenRSS/internal/feed"
+)
+
+// HandleCallback is the WebSub subscriber callback.
+// GET requests are hub verifications of (un)subscription intent, POST requests deliver content.
+// @Summary      WebSub subscriber callback
+// @Description  GET answers hub verification requests by echoing hub.challenge.
+// @Description  POST receives pushed feed content, signed with X-Hub-Signature, and saves its items.
+// @Tags         websub
+// @Param        token           path      string  true   "Subscription callback token"
+// @Param        hub.mode        query     string  false  "subscribe, unsubscribe or denied (GET)"
+// @Param        hub.topic       query     string  false  "Topic URL (GET)"
+// @Param        hub.challenge   query     string  false  "Challenge to echo back (GET)"
+// @Param        hub.lease_seconds  query  int  false  "Granted lease in seconds (GET)"
+// @Success      200  {string}  string  "Challenge (GET)"
+// @Success      202  {string}  string  "Content accepted (POST)"
+// @Failure      404  {object}  map[string]string  "Unknown callback or refused verification"
+// @Failure      413  {object}  map[string]string  "Payload too large"
+// @Router       /websub/callback/{token} [get]
+// @Router       /websub/callback/{token} [post]
+func HandleCallback(h *core.Handler, w http.ResponseWriter, r *http.Request) {
+	manager := h.Fetcher.GetWebSubManager()
+	if manager == nil {
+		response.Error(w, fmt.Errorf("WebSub is disabled"), http.StatusNotFound)
+		return
+	}
+	token := r.PathValue("token")
+
+	switch r.Method {
+	case http.MethodGet:
+		challenge, err := manager.VerifyIntent(token, r.URL.Query())
+		if err != nil {
+			response.Error(w, err, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, challenge)
+
+	case http.MethodPost:
+		body, err := io.ReadAll(io.LimitReader(r.Body, feed.MaxWebSubPayloadSize+1))
+		if err != nil {
+			response.Error(w, err, http.StatusBadRequest)
+			return
+		}
+		if len(body) > feed.MaxWebSubPayloadSize {
+			response.Error(w, fmt.Errorf("payload too large"), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		err = manager.HandlePush(r.Context(), token, body, r.Header.Get("X-Hub-Signature"))
+		if errors.Is(err, feed.ErrWebSubUnknownCallback) {
+			response.Error(w, err, http.StatusNotFound)
+			return
+		}
+		// Content that can't be verified or processed is still acknowledged,
+		// as required by the spec, so the hub doesn't keep retrying it
+		if err != nil {
+			log.Printf("WebSub: ignoring pushed content: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+
+	default:
+		response.Error(w, nil, http.StatusMethodNotAllowed)
+	}
+}
@@ -23,6 +23,7 @@ type JWTManager struct {
 	secretKey     []byte
 	accessTokenTTL time.Duration
 	refreshTokenTTL time.Duration
+	apiTokenTTL     time.Duration
 }
 
 func NewJWTManager(secretKey string) *JWTManager {
@@ -30,6 +31,7 @@ func NewJWTManager(secretKey string) *JWTManager {
 		secretKey:      []byte(secretKey),
 		accessTokenTTL: 1 * time.Hour,   // 1 hour for security
 		refreshTokenTTL: 30 * 24 * time.Hour,
+		apiTokenTTL:     30 * 24 * time.Hour,
 	}
 }
 
@@ -69,6 +71,23 @@ func (jm *JWTManager) GenerateTokens(userID int64, username, role string) (acces
 	return accessToken, refreshToken, nil
 }
 
+// GenerateAPIToken issues a long-lived access token for third-party sync clients
+// (e.g. Google Reader API clients) that cannot use the refresh token flow.
+func (jm *JWTManager) GenerateAPIToken(userID int64, username, role string) (string, error) {
+	claims := &Claims{
+		UserID:   userID,
+		Username: username,
+		Role:     role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(jm.apiTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jm.secretKey)
+}
+
 func (jm *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
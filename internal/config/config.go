@@ -54,6 +54,7 @@ type Defaults struct {
 	DeeplAPIKey string                  `json:"deepl_api_key"`
 	DeeplEndpoint string                `json:"deepl_endpoint"`
 	DefaultViewMode string              `json:"default_view_mode"`
+	FeedAutoRediscover bool             `json:"feed_auto_rediscover"`
 	FeedDrawerExpanded bool             `json:"feed_drawer_expanded"`
 	FeedDrawerPinned bool               `json:"feed_drawer_pinned"`
 	FreshRSSAPIPassword string          `json:"freshrss_api_password"`
@@ -65,6 +66,7 @@ type Defaults struct {
 	FreshRSSUsername string             `json:"freshrss_username"`
 	FullTextFetchEnabled bool           `json:"full_text_fetch_enabled"`
 	GoogleTranslateEndpoint string      `json:"google_translate_endpoint"`
+	HostRefreshSpacingMs string         `json:"host_refresh_spacing_ms"`
 	HoverMarkAsRead bool                `json:"hover_mark_as_read"`
 	IdleConnTimeoutSeconds int          `json:"idle_conn_timeout_seconds"`
 	ImageGalleryEnabled bool            `json:"image_gallery_enabled"`
@@ -75,6 +77,7 @@ type Defaults struct {
 	MaxArticleAgeDays int               `json:"max_article_age_days"`
 	MaxCacheSizeMb int                  `json:"max_cache_size_mb"`
 	MaxConcurrentRefreshes string       `json:"max_concurrent_refreshes"`
+	MaxConcurrentRefreshesPerHost string`json:"max_concurrent_refreshes_per_host"`
 	MaxConnsPerHost int                 `json:"max_conns_per_host"`
 	MaxIdleConns int                    `json:"max_idle_conns"`
 	MaxIdleConnsPerHost int             `json:"max_idle_conns_per_host"`
@@ -99,6 +102,7 @@ type Defaults struct {
 	ProxyType string                    `json:"proxy_type"`
 	ProxyUsername string                `json:"proxy_username"`
 	RefreshMode string                  `json:"refresh_mode"`
+	RespectRobotsTxt bool               `json:"respect_robots_txt"`
 	RetryTimeoutSeconds int             `json:"retry_timeout_seconds"`
 	RsshubAPIKey string                 `json:"rsshub_api_key"`
 	RsshubEnabled bool                  `json:"rsshub_enabled"`
@@ -214,6 +218,8 @@ func GetString(key string) string {
 		return defaults.DeeplEndpoint
 	case "default_view_mode":
 		return defaults.DefaultViewMode
+	case "feed_auto_rediscover":
+		return strconv.FormatBool(defaults.FeedAutoRediscover)
 	case "feed_drawer_expanded":
 		return strconv.FormatBool(defaults.FeedDrawerExpanded)
 	case "feed_drawer_pinned":
@@ -236,6 +242,8 @@ func GetString(key string) string {
 		return strconv.FormatBool(defaults.FullTextFetchEnabled)
 	case "google_translate_endpoint":
 		return defaults.GoogleTranslateEndpoint
+	case "host_refresh_spacing_ms":
+		return defaults.HostRefreshSpacingMs
 	case "hover_mark_as_read":
 		return strconv.FormatBool(defaults.HoverMarkAsRead)
 	case "idle_conn_timeout_seconds":
@@ -256,6 +264,8 @@ func GetString(key string) string {
 		return strconv.Itoa(defaults.MaxCacheSizeMb)
 	case "max_concurrent_refreshes":
 		return defaults.MaxConcurrentRefreshes
+	case "max_concurrent_refreshes_per_host":
+		return defaults.MaxConcurrentRefreshesPerHost
 	case "max_conns_per_host":
 		return strconv.Itoa(defaults.MaxConnsPerHost)
 	case "max_idle_conns":
@@ -304,6 +314,8 @@ func GetString(key string) string {
 		return defaults.ProxyUsername
 	case "refresh_mode":
 		return defaults.RefreshMode
+	case "respect_robots_txt":
+		return strconv.FormatBool(defaults.RespectRobotsTxt)
 	case "retry_timeout_seconds":
 		return strconv.Itoa(defaults.RetryTimeoutSeconds)
 	case "rsshub_api_key":
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"MavenRSS/internal/models"
 )
 
 func TestNewService(t *testing.T) {
@@ -231,7 +233,7 @@ func TestFindRSSFeed_LinkInHead(t *testing.T) {
 
 	s := newServiceWithClient(srv.Client())
 	// Updated signature: ctx, url, maxPathConcurrency
-	feedURL, err := s.findRSSFeed(context.Background(), srv.URL, 0)
+	feedURL, _, err := s.findRSSFeed(context.Background(), srv.URL, 0)
 	if err != nil {
 		t.Fatalf("findRSSFeed error: %v", err)
 	}
@@ -290,3 +292,37 @@ func TestGetFaviconAndResolveURLAndExtractLinks(t *testing.T) {
 		t.Fatalf("resolveURL failed: %s", resolved)
 	}
 }
+
+func TestFindRSSFeed_JSONFeedAndHFeed(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><link rel="alternate" type="application/feed+json" href="/json/feed.json"></head></html>`))
+	})
+	mux.HandleFunc("/json/feed.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/feed+json")
+		_, _ = w.Write([]byte(`{"version": "https://jsonfeed.org/version/1.1", "title": "J", "items": []}`))
+	})
+	mux.HandleFunc("/notes/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><div class="h-feed"><article class="h-entry"><a class="u-url p-name" href="/notes/1">Note</a></article></div></body></html>`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	s := newServiceWithClient(srv.Client())
+	feedURL, feedType, err := s.findRSSFeed(context.Background(), srv.URL+"/json/", 0)
+	if err != nil || !strings.HasSuffix(feedURL, "/json/feed.json") || feedType != models.FeedTypeJSONFeed {
+		t.Fatalf("unexpected JSON Feed discovery: %s %q %v", feedURL, feedType, err)
+	}
+
+	blog, err := s.discoverBlogRSS(context.Background(), srv.URL+"/notes/", 0)
+	if err != nil {
+		t.Fatalf("discoverBlogRSS error: %v", err)
+	}
+	if blog.RSSFeed != srv.URL+"/notes/" || blog.FeedType != models.FeedTypeHFeed || len(blog.RecentArticles) != 1 {
+		t.Fatalf("unexpected h-feed discovery: %+v", blog)
+	}
+}
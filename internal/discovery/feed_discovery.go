@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"time"
 
+	"MavenRSS/internal/feed/source"
 	"MavenRSS/internal/utils/httputil"
 
 	"github.com/PuerkitoBio/goquery"
@@ -64,6 +65,10 @@ func (s *Service) DiscoverFromFeedWithProgress(ctx context.Context, feedURL stri
 func (s *Service) getFeedHomepage(ctx context.Context, feedURL string) (string, error) {
 	feed, err := s.feedParser.ParseURLWithContext(feedURL, ctx)
 	if err != nil {
+		// h-feed subscriptions point to an HTML page, which is the homepage itself
+		if doc, htmlErr := s.fetchHTML(ctx, feedURL); htmlErr == nil && source.HasHFeed(doc) {
+			return feedURL, nil
+		}
 		return "", err
 	}
 
@@ -9,7 +9,11 @@ import (
 	"strings"
 	"sync"
 
+	"MavenRSS/internal/feed/source"
+	"MavenRSS/internal/models"
+
 	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
 )
 
 // discoverRSSFeedsWithProgress discovers RSS feeds with progress updates
@@ -86,13 +90,13 @@ OuterLoop:
 // discoverBlogRSS discovers RSS feed for a single blog
 func (s *Service) discoverBlogRSS(ctx context.Context, blogURL string, maxPathConcurrency int) (DiscoveredBlog, error) {
 	// Try to find RSS feed URL
-	rssURL, err := s.findRSSFeed(ctx, blogURL, maxPathConcurrency)
+	rssURL, feedType, err := s.findRSSFeed(ctx, blogURL, maxPathConcurrency)
 	if err != nil {
 		return DiscoveredBlog{}, err
 	}
 
-	// Parse the RSS feed to get blog info
-	feed, err := s.feedParser.ParseURLWithContext(rssURL, ctx)
+	// Parse the feed to get blog info
+	feed, err := s.parseFeed(ctx, rssURL, feedType)
 	if err != nil {
 		return DiscoveredBlog{}, err
 	}
@@ -119,17 +123,27 @@ func (s *Service) discoverBlogRSS(ctx context.Context, blogURL string, maxPathCo
 		Name:           feed.Title,
 		Homepage:       blogURL,
 		RSSFeed:        rssURL,
+		FeedType:       feedType,
 		IconURL:        iconURL,
 		RecentArticles: recentArticles,
 	}, nil
 }
 
-// findRSSFeed finds the RSS feed URL for a blog
-func (s *Service) findRSSFeed(ctx context.Context, blogURL string, maxPathConcurrency int) (string, error) {
+// FindFeed finds the feed URL of a website from its homepage, e.g. to replace
+// the URL of a feed that disappeared. The feed type is returned as by findRSSFeed.
+func (s *Service) FindFeed(ctx context.Context, homepage string) (string, string, error) {
+	return s.findRSSFeed(ctx, homepage, 0)
+}
+
+// findRSSFeed finds the feed URL for a blog.
+// It also returns the feed type to subscribe with: "" for RSS/Atom,
+// models.FeedTypeJSONFeed for JSON Feed and models.FeedTypeHFeed when the
+// page itself is an h-feed.
+func (s *Service) findRSSFeed(ctx context.Context, blogURL string, maxPathConcurrency int) (string, string, error) {
 	// Common RSS feed paths to try
 	u, err := url.Parse(blogURL)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	baseURL := fmt.Sprintf("%s://%s", u.Scheme, u.Host)
@@ -137,18 +151,21 @@ func (s *Service) findRSSFeed(ctx context.Context, blogURL string, maxPathConcur
 	// First, try to parse HTML and find RSS link in <head> - this is usually the most reliable
 	doc, err := s.fetchHTML(ctx, blogURL)
 	if err == nil {
-		var foundFeed string
-		doc.Find("link[type='application/rss+xml'], link[type='application/atom+xml'], link[rel='alternate'][type*='xml']").Each(func(i int, sel *goquery.Selection) {
+		var foundFeed, foundType string
+		doc.Find("link[type='application/rss+xml'], link[type='application/atom+xml'], link[rel='alternate'][type*='xml'], link[type='application/feed+json'], link[rel='alternate'][type='application/json']").Each(func(i int, sel *goquery.Selection) {
 			if foundFeed != "" {
 				return
 			}
 			if href, exists := sel.Attr("href"); exists {
 				foundFeed = s.resolveURL(blogURL, href)
+				if strings.Contains(sel.AttrOr("type", ""), "json") {
+					foundType = models.FeedTypeJSONFeed
+				}
 			}
 		})
 
 		if foundFeed != "" && s.isValidFeed(ctx, foundFeed) {
-			return foundFeed, nil
+			return foundFeed, foundType, nil
 		}
 	}
 
@@ -175,6 +192,8 @@ func (s *Service) findRSSFeed(ctx context.Context, blogURL string, maxPathConcur
 		"/rss2.xml",
 		"/feed.atom",
 		"/feed.rss",
+		"/feed.json", // JSON Feed
+		"/index.json",
 	}
 
 	// Try common paths concurrently for faster discovery
@@ -212,11 +231,35 @@ func (s *Service) findRSSFeed(ctx context.Context, blogURL string, maxPathConcur
 	// Return the first valid feed found
 	for result := range resultCh {
 		if result.valid {
-			return result.url, nil
+			if strings.HasSuffix(result.url, ".json") {
+				return result.url, models.FeedTypeJSONFeed, nil
+			}
+			return result.url, "", nil
 		}
 	}
 
-	return "", errRSSFeedNotFound
+	// Finally, the page itself may be an h-feed
+	if doc != nil && source.HasHFeed(doc) {
+		return blogURL, models.FeedTypeHFeed, nil
+	}
+
+	return "", "", errRSSFeedNotFound
+}
+
+// parseFeed parses a discovered feed of the given type
+func (s *Service) parseFeed(ctx context.Context, feedURL, feedType string) (*gofeed.Feed, error) {
+	if feedType == models.FeedTypeHFeed {
+		doc, err := s.fetchHTML(ctx, feedURL)
+		if err != nil {
+			return nil, err
+		}
+		feed := source.ParseHFeed(doc, feedURL)
+		if len(feed.Items) == 0 {
+			return nil, errRSSFeedNotFound
+		}
+		return feed, nil
+	}
+	return s.feedParser.ParseURLWithContext(feedURL, ctx)
 }
 
 // isValidFeed checks if a URL is a valid RSS/Atom feed
@@ -260,11 +303,12 @@ func (s *Service) isValidFeed(ctx context.Context, feedURL string) bool {
 		}
 		content := string(buf[:n])
 
-		// Check for XML declaration and RSS/Atom tags
+		// Check for XML declaration and RSS/Atom tags, or a JSON Feed version
 		if strings.Contains(content, "<?xml") ||
 			strings.Contains(content, "<rss") ||
 			strings.Contains(content, "<feed") ||
-			strings.Contains(content, "<atom") {
+			strings.Contains(content, "<atom") ||
+			source.IsJSONFeed(buf[:n]) {
 			return true
 		}
 		return false
@@ -273,7 +317,8 @@ func (s *Service) isValidFeed(ctx context.Context, feedURL string) bool {
 	contentType := resp.Header.Get("Content-Type")
 	return strings.Contains(contentType, "xml") ||
 		strings.Contains(contentType, "rss") ||
-		strings.Contains(contentType, "atom")
+		strings.Contains(contentType, "atom") ||
+		strings.Contains(contentType, "feed+json")
 }
 
 // getFavicon gets the favicon URL for a blog
@@ -24,6 +24,7 @@ type DiscoveredBlog struct {
 	Name           string          `json:"name"`
 	Homepage       string          `json:"homepage"`
 	RSSFeed        string          `json:"rss_feed"`
+	FeedType       string          `json:"feed_type,omitempty"` // Feed type to subscribe with, empty for RSS/Atom
 	IconURL        string          `json:"icon_url"`
 	RecentArticles []RecentArticle `json:"recent_articles"`
 }
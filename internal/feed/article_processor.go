@@ -112,6 +112,7 @@ func (f *Fetcher) processArticles(feed models.Feed, items []*gofeed.Item) []*Art
 			HasValidPublishedTime: hasValidPublishedTime,
 			TranslatedTitle:       translatedTitle,
 			Author:                author,
+			PodcastEpisode:        extractPodcastEpisode(item, feed.URL),
 		}
 
 		articlesWithContent = append(articlesWithContent, &ArticleWithContent{
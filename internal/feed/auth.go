@@ -0,0 +1,165 @@
+package feed
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/utils/httputil"
+)
+
+// maxCookiesSize caps the cookies.txt content stored for a feed
+const maxCookiesSize = 512 * 1024
+
+// ValidateFeedAuth checks the credentials of a feed before they are stored
+func ValidateFeedAuth(auth *models.FeedAuth) error {
+	if auth == nil {
+		return nil
+	}
+	switch auth.Type {
+	case "":
+	case models.FeedAuthBasic:
+		if auth.Username == "" {
+			return errors.New("basic authentication requires a username")
+		}
+	case models.FeedAuthBearer:
+		if auth.Token == "" {
+			return errors.New("bearer authentication requires a token")
+		}
+	default:
+		return fmt.Errorf("unsupported authentication type '%s'", auth.Type)
+	}
+	for name, value := range auth.Headers {
+		if !validHeaderName(name) {
+			return fmt.Errorf("invalid header name '%s'", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid value for header '%s'", name)
+		}
+	}
+	if auth.Cookies != "" {
+		if len(auth.Cookies) > maxCookiesSize {
+			return errors.New("cookies file is too large")
+		}
+		if _, err := httputil.ParseNetscapeCookies(strings.NewReader(auth.Cookies)); err != nil {
+			return fmt.Errorf("invalid cookies file: %w", err)
+		}
+	}
+	return nil
+}
+
+// validHeaderName reports whether a header name is an HTTP token
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c > 0x7e || c <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// feedAuthUserAgent returns the User-Agent header configured for a feed, or the fallback
+func feedAuthUserAgent(feed models.Feed, fallback string) string {
+	if feed.Auth != nil {
+		for name, value := range feed.Auth.Headers {
+			if strings.EqualFold(name, "User-Agent") && value != "" {
+				return value
+			}
+		}
+	}
+	return fallback
+}
+
+// WithFeedAuth returns a copy of the client sending the credentials, cookies and
+// custom headers of the feed. Credentials and headers are only sent to the feed's
+// host; cookies are sent to the domains they were set for.
+func WithFeedAuth(client *http.Client, feed models.Feed) *http.Client {
+	auth := feed.Auth
+	if auth == nil {
+		return client
+	}
+	u, err := url.Parse(feed.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return client
+	}
+
+	authClient := *client
+	if auth.Type != "" || len(auth.Headers) > 0 {
+		authClient.Transport = &feedAuthTransport{
+			original: client.Transport,
+			host:     strings.ToLower(u.Host),
+			auth:     auth,
+		}
+	}
+	if auth.Cookies != "" {
+		jar, err := httputil.NewCookieJar(auth.Cookies)
+		if err != nil {
+			log.Printf("Ignoring the cookies of feed %s: %v", feed.Title, err)
+		} else {
+			authClient.Jar = jar
+		}
+	}
+	return &authClient
+}
+
+// feedAuthTransport adds the credentials and custom headers of a feed to the
+// requests sent to the feed's host
+type feedAuthTransport struct {
+	original http.RoundTripper
+	host     string
+	auth     *models.FeedAuth
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *feedAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	original := t.original
+	if original == nil {
+		original = http.DefaultTransport
+	}
+	if !strings.EqualFold(req.URL.Host, t.host) {
+		return original.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the request
+	req = req.Clone(req.Context())
+	for name, value := range t.auth.Headers {
+		req.Header.Set(name, value)
+	}
+	switch t.auth.Type {
+	case models.FeedAuthBasic:
+		req.SetBasicAuth(t.auth.Username, t.auth.Password)
+	case models.FeedAuthBearer:
+		req.Header.Set("Authorization", "Bearer "+t.auth.Token)
+	}
+	return original.RoundTrip(req)
+}
+
+// MergeRedactedSecrets keeps the stored secrets that were sent back as models.RedactedSecret
+func MergeRedactedSecrets(auth, stored *models.FeedAuth) {
+	if auth == nil {
+		return
+	}
+	if stored == nil {
+		stored = &models.FeedAuth{}
+	}
+	keep := func(secret *string, storedSecret string) {
+		if *secret == models.RedactedSecret {
+			*secret = storedSecret
+		}
+	}
+	keep(&auth.Password, stored.Password)
+	keep(&auth.Token, stored.Token)
+	keep(&auth.Cookies, stored.Cookies)
+	for name, value := range auth.Headers {
+		if value == models.RedactedSecret {
+			auth.Headers[name] = stored.Headers[name]
+		}
+	}
+}
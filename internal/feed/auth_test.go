@@ -0,0 +1,125 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"MavenRSS/internal/models"
+)
+
+const authTestCookies = "# Netscape HTTP Cookie File\n" +
+	"127.0.0.1\tFALSE\t/\tFALSE\t0\tsession\tabc123\n" +
+	"#HttpOnly_127.0.0.1\tFALSE\t/\tFALSE\t1\texpired\tgone\n"
+
+func TestFetchFeedWithContext_FeedAuth(t *testing.T) {
+	fetcher, db := newFailuresTestFetcher(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		session, err := r.Cookie("session")
+		if !ok || user != "reader" || pass != "s3cret" || r.Header.Get("X-Api-Key") != "key" || err != nil || session.Value != "abc123" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if _, err := r.Cookie("expired"); err == nil {
+			http.Error(w, "expired cookie sent", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(failuresTestRSS))
+	}))
+	defer srv.Close()
+
+	id, _ := db.AddFeed(&models.Feed{Title: "private", URL: srv.URL})
+	feed, _ := db.GetFeedByID(id)
+	if err := fetcher.fetchFeedWithContext(context.Background(), *feed); err == nil {
+		t.Fatal("expected the fetch without credentials to fail")
+	}
+
+	auth := &models.FeedAuth{
+		Type:     models.FeedAuthBasic,
+		Username: "reader",
+		Password: "s3cret",
+		Cookies:  authTestCookies,
+		Headers:  map[string]string{"X-Api-Key": "key"},
+	}
+	if err := ValidateFeedAuth(auth); err != nil {
+		t.Fatalf("ValidateFeedAuth: %v", err)
+	}
+	if err := db.SetFeedAuth(id, auth); err != nil {
+		t.Fatalf("SetFeedAuth: %v", err)
+	}
+
+	var stored string
+	db.QueryRow("SELECT http_auth FROM feeds WHERE id = ?", id).Scan(&stored)
+	if stored == "" || strings.Contains(stored, "s3cret") {
+		t.Fatalf("credentials not stored encrypted: %q", stored)
+	}
+
+	feed, _ = db.GetFeedByID(id)
+	if feed.Auth == nil || feed.Auth.Password != "s3cret" {
+		t.Fatalf("credentials not loaded: %+v", feed.Auth)
+	}
+	if err := fetcher.fetchFeedWithContext(context.Background(), *feed); err != nil {
+		t.Fatalf("fetch with credentials: %v", err)
+	}
+}
+
+func TestFeedAuthTransport_OnlyFeedHost(t *testing.T) {
+	var leaked bool
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Get("Authorization") != "" || r.Header.Get("X-Api-Key") != ""
+	}))
+	defer other.Close()
+
+	feed := models.Feed{
+		URL:  "http://feeds.example.com/rss",
+		Auth: &models.FeedAuth{Type: models.FeedAuthBearer, Token: "token", Headers: map[string]string{"X-Api-Key": "key"}},
+	}
+	client := WithFeedAuth(other.Client(), feed)
+	resp, err := client.Get(other.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if leaked {
+		t.Error("feed credentials sent to another host")
+	}
+}
+
+func TestValidateFeedAuth(t *testing.T) {
+	tests := []struct {
+		name  string
+		auth  *models.FeedAuth
+		valid bool
+	}{
+		{"none", nil, true},
+		{"basic", &models.FeedAuth{Type: models.FeedAuthBasic, Username: "u"}, true},
+		{"basic without username", &models.FeedAuth{Type: models.FeedAuthBasic}, false},
+		{"bearer without token", &models.FeedAuth{Type: models.FeedAuthBearer}, false},
+		{"unknown type", &models.FeedAuth{Type: "digest"}, false},
+		{"invalid header name", &models.FeedAuth{Headers: map[string]string{"X Key": "v"}}, false},
+		{"header injection", &models.FeedAuth{Headers: map[string]string{"X-Key": "v\r\nHost: evil"}}, false},
+		{"invalid cookies", &models.FeedAuth{Cookies: "not a cookies file"}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateFeedAuth(tt.auth); (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateFeedAuth = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestMergeRedactedSecrets(t *testing.T) {
+	stored := &models.FeedAuth{Type: models.FeedAuthBasic, Username: "u", Password: "p", Headers: map[string]string{"X-Api-Key": "key"}}
+	auth := stored.Redacted()
+	if auth.Password != models.RedactedSecret || auth.Headers["X-Api-Key"] != models.RedactedSecret {
+		t.Fatalf("secrets not redacted: %+v", auth)
+	}
+	auth.Username = "v"
+	MergeRedactedSecrets(auth, stored)
+	if auth.Username != "v" || auth.Password != "p" || auth.Headers["X-Api-Key"] != "key" {
+		t.Errorf("unexpected merged auth %+v", auth)
+	}
+}
@@ -0,0 +1,35 @@
+package feed
+
+import (
+	"log"
+
+	"MavenRSS/internal/utils"
+	"MavenRSS/internal/utils/textutil"
+)
+
+// clusterArticles fingerprints newly saved articles and groups each with its
+// near-duplicates from other feeds, so the same story syndicated by several
+// outlets can be collapsed and read once.
+func (f *Fetcher) clusterArticles(articlesWithContent []*ArticleWithContent) {
+	for _, awc := range articlesWithContent {
+		fingerprint, ok := textutil.Fingerprint(awc.Article.Title, textutil.StripHTML(awc.Content))
+		if !ok {
+			continue
+		}
+
+		articleID, err := f.findArticleID(awc.Article)
+		if err != nil {
+			utils.DebugLog("Could not find article ID for clustering %s: %v", awc.Article.Title, err)
+			continue
+		}
+
+		clusterID, err := f.db.AssignArticleCluster(awc.Article.UserID, articleID, fingerprint, awc.Article.PublishedAt)
+		if err != nil {
+			log.Printf("Error clustering article %d: %v", articleID, err)
+			continue
+		}
+		if clusterID != articleID {
+			utils.DebugLog("Article %d is a near-duplicate in cluster %d", articleID, clusterID)
+		}
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
+	"log"
+	"net/url"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -33,7 +36,8 @@ func NewEmailFetcher(db *sqlite.DB) *EmailFetcher {
 	}
 }
 
-// FetchEmails fetches new emails from IMAP and converts them to feed items
+// FetchEmails fetches new emails from the IMAP folders of a feed and converts them to feed items.
+// A folder that can't be read is skipped; an error is returned only if no folder could be read.
 func (ef *EmailFetcher) FetchEmails(ctx context.Context, feed *models.Feed) ([]*gofeed.Item, error) {
 	if feed.EmailIMAPServer == "" || feed.EmailUsername == "" || feed.EmailPassword == "" {
 		return nil, fmt.Errorf("IMAP credentials not configured")
@@ -46,38 +50,86 @@ func (ef *EmailFetcher) FetchEmails(ctx context.Context, feed *models.Feed) ([]*
 	}
 	defer c.Logout()
 
-	// Select mailbox
-	_, err = c.Select(feed.EmailFolder, false)
+	senders := parseEmailSenders(feed.EmailSenders)
+	var items []*gofeed.Item
+	var lastErr error
+	read := 0
+	for i, folder := range emailFolders(feed.EmailFolder) {
+		if err := ctx.Err(); err != nil {
+			lastErr = err
+			break
+		}
+		folderItems, err := ef.fetchFolder(c, feed, folder, i == 0, senders)
+		if err != nil {
+			// The folders read before have saved their sync state, so their
+			// items are returned whatever happens to the other folders
+			log.Printf("Error fetching folder %s of feed %s: %v", folder, feed.Title, err)
+			lastErr = err
+			continue
+		}
+		read++
+		items = append(items, folderItems...)
+	}
+	if read == 0 {
+		return nil, lastErr
+	}
+	return items, nil
+}
+
+// fetchFolder fetches the messages received in a folder since its last sync
+func (ef *EmailFetcher) fetchFolder(c *client.Client, feed *models.Feed, folder string, first bool, senders []string) ([]*gofeed.Item, error) {
+	mbox, err := c.Select(folder, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to select mailbox %s: %w", feed.EmailFolder, err)
+		return nil, fmt.Errorf("failed to select mailbox %s: %w", folder, err)
 	}
 
-	// Determine UID range for fetching
-	fromUID := uint32(1)
-	if feed.EmailLastUID > 0 {
-		fromUID = uint32(feed.EmailLastUID + 1)
+	state, err := ef.db.GetEmailSyncState(feed.ID, folder)
+	if err != nil {
+		return nil, err
 	}
+	if state == nil {
+		state = &sqlite.EmailSyncState{FeedID: feed.ID, Folder: folder}
+		// Feeds synced before folder states were kept only have the last UID of their folder
+		if first {
+			state.LastUID = uint32(feed.EmailLastUID)
+		}
+	}
+	since := time.Now().AddDate(0, -1, 0) // Last 1 month
+	lastUID, resync := nextEmailSync(state, mbox.UidValidity)
+	if resync {
+		// The folder was recreated or renumbered, its UIDs don't match the stored
+		// ones anymore. Rescan what arrived since the last sync: messages imported
+		// before keep their identity (Message-Id and date) and aren't duplicated.
+		log.Printf("UIDVALIDITY of %s changed for feed %s, resyncing", folder, feed.Title)
+		if lastSync := state.UpdatedAt.AddDate(0, 0, -1); lastSync.After(since) {
+			since = lastSync
+		}
+	}
+	state.UIDValidity = mbox.UidValidity
 
 	// Search for emails newer than last processed UID
 	criteria := imap.NewSearchCriteria()
 	seqset := new(imap.SeqSet)
-	seqset.AddRange(fromUID, ^uint32(0)) // Use ^uint32(0) as max UID
+	seqset.AddRange(lastUID+1, 0) // "N:*" (also matches the highest UID when it's below N)
 	criteria.Uid = seqset
-	criteria.Since = time.Now().AddDate(0, -1, 0) // Last 1 month
+	criteria.Since = since
 
-	uids, err := c.Search(criteria)
+	found, err := c.UidSearch(criteria)
 	if err != nil {
 		return nil, fmt.Errorf("IMAP search failed: %w", err)
 	}
-
-	if len(uids) == 0 {
-		return nil, nil
+	uids := make([]uint32, 0, len(found))
+	for _, uid := range found {
+		if uid > lastUID {
+			uids = append(uids, uid)
+		}
 	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
 
 	// Fetch emails in batches
 	batchSize := 50
 	items := make([]*gofeed.Item, 0, len(uids))
-	maxUID := feed.EmailLastUID
+	state.LastUID = lastUID
 
 	for i := 0; i < len(uids); i += batchSize {
 		end := i + batchSize
@@ -86,26 +138,75 @@ func (ef *EmailFetcher) FetchEmails(ctx context.Context, feed *models.Feed) ([]*
 		}
 		batchUIDs := uids[i:end]
 
-		// Track max UID in this batch
-		if int(batchUIDs[len(batchUIDs)-1]) > maxUID {
-			maxUID = int(batchUIDs[len(batchUIDs)-1])
-		}
-
-		batchItems, err := ef.fetchEmailBatch(c, batchUIDs)
+		batchItems, err := ef.fetchEmailBatch(c, batchUIDs, folder, mbox.UidValidity, senders)
 		if err != nil {
 			return nil, err
 		}
 		items = append(items, batchItems...)
+		state.LastUID = batchUIDs[len(batchUIDs)-1]
+	}
+
+	if err := ef.db.SaveEmailSyncState(state); err != nil {
+		return items, fmt.Errorf("failed to update sync state: %w", err)
 	}
+	return items, nil
+}
+
+// nextEmailSync returns the UID after which a folder's messages are new, and whether
+// the folder's UIDVALIDITY changed since the last sync, invalidating its last UID
+func nextEmailSync(state *sqlite.EmailSyncState, uidValidity uint32) (lastUID uint32, resync bool) {
+	if state.UIDValidity != 0 && state.UIDValidity != uidValidity {
+		return 0, true
+	}
+	return state.LastUID, false
+}
 
-	// Update last UID if we processed new emails
-	if maxUID > feed.EmailLastUID {
-		if err := ef.db.UpdateFeedEmailLastUID(feed.ID, maxUID); err != nil {
-			return items, fmt.Errorf("failed to update last UID: %w", err)
+// emailFolders returns the folders of a comma-separated list, INBOX by default
+func emailFolders(list string) []string {
+	var folders []string
+	seen := make(map[string]bool)
+	for _, folder := range strings.Split(list, ",") {
+		folder = strings.TrimSpace(folder)
+		if folder != "" && !seen[folder] {
+			seen[folder] = true
+			folders = append(folders, folder)
 		}
 	}
+	if len(folders) == 0 {
+		return []string{"INBOX"}
+	}
+	return folders
+}
 
-	return items, nil
+// parseEmailSenders parses a comma-separated list of sender addresses and domains
+func parseEmailSenders(list string) []string {
+	var senders []string
+	for _, sender := range strings.Split(list, ",") {
+		if sender = strings.ToLower(strings.TrimSpace(sender)); sender != "" {
+			senders = append(senders, strings.TrimPrefix(sender, "@"))
+		}
+	}
+	return senders
+}
+
+// matchEmailSender reports whether an address is one of the senders or from one of their
+// domains (subdomains included). Any address matches an empty list.
+func matchEmailSender(senders []string, address string) bool {
+	if len(senders) == 0 {
+		return true
+	}
+	address = strings.ToLower(address)
+	_, domain, _ := strings.Cut(address, "@")
+	for _, sender := range senders {
+		if strings.Contains(sender, "@") {
+			if address == sender {
+				return true
+			}
+		} else if domain == sender || strings.HasSuffix(domain, "."+sender) {
+			return true
+		}
+	}
+	return false
 }
 
 // connectToIMAP establishes a connection to the IMAP server
@@ -168,14 +269,14 @@ func (ef *EmailFetcher) sendIMAPID(c *client.Client) error {
 	return err
 }
 
-// fetchEmailBatch fetches and parses a batch of emails
-func (ef *EmailFetcher) fetchEmailBatch(c *client.Client, uids []uint32) ([]*gofeed.Item, error) {
+// fetchEmailBatch fetches and parses a batch of emails, skipping those of other senders
+func (ef *EmailFetcher) fetchEmailBatch(c *client.Client, uids []uint32, folder string, uidValidity uint32, senders []string) ([]*gofeed.Item, error) {
 	seqset := new(imap.SeqSet)
 	seqset.AddNum(uids...)
 
 	// Fetch the envelope and body
 	messages := make(chan *imap.Message, len(uids))
-	err := c.Fetch(seqset, []imap.FetchItem{imap.FetchEnvelope, imap.FetchBody}, messages)
+	err := c.UidFetch(seqset, []imap.FetchItem{imap.FetchUid, imap.FetchEnvelope, imap.FetchBody}, messages)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch messages: %w", err)
 	}
@@ -183,11 +284,14 @@ func (ef *EmailFetcher) fetchEmailBatch(c *client.Client, uids []uint32) ([]*gof
 	items := make([]*gofeed.Item, 0, len(uids))
 
 	for msg := range messages {
-		if msg == nil {
-			break
+		if msg == nil || msg.Envelope == nil {
+			continue
+		}
+		if len(senders) > 0 && (len(msg.Envelope.From) == 0 || !matchEmailSender(senders, msg.Envelope.From[0].Address())) {
+			continue
 		}
 
-		item, err := ef.parseEmailToItem(msg)
+		item, err := ef.parseEmailToItem(msg, folder, uidValidity)
 		if err != nil {
 			// Skip invalid emails but continue processing others
 			continue
@@ -201,14 +305,23 @@ func (ef *EmailFetcher) fetchEmailBatch(c *client.Client, uids []uint32) ([]*gof
 	return items, nil
 }
 
-// parseEmailToItem converts an IMAP message to a gofeed Item
-func (ef *EmailFetcher) parseEmailToItem(msg *imap.Message) (*gofeed.Item, error) {
+// parseEmailToItem converts an IMAP message to a gofeed Item. Items are identified by
+// their Message-Id, else by their UID in the folder.
+func (ef *EmailFetcher) parseEmailToItem(msg *imap.Message, folder string, uidValidity uint32) (*gofeed.Item, error) {
+	messageID := strings.Trim(strings.TrimSpace(msg.Envelope.MessageId), "<>")
+	if messageID == "" {
+		messageID = fmt.Sprintf("%s/%d/%d", url.PathEscape(folder), uidValidity, msg.Uid)
+	}
 	item := &gofeed.Item{
 		Title:     msg.Envelope.Subject,
-		Link:      fmt.Sprintf("email://%d", msg.Uid),
-		GUID:      fmt.Sprintf("email-%d", msg.Uid),
+		Link:      "email://" + messageID,
+		GUID:      "email-" + messageID,
 		Published: msg.Envelope.Date.Format(time.RFC1123),
 	}
+	if !msg.Envelope.Date.IsZero() {
+		published := msg.Envelope.Date
+		item.PublishedParsed = &published
+	}
 
 	// Extract sender as author if available
 	if len(msg.Envelope.From) > 0 {
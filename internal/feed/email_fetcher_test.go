@@ -0,0 +1,205 @@
+package feed
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend"
+	"github.com/emersion/go-imap/server"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+func TestEmailFolders(t *testing.T) {
+	tests := map[string][]string{
+		"":                           {"INBOX"},
+		"INBOX":                      {"INBOX"},
+		" INBOX, Newsletters ,INBOX": {"INBOX", "Newsletters"},
+		"Lists/Go,":                  {"Lists/Go"},
+	}
+	for list, want := range tests {
+		if got := emailFolders(list); !reflect.DeepEqual(got, want) {
+			t.Errorf("emailFolders(%q) = %v, want %v", list, got, want)
+		}
+	}
+}
+
+func TestMatchEmailSender(t *testing.T) {
+	senders := parseEmailSenders("news@Weekly.example, @substack.com ,example.org")
+	tests := map[string]bool{
+		"news@weekly.example":      true,
+		"other@weekly.example":     false,
+		"writer@substack.com":      true,
+		"writer@mail.substack.com": true,
+		"writer@notsubstack.com":   false,
+		"a@EXAMPLE.org":            true,
+	}
+	for address, want := range tests {
+		if got := matchEmailSender(senders, address); got != want {
+			t.Errorf("matchEmailSender(%q) = %v, want %v", address, got, want)
+		}
+	}
+	if !matchEmailSender(parseEmailSenders(""), "anyone@example.com") {
+		t.Error("an empty sender list must match any address")
+	}
+}
+
+func TestEmailSyncState(t *testing.T) {
+	_, db := newFailuresTestFetcher(t)
+	feedID, _ := db.AddFeed(&models.Feed{Title: "mail", URL: "email://me@example.com", Type: "email"})
+
+	state, err := db.GetEmailSyncState(feedID, "INBOX")
+	if err != nil || state != nil {
+		t.Fatalf("expected no state, got %+v (%v)", state, err)
+	}
+	state = &sqlite.EmailSyncState{FeedID: feedID, Folder: "INBOX", UIDValidity: 7, LastUID: 42}
+	if err := db.SaveEmailSyncState(state); err != nil {
+		t.Fatalf("SaveEmailSyncState: %v", err)
+	}
+	state, err = db.GetEmailSyncState(feedID, "INBOX")
+	if err != nil || state == nil || state.UIDValidity != 7 || state.LastUID != 42 {
+		t.Fatalf("unexpected state %+v (%v)", state, err)
+	}
+
+	// Same UIDVALIDITY: continue after the last UID
+	if lastUID, resync := nextEmailSync(state, 7); lastUID != 42 || resync {
+		t.Errorf("nextEmailSync = %d, %v", lastUID, resync)
+	}
+	// Changed UIDVALIDITY: the stored UIDs are stale
+	if lastUID, resync := nextEmailSync(state, 8); lastUID != 0 || !resync {
+		t.Errorf("nextEmailSync after UIDVALIDITY change = %d, %v", lastUID, resync)
+	}
+	// Unknown UIDVALIDITY (migrated state) is adopted
+	if lastUID, resync := nextEmailSync(&sqlite.EmailSyncState{LastUID: 5}, 8); lastUID != 5 || resync {
+		t.Errorf("nextEmailSync of migrated state = %d, %v", lastUID, resync)
+	}
+
+	if err := db.DeleteFeed(feedID); err != nil {
+		t.Fatalf("DeleteFeed: %v", err)
+	}
+	if state, _ := db.GetEmailSyncState(feedID, "INBOX"); state != nil {
+		t.Error("sync state not deleted with its feed")
+	}
+}
+
+func TestFetchEmailsSkipsFailingFolder(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	imapServer := server.New(testIMAPBackend{})
+	imapServer.AllowInsecureAuth = true
+	go imapServer.Serve(listener)
+	defer imapServer.Close()
+
+	_, db := newFailuresTestFetcher(t)
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	imapPort, _ := strconv.Atoi(port)
+	feed := &models.Feed{
+		Title:           "mail",
+		URL:             "email://username@example.org",
+		Type:            "email",
+		EmailIMAPServer: host,
+		EmailIMAPPort:   imapPort,
+		EmailUsername:   "username",
+		EmailPassword:   "password",
+		EmailFolder:     "INBOX, Missing",
+	}
+	feed.ID, _ = db.AddFeed(feed)
+
+	// INBOX is synced before the missing folder fails: its message is returned
+	items, err := NewEmailFetcher(db).FetchEmails(context.Background(), feed)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected the INBOX message, got %d items (%v)", len(items), err)
+	}
+	if state, _ := db.GetEmailSyncState(feed.ID, "INBOX"); state == nil || state.LastUID != 6 {
+		t.Errorf("unexpected INBOX state %+v", state)
+	}
+
+	feed.EmailFolder = "Missing"
+	if _, err := NewEmailFetcher(db).FetchEmails(context.Background(), feed); err == nil {
+		t.Error("expected an error when no folder can be read")
+	}
+}
+
+// testIMAPBackend serves an INBOX with a single message (UID 6) to any login
+type testIMAPBackend struct{ backend.User }
+
+func (b testIMAPBackend) Login(_ *imap.ConnInfo, username, password string) (backend.User, error) {
+	return b, nil
+}
+
+func (b testIMAPBackend) Username() string { return "username" }
+
+func (b testIMAPBackend) GetMailbox(name string) (backend.Mailbox, error) {
+	if name != "INBOX" {
+		return nil, backend.ErrNoSuchMailbox
+	}
+	return testIMAPMailbox{}, nil
+}
+
+func (b testIMAPBackend) Logout() error { return nil }
+
+type testIMAPMailbox struct{ backend.Mailbox }
+
+func (m testIMAPMailbox) Name() string { return "INBOX" }
+
+func (m testIMAPMailbox) Status(items []imap.StatusItem) (*imap.MailboxStatus, error) {
+	status := imap.NewMailboxStatus("INBOX", items)
+	status.Messages = 1
+	status.UidNext = 7
+	status.UidValidity = 1
+	return status, nil
+}
+
+func (m testIMAPMailbox) SearchMessages(uid bool, criteria *imap.SearchCriteria) ([]uint32, error) {
+	if criteria.Uid != nil && !criteria.Uid.Contains(6) {
+		return nil, nil
+	}
+	return []uint32{6}, nil
+}
+
+func (m testIMAPMailbox) ListMessages(uid bool, seqset *imap.SeqSet, items []imap.FetchItem, ch chan<- *imap.Message) error {
+	defer close(ch)
+	if !seqset.Contains(6) {
+		return nil
+	}
+	msg := imap.NewMessage(1, items)
+	msg.Uid = 6
+	msg.Envelope = &imap.Envelope{
+		Date:      time.Now(),
+		Subject:   "Weekly digest",
+		MessageId: "<digest-1@example.org>",
+		From:      []*imap.Address{{MailboxName: "news", HostName: "example.org"}},
+	}
+	msg.BodyStructure = &imap.BodyStructure{MIMEType: "text", MIMESubType: "plain"}
+	ch <- msg
+	return nil
+}
+
+func TestEmailIdleTargets(t *testing.T) {
+	feeds := []models.Feed{
+		{ID: 1, Type: "email", EmailIMAPServer: "imap.example.com", EmailIMAPPort: 993, EmailUsername: "me", EmailFolder: "INBOX, News"},
+		{ID: 2, Type: "email", EmailIMAPServer: "IMAP.example.com", EmailIMAPPort: 993, EmailUsername: "Me", EmailFolder: "INBOX", EmailSenders: "@substack.com"},
+		{ID: 3, Type: "email", EmailIMAPServer: "imap.example.com", EmailIMAPPort: 993, EmailUsername: "me", IsPaused: true},
+		{ID: 4, Type: "email", EmailAddress: "feed-1@rss.example"},
+		{ID: 5, URL: "https://example.com/feed"},
+	}
+	targets := emailIdleTargets(feeds)
+	if len(targets) != 2 {
+		t.Fatalf("expected INBOX and News to be watched, got %d targets", len(targets))
+	}
+	inbox := targets["imap.example.com:993/me/INBOX"]
+	if inbox == nil || !reflect.DeepEqual(inbox.feedIDs, []int64{1, 2}) {
+		t.Errorf("INBOX target = %+v", inbox)
+	}
+	if news := targets["imap.example.com:993/me/News"]; news == nil || !reflect.DeepEqual(news.feedIDs, []int64{1}) {
+		t.Errorf("News target = %+v", news)
+	}
+}
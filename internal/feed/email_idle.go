@@ -0,0 +1,328 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emersion/go-imap/client"
+
+	"MavenRSS/internal/models"
+)
+
+const (
+	// emailIdleSyncInterval is how often the watched folders are matched with the email feeds
+	emailIdleSyncInterval = 5 * time.Minute
+	// emailIdleDebounce groups the updates of a burst of new messages into one refresh
+	emailIdleDebounce = 2 * time.Second
+	// emailIdlePollInterval is used with servers not supporting IDLE
+	emailIdlePollInterval = time.Minute
+	// emailIdleMinBackoff and emailIdleMaxBackoff bound the delay between reconnections
+	emailIdleMinBackoff = 10 * time.Second
+	emailIdleMaxBackoff = 15 * time.Minute
+)
+
+var errEmailIdleEnded = errors.New("IDLE ended by the server")
+
+// EmailIdleManager keeps an IMAP IDLE connection open to each folder of the email
+// feeds, refreshing the feeds of a folder as soon as the server announces new mail.
+// Feeds sharing a mailbox folder share its connection.
+type EmailIdleManager struct {
+	fetcher *Fetcher
+
+	isRunning bool
+	mu        sync.Mutex
+	watchers  map[string]*emailIdleWatcher
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// emailIdleTarget is a mailbox folder to watch and the feeds it populates
+type emailIdleTarget struct {
+	feed    models.Feed // Feed whose credentials are used to connect
+	folder  string
+	feedIDs []int64
+}
+
+// emailIdleWatcher keeps the IDLE connection of a folder
+type emailIdleWatcher struct {
+	mu        sync.Mutex
+	target    emailIdleTarget
+	connected bool
+	stop      chan struct{}
+}
+
+// NewEmailIdleManager creates a new IMAP IDLE manager
+func NewEmailIdleManager(fetcher *Fetcher) *EmailIdleManager {
+	return &EmailIdleManager{
+		fetcher:  fetcher,
+		watchers: make(map[string]*emailIdleWatcher),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start starts watching the folders of the email feeds
+func (im *EmailIdleManager) Start() {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	if im.isRunning {
+		return
+	}
+	im.isRunning = true
+
+	im.wg.Add(1)
+	go im.syncLoop()
+
+	log.Println("Email IDLE manager started")
+}
+
+// Stop closes the IDLE connections
+func (im *EmailIdleManager) Stop() {
+	im.mu.Lock()
+	if !im.isRunning {
+		im.mu.Unlock()
+		return
+	}
+	close(im.stopChan)
+	im.mu.Unlock()
+
+	im.wg.Wait()
+
+	im.mu.Lock()
+	im.isRunning = false
+	im.watchers = make(map[string]*emailIdleWatcher)
+	im.mu.Unlock()
+	log.Println("Email IDLE manager stopped")
+}
+
+// IsWatching reports whether new mail for a feed is announced by an open IDLE connection
+func (im *EmailIdleManager) IsWatching(feedID int64) bool {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	for _, w := range im.watchers {
+		w.mu.Lock()
+		watching := w.connected && containsFeedID(w.target.feedIDs, feedID)
+		w.mu.Unlock()
+		if watching {
+			return true
+		}
+	}
+	return false
+}
+
+// syncLoop periodically matches the watched folders with the email feeds
+func (im *EmailIdleManager) syncLoop() {
+	defer im.wg.Done()
+
+	ticker := time.NewTicker(emailIdleSyncInterval)
+	defer ticker.Stop()
+
+	im.syncWatchers()
+	for {
+		select {
+		case <-ticker.C:
+			im.syncWatchers()
+		case <-im.stopChan:
+			im.mu.Lock()
+			for key, w := range im.watchers {
+				close(w.stop)
+				delete(im.watchers, key)
+			}
+			im.mu.Unlock()
+			return
+		}
+	}
+}
+
+// syncWatchers starts watching the folders of new email feeds and stops watching
+// those no feed uses anymore
+func (im *EmailIdleManager) syncWatchers() {
+	feeds, err := im.fetcher.db.GetFeeds()
+	if err != nil {
+		log.Printf("Email IDLE: error loading feeds: %v", err)
+		return
+	}
+	targets := emailIdleTargets(feeds)
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	for key, w := range im.watchers {
+		target, ok := targets[key]
+		w.mu.Lock()
+		credentialsChanged := ok && w.target.feed.EmailPassword != target.feed.EmailPassword
+		if ok && !credentialsChanged {
+			w.target.feedIDs = target.feedIDs
+		}
+		w.mu.Unlock()
+		if !ok || credentialsChanged {
+			close(w.stop)
+			delete(im.watchers, key)
+		}
+	}
+	for key, target := range targets {
+		if _, ok := im.watchers[key]; ok {
+			continue
+		}
+		w := &emailIdleWatcher{target: *target, stop: make(chan struct{})}
+		im.watchers[key] = w
+		im.wg.Add(1)
+		go im.watch(w)
+	}
+}
+
+// emailIdleTargets groups the IMAP email feeds by mailbox folder
+func emailIdleTargets(feeds []models.Feed) map[string]*emailIdleTarget {
+	targets := make(map[string]*emailIdleTarget)
+	for _, feed := range feeds {
+		if feed.Type != "email" || feed.EmailIMAPServer == "" || feed.EmailUsername == "" || feed.IsPaused {
+			continue
+		}
+		for _, folder := range emailFolders(feed.EmailFolder) {
+			key := fmt.Sprintf("%s:%d/%s/%s", strings.ToLower(feed.EmailIMAPServer), feed.EmailIMAPPort,
+				strings.ToLower(feed.EmailUsername), folder)
+			target, ok := targets[key]
+			if !ok {
+				target = &emailIdleTarget{feed: feed, folder: folder}
+				targets[key] = target
+			}
+			target.feedIDs = append(target.feedIDs, feed.ID)
+		}
+	}
+	return targets
+}
+
+// watch keeps an IDLE connection to a folder open until the watcher is stopped,
+// reconnecting with a growing delay after failures
+func (im *EmailIdleManager) watch(w *emailIdleWatcher) {
+	defer im.wg.Done()
+
+	backoff := emailIdleMinBackoff
+	for {
+		started := time.Now()
+		err := im.idle(w)
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+
+		if time.Since(started) > emailIdleMaxBackoff {
+			backoff = emailIdleMinBackoff
+		}
+		log.Printf("Email IDLE on %s of %s: %v, reconnecting in %v", w.target.folder, w.target.feed.EmailUsername, err, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-w.stop:
+			return
+		}
+		if backoff *= 2; backoff > emailIdleMaxBackoff {
+			backoff = emailIdleMaxBackoff
+		}
+	}
+}
+
+// idle runs one IDLE connection to the folder of a watcher
+func (im *EmailIdleManager) idle(w *emailIdleWatcher) error {
+	w.mu.Lock()
+	feed, folder := w.target.feed, w.target.folder
+	w.mu.Unlock()
+
+	c, err := im.fetcher.emailFetcher.connectToIMAP(&feed)
+	if err != nil {
+		return err
+	}
+	defer c.Logout()
+
+	updates := make(chan client.Update, 64)
+	c.Updates = updates
+	if _, err := c.Select(folder, true); err != nil {
+		return fmt.Errorf("failed to select mailbox %s: %w", folder, err)
+	}
+
+	w.mu.Lock()
+	w.connected = true
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.connected = false
+		w.mu.Unlock()
+	}()
+
+	// Catch up with what arrived while disconnected
+	im.refresh(w)
+
+	stopIdle := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Idle(stopIdle, &client.IdleOptions{PollInterval: emailIdlePollInterval})
+	}()
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case update := <-updates:
+			if _, ok := update.(*client.MailboxUpdate); ok && debounce == nil {
+				debounce = time.After(emailIdleDebounce)
+			}
+		case <-debounce:
+			debounce = nil
+			im.refresh(w)
+		case err := <-done:
+			if err == nil {
+				err = errEmailIdleEnded
+			}
+			return err
+		case <-w.stop:
+			close(stopIdle)
+			// Keep reading updates so that the client can leave IDLE
+			for {
+				select {
+				case <-updates:
+				case <-done:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// refresh queues the refresh of the feeds of a watched folder
+func (im *EmailIdleManager) refresh(w *emailIdleWatcher) {
+	w.mu.Lock()
+	feedIDs := append([]int64(nil), w.target.feedIDs...)
+	w.mu.Unlock()
+
+	for _, feedID := range feedIDs {
+		feed, err := im.fetcher.db.GetFeedByID(feedID)
+		if err != nil || feed == nil || feed.IsPaused {
+			continue
+		}
+		im.fetcher.taskManager.AddToQueueHead(context.Background(), *feed, TaskReasonPush)
+	}
+}
+
+func containsFeedID(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// EnableEmailIdle enables IMAP IDLE for email feeds, so that new newsletters appear
+// within seconds instead of at the next refresh
+func (f *Fetcher) EnableEmailIdle() {
+	if f.emailIdle != nil {
+		return
+	}
+	f.emailIdle = NewEmailIdleManager(f)
+	f.emailIdle.Start()
+}
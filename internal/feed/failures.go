@@ -0,0 +1,192 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"MavenRSS/internal/discovery"
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// After backoffThreshold consecutive failed refreshes, scheduled refreshes of a feed
+// are delayed by backoffBase, doubled for every further failure up to backoffMax.
+const (
+	backoffThreshold = 3
+	backoffBase      = 15 * time.Minute
+	backoffMax       = 24 * time.Hour
+)
+
+// goneProbeInterval is how often a feed paused after HTTP 410 is probed
+const goneProbeInterval = 24 * time.Hour
+
+// rediscoveryTimeout bounds the homepage discovery of a replacement feed URL
+const rediscoveryTimeout = 2 * time.Minute
+
+// PauseReasonGone is the pause reason of feeds whose server answered HTTP 410 Gone
+const PauseReasonGone = "gone"
+
+// ErrFeedGone is returned when the server of a feed answered HTTP 410 Gone
+var ErrFeedGone = errors.New("feed is gone (HTTP 410)")
+
+// ErrNoReplacementFeed is returned when rediscovery didn't find another feed URL
+var ErrNoReplacementFeed = errors.New("no replacement feed found on the homepage")
+
+// backoffDelay returns how long scheduled refreshes are delayed after consecutive failures
+func backoffDelay(failures int) time.Duration {
+	if failures < backoffThreshold {
+		return 0
+	}
+	delay := backoffBase
+	for i := backoffThreshold; i < failures; i++ {
+		delay *= 2
+		if delay >= backoffMax {
+			return backoffMax
+		}
+	}
+	return delay
+}
+
+// isBackingOff reports whether scheduled refreshes of a feed are delayed
+func isBackingOff(feed models.Feed, now time.Time) bool {
+	return feed.NextRetryAt != nil && now.Before(*feed.NextRetryAt)
+}
+
+// filterBackingOffFeeds removes the feeds whose scheduled refreshes are delayed
+func filterBackingOffFeeds(feeds []models.Feed) ([]models.Feed, int) {
+	now := time.Now()
+	ready := make([]models.Feed, 0, len(feeds))
+	for _, feed := range feeds {
+		if !isBackingOff(feed, now) {
+			ready = append(ready, feed)
+		}
+	}
+	return ready, len(feeds) - len(ready)
+}
+
+// isGoneError reports whether a failed fetch was answered with HTTP 410 Gone
+func isGoneError(err error, httpStatus int) bool {
+	var httpErr gofeed.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusGone
+	}
+	return httpStatus == http.StatusGone
+}
+
+// isPermanentRedirect reports whether a redirect status moves the resource permanently
+func isPermanentRedirect(status int) bool {
+	return status == http.StatusMovedPermanently || status == http.StatusPermanentRedirect
+}
+
+// applyPermanentRedirect moves a feed to the URL its server permanently redirected to
+func (f *Fetcher) applyPermanentRedirect(feed models.Feed, stats *fetchStats) {
+	if stats.movedTo == "" || stats.movedTo == feed.URL {
+		return
+	}
+	if err := f.db.ChangeFeedURL(feed.ID, stats.movedTo, sqlite.FeedURLChangeMoved, stats.movedStatus); err != nil {
+		log.Printf("Failed to move feed %s to %s: %v", feed.Title, stats.movedTo, err)
+		return
+	}
+	log.Printf("Feed %s moved permanently (HTTP %d) from %s to %s", feed.Title, stats.movedStatus, feed.URL, stats.movedTo)
+}
+
+// recordRefreshResult updates the failure tracking of a feed after a refresh: a success
+// clears the failures and resumes a paused feed, HTTP 410 pauses the feed until the next
+// probe, and other failures delay the next scheduled refreshes once they pile up.
+func (f *Fetcher) recordRefreshResult(feed models.Feed, err error) {
+	if err == nil {
+		if feed.IsPaused {
+			log.Printf("Feed %s is back, resuming it", feed.Title)
+		}
+		if err := f.db.ResetFeedFailures(feed.ID); err != nil {
+			log.Printf("Failed to reset failures of feed %s: %v", feed.Title, err)
+		}
+		return
+	}
+
+	// A host throttling us doesn't make the feed broken
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) && rateLimitErr.StatusCode == http.StatusTooManyRequests {
+		return
+	}
+
+	if errors.Is(err, ErrFeedGone) {
+		log.Printf("Feed %s is gone, pausing it", feed.Title)
+		if err := f.db.PauseFeed(feed.ID, PauseReasonGone, time.Now().Add(goneProbeInterval)); err != nil {
+			log.Printf("Failed to pause feed %s: %v", feed.Title, err)
+		}
+		f.autoRediscover(feed)
+		return
+	}
+
+	failures, dbErr := f.db.IncrementFeedFailures(feed.ID)
+	if dbErr != nil {
+		log.Printf("Failed to count failure of feed %s: %v", feed.Title, dbErr)
+		return
+	}
+	delay := backoffDelay(failures)
+	if delay == 0 {
+		return
+	}
+	log.Printf("Feed %s failed %d times in a row, next scheduled refresh in %v", feed.Title, failures, delay)
+	if err := f.db.SetFeedNextRetry(feed.ID, time.Now().Add(delay)); err != nil {
+		log.Printf("Failed to delay refreshes of feed %s: %v", feed.Title, err)
+	}
+	if delay == backoffMax {
+		f.autoRediscover(feed)
+	}
+}
+
+// autoRediscover looks for a replacement URL of a dead feed in the background,
+// when enabled by the feed_auto_rediscover setting
+func (f *Fetcher) autoRediscover(feed models.Feed) {
+	enabled, _ := f.db.GetSettingWithFallback(feed.UserID, "feed_auto_rediscover")
+	if enabled != "true" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), rediscoveryTimeout)
+		defer cancel()
+		if _, err := f.RediscoverFeed(ctx, feed); err != nil {
+			log.Printf("Rediscovery of feed %s failed: %v", feed.Title, err)
+		}
+	}()
+}
+
+// RediscoverFeed reruns feed discovery on the homepage of a feed and moves the feed
+// to the URL found. The failures of the feed are cleared so it's refreshed again.
+// It returns the new feed URL, or ErrNoReplacementFeed.
+func (f *Fetcher) RediscoverFeed(ctx context.Context, feed models.Feed) (string, error) {
+	homepage := feed.Link
+	if homepage == "" {
+		u, err := url.Parse(feed.URL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return "", ErrNoReplacementFeed
+		}
+		homepage = u.Scheme + "://" + u.Host
+	}
+
+	feedURL, feedType, err := discovery.NewServiceWithProxy(f.feedProxyURL(feed)).FindFeed(ctx, homepage)
+	if err != nil || feedURL == feed.URL || feedType != feed.Type {
+		if err != nil {
+			log.Printf("Discovery on %s found no feed: %v", homepage, err)
+		}
+		return "", ErrNoReplacementFeed
+	}
+
+	if err := f.db.ChangeFeedURL(feed.ID, feedURL, sqlite.FeedURLChangeDiscovered, 0); err != nil {
+		return "", fmt.Errorf("failed to update feed URL: %w", err)
+	}
+	if err := f.db.ResetFeedFailures(feed.ID); err != nil {
+		return "", err
+	}
+	log.Printf("Feed %s rediscovered at %s (was %s)", feed.Title, feedURL, feed.URL)
+	return feedURL, nil
+}
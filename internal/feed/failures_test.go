@@ -0,0 +1,186 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+const failuresTestRSS = `<?xml version="1.0"?><rss><channel><title>Moved</title>` +
+	`<item><title>one</title><link>/1</link><guid>1</guid></item></channel></rss>`
+
+func newFailuresTestFetcher(t *testing.T) (*Fetcher, *sqlite.DB) {
+	t.Helper()
+	db, err := sqlite.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	return NewFetcher(db), db
+}
+
+func TestFetchFeedWithContext_PermanentRedirect(t *testing.T) {
+	fetcher, db := newFailuresTestFetcher(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/temporary":
+			http.Redirect(w, r, "/new", http.StatusFound)
+		default:
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Write([]byte(failuresTestRSS))
+		}
+	}))
+	defer srv.Close()
+
+	movedID, _ := db.AddFeed(&models.Feed{Title: "moved", URL: srv.URL + "/old"})
+	temporaryID, _ := db.AddFeed(&models.Feed{Title: "temporary", URL: srv.URL + "/temporary"})
+	for _, id := range []int64{movedID, temporaryID} {
+		feed, _ := db.GetFeedByID(id)
+		if err := fetcher.fetchFeedWithContext(context.Background(), *feed); err != nil {
+			t.Fatalf("fetch %s: %v", feed.Title, err)
+		}
+	}
+
+	if moved, _ := db.GetFeedByID(movedID); moved.URL != srv.URL+"/new" {
+		t.Errorf("moved feed URL = %q", moved.URL)
+	}
+	if temporary, _ := db.GetFeedByID(temporaryID); temporary.URL != srv.URL+"/temporary" {
+		t.Errorf("temporarily redirected feed URL = %q", temporary.URL)
+	}
+	changes, err := db.GetFeedURLChanges(0, 0, 10)
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("GetFeedURLChanges = %+v, %v", changes, err)
+	}
+	if c := changes[0]; c.FeedID != movedID || c.OldURL != srv.URL+"/old" || c.Reason != sqlite.FeedURLChangeMoved || c.HTTPStatus != http.StatusMovedPermanently {
+		t.Errorf("unexpected URL change %+v", c)
+	}
+}
+
+func TestRecordRefreshResult_GoneAndResume(t *testing.T) {
+	fetcher, db := newFailuresTestFetcher(t)
+	var gone atomic.Bool
+	gone.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if gone.Load() {
+			http.Error(w, "gone", http.StatusGone)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(failuresTestRSS))
+	}))
+	defer srv.Close()
+
+	id, _ := db.AddFeed(&models.Feed{Title: "gone", URL: srv.URL})
+	refresh := func() *models.Feed {
+		t.Helper()
+		feed, _ := db.GetFeedByID(id)
+		fetcher.recordRefreshResult(*feed, fetcher.fetchFeedWithContext(context.Background(), *feed))
+		feed, _ = db.GetFeedByID(id)
+		return feed
+	}
+
+	feed, _ := db.GetFeedByID(id)
+	if err := fetcher.fetchFeedWithContext(context.Background(), *feed); !errors.Is(err, ErrFeedGone) {
+		t.Fatalf("expected ErrFeedGone, got %v", err)
+	}
+	feed = refresh()
+	if !feed.IsPaused || feed.PauseReason != PauseReasonGone || !isBackingOff(*feed, time.Now().Add(23*time.Hour)) {
+		t.Fatalf("expected a paused feed, got %+v", feed)
+	}
+	if ready, skipped := filterBackingOffFeeds([]models.Feed{*feed}); len(ready) != 0 || skipped != 1 {
+		t.Errorf("paused feed not skipped")
+	}
+
+	gone.Store(false)
+	feed = refresh()
+	if feed.IsPaused || feed.ConsecutiveFailures != 0 || feed.NextRetryAt != nil {
+		t.Errorf("expected the feed to resume, got %+v", feed)
+	}
+}
+
+func TestRecordRefreshResult_Backoff(t *testing.T) {
+	fetcher, db := newFailuresTestFetcher(t)
+	id, _ := db.AddFeed(&models.Feed{Title: "failing", URL: "http://example.invalid/feed"})
+	failure := errors.New("HTTP 500: 500 Internal Server Error")
+
+	for i := 1; i <= backoffThreshold; i++ {
+		feed, _ := db.GetFeedByID(id)
+		if i < backoffThreshold && feed.NextRetryAt != nil {
+			t.Fatalf("backoff before %d failures", backoffThreshold)
+		}
+		fetcher.recordRefreshResult(*feed, failure)
+	}
+	feed, _ := db.GetFeedByID(id)
+	if feed.ConsecutiveFailures != backoffThreshold || !isBackingOff(*feed, time.Now()) || isBackingOff(*feed, time.Now().Add(backoffBase+time.Minute)) {
+		t.Fatalf("unexpected backoff %+v", feed)
+	}
+
+	fetcher.recordRefreshResult(*feed, nil)
+	if feed, _ = db.GetFeedByID(id); feed.ConsecutiveFailures != 0 || feed.NextRetryAt != nil {
+		t.Errorf("expected failures to be cleared, got %+v", feed)
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	tests := map[int]time.Duration{
+		1:  0,
+		2:  0,
+		3:  15 * time.Minute,
+		4:  30 * time.Minute,
+		5:  time.Hour,
+		9:  16 * time.Hour,
+		10: 24 * time.Hour,
+		50: 24 * time.Hour,
+	}
+	for failures, want := range tests {
+		if got := backoffDelay(failures); got != want {
+			t.Errorf("backoffDelay(%d) = %v, want %v", failures, got, want)
+		}
+	}
+}
+
+func TestRediscoverFeed(t *testing.T) {
+	fetcher, db := newFailuresTestFetcher(t)
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><link rel="alternate" type="application/rss+xml" href="` + srv.URL + `/new.xml"></head></html>`))
+		case "/new.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Write([]byte(failuresTestRSS))
+		default:
+			http.Error(w, "gone", http.StatusGone)
+		}
+	}))
+	defer srv.Close()
+
+	id, _ := db.AddFeed(&models.Feed{Title: "dead", URL: srv.URL + "/old.xml", Link: srv.URL + "/"})
+	feed, _ := db.GetFeedByID(id)
+	newURL, err := fetcher.RediscoverFeed(context.Background(), *feed)
+	if err != nil || newURL != srv.URL+"/new.xml" {
+		t.Fatalf("RediscoverFeed = %q, %v", newURL, err)
+	}
+	changes, _ := db.GetFeedURLChanges(0, id, 10)
+	if len(changes) != 1 || changes[0].Reason != sqlite.FeedURLChangeDiscovered {
+		t.Errorf("unexpected URL changes %+v", changes)
+	}
+
+	// The homepage now points at the feed itself
+	feed, _ = db.GetFeedByID(id)
+	if _, err := fetcher.RediscoverFeed(context.Background(), *feed); !errors.Is(err, ErrNoReplacementFeed) {
+		t.Errorf("expected ErrNoReplacementFeed, got %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package feed
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/xml"
+	"errors"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+	"MavenRSS/internal/utils/httputil"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// fetchStats collects the details of a fetch recorded in the fetch log.
+// The nil *fetchStats ignores all updates, so code shared with fetches that
+// aren't logged, like WebSub pushes, can update it unconditionally.
+type fetchStats struct {
+	httpStatus   int
+	bytes        int64
+	notModified  bool
+	items        int
+	newItems     int
+	updatedItems int
+	proxy        string
+	movedTo      string // URL the feed permanently redirected to
+	movedStatus  int
+}
+
+type fetchStatsKey struct{}
+
+// withFetchStats returns a context collecting the details of a fetch
+func withFetchStats(ctx context.Context) (context.Context, *fetchStats) {
+	stats := &fetchStats{}
+	return context.WithValue(ctx, fetchStatsKey{}, stats), stats
+}
+
+// fetchStatsFrom returns the fetch details collected by the context, or nil
+func fetchStatsFrom(ctx context.Context) *fetchStats {
+	stats, _ := ctx.Value(fetchStatsKey{}).(*fetchStats)
+	return stats
+}
+
+func (s *fetchStats) setResponse(status int, notModified bool) {
+	if s != nil {
+		s.httpStatus = status
+		s.notModified = notModified
+	}
+}
+
+func (s *fetchStats) setBytes(n int) {
+	if s != nil {
+		s.bytes = int64(n)
+	}
+}
+
+func (s *fetchStats) setItems(items int) {
+	if s != nil {
+		s.items = items
+	}
+}
+
+func (s *fetchStats) setSaved(saved, inserted int) {
+	if s != nil {
+		s.newItems = inserted
+		s.updatedItems = saved - inserted
+	}
+}
+
+func (s *fetchStats) setMoved(url string, status int) {
+	if s != nil {
+		s.movedTo = url
+		s.movedStatus = status
+	}
+}
+
+func (s *fetchStats) setProxy(proxyURL string) {
+	if s != nil {
+		s.proxy = redactProxyURL(proxyURL)
+	}
+}
+
+// recordFetch adds a fetch of a feed to the fetch log
+func (f *Fetcher) recordFetch(feed models.Feed, startedAt time.Time, stats *fetchStats, err error) {
+	finishedAt := time.Now()
+	entry := &sqlite.FeedFetchLog{
+		FeedID:       feed.ID,
+		UserID:       feed.UserID,
+		StartedAt:    startedAt,
+		FinishedAt:   finishedAt,
+		DurationMs:   finishedAt.Sub(startedAt).Milliseconds(),
+		HTTPStatus:   stats.httpStatus,
+		Bytes:        stats.bytes,
+		NotModified:  stats.notModified,
+		Items:        stats.items,
+		NewItems:     stats.newItems,
+		UpdatedItems: stats.updatedItems,
+		Proxy:        stats.proxy,
+	}
+	if err != nil {
+		entry.ErrorClass = classifyFetchError(err, stats.httpStatus)
+		entry.Error = err.Error()
+	}
+	if err := f.db.AddFeedFetchLog(entry); err != nil {
+		log.Printf("Error recording fetch of feed %d: %v", feed.ID, err)
+	}
+}
+
+// classifyFetchError returns the error class of a failed fetch
+func classifyFetchError(err error, httpStatus int) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var scriptErr *ScriptError
+	var httpErr gofeed.HTTPError
+	var certErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	var recordErr tls.RecordHeaderError
+	var syntaxErr *xml.SyntaxError
+	var opErr *net.OpError
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return sqlite.FetchErrorTimeout
+	case errors.Is(err, httputil.ErrCircuitOpen):
+		return sqlite.FetchErrorConnection
+	case errors.As(err, &dnsErr):
+		return sqlite.FetchErrorDNS
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return sqlite.FetchErrorTimeout
+	case errors.As(err, &certErr), errors.As(err, &authorityErr), errors.As(err, &hostnameErr),
+		errors.As(err, &invalidErr), errors.As(err, &recordErr):
+		return sqlite.FetchErrorTLS
+	case errors.As(err, &scriptErr):
+		return sqlite.FetchErrorScript
+	case errors.As(err, &httpErr), httpStatus >= 400:
+		return sqlite.FetchErrorHTTP
+	case errors.As(err, &opErr):
+		return sqlite.FetchErrorConnection
+	case errors.Is(err, gofeed.ErrFeedTypeNotDetected), errors.As(err, &syntaxErr):
+		return sqlite.FetchErrorParse
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "timeout"):
+		return sqlite.FetchErrorTimeout
+	case strings.Contains(msg, "tls:") || strings.Contains(msg, "x509:"):
+		return sqlite.FetchErrorTLS
+	case strings.Contains(msg, "connection reset") || strings.Contains(msg, "connection refused") ||
+		strings.Contains(msg, "eof"):
+		return sqlite.FetchErrorConnection
+	case strings.Contains(msg, "pars") || strings.Contains(msg, "syntax") || strings.Contains(msg, "feed type"):
+		return sqlite.FetchErrorParse
+	}
+	return sqlite.FetchErrorOther
+}
+
+// redactProxyURL removes the credentials of a proxy URL
+func redactProxyURL(proxyURL string) string {
+	if proxyURL == "" {
+		return ""
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
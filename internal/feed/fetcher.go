@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"MavenRSS/internal/feed/source"
 	"MavenRSS/internal/store/sqlite"
 	"MavenRSS/internal/models"
 	"MavenRSS/internal/rsshub"
@@ -53,6 +54,12 @@ type Fetcher struct {
 	postProcessWg     sync.WaitGroup
 	articleSink       chan []*models.Article // Global sink for article writes (Eco mode)
 	writerWg          sync.WaitGroup         // WaitGroup for article writer loop
+	webSub            *WebSubManager         // WebSub push subscriptions (nil when disabled)
+	mailReceiver      *MailReceiver          // Newsletter SMTP/LMTP receiver (nil when disabled)
+	emailIdle         *EmailIdleManager      // IMAP IDLE connections of email feeds (nil when disabled)
+
+	mailArchives    *source.MailArchiveSource // mbox/Maildir reader (nil when disabled)
+	mailArchiveRoot string                    // Directory the mail archives must be in (any when empty)
 }
 
 func NewFetcher(db *sqlite.DB) *Fetcher {
@@ -239,9 +246,18 @@ func (f *Fetcher) processPostTask(task *PostProcessTask) {
 	// Cache article contents
 	f.cacheArticleContents(task.ArticlesWithContent)
 
+	// Replace the content with the full text for feeds set to always fetch it
+	f.fetchFullTexts(task)
+
+	// Save podcast metadata and fetch transcripts
+	f.savePodcastEpisodes(task.ArticlesWithContent)
+
+	// Group near-duplicates across feeds
+	f.clusterArticles(task.ArticlesWithContent)
+
 	// Apply rules
 	if len(task.ArticlesWithContent) > 0 {
-		savedArticles, err := f.db.GetArticlesForUser(task.UserID, "", task.FeedID, "", false, len(task.ArticlesWithContent), 0)
+		savedArticles, err := f.db.GetArticlesForUser(task.UserID, "", task.FeedID, "", false, false, len(task.ArticlesWithContent), 0)
 		if err != nil {
 			log.Printf("Error getting articles for rule application: %v", err)
 			return
@@ -281,6 +297,14 @@ func (f *Fetcher) StopRefreshForUser(userID int64) {
 // Stop stops the fetcher and cleans up all resources
 func (f *Fetcher) Stop() {
 	log.Println("Stopping fetcher...")
+
+	// Stop receiving mail before the writers go away
+	if f.mailReceiver != nil {
+		f.mailReceiver.Stop()
+	}
+	if f.emailIdle != nil {
+		f.emailIdle.Stop()
+	}
 	
 	// Stop post-processing workers by closing the channel
 	// This is the clean way to stop workers - they'll finish
@@ -300,6 +324,9 @@ func (f *Fetcher) Stop() {
 	if f.cleanupManager != nil {
 		f.cleanupManager.Stop()
 	}
+	if f.webSub != nil {
+		f.webSub.Stop()
+	}
 	log.Println("Fetcher stopped")
 }
 
@@ -381,7 +408,19 @@ func (f *Fetcher) getConcurrencyLimit() int {
 // 1. Feed custom proxy (ProxyURL != "") - use custom proxy
 // 2. Feed proxy_enabled is true - use user's global proxy settings
 // 3. Feed proxy_enabled is false - NO proxy, even if global proxy is enabled
+// The client sends the credentials, cookies and custom headers of the feed.
 func (f *Fetcher) getHTTPClient(feed models.Feed) (*http.Client, error) {
+	proxyURL := f.feedProxyURL(feed)
+
+	// Use pooled client with browser-like headers to bypass Cloudflare and anti-bot protections
+	// This is critical for RSSHub feeds and other services with anti-bot protection
+	userAgent := feedAuthUserAgent(feed, "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	client := httputil.GetPooledUserAgentClient(proxyURL, httputil.DefaultRSSFetchTimeout, userAgent)
+	return WithFeedAuth(client, feed), nil
+}
+
+// feedProxyURL returns the proxy URL used to fetch a feed, or "" for none
+func (f *Fetcher) feedProxyURL(feed models.Feed) string {
 	var proxyURL string
 	userID := feed.UserID
 
@@ -402,11 +441,7 @@ func (f *Fetcher) getHTTPClient(feed models.Feed) (*http.Client, error) {
 		}
 	}
 	// Else: feed.ProxyEnabled is false - use NO proxy at all
-
-	// Use pooled client with browser-like headers to bypass Cloudflare and anti-bot protections
-	// This is critical for RSSHub feeds and other services with anti-bot protection
-	userAgent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
-	return httputil.GetPooledUserAgentClient(proxyURL, httputil.DefaultRSSFetchTimeout, userAgent), nil
+	return proxyURL
 }
 
 func (f *Fetcher) FetchAll(ctx context.Context) {
@@ -466,6 +501,26 @@ func (f *Fetcher) FetchAll(ctx context.Context) {
 		return
 	}
 
+	// Feeds failing repeatedly or paused are only refreshed once their backoff expires
+	if ready, skipped := filterBackingOffFeeds(filteredFeeds); skipped > 0 {
+		log.Printf("Skipping %d feeds backing off after failures", skipped)
+		filteredFeeds = ready
+		if len(filteredFeeds) == 0 {
+			f.taskManager.MarkCompleted()
+			return
+		}
+	}
+
+	// Feeds kept up to date by WebSub pushes or delivered by mail don't need to be polled
+	if pushed := f.FilterPushCoveredFeeds(filteredFeeds); len(pushed) < len(filteredFeeds) {
+		log.Printf("Skipping %d feeds receiving pushes", len(filteredFeeds)-len(pushed))
+		filteredFeeds = pushed
+		if len(filteredFeeds) == 0 {
+			f.taskManager.MarkCompleted()
+			return
+		}
+	}
+
 	if neverRefreshCount > 0 {
 		log.Printf("Standard refresh: %d feeds (skipped %d FreshRSS feeds, %d never-refresh feeds)", len(filteredFeeds), freshRSSCount, neverRefreshCount)
 	} else {
@@ -533,6 +588,26 @@ func (f *Fetcher) FetchAllForUser(ctx context.Context, userID int64) {
 		return
 	}
 
+	// Feeds failing repeatedly or paused are only refreshed once their backoff expires
+	if ready, skipped := filterBackingOffFeeds(filteredFeeds); skipped > 0 {
+		log.Printf("User %d: skipping %d feeds backing off after failures", userID, skipped)
+		filteredFeeds = ready
+		if len(filteredFeeds) == 0 {
+			f.taskManager.MarkCompleted()
+			return
+		}
+	}
+
+	// Feeds kept up to date by WebSub pushes or delivered by mail don't need to be polled
+	if pushed := f.FilterPushCoveredFeeds(filteredFeeds); len(pushed) < len(filteredFeeds) {
+		log.Printf("User %d: skipping %d feeds receiving pushes", userID, len(filteredFeeds)-len(pushed))
+		filteredFeeds = pushed
+		if len(filteredFeeds) == 0 {
+			f.taskManager.MarkCompleted()
+			return
+		}
+	}
+
 	if neverRefreshCount > 0 {
 		log.Printf("User %d refresh: %d feeds (skipped %d FreshRSS feeds, %d never-refresh feeds)", userID, len(filteredFeeds), freshRSSCount, neverRefreshCount)
 	} else {
@@ -625,8 +700,23 @@ func (f *Fetcher) FetchFeed(ctx context.Context, feed models.Feed) {
 }
 
 // fetchFeedWithContext is the internal fetch method used by TaskManager
-// Returns error instead of storing in progress.Errors
+// Returns error instead of storing in progress.Errors. Every fetch is recorded in the fetch log.
 func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) error {
+	ctx, stats := withFetchStats(ctx)
+	startedAt := time.Now()
+	err := f.fetchAndSaveFeed(ctx, feed)
+	if err != nil && isGoneError(err, stats.httpStatus) {
+		err = fmt.Errorf("%w: %v", ErrFeedGone, err)
+	}
+	f.recordFetch(feed, startedAt, stats, err)
+	if err == nil {
+		f.applyPermanentRedirect(feed, stats)
+	}
+	return err
+}
+
+// fetchAndSaveFeed fetches a feed and saves its new items
+func (f *Fetcher) fetchAndSaveFeed(ctx context.Context, feed models.Feed) error {
 	// Use ParseFeedWithFeed with normal priority for feed refresh
 	parsedFeed, err := f.ParseFeedWithFeed(ctx, &feed, false)
 	if err != nil {
@@ -637,6 +727,7 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 		}
 		return err
 	}
+	fetchStatsFrom(ctx).setItems(len(parsedFeed.Items))
 
 	// Check context after parsing
 	select {
@@ -658,6 +749,17 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 		f.db.UpdateFeedLink(feed.ID, parsedFeed.Link)
 	}
 
+	// Subscribe to the feed's WebSub hub, if it advertises one
+	if f.webSub != nil {
+		go f.webSub.onFeedFetched(feed, parsedFeed)
+	}
+
+	return f.saveFeedItems(ctx, feed, parsedFeed.Items)
+}
+
+// saveFeedItems processes parsed items and saves them, then queues post-processing.
+// It is shared by polling and WebSub pushes.
+func (f *Fetcher) saveFeedItems(ctx context.Context, feed models.Feed, items []*gofeed.Item) error {
 	// Check context before processing articles
 	select {
 	case <-ctx.Done():
@@ -665,8 +767,19 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 	default:
 	}
 
+	// Messages are imported once, even if their article was deleted since or they're read
+	// again from another export
+	if feed.Type == models.FeedTypeMailArchive {
+		items = f.skipImportedMessages(feed, items)
+	}
+
 	// Process articles
-	articlesWithContent := f.processArticles(feed, parsedFeed.Items)
+	articlesWithContent := f.processArticles(feed, items)
+
+	// Changed events of calendars update their articles instead of adding new ones
+	if isICalendarFeed(&feed) {
+		f.updateChangedEvents(feed, articlesWithContent)
+	}
 
 	// Check context before heavy DB operation
 	select {
@@ -675,6 +788,7 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 	default:
 	}
 
+	inserted := 0
 	if len(articlesWithContent) > 0 {
 		// Extract just the articles for saving
 		articlesToSave := make([]*models.Article, len(articlesWithContent))
@@ -686,7 +800,8 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 		perfMode, _ := f.db.GetSetting("performance_mode")
 		isEcoMode := perfMode == "eco"
 
-		if isEcoMode {
+		// Changes of watched pages are written immediately, as their snapshot follows the saved change
+		if isEcoMode && feed.Type != models.FeedTypeWatch {
 			// In Eco mode, send to global sink for batched writing
 			// This prevents database lock contention
 			select {
@@ -700,9 +815,12 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 			// The writer loop handles sending to postProcessChan after saving
 		} else {
 			// Standard mode: write immediately in this goroutine
-			if err := f.db.SaveArticles(ctx, articlesToSave); err != nil {
+			var err error
+			inserted, err = f.db.SaveArticlesWithCount(ctx, articlesToSave)
+			if err != nil {
 				return err
 			}
+			fetchStatsFrom(ctx).setSaved(len(articlesToSave), inserted)
 
 			// Post-processing operations (content caching and rule application)
 			// Send to worker pool to avoid creating too many goroutines
@@ -734,6 +852,17 @@ func (f *Fetcher) fetchFeedWithContext(ctx context.Context, feed models.Feed) er
 			}
 		}
 	}
+
+	// Watched pages are compared with the snapshot of their saved change from now on. A
+	// change that wasn't saved keeps the last snapshot, so it's reported again next time.
+	if feed.Type == models.FeedTypeWatch && inserted > 0 {
+		f.saveWatchSnapshot(feed, items)
+	}
+	// The pagination of XPath feeds stops at the items read so far, and mail archive
+	// feeds skip the messages imported before
+	if isPaginatedXPathFeed(&feed) || feed.Type == models.FeedTypeMailArchive {
+		f.rememberFeedItems(feed, items)
+	}
 	return nil
 }
 
@@ -746,6 +875,9 @@ func (f *Fetcher) FetchSingleFeed(ctx context.Context, feed models.Feed, isManua
 	if isManual {
 		// Manual operations go to queue head
 		f.taskManager.AddToQueueHead(ctx, feed, TaskReasonManualRefresh)
+	} else if isBackingOff(feed, time.Now()) {
+		// Scheduled operations wait for the backoff of failing and paused feeds
+		log.Printf("Skipping feed %s backing off after failures", feed.Title)
 	} else {
 		// Scheduled operations go to queue tail
 		f.taskManager.AddToQueueTail(ctx, feed, TaskReasonScheduledCustom)
@@ -782,35 +914,54 @@ func (f *Fetcher) FetchFeedsByIDs(ctx context.Context, feedIDs []int64) {
 	}
 }
 
+// findArticleID returns the ID of an article that was just saved
+func (f *Fetcher) findArticleID(article *models.Article) (int64, error) {
+	if article.UniqueID != "" {
+		// We use the pre-calculated UniqueID field from the article to avoid recalculating
+		// (faster and more reliable)
+		return f.db.GetArticleIDByRawUniqueID(article.UserID, article.UniqueID)
+	}
+	// Fallback to recalculating if unique_id is not available
+	return f.db.GetArticleIDByUniqueID(article.UserID, article.Title, article.FeedID, article.PublishedAt, article.HasValidPublishedTime)
+}
+
 // cacheArticleContents caches article contents from RSS feeds
 // This is called after articles are saved to the database
 func (f *Fetcher) cacheArticleContents(articlesWithContent []*ArticleWithContent) {
+	contents := make(map[int64]string)
+	articleIDs := make([]int64, 0, len(articlesWithContent))
 	for _, awc := range articlesWithContent {
 		// Only cache if content is not empty and URL is present
 		if awc.Content == "" || awc.Article.URL == "" {
 			continue
 		}
 
-		// Get article ID by raw unique_id (article was just saved, so it should exist)
-		// We use the pre-calculated UniqueID field from the article to avoid recalculating
-		var articleID int64
-		var err error
-		if awc.Article.UniqueID != "" {
-			// If we already have the unique_id, use it directly (faster and more reliable)
-			articleID, err = f.db.GetArticleIDByRawUniqueID(awc.Article.UserID, awc.Article.UniqueID)
-		} else {
-			// Fallback to recalculating if unique_id is not available
-			articleID, err = f.db.GetArticleIDByUniqueID(awc.Article.UserID, awc.Article.Title, awc.Article.FeedID, awc.Article.PublishedAt, awc.Article.HasValidPublishedTime)
-		}
-		
+		// Get article ID (article was just saved, so it should exist)
+		articleID, err := f.findArticleID(awc.Article)
 		if err != nil {
 			// Article might not exist yet (race condition) or other error
 			utils.DebugLog("Could not find article ID for %s: %v", awc.Article.Title, err)
 			continue
 		}
+		if _, seen := contents[articleID]; !seen {
+			articleIDs = append(articleIDs, articleID)
+		}
+		contents[articleID] = awc.Content
+	}
+
+	// Full text fetched from the article pages is kept
+	fullText, err := f.db.GetArticleFullTextIDs(articleIDs)
+	if err != nil {
+		log.Printf("Error getting full text articles: %v", err)
+		return
+	}
 
+	for _, articleID := range articleIDs {
+		if fullText[articleID] {
+			continue
+		}
 		// Cache the content (this will overwrite any existing cache as required)
-		if err := f.db.SetArticleContent(articleID, awc.Content); err != nil {
+		if err := f.db.SetArticleContent(articleID, contents[articleID]); err != nil {
 			log.Printf("Error caching content for article %d: %v", articleID, err)
 		} else {
 			utils.DebugLog("Cached content for article %d", articleID)
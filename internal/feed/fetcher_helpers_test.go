@@ -43,10 +43,14 @@ func TestGetHTTPClientProxyPrecedence(t *testing.T) {
 
 	// Helper function to get the underlying http.Transport
 	getTransport := func(client *http.Client) *http.Transport {
-		if uat, ok := client.Transport.(*httputil.UserAgentTransport); ok {
-			return uat.Original.(*http.Transport)
+		rt := client.Transport
+		if uat, ok := rt.(*httputil.UserAgentTransport); ok {
+			rt = uat.Original
 		}
-		return client.Transport.(*http.Transport)
+		if cbt, ok := rt.(*httputil.CircuitBreakerTransport); ok {
+			rt = cbt.Original
+		}
+		return rt.(*http.Transport)
 	}
 
 	feed := models.Feed{ProxyEnabled: true, ProxyURL: "http://10.0.0.1:3128"}
@@ -10,6 +10,7 @@ import (
 
 	"MavenRSS/internal/store/sqlite"
 	"MavenRSS/internal/models"
+	"MavenRSS/internal/rules"
 )
 
 func TestFetchFeed_SavesArticlesAndAppliesRules(t *testing.T) {
@@ -43,7 +44,7 @@ func TestFetchFeed_SavesArticlesAndAppliesRules(t *testing.T) {
 	}
 
 	// Insert a simple rule to favorite articles with title containing 'favme'
-	rules := []map[string]interface{}{
+	ruleDefs := []map[string]interface{}{
 		{
 			"id":      1,
 			"name":    "fav rule",
@@ -54,8 +55,10 @@ func TestFetchFeed_SavesArticlesAndAppliesRules(t *testing.T) {
 			"actions": []string{"favorite"},
 		},
 	}
-	rb, _ := json.Marshal(rules)
-	db.SetSetting("rules", string(rb))
+	rb, _ := json.Marshal(ruleDefs)
+	if err := rules.NewEngine(db).SaveRulesJSON(1, string(rb)); err != nil {
+		t.Fatalf("SaveRulesJSON error: %v", err)
+	}
 
 	// Fetch the feed
 	feedRow, err := db.GetFeedByID(id)
@@ -0,0 +1,94 @@
+package feed
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"MavenRSS/internal/fulltext"
+	"MavenRSS/internal/utils"
+)
+
+const (
+	// maxFullTextFetches caps the article pages fetched per feed refresh; the
+	// remaining articles are fetched on the next refreshes
+	maxFullTextFetches = 20
+	// maxFullTextFailures stops fetching after that many consecutive failures
+	maxFullTextFailures = 3
+	// fullTextFetchTimeout bounds the extraction of an article, including its next pages
+	fullTextFetchTimeout = 60 * time.Second
+)
+
+// fetchFullTexts stores the full text of the new articles of feeds set to always
+// fetch it, replacing the content from the feed. Requires the global
+// full_text_fetch_enabled setting.
+func (f *Fetcher) fetchFullTexts(task *PostProcessTask) {
+	if len(task.ArticlesWithContent) == 0 {
+		return
+	}
+	if enabled, _ := f.db.GetSetting("full_text_fetch_enabled"); enabled != "true" {
+		return
+	}
+	feed, err := f.db.GetFeedByID(task.FeedID)
+	if err != nil || feed == nil || !feed.FetchFullText {
+		return
+	}
+
+	ids := make(map[int64]string)
+	articleIDs := make([]int64, 0, len(task.ArticlesWithContent))
+	for _, awc := range task.ArticlesWithContent {
+		if awc.Article.URL == "" {
+			continue
+		}
+		articleID, err := f.findArticleID(awc.Article)
+		if _, seen := ids[articleID]; err != nil || seen {
+			continue
+		}
+		ids[articleID] = awc.Article.URL
+		articleIDs = append(articleIDs, articleID)
+	}
+	done, err := f.db.GetArticleFullTextIDs(articleIDs)
+	if err != nil {
+		log.Printf("Error getting full text articles of feed %s: %v", feed.Title, err)
+		return
+	}
+
+	siteRules, err := fulltext.LoadSiteRules(f.db, feed.UserID)
+	if err != nil {
+		log.Printf("Error loading site rules: %v", err)
+	}
+	client, err := f.getHTTPClient(*feed)
+	if err != nil {
+		return
+	}
+
+	fetched, failures := 0, 0
+	for _, articleID := range articleIDs {
+		if done[articleID] {
+			continue
+		}
+		if fetched >= maxFullTextFetches || failures >= maxFullTextFailures {
+			break
+		}
+		fetched++
+
+		pageURL := ids[articleID]
+		ctx, cancel := context.WithTimeout(context.Background(), fullTextFetchTimeout)
+		err := f.checkRobots(ctx, client, feed.UserID, pageURL)
+		var result *fulltext.Result
+		if err == nil {
+			result, err = fulltext.Extract(ctx, client, pageURL, fulltext.MatchSiteRule(siteRules, pageURL))
+		}
+		cancel()
+		if err != nil {
+			failures++
+			utils.DebugLog("Could not fetch the full text of %s: %v", pageURL, err)
+			continue
+		}
+		failures = 0
+
+		if err := f.db.SetArticleFullText(articleID, result.Content); err != nil {
+			log.Printf("Error storing full text of article %d: %v", articleID, err)
+		}
+	}
+}
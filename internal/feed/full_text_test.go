@@ -0,0 +1,68 @@
+package feed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/fulltext"
+	"MavenRSS/internal/models"
+)
+
+func TestFetchFullTexts(t *testing.T) {
+	fetcher, db := newFailuresTestFetcher(t)
+	var pageFetches int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pageFetches++
+		w.Write([]byte(`<html><body><nav>menu</nav><div id="story">The whole story</div></body></html>`))
+	}))
+	defer srv.Close()
+
+	feedID, _ := db.AddFeed(&models.Feed{Title: "truncated", URL: srv.URL + "/rss"})
+	article := &models.Article{FeedID: feedID, UserID: 1, Title: "one", URL: srv.URL + "/1", PublishedAt: time.Now()}
+	if err := db.SaveArticle(article); err != nil {
+		t.Fatalf("SaveArticle: %v", err)
+	}
+	articleID, err := fetcher.findArticleID(article)
+	if err != nil {
+		t.Fatalf("findArticleID: %v", err)
+	}
+	rule := &fulltext.SiteRule{Host: "127.0.0.1", Body: []string{"//div[@id='story']"}}
+	if err := fulltext.SaveSiteRule(db, 1, rule); err != nil {
+		t.Fatalf("SaveSiteRule: %v", err)
+	}
+
+	task := &PostProcessTask{
+		ArticlesWithContent: []*ArticleWithContent{{Article: article, Content: "<p>The whole…</p>"}},
+		FeedID:              feedID,
+		UserID:              1,
+	}
+	refresh := func() {
+		fetcher.cacheArticleContents(task.ArticlesWithContent)
+		fetcher.fetchFullTexts(task)
+	}
+
+	// Full text is only fetched for feeds set to, when enabled globally
+	db.SetSetting("full_text_fetch_enabled", "true")
+	refresh()
+	if content, _, _ := db.GetArticleContent(articleID); pageFetches != 0 || content != "<p>The whole…</p>" {
+		t.Fatalf("full text fetched for a feed not set to: %q", content)
+	}
+
+	if err := db.SetFeedFetchFullText(feedID, true); err != nil {
+		t.Fatalf("SetFeedFetchFullText: %v", err)
+	}
+	refresh()
+	content, _, _ := db.GetArticleContent(articleID)
+	if content != `<div id="story">The whole story</div>` {
+		t.Fatalf("content = %q, want the extracted story", content)
+	}
+
+	// Later refreshes keep the full text and don't fetch the page again
+	refresh()
+	if content, _, _ := db.GetArticleContent(articleID); pageFetches != 1 || !strings.Contains(content, "The whole story") {
+		t.Errorf("full text replaced or fetched again (%d fetches): %q", pageFetches, content)
+	}
+}
@@ -0,0 +1,238 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/rsshub"
+	"MavenRSS/internal/utils/httputil"
+)
+
+const (
+	defaultMaxRefreshesPerHost = 2
+	defaultHostSpacing         = 500 * time.Millisecond
+	// defaultRetryAfter defers a host answering 429 or 503 without Retry-After
+	defaultRetryAfter = time.Minute
+	// maxRetryAfter caps how long a host's queued tasks wait, so refreshes don't hang
+	maxRetryAfter = 15 * time.Minute
+)
+
+// RateLimitError is returned when a host answered 429 Too Many Requests or
+// 503 Service Unavailable. The host's queued refreshes wait for RetryAfter.
+type RateLimitError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s, retrying after %v", e.StatusCode, http.StatusText(e.StatusCode), e.RetryAfter)
+}
+
+// newRateLimitError returns the rate limit error of a 429 or 503 response, or nil
+func newRateLimitError(resp *http.Response, retryAfter time.Duration) *RateLimitError {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return nil
+	}
+	if retryAfter <= 0 {
+		retryAfter = defaultRetryAfter
+	}
+	if retryAfter > maxRetryAfter {
+		retryAfter = maxRetryAfter
+	}
+	return &RateLimitError{StatusCode: resp.StatusCode, RetryAfter: retryAfter}
+}
+
+// feedHost returns the host a feed is refreshed from, or "" for feeds that
+// aren't fetched over HTTP, like email and script feeds
+func feedHost(feed models.Feed) string {
+	if feed.Type == "email" || feed.ScriptPath != "" {
+		return ""
+	}
+	if rsshub.IsRSSHubURL(feed.URL) {
+		// All routes are served by the configured RSSHub instance
+		return "rsshub"
+	}
+	u, err := url.Parse(feed.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// hostLimiter keeps refreshes polite to each host: it caps the concurrent refreshes
+// of a host, spaces their starts, and defers a host's refreshes after it asked to
+// retry later.
+type hostLimiter struct {
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	active        int
+	lastStart     time.Time
+	deferredUntil time.Time
+}
+
+func newHostLimiter() *hostLimiter {
+	return &hostLimiter{hosts: make(map[string]*hostState)}
+}
+
+// tryAcquire starts a refresh on the host if its limits allow it. Otherwise it returns
+// how long to wait before the host may be ready, or 0 when waiting for a running refresh.
+func (l *hostLimiter) tryAcquire(host string, now time.Time, maxPerHost int, spacing time.Duration) (bool, time.Duration) {
+	if host == "" {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := l.hosts[host]
+	if s == nil {
+		s = &hostState{}
+		l.hosts[host] = s
+	}
+	if now.Before(s.deferredUntil) {
+		return false, s.deferredUntil.Sub(now)
+	}
+	if maxPerHost > 0 && s.active >= maxPerHost {
+		return false, 0
+	}
+	if next := s.lastStart.Add(spacing); now.Before(next) {
+		return false, next.Sub(now)
+	}
+	s.active++
+	s.lastStart = now
+	return true, 0
+}
+
+// release ends a refresh on the host
+func (l *hostLimiter) release(host string) {
+	if host == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if s := l.hosts[host]; s != nil {
+		if s.active > 0 {
+			s.active--
+		}
+		if s.active == 0 && time.Now().After(s.deferredUntil) && time.Since(s.lastStart) > time.Minute {
+			delete(l.hosts, host)
+		}
+	}
+}
+
+// deferHost delays the refreshes of a host until the given time
+func (l *hostLimiter) deferHost(host string, until time.Time) {
+	if host == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := l.hosts[host]
+	if s == nil {
+		s = &hostState{}
+		l.hosts[host] = s
+	}
+	if until.After(s.deferredUntil) {
+		s.deferredUntil = until
+	}
+}
+
+// hostLimits returns the per-host concurrency cap and start spacing from the settings
+func (tm *TaskManager) hostLimits() (int, time.Duration) {
+	maxPerHost := defaultMaxRefreshesPerHost
+	if v, err := tm.fetcher.db.GetSetting("max_concurrent_refreshes_per_host"); err == nil {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			maxPerHost = n
+		}
+	}
+	spacing := defaultHostSpacing
+	if v, err := tm.fetcher.db.GetSetting("host_refresh_spacing_ms"); err == nil {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			spacing = time.Duration(n) * time.Millisecond
+		}
+	}
+	return maxPerHost, spacing
+}
+
+// nextReadyTask removes the first queued feed whose host accepts another refresh
+// from the queue and starts its refresh on the host. When none does, it returns how
+// long until a deferred or spaced host may be ready, or 0 to wait for a running refresh.
+// The caller holds queueMutex.
+func (tm *TaskManager) nextReadyTask(now time.Time, maxPerHost int, spacing time.Duration) (int64, string, time.Duration) {
+	var wait time.Duration
+	for i, feedID := range tm.queue {
+		host := tm.queueHosts[feedID]
+		ok, readyIn := tm.hosts.tryAcquire(host, now, maxPerHost, spacing)
+		if ok {
+			tm.queue = append(tm.queue[:i:i], tm.queue[i+1:]...)
+			delete(tm.queueHosts, feedID)
+			return feedID, host, 0
+		}
+		if readyIn > 0 && (wait == 0 || readyIn < wait) {
+			wait = readyIn
+		}
+	}
+	return 0, "", wait
+}
+
+// scheduleWakeup processes the queue again after the given delay, unless an
+// earlier wakeup is already scheduled
+func (tm *TaskManager) scheduleWakeup(ctx context.Context, delay time.Duration) {
+	tm.wakeupMutex.Lock()
+	defer tm.wakeupMutex.Unlock()
+
+	at := time.Now().Add(delay)
+	if tm.wakeup != nil && !tm.wakeupAt.After(at) {
+		return
+	}
+	if tm.wakeup != nil {
+		tm.wakeup.Stop()
+	}
+	tm.wakeupAt = at
+	tm.wakeup = time.AfterFunc(delay, func() {
+		tm.wakeupMutex.Lock()
+		tm.wakeup = nil
+		tm.wakeupMutex.Unlock()
+		tm.processQueue(ctx)
+	})
+}
+
+// deferHostAfter defers the queued refreshes of a host after a failed fetch when the
+// host asked to retry later or its circuit is open. It reports whether it did.
+func (tm *TaskManager) deferHostAfter(host string, err error) bool {
+	var rateLimitErr *RateLimitError
+	var circuitErr *httputil.CircuitOpenError
+	switch {
+	case errors.As(err, &rateLimitErr):
+		log.Printf("Host %s asked to retry after %v, deferring its refreshes", host, rateLimitErr.RetryAfter)
+		tm.hosts.deferHost(host, time.Now().Add(rateLimitErr.RetryAfter))
+	case errors.As(err, &circuitErr):
+		tm.hosts.deferHost(host, circuitErr.Until)
+	default:
+		return false
+	}
+	return true
+}
+
+// checkRobots returns httputil.ErrDisallowedByRobots when the respect_robots_txt
+// setting is enabled and the site's robots.txt disallows fetching the page
+func (f *Fetcher) checkRobots(ctx context.Context, client *http.Client, userID int64, pageURL string) error {
+	enabled, _ := f.db.GetSettingWithFallback(userID, "respect_robots_txt")
+	if enabled != "true" {
+		return nil
+	}
+	return httputil.GetRobotsChecker().Check(ctx, client, pageURL)
+}
@@ -0,0 +1,141 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/utils/httputil"
+)
+
+func TestHostLimiter_TryAcquire(t *testing.T) {
+	l := newHostLimiter()
+	now := time.Now()
+
+	if ok, _ := l.tryAcquire("a.example", now, 2, 0); !ok {
+		t.Fatal("first refresh not started")
+	}
+	if ok, _ := l.tryAcquire("a.example", now, 2, 0); !ok {
+		t.Fatal("second refresh not started")
+	}
+	if ok, wait := l.tryAcquire("a.example", now, 2, 0); ok || wait != 0 {
+		t.Fatalf("third refresh = %v, %v, want a wait for a running refresh", ok, wait)
+	}
+	if ok, _ := l.tryAcquire("b.example", now, 2, 0); !ok {
+		t.Fatal("other host blocked")
+	}
+	l.release("a.example")
+	if ok, _ := l.tryAcquire("a.example", now, 2, 0); !ok {
+		t.Fatal("refresh not started after release")
+	}
+
+	// Spacing
+	if ok, _ := l.tryAcquire("c.example", now, 0, time.Second); !ok {
+		t.Fatal("first spaced refresh not started")
+	}
+	if ok, wait := l.tryAcquire("c.example", now.Add(200*time.Millisecond), 0, time.Second); ok || wait != 800*time.Millisecond {
+		t.Fatalf("spaced refresh = %v, %v", ok, wait)
+	}
+
+	// Deferral
+	l.deferHost("d.example", now.Add(time.Minute))
+	if ok, wait := l.tryAcquire("d.example", now, 0, 0); ok || wait != time.Minute {
+		t.Fatalf("deferred refresh = %v, %v", ok, wait)
+	}
+	if ok, _ := l.tryAcquire("d.example", now.Add(time.Minute+time.Second), 0, 0); !ok {
+		t.Fatal("refresh not started after the deferral")
+	}
+
+	// Feeds without a host are never limited
+	if ok, _ := l.tryAcquire("", now, 1, time.Hour); !ok {
+		t.Fatal("hostless refresh blocked")
+	}
+}
+
+func TestTaskManager_NextReadyTask(t *testing.T) {
+	tm := &TaskManager{
+		queue:      []int64{1, 2, 3},
+		queueHosts: map[int64]string{1: "busy.example", 2: "busy.example", 3: "idle.example"},
+		hosts:      newHostLimiter(),
+	}
+	now := time.Now()
+	tm.hosts.tryAcquire("busy.example", now, 1, 0)
+
+	id, host, _ := tm.nextReadyTask(now, 1, 0)
+	if id != 3 || host != "idle.example" {
+		t.Fatalf("nextReadyTask = %d, %q, want 3 on idle.example", id, host)
+	}
+	if len(tm.queue) != 2 || tm.queue[0] != 1 || tm.queue[1] != 2 {
+		t.Fatalf("queue = %v", tm.queue)
+	}
+
+	tm.hosts.deferHost("busy.example", now.Add(time.Minute))
+	tm.hosts.release("busy.example")
+	if id, _, wait := tm.nextReadyTask(now, 1, 0); id != 0 || wait != time.Minute {
+		t.Fatalf("nextReadyTask on a deferred host = %d, %v", id, wait)
+	}
+}
+
+func TestFetchFeedWithContext_RetryAfter(t *testing.T) {
+	fetcher, db := newFailuresTestFetcher(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	id, _ := db.AddFeed(&models.Feed{Title: "throttled", URL: srv.URL})
+	feed, _ := db.GetFeedByID(id)
+	err := fetcher.fetchFeedWithContext(context.Background(), *feed)
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) || rateLimitErr.RetryAfter != 2*time.Minute {
+		t.Fatalf("expected a rate limit error retrying after 2m, got %v", err)
+	}
+
+	// Throttling doesn't count as a failure of the feed
+	fetcher.recordRefreshResult(*feed, err)
+	if feed, _ = db.GetFeedByID(id); feed.ConsecutiveFailures != 0 {
+		t.Errorf("throttled refresh counted as a failure")
+	}
+
+	tm := &TaskManager{hosts: newHostLimiter()}
+	if !tm.deferHostAfter(feedHost(*feed), err) {
+		t.Fatal("host not deferred")
+	}
+	if ok, wait := tm.hosts.tryAcquire(feedHost(*feed), time.Now(), 0, 0); ok || wait <= time.Minute {
+		t.Errorf("deferred host = %v, %v", ok, wait)
+	}
+}
+
+func TestCheckRobots(t *testing.T) {
+	fetcher, db := newFailuresTestFetcher(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("User-agent: *\nDisallow: /private\nAllow: /private/feed$\n\nUser-agent: OtherBot\nDisallow: /\n"))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := srv.Client()
+	if err := fetcher.checkRobots(ctx, client, 0, srv.URL+"/private/page"); err != nil {
+		t.Fatalf("robots.txt checked while disabled: %v", err)
+	}
+
+	db.SetSetting("respect_robots_txt", "true")
+	tests := map[string]bool{
+		"/":                  true,
+		"/blog/post":         true,
+		"/private/page":      false,
+		"/private/feed":      true,
+		"/private/feed/more": false,
+	}
+	for path, allowed := range tests {
+		err := fetcher.checkRobots(ctx, client, 0, srv.URL+path)
+		if allowed != (err == nil) || (err != nil && !errors.Is(err, httputil.ErrDisallowedByRobots)) {
+			t.Errorf("checkRobots(%s) = %v, want allowed %v", path, err, allowed)
+		}
+	}
+}
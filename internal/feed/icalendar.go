@@ -0,0 +1,32 @@
+package feed
+
+import (
+	"log"
+
+	"MavenRSS/internal/feed/source"
+	"MavenRSS/internal/models"
+)
+
+// isICalendarFeed reports whether a feed is an iCalendar calendar, either by its type or,
+// for feeds without a type, by a webcal:// or .ics URL
+func isICalendarFeed(feed *models.Feed) bool {
+	if feed.Type == models.FeedTypeICalendar {
+		return true
+	}
+	return feed.Type == "" && feed.ScriptPath == "" && source.IsICalendarURL(feed.URL)
+}
+
+// updateChangedEvents updates the articles of the events of a calendar that changed
+// since the last refresh. The links of the items identify the event occurrences, so a
+// new summary, date or description updates the article instead of adding another one.
+func (f *Fetcher) updateChangedEvents(feed models.Feed, articles []*ArticleWithContent) {
+	for _, awc := range articles {
+		article := awc.Article
+		if article.URL == "" {
+			continue
+		}
+		if _, err := f.db.UpdateArticleByURL(feed.UserID, feed.ID, article.URL, article.Title, awc.Content, article.PublishedAt, article.HasValidPublishedTime); err != nil {
+			log.Printf("Error updating the event %s of feed %d: %v", article.URL, feed.ID, err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package feed
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/feed/source"
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+func TestSaveChangedCalendarEvents(t *testing.T) {
+	db, err := sqlite.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	defer db.Close()
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	fetcher := NewFetcher(db)
+
+	feed := models.Feed{Title: "Conferences", URL: "https://example.com/conferences.ics", Type: models.FeedTypeICalendar}
+	if feed.ID, err = db.AddFeedForUser(1, &feed); err != nil {
+		t.Fatalf("AddFeedForUser: %v", err)
+	}
+	feed.UserID = 1
+
+	start := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Hour).Add(30 * time.Minute)
+	save := func(summary, description string, start time.Time) {
+		t.Helper()
+		data := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:gophercon@example.com\r\n" +
+			"DTSTART:" + start.Format("20060102T150405Z") + "\r\nSUMMARY:" + summary +
+			"\r\nDESCRIPTION:" + description + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+		parsed, err := source.ParseICalendar([]byte(data), feed.URL, start.AddDate(0, 0, -7), start.AddDate(0, 0, 7))
+		if err != nil || len(parsed.Items) != 1 {
+			t.Fatalf("ParseICalendar = %+v (%v)", parsed, err)
+		}
+		if err := fetcher.saveFeedItems(context.Background(), feed, parsed.Items); err != nil {
+			t.Fatalf("saveFeedItems: %v", err)
+		}
+	}
+
+	save("GopherCon", "Talks", start)
+	save("GopherCon", "Talks", start)
+	// Renamed, moved to the next day and described again
+	moved := start.AddDate(0, 0, 1)
+	save("GopherCon EU", "Talks and workshops", moved)
+
+	articles, err := db.GetArticles("", feed.ID, "", false, 10, 0)
+	if err != nil || len(articles) != 1 {
+		t.Fatalf("expected the event to stay a single article, got %d (%v)", len(articles), err)
+	}
+	if articles[0].Title != "GopherCon EU" || articles[0].URL != feed.URL+"#event=gophercon@example.com" {
+		t.Errorf("unexpected article %q %q", articles[0].Title, articles[0].URL)
+	}
+	if !articles[0].PublishedAt.Equal(moved) {
+		t.Errorf("expected the event to start at %v, got %v", moved, articles[0].PublishedAt)
+	}
+	contents, err := db.GetArticleContents([]int64{articles[0].ID})
+	if err != nil || !strings.Contains(contents[articles[0].ID], "Talks and workshops") {
+		t.Errorf("expected the new description, got %q (%v)", contents[articles[0].ID], err)
+	}
+}
@@ -0,0 +1,74 @@
+package feed
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mmcdole/gofeed"
+
+	"MavenRSS/internal/feed/source"
+	"MavenRSS/internal/models"
+)
+
+// jsonPathConfig returns the source config of a JSON+JSONPath feed. Its JSONPath
+// expressions are stored in the XPath fields, as FreshRSS does for its JSON feeds.
+func jsonPathConfig(feed *models.Feed) *source.Config {
+	config := &source.Config{
+		URL:                feed.URL,
+		SourceType:         source.TypeJSONPath,
+		JSONPathItems:      feed.XPathItem,
+		JSONPathTitle:      feed.XPathItemTitle,
+		JSONPathLink:       feed.XPathItemUri,
+		JSONPathContent:    feed.XPathItemContent,
+		JSONPathAuthor:     feed.XPathItemAuthor,
+		JSONPathDate:       feed.XPathItemTimestamp,
+		JSONPathDateFormat: feed.XPathItemTimeFormat,
+		JSONPathThumbnail:  feed.XPathItemThumbnail,
+		JSONPathCategories: feed.XPathItemCategories,
+		JSONPathUID:        feed.XPathItemUid,
+	}
+	if p := feed.Pagination; p != nil {
+		config.JSONPathNext = p.Next
+		config.JSONPathCursorParam = p.CursorParam
+		config.MaxPages = p.MaxPages
+	}
+	return config
+}
+
+// ValidateJSONPathFeed checks the URL and JSONPath expressions of a JSON+JSONPath feed
+func ValidateJSONPathFeed(feed *models.Feed) error {
+	return new(source.JSONPathSource).Validate(jsonPathConfig(feed))
+}
+
+// PreviewJSONPathFeed fetches a JSON+JSONPath feed that isn't saved yet and returns
+// the items its JSONPath expressions extract, e.g. to check them before subscribing.
+// The feed's proxy settings and credentials are used.
+func (f *Fetcher) PreviewJSONPathFeed(ctx context.Context, feed models.Feed) (*gofeed.Feed, error) {
+	feed.Type = models.FeedTypeJSONPath
+	return f.parseFeedWithSource(ctx, &feed)
+}
+
+// AddJSONPathSubscription adds a JSON+JSONPath feed after checking that its API can be
+// fetched with its JSONPath expressions, and returns the feed ID.
+func (f *Fetcher) AddJSONPathSubscription(ctx context.Context, feed *models.Feed) (int64, error) {
+	if feed.URL == "" {
+		return 0, errors.New("URL cannot be empty")
+	}
+	if _, err := f.PreviewJSONPathFeed(ctx, *feed); err != nil {
+		return 0, err
+	}
+	feed.Type = models.FeedTypeJSONPath
+	if feed.Title == "" {
+		feed.Title = "JSONPath Feed"
+	}
+	id, err := f.db.AddFeed(feed)
+	if err != nil {
+		return 0, err
+	}
+	if feed.Pagination != nil {
+		if err := f.db.SetFeedPagination(id, feed.Pagination); err != nil {
+			return id, err
+		}
+	}
+	return id, nil
+}
@@ -0,0 +1,76 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+func TestAddJSONPathSubscription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`{"incidents": [{"id": "b", "name": "Degraded API", "shortlink": "/b"}]}`))
+			return
+		}
+		w.Write([]byte(`{"incidents": [{"id": "a", "name": "Outage", "shortlink": "/a"}], "next_page": "2"}`))
+	}))
+	defer server.Close()
+
+	db, err := sqlite.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	defer db.Close()
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	fetcher := NewFetcher(db)
+
+	feed := &models.Feed{
+		URL:            server.URL + "/api/v2/incidents.json",
+		Title:          "Status",
+		XPathItem:      "$.incidents[*]",
+		XPathItemTitle: "name",
+		XPathItemUri:   "shortlink",
+		XPathItemUid:   "id",
+		Auth:           &models.FeedAuth{Type: models.FeedAuthBearer, Token: "secret"},
+		Pagination:     &models.FeedPagination{Next: "$.next_page", CursorParam: "page", MaxPages: 3},
+	}
+
+	preview, err := fetcher.PreviewJSONPathFeed(context.Background(), *feed)
+	if err != nil || len(preview.Items) != 2 || preview.Items[1].Title != "Degraded API" || preview.Items[1].Link != server.URL+"/b" {
+		t.Fatalf("PreviewJSONPathFeed = %+v (%v)", preview, err)
+	}
+
+	id, err := fetcher.AddJSONPathSubscription(context.Background(), feed)
+	if err != nil {
+		t.Fatalf("AddJSONPathSubscription: %v", err)
+	}
+	if err := db.SetFeedAuth(id, feed.Auth); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := db.GetFeedByID(id)
+	if err != nil || stored.Type != models.FeedTypeJSONPath || stored.Pagination == nil || stored.Pagination.MaxPages != 3 {
+		t.Fatalf("unexpected stored feed %+v (%v)", stored, err)
+	}
+
+	// Refreshes read the pages with the stored mapping
+	parsed, err := fetcher.ParseFeedWithFeed(context.Background(), stored, false)
+	if err != nil || len(parsed.Items) != 2 || parsed.Items[0].GUID != "a" {
+		t.Fatalf("ParseFeedWithFeed = %+v (%v)", parsed, err)
+	}
+
+	feed.XPathItem = "$.incidents[?(@.id)]"
+	if err := ValidateJSONPathFeed(feed); err == nil {
+		t.Error("expected filter expressions to be refused")
+	}
+}
@@ -0,0 +1,193 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+
+	"MavenRSS/internal/feed/source"
+	"MavenRSS/internal/models"
+)
+
+// ErrMailArchivesDisabled is returned when importing a mail archive without EnableMailArchives
+var ErrMailArchivesDisabled = errors.New("mail archive import is not enabled")
+
+// ErrMailArchiveOutsideRoot is returned for mail archives outside the allowed directory
+var ErrMailArchiveOutsideRoot = errors.New("mail archive is outside the allowed directory")
+
+// MailArchiveFeed is a feed of a mailing list or sender of an imported mail archive
+type MailArchiveFeed struct {
+	FeedID   int64  `json:"feed_id"`
+	Title    string `json:"title"`
+	Group    string `json:"group"`    // "list:<List-Id>" or "from:<address>"
+	Articles int    `json:"articles"` // Messages of the group in the archive
+	Created  bool   `json:"created"`  // Whether the feed was created by this import
+}
+
+// EnableMailArchives allows importing mbox files and Maildir directories. When root is
+// set, only the archives inside it can be read and relative paths are resolved
+// against it; otherwise any path is allowed.
+func (f *Fetcher) EnableMailArchives(root string) {
+	if root != "" {
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolved
+		}
+	}
+	f.mailArchives = source.NewMailArchiveSource()
+	f.mailArchiveRoot = root
+}
+
+// mailArchivePath returns the absolute path of a mail archive, checking that it can be read
+func (f *Fetcher) mailArchivePath(path string) (string, error) {
+	if f.mailArchives == nil {
+		return "", ErrMailArchivesDisabled
+	}
+	if f.mailArchiveRoot != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(f.mailArchiveRoot, path)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if f.mailArchiveRoot == "" {
+		return path, nil
+	}
+
+	// Check the path before and after resolving its symlinks, so that nothing is
+	// disclosed about files outside the root
+	if !isInsideDir(f.mailArchiveRoot, path) {
+		return "", ErrMailArchiveOutsideRoot
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	if !isInsideDir(f.mailArchiveRoot, resolved) {
+		return "", ErrMailArchiveOutsideRoot
+	}
+	return path, nil
+}
+
+// isInsideDir reports whether an absolute path is dir or inside it
+func isInsideDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ImportMailArchive imports the newsletters of an mbox file, Maildir or directory of
+// them, with a feed per mailing list (List-Id) or sender. Importing an archive again
+// reuses its feeds and only adds the new messages. Watched archives are read again on
+// each refresh of their feeds, so that messages added later appear too.
+func (f *Fetcher) ImportMailArchive(ctx context.Context, userID int64, path, category string, watch bool) ([]MailArchiveFeed, error) {
+	path, err := f.mailArchivePath(path)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := f.mailArchives.FetchGroups(ctx, source.ConfigFromMailArchive(path, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	feeds, err := f.db.GetFeedsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	byURL := make(map[string]models.Feed)
+	for _, feed := range feeds {
+		if feed.Type == models.FeedTypeMailArchive {
+			byURL[feed.URL] = feed
+		}
+	}
+
+	result := make([]MailArchiveFeed, 0, len(groups))
+	for _, group := range groups {
+		// Watched and one-shot imports of an archive share the feed of a group
+		feed, found := byURL[source.MailArchiveURL(path, group.Key, true)]
+		if !found {
+			feed, found = byURL[source.MailArchiveURL(path, group.Key, false)]
+		}
+		if !found {
+			feed = models.Feed{
+				UserID:      userID,
+				Title:       group.Title,
+				URL:         source.MailArchiveURL(path, group.Key, watch),
+				Description: fmt.Sprintf("Newsletters of %s imported from %s", group.Title, path),
+				Category:    category,
+				Type:        models.FeedTypeMailArchive,
+			}
+			if feed.ID, err = f.db.AddFeedForUser(userID, &feed); err != nil {
+				return nil, fmt.Errorf("failed to add feed for %s: %w", group.Key, err)
+			}
+		}
+
+		if err := f.saveFeedItems(ctx, feed, group.Feed.Items); err != nil {
+			return nil, fmt.Errorf("failed to import %s: %w", group.Key, err)
+		}
+		f.db.UpdateFeedError(feed.ID, "")
+		f.db.UpdateFeedLastUpdated(feed.ID)
+
+		result = append(result, MailArchiveFeed{
+			FeedID:   feed.ID,
+			Title:    feed.Title,
+			Group:    group.Key,
+			Articles: len(group.Feed.Items),
+			Created:  !found,
+		})
+	}
+	return result, nil
+}
+
+// skipImportedMessages returns the messages of a mail archive feed whose Message-Id
+// wasn't imported before
+func (f *Fetcher) skipImportedMessages(feed models.Feed, items []*gofeed.Item) []*gofeed.Item {
+	uids := make([]string, 0, len(items))
+	for _, item := range items {
+		uids = append(uids, item.GUID)
+	}
+	known, err := f.db.GetKnownItemUIDs(feed.ID, uids)
+	if err != nil {
+		// The articles are still deduplicated by title and date
+		log.Printf("Error reading the imported messages of feed %d: %v", feed.ID, err)
+		return items
+	}
+	if len(known) == 0 {
+		return items
+	}
+	fresh := make([]*gofeed.Item, 0, len(items)-len(known))
+	for _, item := range items {
+		if !known[item.GUID] {
+			fresh = append(fresh, item)
+		}
+	}
+	return fresh
+}
+
+// parseMailArchive reads the messages of the group of a mail archive feed. Archives
+// imported once have nothing to refresh.
+func (f *Fetcher) parseMailArchive(ctx context.Context, feed *models.Feed) (*gofeed.Feed, error) {
+	path, group, watch, err := source.ParseMailArchiveURL(feed.URL)
+	if err != nil {
+		return nil, err
+	}
+	if !watch {
+		return &gofeed.Feed{Title: feed.Title, Description: feed.Description}, nil
+	}
+	if path, err = f.mailArchivePath(path); err != nil {
+		return nil, err
+	}
+
+	parsedFeed, err := f.mailArchives.Fetch(ctx, source.ConfigFromMailArchive(path, group))
+	if err != nil {
+		return nil, err
+	}
+	parsedFeed.Title = feed.Title
+	return parsedFeed, nil
+}
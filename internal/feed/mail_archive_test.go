@@ -0,0 +1,87 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+func TestImportMailArchive(t *testing.T) {
+	// The import writes from several connections, which an in-memory database can't share
+	root := t.TempDir()
+	db, err := sqlite.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	defer db.Close()
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	fetcher := NewFetcher(db)
+	mbox := filepath.Join(root, "news.mbox")
+	message := func(id, from, list string) string {
+		data := "From " + from + " Mon Jan  2 15:04:05 2006\n" +
+			"From: " + from + "\nSubject: " + id + "\nMessage-Id: <" + id + "@example.com>\n" +
+			"Date: Mon, 02 Jan 2006 15:04:05 +0000\n"
+		if list != "" {
+			data += "List-Id: " + list + "\n"
+		}
+		return data + "\n<p>" + id + "</p>\n\n"
+	}
+	os.WriteFile(mbox, []byte(message("one", "news@list.example", "News <list.example>")+message("two", "a@writer.example", "")), 0o644)
+
+	if _, err := fetcher.ImportMailArchive(context.Background(), 1, mbox, "", false); !errors.Is(err, ErrMailArchivesDisabled) {
+		t.Fatalf("expected import to be disabled, got %v", err)
+	}
+	fetcher.EnableMailArchives(root)
+	if _, err := fetcher.ImportMailArchive(context.Background(), 1, filepath.Join(root, "..", "other.mbox"), "", false); !errors.Is(err, ErrMailArchiveOutsideRoot) {
+		t.Errorf("expected archives outside the root to be refused, got %v", err)
+	}
+
+	feeds, err := fetcher.ImportMailArchive(context.Background(), 1, "news.mbox", "Mail", true)
+	if err != nil {
+		t.Fatalf("ImportMailArchive: %v", err)
+	}
+	if len(feeds) != 2 || feeds[0].Group != "list:list.example" || feeds[0].Title != "News" || !feeds[0].Created {
+		t.Fatalf("unexpected feeds %+v", feeds)
+	}
+
+	// Importing again adds the new messages to the same feeds
+	os.WriteFile(mbox, []byte(message("one", "news@list.example", "News <list.example>")+message("two", "a@writer.example", "")+
+		message("three", "news@list.example", "News <list.example>")), 0o644)
+	again, err := fetcher.ImportMailArchive(context.Background(), 1, mbox, "Mail", true)
+	if err != nil || len(again) != 2 || again[0].FeedID != feeds[0].FeedID || again[0].Created || again[0].Articles != 2 {
+		t.Fatalf("unexpected feeds after reimport %+v (%v)", again, err)
+	}
+	articles, err := db.GetArticles("", feeds[0].FeedID, "", false, 10, 0)
+	if err != nil || len(articles) != 2 {
+		t.Fatalf("expected 2 articles without duplicates, got %d (%v)", len(articles), err)
+	}
+
+	// Messages imported before aren't added again, even after their article was deleted
+	if _, err := db.Exec(`DELETE FROM articles WHERE feed_id = ? AND title = 'one'`, feeds[0].FeedID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fetcher.ImportMailArchive(context.Background(), 1, mbox, "Mail", true); err != nil {
+		t.Fatalf("ImportMailArchive: %v", err)
+	}
+	articles, err = db.GetArticles("", feeds[0].FeedID, "", false, 10, 0)
+	if err != nil || len(articles) != 1 || articles[0].Title != "three" {
+		t.Fatalf("expected the deleted message to stay deleted, got %+v (%v)", articles, err)
+	}
+
+	// Watched archives are read again on refresh
+	feed, _ := db.GetFeedByID(feeds[1].FeedID)
+	if feed.Type != models.FeedTypeMailArchive || feed.Category != "Mail" {
+		t.Errorf("unexpected feed %+v", feed)
+	}
+	parsed, err := fetcher.ParseFeedWithFeed(context.Background(), feed, false)
+	if err != nil || len(parsed.Items) != 1 || parsed.Items[0].Title != "two" {
+		t.Errorf("refresh of watched archive feed = %+v (%v)", parsed, err)
+	}
+}
@@ -0,0 +1,364 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"MavenRSS/internal/feed/source"
+	"MavenRSS/internal/mailserver"
+	"MavenRSS/internal/models"
+)
+
+// ErrMailReceiverDisabled is returned when creating a newsletter address without mail receiver
+var ErrMailReceiverDisabled = errors.New("mail receiver is not enabled")
+
+// MailReceiver runs SMTP/LMTP listeners accepting newsletters sent to generated
+// per-feed addresses (feed-<token>@domain) and saves them as articles of their feed.
+// Newsletters of different mailing lists sent to one address are grouped into a feed
+// per List-Id.
+type MailReceiver struct {
+	fetcher *Fetcher
+	domain  string
+
+	mu      sync.Mutex // Serializes deliveries so that the feed of a new list is created once
+	servers []*mailserver.Server
+}
+
+// NewMailReceiver creates a mail receiver for the addresses of a domain
+func NewMailReceiver(f *Fetcher, domain string) *MailReceiver {
+	return &MailReceiver{fetcher: f, domain: strings.ToLower(domain)}
+}
+
+// Domain returns the domain of the newsletter addresses
+func (mr *MailReceiver) Domain() string {
+	return mr.domain
+}
+
+// Listen starts accepting mail on an address, speaking LMTP instead of SMTP if lmtp is
+// set. An address starting with "/" is a unix socket, e.g. for an LMTP delivery from a
+// local MTA.
+func (mr *MailReceiver) Listen(addr string, lmtp bool) error {
+	network := "tcp"
+	if strings.HasPrefix(addr, "/") {
+		network = "unix"
+		// Remove the socket left by a previous run
+		if fi, err := os.Stat(addr); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			os.Remove(addr)
+		}
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+
+	server := &mailserver.Server{Backend: mr, Domain: mr.domain, LMTP: lmtp}
+	mr.mu.Lock()
+	mr.servers = append(mr.servers, server)
+	mr.mu.Unlock()
+
+	go func() {
+		if err := server.Serve(l); err != nil && !errors.Is(err, mailserver.ErrServerClosed) {
+			log.Printf("Mail receiver on %s stopped: %v", addr, err)
+		}
+	}()
+	log.Printf("Mail receiver listening on %s for @%s", addr, mr.domain)
+	return nil
+}
+
+// Stop closes the listeners, waiting for the deliveries in progress
+func (mr *MailReceiver) Stop() {
+	mr.mu.Lock()
+	servers := mr.servers
+	mr.servers = nil
+	mr.mu.Unlock()
+	for _, server := range servers {
+		server.Close()
+	}
+}
+
+// NewAddress generates a new newsletter address
+func (mr *MailReceiver) NewAddress() (string, error) {
+	token, err := randomHex(8)
+	if err != nil {
+		return "", err
+	}
+	return "feed-" + token + "@" + mr.domain, nil
+}
+
+// CheckRecipient accepts the addresses of existing newsletter feeds
+func (mr *MailReceiver) CheckRecipient(address string) error {
+	_, domain, ok := strings.Cut(address, "@")
+	if !ok || !strings.EqualFold(domain, mr.domain) {
+		return mailserver.ErrRelayDenied
+	}
+	ids, err := mr.fetcher.db.GetReceivedEmailFeedIDs(address)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return mailserver.ErrUnknownRecipient
+	}
+	return nil
+}
+
+// Deliver saves a newsletter as an article of the feed of its recipient and mailing list
+func (mr *MailReceiver) Deliver(ctx context.Context, from, recipient string, data []byte) error {
+	msg, err := parseNewsletter(data)
+	if err != nil {
+		return &mailserver.Error{Code: 554, EnhancedCode: "5.6.0", Message: "Malformed message"}
+	}
+
+	mr.mu.Lock()
+	feed, err := mr.newsletterFeed(recipient, msg)
+	mr.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := mr.fetcher.saveFeedItems(ctx, *feed, []*gofeed.Item{msg.item}); err != nil {
+		return err
+	}
+	db := mr.fetcher.db
+	db.UpdateFeedError(feed.ID, "")
+	db.UpdateFeedLastUpdated(feed.ID)
+	return nil
+}
+
+// newsletterFeed returns the feed receiving a message sent to an address. Messages
+// without List-Id go to the first feed of the address, which also takes the first
+// mailing list it receives; other lists get a feed of their own.
+func (mr *MailReceiver) newsletterFeed(address string, msg *newsletterMessage) (*models.Feed, error) {
+	db := mr.fetcher.db
+	ids, err := db.GetReceivedEmailFeedIDs(address)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, mailserver.ErrUnknownRecipient
+	}
+	feeds := make([]*models.Feed, 0, len(ids))
+	for _, id := range ids {
+		feed, err := db.GetFeedByID(id)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, feed)
+	}
+
+	feed := feeds[0]
+	if msg.listID != "" {
+		found := false
+		for _, f := range feeds {
+			if f.EmailListID == msg.listID {
+				feed, found = f, true
+				break
+			}
+		}
+		if !found && feed.EmailListID != "" {
+			sibling, err := mr.addListFeed(feed, msg)
+			if err != nil {
+				log.Printf("Could not create a feed for list %s of %s: %v", msg.listID, address, err)
+			} else {
+				feed = sibling
+			}
+		}
+	}
+
+	// Remember the list and its latest unsubscribe link
+	listID := feed.EmailListID
+	if listID == "" {
+		listID = msg.listID
+	}
+	unsubscribe := feed.EmailUnsubscribe
+	if msg.unsubscribe != "" && (listID == msg.listID || msg.listID == "") {
+		unsubscribe = msg.unsubscribe
+	}
+	if listID != feed.EmailListID || unsubscribe != feed.EmailUnsubscribe {
+		if err := db.UpdateFeedEmailList(feed.ID, listID, unsubscribe); err != nil {
+			return nil, err
+		}
+		feed.EmailListID = listID
+		feed.EmailUnsubscribe = unsubscribe
+	}
+	return feed, nil
+}
+
+// addListFeed creates the feed of another mailing list sent to the address of a feed
+func (mr *MailReceiver) addListFeed(primary *models.Feed, msg *newsletterMessage) (*models.Feed, error) {
+	title := msg.listName
+	if title == "" {
+		title = msg.listID
+	}
+	feed := &models.Feed{
+		UserID:       primary.UserID,
+		Title:        title,
+		URL:          "email://" + primary.EmailAddress + "?list=" + url.QueryEscape(msg.listID),
+		Description:  fmt.Sprintf("Newsletter %s sent to %s", msg.listID, primary.EmailAddress),
+		Category:     primary.Category,
+		Type:         "email",
+		EmailAddress: primary.EmailAddress,
+	}
+	id, err := mr.fetcher.db.AddFeedForUser(primary.UserID, feed)
+	if err != nil {
+		return nil, err
+	}
+	if err := mr.fetcher.db.UpdateFeedEmailList(id, msg.listID, ""); err != nil {
+		return nil, err
+	}
+	return mr.fetcher.db.GetFeedByID(id)
+}
+
+// newsletterMessage is a received message converted to a feed item
+type newsletterMessage struct {
+	item        *gofeed.Item
+	listID      string // List-Id without the phrase, e.g. "weekly.example.com"
+	listName    string // Phrase of the List-Id, e.g. "Weekly digest"
+	unsubscribe string // First http(s) link of List-Unsubscribe, else the mailto link
+}
+
+// parseNewsletter converts a message to a feed item
+func parseNewsletter(data []byte) (*newsletterMessage, error) {
+	m, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	item := &gofeed.Item{Title: strings.TrimSpace(source.DecodeHeader(m.Header.Get("Subject")))}
+
+	// Message-Id identifies the article, or the content when there's none
+	messageID := strings.Trim(strings.TrimSpace(m.Header.Get("Message-Id")), "<>")
+	if messageID == "" {
+		sum := sha256.Sum256(data)
+		messageID = hex.EncodeToString(sum[:16])
+	}
+	item.Link = "email://" + messageID
+	item.GUID = "email-" + messageID
+
+	published := time.Now()
+	if date, err := m.Header.Date(); err == nil {
+		published = date
+	}
+	item.Published = published.Format(time.RFC1123)
+	item.PublishedParsed = &published
+
+	if from, err := m.Header.AddressList("From"); err == nil && len(from) > 0 {
+		item.Author = &gofeed.Person{Name: from[0].Name, Email: from[0].Address}
+		if item.Title == "" && from[0].Name != "" {
+			item.Title = fmt.Sprintf("Email from %s", from[0].Name)
+		} else if item.Title == "" {
+			item.Title = fmt.Sprintf("Email from %s", from[0].Address)
+		}
+	}
+
+	body, err := source.MessageBody(m.Header.Get("Content-Type"), m.Header.Get("Content-Transfer-Encoding"), m.Body)
+	if err != nil || strings.TrimSpace(body) == "" {
+		body = "(No content available)"
+	}
+	item.Description = cleanEmailContent(body)
+
+	msg := &newsletterMessage{item: item, unsubscribe: parseListUnsubscribe(m.Header.Get("List-Unsubscribe"))}
+	msg.listID, msg.listName = source.ParseListID(m.Header.Get("List-Id"))
+	return msg, nil
+}
+
+// parseListUnsubscribe returns the best link of a List-Unsubscribe header (RFC 2369),
+// e.g. `<mailto:leave@example.com>, <https://example.com/unsubscribe>`
+func parseListUnsubscribe(value string) string {
+	var mailto string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if !strings.HasPrefix(field, "<") || !strings.HasSuffix(field, ">") {
+			continue
+		}
+		link := strings.TrimSpace(field[1 : len(field)-1])
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		switch strings.ToLower(u.Scheme) {
+		case "https", "http":
+			return link
+		case "mailto":
+			if mailto == "" {
+				mailto = link
+			}
+		}
+	}
+	return mailto
+}
+
+// IsReceivedEmailFeed reports whether a feed is delivered by the mail receiver rather than polled
+func IsReceivedEmailFeed(feed models.Feed) bool {
+	return feed.Type == "email" && feed.EmailIMAPServer == ""
+}
+
+// EnableMailReceiver starts receiving newsletters for addresses of the domain, over
+// SMTP on smtpAddr and LMTP on lmtpAddr (either may be empty)
+func (f *Fetcher) EnableMailReceiver(domain, smtpAddr, lmtpAddr string) error {
+	if f.mailReceiver != nil {
+		return nil
+	}
+	mr := NewMailReceiver(f, domain)
+	if smtpAddr != "" {
+		if err := mr.Listen(smtpAddr, false); err != nil {
+			return fmt.Errorf("SMTP listener: %w", err)
+		}
+	}
+	if lmtpAddr != "" {
+		if err := mr.Listen(lmtpAddr, true); err != nil {
+			mr.Stop()
+			return fmt.Errorf("LMTP listener: %w", err)
+		}
+	}
+	f.mailReceiver = mr
+	return nil
+}
+
+// GetMailReceiver returns the mail receiver, or nil if it is disabled
+func (f *Fetcher) GetMailReceiver() *MailReceiver {
+	return f.mailReceiver
+}
+
+// AddReceivedEmailSubscription creates a newsletter feed with a new address of the mail
+// receiver, returning the feed ID and the address to subscribe with
+func (f *Fetcher) AddReceivedEmailSubscription(userID int64, title, category string) (int64, string, error) {
+	if f.mailReceiver == nil {
+		return 0, "", ErrMailReceiverDisabled
+	}
+	address, err := f.mailReceiver.NewAddress()
+	if err != nil {
+		return 0, "", err
+	}
+	if title == "" {
+		title = address
+	}
+
+	feed := &models.Feed{
+		UserID:       userID,
+		Title:        title,
+		URL:          "email://" + address,
+		Description:  fmt.Sprintf("Newsletter subscription for %s", address),
+		Category:     category,
+		Type:         "email",
+		EmailAddress: address,
+	}
+	feedID, err := f.db.AddFeedForUser(userID, feed)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to add email subscription: %w", err)
+	}
+	return feedID, address, nil
+}
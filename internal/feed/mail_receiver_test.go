@@ -0,0 +1,129 @@
+package feed
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"MavenRSS/internal/mailserver"
+	"MavenRSS/internal/models"
+)
+
+func TestParseNewsletter(t *testing.T) {
+	data := "From: \"Weekly\" <news@weekly.example>\r\n" +
+		"Subject: =?UTF-8?Q?Issue_=E2=84=961?=\r\n" +
+		"Message-Id: <abc@weekly.example>\r\n" +
+		"Date: Mon, 02 Jan 2006 15:04:05 +0000\r\n" +
+		"List-Id: \"Weekly digest\" <Weekly.Example>\r\n" +
+		"List-Unsubscribe: <mailto:leave@weekly.example>, <https://weekly.example/unsubscribe?u=1>\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/alternative; boundary=b1\r\n" +
+		"\r\n" +
+		"--b1\r\nContent-Type: text/plain; charset=utf-8\r\n\r\nPlain <text>\r\n" +
+		"--b1\r\nContent-Type: text/html; charset=iso-8859-1\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n<p>Caf=E9</p>\r\n" +
+		"--b1--\r\n"
+
+	msg, err := parseNewsletter([]byte(data))
+	if err != nil {
+		t.Fatalf("parseNewsletter: %v", err)
+	}
+	if msg.item.Title != "Issue №1" || msg.item.GUID != "email-abc@weekly.example" || msg.item.Author.Email != "news@weekly.example" {
+		t.Errorf("unexpected item %+v", msg.item)
+	}
+	if msg.item.PublishedParsed == nil || msg.item.PublishedParsed.Year() != 2006 {
+		t.Errorf("published = %v", msg.item.PublishedParsed)
+	}
+	if msg.item.Description != "<p>Café</p>" {
+		t.Errorf("description = %q, want the decoded HTML part", msg.item.Description)
+	}
+	if msg.listID != "weekly.example" || msg.listName != "Weekly digest" {
+		t.Errorf("list = %q %q", msg.listID, msg.listName)
+	}
+	if msg.unsubscribe != "https://weekly.example/unsubscribe?u=1" {
+		t.Errorf("unsubscribe = %q", msg.unsubscribe)
+	}
+
+	// Plain text messages are escaped
+	msg, err = parseNewsletter([]byte("Subject: plain\r\n\r\na < b\r\nc\r\n"))
+	if err != nil || msg.item.Description != "<p>a &lt; b<br>\nc<br>\n</p>" {
+		t.Errorf("plain description = %q (%v)", msg.item.Description, err)
+	}
+}
+
+func TestMailReceiver_Deliver(t *testing.T) {
+	fetcher, db := newFailuresTestFetcher(t)
+	fetcher.mailReceiver = NewMailReceiver(fetcher, "rss.example")
+
+	feedID, address, err := fetcher.AddReceivedEmailSubscription(1, "Newsletters", "News")
+	if err != nil {
+		t.Fatalf("AddReceivedEmailSubscription: %v", err)
+	}
+	if !strings.HasPrefix(address, "feed-") || !strings.HasSuffix(address, "@rss.example") {
+		t.Fatalf("unexpected address %q", address)
+	}
+
+	mr := fetcher.mailReceiver
+	if err := mr.CheckRecipient(strings.ToUpper(address)); err != nil {
+		t.Errorf("CheckRecipient(%s): %v", address, err)
+	}
+	if err := mr.CheckRecipient("feed-0000@rss.example"); err != mailserver.ErrUnknownRecipient {
+		t.Errorf("expected unknown recipient, got %v", err)
+	}
+	if err := mr.CheckRecipient(strings.Replace(address, "rss.example", "other.example", 1)); err != mailserver.ErrRelayDenied {
+		t.Errorf("expected relay denied, got %v", err)
+	}
+
+	send := func(id, list string) {
+		t.Helper()
+		data := "From: news@example.com\r\nSubject: " + id + "\r\nMessage-Id: <" + id + "@example.com>\r\n"
+		if list != "" {
+			data += "List-Id: " + list + "\r\nList-Unsubscribe: <https://example.com/unsubscribe/" + id + ">\r\n"
+		}
+		data += "Content-Type: text/html\r\n\r\n<p>" + id + "</p>\r\n"
+		if err := mr.Deliver(context.Background(), "news@example.com", address, []byte(data)); err != nil {
+			t.Fatalf("Deliver(%s): %v", id, err)
+		}
+	}
+
+	// The first list is taken by the feed of the address, the others get their own feed
+	send("one", "First <first.example.com>")
+	send("two", "Second <second.example.com>")
+	send("three", "First <first.example.com>")
+	send("four", "")
+
+	ids, err := db.GetReceivedEmailFeedIDs(address)
+	if err != nil || len(ids) != 2 || ids[0] != feedID {
+		t.Fatalf("expected the feed and a sibling, got %v (%v)", ids, err)
+	}
+	primary, _ := db.GetFeedByID(ids[0])
+	sibling, _ := db.GetFeedByID(ids[1])
+	if primary.EmailListID != "first.example.com" || primary.EmailUnsubscribe != "https://example.com/unsubscribe/three" {
+		t.Errorf("unexpected primary feed list %q %q", primary.EmailListID, primary.EmailUnsubscribe)
+	}
+	if sibling.EmailListID != "second.example.com" || sibling.Title != "Second" || sibling.Category != "News" {
+		t.Errorf("unexpected sibling feed %+v", sibling)
+	}
+
+	titles := func(feedID int64) []string {
+		articles, err := db.GetArticles("", feedID, "", false, 10, 0)
+		if err != nil {
+			t.Fatalf("GetArticles: %v", err)
+		}
+		var titles []string
+		for _, a := range articles {
+			titles = append(titles, a.Title)
+		}
+		return titles
+	}
+	if got := titles(primary.ID); len(got) != 3 {
+		t.Errorf("primary feed articles = %v", got)
+	}
+	if got := titles(sibling.ID); len(got) != 1 || got[0] != "two" {
+		t.Errorf("sibling feed articles = %v", got)
+	}
+
+	// Received newsletters aren't polled
+	if got := fetcher.FilterPushCoveredFeeds([]models.Feed{*primary}); len(got) != 0 {
+		t.Error("received newsletter feed must not be polled")
+	}
+}
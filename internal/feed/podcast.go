@@ -0,0 +1,393 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/utils"
+	"MavenRSS/internal/utils/textutil"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+const (
+	// maxPodcastFetchesPerTask limits how many transcripts and chapter documents are
+	// fetched per post-processing task; remaining episodes are fetched on later refreshes
+	maxPodcastFetchesPerTask = 5
+	// maxPodcastResourceSize limits the size of fetched transcripts and chapters
+	maxPodcastResourceSize = 10 << 20
+)
+
+// transcriptTypePreference orders transcript formats from most to least useful.
+// Timed formats come first as they are the most common and carry no markup.
+var transcriptTypePreference = []string{
+	"text/vtt",
+	"application/x-subrip",
+	"application/srt",
+	"application/json",
+	"text/plain",
+	"text/html",
+}
+
+var transcriptTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// extractPodcastEpisode extracts podcast metadata from a feed item.
+// Returns nil when the item has no audio enclosure.
+func extractPodcastEpisode(item *gofeed.Item, feedURL string) *models.PodcastEpisode {
+	var enclosure *gofeed.Enclosure
+	for _, enc := range item.Enclosures {
+		if strings.HasPrefix(enc.Type, "audio/") {
+			enclosure = enc
+			break
+		}
+	}
+	if enclosure == nil || enclosure.URL == "" {
+		return nil
+	}
+
+	ep := &models.PodcastEpisode{
+		AudioURL: enclosure.URL,
+		MimeType: enclosure.Type,
+	}
+	if size, err := strconv.ParseInt(strings.TrimSpace(enclosure.Length), 10, 64); err == nil && size > 0 {
+		ep.SizeBytes = size
+	}
+
+	if it := item.ITunesExt; it != nil {
+		ep.DurationSeconds = parseDuration(it.Duration)
+		ep.Season, _ = strconv.Atoi(strings.TrimSpace(it.Season))
+		ep.Episode, _ = strconv.Atoi(strings.TrimSpace(it.Episode))
+		ep.EpisodeType = strings.ToLower(strings.TrimSpace(it.EpisodeType))
+		switch strings.ToLower(strings.TrimSpace(it.Explicit)) {
+		case "yes", "true", "explicit":
+			ep.Explicit = true
+		}
+		if it.Image != "" {
+			ep.ImageURL = resolveRelativeURL(it.Image, feedURL)
+		}
+	}
+	if ep.ImageURL == "" && item.Image != nil {
+		ep.ImageURL = resolveRelativeURL(item.Image.URL, feedURL)
+	}
+
+	if podcast, ok := item.Extensions["podcast"]; ok {
+		if chapters := podcast["chapters"]; len(chapters) > 0 {
+			ep.ChaptersURL = resolveRelativeURL(chapters[0].Attrs["url"], feedURL)
+		}
+		if transcript := preferredTranscript(podcast["transcript"]); transcript != nil {
+			ep.TranscriptURL = resolveRelativeURL(transcript.Attrs["url"], feedURL)
+			ep.TranscriptType = transcript.Attrs["type"]
+		}
+	}
+
+	// Podlove Simple Chapters are embedded in the feed
+	if psc, ok := item.Extensions["psc"]; ok {
+		for _, list := range psc["chapters"] {
+			for _, c := range list.Children["chapter"] {
+				ep.Chapters = append(ep.Chapters, models.PodcastChapter{
+					StartTime: parseTimestamp(c.Attrs["start"]),
+					Title:     c.Attrs["title"],
+					URL:       c.Attrs["href"],
+					ImageURL:  c.Attrs["image"],
+				})
+			}
+		}
+	}
+
+	return ep
+}
+
+// preferredTranscript returns the podcast:transcript element in the most useful format
+func preferredTranscript(transcripts []ext.Extension) *ext.Extension {
+	var best *ext.Extension
+	bestRank := len(transcriptTypePreference) + 1
+	for i := range transcripts {
+		t := &transcripts[i]
+		if t.Attrs["url"] == "" {
+			continue
+		}
+		rank := len(transcriptTypePreference)
+		for j, mimeType := range transcriptTypePreference {
+			if strings.EqualFold(t.Attrs["type"], mimeType) {
+				rank = j
+				break
+			}
+		}
+		if rank < bestRank {
+			best, bestRank = t, rank
+		}
+	}
+	return best
+}
+
+// parseDuration parses an itunes:duration value: seconds, MM:SS or HH:MM:SS
+func parseDuration(value string) int {
+	return int(parseTimestamp(value))
+}
+
+// parseTimestamp parses a time offset in seconds: "90", "90.5", "01:30" or "00:01:30.500"
+func parseTimestamp(value string) float64 {
+	value = strings.TrimSpace(strings.ReplaceAll(value, ",", "."))
+	if value == "" {
+		return 0
+	}
+	var seconds float64
+	for _, part := range strings.Split(value, ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds
+}
+
+// ParseTranscript converts a transcript in SRT, WebVTT, Podcasting 2.0 JSON, HTML or
+// plain text to plain text. Cue timings and numbering are dropped and consecutive
+// cues are joined into paragraphs, starting a new one when the speaker changes.
+func ParseTranscript(data []byte, mimeType string) string {
+	text := strings.TrimPrefix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\ufeff")
+	trimmed := strings.TrimSpace(text)
+	mimeType = strings.ToLower(mimeType)
+
+	switch {
+	case strings.Contains(mimeType, "json") || strings.HasPrefix(trimmed, "{"):
+		if parsed, ok := parseJSONTranscript([]byte(trimmed)); ok {
+			return parsed
+		}
+	case strings.Contains(mimeType, "vtt") || strings.HasPrefix(trimmed, "WEBVTT"):
+		return parseCueTranscript(trimmed, true)
+	case strings.Contains(mimeType, "srt") || strings.Contains(mimeType, "subrip") || strings.Contains(trimmed, "-->"):
+		return parseCueTranscript(trimmed, false)
+	case strings.Contains(mimeType, "html"):
+		return strings.TrimSpace(textutil.StripHTML(trimmed))
+	}
+	return trimmed
+}
+
+// parseCueTranscript extracts the text of SRT or WebVTT cues
+func parseCueTranscript(text string, vtt bool) string {
+	var paragraphs []string
+	var current []string
+	var speaker, last string
+
+	flush := func() {
+		if len(current) > 0 {
+			paragraphs = append(paragraphs, strings.Join(current, " "))
+			current = nil
+		}
+	}
+
+	for i, block := range strings.Split(text, "\n\n") {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		if vtt && (i == 0 || strings.HasPrefix(lines[0], "NOTE") || lines[0] == "STYLE" || lines[0] == "REGION") {
+			// Header and non-cue blocks
+			continue
+		}
+
+		// Skip the cue number or identifier and the timing line
+		start := 0
+		for start < len(lines) && !strings.Contains(lines[start], "-->") {
+			start++
+		}
+		if start == len(lines) {
+			continue
+		}
+
+		for _, line := range lines[start+1:] {
+			if vtt && strings.HasPrefix(line, "<v ") {
+				if end := strings.Index(line, ">"); end > 0 {
+					if name := strings.TrimSpace(line[3:end]); name != speaker {
+						flush()
+						speaker = name
+						line = name + ": " + line[end+1:]
+					}
+				}
+			}
+			line = strings.TrimSpace(transcriptTagRegex.ReplaceAllString(line, ""))
+			// Automatic captions repeat lines across cues
+			if line == "" || line == last {
+				continue
+			}
+			last = line
+			current = append(current, line)
+		}
+	}
+	flush()
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// parseJSONTranscript extracts the text of a Podcasting 2.0 JSON transcript
+func parseJSONTranscript(data []byte) (string, bool) {
+	var doc struct {
+		Segments []struct {
+			Speaker string `json:"speaker"`
+			Body    string `json:"body"`
+		} `json:"segments"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil || len(doc.Segments) == 0 {
+		return "", false
+	}
+
+	var paragraphs []string
+	var current []string
+	speaker := ""
+	for _, seg := range doc.Segments {
+		body := strings.TrimSpace(seg.Body)
+		if body == "" {
+			continue
+		}
+		if seg.Speaker != "" && seg.Speaker != speaker {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, strings.Join(current, " "))
+			}
+			speaker = seg.Speaker
+			current = []string{speaker + ":"}
+		}
+		current = append(current, body)
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, strings.Join(current, " "))
+	}
+	return strings.Join(paragraphs, "\n\n"), true
+}
+
+// parseChaptersJSON parses a Podcasting 2.0 JSON chapters document
+func parseChaptersJSON(data []byte) ([]models.PodcastChapter, error) {
+	var doc struct {
+		Chapters []struct {
+			StartTime float64 `json:"startTime"`
+			Title     string  `json:"title"`
+			URL       string  `json:"url"`
+			Img       string  `json:"img"`
+			TOC       *bool   `json:"toc"`
+		} `json:"chapters"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	chapters := []models.PodcastChapter{}
+	for _, c := range doc.Chapters {
+		// Chapters excluded from the table of contents only carry artwork or links
+		if c.TOC != nil && !*c.TOC {
+			continue
+		}
+		chapters = append(chapters, models.PodcastChapter{StartTime: c.StartTime, Title: c.Title, URL: c.URL, ImageURL: c.Img})
+	}
+	return chapters, nil
+}
+
+// savePodcastEpisodes stores the podcast metadata of saved articles and fetches the
+// transcripts and chapters of new episodes.
+func (f *Fetcher) savePodcastEpisodes(articlesWithContent []*ArticleWithContent) {
+	fetches := 0
+	for _, awc := range articlesWithContent {
+		ep := awc.Article.PodcastEpisode
+		if ep == nil {
+			continue
+		}
+
+		articleID, err := f.findArticleID(awc.Article)
+		if err != nil {
+			utils.DebugLog("Could not find article ID for podcast episode %s: %v", awc.Article.Title, err)
+			continue
+		}
+		ep.ArticleID = articleID
+		if err := f.db.SavePodcastEpisode(ep); err != nil {
+			log.Printf("Error saving podcast episode for article %d: %v", articleID, err)
+			continue
+		}
+
+		if fetches >= maxPodcastFetchesPerTask || (ep.TranscriptURL == "" && ep.ChaptersURL == "") {
+			continue
+		}
+		saved, err := f.db.GetPodcastEpisode(articleID)
+		if err != nil || saved == nil || saved.FetchedAt != nil {
+			continue
+		}
+		fetches++
+		f.fetchPodcastResources(saved)
+	}
+}
+
+// fetchPodcastResources downloads the transcript and chapters of an episode.
+// The episode is marked as fetched even when downloads fail, so broken links
+// are not retried on every refresh.
+func (f *Fetcher) fetchPodcastResources(ep *models.PodcastEpisode) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client := f.getPodcastHTTPClient(ep.ArticleID)
+
+	transcript := ep.Transcript
+	if ep.TranscriptURL != "" {
+		data, contentType, err := fetchPodcastResource(ctx, client, ep.TranscriptURL)
+		if err != nil {
+			log.Printf("Error fetching transcript for article %d: %v", ep.ArticleID, err)
+		} else {
+			mimeType := ep.TranscriptType
+			if mimeType == "" {
+				mimeType = contentType
+			}
+			transcript = ParseTranscript(data, mimeType)
+		}
+	}
+
+	var chapters []models.PodcastChapter
+	if ep.ChaptersURL != "" {
+		data, _, err := fetchPodcastResource(ctx, client, ep.ChaptersURL)
+		if err == nil {
+			chapters, err = parseChaptersJSON(data)
+		}
+		if err != nil {
+			log.Printf("Error fetching chapters for article %d: %v", ep.ArticleID, err)
+		}
+	}
+
+	if err := f.db.SetPodcastEpisodeResources(ep.ArticleID, transcript, chapters); err != nil {
+		log.Printf("Error saving transcript for article %d: %v", ep.ArticleID, err)
+	}
+}
+
+// getPodcastHTTPClient returns the HTTP client of the feed an episode belongs to
+func (f *Fetcher) getPodcastHTTPClient(articleID int64) *http.Client {
+	var feed models.Feed
+	if article, err := f.db.GetArticleByID(articleID); err == nil && article != nil {
+		if fd, err := f.db.GetFeedByID(article.FeedID); err == nil && fd != nil {
+			feed = *fd
+		}
+	}
+	client, _ := f.getHTTPClient(feed)
+	return client
+}
+
+// fetchPodcastResource downloads a transcript or chapters document
+func fetchPodcastResource(ctx context.Context, client *http.Client, url string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPodcastResourceSize))
+	return data, resp.Header.Get("Content-Type"), err
+}
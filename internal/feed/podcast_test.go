@@ -0,0 +1,122 @@
+package feed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestExtractPodcastEpisode(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"
+  xmlns:podcast="https://podcastindex.org/namespace/1.0" xmlns:psc="http://podlove.org/simple-chapters">
+<channel>
+  <title>Show</title>
+  <item>
+    <title>Episode 3</title>
+    <link>https://example.com/3</link>
+    <enclosure url="https://cdn.example.com/3.mp3" type="audio/mpeg" length="31337"/>
+    <itunes:duration>01:02:03</itunes:duration>
+    <itunes:season>2</itunes:season>
+    <itunes:episode>3</itunes:episode>
+    <itunes:episodeType>full</itunes:episodeType>
+    <itunes:explicit>yes</itunes:explicit>
+    <itunes:image href="/art/3.jpg"/>
+    <podcast:chapters url="https://example.com/3/chapters.json" type="application/json+chapters"/>
+    <podcast:transcript url="https://example.com/3.html" type="text/html"/>
+    <podcast:transcript url="https://example.com/3.srt" type="application/srt"/>
+    <psc:chapters version="1.2">
+      <psc:chapter start="00:00:00" title="Intro"/>
+      <psc:chapter start="00:05:30.5" title="News" href="https://example.com/news"/>
+    </psc:chapters>
+  </item>
+  <item>
+    <title>Blog post</title>
+    <enclosure url="https://example.com/image.jpg" type="image/jpeg"/>
+  </item>
+</channel>
+</rss>`
+
+	parsed, err := gofeed.NewParser().ParseString(data)
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+
+	ep := extractPodcastEpisode(parsed.Items[0], "https://example.com/feed.xml")
+	if ep == nil {
+		t.Fatal("expected an episode")
+	}
+	if ep.AudioURL != "https://cdn.example.com/3.mp3" || ep.MimeType != "audio/mpeg" || ep.SizeBytes != 31337 {
+		t.Errorf("unexpected enclosure: %+v", ep)
+	}
+	if ep.DurationSeconds != 3723 || ep.Season != 2 || ep.Episode != 3 || ep.EpisodeType != "full" || !ep.Explicit {
+		t.Errorf("unexpected itunes metadata: %+v", ep)
+	}
+	if ep.ImageURL != "https://example.com/art/3.jpg" {
+		t.Errorf("unexpected artwork: %q", ep.ImageURL)
+	}
+	if ep.ChaptersURL != "https://example.com/3/chapters.json" {
+		t.Errorf("unexpected chapters URL: %q", ep.ChaptersURL)
+	}
+	if ep.TranscriptURL != "https://example.com/3.srt" || ep.TranscriptType != "application/srt" {
+		t.Errorf("expected the SRT transcript to be preferred, got %q (%s)", ep.TranscriptURL, ep.TranscriptType)
+	}
+	if len(ep.Chapters) != 2 || ep.Chapters[1].StartTime != 330.5 || ep.Chapters[1].URL != "https://example.com/news" {
+		t.Errorf("unexpected inline chapters: %+v", ep.Chapters)
+	}
+
+	if ep := extractPodcastEpisode(parsed.Items[1], ""); ep != nil {
+		t.Errorf("expected no episode without an audio enclosure, got %+v", ep)
+	}
+}
+
+func TestParseTranscript(t *testing.T) {
+	srt := "1\r\n00:00:00,000 --> 00:00:02,000\r\nHello <i>and</i> welcome.\r\n\r\n2\r\n00:00:02,000 --> 00:00:04,000\r\nHello and welcome.\r\nToday: feeds.\r\n"
+	if got := ParseTranscript([]byte(srt), "application/x-subrip"); got != "Hello and welcome. Today: feeds." {
+		t.Errorf("SRT: got %q", got)
+	}
+
+	vtt := `WEBVTT
+Kind: captions
+
+NOTE this is ignored
+
+intro
+00:00.000 --> 00:02.000
+<v Alice>Hi Bob.
+
+00:02.000 --> 00:04.000
+<v Bob>Hi Alice.
+
+00:04.000 --> 00:06.000 align:start
+<v Bob>How are you?`
+	if got := ParseTranscript([]byte(vtt), ""); got != "Alice: Hi Bob.\n\nBob: Hi Alice. How are you?" {
+		t.Errorf("VTT: got %q", got)
+	}
+
+	jsonTranscript := `{"version": "1.0.0", "segments": [
+		{"speaker": "Alice", "startTime": 0, "endTime": 1, "body": "Welcome"},
+		{"speaker": "Alice", "startTime": 1, "endTime": 2, "body": "back."},
+		{"speaker": "Bob", "startTime": 2, "endTime": 3, "body": "Thanks!"}]}`
+	if got := ParseTranscript([]byte(jsonTranscript), "application/json"); got != "Alice: Welcome back.\n\nBob: Thanks!" {
+		t.Errorf("JSON: got %q", got)
+	}
+
+	if got := ParseTranscript([]byte("<p>Plain <b>HTML</b></p>"), "text/html"); !strings.Contains(got, "Plain HTML") {
+		t.Errorf("HTML: got %q", got)
+	}
+}
+
+func TestParseChaptersJSON(t *testing.T) {
+	chapters, err := parseChaptersJSON([]byte(`{"version": "1.2.0", "chapters": [
+		{"startTime": 0, "title": "Intro"},
+		{"startTime": 60, "img": "https://example.com/art.jpg", "toc": false},
+		{"startTime": 125.5, "title": "Main topic", "url": "https://example.com/topic"}]}`))
+	if err != nil {
+		t.Fatalf("parseChaptersJSON: %v", err)
+	}
+	if len(chapters) != 2 || chapters[1].Title != "Main topic" || chapters[1].StartTime != 125.5 {
+		t.Errorf("unexpected chapters: %+v", chapters)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/mail"
 	"runtime"
 	"strings"
 	"time"
@@ -195,14 +196,23 @@ func (e *EmailSource) fetchEmailBatch(c *client.Client, uids []uint32) ([]*gofee
 	return items, nil
 }
 
-// parseEmailToItem converts an IMAP message to a gofeed Item.
+// parseEmailToItem converts an IMAP message to a gofeed Item. Items are identified by
+// their Message-Id, else by their UID.
 func (e *EmailSource) parseEmailToItem(msg *imap.Message) *gofeed.Item {
+	link, guid := fmt.Sprintf("email://%d", msg.Uid), fmt.Sprintf("email-%d", msg.Uid)
+	if messageID := strings.Trim(strings.TrimSpace(msg.Envelope.MessageId), "<>"); messageID != "" {
+		link, guid = "email://"+messageID, "email-"+messageID
+	}
 	item := &gofeed.Item{
 		Title:     msg.Envelope.Subject,
-		Link:      fmt.Sprintf("email://%d", msg.Uid),
-		GUID:      fmt.Sprintf("email-%d", msg.Uid),
+		Link:      link,
+		GUID:      guid,
 		Published: msg.Envelope.Date.Format(time.RFC1123),
 	}
+	if !msg.Envelope.Date.IsZero() {
+		published := msg.Envelope.Date
+		item.PublishedParsed = &published
+	}
 
 	// Set author from sender
 	if len(msg.Envelope.From) > 0 {
@@ -229,9 +239,21 @@ func (e *EmailSource) parseEmailToItem(msg *imap.Message) *gofeed.Item {
 	return item
 }
 
-// extractEmailBody extracts text/HTML content from the message.
+// extractEmailBody extracts text/HTML content from the message. Entire messages
+// (BODY[]) are decoded, other sections are used as is.
 func (e *EmailSource) extractEmailBody(msg *imap.Message) string {
-	for _, r := range msg.Body {
+	for section, r := range msg.Body {
+		if section != nil && section.Specifier == imap.EntireSpecifier && len(section.Path) == 0 {
+			m, err := mail.ReadMessage(r)
+			if err != nil {
+				continue
+			}
+			body, err := MessageBody(m.Header.Get("Content-Type"), m.Header.Get("Content-Transfer-Encoding"), m.Body)
+			if err == nil && strings.TrimSpace(body) != "" {
+				return e.cleanEmailContent(body)
+			}
+			continue
+		}
 		data, err := io.ReadAll(r)
 		if err != nil {
 			continue
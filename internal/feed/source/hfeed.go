@@ -0,0 +1,370 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"MavenRSS/internal/utils/httputil"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	maxHFeedRetries = 2
+	// Length of titles implied from the content of untitled entries (notes)
+	impliedTitleLength = 80
+)
+
+// HFeedSource turns HTML pages marked up with microformats2 h-feed/h-entry
+// (https://microformats.org/wiki/h-feed) into feeds, without XPath configuration.
+type HFeedSource struct {
+	client *http.Client
+}
+
+// NewHFeedSource creates a new h-feed source.
+func NewHFeedSource() *HFeedSource {
+	return NewHFeedSourceWithProxy("")
+}
+
+// NewHFeedSourceWithProxy creates a new h-feed source with custom proxy.
+func NewHFeedSourceWithProxy(proxyURL string) *HFeedSource {
+	userAgent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	return &HFeedSource{
+		client: httputil.GetPooledUserAgentClient(proxyURL, 20*time.Second, userAgent),
+	}
+}
+
+// Type returns the source type identifier.
+func (s *HFeedSource) Type() Type {
+	return TypeHFeed
+}
+
+// Validate checks if the configuration is valid for h-feed source.
+func (s *HFeedSource) Validate(config *Config) error {
+	if config == nil {
+		return errors.New("config is nil")
+	}
+	if config.URL == "" {
+		return errors.New("URL is required for h-feed source")
+	}
+	return nil
+}
+
+// SetHTTPClient allows setting a custom HTTP client.
+func (s *HFeedSource) SetHTTPClient(client *http.Client) {
+	if client != nil {
+		s.client = client
+	}
+}
+
+// Fetch retrieves the page and extracts its h-entry items with retry support.
+func (s *HFeedSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed, error) {
+	if err := s.Validate(config); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxHFeedRetries; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		data, err := fetchDocument(ctx, s.client, config, "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+		if err == nil {
+			doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse HTML: %w", err)
+			}
+			feed := ParseHFeed(doc, config.URL)
+			if len(feed.Items) == 0 {
+				return nil, fmt.Errorf("no h-entry items found on %s", config.URL)
+			}
+			return feed, nil
+		}
+
+		lastErr = err
+		if httputil.IsNetworkError(err.Error()) && attempt < maxHFeedRetries-1 {
+			backoff := httputil.CalculateBackoffSimple(attempt)
+			log.Printf("[HFeedSource] Network error on attempt %d/%d for %s, retrying in %v: %v",
+				attempt+1, maxHFeedRetries, config.URL, backoff, err)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			continue
+		}
+
+		return nil, fmt.Errorf("failed to fetch page: %w", err)
+	}
+
+	return nil, fmt.Errorf("all %d attempts failed, last error: %w", maxHFeedRetries, lastErr)
+}
+
+// HasHFeed reports whether a page contains h-entry markup
+func HasHFeed(doc *goquery.Document) bool {
+	return doc.Find(".h-entry").Length() > 0
+}
+
+// ParseHFeed extracts the first h-feed of a page, or its top-level h-entry
+// elements when there is no explicit h-feed. Relative URLs are resolved
+// against pageURL.
+func ParseHFeed(doc *goquery.Document, pageURL string) *gofeed.Feed {
+	base, _ := url.Parse(pageURL)
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok && base != nil {
+		if u, err := base.Parse(href); err == nil {
+			base = u
+		}
+	}
+
+	feed := &gofeed.Feed{
+		Link:     pageURL,
+		FeedType: "h-feed",
+		Items:    []*gofeed.Item{},
+	}
+
+	root := doc.Find(".h-feed").First()
+	if root.Length() > 0 {
+		feed.Title = mfText(mfProperty(root, "p-name"))
+		feed.Description = mfText(mfProperty(root, "p-summary"))
+		if author := mfAuthor(root); author != nil {
+			feed.Author = author
+			feed.Authors = []*gofeed.Person{author}
+		}
+		if photo := mfURL(mfProperty(root, "u-photo"), base); photo != "" {
+			feed.Image = &gofeed.Image{URL: photo}
+		}
+	} else {
+		root = doc.Selection
+	}
+	if feed.Title == "" {
+		feed.Title = strings.TrimSpace(doc.Find("title").First().Text())
+	}
+
+	root.Find(".h-entry").Each(func(_ int, entry *goquery.Selection) {
+		// Entries nested in other entries are replies, likes or quotes
+		if entry.ParentsFiltered(".h-entry").Length() > 0 {
+			return
+		}
+		if item := parseHEntry(entry, base); item != nil {
+			if item.Author == nil {
+				item.Author = feed.Author
+			}
+			feed.Items = append(feed.Items, item)
+		}
+	})
+
+	return feed
+}
+
+// parseHEntry converts an h-entry to a feed item
+func parseHEntry(entry *goquery.Selection, base *url.URL) *gofeed.Item {
+	item := &gofeed.Item{}
+
+	content := mfProperty(entry, "e-content")
+	if content.Length() > 0 {
+		item.Content, _ = content.Html()
+		item.Content = strings.TrimSpace(item.Content)
+	}
+	item.Description = mfText(mfProperty(entry, "p-summary"))
+
+	item.Title = mfText(mfProperty(entry, "p-name"))
+	contentText := strings.Join(strings.Fields(content.Text()), " ")
+	if item.Title == "" || item.Title == contentText {
+		// Notes have no name, imply one from the start of the content
+		item.Title = truncateRunes(contentText, impliedTitleLength)
+	}
+
+	item.Link = mfURL(mfProperty(entry, "u-url"), base)
+	if item.Link == "" {
+		item.Link = mfURL(entry.Find("a[rel~=bookmark]").First(), base)
+	}
+	item.GUID = mfURL(mfProperty(entry, "u-uid"), base)
+	if item.GUID == "" {
+		item.GUID = item.Link
+	}
+
+	if t := mfTime(mfProperty(entry, "dt-published")); t != nil {
+		item.PublishedParsed = t
+		item.Published = t.Format(time.RFC3339)
+	}
+	if t := mfTime(mfProperty(entry, "dt-updated")); t != nil {
+		item.UpdatedParsed = t
+		item.Updated = t.Format(time.RFC3339)
+		if item.PublishedParsed == nil {
+			item.PublishedParsed = t
+		}
+	}
+
+	if author := mfAuthor(entry); author != nil {
+		item.Author = author
+		item.Authors = []*gofeed.Person{author}
+	}
+
+	photo := mfURL(mfProperty(entry, "u-featured"), base)
+	if photo == "" {
+		photo = mfURL(mfProperty(entry, "u-photo"), base)
+	}
+	if photo != "" {
+		item.Image = &gofeed.Image{URL: photo}
+	}
+
+	mfProperties(entry, "p-category").Each(func(_ int, sel *goquery.Selection) {
+		if category := mfText(sel); category != "" {
+			item.Categories = append(item.Categories, category)
+		}
+	})
+
+	if item.Title == "" && item.Link == "" {
+		return nil
+	}
+	return item
+}
+
+// mfProperties returns the elements with the given property class that
+// belong to root, skipping properties of nested microformats.
+func mfProperties(root *goquery.Selection, class string) *goquery.Selection {
+	rootNode := root.Get(0)
+	return root.Find("." + class).FilterFunction(func(_ int, sel *goquery.Selection) bool {
+		for p := sel.Parent(); p.Length() > 0; p = p.Parent() {
+			if p.Get(0) == rootNode {
+				return true
+			}
+			if hasMicroformatRoot(p) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// mfProperty returns the first element with the given property class of root
+func mfProperty(root *goquery.Selection, class string) *goquery.Selection {
+	return mfProperties(root, class).First()
+}
+
+// hasMicroformatRoot reports whether an element is a microformats2 root (h-*)
+func hasMicroformatRoot(sel *goquery.Selection) bool {
+	for _, class := range strings.Fields(sel.AttrOr("class", "")) {
+		if strings.HasPrefix(class, "h-") {
+			return true
+		}
+	}
+	return false
+}
+
+// mfText returns the value of a p-* property
+func mfText(sel *goquery.Selection) string {
+	if sel.Length() == 0 {
+		return ""
+	}
+	if goquery.NodeName(sel) == "abbr" {
+		if title, ok := sel.Attr("title"); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+	if goquery.NodeName(sel) == "img" || goquery.NodeName(sel) == "area" {
+		return strings.TrimSpace(sel.AttrOr("alt", ""))
+	}
+	return strings.Join(strings.Fields(sel.Text()), " ")
+}
+
+// mfURL returns the absolute value of a u-* property
+func mfURL(sel *goquery.Selection, base *url.URL) string {
+	if sel.Length() == 0 {
+		return ""
+	}
+	var raw string
+	switch goquery.NodeName(sel) {
+	case "a", "area", "link":
+		raw = sel.AttrOr("href", "")
+	case "img", "audio", "video", "source":
+		raw = sel.AttrOr("src", "")
+	case "object":
+		raw = sel.AttrOr("data", "")
+	default:
+		raw = strings.TrimSpace(sel.Text())
+	}
+	if raw == "" {
+		return ""
+	}
+	if base == nil {
+		return raw
+	}
+	u, err := base.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.String()
+}
+
+// mfTime parses a dt-* property
+func mfTime(sel *goquery.Selection) *time.Time {
+	if sel.Length() == 0 {
+		return nil
+	}
+	value := sel.AttrOr("datetime", "")
+	if value == "" {
+		value = sel.AttrOr("title", "")
+	}
+	if value == "" {
+		value = sel.Text()
+	}
+	value = strings.TrimSpace(value)
+
+	formats := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05-0700",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05-07:00",
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04",
+		"2006-01-02",
+	}
+	for _, format := range formats {
+		if t, err := time.Parse(format, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// mfAuthor returns the p-author of root, which may be an h-card or plain text
+func mfAuthor(root *goquery.Selection) *gofeed.Person {
+	author := mfProperty(root, "p-author")
+	if author.Length() == 0 {
+		return nil
+	}
+	name := ""
+	if author.HasClass("h-card") {
+		name = mfText(mfProperty(author, "p-name"))
+	}
+	if name == "" {
+		name = mfText(author)
+	}
+	if name == "" {
+		return nil
+	}
+	return &gofeed.Person{Name: name}
+}
+
+// truncateRunes shortens s to at most n runes, adding an ellipsis when cut
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:n])) + "…"
+}
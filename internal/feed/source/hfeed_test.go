@@ -0,0 +1,126 @@
+package source
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseHFeed(t *testing.T) {
+	page := `<html><head><title>Page title</title></head><body>
+<div class="h-feed">
+  <h1 class="p-name">Jane's notes</h1>
+  <a class="p-author h-card" href="/"><span class="p-name">Jane</span></a>
+  <article class="h-entry">
+    <h2 class="p-name"><a class="u-url" href="/posts/1">First post</a></h2>
+    <time class="dt-published" datetime="2024-03-01T10:00:00Z">March 1</time>
+    <a class="p-category" href="/tags/go">go</a>
+    <div class="e-content"><p>Hello <b>world</b></p></div>
+    <div class="h-cite u-comment">
+      <a class="u-url" href="https://other.example.com/reply">Reply</a>
+      <span class="p-name">A reply</span>
+    </div>
+  </article>
+  <article class="h-entry">
+    <div class="e-content p-name">Just a short note without a title</div>
+    <a class="u-url" href="/notes/2"><time class="dt-published" datetime="2024-03-02">2 March</time></a>
+    <img class="u-photo" src="/img/2.jpg" alt="">
+    <div class="h-entry"><span class="p-name">Nested quote</span></div>
+  </article>
+</div></body></html>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed := ParseHFeed(doc, "https://example.com/blog/")
+
+	if feed.Title != "Jane's notes" || feed.Author == nil || feed.Author.Name != "Jane" {
+		t.Fatalf("unexpected feed metadata: %q %+v", feed.Title, feed.Author)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(feed.Items))
+	}
+
+	first := feed.Items[0]
+	if first.Title != "First post" || first.Link != "https://example.com/posts/1" {
+		t.Errorf("unexpected first entry: %q %q", first.Title, first.Link)
+	}
+	if first.PublishedParsed == nil || first.PublishedParsed.Day() != 1 {
+		t.Errorf("unexpected published date: %v", first.PublishedParsed)
+	}
+	if first.Content != "<p>Hello <b>world</b></p>" || len(first.Categories) != 1 || first.Categories[0] != "go" {
+		t.Errorf("unexpected content or categories: %q %v", first.Content, first.Categories)
+	}
+	if first.Author == nil || first.Author.Name != "Jane" {
+		t.Errorf("expected entry to inherit the feed author, got %+v", first.Author)
+	}
+
+	note := feed.Items[1]
+	if note.Title != "Just a short note without a title" || note.Link != "https://example.com/notes/2" {
+		t.Errorf("unexpected note: %q %q", note.Title, note.Link)
+	}
+	if note.Image == nil || note.Image.URL != "https://example.com/img/2.jpg" {
+		t.Errorf("unexpected note image: %+v", note.Image)
+	}
+}
+
+func TestParseJSONFeed(t *testing.T) {
+	data := []byte(`{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Podcast",
+  "home_page_url": "https://example.com/",
+  "authors": [{"name": "Ann"}, {"name": "Bob"}],
+  "_custom": {"level": 1},
+  "items": [
+    {
+      "id": 42,
+      "url": "https://example.com/ep/42",
+      "title": "Episode 42",
+      "content_text": "Line one\nline two\n\n<Second> paragraph",
+      "date_published": "2024-01-02T03:04:05Z",
+      "tags": ["audio"],
+      "attachments": [{"url": "https://example.com/42.mp3", "mime_type": "audio/mpeg", "size_in_bytes": 1234, "duration_in_seconds": 1800}],
+      "_itunes": {"episode": 42}
+    },
+    {
+      "id": "b",
+      "external_url": "https://elsewhere.example.com/",
+      "content_html": "<p>Linked</p>",
+      "summary": "A link",
+      "authors": [{"name": "Carol"}]
+    }
+  ]
+}`)
+	feed, err := ParseJSONFeed(data)
+	if err != nil {
+		t.Fatalf("ParseJSONFeed: %v", err)
+	}
+	if feed.Title != "Podcast" || feed.Link != "https://example.com/" || len(feed.Authors) != 2 || feed.Custom["_custom"] != `{"level": 1}` {
+		t.Fatalf("unexpected feed: %+v", feed)
+	}
+
+	ep := feed.Items[0]
+	if ep.GUID != "42" || ep.Description != "Line one\nline two\n\n<Second> paragraph" {
+		t.Errorf("unexpected id or description: %q %q", ep.GUID, ep.Description)
+	}
+	if ep.Content != "<p>Line one<br>line two</p><p>&lt;Second&gt; paragraph</p>" {
+		t.Errorf("unexpected content: %q", ep.Content)
+	}
+	if len(ep.Enclosures) != 1 || ep.Enclosures[0].Type != "audio/mpeg" || ep.Enclosures[0].Length != "1234" || ep.ITunesExt == nil || ep.ITunesExt.Duration != "1800" {
+		t.Errorf("unexpected enclosures: %+v", ep.Enclosures)
+	}
+	if ep.Author == nil || ep.Author.Name != "Ann" || ep.Custom["_itunes"] != `{"episode": 42}` {
+		t.Errorf("unexpected author or extensions: %+v %v", ep.Author, ep.Custom)
+	}
+
+	link := feed.Items[1]
+	if link.Link != "https://elsewhere.example.com/" || link.Content != "<p>Linked</p>" || link.Description != "A link" || link.Author.Name != "Carol" {
+		t.Errorf("unexpected second item: %+v", link)
+	}
+
+	if _, err := ParseJSONFeed([]byte(`{"version": "1", "items": []}`)); err == nil {
+		t.Error("expected unknown version to be rejected")
+	}
+}
@@ -0,0 +1,691 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/utils/httputil"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	maxICalendarRetries = 2
+	// defaultCalendarPast and defaultCalendarFuture bound the occurrences of events
+	// turned into items, relative to the time of the refresh
+	defaultCalendarPast   = 30 * 24 * time.Hour
+	defaultCalendarFuture = 180 * 24 * time.Hour
+)
+
+// ICalendarSource fetches iCalendar (RFC 5545) calendars, e.g. .ics or webcal:// URLs,
+// and turns the occurrences of their events within a window into items. Recurring
+// events are expanded (RRULE, RDATE, EXDATE and RECURRENCE-ID overrides). Items keep
+// the same link and GUID when their event changes.
+type ICalendarSource struct {
+	client *http.Client
+}
+
+// NewICalendarSource creates a new iCalendar source.
+func NewICalendarSource() *ICalendarSource {
+	return NewICalendarSourceWithProxy("")
+}
+
+// NewICalendarSourceWithProxy creates a new iCalendar source with custom proxy.
+func NewICalendarSourceWithProxy(proxyURL string) *ICalendarSource {
+	userAgent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	return &ICalendarSource{
+		client: httputil.GetPooledUserAgentClient(proxyURL, 20*time.Second, userAgent),
+	}
+}
+
+// Type returns the source type identifier.
+func (s *ICalendarSource) Type() Type {
+	return TypeICalendar
+}
+
+// Validate checks if the configuration is valid for iCalendar source.
+func (s *ICalendarSource) Validate(config *Config) error {
+	if config == nil {
+		return errors.New("config is nil")
+	}
+	if config.URL == "" {
+		return errors.New("URL is required for iCalendar source")
+	}
+	return nil
+}
+
+// SetHTTPClient allows setting a custom HTTP client.
+func (s *ICalendarSource) SetHTTPClient(client *http.Client) {
+	if client != nil {
+		s.client = client
+	}
+}
+
+// Fetch retrieves the calendar and converts the occurrences of its events to items with retry support.
+func (s *ICalendarSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed, error) {
+	if err := s.Validate(config); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	fetchConfig := *config
+	fetchConfig.URL = ICalendarHTTPURL(config.URL)
+
+	past, future := config.CalendarPast, config.CalendarFuture
+	if past <= 0 {
+		past = defaultCalendarPast
+	}
+	if future <= 0 {
+		future = defaultCalendarFuture
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxICalendarRetries; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		data, err := fetchDocument(ctx, s.client, &fetchConfig, "text/calendar, */*;q=0.5")
+		if err == nil {
+			now := time.Now()
+			feed, err := ParseICalendar(data, fetchConfig.URL, now.Add(-past), now.Add(future))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse calendar from %s: %w", config.URL, err)
+			}
+			return feed, nil
+		}
+
+		lastErr = err
+		if httputil.IsNetworkError(err.Error()) && attempt < maxICalendarRetries-1 {
+			backoff := httputil.CalculateBackoffSimple(attempt)
+			log.Printf("[ICalendarSource] Network error on attempt %d/%d for %s, retrying in %v: %v",
+				attempt+1, maxICalendarRetries, config.URL, backoff, err)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			continue
+		}
+
+		return nil, fmt.Errorf("failed to fetch calendar: %w", err)
+	}
+
+	return nil, fmt.Errorf("all %d attempts failed, last error: %w", maxICalendarRetries, lastErr)
+}
+
+// IsICalendarURL reports whether a URL looks like an iCalendar subscription (webcal:// or .ics)
+func IsICalendarURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Scheme, "webcal") || strings.EqualFold(u.Scheme, "webcals") ||
+		strings.HasSuffix(strings.ToLower(u.Path), ".ics")
+}
+
+// ICalendarHTTPURL returns the http(s) URL of a webcal:// subscription URL
+func ICalendarHTTPURL(rawURL string) string {
+	lower := strings.ToLower(rawURL)
+	switch {
+	case strings.HasPrefix(lower, "webcals://"):
+		return "https://" + rawURL[len("webcals://"):]
+	case strings.HasPrefix(lower, "webcal://"):
+		return "https://" + rawURL[len("webcal://"):]
+	}
+	return rawURL
+}
+
+// IsICalendar reports whether data is an iCalendar object
+func IsICalendar(data []byte) bool {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("\xef\xbb\xbf"))
+	return len(data) >= 15 && strings.EqualFold(string(data[:15]), "BEGIN:VCALENDAR")
+}
+
+// icalProperty is a content line of an iCalendar object
+type icalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// icalComponent is a BEGIN/END block of an iCalendar object, e.g. VCALENDAR or VEVENT
+type icalComponent struct {
+	Name       string
+	Props      []icalProperty
+	Components []*icalComponent
+}
+
+// prop returns the first property with the given name, or nil
+func (c *icalComponent) prop(name string) *icalProperty {
+	for i := range c.Props {
+		if c.Props[i].Name == name {
+			return &c.Props[i]
+		}
+	}
+	return nil
+}
+
+// text returns the unescaped value of the first property with the given name
+func (c *icalComponent) text(name string) string {
+	if p := c.prop(name); p != nil {
+		return strings.TrimSpace(unescapeICalText(p.Value))
+	}
+	return ""
+}
+
+// parseICalComponents parses an iCalendar object into its components
+func parseICalComponents(data []byte) (*icalComponent, error) {
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	// Unfold the lines continued with a leading space or tab
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	root := &icalComponent{}
+	stack := []*icalComponent{root}
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, ok := parseICalContentLine(line)
+		if !ok {
+			continue
+		}
+		top := stack[len(stack)-1]
+		switch prop.Name {
+		case "BEGIN":
+			c := &icalComponent{Name: strings.ToUpper(strings.TrimSpace(prop.Value))}
+			top.Components = append(top.Components, c)
+			stack = append(stack, c)
+		case "END":
+			if len(stack) > 1 && top.Name == strings.ToUpper(strings.TrimSpace(prop.Value)) {
+				stack = stack[:len(stack)-1]
+			}
+		default:
+			top.Props = append(top.Props, prop)
+		}
+	}
+
+	for _, c := range root.Components {
+		if c.Name == "VCALENDAR" {
+			return c, nil
+		}
+	}
+	return nil, errors.New("no VCALENDAR found")
+}
+
+// parseICalContentLine parses a line like `DTSTART;TZID="Europe/Paris":20240301T100000`
+func parseICalContentLine(line string) (icalProperty, bool) {
+	var parts []string
+	start, inQuotes := 0, false
+	valueStart := -1
+	for i := 0; i < len(line) && valueStart < 0; i++ {
+		switch line[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case ';':
+			if !inQuotes {
+				parts = append(parts, line[start:i])
+				start = i + 1
+			}
+		case ':':
+			if !inQuotes {
+				parts = append(parts, line[start:i])
+				valueStart = i + 1
+			}
+		}
+	}
+	if valueStart < 0 || len(parts) == 0 || parts[0] == "" {
+		return icalProperty{}, false
+	}
+
+	prop := icalProperty{Name: strings.ToUpper(parts[0]), Value: line[valueStart:]}
+	for _, param := range parts[1:] {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		if prop.Params == nil {
+			prop.Params = make(map[string]string)
+		}
+		prop.Params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return prop, true
+}
+
+// unescapeICalText unescapes a TEXT value
+func unescapeICalText(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+			switch value[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(value[i])
+			}
+			continue
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// loadICalLocation returns the location of a TZID, or nil if it is unknown. Only IANA
+// names are supported; custom VTIMEZONE definitions are not interpreted.
+func loadICalLocation(tzid string) *time.Location {
+	tzid = strings.TrimPrefix(strings.TrimSpace(tzid), "/")
+	if tzid == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(tzid)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// parseICalTime parses a DATE or DATE-TIME value. Floating times and times of unknown
+// time zones are read in loc.
+func parseICalTime(value string, params map[string]string, loc *time.Location) (t time.Time, allDay bool, err error) {
+	value = strings.TrimSpace(value)
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err = time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	if tzLoc := loadICalLocation(params["TZID"]); tzLoc != nil {
+		loc = tzLoc
+	}
+	t, err = time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// parseICalTimes parses the comma-separated values of EXDATE and RDATE properties
+func parseICalTimes(props []icalProperty, loc *time.Location) []time.Time {
+	var times []time.Time
+	for _, p := range props {
+		if p.Params["VALUE"] == "PERIOD" {
+			continue
+		}
+		for _, value := range strings.Split(p.Value, ",") {
+			if t, _, err := parseICalTime(value, p.Params, loc); err == nil {
+				times = append(times, t)
+			}
+		}
+	}
+	return times
+}
+
+// parseICalDuration parses a DURATION value like P1DT2H30M, P2W or -PT15M
+func parseICalDuration(value string) (time.Duration, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	sign := time.Duration(1)
+	if strings.HasPrefix(value, "-") {
+		sign = -1
+	}
+	value = strings.TrimLeft(value, "+-")
+	if !strings.HasPrefix(value, "P") {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var d time.Duration
+	inTime := false
+	number := ""
+	for _, r := range value[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			number += string(r)
+		case r == 'T':
+			inTime = true
+		default:
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			number = ""
+			switch {
+			case r == 'W':
+				d += time.Duration(n) * 7 * 24 * time.Hour
+			case r == 'D':
+				d += time.Duration(n) * 24 * time.Hour
+			case r == 'H' && inTime:
+				d += time.Duration(n) * time.Hour
+			case r == 'M' && inTime:
+				d += time.Duration(n) * time.Minute
+			case r == 'S' && inTime:
+				d += time.Duration(n) * time.Second
+			default:
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+		}
+	}
+	return sign * d, nil
+}
+
+// icalEvent is a VEVENT
+type icalEvent struct {
+	uid          string
+	summary      string
+	description  string
+	location     string
+	url          string
+	status       string
+	organizer    string
+	categories   []string
+	start        time.Time
+	duration     time.Duration
+	allDay       bool
+	rrule        string
+	rdates       []time.Time
+	exdates      []time.Time
+	recurrenceID time.Time // Zero unless the event overrides an occurrence
+	updated      time.Time
+}
+
+// parseICalEvent converts a VEVENT, returning false if it has no valid start
+func parseICalEvent(c *icalComponent, loc *time.Location) (*icalEvent, bool) {
+	dtstart := c.prop("DTSTART")
+	if dtstart == nil {
+		return nil, false
+	}
+	start, allDay, err := parseICalTime(dtstart.Value, dtstart.Params, loc)
+	if err != nil {
+		return nil, false
+	}
+	e := &icalEvent{
+		uid:         c.text("UID"),
+		summary:     c.text("SUMMARY"),
+		description: c.text("DESCRIPTION"),
+		location:    c.text("LOCATION"),
+		url:         c.text("URL"),
+		status:      strings.ToUpper(c.text("STATUS")),
+		start:       start,
+		allDay:      allDay,
+	}
+	if e.uid == "" {
+		// Events should have a UID, identify the others by their start and summary
+		sum := sha256.Sum256([]byte(dtstart.Value + "|" + e.summary))
+		e.uid = hex.EncodeToString(sum[:12])
+	}
+
+	if end := c.prop("DTEND"); end != nil {
+		if t, _, err := parseICalTime(end.Value, end.Params, loc); err == nil && t.After(start) {
+			e.duration = t.Sub(start)
+		}
+	} else if d := c.prop("DURATION"); d != nil {
+		if duration, err := parseICalDuration(d.Value); err == nil && duration > 0 {
+			e.duration = duration
+		}
+	} else if allDay {
+		e.duration = 24 * time.Hour
+	}
+
+	if organizer := c.prop("ORGANIZER"); organizer != nil {
+		e.organizer = firstNonEmpty(organizer.Params["CN"], strings.TrimPrefix(strings.TrimPrefix(organizer.Value, "mailto:"), "MAILTO:"))
+	}
+	for _, p := range c.Props {
+		switch p.Name {
+		case "CATEGORIES":
+			for _, category := range strings.Split(p.Value, ",") {
+				if category = strings.TrimSpace(unescapeICalText(category)); category != "" {
+					e.categories = append(e.categories, category)
+				}
+			}
+		case "RRULE":
+			e.rrule = p.Value
+		case "RECURRENCE-ID":
+			if t, _, err := parseICalTime(p.Value, p.Params, loc); err == nil {
+				e.recurrenceID = t
+			}
+		}
+	}
+	var rdates, exdates []icalProperty
+	for _, p := range c.Props {
+		switch p.Name {
+		case "RDATE":
+			rdates = append(rdates, p)
+		case "EXDATE":
+			exdates = append(exdates, p)
+		}
+	}
+	e.rdates = parseICalTimes(rdates, start.Location())
+	e.exdates = parseICalTimes(exdates, start.Location())
+
+	for _, name := range []string{"LAST-MODIFIED", "DTSTAMP"} {
+		if p := c.prop(name); p != nil {
+			if t, _, err := parseICalTime(p.Value, p.Params, time.UTC); err == nil {
+				e.updated = t
+				break
+			}
+		}
+	}
+	return e, true
+}
+
+// occurrenceKey identifies an occurrence of a recurring event by its original start
+func occurrenceKey(t time.Time, allDay bool) string {
+	if allDay {
+		return t.Format("20060102")
+	}
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// ParseICalendar converts the occurrences of the events of a calendar that start
+// between from and to into feed items, sorted by start. Occurrences of recurring
+// events get the GUID "<UID>/<original start>", single events their UID.
+func ParseICalendar(data []byte, calendarURL string, from, to time.Time) (*gofeed.Feed, error) {
+	cal, err := parseICalComponents(data)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := loadICalLocation(cal.text("X-WR-TIMEZONE"))
+	if loc == nil {
+		loc = time.UTC
+	}
+	feed := &gofeed.Feed{
+		Title:       firstNonEmpty(cal.text("X-WR-CALNAME"), cal.text("NAME"), calendarURL),
+		Description: firstNonEmpty(cal.text("X-WR-CALDESC"), cal.text("DESCRIPTION")),
+		Link:        calendarURL,
+		Items:       []*gofeed.Item{},
+	}
+
+	var masters []*icalEvent
+	overrides := make(map[string]map[string]*icalEvent)
+	for _, c := range cal.Components {
+		if c.Name != "VEVENT" {
+			continue
+		}
+		e, ok := parseICalEvent(c, loc)
+		if !ok {
+			continue
+		}
+		if e.recurrenceID.IsZero() {
+			masters = append(masters, e)
+			continue
+		}
+		if overrides[e.uid] == nil {
+			overrides[e.uid] = make(map[string]*icalEvent)
+		}
+		overrides[e.uid][occurrenceKey(e.recurrenceID, e.allDay)] = e
+	}
+
+	inWindow := func(t time.Time) bool {
+		return !t.Before(from) && !t.After(to)
+	}
+	for _, master := range masters {
+		if master.rrule == "" && len(master.rdates) == 0 {
+			if inWindow(master.start) {
+				feed.Items = append(feed.Items, eventItem(master, master.start, "", calendarURL))
+			}
+			continue
+		}
+
+		for _, start := range eventOccurrences(master, from, to) {
+			key := occurrenceKey(start, master.allDay)
+			occurrence := master
+			if override, ok := overrides[master.uid][key]; ok {
+				occurrence = override
+				delete(overrides[master.uid], key)
+			}
+			feed.Items = append(feed.Items, eventItem(occurrence, start, key, calendarURL))
+		}
+	}
+	// Overrides moved into the window from an occurrence outside of it
+	for _, byKey := range overrides {
+		for key, override := range byKey {
+			if inWindow(override.start) {
+				feed.Items = append(feed.Items, eventItem(override, override.recurrenceID, key, calendarURL))
+			}
+		}
+	}
+
+	sort.SliceStable(feed.Items, func(i, j int) bool {
+		return feed.Items[i].PublishedParsed.Before(*feed.Items[j].PublishedParsed)
+	})
+	return feed, nil
+}
+
+// eventOccurrences returns the original starts of the occurrences of a recurring
+// event between from and to, without the excluded dates
+func eventOccurrences(e *icalEvent, from, to time.Time) []time.Time {
+	var starts []time.Time
+	if rule, err := parseRecurrenceRule(e.rrule, e.start.Location()); err == nil {
+		starts = rule.occurrences(e.start, from, to)
+	} else if !e.start.Before(from) && !e.start.After(to) {
+		starts = []time.Time{e.start}
+	}
+	for _, rdate := range e.rdates {
+		if !rdate.Before(from) && !rdate.After(to) {
+			starts = append(starts, rdate)
+		}
+	}
+
+	result := starts[:0]
+	seen := make(map[string]bool)
+	for _, start := range starts {
+		key := occurrenceKey(start, e.allDay)
+		excluded := seen[key]
+		for _, exdate := range e.exdates {
+			if occurrenceKey(exdate, e.allDay) == key {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			seen[key] = true
+			result = append(result, start)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result
+}
+
+// eventItem converts an occurrence of an event to a feed item. Its link is the calendar
+// URL with the UID and occurrence as fragment, so that it is kept when the event changes.
+func eventItem(e *icalEvent, originalStart time.Time, key, calendarURL string) *gofeed.Item {
+	start := e.start
+	if e.recurrenceID.IsZero() && key != "" {
+		// Generated occurrence of a recurring event
+		start = originalStart
+	}
+	end := start.Add(e.duration)
+
+	guid := e.uid
+	if key != "" {
+		guid += "/" + key
+	}
+	link := calendarURL + "#event=" + url.QueryEscape(guid)
+	if u, err := url.Parse(calendarURL); err == nil {
+		u.Fragment = "event=" + guid
+		link = u.String()
+	}
+
+	title := firstNonEmpty(e.summary, "(No title)")
+	if e.status == "CANCELLED" {
+		title = "Cancelled: " + title
+	}
+
+	var b strings.Builder
+	b.WriteString("<p><strong>When:</strong> ")
+	b.WriteString(html.EscapeString(formatEventTime(start, end, e.allDay)))
+	b.WriteString("</p>")
+	if e.location != "" {
+		b.WriteString("<p><strong>Where:</strong> ")
+		b.WriteString(html.EscapeString(e.location))
+		b.WriteString("</p>")
+	}
+	if e.url != "" {
+		fmt.Fprintf(&b, `<p><a href="%s">%s</a></p>`, html.EscapeString(e.url), html.EscapeString(e.url))
+	}
+	b.WriteString(textToHTML(e.description))
+
+	item := &gofeed.Item{
+		Title:           title,
+		Link:            link,
+		GUID:            guid,
+		Description:     b.String(),
+		Published:       start.Format(time.RFC3339),
+		PublishedParsed: &start,
+		Categories:      e.categories,
+	}
+	if !e.updated.IsZero() {
+		updated := e.updated
+		item.Updated = updated.Format(time.RFC3339)
+		item.UpdatedParsed = &updated
+	}
+	if e.organizer != "" {
+		item.Author = &gofeed.Person{Name: e.organizer}
+	}
+	return item
+}
+
+// formatEventTime formats the time span of an event occurrence
+func formatEventTime(start, end time.Time, allDay bool) string {
+	if allDay {
+		// The end date of all-day events is exclusive
+		last := end.AddDate(0, 0, -1)
+		if !last.After(start) {
+			return start.Format("Mon, 2 Jan 2006")
+		}
+		return start.Format("Mon, 2 Jan 2006") + " – " + last.Format("Mon, 2 Jan 2006")
+	}
+	s := start.Format("Mon, 2 Jan 2006 15:04 MST")
+	switch {
+	case !end.After(start):
+		return s
+	case end.Year() == start.Year() && end.YearDay() == start.YearDay():
+		return s + " – " + end.Format("15:04")
+	default:
+		return s + " – " + end.Format("Mon, 2 Jan 2006 15:04 MST")
+	}
+}
@@ -0,0 +1,161 @@
+package source
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseICalendar(t *testing.T) {
+	data := strings.ReplaceAll(`BEGIN:VCALENDAR
+VERSION:2.0
+X-WR-CALNAME:Team releases
+BEGIN:VEVENT
+UID:single@example.com
+DTSTAMP:20240201T120000Z
+DTSTART;TZID=Europe/Berlin:20240305T100000
+DTEND;TZID=Europe/Berlin:20240305T113000
+SUMMARY:Release 1.2
+DESCRIPTION:Line one\nline two\, with a com
+ ma
+LOCATION:Room\, 2
+URL:https://example.com/releases/1.2
+ORGANIZER;CN="Release Team":mailto:releases@example.com
+CATEGORIES:release,ops
+BEGIN:VALARM
+ACTION:DISPLAY
+DESCRIPTION:Reminder
+END:VALARM
+END:VEVENT
+BEGIN:VEVENT
+UID:weekly@example.com
+DTSTART:20240226T090000Z
+DURATION:PT30M
+RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=6
+EXDATE:20240306T090000Z
+SUMMARY:Standup
+END:VEVENT
+BEGIN:VEVENT
+UID:weekly@example.com
+RECURRENCE-ID:20240311T090000Z
+DTSTART:20240311T150000Z
+DTEND:20240311T153000Z
+SUMMARY:Standup (moved)
+END:VEVENT
+BEGIN:VEVENT
+UID:monthly@example.com
+DTSTART;VALUE=DATE:20240126
+RRULE:FREQ=MONTHLY;BYDAY=-1FR
+SUMMARY:Demo day
+END:VEVENT
+BEGIN:VEVENT
+UID:old@example.com
+DTSTART:20230101T100000Z
+SUMMARY:Old event
+END:VEVENT
+BEGIN:VEVENT
+UID:meetup@example.com
+DTSTART:20240320T100000Z
+STATUS:CANCELLED
+SUMMARY:Meetup
+END:VEVENT
+END:VCALENDAR
+`, "\n", "\r\n")
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	feed, err := ParseICalendar([]byte(data), "https://example.com/cal.ics", from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Team releases" {
+		t.Errorf("unexpected title %q", feed.Title)
+	}
+
+	want := []struct{ guid, title, start string }{
+		{"weekly@example.com/20240304T090000Z", "Standup", "2024-03-04T09:00:00Z"},
+		{"single@example.com", "Release 1.2", "2024-03-05T09:00:00Z"},
+		{"weekly@example.com/20240311T090000Z", "Standup (moved)", "2024-03-11T15:00:00Z"},
+		{"weekly@example.com/20240313T090000Z", "Standup", "2024-03-13T09:00:00Z"},
+		{"meetup@example.com", "Cancelled: Meetup", "2024-03-20T10:00:00Z"},
+		{"monthly@example.com/20240329", "Demo day", "2024-03-29T00:00:00Z"},
+	}
+	if len(feed.Items) != len(want) {
+		for _, item := range feed.Items {
+			t.Logf("%s %s %v", item.GUID, item.Title, item.PublishedParsed)
+		}
+		t.Fatalf("expected %d items, got %d", len(want), len(feed.Items))
+	}
+	for i, w := range want {
+		item := feed.Items[i]
+		if item.GUID != w.guid || item.Title != w.title || item.PublishedParsed.UTC().Format(time.RFC3339) != w.start {
+			t.Errorf("item %d: got %q %q %v, want %q %q %s", i, item.GUID, item.Title, item.PublishedParsed, w.guid, w.title, w.start)
+		}
+		if item.Link != "https://example.com/cal.ics#event="+w.guid {
+			t.Errorf("item %d: unexpected link %q", i, item.Link)
+		}
+	}
+
+	release := feed.Items[1]
+	for _, s := range []string{"Room, 2", "line two, with a comma", "https://example.com/releases/1.2", "11:30"} {
+		if !strings.Contains(release.Description, s) {
+			t.Errorf("expected description to contain %q, got %q", s, release.Description)
+		}
+	}
+	if strings.Contains(release.Description, "Reminder") {
+		t.Errorf("alarm description leaked into the event: %q", release.Description)
+	}
+	if release.Author == nil || release.Author.Name != "Release Team" || len(release.Categories) != 2 {
+		t.Errorf("unexpected author or categories: %+v %v", release.Author, release.Categories)
+	}
+}
+
+func TestRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		want    []string
+	}{
+		{
+			name:    "daily with interval and until",
+			rule:    "FREQ=DAILY;INTERVAL=2;UNTIL=20240107T235959Z",
+			dtstart: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+			want:    []string{"2024-01-01", "2024-01-03", "2024-01-05", "2024-01-07"},
+		},
+		{
+			name:    "last day of the month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3",
+			dtstart: time.Date(2024, 1, 31, 8, 0, 0, 0, time.UTC),
+			want:    []string{"2024-01-31", "2024-02-29", "2024-03-31"},
+		},
+		{
+			name:    "fourth Thursday of November",
+			rule:    "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH;COUNT=2",
+			dtstart: time.Date(2024, 11, 28, 8, 0, 0, 0, time.UTC),
+			want:    []string{"2024-11-28", "2025-11-27"},
+		},
+		{
+			name:    "last weekday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;COUNT=3",
+			dtstart: time.Date(2024, 3, 29, 8, 0, 0, 0, time.UTC),
+			want:    []string{"2024-03-29", "2024-04-30", "2024-05-31"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := parseRecurrenceRule(tt.rule, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, occurrence := range rule.occurrences(tt.dtstart, tt.dtstart, tt.dtstart.AddDate(3, 0, 0)) {
+				got = append(got, occurrence.Format("2006-01-02"))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -16,6 +16,13 @@ const (
 	TypeScript Type = "script" // Custom script that outputs RSS
 	TypeXPath  Type = "xpath"  // HTML scraping with XPath selectors
 	TypeEmail  Type = "email"  // Email/IMAP as feed source
+
+	TypeJSONFeed Type = "jsonfeed" // JSON Feed 1.0/1.1 via HTTP
+	TypeHFeed    Type = "hfeed"    // HTML page with microformats2 h-feed/h-entry markup
+
+	TypeMailArchive Type = "mailarchive" // Local mbox files and Maildir directories
+	TypeICalendar   Type = "icalendar"   // iCalendar (.ics) events via HTTP
+	TypeJSONPath    Type = "jsonpath"    // JSON API mapped with JSONPath expressions
 )
 
 // Source is the interface that all feed sources must implement.
@@ -51,6 +58,12 @@ type Config struct {
 	XPathItemContentSelector string // CSS selector for item content
 	XPathItemDateSelector    string // CSS selector for item date
 
+	// XPath source pagination and item pages
+	XPathNextSelector          string          // CSS selector of the next page link (pages read up to MaxPages)
+	XPathDetailContentSelector string          // CSS selector of the content in the page of an item
+	XPathDetailDateSelector    string          // CSS selector of the date in the page of an item
+	XPathKnownLinks            map[string]bool // Links of the items already read; the pagination stops at a page with one of them
+
 	// Email source fields
 	EmailIMAPServer string // IMAP server address
 	EmailIMAPPort   int    // IMAP server port (default: 993)
@@ -59,6 +72,29 @@ type Config struct {
 	EmailFolder     string // IMAP folder to fetch from (default: INBOX)
 	EmailLastUID    int    // Last processed email UID
 
+	// Mail archive source fields
+	MailArchivePath  string // mbox file, Maildir, or directory of them
+	MailArchiveGroup string // Key of the group of messages to fetch (all messages if empty)
+
+	// JSON API source fields; item fields are relative to the item
+	JSONPathItems       string // JSONPath of the items, or of their array
+	JSONPathTitle       string // JSONPath of the item title
+	JSONPathLink        string // JSONPath of the item link
+	JSONPathContent     string // JSONPath of the item content
+	JSONPathAuthor      string // JSONPath of the item author
+	JSONPathDate        string // JSONPath of the item date
+	JSONPathDateFormat  string // Go layout, "unix" or "unixms" of the date (guessed if empty)
+	JSONPathThumbnail   string // JSONPath of the item thumbnail URL
+	JSONPathCategories  string // JSONPath of the item categories
+	JSONPathUID         string // JSONPath of the item unique ID
+	JSONPathNext        string // JSONPath of the next page URL or cursor
+	JSONPathCursorParam string // Query parameter the cursor is sent in (JSONPathNext is a URL if empty)
+	MaxPages            int    // Pages read per fetch by JSON API and XPath sources (default 1)
+
+	// iCalendar source fields
+	CalendarPast   time.Duration // How far back occurrences are kept (default: 30 days)
+	CalendarFuture time.Duration // How far ahead occurrences are expanded (default: 180 days)
+
 	// Network configuration
 	ProxyURL  string            // HTTP proxy URL
 	Headers   map[string]string // Custom HTTP headers
@@ -0,0 +1,378 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/utils/httputil"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	maxJSONPathRetries = 2
+	// maxJSONPathPages bounds the pages read per fetch, whatever the configuration
+	maxJSONPathPages = 20
+)
+
+// JSONPathSource fetches JSON APIs and maps their items to feed items with JSONPath
+// expressions, following the next pages of the items through cursors or URLs.
+type JSONPathSource struct {
+	client *http.Client
+}
+
+// NewJSONPathSource creates a new JSON API source.
+func NewJSONPathSource() *JSONPathSource {
+	return NewJSONPathSourceWithProxy("")
+}
+
+// NewJSONPathSourceWithProxy creates a new JSON API source with custom proxy.
+func NewJSONPathSourceWithProxy(proxyURL string) *JSONPathSource {
+	userAgent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	return &JSONPathSource{
+		client: httputil.GetPooledUserAgentClient(proxyURL, 20*time.Second, userAgent),
+	}
+}
+
+// Type returns the source type identifier.
+func (s *JSONPathSource) Type() Type {
+	return TypeJSONPath
+}
+
+// Validate checks if the configuration is valid for JSON API source.
+func (s *JSONPathSource) Validate(config *Config) error {
+	if config == nil {
+		return errors.New("config is nil")
+	}
+	if config.URL == "" {
+		return errors.New("URL is required for JSON API source")
+	}
+	if config.JSONPathItems == "" {
+		return errors.New("items JSONPath is required for JSON API source")
+	}
+	_, err := compileJSONPathMapping(config)
+	return err
+}
+
+// SetHTTPClient allows setting a custom HTTP client.
+func (s *JSONPathSource) SetHTTPClient(client *http.Client) {
+	if client != nil {
+		s.client = client
+	}
+}
+
+// Fetch retrieves the pages of the JSON API and extracts their items.
+func (s *JSONPathSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed, error) {
+	if err := s.Validate(config); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	mapping, _ := compileJSONPathMapping(config)
+
+	maxPages := max(config.MaxPages, 1)
+	if mapping.next == nil {
+		maxPages = 1
+	}
+	maxPages = min(maxPages, maxJSONPathPages)
+
+	feed := &gofeed.Feed{Link: config.URL, Items: []*gofeed.Item{}}
+	pageURL := config.URL
+	seen := map[string]bool{}
+	for page := 0; page < maxPages && pageURL != "" && !seen[pageURL]; page++ {
+		seen[pageURL] = true
+		doc, err := s.fetchPage(ctx, config, pageURL)
+		if err != nil {
+			if page > 0 {
+				// Keep the items of the pages already read
+				log.Printf("[JSONPathSource] Stopping at page %d of %s: %v", page+1, config.URL, err)
+				break
+			}
+			return nil, err
+		}
+		feed.Items = append(feed.Items, mapping.items(doc, pageURL, config.URL)...)
+		pageURL = mapping.nextPage(doc, pageURL, config.JSONPathCursorParam)
+	}
+	return feed, nil
+}
+
+// fetchPage fetches and decodes a page with retry support
+func (s *JSONPathSource) fetchPage(ctx context.Context, config *Config, pageURL string) (interface{}, error) {
+	pageConfig := *config
+	pageConfig.URL = pageURL
+
+	var lastErr error
+	for attempt := 0; attempt < maxJSONPathRetries; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		data, err := fetchDocument(ctx, s.client, &pageConfig, "application/json")
+		if err == nil {
+			doc, err := decodeJSONDocument(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse JSON from %s: %w", pageURL, err)
+			}
+			return doc, nil
+		}
+
+		lastErr = err
+		if httputil.IsNetworkError(err.Error()) && attempt < maxJSONPathRetries-1 {
+			backoff := httputil.CalculateBackoffSimple(attempt)
+			log.Printf("[JSONPathSource] Network error on attempt %d/%d for %s, retrying in %v: %v",
+				attempt+1, maxJSONPathRetries, pageURL, backoff, err)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			continue
+		}
+
+		return nil, fmt.Errorf("failed to fetch JSON: %w", err)
+	}
+
+	return nil, fmt.Errorf("all %d attempts failed, last error: %w", maxJSONPathRetries, lastErr)
+}
+
+// decodeJSONDocument decodes a JSON document, keeping numbers like large IDs exact
+func decodeJSONDocument(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// ParseJSONPathItems extracts the items of a JSON page at config.URL with the mapping of the config
+func ParseJSONPathItems(data []byte, config *Config) ([]*gofeed.Item, error) {
+	mapping, err := compileJSONPathMapping(config)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeJSONDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	return mapping.items(doc, config.URL, config.URL), nil
+}
+
+// jsonPathMapping holds the compiled expressions of a config
+type jsonPathMapping struct {
+	itemList                                                             JSONPath
+	title, link, content, author, date, thumbnail, categories, uid, next JSONPath
+	dateFormat                                                           string
+}
+
+func compileJSONPathMapping(config *Config) (*jsonPathMapping, error) {
+	m := &jsonPathMapping{dateFormat: config.JSONPathDateFormat}
+	for _, field := range []struct {
+		name string
+		expr string
+		path *JSONPath
+	}{
+		{"items", config.JSONPathItems, &m.itemList},
+		{"title", config.JSONPathTitle, &m.title},
+		{"link", config.JSONPathLink, &m.link},
+		{"content", config.JSONPathContent, &m.content},
+		{"author", config.JSONPathAuthor, &m.author},
+		{"date", config.JSONPathDate, &m.date},
+		{"thumbnail", config.JSONPathThumbnail, &m.thumbnail},
+		{"categories", config.JSONPathCategories, &m.categories},
+		{"uid", config.JSONPathUID, &m.uid},
+		{"next page", config.JSONPathNext, &m.next},
+	} {
+		if field.expr == "" {
+			continue
+		}
+		path, err := CompileJSONPath(field.expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s JSONPath %q: %w", field.name, field.expr, err)
+		}
+		*field.path = path
+	}
+	return m, nil
+}
+
+// items extracts the items of a page of the feed at feedURL. The items expression may
+// match the array of the items or the items themselves.
+func (m *jsonPathMapping) items(doc interface{}, pageURL, feedURL string) []*gofeed.Item {
+	nodes := m.itemList.Find(doc)
+	if len(nodes) == 1 {
+		if array, ok := nodes[0].([]interface{}); ok {
+			nodes = array
+		}
+	}
+
+	items := make([]*gofeed.Item, 0, len(nodes))
+	for _, node := range nodes {
+		if item := m.item(node, pageURL, feedURL); item != nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// item maps an item with the field expressions, which are relative to the item
+func (m *jsonPathMapping) item(node interface{}, pageURL, feedURL string) *gofeed.Item {
+	find := func(path JSONPath) string {
+		if path == nil {
+			return ""
+		}
+		return path.FindString(node)
+	}
+
+	item := &gofeed.Item{
+		Title:   find(m.title),
+		Link:    resolveReference(pageURL, find(m.link)),
+		Content: find(m.content),
+		GUID:    find(m.uid),
+	}
+	if author := find(m.author); author != "" {
+		item.Author = &gofeed.Person{Name: author}
+	}
+	if date := find(m.date); date != "" {
+		if t := parseJSONDate(date, m.dateFormat); t != nil {
+			item.PublishedParsed = t
+			item.Published = t.Format(time.RFC3339)
+		}
+	}
+	if thumbnail := resolveReference(pageURL, find(m.thumbnail)); thumbnail != "" {
+		item.Image = &gofeed.Image{URL: thumbnail}
+	}
+	if m.categories != nil {
+		for _, value := range m.categories.Find(node) {
+			// The elements of an array are categories of their own
+			values, isArray := value.([]interface{})
+			if !isArray {
+				values = []interface{}{value}
+			}
+			for _, element := range values {
+				if _, isObject := element.(map[string]interface{}); isObject {
+					continue
+				}
+				if category := jsonString(element); category != "" {
+					item.Categories = append(item.Categories, category)
+				}
+			}
+		}
+	}
+
+	if item.Title == "" && item.Link == "" && item.Content == "" {
+		return nil
+	}
+	if item.Link == "" {
+		// Give each item a stable link of its own, whatever its page, so that articles don't collide
+		id := item.GUID
+		if id == "" {
+			sum := sha256.Sum256([]byte(item.Title + "\x00" + item.Content))
+			id = hex.EncodeToString(sum[:8])
+		}
+		item.Link = feedURL + "#item=" + url.QueryEscape(id)
+	}
+	if item.GUID == "" {
+		item.GUID = item.Link
+	}
+	return item
+}
+
+// nextPage returns the URL of the page after the one at pageURL, or "" if there is none
+func (m *jsonPathMapping) nextPage(doc interface{}, pageURL, cursorParam string) string {
+	if m.next == nil {
+		return ""
+	}
+	next := m.next.FindString(doc)
+	if next == "" || next == "null" || next == "false" {
+		return ""
+	}
+	if cursorParam == "" {
+		return resolveReference(pageURL, next)
+	}
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	query := u.Query()
+	query.Set(cursorParam, next)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// resolveReference resolves a URL found in a page against the page URL
+func resolveReference(base, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
+
+// parseJSONDate parses a date with a Go layout, "unix" or "unixms", or guesses the
+// format when layout is empty: Unix timestamps in seconds or milliseconds and common
+// date formats.
+func parseJSONDate(value, layout string) *time.Time {
+	value = strings.TrimSpace(value)
+	parseUnix := func(ms bool) *time.Time {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil
+		}
+		if ms {
+			f /= 1000
+		}
+		t := time.Unix(int64(f), int64((f-float64(int64(f)))*1e9)).UTC()
+		return &t
+	}
+
+	switch layout {
+	case "unix":
+		return parseUnix(false)
+	case "unixms":
+		return parseUnix(true)
+	case "":
+	default:
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+		return nil
+	}
+
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		// Timestamps after 2286 in seconds are taken for milliseconds
+		return parseUnix(f > 1e10)
+	}
+	for _, format := range []string{
+		time.RFC3339Nano,
+		time.RFC1123Z,
+		time.RFC1123,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05Z07:00",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+	} {
+		if t, err := time.Parse(format, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJSONPath(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{
+  "data": {"children": [
+    {"data": {"title": "First", "tags": ["a", "b"], "score": 3}},
+    {"data": {"title": "Second", "tags": [], "score": 5}},
+    {"data": {"title": "Third", "score": 1}}
+  ]},
+  "meta": {"next.cursor": "abc"}
+}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"$.data.children[0].data.title", "First"},
+		{"data.children[-1].data.title", "Third"},
+		{"$.data.children[*].data.title", "First|Second|Third"},
+		{"$.data.children[1:].data.score", "5|1"},
+		{"$..title", "First|Second|Third"},
+		{"$.data.children[0].data.tags", "a, b"},
+		{"$['meta']['next.cursor']", "abc"},
+		{"$.data.children[0,2].data['title']", "First|Third"},
+		{"$.missing.title", ""},
+	}
+	for _, tt := range tests {
+		path, err := CompileJSONPath(tt.expr)
+		if err != nil {
+			t.Errorf("CompileJSONPath(%q): %v", tt.expr, err)
+			continue
+		}
+		var got []string
+		for _, value := range path.Find(doc) {
+			got = append(got, jsonString(value))
+		}
+		if strings.Join(got, "|") != tt.want {
+			t.Errorf("%s = %q, want %q", tt.expr, strings.Join(got, "|"), tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", "$.items[?(@.id > 1)]", "$.items[0", "$..", "$x"} {
+		if _, err := CompileJSONPath(expr); err == nil {
+			t.Errorf("expected an error for %q", expr)
+		}
+	}
+}
+
+func TestJSONPathSource_Fetch(t *testing.T) {
+	pages := map[string]string{
+		"": `{"posts": [
+  {"id": 1, "title": "One", "url": "/posts/1", "body": "<p>1</p>", "user": {"name": "Ann"},
+   "created": 1709287200, "image": "/img/1.png", "labels": [{"name": "go"}]},
+  {"id": 2, "title": "Two", "created": 1709373600}
+], "next": "c2"}`,
+		"c2": `{"posts": [{"id": 3, "title": "Three", "url": "https://other.example.com/3", "created": "2024-03-03T10:00:00Z"}], "next": "c3"}`,
+		"c3": `{"posts": [{"id": 4, "title": "Four"}], "next": null}`,
+	}
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		page, ok := pages[r.URL.Query().Get("cursor")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	config := &Config{
+		URL:                 server.URL + "/api/posts?limit=2",
+		JSONPathItems:       "$.posts",
+		JSONPathTitle:       "title",
+		JSONPathLink:        "url",
+		JSONPathContent:     "body",
+		JSONPathAuthor:      "user.name",
+		JSONPathDate:        "created",
+		JSONPathThumbnail:   "image",
+		JSONPathCategories:  "labels[*].name",
+		JSONPathUID:         "id",
+		JSONPathNext:        "$.next",
+		JSONPathCursorParam: "cursor",
+		MaxPages:            2,
+		Headers:             map[string]string{"Authorization": "Bearer token"},
+	}
+	feed, err := NewJSONPathSource().Fetch(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(feed.Items) != 3 || len(auth) != 2 || auth[1] != "Bearer token" {
+		t.Fatalf("expected 3 items from 2 pages with auth, got %d items and requests %v", len(feed.Items), auth)
+	}
+	first := feed.Items[0]
+	if first.Title != "One" || first.Link != server.URL+"/posts/1" || first.GUID != "1" || first.Content != "<p>1</p>" {
+		t.Errorf("unexpected first item %+v", first)
+	}
+	if first.Author == nil || first.Author.Name != "Ann" || first.Image == nil || first.Image.URL != server.URL+"/img/1.png" {
+		t.Errorf("unexpected author or image %+v %+v", first.Author, first.Image)
+	}
+	if first.PublishedParsed == nil || first.PublishedParsed.Unix() != 1709287200 || len(first.Categories) != 1 || first.Categories[0] != "go" {
+		t.Errorf("unexpected date or categories %v %v", first.PublishedParsed, first.Categories)
+	}
+	// Items without link get a stable one of the feed URL
+	if second := feed.Items[1]; second.Link != config.URL+"#item=2" || second.GUID != "2" {
+		t.Errorf("unexpected generated link %q (%q)", second.Link, second.GUID)
+	}
+	if third := feed.Items[2]; third.Link != "https://other.example.com/3" || third.PublishedParsed == nil || third.PublishedParsed.Day() != 3 {
+		t.Errorf("unexpected third item %+v", third)
+	}
+}
+
+func TestJSONPathCategories(t *testing.T) {
+	var node interface{}
+	if err := json.Unmarshal([]byte(`{"title": "Trip", "tags": ["Paris, France", "travel", {"x": 1}], "topics": [{"name": "Lyon, France"}, {"name": "food"}]}`), &node); err != nil {
+		t.Fatal(err)
+	}
+	for expr, want := range map[string]string{
+		"tags":           "Paris, France|travel",
+		"topics[*].name": "Lyon, France|food",
+	} {
+		mapping, err := compileJSONPathMapping(&Config{JSONPathItems: "$", JSONPathTitle: "title", JSONPathCategories: expr})
+		if err != nil {
+			t.Fatalf("compileJSONPathMapping(%q): %v", expr, err)
+		}
+		item := mapping.item(node, "https://example.com/api", "https://example.com/api")
+		if got := strings.Join(item.Categories, "|"); got != want {
+			t.Errorf("%s: got categories %q, want %q", expr, got, want)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/utils/httputil"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+const (
+	maxJSONFeedRetries = 2
+	// maxDocumentSize limits the size of documents read by fetchDocument
+	maxDocumentSize = 20 << 20
+)
+
+// JSONFeedSource fetches JSON Feed 1.0/1.1 documents (https://jsonfeed.org).
+// Unlike the generic gofeed parser it keeps attachments, multiple authors,
+// _extensions and the distinction between content_html and content_text.
+type JSONFeedSource struct {
+	client *http.Client
+}
+
+// NewJSONFeedSource creates a new JSON Feed source.
+func NewJSONFeedSource() *JSONFeedSource {
+	return NewJSONFeedSourceWithProxy("")
+}
+
+// NewJSONFeedSourceWithProxy creates a new JSON Feed source with custom proxy.
+func NewJSONFeedSourceWithProxy(proxyURL string) *JSONFeedSource {
+	userAgent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	return &JSONFeedSource{
+		client: httputil.GetPooledUserAgentClient(proxyURL, 20*time.Second, userAgent),
+	}
+}
+
+// Type returns the source type identifier.
+func (s *JSONFeedSource) Type() Type {
+	return TypeJSONFeed
+}
+
+// Validate checks if the configuration is valid for JSON Feed source.
+func (s *JSONFeedSource) Validate(config *Config) error {
+	if config == nil {
+		return errors.New("config is nil")
+	}
+	if config.URL == "" {
+		return errors.New("URL is required for JSON Feed source")
+	}
+	return nil
+}
+
+// SetHTTPClient allows setting a custom HTTP client.
+func (s *JSONFeedSource) SetHTTPClient(client *http.Client) {
+	if client != nil {
+		s.client = client
+	}
+}
+
+// Fetch retrieves and parses the JSON Feed from the URL with retry support.
+func (s *JSONFeedSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed, error) {
+	if err := s.Validate(config); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxJSONFeedRetries; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		data, err := fetchDocument(ctx, s.client, config, "application/feed+json, application/json;q=0.9")
+		if err == nil {
+			feed, err := ParseJSONFeed(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse JSON Feed from %s: %w", config.URL, err)
+			}
+			return feed, nil
+		}
+
+		lastErr = err
+		if httputil.IsNetworkError(err.Error()) && attempt < maxJSONFeedRetries-1 {
+			backoff := httputil.CalculateBackoffSimple(attempt)
+			log.Printf("[JSONFeedSource] Network error on attempt %d/%d for %s, retrying in %v: %v",
+				attempt+1, maxJSONFeedRetries, config.URL, backoff, err)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			continue
+		}
+
+		return nil, fmt.Errorf("failed to fetch JSON Feed: %w", err)
+	}
+
+	return nil, fmt.Errorf("all %d attempts failed, last error: %w", maxJSONFeedRetries, lastErr)
+}
+
+// jsonFeedDoc is a JSON Feed document. Fields of version 1.0 that were
+// replaced in 1.1 (author) are kept for compatibility.
+type jsonFeedDoc struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	FeedURL     string           `json:"feed_url"`
+	Description string           `json:"description"`
+	Icon        string           `json:"icon"`
+	Favicon     string           `json:"favicon"`
+	Language    string           `json:"language"`
+	Author      *jsonFeedAuthor  `json:"author"`
+	Authors     []jsonFeedAuthor `json:"authors"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Avatar string `json:"avatar"`
+}
+
+type jsonFeedItem struct {
+	ID            json.RawMessage      `json:"id"` // Should be a string, but numbers are common
+	URL           string               `json:"url"`
+	ExternalURL   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	Image         string               `json:"image"`
+	BannerImage   string               `json:"banner_image"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Author        *jsonFeedAuthor      `json:"author"`
+	Authors       []jsonFeedAuthor     `json:"authors"`
+	Tags          []string             `json:"tags"`
+	Language      string               `json:"language"`
+	Attachments   []jsonFeedAttachment `json:"attachments"`
+}
+
+type jsonFeedAttachment struct {
+	URL               string  `json:"url"`
+	MimeType          string  `json:"mime_type"`
+	Title             string  `json:"title"`
+	SizeInBytes       int64   `json:"size_in_bytes"`
+	DurationInSeconds float64 `json:"duration_in_seconds"`
+}
+
+// ParseJSONFeed converts a JSON Feed document to a gofeed.Feed.
+// Extension objects (keys starting with "_") are kept as raw JSON in the
+// Custom map of the feed and items, under their original key.
+func ParseJSONFeed(data []byte) (*gofeed.Feed, error) {
+	var doc jsonFeedDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("not a JSON Feed (version %q)", doc.Version)
+	}
+
+	// Decode a second time to collect the extension objects
+	var rawFeed struct {
+		Items []map[string]json.RawMessage `json:"items"`
+	}
+	var rawTop map[string]json.RawMessage
+	_ = json.Unmarshal(data, &rawFeed)
+	_ = json.Unmarshal(data, &rawTop)
+
+	feed := &gofeed.Feed{
+		Title:       doc.Title,
+		Description: doc.Description,
+		Link:        doc.HomePageURL,
+		FeedLink:    doc.FeedURL,
+		Language:    doc.Language,
+		Authors:     jsonFeedPersons(doc.Author, doc.Authors),
+		Custom:      jsonFeedExtensions(rawTop),
+		FeedType:    "json",
+		FeedVersion: strings.TrimPrefix(doc.Version, "https://jsonfeed.org/version/"),
+		Items:       make([]*gofeed.Item, 0, len(doc.Items)),
+	}
+	if len(feed.Authors) > 0 {
+		feed.Author = feed.Authors[0]
+	}
+	if icon := firstNonEmpty(doc.Icon, doc.Favicon); icon != "" {
+		feed.Image = &gofeed.Image{URL: icon}
+	}
+
+	for i, it := range doc.Items {
+		item := &gofeed.Item{
+			GUID:        jsonFeedID(it.ID),
+			Title:       it.Title,
+			Link:        firstNonEmpty(it.URL, it.ExternalURL),
+			Description: it.Summary,
+			Content:     it.ContentHTML,
+			Categories:  it.Tags,
+			Authors:     jsonFeedPersons(it.Author, it.Authors),
+		}
+		if i < len(rawFeed.Items) {
+			item.Custom = jsonFeedExtensions(rawFeed.Items[i])
+		}
+		if it.ExternalURL != "" && it.ExternalURL != item.Link {
+			item.Links = []string{item.Link, it.ExternalURL}
+		}
+		if item.GUID == "" {
+			item.GUID = item.Link
+		}
+
+		// content_text is plain text, keep it as such in the description and
+		// only convert it to HTML when there is no HTML content
+		if item.Content == "" && it.ContentText != "" {
+			item.Content = textToHTML(it.ContentText)
+		}
+		if item.Description == "" {
+			item.Description = it.ContentText
+		}
+
+		if len(item.Authors) > 0 {
+			item.Author = item.Authors[0]
+		} else if feed.Author != nil {
+			item.Author = feed.Author
+		}
+		if image := firstNonEmpty(it.Image, it.BannerImage); image != "" {
+			item.Image = &gofeed.Image{URL: image}
+		}
+		if t, err := time.Parse(time.RFC3339, it.DatePublished); err == nil {
+			item.Published = it.DatePublished
+			item.PublishedParsed = &t
+		}
+		if t, err := time.Parse(time.RFC3339, it.DateModified); err == nil {
+			item.Updated = it.DateModified
+			item.UpdatedParsed = &t
+			if item.PublishedParsed == nil {
+				item.PublishedParsed = &t
+			}
+		}
+
+		for _, a := range it.Attachments {
+			if a.URL == "" {
+				continue
+			}
+			enclosure := &gofeed.Enclosure{URL: a.URL, Type: a.MimeType}
+			if a.SizeInBytes > 0 {
+				enclosure.Length = strconv.FormatInt(a.SizeInBytes, 10)
+			}
+			item.Enclosures = append(item.Enclosures, enclosure)
+			// Keep the duration where podcast metadata is read from
+			if a.DurationInSeconds > 0 && item.ITunesExt == nil && strings.HasPrefix(a.MimeType, "audio/") {
+				item.ITunesExt = &ext.ITunesItemExtension{Duration: strconv.Itoa(int(a.DurationInSeconds))}
+			}
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	return feed, nil
+}
+
+// IsJSONFeed reports whether data looks like a JSON Feed document
+func IsJSONFeed(data []byte) bool {
+	return strings.Contains(string(data), "jsonfeed.org/version/")
+}
+
+func jsonFeedPersons(single *jsonFeedAuthor, multiple []jsonFeedAuthor) []*gofeed.Person {
+	if len(multiple) == 0 && single != nil {
+		multiple = []jsonFeedAuthor{*single}
+	}
+	var persons []*gofeed.Person
+	for _, a := range multiple {
+		if a.Name == "" && a.URL == "" {
+			continue
+		}
+		persons = append(persons, &gofeed.Person{Name: firstNonEmpty(a.Name, a.URL)})
+	}
+	return persons
+}
+
+// jsonFeedExtensions returns the extension objects of a raw JSON object
+func jsonFeedExtensions(raw map[string]json.RawMessage) map[string]string {
+	var custom map[string]string
+	for key, value := range raw {
+		if !strings.HasPrefix(key, "_") {
+			continue
+		}
+		if custom == nil {
+			custom = make(map[string]string)
+		}
+		custom[key] = string(value)
+	}
+	return custom
+}
+
+// jsonFeedID returns an item ID as a string, accepting numeric IDs
+func jsonFeedID(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// textToHTML converts plain text to HTML paragraphs
+func textToHTML(text string) string {
+	var b strings.Builder
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(para), "\n", "<br>"))
+		b.WriteString("</p>")
+	}
+	return b.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// fetchDocument performs a GET request for a source and returns the body
+func fetchDocument(ctx context.Context, client *http.Client, config *Config, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", config.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if config.UserAgent != "" {
+		req.Header.Set("User-Agent", config.UserAgent)
+	}
+	req.Header.Set("Accept", accept)
+	for key, value := range config.Headers {
+		req.Header.Set(key, value)
+	}
+	if config.BasicAuthUser != "" {
+		req.SetBasicAuth(config.BasicAuthUser, config.BasicAuthPassword)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+}
@@ -0,0 +1,334 @@
+package source
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a compiled JSONPath expression. A subset of RFC 9535 is supported: the
+// root $ (or @), .name and ['name'] members, [n] indices (negative from the end),
+// [start:end] slices, .* and [*] wildcards, [a,b] unions and .. descendants. Filter
+// expressions are not supported. Expressions without root, like "data.title", are
+// relative to the root.
+type JSONPath []jsonPathStep
+
+// jsonPathStep is a segment of a JSONPath expression
+type jsonPathStep struct {
+	descendant bool // Applies to the node and all its descendants
+	wildcard   bool
+	names      []string
+	indices    []int
+	slice      *jsonPathSlice
+}
+
+type jsonPathSlice struct {
+	start, end *int
+}
+
+// CompileJSONPath parses a JSONPath expression
+func CompileJSONPath(expr string) (JSONPath, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, errors.New("empty JSONPath expression")
+	}
+	switch {
+	case expr[0] == '$' || expr[0] == '@':
+		expr = expr[1:]
+	case expr[0] != '.' && expr[0] != '[':
+		expr = "." + expr
+	}
+
+	path := JSONPath{}
+	for i := 0; i < len(expr); {
+		var step jsonPathStep
+		switch {
+		case strings.HasPrefix(expr[i:], ".."):
+			step.descendant = true
+			i += 2
+			if i < len(expr) && expr[i] == '[' {
+				n, err := parseJSONPathBracket(expr[i:], &step)
+				if err != nil {
+					return nil, err
+				}
+				i += n
+			} else {
+				i += parseJSONPathName(expr[i:], &step)
+			}
+		case expr[i] == '.':
+			i++
+			i += parseJSONPathName(expr[i:], &step)
+		case expr[i] == '[':
+			n, err := parseJSONPathBracket(expr[i:], &step)
+			if err != nil {
+				return nil, err
+			}
+			i += n
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d of JSONPath", expr[i], i)
+		}
+		if !step.wildcard && step.names == nil && step.indices == nil && step.slice == nil {
+			return nil, errors.New("empty segment in JSONPath")
+		}
+		path = append(path, step)
+	}
+	return path, nil
+}
+
+// parseJSONPathName parses a member name or * after a dot, returning its length
+func parseJSONPathName(expr string, step *jsonPathStep) int {
+	end := strings.IndexAny(expr, ".[")
+	if end < 0 {
+		end = len(expr)
+	}
+	name := strings.TrimSpace(expr[:end])
+	if name == "*" {
+		step.wildcard = true
+	} else if name != "" {
+		step.names = []string{name}
+	}
+	return end
+}
+
+// parseJSONPathBracket parses a [...] segment, returning its length
+func parseJSONPathBracket(expr string, step *jsonPathStep) (int, error) {
+	end, inQuote := -1, byte(0)
+	for i := 1; i < len(expr) && end < 0; i++ {
+		switch c := expr[i]; {
+		case inQuote != 0 && c == '\\':
+			i++
+		case inQuote != 0:
+			if c == inQuote {
+				inQuote = 0
+			}
+		case c == '\'' || c == '"':
+			inQuote = c
+		case c == ']':
+			end = i
+		}
+	}
+	if end < 0 {
+		return 0, errors.New("unclosed bracket in JSONPath")
+	}
+
+	content := strings.TrimSpace(expr[1:end])
+	switch {
+	case content == "*":
+		step.wildcard = true
+	case strings.HasPrefix(content, "?"):
+		return 0, errors.New("JSONPath filter expressions are not supported")
+	case strings.HasPrefix(content, "'") || strings.HasPrefix(content, `"`):
+		for _, part := range splitJSONPathUnion(content) {
+			name, err := unquoteJSONPathName(part)
+			if err != nil {
+				return 0, err
+			}
+			step.names = append(step.names, name)
+		}
+	case strings.Contains(content, ":"):
+		bounds := strings.Split(content, ":")
+		if len(bounds) > 3 || (len(bounds) == 3 && strings.TrimSpace(bounds[2]) != "" && strings.TrimSpace(bounds[2]) != "1") {
+			return 0, fmt.Errorf("unsupported JSONPath slice [%s]", content)
+		}
+		step.slice = &jsonPathSlice{}
+		for i, bound := range bounds[:2] {
+			if bound = strings.TrimSpace(bound); bound == "" {
+				continue
+			}
+			n, err := strconv.Atoi(bound)
+			if err != nil {
+				return 0, fmt.Errorf("invalid JSONPath slice [%s]", content)
+			}
+			if i == 0 {
+				step.slice.start = &n
+			} else {
+				step.slice.end = &n
+			}
+		}
+	default:
+		for _, part := range strings.Split(content, ",") {
+			part = strings.TrimSpace(part)
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				// Lenient: a bare member name like [title]
+				step.names = append(step.names, part)
+				continue
+			}
+			step.indices = append(step.indices, n)
+		}
+	}
+	return end + 1, nil
+}
+
+// splitJSONPathUnion splits the quoted names of a union like 'a','b'
+func splitJSONPathUnion(content string) []string {
+	var parts []string
+	start, inQuote := 0, byte(0)
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case inQuote != 0 && c == '\\':
+			i++
+		case inQuote != 0:
+			if c == inQuote {
+				inQuote = 0
+			}
+		case c == '\'' || c == '"':
+			inQuote = c
+		case c == ',':
+			parts = append(parts, strings.TrimSpace(content[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(content[start:]))
+}
+
+func unquoteJSONPathName(quoted string) (string, error) {
+	if len(quoted) < 2 || quoted[0] != quoted[len(quoted)-1] {
+		return "", fmt.Errorf("invalid JSONPath name %s", quoted)
+	}
+	var b strings.Builder
+	for i := 1; i < len(quoted)-1; i++ {
+		if quoted[i] == '\\' && i+1 < len(quoted)-1 {
+			i++
+		}
+		b.WriteByte(quoted[i])
+	}
+	return b.String(), nil
+}
+
+// Find returns the values matched by the expression in a decoded JSON document
+func (p JSONPath) Find(root interface{}) []interface{} {
+	nodes := []interface{}{root}
+	for _, step := range p {
+		var next []interface{}
+		for _, node := range nodes {
+			if step.descendant {
+				for _, d := range jsonDescendants(node, nil) {
+					next = append(next, step.apply(d)...)
+				}
+			} else {
+				next = append(next, step.apply(node)...)
+			}
+		}
+		nodes = next
+		if len(nodes) == 0 {
+			break
+		}
+	}
+	return nodes
+}
+
+// FindString returns the first value matched by the expression as a string, or ""
+func (p JSONPath) FindString(root interface{}) string {
+	for _, value := range p.Find(root) {
+		if s := jsonString(value); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// apply returns the children of a node selected by the step
+func (s jsonPathStep) apply(node interface{}) []interface{} {
+	var result []interface{}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if s.wildcard {
+			for _, key := range sortedKeys(n) {
+				result = append(result, n[key])
+			}
+		}
+		for _, name := range s.names {
+			if value, ok := n[name]; ok {
+				result = append(result, value)
+			}
+		}
+	case []interface{}:
+		if s.wildcard {
+			result = append(result, n...)
+		}
+		for _, i := range s.indices {
+			if i < 0 {
+				i += len(n)
+			}
+			if i >= 0 && i < len(n) {
+				result = append(result, n[i])
+			}
+		}
+		if s.slice != nil {
+			start, end := 0, len(n)
+			if s.slice.start != nil {
+				start = clampSliceBound(*s.slice.start, len(n))
+			}
+			if s.slice.end != nil {
+				end = clampSliceBound(*s.slice.end, len(n))
+			}
+			for i := start; i < end; i++ {
+				result = append(result, n[i])
+			}
+		}
+	}
+	return result
+}
+
+func clampSliceBound(i, length int) int {
+	if i < 0 {
+		i += length
+	}
+	return max(0, min(i, length))
+}
+
+// jsonDescendants returns a node and all its descendants, the members of objects by key
+func jsonDescendants(node interface{}, result []interface{}) []interface{} {
+	result = append(result, node)
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(n) {
+			result = jsonDescendants(n[key], result)
+		}
+	case []interface{}:
+		for _, child := range n {
+			result = jsonDescendants(child, result)
+		}
+	}
+	return result
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonString converts a scalar JSON value to a string. Arrays of scalars are joined
+// with commas and objects are ignored.
+func jsonString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		var parts []string
+		for _, element := range v {
+			if _, isObject := element.(map[string]interface{}); isObject {
+				continue
+			}
+			if s := jsonString(element); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+	return ""
+}
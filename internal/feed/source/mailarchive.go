@@ -0,0 +1,457 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	// maxArchiveMessageSize bounds the size of the messages read from an archive;
+	// larger messages are skipped
+	maxArchiveMessageSize = 32 << 20
+	// mailArchiveCacheTTL is how long a parsed archive is reused while it is unchanged,
+	// so that refreshing the feeds of its groups reads it once
+	mailArchiveCacheTTL = 10 * time.Minute
+)
+
+// MailArchiveSource reads newsletters from local mbox files and Maildir directories,
+// e.g. exports of a mail client. A directory is searched for mbox files, .eml files
+// and Maildirs (including Maildir++ subfolders). Messages are grouped into feeds by
+// List-Id, else by sender, and deduplicated by Message-Id so that reading an archive
+// again is idempotent.
+type MailArchiveSource struct {
+	email *EmailSource
+
+	mu    sync.Mutex
+	cache map[string]*mailArchiveCacheEntry
+}
+
+// MailArchiveGroup holds the messages of a mailing list or sender of an archive
+type MailArchiveGroup struct {
+	Key   string // "list:<List-Id>" or "from:<address>"
+	Title string // Name of the list or sender
+	Feed  *gofeed.Feed
+}
+
+type mailArchiveCacheEntry struct {
+	stamp    string
+	groups   []*MailArchiveGroup
+	lastUsed time.Time
+}
+
+// NewMailArchiveSource creates a new mail archive source.
+func NewMailArchiveSource() *MailArchiveSource {
+	return &MailArchiveSource{
+		email: NewEmailSource(),
+		cache: make(map[string]*mailArchiveCacheEntry),
+	}
+}
+
+// Type returns the source type identifier.
+func (m *MailArchiveSource) Type() Type {
+	return TypeMailArchive
+}
+
+// Validate checks if the configuration is valid for mail archive source.
+func (m *MailArchiveSource) Validate(config *Config) error {
+	if config == nil {
+		return errors.New("config is nil")
+	}
+	if config.MailArchivePath == "" {
+		return errors.New("path is required for mail archive source")
+	}
+	if _, err := os.Stat(config.MailArchivePath); err != nil {
+		return fmt.Errorf("mail archive not readable: %w", err)
+	}
+	return nil
+}
+
+// Fetch returns the messages of the group config.MailArchiveGroup of the archive,
+// or all its messages if no group is set.
+func (m *MailArchiveSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed, error) {
+	groups, err := m.FetchGroups(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &gofeed.Feed{
+		Title:       filepath.Base(config.MailArchivePath),
+		Description: fmt.Sprintf("Emails from %s", config.MailArchivePath),
+		Items:       []*gofeed.Item{},
+	}
+	for _, group := range groups {
+		if config.MailArchiveGroup == "" {
+			feed.Items = append(feed.Items, group.Feed.Items...)
+		} else if group.Key == config.MailArchiveGroup {
+			// The groups are cached, return a copy
+			groupFeed := *group.Feed
+			groupFeed.Items = append([]*gofeed.Item{}, group.Feed.Items...)
+			return &groupFeed, nil
+		}
+	}
+	if config.MailArchiveGroup != "" {
+		// The messages of the group were removed from the archive
+		feed.Title = config.MailArchiveGroup
+	}
+	return feed, nil
+}
+
+// FetchGroups reads the archive and returns its messages grouped by mailing list or
+// sender, in the order the groups first appear.
+func (m *MailArchiveSource) FetchGroups(ctx context.Context, config *Config) ([]*MailArchiveGroup, error) {
+	if err := m.Validate(config); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	path := config.MailArchivePath
+
+	stamp, err := mailArchiveStamp(path)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	now := time.Now()
+	for key, entry := range m.cache {
+		if now.Sub(entry.lastUsed) > mailArchiveCacheTTL {
+			delete(m.cache, key)
+		}
+	}
+	if entry, ok := m.cache[path]; ok && entry.stamp == stamp {
+		entry.lastUsed = now
+		m.mu.Unlock()
+		return entry.groups, nil
+	}
+	m.mu.Unlock()
+
+	var groups []*MailArchiveGroup
+	byKey := make(map[string]*MailArchiveGroup)
+	seen := make(map[string]bool)
+	err = walkMailArchive(ctx, path, func(raw []byte) error {
+		msg, key, title, err := parseArchiveMessage(raw)
+		if err != nil {
+			// Skip malformed messages but continue with the others
+			return nil
+		}
+		// The same message is often in several folders or exports
+		if seen[msg.Envelope.MessageId] {
+			return nil
+		}
+		seen[msg.Envelope.MessageId] = true
+
+		group, ok := byKey[key]
+		if !ok {
+			group = &MailArchiveGroup{Key: key, Feed: &gofeed.Feed{Items: []*gofeed.Item{}}}
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+		// Prefer the name of the list or sender to its address
+		if group.Title == "" || (isGroupAddress(key, group.Title) && !isGroupAddress(key, title)) {
+			group.Title = title
+			group.Feed.Title = title
+			group.Feed.Description = fmt.Sprintf("Emails of %s from %s", title, path)
+		}
+		group.Feed.Items = append(group.Feed.Items, m.email.parseEmailToItem(msg))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.cache[path] = &mailArchiveCacheEntry{stamp: stamp, groups: groups, lastUsed: time.Now()}
+	m.mu.Unlock()
+	return groups, nil
+}
+
+// parseArchiveMessage converts a raw message to an IMAP message holding its envelope
+// and entire body, and returns the key and title of its group. Messages without
+// Message-Id are identified by a hash of their content.
+func parseArchiveMessage(raw []byte) (msg *imap.Message, key, title string, err error) {
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	envelope := &imap.Envelope{
+		Subject:   strings.TrimSpace(DecodeHeader(m.Header.Get("Subject"))),
+		MessageId: strings.Trim(strings.TrimSpace(m.Header.Get("Message-Id")), "<>"),
+	}
+	if envelope.MessageId == "" {
+		sum := sha256.Sum256(raw)
+		envelope.MessageId = hex.EncodeToString(sum[:16])
+	}
+	if date, err := m.Header.Date(); err == nil {
+		envelope.Date = date
+	}
+	if from, err := m.Header.AddressList("From"); err == nil {
+		for _, address := range from {
+			mailbox, host, _ := strings.Cut(address.Address, "@")
+			envelope.From = append(envelope.From, &imap.Address{
+				PersonalName: address.Name,
+				MailboxName:  mailbox,
+				HostName:     host,
+			})
+		}
+	}
+
+	msg = &imap.Message{
+		Envelope: envelope,
+		Body:     map[*imap.BodySectionName]imap.Literal{{}: bytes.NewReader(raw)},
+	}
+
+	if listID, listName := ParseListID(m.Header.Get("List-Id")); listID != "" {
+		if listName == "" {
+			listName = listID
+		}
+		return msg, "list:" + listID, listName, nil
+	}
+	if len(envelope.From) == 0 {
+		return msg, "from:", "Unknown sender", nil
+	}
+	sender := envelope.From[0]
+	title = sender.PersonalName
+	if title == "" {
+		title = sender.Address()
+	}
+	return msg, "from:" + strings.ToLower(sender.Address()), title, nil
+}
+
+// isGroupAddress reports whether the title of a group is its List-Id or sender address
+func isGroupAddress(key, title string) bool {
+	_, address, _ := strings.Cut(key, ":")
+	return strings.EqualFold(address, title)
+}
+
+// walkMailArchive calls fn with each message of an mbox file, Maildir, .eml file or
+// directory of them. The data passed to fn is only valid during the call.
+func walkMailArchive(ctx context.Context, root string, fn func(raw []byte) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if (name == "cur" || name == "new" || name == "tmp") && isMaildir(filepath.Dir(path)) {
+				return filepath.SkipDir
+			}
+			if isMaildir(path) {
+				return readMaildir(ctx, path, fn)
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(path), ".eml") {
+			data, err := readArchiveFile(path)
+			if err != nil || data == nil {
+				return err
+			}
+			return fn(data)
+		}
+		if isMbox(path) {
+			return readMbox(ctx, path, fn)
+		}
+		return nil
+	})
+}
+
+// isMaildir reports whether a directory is a Maildir
+func isMaildir(dir string) bool {
+	for _, sub := range []string{"cur", "new"} {
+		if fi, err := os.Stat(filepath.Join(dir, sub)); err != nil || !fi.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// isMbox reports whether a file starts with the "From " line of an mbox message
+func isMbox(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	header := make([]byte, 5)
+	_, err = io.ReadFull(f, header)
+	return err == nil && string(header) == "From "
+}
+
+// readMaildir calls fn with the messages of the new and cur folders of a Maildir
+func readMaildir(ctx context.Context, dir string, fn func(raw []byte) error) error {
+	for _, sub := range []string{"new", "cur"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			data, err := readArchiveFile(filepath.Join(dir, sub, entry.Name()))
+			if err != nil {
+				return err
+			}
+			if data == nil {
+				continue
+			}
+			if err := fn(data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readArchiveFile reads a message file, returning nil if it is too large
+func readArchiveFile(path string) ([]byte, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size() > maxArchiveMessageSize {
+		return nil, nil
+	}
+	return os.ReadFile(path)
+}
+
+// readMbox calls fn with the messages of an mbox file. Messages start with a "From "
+// line following an empty line; quoted ">From " lines of the bodies are unquoted
+// (mboxrd).
+func readMbox(ctx context.Context, path string, fn func(raw []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var msg bytes.Buffer
+	inMessage, tooLarge := false, false
+	flush := func() error {
+		if !inMessage || tooLarge {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(msg.Bytes())
+	}
+
+	r := bufio.NewReaderSize(f, 64*1024)
+	afterBlank := true
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			if afterBlank && bytes.HasPrefix(line, []byte("From ")) {
+				if err := flush(); err != nil {
+					return err
+				}
+				msg.Reset()
+				inMessage, tooLarge = true, false
+			} else if inMessage && !tooLarge {
+				if unquoted := bytes.TrimLeft(line, ">"); len(unquoted) < len(line) && bytes.HasPrefix(unquoted, []byte("From ")) {
+					line = line[1:]
+				}
+				msg.Write(line)
+				tooLarge = msg.Len() > maxArchiveMessageSize
+			}
+			afterBlank = len(bytes.TrimRight(line, "\r\n")) == 0
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return flush()
+}
+
+// mailArchiveStamp summarizes the size and modification times of the files of an
+// archive, changing whenever messages are added or removed
+func mailArchiveStamp(root string) (string, error) {
+	var files, size int64
+	var latest time.Time
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files++
+		size += fi.Size()
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d/%d/%d", files, size, latest.UnixNano()), nil
+}
+
+// MailArchiveURL returns the feed URL of a group of the messages of an archive.
+// Watched archives are read again on each refresh, others are imported once.
+func MailArchiveURL(path, group string, watch bool) string {
+	p := filepath.ToSlash(path)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	u := url.URL{Scheme: "mailarchive", Path: p}
+	query := url.Values{}
+	if group != "" {
+		query.Set("group", group)
+	}
+	if watch {
+		query.Set("watch", "1")
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// ParseMailArchiveURL returns the path, group and watch flag of a feed URL built by
+// MailArchiveURL
+func ParseMailArchiveURL(rawURL string) (path, group string, watch bool, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", false, err
+	}
+	if u.Scheme != "mailarchive" || u.Path == "" {
+		return "", "", false, fmt.Errorf("not a mail archive URL: %s", rawURL)
+	}
+	p := u.Path
+	// Windows paths, e.g. /C:/Mail/archive.mbox
+	if len(p) > 2 && p[2] == ':' {
+		p = p[1:]
+	}
+	query := u.Query()
+	return filepath.FromSlash(p), query.Get("group"), query.Get("watch") == "1", nil
+}
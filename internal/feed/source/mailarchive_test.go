@@ -0,0 +1,110 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const archiveTestMbox = "From news@weekly.example Mon Jan  2 15:04:05 2006\n" +
+	"From: \"Weekly\" <news@weekly.example>\n" +
+	"Subject: Issue 1\n" +
+	"Message-Id: <1@weekly.example>\n" +
+	"Date: Mon, 02 Jan 2006 15:04:05 +0000\n" +
+	"List-Id: \"Weekly digest\" <weekly.example>\n" +
+	"Content-Type: text/html; charset=utf-8\n" +
+	"\n" +
+	"<p>First</p>\n" +
+	">From the archives\n" +
+	"\n" +
+	"From writer@blog.example Tue Jan  3 15:04:05 2006\n" +
+	"From: Writer <Writer@Blog.example>\n" +
+	"Subject: Hello\n" +
+	"Date: Tue, 03 Jan 2006 15:04:05 +0000\n" +
+	"\n" +
+	"Plain text\n"
+
+const archiveTestMaildirMessage = "From: \"Weekly\" <news@weekly.example>\r\n" +
+	"Subject: Issue 2\r\n" +
+	"Message-Id: <2@weekly.example>\r\n" +
+	"Date: Mon, 09 Jan 2006 15:04:05 +0000\r\n" +
+	"List-Id: <Weekly.Example>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/alternative; boundary=b1\r\n" +
+	"\r\n" +
+	"--b1\r\nContent-Type: text/plain\r\n\r\nplain\r\n" +
+	"--b1\r\nContent-Type: text/html\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n<p>Second=21</p>\r\n" +
+	"--b1--\r\n"
+
+func writeArchiveFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMailArchiveSource_FetchGroups(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveFile(t, filepath.Join(dir, "export.mbox"), archiveTestMbox)
+	// The first issue is also in the Maildir, it must be imported once
+	first := strings.SplitN(archiveTestMbox, "\n\nFrom writer", 2)[0]
+	writeArchiveFile(t, filepath.Join(dir, "maildir", "cur", "1:2,S"), strings.Replace(strings.SplitN(first, "\n", 2)[1], ">From", "From", 1))
+	writeArchiveFile(t, filepath.Join(dir, "maildir", "new", "2"), archiveTestMaildirMessage)
+	os.MkdirAll(filepath.Join(dir, "maildir", "tmp"), 0o755)
+	writeArchiveFile(t, filepath.Join(dir, "notes.txt"), "not a mailbox")
+
+	src := NewMailArchiveSource()
+	groups, err := src.FetchGroups(context.Background(), ConfigFromMailArchive(dir, ""))
+	if err != nil {
+		t.Fatalf("FetchGroups: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("expected the list and the sender groups, got %d", len(groups))
+	}
+
+	weekly, writer := groups[0], groups[1]
+	if weekly.Key != "list:weekly.example" || weekly.Title != "Weekly digest" || len(weekly.Feed.Items) != 2 {
+		t.Fatalf("unexpected list group %q %q with %d items", weekly.Key, weekly.Title, len(weekly.Feed.Items))
+	}
+	descriptions := map[string]string{}
+	for _, item := range weekly.Feed.Items {
+		descriptions[item.GUID] = item.Description
+	}
+	if !strings.Contains(descriptions["email-1@weekly.example"], "From the archives") ||
+		strings.Contains(descriptions["email-1@weekly.example"], ">From") {
+		t.Errorf("mbox quoting not removed: %q", descriptions["email-1@weekly.example"])
+	}
+	if descriptions["email-2@weekly.example"] != "<p>Second!</p>" {
+		t.Errorf("Maildir message description = %q", descriptions["email-2@weekly.example"])
+	}
+
+	if writer.Key != "from:writer@blog.example" || writer.Title != "Writer" || len(writer.Feed.Items) != 1 {
+		t.Fatalf("unexpected sender group %q %q with %d items", writer.Key, writer.Title, len(writer.Feed.Items))
+	}
+	item := writer.Feed.Items[0]
+	if !strings.HasPrefix(item.GUID, "email-") || item.PublishedParsed == nil || item.PublishedParsed.Day() != 3 {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	// Reading the archive again gives the same identifiers
+	feed, err := NewMailArchiveSource().Fetch(context.Background(), ConfigFromMailArchive(dir, writer.Key))
+	if err != nil || len(feed.Items) != 1 || feed.Items[0].GUID != item.GUID {
+		t.Errorf("Fetch of %s = %+v (%v)", writer.Key, feed, err)
+	}
+}
+
+func TestMailArchiveURL(t *testing.T) {
+	rawURL := MailArchiveURL("/home/me/Mail Export/news.mbox", "list:weekly.example", true)
+	path, group, watch, err := ParseMailArchiveURL(rawURL)
+	if err != nil || path != filepath.FromSlash("/home/me/Mail Export/news.mbox") || group != "list:weekly.example" || !watch {
+		t.Errorf("ParseMailArchiveURL(%q) = %q, %q, %v, %v", rawURL, path, group, watch, err)
+	}
+	if _, _, _, err := ParseMailArchiveURL("https://example.com/feed"); err == nil {
+		t.Error("expected an error for a non mail archive URL")
+	}
+}
@@ -17,6 +17,13 @@ type Manager struct {
 	xpath  *XPathSource
 	email  *EmailSource
 
+	jsonFeed *JSONFeedSource
+	hFeed    *HFeedSource
+
+	mailArchive *MailArchiveSource
+	iCalendar   *ICalendarSource
+	jsonPath    *JSONPathSource
+
 	mu sync.RWMutex
 }
 
@@ -27,6 +34,13 @@ func NewManager(scriptsDir string) *Manager {
 		script: NewScriptSource(scriptsDir),
 		xpath:  NewXPathSource(),
 		email:  NewEmailSource(),
+
+		jsonFeed: NewJSONFeedSource(),
+		hFeed:    NewHFeedSource(),
+
+		mailArchive: NewMailArchiveSource(),
+		iCalendar:   NewICalendarSource(),
+		jsonPath:    NewJSONPathSource(),
 	}
 }
 
@@ -44,6 +58,16 @@ func (m *Manager) GetSource(sourceType Type) (Source, error) {
 		return m.xpath, nil
 	case TypeEmail:
 		return m.email, nil
+	case TypeJSONFeed:
+		return m.jsonFeed, nil
+	case TypeHFeed:
+		return m.hFeed, nil
+	case TypeMailArchive:
+		return m.mailArchive, nil
+	case TypeICalendar:
+		return m.iCalendar, nil
+	case TypeJSONPath:
+		return m.jsonPath, nil
 	default:
 		return nil, fmt.Errorf("unknown source type: %s", sourceType)
 	}
@@ -81,18 +105,31 @@ func (m *Manager) detectSourceType(config *Config) Type {
 	if config.XPathItemSelector != "" {
 		return TypeXPath
 	}
+	if config.JSONPathItems != "" {
+		return TypeJSONPath
+	}
+	if config.MailArchivePath != "" {
+		return TypeMailArchive
+	}
+	if IsICalendarURL(config.URL) {
+		return TypeICalendar
+	}
 
 	// Default to RSS
 	return TypeRSS
 }
 
-// SetHTTPClient sets the HTTP client for RSS and XPath sources.
+// SetHTTPClient sets the HTTP client for the HTTP based sources.
 func (m *Manager) SetHTTPClient(client *http.Client) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.rss.SetHTTPClient(client)
 	m.xpath.SetHTTPClient(client)
+	m.jsonFeed.SetHTTPClient(client)
+	m.hFeed.SetHTTPClient(client)
+	m.iCalendar.SetHTTPClient(client)
+	m.jsonPath.SetHTTPClient(client)
 }
 
 // Validate validates the configuration for the appropriate source.
@@ -146,3 +183,12 @@ func ConfigFromEmail(server string, port int, username, password, folder string)
 		SourceType:      TypeEmail,
 	}
 }
+
+// ConfigFromMailArchive creates a mail archive config.
+func ConfigFromMailArchive(path, group string) *Config {
+	return &Config{
+		MailArchivePath:  path,
+		MailArchiveGroup: group,
+		SourceType:       TypeMailArchive,
+	}
+}
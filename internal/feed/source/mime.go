@@ -0,0 +1,138 @@
+package source
+
+import (
+	"encoding/base64"
+	"errors"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// maxMIMEDepth bounds the nesting of multipart messages
+const maxMIMEDepth = 5
+
+// headerDecoder decodes RFC 2047 encoded words of any charset
+var headerDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// DecodeHeader decodes the encoded words of a header value, returning the value
+// unchanged if it can't be decoded.
+func DecodeHeader(value string) string {
+	decoded, err := headerDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// MessageBody returns the HTML body of a MIME message or part with the given
+// Content-Type and Content-Transfer-Encoding. The HTML alternative of multipart
+// messages is preferred, plain text is converted to HTML and attachments are skipped.
+func MessageBody(contentType, transferEncoding string, r io.Reader) (string, error) {
+	return messageBody(contentType, transferEncoding, r, 0)
+}
+
+func messageBody(contentType, transferEncoding string, r io.Reader, depth int) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxMIMEDepth || params["boundary"] == "" {
+			return "", errors.New("invalid multipart message")
+		}
+		var htmlBody, textBody string
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+			if strings.HasPrefix(part.Header.Get("Content-Disposition"), "attachment") {
+				continue
+			}
+			partType := part.Header.Get("Content-Type")
+			body, err := messageBody(partType, part.Header.Get("Content-Transfer-Encoding"), part, depth+1)
+			if err != nil || strings.TrimSpace(body) == "" {
+				continue
+			}
+			// Nested multiparts and HTML parts are rendered as HTML
+			if !strings.HasPrefix(strings.ToLower(partType), "text/plain") && partType != "" {
+				if htmlBody == "" {
+					htmlBody = body
+				}
+			} else if textBody == "" {
+				textBody = body
+			}
+		}
+		if htmlBody != "" {
+			return htmlBody, nil
+		}
+		return textBody, nil
+	}
+
+	if mediaType != "text/html" && mediaType != "text/plain" {
+		return "", nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(transferEncoding)) {
+	case "quoted-printable":
+		r = quotedprintable.NewReader(r)
+	case "base64":
+		r = base64.NewDecoder(base64.StdEncoding, &newlineSkipper{r: r})
+	}
+	if cs := params["charset"]; cs != "" && !strings.EqualFold(cs, "utf-8") && !strings.EqualFold(cs, "us-ascii") {
+		if cr, err := charset.NewReaderLabel(cs, r); err == nil {
+			r = cr
+		}
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	if mediaType == "text/plain" {
+		text := html.EscapeString(strings.ReplaceAll(string(data), "\r\n", "\n"))
+		return "<p>" + strings.ReplaceAll(text, "\n", "<br>\n") + "</p>", nil
+	}
+	return string(data), nil
+}
+
+// newlineSkipper drops the line breaks of base64 encoded parts
+type newlineSkipper struct {
+	r io.Reader
+}
+
+func (s *newlineSkipper) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	j := 0
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' {
+			p[j] = b
+			j++
+		}
+	}
+	return j, err
+}
+
+// ParseListID parses a List-Id header (RFC 2919), e.g. `"Weekly digest" <weekly.example.com>`,
+// into the lowercased list identifier and its phrase
+func ParseListID(value string) (id, name string) {
+	value = strings.TrimSpace(value)
+	start := strings.LastIndexByte(value, '<')
+	end := strings.LastIndexByte(value, '>')
+	if start < 0 || end < start {
+		return strings.ToLower(value), ""
+	}
+	id = strings.ToLower(strings.TrimSpace(value[start+1 : end]))
+	name = DecodeHeader(strings.Trim(strings.TrimSpace(value[:start]), `"`))
+	return id, strings.TrimSpace(name)
+}
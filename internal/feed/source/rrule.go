@@ -0,0 +1,331 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRecurrencePeriods bounds the expansion of a recurrence rule, e.g. about 27 years
+// of a daily event
+const maxRecurrencePeriods = 10000
+
+// weekdayNum is a BYDAY value like MO, 2TU or -1FR
+type weekdayNum struct {
+	n   int // Ordinal within the month, 0 for every such weekday
+	day time.Weekday
+}
+
+// recurrenceRule is a RRULE (RFC 5545 section 3.3.10). Only the DAILY, WEEKLY, MONTHLY
+// and YEARLY frequencies are supported, with INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY,
+// BYMONTH and BYSETPOS.
+type recurrenceRule struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time
+	byDay      []weekdayNum
+	byMonthDay []int
+	byMonth    []time.Month
+	bySetPos   []int
+}
+
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseRecurrenceRule parses a RRULE value, reading a floating UNTIL in loc
+func parseRecurrenceRule(value string, loc *time.Location) (*recurrenceRule, error) {
+	if value == "" {
+		return nil, errors.New("empty recurrence rule")
+	}
+	r := &recurrenceRule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, _ := strings.Cut(part, "=")
+		key, val = strings.ToUpper(strings.TrimSpace(key)), strings.ToUpper(strings.TrimSpace(val))
+		var err error
+		switch key {
+		case "FREQ":
+			r.freq = val
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(val)
+			if err == nil && r.interval < 1 {
+				err = errors.New("interval must be positive")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(val)
+		case "UNTIL":
+			var allDay bool
+			r.until, allDay, err = parseICalTime(val, nil, loc)
+			if err == nil && allDay {
+				// UNTIL is inclusive
+				r.until = r.until.AddDate(0, 0, 1).Add(-time.Second)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				var wd weekdayNum
+				wd, err = parseWeekdayNum(day)
+				if err != nil {
+					break
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseIntList(val, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseIntList(val, 12)
+			for _, m := range months {
+				if m < 1 {
+					err = fmt.Errorf("invalid month %d", m)
+				}
+				r.byMonth = append(r.byMonth, time.Month(m))
+			}
+		case "BYSETPOS":
+			r.bySetPos, err = parseIntList(val, 366)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in recurrence rule: %w", key, err)
+		}
+	}
+
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+		return r, nil
+	default:
+		return nil, fmt.Errorf("unsupported recurrence frequency %q", r.freq)
+	}
+}
+
+func parseWeekdayNum(value string) (weekdayNum, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return weekdayNum{}, fmt.Errorf("invalid weekday %q", value)
+	}
+	day, ok := icalWeekdays[value[len(value)-2:]]
+	if !ok {
+		return weekdayNum{}, fmt.Errorf("invalid weekday %q", value)
+	}
+	wd := weekdayNum{day: day}
+	if n := value[:len(value)-2]; n != "" {
+		var err error
+		if wd.n, err = strconv.Atoi(n); err != nil {
+			return weekdayNum{}, fmt.Errorf("invalid weekday %q", value)
+		}
+	}
+	return wd, nil
+}
+
+// parseIntList parses a comma-separated list of non-zero integers within [-max, max]
+func parseIntList(value string, max int) ([]int, error) {
+	var list []int
+	for _, s := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 || n > max || n < -max {
+			return nil, fmt.Errorf("value %d out of range", n)
+		}
+		list = append(list, n)
+	}
+	return list, nil
+}
+
+// occurrences returns the occurrences of the rule for an event starting at dtstart
+// that fall between from and to. COUNT counts the occurrences before from as well.
+func (r *recurrenceRule) occurrences(dtstart, from, to time.Time) []time.Time {
+	var result []time.Time
+	n := 0
+	for i := 0; i < maxRecurrencePeriods; i++ {
+		periodStart, candidates := r.period(dtstart, i)
+		if periodStart.After(to) {
+			break
+		}
+		for _, t := range candidates {
+			if t.Before(dtstart) {
+				continue
+			}
+			if (!r.until.IsZero() && t.After(r.until)) || t.After(to) {
+				return result
+			}
+			n++
+			if !t.Before(from) {
+				result = append(result, t)
+			}
+			if r.count > 0 && n >= r.count {
+				return result
+			}
+		}
+	}
+	return result
+}
+
+// period returns the start of the i-th period (day, week, month or year) of the rule
+// and its occurrences, sorted
+func (r *recurrenceRule) period(dtstart time.Time, i int) (time.Time, []time.Time) {
+	step := i * r.interval
+	var start time.Time
+	var candidates []time.Time
+
+	switch r.freq {
+	case "DAILY":
+		start = dtstart.AddDate(0, 0, step)
+		if r.matchesMonth(start) && r.matchesMonthDay(start) && r.matchesWeekday(start) {
+			candidates = []time.Time{start}
+		}
+	case "WEEKLY":
+		// Weeks start on Monday
+		start = dtstart.AddDate(0, 0, 7*step-(int(dtstart.Weekday())+6)%7)
+		if len(r.byDay) == 0 {
+			candidates = []time.Time{dtstart.AddDate(0, 0, 7*step)}
+		}
+		for _, wd := range r.byDay {
+			candidates = append(candidates, start.AddDate(0, 0, (int(wd.day)+6)%7))
+		}
+		candidates = filterTimes(candidates, r.matchesMonth)
+	case "MONTHLY":
+		start = time.Date(dtstart.Year(), dtstart.Month()+time.Month(step), 1,
+			dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+		if r.matchesMonth(start) {
+			candidates = r.monthDays(start, dtstart)
+		}
+	case "YEARLY":
+		start = time.Date(dtstart.Year()+step, time.January, 1,
+			dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+		months := r.byMonth
+		if len(months) == 0 {
+			months = []time.Month{dtstart.Month()}
+		}
+		for _, month := range months {
+			first := time.Date(start.Year(), month, 1, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+			candidates = append(candidates, r.monthDays(first, dtstart)...)
+		}
+	}
+
+	sort.Slice(candidates, func(a, b int) bool { return candidates[a].Before(candidates[b]) })
+	return start, r.applySetPos(candidates)
+}
+
+// monthDays returns the occurrences within the month starting at first
+func (r *recurrenceRule) monthDays(first, dtstart time.Time) []time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	day := func(d int) time.Time {
+		return time.Date(first.Year(), first.Month(), d, first.Hour(), first.Minute(), first.Second(), 0, first.Location())
+	}
+
+	var days []int
+	switch {
+	case len(r.byMonthDay) > 0:
+		for _, d := range r.byMonthDay {
+			if d < 0 {
+				d = last + 1 + d
+			}
+			if d >= 1 && d <= last {
+				days = append(days, d)
+			}
+		}
+	case len(r.byDay) > 0:
+		for _, wd := range r.byDay {
+			// Days of the month with this weekday
+			var matching []int
+			for d := 1 + (int(wd.day)-int(first.Weekday())+7)%7; d <= last; d += 7 {
+				matching = append(matching, d)
+			}
+			switch {
+			case wd.n == 0:
+				days = append(days, matching...)
+			case wd.n > 0 && wd.n <= len(matching):
+				days = append(days, matching[wd.n-1])
+			case wd.n < 0 && -wd.n <= len(matching):
+				days = append(days, matching[len(matching)+wd.n])
+			}
+		}
+	default:
+		if dtstart.Day() <= last {
+			days = append(days, dtstart.Day())
+		}
+	}
+
+	seen := make(map[int]bool)
+	var result []time.Time
+	for _, d := range days {
+		t := day(d)
+		// BYDAY restricts BYMONTHDAY when both are set
+		if seen[d] || (len(r.byMonthDay) > 0 && !r.matchesWeekday(t)) {
+			continue
+		}
+		seen[d] = true
+		result = append(result, t)
+	}
+	return result
+}
+
+// applySetPos keeps the BYSETPOS positions of the sorted occurrences of a period
+func (r *recurrenceRule) applySetPos(candidates []time.Time) []time.Time {
+	if len(r.bySetPos) == 0 {
+		return candidates
+	}
+	var result []time.Time
+	for _, pos := range r.bySetPos {
+		switch {
+		case pos > 0 && pos <= len(candidates):
+			result = append(result, candidates[pos-1])
+		case pos < 0 && -pos <= len(candidates):
+			result = append(result, candidates[len(candidates)+pos])
+		}
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a].Before(result[b]) })
+	return result
+}
+
+func (r *recurrenceRule) matchesMonth(t time.Time) bool {
+	if len(r.byMonth) == 0 {
+		return true
+	}
+	for _, m := range r.byMonth {
+		if t.Month() == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *recurrenceRule) matchesMonthDay(t time.Time) bool {
+	if len(r.byMonthDay) == 0 {
+		return true
+	}
+	last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	for _, d := range r.byMonthDay {
+		if d == t.Day() || last+1+d == t.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *recurrenceRule) matchesWeekday(t time.Time) bool {
+	if len(r.byDay) == 0 {
+		return true
+	}
+	for _, wd := range r.byDay {
+		if wd.day == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func filterTimes(times []time.Time, keep func(time.Time) bool) []time.Time {
+	result := times[:0]
+	for _, t := range times {
+		if keep(t) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
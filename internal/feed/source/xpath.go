@@ -16,7 +16,13 @@ import (
 	"github.com/mmcdole/gofeed"
 )
 
-const maxXPathRetries = 3
+const (
+	maxXPathRetries = 3
+	// maxXPathPages bounds the pages read per fetch, whatever the configuration
+	maxXPathPages = 20
+	// maxXPathItemPages bounds the item pages fetched per fetch
+	maxXPathItemPages = 30
+)
 
 // XPathSource fetches content from web pages using XPath/CSS selectors.
 type XPathSource struct {
@@ -63,11 +69,53 @@ func (x *XPathSource) SetHTTPClient(client *http.Client) {
 }
 
 // Fetch retrieves content from the URL and extracts items using selectors with retry support.
+// With a next page selector, the next pages are read up to MaxPages or the first page with a
+// known item; with item page selectors, new items are completed from their own pages.
 func (x *XPathSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed, error) {
 	if err := x.Validate(config); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
+	maxPages := 1
+	if config.XPathNextSelector != "" {
+		maxPages = min(max(config.MaxPages, 1), maxXPathPages)
+	}
+
+	var feed *gofeed.Feed
+	pageURL := config.URL
+	visited := map[string]bool{}
+	for page := 0; page < maxPages && pageURL != "" && !visited[pageURL]; page++ {
+		visited[pageURL] = true
+		doc, err := x.fetchPage(ctx, config, pageURL)
+		if err != nil {
+			if page > 0 {
+				// Keep the items of the pages already read
+				log.Printf("[XPathSource] Stopping at page %d of %s: %v", page+1, config.URL, err)
+				break
+			}
+			return nil, err
+		}
+
+		pageFeed := x.extractFeed(doc, config, pageURL)
+		if feed == nil {
+			feed = pageFeed
+		} else {
+			feed.Items = append(feed.Items, pageFeed.Items...)
+		}
+		if x.hasKnownItem(pageFeed.Items, config.XPathKnownLinks) {
+			break
+		}
+		pageURL = x.nextPage(doc, config.XPathNextSelector, pageURL)
+	}
+
+	if config.XPathDetailContentSelector != "" || config.XPathDetailDateSelector != "" {
+		feed.Items = x.fetchItemPages(ctx, config, feed.Items)
+	}
+	return feed, nil
+}
+
+// fetchPage fetches and parses a page with retry support
+func (x *XPathSource) fetchPage(ctx context.Context, config *Config, pageURL string) (*goquery.Document, error) {
 	var lastErr error
 	for attempt := 0; attempt < maxXPathRetries; attempt++ {
 		select {
@@ -76,9 +124,9 @@ func (x *XPathSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed,
 		default:
 		}
 
-		feed, err := x.doFetch(ctx, config)
+		doc, err := x.doFetch(ctx, config, pageURL)
 		if err == nil {
-			return feed, nil
+			return doc, nil
 		}
 
 		lastErr = err
@@ -87,7 +135,7 @@ func (x *XPathSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed,
 		if httputil.IsNetworkError(errStr) && attempt < maxXPathRetries-1 {
 			backoff := httputil.CalculateBackoffSimple(attempt)
 			log.Printf("[XPathSource] Network error on attempt %d/%d for %s, retrying in %v: %v",
-				attempt+1, maxXPathRetries, config.URL, backoff, err)
+				attempt+1, maxXPathRetries, pageURL, backoff, err)
 			select {
 			case <-ctx.Done():
 				return nil, ctx.Err()
@@ -102,9 +150,9 @@ func (x *XPathSource) Fetch(ctx context.Context, config *Config) (*gofeed.Feed,
 	return nil, fmt.Errorf("all %d attempts failed, last error: %w", maxXPathRetries, lastErr)
 }
 
-// doFetch performs the actual fetch without retries
-func (x *XPathSource) doFetch(ctx context.Context, config *Config) (*gofeed.Feed, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", config.URL, nil)
+// doFetch performs the actual fetch of a page without retries
+func (x *XPathSource) doFetch(ctx context.Context, config *Config, pageURL string) (*goquery.Document, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -129,13 +177,11 @@ func (x *XPathSource) doFetch(ctx context.Context, config *Config) (*gofeed.Feed
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
-
-	feed := x.extractFeed(doc, config)
-	return feed, nil
+	return doc, nil
 }
 
-// extractFeed extracts feed items from the HTML document.
-func (x *XPathSource) extractFeed(doc *goquery.Document, config *Config) *gofeed.Feed {
+// extractFeed extracts feed items from the HTML document of a page.
+func (x *XPathSource) extractFeed(doc *goquery.Document, config *Config, pageURL string) *gofeed.Feed {
 	feed := &gofeed.Feed{
 		Title:       x.extractText(doc, config.XPathTitleSelector),
 		Description: x.extractText(doc, config.XPathDescSelector),
@@ -157,10 +203,10 @@ func (x *XPathSource) extractFeed(doc *goquery.Document, config *Config) *gofeed
 		// Extract link
 		if config.XPathItemLinkSelector != "" {
 			link, _ := s.Find(config.XPathItemLinkSelector).Attr("href")
-			item.Link = x.resolveURL(config.URL, link)
+			item.Link = x.resolveURL(pageURL, link)
 		} else {
 			link, _ := s.Find("a").First().Attr("href")
-			item.Link = x.resolveURL(config.URL, link)
+			item.Link = x.resolveURL(pageURL, link)
 		}
 
 		// Extract content
@@ -187,6 +233,70 @@ func (x *XPathSource) extractFeed(doc *goquery.Document, config *Config) *gofeed
 	return feed
 }
 
+// hasKnownItem reports whether one of the items was already read
+func (x *XPathSource) hasKnownItem(items []*gofeed.Item, known map[string]bool) bool {
+	for _, item := range items {
+		if item.Link != "" && known[item.Link] {
+			return true
+		}
+	}
+	return false
+}
+
+// nextPage returns the URL of the next page linked from a page, or "" if there is none
+func (x *XPathSource) nextPage(doc *goquery.Document, selector, pageURL string) string {
+	if selector == "" {
+		return ""
+	}
+	next := doc.Find(selector).First()
+	href, ok := next.Attr("href")
+	if !ok {
+		href = next.Text()
+	}
+	return resolveReference(pageURL, strings.TrimSpace(href))
+}
+
+// fetchItemPages completes the new items with the content and date of their own pages.
+// Known items were read with their page before and are left out. The new items beyond
+// the item pages fetched per fetch keep what their list page has.
+func (x *XPathSource) fetchItemPages(ctx context.Context, config *Config, items []*gofeed.Item) []*gofeed.Item {
+	result := make([]*gofeed.Item, 0, len(items))
+	fetched := 0
+	for _, item := range items {
+		if config.XPathKnownLinks[item.Link] {
+			continue
+		}
+		if item.Link == "" {
+			result = append(result, item)
+			continue
+		}
+		if fetched >= maxXPathItemPages || ctx.Err() != nil {
+			result = append(result, item)
+			continue
+		}
+		fetched++
+		doc, err := x.doFetch(ctx, config, item.Link)
+		if err != nil {
+			log.Printf("[XPathSource] Failed to fetch the page of item %s: %v", item.Link, err)
+			result = append(result, item)
+			continue
+		}
+		if config.XPathDetailContentSelector != "" {
+			if content := doc.Find(config.XPathDetailContentSelector).First(); content.Length() > 0 {
+				item.Content, _ = content.Html()
+				item.Description = strings.TrimSpace(content.Text())
+			}
+		}
+		if config.XPathDetailDateSelector != "" {
+			if t := x.parseDate(strings.TrimSpace(doc.Find(config.XPathDetailDateSelector).First().Text())); t != nil {
+				item.PublishedParsed = t
+			}
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
 // extractText extracts text content using a selector.
 func (x *XPathSource) extractText(doc *goquery.Document, selector string) string {
 	if selector == "" {
@@ -0,0 +1,74 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestXPathSource_Pagination(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		switch {
+		case r.URL.Path == "/archive":
+			page := r.URL.Query().Get("page")
+			if page == "" {
+				page = "1"
+			}
+			fmt.Fprintf(w, `<ul><li><a href="/p/%s-a">%s A</a></li><li><a href="/p/%s-b">%s B</a></li></ul>`, page, page, page, page)
+			if page != "3" {
+				fmt.Fprintf(w, `<a class="next" href="?page=%c">Older</a>`, page[0]+1)
+			}
+		case strings.HasPrefix(r.URL.Path, "/p/"):
+			fmt.Fprintf(w, `<article><p>Text of %s</p></article><time>2026-01-0%c</time>`, r.URL.Path, r.URL.Path[3])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := &Config{
+		URL:                        server.URL + "/archive",
+		XPathItemSelector:          "li",
+		XPathNextSelector:          "a.next",
+		XPathDetailContentSelector: "article",
+		XPathDetailDateSelector:    "time",
+		MaxPages:                   2,
+	}
+	feed, err := NewXPathSource().Fetch(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The page limit stops before the third page
+	if len(feed.Items) != 4 || feed.Items[3].Title != "2 B" {
+		t.Fatalf("expected the items of 2 pages, got %d items (requests %v)", len(feed.Items), requests)
+	}
+	first := feed.Items[0]
+	if first.Link != server.URL+"/p/1-a" || first.Content != "<p>Text of /p/1-a</p>" {
+		t.Errorf("unexpected first item %+v", first)
+	}
+	if first.PublishedParsed == nil || first.PublishedParsed.Day() != 1 || feed.Items[2].PublishedParsed.Day() != 2 {
+		t.Errorf("unexpected item page dates %v %v", first.PublishedParsed, feed.Items[2].PublishedParsed)
+	}
+
+	// Known items stop the pagination and aren't read again
+	requests = nil
+	config.MaxPages = 3
+	config.XPathKnownLinks = map[string]bool{server.URL + "/p/2-b": true}
+	feed, err = NewXPathSource().Fetch(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.Items) != 3 || feed.Items[2].Title != "2 A" {
+		t.Fatalf("expected the 3 new items, got %d", len(feed.Items))
+	}
+	for _, request := range requests {
+		if request == "/archive?page=3" || request == "/p/2-b" {
+			t.Errorf("unexpected request %s", request)
+		}
+	}
+}
@@ -2,14 +2,17 @@ package feed
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
 	"time"
 
+	"MavenRSS/internal/feed/source"
 	"MavenRSS/internal/models"
 	"MavenRSS/internal/rsshub"
 	"MavenRSS/internal/utils"
@@ -183,6 +186,25 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feed *models.Feed, f
 		req.Header.Set("If-Modified-Since", feed.LastModified)
 	}
 
+	// Track the redirects of the feed's own URL, on a copy of the shared client,
+	// to move the feed when all of them are permanent
+	var redirects []int
+	if feed.ID > 0 && feedURL == feed.URL {
+		tracking := *httpClient
+		checkRedirect := httpClient.CheckRedirect
+		tracking.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			redirects = append(redirects, req.Response.StatusCode)
+			if checkRedirect != nil {
+				return checkRedirect(req, via)
+			}
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return nil
+		}
+		httpClient = &tracking
+	}
+
 	debugTimer.LogWithTime("Sending HTTP request to %s", feedURL)
 	resp, err := httpClient.Do(req)
 	if err != nil {
@@ -192,11 +214,31 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feed *models.Feed, f
 	defer resp.Body.Close()
 	debugTimer.Stage("HTTP request completed")
 
+	stats := fetchStatsFrom(ctx)
+	stats.setResponse(resp.StatusCode, resp.StatusCode == http.StatusNotModified)
+	if stats != nil {
+		stats.setProxy(f.feedProxyURL(*feed))
+	}
+	if len(redirects) > 0 {
+		permanent := true
+		for _, status := range redirects {
+			permanent = permanent && isPermanentRedirect(status)
+		}
+		if permanent {
+			stats.setMoved(resp.Request.URL.String(), redirects[0])
+		}
+	}
+
 	if resp.StatusCode == http.StatusNotModified {
 		debugTimer.LogWithTime("HTTP 304 Not Modified")
 		return "", ErrNotModified
 	}
 
+	if rateLimitErr := newRateLimitError(resp, httputil.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())); rateLimitErr != nil {
+		debugTimer.LogWithTime("HTTP %d, retry after %v", resp.StatusCode, rateLimitErr.RetryAfter)
+		return "", rateLimitErr
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		debugTimer.LogWithTime("HTTP status not OK: %d", resp.StatusCode)
 		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
@@ -226,6 +268,7 @@ func (f *Fetcher) fetchAndSanitizeFeed(ctx context.Context, feed *models.Feed, f
 		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
 	debugTimer.LogWithTime("Read %d bytes from response", len(body))
+	stats.setBytes(len(body))
 	debugTimer.Stage("Body read complete")
 
 	xmlContent := string(body)
@@ -586,6 +629,9 @@ func (f *Fetcher) ParseFeedWithUserID(ctx context.Context, url string, userID in
 // ParseFeedWithScript parses an RSS feed, using a custom script or XPath if specified.
 // If scriptPath is non-empty, it executes the script.
 // If feed.Type is "HTML+XPath" or "XML+XPath", it uses XPath parsing.
+// If feed.Type is "JSONFeed", "HFeed" or "JSON+JSONPath", it uses the matching source.
+// If feed.Type is "MailArchive", it reads the local mbox/Maildir archive.
+// If feed.Type is "ICalendar" or the URL is a webcal:// or .ics one, it uses the iCalendar source.
 // Otherwise, it fetches from the URL as normal.
 // priority: true for high-priority requests (like article content fetching), false for normal requests (like feed refresh)
 func (f *Fetcher) ParseFeedWithScript(ctx context.Context, url string, scriptPath string, priority bool) (*gofeed.Feed, error) {
@@ -614,6 +660,11 @@ func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Fe
 			return nil, fmt.Errorf("email fetcher not initialized")
 		}
 
+		// Newsletters without IMAP mailbox are delivered by the mail receiver
+		if IsReceivedEmailFeed(*feed) {
+			return &gofeed.Feed{Title: feed.Title, Link: feed.URL, Description: feed.Description}, nil
+		}
+
 		// Fetch emails from IMAP
 		items, err := f.emailFetcher.FetchEmails(ctx, feed)
 		if err != nil {
@@ -649,6 +700,39 @@ func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Fe
 		return f.scriptExecutor.ExecuteScript(scriptCtx, feed.ScriptPath)
 	}
 
+	// Mail archives are read from the local file system
+	if feed.Type == models.FeedTypeMailArchive {
+		utils.DebugLog("parseFeedWithFeedInternal: Reading mail archive %s", feed.URL)
+		return f.parseMailArchive(ctx, feed)
+	}
+
+	// JSON Feed, h-feed pages, calendars and JSON APIs are handled by their dedicated sources
+	if feed.Type == models.FeedTypeJSONFeed || feed.Type == models.FeedTypeHFeed || isICalendarFeed(feed) ||
+		feed.Type == models.FeedTypeJSONPath {
+		utils.DebugLog("parseFeedWithFeedInternal: Using %s source for %s", feed.Type, feed.URL)
+		sourceCtx := ctx
+		if priority {
+			var cancel context.CancelFunc
+			sourceCtx, cancel = context.WithTimeout(ctx, 15*time.Second) // Shorter timeout for content fetching
+			defer cancel()
+		}
+
+		return f.parseFeedWithSource(sourceCtx, feed)
+	}
+
+	// Watched pages are compared with their last snapshot
+	if feed.Type == models.FeedTypeWatch {
+		utils.DebugLog("parseFeedWithFeedInternal: Watching %s for changes", feed.URL)
+		watchCtx := ctx
+		if priority {
+			var cancel context.CancelFunc
+			watchCtx, cancel = context.WithTimeout(ctx, 15*time.Second) // Shorter timeout for content fetching
+			defer cancel()
+		}
+
+		return f.parseWatchFeed(watchCtx, feed)
+	}
+
 	// Check if this is an XPath-based feed
 	if feed.Type == "HTML+XPath" || feed.Type == "XML+XPath" {
 		debugTimer.Stage("XPath parsing path")
@@ -710,11 +794,18 @@ func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Fe
 			utils.DebugLog("parseFeedWithFeedInternal: Successfully parsed sanitized feed for %s", actualURL)
 			// Fix Atom authors for feeds that use simple text format
 			fixFeedAuthors(parsedFeed, cleanedXML)
+			// Record the WebSub hub and self links, if any
+			discoverWebSubLinks(parsedFeed, cleanedXML)
 			return parsedFeed, nil
 		}
 		utils.DebugLog("parseFeedWithFeedInternal: Parsing sanitized feed failed: %v", err)
 		// Fall through to standard parsing
 	} else {
+		// Don't ask a host that told us to come back later again right away
+		var rateLimitErr *RateLimitError
+		if errors.As(sanitizeErr, &rateLimitErr) {
+			return nil, sanitizeErr
+		}
 		debugTimer.LogWithTime("Sanitization failed, will try standard parsing")
 		utils.DebugLog("parseFeedWithFeedInternal: Sanitization failed: %v", sanitizeErr)
 	}
@@ -781,47 +872,51 @@ func (f *Fetcher) parseFeedWithFeedInternal(ctx context.Context, feed *models.Fe
 	return parsedFeed, nil
 }
 
-// parseFeedWithXPath parses a feed using XPath expressions
-func (f *Fetcher) parseFeedWithXPath(_ context.Context, feed *models.Feed) (*gofeed.Feed, error) {
-	if feed.XPathItem == "" {
-		return nil, &XPathError{
-			Operation: "validate",
-			Details:   "XPath item expression is required for XPath-based feeds",
-		}
+// parseFeedWithSource fetches a JSON Feed, h-feed, iCalendar or JSON+JSONPath feed with its source.Source implementation
+func (f *Fetcher) parseFeedWithSource(ctx context.Context, feed *models.Feed) (*gofeed.Feed, error) {
+	var src interface {
+		source.Source
+		SetHTTPClient(*http.Client)
+	}
+	switch {
+	case feed.Type == models.FeedTypeJSONFeed:
+		src = source.NewJSONFeedSource()
+	case feed.Type == models.FeedTypeHFeed:
+		src = source.NewHFeedSource()
+	case isICalendarFeed(feed):
+		src = source.NewICalendarSource()
+	case feed.Type == models.FeedTypeJSONPath:
+		src = source.NewJSONPathSource()
+	default:
+		return nil, fmt.Errorf("unsupported feed type '%s'", feed.Type)
 	}
 
-	// Fetch the content with proper proxy configuration
-	httpClient, err := f.getHTTPClient(*feed)
-	if err != nil {
-		// Fallback to default client if getHTTPClient fails
-		httpClient = httputil.GetPooledHTTPClient("", 30*time.Second)
-	}
-	resp, err := httpClient.Get(feed.URL)
-	if err != nil {
-		return nil, &XPathError{
-			Operation: "fetch",
-			URL:       feed.URL,
-			Details:   "Failed to fetch content. Please check the URL and your network connection",
-			Err:       err,
-		}
+	// Use the feed's proxy settings
+	if client, err := f.getHTTPClient(*feed); err == nil {
+		src.SetHTTPClient(client)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return nil, &XPathError{
-			Operation: "fetch",
-			URL:       feed.URL,
-			Details:   fmt.Sprintf("HTTP %d: %s. The server may be unreachable or the page may have moved", resp.StatusCode, resp.Status),
+	config := &source.Config{URL: feed.URL}
+	if feed.Type == models.FeedTypeJSONPath {
+		config = jsonPathConfig(feed)
+	}
+	if auth := feed.Auth; auth != nil {
+		config.Headers = auth.Headers
+		config.UserAgent = feedAuthUserAgent(*feed, "")
+		if auth.Type == models.FeedAuthBasic {
+			config.BasicAuthUser = auth.Username
+			config.BasicAuthPassword = auth.Password
 		}
 	}
+	return src.Fetch(ctx, config)
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+// parseFeedWithXPath parses a feed using XPath expressions
+func (f *Fetcher) parseFeedWithXPath(ctx context.Context, feed *models.Feed) (*gofeed.Feed, error) {
+	if feed.XPathItem == "" {
 		return nil, &XPathError{
-			Operation: "fetch",
-			URL:       feed.URL,
-			Details:   "Failed to read response body",
-			Err:       err,
+			Operation: "validate",
+			Details:   "XPath item expression is required for XPath-based feeds",
 		}
 	}
 
@@ -833,66 +928,162 @@ func (f *Fetcher) parseFeedWithXPath(_ context.Context, feed *models.Feed) (*gof
 		Items:       make([]*gofeed.Item, 0),
 	}
 
+	// Archive-style sites have their older items on the next pages, which are read up
+	// to the page limit or the first page with items already read
+	known := make(map[string]bool)
+	visited := make(map[string]bool)
+	pageURL := feed.URL
+	for page := 0; page < xpathMaxPages(feed) && pageURL != "" && !visited[pageURL]; page++ {
+		visited[pageURL] = true
+		items, next, err := f.fetchXPathItems(ctx, feed, pageURL)
+		if err != nil {
+			if page > 0 {
+				// Keep the items of the pages already read
+				log.Printf("Stopping at page %d of XPath feed %s: %v", page+1, feed.URL, err)
+				break
+			}
+			return nil, err
+		}
+		parsedFeed.Items = append(parsedFeed.Items, items...)
+		if f.findKnownXPathItems(feed, items, known) {
+			break
+		}
+		pageURL = next
+	}
+
+	// New items are completed from their own pages
+	if feed.Pagination.HasDetails() {
+		parsedFeed.Items = f.fetchXPathItemPages(ctx, feed, parsedFeed.Items, known)
+	}
+
+	return parsedFeed, nil
+}
+
+// fetchXPathItems fetches a page of an XPath feed and returns its items and the URL of
+// the next page, if the feed has a next page expression
+func (f *Fetcher) fetchXPathItems(ctx context.Context, feed *models.Feed, pageURL string) ([]*gofeed.Item, string, error) {
+	body, err := f.fetchXPathPage(ctx, feed, pageURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Links of the items are relative to their page
+	pageFeed := *feed
+	pageFeed.URL = pageURL
+	var items []*gofeed.Item
+	var next string
+
 	// Parse based on type
 	switch feed.Type {
 	case "HTML+XPath":
 		doc, err := htmlquery.Parse(strings.NewReader(string(body)))
 		if err != nil {
-			return nil, &XPathError{
+			return nil, "", &XPathError{
 				Operation: "parse",
-				URL:       feed.URL,
+				URL:       pageURL,
 				Details:   "Failed to parse HTML. The page structure may have changed or the content may not be valid HTML",
 				Err:       err,
 			}
 		}
-		items := htmlquery.Find(doc, feed.XPathItem)
-		if len(items) == 0 {
-			return nil, &XPathError{
+		nodes := htmlquery.Find(doc, feed.XPathItem)
+		if len(nodes) == 0 {
+			return nil, "", &XPathError{
 				Operation: "extract",
-				URL:       feed.URL,
+				URL:       pageURL,
 				XPathExpr: feed.XPathItem,
 				Details:   "No items found. The Item XPath expression doesn't match any elements on the page. The page structure may have changed",
 			}
 		}
 
 		// Process HTML items
-		for _, item := range items {
-			gofeedItem := f.extractItemFromHTMLNode(item, feed)
-			parsedFeed.Items = append(parsedFeed.Items, gofeedItem)
+		for _, node := range nodes {
+			items = append(items, f.extractItemFromHTMLNode(node, &pageFeed))
 		}
+		next = nextHTMLXPathPage(doc, feed, pageURL)
 	case "XML+XPath":
 		doc, err := xmlquery.Parse(strings.NewReader(string(body)))
 		if err != nil {
-			return nil, &XPathError{
+			return nil, "", &XPathError{
 				Operation: "parse",
-				URL:       feed.URL,
+				URL:       pageURL,
 				Details:   "Failed to parse XML. The content may not be valid XML",
 				Err:       err,
 			}
 		}
-		items := xmlquery.Find(doc, feed.XPathItem)
-		if len(items) == 0 {
-			return nil, &XPathError{
+		nodes := xmlquery.Find(doc, feed.XPathItem)
+		if len(nodes) == 0 {
+			return nil, "", &XPathError{
 				Operation: "extract",
-				URL:       feed.URL,
+				URL:       pageURL,
 				XPathExpr: feed.XPathItem,
 				Details:   "No items found. The Item XPath expression doesn't match any elements in the XML. The structure may have changed",
 			}
 		}
 
 		// Process XML items
-		for _, item := range items {
-			gofeedItem := f.extractItemFromXMLNode(item, feed)
-			parsedFeed.Items = append(parsedFeed.Items, gofeedItem)
+		for _, node := range nodes {
+			items = append(items, f.extractItemFromXMLNode(node, &pageFeed))
 		}
+		next = nextXMLXPathPage(doc, feed, pageURL)
 	default:
-		return nil, &XPathError{
+		return nil, "", &XPathError{
 			Operation: "validate",
 			Details:   fmt.Sprintf("Unsupported feed type '%s'. Must be 'HTML+XPath' or 'XML+XPath'", feed.Type),
 		}
 	}
 
-	return parsedFeed, nil
+	return items, next, nil
+}
+
+// fetchXPathPage fetches a page of an XPath or watch feed with the feed's proxy and credentials
+func (f *Fetcher) fetchXPathPage(ctx context.Context, feed *models.Feed, pageURL string) ([]byte, error) {
+	httpClient, err := f.getHTTPClient(*feed)
+	if err != nil {
+		// Fallback to default client if getHTTPClient fails
+		httpClient = httputil.GetPooledHTTPClient("", 30*time.Second)
+	}
+	if err := f.checkRobots(ctx, httpClient, feed.UserID, pageURL); err != nil {
+		return nil, &XPathError{
+			Operation: "fetch",
+			URL:       pageURL,
+			Details:   "The site's robots.txt disallows scraping this page",
+			Err:       err,
+		}
+	}
+	resp, err := httpClient.Get(pageURL)
+	if err != nil {
+		return nil, &XPathError{
+			Operation: "fetch",
+			URL:       pageURL,
+			Details:   "Failed to fetch content. Please check the URL and your network connection",
+			Err:       err,
+		}
+	}
+	defer resp.Body.Close()
+
+	if rateLimitErr := newRateLimitError(resp, httputil.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())); rateLimitErr != nil {
+		return nil, rateLimitErr
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, &XPathError{
+			Operation: "fetch",
+			URL:       pageURL,
+			Details:   fmt.Sprintf("HTTP %d: %s. The server may be unreachable or the page may have moved", resp.StatusCode, resp.Status),
+		}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &XPathError{
+			Operation: "fetch",
+			URL:       pageURL,
+			Details:   "Failed to read response body",
+			Err:       err,
+		}
+	}
+
+	return body, nil
 }
 
 // extractItemFromHTMLNode extracts a gofeed.Item from an HTML node
@@ -990,47 +1181,7 @@ func (f *Fetcher) extractItemFromHTMLNode(item *html.Node, feed *models.Feed) *g
 	// Extract timestamp
 	if feed.XPathItemTimestamp != "" {
 		if timeNode := htmlquery.FindOne(item, feed.XPathItemTimestamp); timeNode != nil {
-			timeStr := strings.TrimSpace(htmlquery.InnerText(timeNode))
-			// Remove icon text if present (e.g., "calendar_month 2025-12" -> "2025-12")
-			if strings.Contains(timeStr, " ") {
-				parts := strings.Split(timeStr, " ")
-				// Find the date part (usually the last part that looks like a date)
-				for i := len(parts) - 1; i >= 0; i-- {
-					part := strings.TrimSpace(parts[i])
-					if part != "" && (strings.Contains(part, "-") || strings.Contains(part, "/") || len(part) >= 4) {
-						timeStr = part
-						break
-					}
-				}
-			}
-			if timeStr != "" {
-				var parsedTime time.Time
-				var err error
-				if feed.XPathItemTimeFormat != "" {
-					parsedTime, err = time.Parse(feed.XPathItemTimeFormat, timeStr)
-				} else {
-					// Try common formats
-					formats := []string{
-						time.RFC3339,
-						time.RFC1123,
-						"2006-01-02T15:04:05Z07:00",
-						"2006-01-02 15:04:05",
-						"2006-01-02",
-						"2006/01/02",
-						"01/02/2006",
-						"2006-01",
-					}
-					for _, format := range formats {
-						parsedTime, err = time.Parse(format, timeStr)
-						if err == nil {
-							break
-						}
-					}
-				}
-				if err == nil {
-					gofeedItem.PublishedParsed = &parsedTime
-				}
-			}
+			gofeedItem.PublishedParsed = parseXPathTimestamp(htmlquery.InnerText(timeNode), feed.XPathItemTimeFormat)
 		}
 	}
 
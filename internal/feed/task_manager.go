@@ -3,6 +3,7 @@ package feed
 import (
 	"MavenRSS/internal/models"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
@@ -21,6 +22,7 @@ const (
 	TaskReasonScheduledCustom                   // Scheduled refresh with custom interval
 	TaskReasonScheduledGlobal                   // Global refresh
 	TaskReasonArticleClick                      // Article content missing
+	TaskReasonPush                              // New content announced by the source (IMAP IDLE)
 )
 
 // RefreshTask represents a single feed refresh task
@@ -28,6 +30,7 @@ type RefreshTask struct {
 	Feed      models.Feed
 	Reason    TaskReason
 	CreatedAt time.Time
+	Host      string // Host the feed is refreshed from, empty when not fetched over HTTP
 }
 
 // TaskManager manages the task queue and pool for feed refreshing
@@ -36,8 +39,15 @@ type TaskManager struct {
 
 	// Double-ended queue for pending tasks
 	queue      []int64 // Feed IDs only for efficient storage
+	queueHosts map[int64]string
 	queueMutex sync.RWMutex
 
+	// Per-host politeness
+	hosts       *hostLimiter
+	wakeup      *time.Timer // Resumes processing once a deferred or spaced host is ready
+	wakeupAt    time.Time
+	wakeupMutex sync.Mutex
+
 	// Task pool for active tasks (limited capacity)
 	pool      map[int64]*RefreshTask
 	poolMutex sync.RWMutex
@@ -81,6 +91,8 @@ func NewTaskManager(fetcher *Fetcher, poolCapacity int) *TaskManager {
 	tm := &TaskManager{
 		fetcher:      fetcher,
 		queue:        make([]int64, 0),
+		queueHosts:   make(map[int64]string),
+		hosts:        newHostLimiter(),
 		pool:         make(map[int64]*RefreshTask),
 		poolCapacity: poolCapacity,
 		poolSem:      make(chan struct{}, poolCapacity),
@@ -147,8 +159,16 @@ func (tm *TaskManager) Stop() {
 	// Clear state
 	tm.queueMutex.Lock()
 	tm.queue = make([]int64, 0)
+	tm.queueHosts = make(map[int64]string)
 	tm.queueMutex.Unlock()
 
+	tm.wakeupMutex.Lock()
+	if tm.wakeup != nil {
+		tm.wakeup.Stop()
+		tm.wakeup = nil
+	}
+	tm.wakeupMutex.Unlock()
+
 	// Close log file if open
 	tm.logMutex.Lock()
 	if tm.logFile != nil {
@@ -174,6 +194,8 @@ func (tm *TaskManager) StopForUser(userID int64) {
 		feed, err := tm.fetcher.db.GetFeedByID(feedID)
 		if err == nil && feed != nil && feed.UserID != userID {
 			newQueue = append(newQueue, feedID)
+		} else {
+			delete(tm.queueHosts, feedID)
 		}
 	}
 	tm.queue = newQueue
@@ -258,6 +280,7 @@ func (tm *TaskManager) AddToQueueHead(ctx context.Context, feed models.Feed, rea
 	if !inPool {
 		// Add to queue head
 		tm.queue = append([]int64{feed.ID}, tm.queue...)
+		tm.queueHosts[feed.ID] = feedHost(feed)
 		added = true
 	}
 
@@ -322,6 +345,7 @@ func (tm *TaskManager) AddToQueueTail(ctx context.Context, feed models.Feed, rea
 	var added bool
 	if !inQueue && !inPool {
 		tm.queue = append(tm.queue, feed.ID)
+		tm.queueHosts[feed.ID] = feedHost(feed)
 		added = true
 	}
 
@@ -447,6 +471,7 @@ func (tm *TaskManager) addGlobalRefreshInternal(ctx context.Context, feeds []mod
 	for _, feed := range feeds {
 		if !existingFeedIDs[feed.ID] {
 			tm.queue = append(tm.queue, feed.ID)
+			tm.queueHosts[feed.ID] = feedHost(feed)
 			existingFeedIDs[feed.ID] = true
 			addedCount++
 			addedFeeds = append(addedFeeds, feed)
@@ -490,6 +515,7 @@ func (tm *TaskManager) ExecuteImmediately(ctx context.Context, feed models.Feed)
 	// Remove from queue if present
 	tm.queueMutex.Lock()
 	removedFromQueue := removeFromQueue(&tm.queue, feed.ID)
+	delete(tm.queueHosts, feed.ID)
 	tm.queueMutex.Unlock()
 
 	// Remove from pool if present
@@ -555,7 +581,7 @@ func (tm *TaskManager) ExecuteImmediately(ctx context.Context, feed models.Feed)
 		}
 
 		// Second attempt: use configured retry timeout if first attempt failed
-		if !success && err != nil {
+		if !success && err != nil && !errors.Is(err, ErrFeedGone) && !tm.deferHostAfter(feedHost(task.Feed), err) {
 			log.Printf("First attempt failed for %s: %v, retrying with %v timeout", task.Feed.Title, err, retryTimeout)
 
 			ctx2, cancel2 := context.WithTimeout(ctx, retryTimeout)
@@ -584,6 +610,7 @@ func (tm *TaskManager) ExecuteImmediately(ctx context.Context, feed models.Feed)
 			tm.fetcher.db.UpdateFeedError(task.Feed.ID, "")
 			tm.fetcher.db.UpdateFeedLastUpdated(task.Feed.ID)
 		}
+		tm.fetcher.recordRefreshResult(task.Feed, err)
 	}()
 
 	// Return completion callback
@@ -611,22 +638,28 @@ func (tm *TaskManager) processQueue(ctx context.Context) {
 			time.Sleep(500 * time.Millisecond)
 		}
 
+		maxPerHost, spacing := tm.hostLimits()
+
 		// Check if we can start a new task
 		tm.queueMutex.Lock()
 		tm.poolMutex.Lock()
 
-		// Get next task from queue
+		// Get next task from queue whose host accepts another refresh
 		var feedID int64
+		var host string
+		var wait time.Duration
 		if len(tm.queue) > 0 && len(tm.pool) < tm.poolCapacity {
-			feedID = tm.queue[0]
-			tm.queue = tm.queue[1:]
+			feedID, host, wait = tm.nextReadyTask(time.Now(), maxPerHost, spacing)
 		}
 
 		tm.poolMutex.Unlock()
 		tm.queueMutex.Unlock()
 
 		if feedID == 0 {
-			// No task available or pool is full
+			// No task available, pool is full, or all queued hosts are busy
+			if wait > 0 {
+				tm.scheduleWakeup(ctx, wait)
+			}
 			tm.checkCompletion()
 			return
 		}
@@ -635,10 +668,12 @@ func (tm *TaskManager) processQueue(ctx context.Context) {
 		feed, err := tm.fetcher.db.GetFeedByID(feedID)
 		if err != nil {
 			log.Printf("Error getting feed %d: %v", feedID, err)
+			tm.hosts.release(host)
 			continue
 		}
 		if feed == nil {
 			log.Printf("Feed %d not found in database", feedID)
+			tm.hosts.release(host)
 			continue
 		}
 
@@ -647,6 +682,7 @@ func (tm *TaskManager) processQueue(ctx context.Context) {
 			Feed:      *feed,
 			Reason:    TaskReasonScheduledGlobal, // Default reason
 			CreatedAt: time.Now(),
+			Host:      host,
 		}
 
 		// Acquire semaphore FIRST (this will block if pool is at capacity)
@@ -673,8 +709,9 @@ func (tm *TaskManager) processQueue(ctx context.Context) {
 // processTask processes a single task with timeout and retry logic
 func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask) {
 	defer func() {
-		// Release semaphore
+		// Release semaphore and host
 		<-tm.poolSem
+		tm.hosts.release(task.Host)
 		tm.wg.Done()
 
 		// Remove from pool
@@ -714,7 +751,9 @@ func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask) {
 	}
 
 	// Second attempt: use configured retry timeout if first attempt failed
-	if !success && err != nil {
+	// A gone feed isn't retried, it is paused below, and a host asking
+	// to retry later isn't retried before its queued tasks are deferred
+	if !success && err != nil && !errors.Is(err, ErrFeedGone) && !tm.deferHostAfter(task.Host, err) {
 		log.Printf("First attempt failed for %s: %v, retrying with %v timeout", task.Feed.Title, err, retryTimeout)
 		tm.logOperation("RT", task.Feed.Title)
 
@@ -732,6 +771,7 @@ func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask) {
 	if err != nil {
 		log.Printf("Failed to fetch feed %s after retry: %v", task.Feed.Title, err)
 		tm.logOperation("FL", task.Feed.Title)
+		tm.deferHostAfter(task.Host, err)
 
 		// Update feed error and last_updated in database
 		tm.fetcher.db.UpdateFeedError(task.Feed.ID, err.Error())
@@ -750,6 +790,9 @@ func (tm *TaskManager) processTask(ctx context.Context, task *RefreshTask) {
 		tm.fetcher.db.UpdateFeedError(task.Feed.ID, "")
 		tm.fetcher.db.UpdateFeedLastUpdated(task.Feed.ID)
 	}
+
+	// Track consecutive failures for backoff and pausing
+	tm.fetcher.recordRefreshResult(task.Feed, err)
 }
 
 // checkCompletion checks if all tasks are completed and triggers cleanup if needed
@@ -1060,6 +1103,7 @@ func (tm *TaskManager) ClearQueue() {
 	defer tm.queueMutex.Unlock()
 
 	tm.queue = make([]int64, 0)
+	tm.queueHosts = make(map[int64]string)
 
 	log.Println("Queue cleared")
 }
@@ -0,0 +1,393 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/models"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/mmcdole/gofeed"
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	// watchDiffContext is the number of unchanged lines shown around the changes of a page
+	watchDiffContext = 2
+	// maxWatchDiffCells bounds the line comparison of two snapshots; beyond it, all the
+	// changed lines are shown as removed and added
+	maxWatchDiffCells = 4000000
+
+	// Keys of the item custom fields carrying the new snapshot until the item is saved
+	watchHashKey     = "watch_hash"
+	watchSnapshotKey = "watch_snapshot"
+)
+
+// watchBlockElements start a new line of the normalized text of a region
+var watchBlockElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Br: true, atom.Dd: true, atom.Details: true, atom.Div: true, atom.Dl: true,
+	atom.Dt: true, atom.Figcaption: true, atom.Figure: true, atom.Footer: true,
+	atom.Form: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true,
+	atom.H5: true, atom.H6: true, atom.Header: true, atom.Hr: true, atom.Li: true,
+	atom.Main: true, atom.Nav: true, atom.Ol: true, atom.P: true, atom.Pre: true,
+	atom.Section: true, atom.Summary: true, atom.Table: true, atom.Tr: true, atom.Ul: true,
+}
+
+// ValidateWatchFeed checks the region selector and ignore patterns of a watch feed
+func ValidateWatchFeed(feed *models.Feed) error {
+	if strings.TrimSpace(feed.XPathItem) == "" {
+		return errors.New("a CSS selector or XPath expression of the watched region is required")
+	}
+	if _, err := selectWatchRegion(&nethtml.Node{Type: nethtml.DocumentNode}, feed.XPathItem); err != nil {
+		return err
+	}
+	_, err := compileWatchIgnore(feed.WatchIgnore)
+	return err
+}
+
+// AddWatchSubscription adds a watch feed after checking its region selector and ignore
+// patterns, and returns the feed ID. The page is compared from its first fetch on.
+func (f *Fetcher) AddWatchSubscription(feed *models.Feed) (int64, error) {
+	if feed.URL == "" {
+		return 0, errors.New("URL cannot be empty")
+	}
+	if err := ValidateWatchFeed(feed); err != nil {
+		return 0, err
+	}
+	feed.Type = models.FeedTypeWatch
+	if feed.Title == "" {
+		feed.Title = "Watched Page"
+	}
+	id, err := f.db.AddFeed(feed)
+	if err != nil {
+		return 0, err
+	}
+	if feed.WatchIgnore != "" {
+		if err := f.db.SetFeedWatchIgnore(id, feed.WatchIgnore); err != nil {
+			return id, err
+		}
+	}
+	return id, nil
+}
+
+// isXPathSelector reports whether a region selector is an XPath expression rather than
+// a CSS selector. XPath expressions start with /, ./ or (.
+func isXPathSelector(selector string) bool {
+	return strings.HasPrefix(selector, "/") || strings.HasPrefix(selector, "./") || strings.HasPrefix(selector, "(")
+}
+
+// selectWatchRegion returns the nodes of a page matched by a CSS selector or XPath expression
+func selectWatchRegion(doc *nethtml.Node, selector string) ([]*nethtml.Node, error) {
+	selector = strings.TrimSpace(selector)
+	if isXPathSelector(selector) {
+		nodes, err := htmlquery.QueryAll(doc, selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid XPath expression %q: %w", selector, err)
+		}
+		return nodes, nil
+	}
+	sel, err := cascadia.Compile(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSS selector %q: %w", selector, err)
+	}
+	return cascadia.QueryAll(doc, sel), nil
+}
+
+// compileWatchIgnore compiles the ignore patterns of a watch feed, one regular expression per line
+func compileWatchIgnore(patterns string) ([]*regexp.Regexp, error) {
+	var ignore []*regexp.Regexp
+	for _, line := range strings.Split(patterns, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		re, err := regexp.Compile(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %w", line, err)
+		}
+		ignore = append(ignore, re)
+	}
+	return ignore, nil
+}
+
+// normalizeWatchRegion returns the text of a region as lines, one per block, with collapsed
+// whitespace and without the matches of the ignore patterns, so that markup and layout
+// changes don't count as changes of the page.
+func normalizeWatchRegion(nodes []*nethtml.Node, ignore []*regexp.Regexp) []string {
+	var lines []string
+	var line strings.Builder
+	flush := func() {
+		text := strings.Join(strings.Fields(line.String()), " ")
+		line.Reset()
+		for _, re := range ignore {
+			text = re.ReplaceAllString(text, "")
+		}
+		if text = strings.Join(strings.Fields(text), " "); text != "" {
+			lines = append(lines, text)
+		}
+	}
+
+	var walk func(n *nethtml.Node)
+	walk = func(n *nethtml.Node) {
+		switch n.Type {
+		case nethtml.TextNode:
+			line.WriteString(n.Data)
+			return
+		case nethtml.CommentNode:
+			return
+		case nethtml.ElementNode:
+			switch n.DataAtom {
+			case atom.Script, atom.Style, atom.Noscript, atom.Template:
+				return
+			case atom.Td, atom.Th:
+				// Keep the cells of a row apart
+				if strings.TrimSpace(line.String()) != "" {
+					line.WriteString(" | ")
+				}
+			}
+		}
+		block := n.Type == nethtml.ElementNode && watchBlockElements[n.DataAtom]
+		if block {
+			flush()
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			flush()
+		}
+	}
+
+	for _, n := range nodes {
+		walk(n)
+		flush()
+	}
+	return lines
+}
+
+// parseWatchFeed fetches the page of a watch feed and compares its region with the last
+// snapshot. It returns an item with the differences when the region changed, or the
+// region itself on the first fetch; the new snapshot is saved with the item.
+func (f *Fetcher) parseWatchFeed(ctx context.Context, feed *models.Feed) (*gofeed.Feed, error) {
+	ignore, err := compileWatchIgnore(feed.WatchIgnore)
+	if err != nil {
+		return nil, &XPathError{Operation: "validate", Details: err.Error()}
+	}
+
+	body, err := f.fetchXPathPage(ctx, feed, feed.URL)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := htmlquery.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, &XPathError{
+			Operation: "parse",
+			URL:       feed.URL,
+			Details:   "Failed to parse HTML. The content may not be valid HTML",
+			Err:       err,
+		}
+	}
+	nodes, err := selectWatchRegion(doc, feed.XPathItem)
+	if err != nil {
+		return nil, &XPathError{Operation: "validate", XPathExpr: feed.XPathItem, Details: err.Error()}
+	}
+	if len(nodes) == 0 {
+		return nil, &XPathError{
+			Operation: "extract",
+			URL:       feed.URL,
+			XPathExpr: feed.XPathItem,
+			Details:   "The watched region wasn't found on the page. The page structure may have changed",
+		}
+	}
+
+	lines := normalizeWatchRegion(nodes, ignore)
+	snapshot := strings.Join(lines, "\n")
+	sum := sha256.Sum256([]byte(snapshot))
+	hash := hex.EncodeToString(sum[:])
+
+	parsedFeed := &gofeed.Feed{
+		Title:       feed.Title,
+		Link:        feed.URL,
+		Description: feed.Description,
+		Items:       make([]*gofeed.Item, 0, 1),
+	}
+	lastHash, lastSnapshot, err := f.db.GetFeedWatchSnapshot(feed.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the last snapshot: %w", err)
+	}
+	if hash == lastHash {
+		return parsedFeed, nil
+	}
+
+	name := feed.Title
+	if name == "" {
+		name = feed.URL
+	}
+	now := time.Now()
+	item := &gofeed.Item{
+		Link:            fmt.Sprintf("%s#watch-%d", feed.URL, now.Unix()),
+		GUID:            fmt.Sprintf("%s@%d", hash[:16], now.Unix()),
+		PublishedParsed: &now,
+		Published:       now.Format(time.RFC3339),
+		Custom:          map[string]string{watchHashKey: hash, watchSnapshotKey: snapshot},
+	}
+	if lastHash == "" {
+		item.Title = "Watching " + name
+		item.Content = renderWatchLines(lines)
+	} else {
+		var lastLines []string
+		if lastSnapshot != "" {
+			lastLines = strings.Split(lastSnapshot, "\n")
+		}
+		var added, removed int
+		item.Content, added, removed = renderWatchDiff(diffWatchLines(lastLines, lines))
+		// The time keeps changes of a day with the same counts apart, as articles are unique per title and day
+		item.Title = fmt.Sprintf("%s changed at %s (%d added, %d removed)", name, now.Format("15:04:05"), added, removed)
+	}
+	parsedFeed.Items = append(parsedFeed.Items, item)
+	return parsedFeed, nil
+}
+
+// saveWatchSnapshot saves the snapshot carried by the saved item of a watch feed, so
+// that the next fetches compare the page with it
+func (f *Fetcher) saveWatchSnapshot(feed models.Feed, items []*gofeed.Item) {
+	for _, item := range items {
+		hash := item.Custom[watchHashKey]
+		if hash == "" {
+			continue
+		}
+		if err := f.db.SetFeedWatchSnapshot(feed.ID, hash, item.Custom[watchSnapshotKey]); err != nil {
+			log.Printf("Error saving the snapshot of watched feed %d: %v", feed.ID, err)
+		}
+	}
+}
+
+// watchDiffLine is a line of the difference between two snapshots
+type watchDiffLine struct {
+	op   byte // '=' unchanged, '-' removed or '+' added
+	text string
+}
+
+// diffWatchLines returns the line differences between two snapshots, from their longest
+// common subsequence
+func diffWatchLines(a, b []string) []watchDiffLine {
+	var prefix, suffix []watchDiffLine
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		prefix = append(prefix, watchDiffLine{'=', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append([]watchDiffLine{{'=', a[len(a)-1]}}, suffix...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	diff := prefix
+	if len(a)*len(b) > maxWatchDiffCells {
+		for _, line := range a {
+			diff = append(diff, watchDiffLine{'-', line})
+		}
+		for _, line := range b {
+			diff = append(diff, watchDiffLine{'+', line})
+		}
+		return append(diff, suffix...)
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			diff = append(diff, watchDiffLine{'=', a[i]})
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			diff = append(diff, watchDiffLine{'+', b[j]})
+			j++
+		default:
+			diff = append(diff, watchDiffLine{'-', a[i]})
+			i++
+		}
+	}
+	return append(diff, suffix...)
+}
+
+// renderWatchDiff renders the changed lines of a diff as HTML, with a few unchanged lines
+// around them, and counts the added and removed lines
+func renderWatchDiff(diff []watchDiffLine) (content string, added, removed int) {
+	// Unchanged lines close enough to a change are shown
+	show := make([]bool, len(diff))
+	for i, line := range diff {
+		if line.op == '=' {
+			continue
+		}
+		for j := max(0, i-watchDiffContext); j <= min(len(diff)-1, i+watchDiffContext); j++ {
+			show[j] = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("<div>")
+	skipped := false
+	for i, line := range diff {
+		if !show[i] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			b.WriteString("<p>…</p>")
+			skipped = false
+		}
+		text := html.EscapeString(line.text)
+		switch line.op {
+		case '-':
+			removed++
+			b.WriteString("<p><del>" + text + "</del></p>")
+		case '+':
+			added++
+			b.WriteString("<p><ins>" + text + "</ins></p>")
+		default:
+			b.WriteString("<p>" + text + "</p>")
+		}
+	}
+	if skipped {
+		b.WriteString("<p>…</p>")
+	}
+	b.WriteString("</div>")
+	return b.String(), added, removed
+}
+
+// renderWatchLines renders the lines of a snapshot as HTML
+func renderWatchLines(lines []string) string {
+	var b strings.Builder
+	b.WriteString("<div>")
+	for _, line := range lines {
+		b.WriteString("<p>" + html.EscapeString(line) + "</p>")
+	}
+	b.WriteString("</div>")
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+
+	"github.com/antchfx/htmlquery"
+)
+
+func TestNormalizeWatchRegion(t *testing.T) {
+	doc, err := htmlquery.Parse(strings.NewReader(`<html><body>
+		<div id="pricing">
+			<h2>Plans</h2>
+			<p>Basic:   <b>$5</b> per month</p>
+			<script>track()</script>
+			<table><tr><td>Pro</td><td>$10</td></tr></table>
+			<p class="updated">Last updated 2026-10-16 09:12</p>
+		</div>
+		<div id="footer">Unrelated</div>
+	</body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ignore, err := compileWatchIgnore("Last updated .*\n\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, selector := range []string{"#pricing", "//div[@id='pricing']"} {
+		nodes, err := selectWatchRegion(doc, selector)
+		if err != nil {
+			t.Fatalf("selectWatchRegion(%q): %v", selector, err)
+		}
+		got := strings.Join(normalizeWatchRegion(nodes, ignore), "\n")
+		if want := "Plans\nBasic: $5 per month\nPro | $10"; got != want {
+			t.Errorf("selector %q: got %q, want %q", selector, got, want)
+		}
+	}
+
+	for _, feed := range []models.Feed{
+		{XPathItem: ""},
+		{XPathItem: "div["},
+		{XPathItem: "//div[", WatchIgnore: ""},
+		{XPathItem: "#pricing", WatchIgnore: "(unclosed"},
+	} {
+		if err := ValidateWatchFeed(&feed); err == nil {
+			t.Errorf("expected an error for %+v", feed)
+		}
+	}
+}
+
+func TestRenderWatchDiff(t *testing.T) {
+	before := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	after := []string{"a", "b", "c", "d", "E", "f", "g", "h", "<i>"}
+	content, added, removed := renderWatchDiff(diffWatchLines(before, after))
+	if added != 2 || removed != 1 {
+		t.Errorf("got %d added and %d removed lines", added, removed)
+	}
+	want := "<div><p>…</p><p>c</p><p>d</p><p><del>e</del></p><p><ins>E</ins></p><p>f</p><p>g</p><p>h</p><p><ins>&lt;i&gt;</ins></p></div>"
+	if content != want {
+		t.Errorf("got %s", content)
+	}
+}
+
+func TestWatchFeedChanges(t *testing.T) {
+	page := `<main><p>Version 1.0</p><p>Generated at 10:00</p></main>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/changelog" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	db, err := sqlite.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	defer db.Close()
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	fetcher := NewFetcher(db)
+
+	id, err := fetcher.AddWatchSubscription(&models.Feed{
+		URL:         server.URL + "/changelog",
+		Title:       "Changelog",
+		XPathItem:   "main",
+		WatchIgnore: `Generated at \d+:\d+`,
+	})
+	if err != nil {
+		t.Fatalf("AddWatchSubscription: %v", err)
+	}
+	feed, err := db.GetFeedByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refresh := func() {
+		t.Helper()
+		if err := fetcher.fetchAndSaveFeed(context.Background(), *feed); err != nil {
+			t.Fatalf("fetchAndSaveFeed: %v", err)
+		}
+	}
+	refresh()
+	// Only the ignored timestamp changes
+	page = `<main><p>Version 1.0</p><p>Generated at 11:30</p></main>`
+	refresh()
+	page = `<main><p>Version 1.1</p><p>Generated at 12:00</p></main>`
+	refresh()
+	refresh()
+	// Another change of the same day with the same counts, a second later
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	page = `<main><p>Version 1.2</p><p>Generated at 12:30</p></main>`
+	refresh()
+
+	articles, err := db.GetArticles("", id, "", false, 10, 0)
+	if err != nil || len(articles) != 3 {
+		t.Fatalf("expected the first snapshot and two changes, got %d articles (%v)", len(articles), err)
+	}
+	var titles []string
+	for _, article := range articles {
+		titles = append(titles, article.Title)
+	}
+	all := strings.Join(titles, "|")
+	if !strings.Contains(all, "Watching Changelog") || strings.Count(all, "(1 added, 1 removed)") != 2 {
+		t.Errorf("unexpected articles %q", all)
+	}
+	// The snapshot follows the saved changes
+	if _, snapshot, err := db.GetFeedWatchSnapshot(id); err != nil || snapshot != "Version 1.2" {
+		t.Errorf("unexpected snapshot %q (%v)", snapshot, err)
+	}
+}
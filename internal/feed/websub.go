@@ -0,0 +1,534 @@
+package feed
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+	"MavenRSS/internal/utils"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	// Lease requested from hubs (7 days); hubs may grant a different one
+	webSubLeaseSeconds = 7 * 24 * 60 * 60
+	// Leases are renewed when they end within this window
+	webSubRenewBefore = 24 * time.Hour
+	// How often leases are checked for renewal and expiry
+	webSubCheckInterval = time.Hour
+	// How long a hub has to verify a (un)subscription request
+	webSubPendingTimeout = time.Hour
+	// How long to wait before asking a hub that denied or failed a request again
+	webSubRetryAfter = 24 * time.Hour
+
+	// MaxWebSubPayloadSize is the largest pushed body accepted on the callback (10 MB)
+	MaxWebSubPayloadSize = 10 << 20
+
+	// Keys of the discovered links in gofeed.Feed.Custom
+	webSubHubKey  = "websub_hub"
+	webSubSelfKey = "websub_self"
+)
+
+var (
+	// ErrWebSubUnknownCallback is returned when no subscription matches a callback
+	ErrWebSubUnknownCallback = errors.New("unknown WebSub callback")
+	// ErrWebSubInvalidSignature is returned when pushed content fails X-Hub-Signature validation
+	ErrWebSubInvalidSignature = errors.New("invalid WebSub signature")
+)
+
+// discoverWebSubLinks records the feed-level rel="hub" and rel="self" links of
+// the raw XML in feed.Custom. gofeed does not expose Atom link relations on the
+// universal feed, so they are read from the document directly.
+func discoverWebSubLinks(feed *gofeed.Feed, rawXML string) {
+	decoder := xml.NewDecoder(strings.NewReader(rawXML))
+	decoder.Strict = false
+
+	var hub, self string
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		// Only links that describe the feed itself are relevant
+		if start.Name.Local == "item" || start.Name.Local == "entry" {
+			break
+		}
+		if start.Name.Local != "link" {
+			continue
+		}
+
+		var rel, href string
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "rel":
+				rel = attr.Value
+			case "href":
+				href = strings.TrimSpace(attr.Value)
+			}
+		}
+		if href == "" {
+			continue
+		}
+		for _, r := range strings.Fields(strings.ToLower(rel)) {
+			if r == "hub" && hub == "" {
+				hub = href
+			} else if r == "self" && self == "" {
+				self = href
+			}
+		}
+	}
+
+	if hub == "" {
+		return
+	}
+	if feed.Custom == nil {
+		feed.Custom = make(map[string]string)
+	}
+	feed.Custom[webSubHubKey] = hub
+	if self != "" {
+		feed.Custom[webSubSelfKey] = self
+	}
+}
+
+// WebSubManager subscribes feeds that advertise a WebSub hub, answers hub
+// verification requests, ingests pushed content and renews leases before they expire.
+// Feeds whose subscription lapses are polled again by the regular scheduler.
+type WebSubManager struct {
+	fetcher      *Fetcher
+	callbackBase string // Public URL the callback token is appended to
+
+	isRunning bool
+	mu        sync.Mutex
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewWebSubManager creates a new WebSub manager using the given callback base URL
+func NewWebSubManager(fetcher *Fetcher, callbackBase string) *WebSubManager {
+	return &WebSubManager{
+		fetcher:      fetcher,
+		callbackBase: callbackBase,
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Start starts the lease renewal loop
+func (wm *WebSubManager) Start() {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	if wm.isRunning {
+		return
+	}
+	wm.isRunning = true
+
+	wm.wg.Add(1)
+	go wm.renewLoop()
+
+	log.Printf("WebSub manager started (callback: %s)", wm.callbackBase)
+}
+
+// Stop stops the lease renewal loop
+func (wm *WebSubManager) Stop() {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	if !wm.isRunning {
+		return
+	}
+
+	close(wm.stopChan)
+	wm.wg.Wait()
+
+	wm.isRunning = false
+	log.Println("WebSub manager stopped")
+}
+
+// CallbackURL returns the callback URL for a subscription token
+func (wm *WebSubManager) CallbackURL(token string) string {
+	return wm.callbackBase + token
+}
+
+// renewLoop periodically renews and expires subscriptions
+func (wm *WebSubManager) renewLoop() {
+	defer wm.wg.Done()
+
+	ticker := time.NewTicker(webSubCheckInterval)
+	defer ticker.Stop()
+
+	wm.checkSubscriptions()
+	for {
+		select {
+		case <-ticker.C:
+			wm.checkSubscriptions()
+		case <-wm.stopChan:
+			return
+		}
+	}
+}
+
+// checkSubscriptions renews leases ending soon and expires lapsed ones
+func (wm *WebSubManager) checkSubscriptions() {
+	db := wm.fetcher.db
+
+	subs, err := db.GetWebSubSubscriptionsExpiringBefore(time.Now().Add(webSubRenewBefore))
+	if err != nil {
+		log.Printf("WebSub: failed to load subscriptions due for renewal: %v", err)
+	}
+	for i := range subs {
+		sub := &subs[i]
+		feed, err := db.GetFeedByID(sub.FeedID)
+		if err != nil || feed == nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := wm.subscribe(ctx, *feed, sub); err != nil {
+			log.Printf("WebSub: failed to renew subscription for feed %s: %v", feed.Title, err)
+		}
+		cancel()
+	}
+
+	if n, err := db.ExpireWebSubSubscriptions(time.Now(), webSubPendingTimeout); err != nil {
+		log.Printf("WebSub: failed to expire subscriptions: %v", err)
+	} else if n > 0 {
+		log.Printf("WebSub: %d subscriptions lapsed, their feeds are polled again", n)
+	}
+}
+
+// onFeedFetched subscribes, renews or unsubscribes a feed after a successful poll,
+// depending on the hub it currently advertises.
+func (wm *WebSubManager) onFeedFetched(feed models.Feed, parsedFeed *gofeed.Feed) {
+	if feed.IsFreshRSSSource || feed.RefreshInterval == -2 {
+		return
+	}
+
+	db := wm.fetcher.db
+	sub, err := db.GetWebSubSubscription(feed.ID)
+	if err != nil {
+		log.Printf("WebSub: failed to load subscription for feed %s: %v", feed.Title, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	hub := parsedFeed.Custom[webSubHubKey]
+	if hub == "" {
+		// The feed stopped advertising a hub
+		if sub != nil && sub.State == sqlite.WebSubStateActive {
+			if err := wm.unsubscribe(ctx, feed, sub); err != nil {
+				log.Printf("WebSub: failed to unsubscribe feed %s: %v", feed.Title, err)
+			}
+		}
+		return
+	}
+
+	topic := parsedFeed.Custom[webSubSelfKey]
+	if topic == "" {
+		topic = feed.URL
+	}
+
+	if sub != nil && sub.HubURL == hub && sub.TopicURL == topic {
+		switch sub.State {
+		case sqlite.WebSubStateActive:
+			if time.Until(sub.ExpiresAt) > webSubRenewBefore {
+				return
+			}
+		case sqlite.WebSubStatePending, sqlite.WebSubStateUnsubscribing:
+			if time.Since(sub.UpdatedAt) < webSubPendingTimeout {
+				return
+			}
+		case sqlite.WebSubStateDenied, sqlite.WebSubStateFailed:
+			if time.Since(sub.UpdatedAt) < webSubRetryAfter {
+				return
+			}
+		}
+	} else {
+		// New hub or topic: use a fresh callback so the old hub can no longer push
+		token, err := randomHex(16)
+		if err != nil {
+			return
+		}
+		secret, err := randomHex(32)
+		if err != nil {
+			return
+		}
+		sub = &sqlite.WebSubSubscription{
+			FeedID:        feed.ID,
+			HubURL:        hub,
+			TopicURL:      topic,
+			CallbackToken: token,
+			Secret:        secret,
+			State:         sqlite.WebSubStatePending,
+		}
+	}
+
+	if err := wm.subscribe(ctx, feed, sub); err != nil {
+		log.Printf("WebSub: failed to subscribe feed %s to hub %s: %v", feed.Title, hub, err)
+	}
+}
+
+// subscribe sends a subscription request for sub to its hub and stores the result.
+// Active subscriptions stay active while the renewal is being verified.
+func (wm *WebSubManager) subscribe(ctx context.Context, feed models.Feed, sub *sqlite.WebSubSubscription) error {
+	err := wm.sendHubRequest(ctx, feed, sub, "subscribe")
+
+	if sub.State != sqlite.WebSubStateActive {
+		sub.State = sqlite.WebSubStatePending
+		if err != nil {
+			sub.State = sqlite.WebSubStateFailed
+		}
+	}
+	sub.LastError = ""
+	if err != nil {
+		sub.LastError = err.Error()
+	}
+	if saveErr := wm.fetcher.db.SaveWebSubSubscription(sub); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// unsubscribe asks the hub to stop pushing content for sub
+func (wm *WebSubManager) unsubscribe(ctx context.Context, feed models.Feed, sub *sqlite.WebSubSubscription) error {
+	if err := wm.sendHubRequest(ctx, feed, sub, "unsubscribe"); err != nil {
+		// The lease will lapse on its own; make sure the feed is polled meanwhile
+		wm.fetcher.db.UpdateWebSubSubscriptionState(feed.ID, sqlite.WebSubStateExpired, err.Error())
+		return err
+	}
+	return wm.fetcher.db.UpdateWebSubSubscriptionState(feed.ID, sqlite.WebSubStateUnsubscribing, "")
+}
+
+// sendHubRequest posts a (un)subscription request to the hub of sub
+func (wm *WebSubManager) sendHubRequest(ctx context.Context, feed models.Feed, sub *sqlite.WebSubSubscription, mode string) error {
+	form := url.Values{
+		"hub.mode":     {mode},
+		"hub.topic":    {sub.TopicURL},
+		"hub.callback": {wm.CallbackURL(sub.CallbackToken)},
+	}
+	if mode == "subscribe" {
+		form.Set("hub.secret", sub.Secret)
+		form.Set("hub.lease_seconds", strconv.Itoa(webSubLeaseSeconds))
+	}
+
+	httpClient, err := wm.fetcher.getHTTPClient(feed)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.HubURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("hub request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("hub returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	utils.DebugLog("WebSub: %s request for %s accepted by %s", mode, sub.TopicURL, sub.HubURL)
+	return nil
+}
+
+// VerifyIntent handles a hub verification request on the callback identified by token.
+// It returns the challenge to echo back, or an error if the request must be refused.
+func (wm *WebSubManager) VerifyIntent(token string, query url.Values) (string, error) {
+	db := wm.fetcher.db
+	sub, err := db.GetWebSubSubscriptionByToken(token)
+	if err != nil {
+		return "", err
+	}
+	if sub == nil {
+		return "", ErrWebSubUnknownCallback
+	}
+	if query.Get("hub.topic") != sub.TopicURL {
+		return "", fmt.Errorf("topic mismatch")
+	}
+
+	switch query.Get("hub.mode") {
+	case "subscribe":
+		if sub.State == sqlite.WebSubStateUnsubscribing {
+			return "", fmt.Errorf("subscription is being cancelled")
+		}
+		lease, err := strconv.Atoi(query.Get("hub.lease_seconds"))
+		if err != nil || lease <= 0 {
+			lease = webSubLeaseSeconds
+		}
+		if err := db.ActivateWebSubSubscription(sub.FeedID, lease); err != nil {
+			return "", err
+		}
+		log.Printf("WebSub: subscription for %s verified (lease: %ds)", sub.TopicURL, lease)
+		return query.Get("hub.challenge"), nil
+
+	case "unsubscribe":
+		if sub.State != sqlite.WebSubStateUnsubscribing {
+			return "", fmt.Errorf("unsubscription was not requested")
+		}
+		if err := db.DeleteWebSubSubscription(sub.FeedID); err != nil {
+			return "", err
+		}
+		return query.Get("hub.challenge"), nil
+
+	case "denied":
+		reason := query.Get("hub.reason")
+		if reason == "" {
+			reason = "subscription denied by hub"
+		}
+		log.Printf("WebSub: subscription for %s denied: %s", sub.TopicURL, reason)
+		return "", db.UpdateWebSubSubscriptionState(sub.FeedID, sqlite.WebSubStateDenied, reason)
+
+	default:
+		return "", fmt.Errorf("unsupported hub.mode %q", query.Get("hub.mode"))
+	}
+}
+
+// HandlePush validates content pushed to the callback identified by token and
+// saves its items like a regular refresh would.
+func (wm *WebSubManager) HandlePush(ctx context.Context, token string, body []byte, signature string) error {
+	db := wm.fetcher.db
+	sub, err := db.GetWebSubSubscriptionByToken(token)
+	if err != nil {
+		return err
+	}
+	if sub == nil {
+		return ErrWebSubUnknownCallback
+	}
+	if !verifyWebSubSignature(sub.Secret, body, signature) {
+		return ErrWebSubInvalidSignature
+	}
+
+	feed, err := db.GetFeedByID(sub.FeedID)
+	if err != nil {
+		return err
+	}
+	if feed == nil {
+		return ErrWebSubUnknownCallback
+	}
+
+	cleanedXML := sanitizeFeedXML(string(body))
+	parsedFeed, err := gofeed.NewParser().ParseString(cleanedXML)
+	if err != nil {
+		return fmt.Errorf("failed to parse pushed content: %w", err)
+	}
+	fixFeedAuthors(parsedFeed, cleanedXML)
+
+	if err := wm.fetcher.saveFeedItems(ctx, *feed, parsedFeed.Items); err != nil {
+		return err
+	}
+
+	db.UpdateWebSubLastPush(feed.ID)
+	db.UpdateFeedError(feed.ID, "")
+	db.UpdateFeedLastUpdated(feed.ID)
+	utils.DebugLog("WebSub: ingested %d pushed items for feed %s", len(parsedFeed.Items), feed.Title)
+	return nil
+}
+
+// verifyWebSubSignature checks an X-Hub-Signature header ("method=hexdigest")
+// against the HMAC of body keyed with secret.
+func verifyWebSubSignature(secret string, body []byte, header string) bool {
+	method, digest, ok := strings.Cut(header, "=")
+	if !ok {
+		return false
+	}
+
+	var newHash func() hash.Hash
+	switch strings.ToLower(method) {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha384":
+		newHash = sha512.New384
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return false
+	}
+
+	expected, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// randomHex returns n random bytes encoded as hex
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// EnableWebSub enables WebSub push subscriptions. publicURL is the externally
+// reachable base URL of the server, hubs deliver to {publicURL}/api/websub/callback/{token}.
+func (f *Fetcher) EnableWebSub(publicURL string) {
+	if f.webSub != nil {
+		return
+	}
+	f.webSub = NewWebSubManager(f, strings.TrimRight(publicURL, "/")+"/api/websub/callback/")
+	f.webSub.Start()
+}
+
+// GetWebSubManager returns the WebSub manager, or nil if WebSub is disabled
+func (f *Fetcher) GetWebSubManager() *WebSubManager {
+	return f.webSub
+}
+
+// FilterPushCoveredFeeds removes feeds kept up to date by an active WebSub
+// subscription from a list of feeds about to be polled. Such feeds are still
+// polled once they have not been updated for MaxRefreshInterval. The same goes
+// for email feeds whose folders are watched with IMAP IDLE. Newsletters delivered
+// by the mail receiver are never polled.
+func (f *Fetcher) FilterPushCoveredFeeds(feeds []models.Feed) []models.Feed {
+	var pushed map[int64]bool
+	if f.webSub != nil {
+		pushed, _ = f.db.GetPushActiveFeedIDs()
+	}
+
+	filtered := make([]models.Feed, 0, len(feeds))
+	for _, feed := range feeds {
+		if IsReceivedEmailFeed(feed) {
+			continue
+		}
+		covered := pushed[feed.ID] || (f.emailIdle != nil && f.emailIdle.IsWatching(feed.ID))
+		if covered && time.Since(feed.LastUpdated) < MaxRefreshInterval {
+			continue
+		}
+		filtered = append(filtered, feed)
+	}
+	return filtered
+}
@@ -0,0 +1,169 @@
+package feed
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestDiscoverWebSubLinks(t *testing.T) {
+	rss := `<?xml version="1.0"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <title>Blog</title>
+    <link>https://example.com/</link>
+    <atom:link rel="hub" href="https://hub.example.com/"/>
+    <atom:link rel="self" href="https://example.com/feed"/>
+    <item><title>Post</title><atom:link rel="hub" href="https://other.example.com/"/></item>
+  </channel>
+</rss>`
+	feed := &gofeed.Feed{}
+	discoverWebSubLinks(feed, rss)
+	if feed.Custom[webSubHubKey] != "https://hub.example.com/" || feed.Custom[webSubSelfKey] != "https://example.com/feed" {
+		t.Errorf("unexpected RSS links: %v", feed.Custom)
+	}
+
+	atom := `<feed xmlns="http://www.w3.org/2005/Atom">
+  <link rel="alternate" href="https://example.com/"/>
+  <link rel="self hub" href="https://example.com/atom"/>
+  <entry><title>Post</title></entry>
+</feed>`
+	feed = &gofeed.Feed{}
+	discoverWebSubLinks(feed, atom)
+	if feed.Custom[webSubHubKey] != "https://example.com/atom" || feed.Custom[webSubSelfKey] != "https://example.com/atom" {
+		t.Errorf("unexpected Atom links: %v", feed.Custom)
+	}
+
+	feed = &gofeed.Feed{}
+	discoverWebSubLinks(feed, `<feed xmlns="http://www.w3.org/2005/Atom"><link rel="self" href="https://example.com/atom"/></feed>`)
+	if feed.Custom != nil {
+		t.Errorf("expected no links without a hub, got %v", feed.Custom)
+	}
+}
+
+func TestVerifyWebSubSignature(t *testing.T) {
+	body := []byte("<feed/>")
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	valid := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !verifyWebSubSignature("secret", body, valid) {
+		t.Error("expected valid signature")
+	}
+	for _, header := range []string{"", valid[:20], "md5=00", strings.Replace(valid, "sha256", "sha1", 1)} {
+		if verifyWebSubSignature("secret", body, header) {
+			t.Errorf("expected %q to be rejected", header)
+		}
+	}
+	if verifyWebSubSignature("other", body, valid) {
+		t.Error("expected signature with another secret to be rejected")
+	}
+}
+
+func TestWebSubSubscriptionLifecycle(t *testing.T) {
+	db, err := sqlite.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+
+	requests := make(chan url.Values, 1)
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		requests <- r.PostForm
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer hub.Close()
+
+	f := NewFetcher(db)
+	f.webSub = NewWebSubManager(f, "https://rss.example.com/api/websub/callback/")
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Blog", URL: "https://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed: %v", err)
+	}
+	if err := db.UpdateFeedLastUpdated(feedID); err != nil {
+		t.Fatalf("UpdateFeedLastUpdated: %v", err)
+	}
+	feed, _ := db.GetFeedByID(feedID)
+
+	// Subscribing sends the request to the hub and leaves the subscription pending
+	f.webSub.onFeedFetched(*feed, &gofeed.Feed{Custom: map[string]string{webSubHubKey: hub.URL}})
+	form := <-requests
+	if form.Get("hub.mode") != "subscribe" || form.Get("hub.topic") != feed.URL || form.Get("hub.secret") == "" {
+		t.Fatalf("unexpected subscription request: %v", form)
+	}
+	sub, _ := db.GetWebSubSubscription(feedID)
+	if sub == nil || sub.State != sqlite.WebSubStatePending {
+		t.Fatalf("expected pending subscription, got %+v", sub)
+	}
+	if !strings.HasSuffix(form.Get("hub.callback"), "/"+sub.CallbackToken) {
+		t.Fatalf("unexpected callback %q", form.Get("hub.callback"))
+	}
+	if got := f.FilterPushCoveredFeeds([]models.Feed{*feed}); len(got) != 1 {
+		t.Fatal("pending subscriptions must not stop polling")
+	}
+
+	// Verification for another topic is refused, the right one activates the lease
+	if _, err := f.webSub.VerifyIntent(sub.CallbackToken, url.Values{"hub.mode": {"subscribe"}, "hub.topic": {"https://evil.example.com"}, "hub.challenge": {"x"}}); err == nil {
+		t.Fatal("expected topic mismatch to be refused")
+	}
+	challenge, err := f.webSub.VerifyIntent(sub.CallbackToken, url.Values{
+		"hub.mode": {"subscribe"}, "hub.topic": {feed.URL}, "hub.challenge": {"c123"}, "hub.lease_seconds": {"3600"},
+	})
+	if err != nil || challenge != "c123" {
+		t.Fatalf("VerifyIntent = %q, %v", challenge, err)
+	}
+	sub, _ = db.GetWebSubSubscription(feedID)
+	if sub.State != sqlite.WebSubStateActive || time.Until(sub.ExpiresAt) > time.Hour {
+		t.Fatalf("expected active subscription with a 1h lease, got %+v", sub)
+	}
+	if got := f.FilterPushCoveredFeeds([]models.Feed{*feed}); len(got) != 0 {
+		t.Fatal("expected pushed feed to be skipped by polling")
+	}
+
+	// Pushed content must be signed with the subscription secret
+	body := []byte(`<feed xmlns="http://www.w3.org/2005/Atom"><title>Blog</title>
+<entry><id>urn:1</id><title>Pushed post</title><link href="https://example.com/pushed"/><updated>2024-01-01T00:00:00Z</updated></entry></feed>`)
+	if err := f.webSub.HandlePush(context.Background(), sub.CallbackToken, body, "sha256=00"); err != ErrWebSubInvalidSignature {
+		t.Fatalf("expected ErrWebSubInvalidSignature, got %v", err)
+	}
+	if err := f.webSub.HandlePush(context.Background(), "unknown", body, ""); err != ErrWebSubUnknownCallback {
+		t.Fatalf("expected ErrWebSubUnknownCallback, got %v", err)
+	}
+	mac := hmac.New(sha256.New, []byte(sub.Secret))
+	mac.Write(body)
+	if err := f.webSub.HandlePush(context.Background(), sub.CallbackToken, body, "sha256="+hex.EncodeToString(mac.Sum(nil))); err != nil {
+		t.Fatalf("HandlePush: %v", err)
+	}
+	articles, err := db.GetArticles("", feedID, "", false, 10, 0)
+	if err != nil || len(articles) != 1 || articles[0].Title != "Pushed post" {
+		t.Fatalf("expected pushed article to be saved, got %v (%v)", articles, err)
+	}
+
+	// A lapsed lease falls back to polling
+	if _, err := db.ExpireWebSubSubscriptions(time.Now().Add(2*time.Hour), webSubPendingTimeout); err != nil {
+		t.Fatalf("ExpireWebSubSubscriptions: %v", err)
+	}
+	sub, _ = db.GetWebSubSubscription(feedID)
+	if sub.State != sqlite.WebSubStateExpired {
+		t.Fatalf("expected expired subscription, got %s", sub.State)
+	}
+	if got := f.FilterPushCoveredFeeds([]models.Feed{*feed}); len(got) != 1 {
+		t.Fatal("expected expired subscription to fall back to polling")
+	}
+}
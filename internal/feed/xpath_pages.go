@@ -0,0 +1,276 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/models"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xmlquery"
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html"
+)
+
+const (
+	// maxXPathPages bounds the pages of an XPath feed read per refresh, whatever its configuration
+	maxXPathPages = 20
+	// maxXPathItemPages bounds the item pages fetched per refresh; the other new items
+	// are saved with what their list page has
+	maxXPathItemPages = 30
+)
+
+// ValidateXPathPagination checks the next page and item page expressions of an XPath feed
+func ValidateXPathPagination(pagination *models.FeedPagination) error {
+	if pagination == nil {
+		return nil
+	}
+	doc := &html.Node{Type: html.DocumentNode}
+	for _, field := range []struct{ name, expr string }{
+		{"next page", pagination.Next},
+		{"item page content", pagination.DetailContent},
+		{"item page timestamp", pagination.DetailTimestamp},
+	} {
+		if field.expr == "" {
+			continue
+		}
+		if _, err := htmlquery.Query(doc, field.expr); err != nil {
+			return fmt.Errorf("invalid %s XPath %q: %w", field.name, field.expr, err)
+		}
+	}
+	if pagination.MaxPages < 0 {
+		return errors.New("the page limit can't be negative")
+	}
+	return nil
+}
+
+// isPaginatedXPathFeed reports whether the read items of an XPath feed are remembered,
+// for its pagination to stop at them
+func isPaginatedXPathFeed(feed *models.Feed) bool {
+	return (feed.Type == "HTML+XPath" || feed.Type == "XML+XPath") && feed.Pagination != nil
+}
+
+// xpathMaxPages returns the number of pages of an XPath feed read per refresh
+func xpathMaxPages(feed *models.Feed) int {
+	if feed.Pagination == nil || feed.Pagination.Next == "" {
+		return 1
+	}
+	return min(max(feed.Pagination.MaxPages, 1), maxXPathPages)
+}
+
+// nextHTMLXPathPage returns the URL of the page after an HTML page, or "" if there is none
+func nextHTMLXPathPage(doc *html.Node, feed *models.Feed, pageURL string) string {
+	if feed.Pagination == nil || feed.Pagination.Next == "" {
+		return ""
+	}
+	node, err := htmlquery.Query(doc, feed.Pagination.Next)
+	if err != nil {
+		log.Printf("Invalid next page XPath of feed %s: %v", feed.URL, err)
+		return ""
+	}
+	if node == nil {
+		return ""
+	}
+	next := htmlquery.SelectAttr(node, "href")
+	if next == "" {
+		next = htmlquery.InnerText(node)
+	}
+	return resolveXPathPageURL(pageURL, next)
+}
+
+// nextXMLXPathPage returns the URL of the page after an XML page, or "" if there is none
+func nextXMLXPathPage(doc *xmlquery.Node, feed *models.Feed, pageURL string) string {
+	if feed.Pagination == nil || feed.Pagination.Next == "" {
+		return ""
+	}
+	node, err := xmlquery.Query(doc, feed.Pagination.Next)
+	if err != nil {
+		log.Printf("Invalid next page XPath of feed %s: %v", feed.URL, err)
+		return ""
+	}
+	if node == nil {
+		return ""
+	}
+	next := node.SelectAttr("href")
+	if next == "" {
+		next = node.InnerText()
+	}
+	return resolveXPathPageURL(pageURL, next)
+}
+
+// resolveXPathPageURL resolves a link of a page against the page URL, keeping only web links
+func resolveXPathPageURL(pageURL, link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	ref, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	resolved := base.ResolveReference(ref)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return ""
+	}
+	return resolved.String()
+}
+
+// findKnownXPathItems adds the items of a page that were already read to known, and
+// reports whether there was any, at which point the pagination stops
+func (f *Fetcher) findKnownXPathItems(feed *models.Feed, items []*gofeed.Item, known map[string]bool) bool {
+	if !isPaginatedXPathFeed(feed) || len(items) == 0 {
+		return false
+	}
+	uids := make([]string, 0, len(items))
+	for _, item := range items {
+		uids = append(uids, item.GUID)
+	}
+	pageKnown, err := f.db.GetKnownItemUIDs(feed.ID, uids)
+	if err != nil {
+		// Without them, the page limit still bounds the pagination
+		log.Printf("Error reading the known items of feed %d: %v", feed.ID, err)
+		return false
+	}
+	for uid := range pageKnown {
+		known[uid] = true
+	}
+	return len(pageKnown) > 0
+}
+
+// fetchXPathItemPages completes the new items of an XPath feed with the content and date
+// of their own pages. Known items were read with their page before and are left out. The
+// new items beyond the item pages fetched per refresh keep what their list page has, as
+// the pagination of the next refreshes stops before reaching them again.
+func (f *Fetcher) fetchXPathItemPages(ctx context.Context, feed *models.Feed, items []*gofeed.Item, known map[string]bool) []*gofeed.Item {
+	result := make([]*gofeed.Item, 0, len(items))
+	fetched := 0
+	for _, item := range items {
+		if known[item.GUID] {
+			continue
+		}
+		link, err := url.Parse(item.Link)
+		if err != nil || (link.Scheme != "http" && link.Scheme != "https") || strings.HasPrefix(link.Fragment, "xpath-") {
+			// Items without link of their own have no page
+			result = append(result, item)
+			continue
+		}
+		if fetched >= maxXPathItemPages || ctx.Err() != nil {
+			result = append(result, item)
+			continue
+		}
+		fetched++
+		if err := f.completeXPathItem(ctx, feed, item); err != nil {
+			log.Printf("Error reading the page of item %s of feed %s: %v", item.Link, feed.URL, err)
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// completeXPathItem reads the content and date of an item from its page
+func (f *Fetcher) completeXPathItem(ctx context.Context, feed *models.Feed, item *gofeed.Item) error {
+	body, err := f.fetchXPathPage(ctx, feed, item.Link)
+	if err != nil {
+		return err
+	}
+	doc, err := htmlquery.Parse(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	pagination := feed.Pagination
+	if pagination.DetailContent != "" {
+		node, err := htmlquery.Query(doc, pagination.DetailContent)
+		if err != nil {
+			return err
+		}
+		if node != nil {
+			item.Content = htmlquery.OutputHTML(node, true)
+		}
+	}
+	if pagination.DetailTimestamp != "" {
+		node, err := htmlquery.Query(doc, pagination.DetailTimestamp)
+		if err != nil {
+			return err
+		}
+		if node != nil {
+			if t := parseXPathTimestamp(htmlquery.InnerText(node), feed.XPathItemTimeFormat); t != nil {
+				item.PublishedParsed = t
+			}
+		}
+	}
+	return nil
+}
+
+// rememberFeedItems records the saved items of a paginated XPath or mail archive feed
+// as read, by GUID
+func (f *Fetcher) rememberFeedItems(feed models.Feed, items []*gofeed.Item) {
+	uids := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.GUID != "" {
+			uids = append(uids, item.GUID)
+		}
+	}
+	if err := f.db.AddKnownItemUIDs(feed.ID, uids); err != nil {
+		log.Printf("Error remembering the items of feed %d: %v", feed.ID, err)
+	}
+}
+
+// parseXPathTimestamp parses the timestamp of an XPath item with the feed's time format,
+// or common formats when it has none
+func parseXPathTimestamp(value, format string) *time.Time {
+	timeStr := strings.TrimSpace(value)
+	// Remove icon text if present (e.g., "calendar_month 2025-12" -> "2025-12")
+	if strings.Contains(timeStr, " ") {
+		parts := strings.Split(timeStr, " ")
+		// Find the date part (usually the last part that looks like a date)
+		for i := len(parts) - 1; i >= 0; i-- {
+			part := strings.TrimSpace(parts[i])
+			if part != "" && (strings.Contains(part, "-") || strings.Contains(part, "/") || len(part) >= 4) {
+				timeStr = part
+				break
+			}
+		}
+	}
+	if timeStr == "" {
+		return nil
+	}
+
+	var parsedTime time.Time
+	var err error
+	if format != "" {
+		parsedTime, err = time.Parse(format, timeStr)
+	} else {
+		// Try common formats
+		formats := []string{
+			time.RFC3339,
+			time.RFC1123,
+			"2006-01-02T15:04:05Z07:00",
+			"2006-01-02 15:04:05",
+			"2006-01-02",
+			"2006/01/02",
+			"01/02/2006",
+			"2006-01",
+		}
+		for _, format := range formats {
+			parsedTime, err = time.Parse(format, timeStr)
+			if err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil
+	}
+	return &parsedTime
+}
@@ -0,0 +1,213 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+func TestXPathFeedPagination(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	pages := map[string][]int{"": {5, 6}, "2": {3, 4}, "3": {1, 2}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		hits[r.URL.RequestURI()]++
+		switch {
+		case r.URL.Path == "/blog":
+			page := r.URL.Query().Get("page")
+			posts, ok := pages[page]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			var b strings.Builder
+			for _, id := range posts {
+				fmt.Fprintf(&b, `<article><a href="/post/%d">Post %d</a></article>`, id, id)
+			}
+			if next := map[string]string{"": "2", "2": "3"}[page]; next != "" {
+				fmt.Fprintf(&b, `<a class="next" href="?page=%s">Older</a>`, next)
+			}
+			w.Write([]byte(b.String()))
+		case strings.HasPrefix(r.URL.Path, "/post/"):
+			id := strings.TrimPrefix(r.URL.Path, "/post/")
+			fmt.Fprintf(w, `<div class="body">Body %s</div><time>2026-01-0%sT10:00:00Z</time>`, id, id)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	db, err := sqlite.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	defer db.Close()
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	fetcher := NewFetcher(db)
+
+	feed := &models.Feed{
+		Title:               "Blog",
+		URL:                 server.URL + "/blog",
+		Type:                "HTML+XPath",
+		XPathItem:           "//article",
+		XPathItemTitle:      ".//a",
+		XPathItemUri:        ".//a/@href",
+		XPathItemTimeFormat: time.RFC3339,
+	}
+	id, err := db.AddFeedForUser(1, feed)
+	if err != nil {
+		t.Fatalf("AddFeedForUser: %v", err)
+	}
+	if err := db.SetFeedPagination(id, &models.FeedPagination{
+		Next:            "//a[@class='next']/@href",
+		MaxPages:        5,
+		DetailContent:   "//div[@class='body']",
+		DetailTimestamp: "//time",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	refresh := func() {
+		t.Helper()
+		stored, err := db.GetFeedByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := fetcher.fetchAndSaveFeed(context.Background(), *stored); err != nil {
+			t.Fatalf("fetchAndSaveFeed: %v", err)
+		}
+	}
+
+	// The first refresh backfills all the pages
+	refresh()
+	articles, err := db.GetArticles("", id, "", false, 20, 0)
+	if err != nil || len(articles) != 6 {
+		t.Fatalf("expected 6 articles after the backfill, got %d (%v)", len(articles), err)
+	}
+	for _, article := range articles {
+		want := "2026-01-0" + strings.TrimPrefix(article.Title, "Post ")
+		if got := article.PublishedAt.UTC().Format("2006-01-02"); got != want {
+			t.Errorf("%s: expected the date of its page %s, got %s", article.Title, want, got)
+		}
+	}
+
+	// Later refreshes stop at the first page with known items
+	mu.Lock()
+	pages[""] = []int{7, 5, 6}
+	mu.Unlock()
+	refresh()
+	articles, err = db.GetArticles("", id, "", false, 20, 0)
+	if err != nil || len(articles) != 7 {
+		t.Fatalf("expected 7 articles, got %d (%v)", len(articles), err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if hits["/blog?page=2"] != 1 || hits["/blog?page=3"] != 1 {
+		t.Errorf("expected the older pages to be read once, got %v", hits)
+	}
+	for _, post := range []string{"/post/1", "/post/5", "/post/7"} {
+		if hits[post] != 1 {
+			t.Errorf("expected %s to be fetched once, got %d", post, hits[post])
+		}
+	}
+}
+
+func TestValidateXPathPagination(t *testing.T) {
+	if err := ValidateXPathPagination(&models.FeedPagination{Next: "//a[@rel='next']/@href", DetailContent: "//article"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateXPathPagination(&models.FeedPagination{DetailTimestamp: "//time["}); err == nil {
+		t.Error("expected an error for an invalid XPath")
+	}
+}
+
+func TestXPathFeedItemPageLimit(t *testing.T) {
+	var mu sync.Mutex
+	itemPages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/news":
+			// Two pages of 25 items
+			page := 0
+			if r.URL.Query().Get("page") == "2" {
+				page = 1
+			}
+			for i := 0; i < 25; i++ {
+				fmt.Fprintf(w, `<article><a href="/item/%d">Item %d</a></article>`, page*25+i, page*25+i)
+			}
+			if page == 0 {
+				w.Write([]byte(`<a class="next" href="?page=2">Older</a>`))
+			}
+		case strings.HasPrefix(r.URL.Path, "/item/"):
+			mu.Lock()
+			itemPages++
+			mu.Unlock()
+			w.Write([]byte(`<time>2026-01-02T10:00:00Z</time>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	db, err := sqlite.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	defer db.Close()
+	if err := db.Init(); err != nil {
+		t.Fatalf("db Init error: %v", err)
+	}
+	fetcher := NewFetcher(db)
+
+	id, err := db.AddFeedForUser(1, &models.Feed{
+		Title:               "News",
+		URL:                 server.URL + "/news",
+		Type:                "HTML+XPath",
+		XPathItem:           "//article",
+		XPathItemTitle:      ".//a",
+		XPathItemUri:        ".//a/@href",
+		XPathItemTimeFormat: time.RFC3339,
+	})
+	if err != nil {
+		t.Fatalf("AddFeedForUser: %v", err)
+	}
+	if err := db.SetFeedPagination(id, &models.FeedPagination{
+		Next:            "//a[@class='next']/@href",
+		MaxPages:        2,
+		DetailTimestamp: "//time",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	feed, err := db.GetFeedByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fetcher.fetchAndSaveFeed(context.Background(), *feed); err != nil {
+		t.Fatalf("fetchAndSaveFeed: %v", err)
+	}
+
+	// The items beyond the item pages fetched per refresh are saved too
+	articles, err := db.GetArticles("", id, "", false, 100, 0)
+	if err != nil || len(articles) != 50 {
+		t.Fatalf("expected the 50 backfilled articles, got %d (%v)", len(articles), err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if itemPages != maxXPathItemPages {
+		t.Errorf("expected %d item pages, got %d", maxXPathItemPages, itemPages)
+	}
+}
@@ -0,0 +1,252 @@
+// Package fulltext extracts the full content of articles from their original pages.
+// Pages of sites with a site rule are extracted with the rule's XPath expressions,
+// other pages with readability.
+package fulltext
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"codeberg.org/readeck/go-readability/v2"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// BrowserUserAgent is the User-Agent sent when fetching article pages
+const BrowserUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+const (
+	// maxPages caps the pages followed through next-page links
+	maxPages = 10
+	// maxPageSize caps the size of a fetched page
+	maxPageSize = 10 * 1024 * 1024
+)
+
+// ErrNoContent is returned when no content could be extracted from a page
+var ErrNoContent = errors.New("no content extracted")
+
+// Result is the content extracted from an article page
+type Result struct {
+	Content  string `json:"content"`
+	URL      string `json:"url"`                 // URL of the first page fetched, after rewrites and redirects
+	Pages    int    `json:"pages"`               // Number of pages the content was extracted from
+	RuleHost string `json:"rule_host,omitempty"` // Host of the site rule used, empty with readability
+}
+
+// Extract fetches an article page with the client and extracts its content, using
+// the site rule when it's not nil
+func Extract(ctx context.Context, client *http.Client, pageURL string, rule *SiteRule) (*Result, error) {
+	result := &Result{}
+	if rule != nil {
+		result.RuleHost = rule.Host
+		pageURL = rewriteURL(pageURL, rule.URLRewrites)
+	}
+
+	doc, base, err := fetchPage(ctx, client, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefer the single-page version of multi-page articles
+	if rule != nil {
+		if link := findLink(doc, base, rule.SinglePageLink); link != "" && link != base.String() {
+			if singleDoc, singleBase, err := fetchPage(ctx, client, link); err == nil {
+				doc, base = singleDoc, singleBase
+			}
+		}
+	}
+	result.URL = base.String()
+
+	var content strings.Builder
+	visited := map[string]bool{base.String(): true}
+	for {
+		var next string
+		if rule != nil {
+			next = findLink(doc, base, rule.NextPageLink)
+		}
+
+		pageContent, err := extractPage(doc, base, rule)
+		if err != nil {
+			if result.Pages == 0 {
+				return nil, err
+			}
+			break
+		}
+		content.WriteString(pageContent)
+		result.Pages++
+
+		if next == "" || visited[next] || result.Pages >= maxPages {
+			break
+		}
+		visited[next] = true
+		if doc, base, err = fetchPage(ctx, client, next); err != nil {
+			break
+		}
+	}
+
+	result.Content = content.String()
+	return result, nil
+}
+
+// rewriteURL applies the first matching URL rewrite
+func rewriteURL(pageURL string, rewrites []URLRewrite) string {
+	for _, rewrite := range rewrites {
+		re, err := regexp.Compile(rewrite.Pattern)
+		if err != nil || !re.MatchString(pageURL) {
+			continue
+		}
+		return re.ReplaceAllString(pageURL, rewrite.Replacement)
+	}
+	return pageURL
+}
+
+// fetchPage fetches and parses an HTML page, returning its final URL
+func fetchPage(ctx context.Context, client *http.Client, pageURL string) (*html.Node, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create request: %w", err)
+	}
+
+	// Add browser-like headers to bypass anti-bot protections
+	// Note: Don't set Accept-Encoding - let Go's http.Transport handle it automatically
+	req.Header.Set("User-Agent", BrowserUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9,zh-CN;q=0.8,zh;q=0.7")
+	req.Header.Set("DNT", "1")
+	req.Header.Set("Connection", "keep-alive")
+	req.Header.Set("Upgrade-Insecure-Requests", "1")
+	req.Header.Set("Sec-Fetch-Dest", "document")
+	req.Header.Set("Sec-Fetch-Mode", "navigate")
+	req.Header.Set("Sec-Fetch-Site", "none")
+	req.Header.Set("Sec-Fetch-User", "?1")
+	req.Header.Set("Cache-Control", "max-age=0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetch page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	doc, err := html.Parse(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse page: %w", err)
+	}
+	return doc, resp.Request.URL, nil
+}
+
+// findLink returns the absolute URL of the first link matched by the expressions
+func findLink(doc *html.Node, base *url.URL, exprs []string) string {
+	for _, expr := range exprs {
+		nodes, err := htmlquery.QueryAll(doc, expr)
+		if err != nil {
+			continue
+		}
+		for _, n := range nodes {
+			// Expressions select either the link element or its href attribute
+			href := htmlquery.SelectAttr(n, "href")
+			if n.Data == "href" && len(n.Attr) == 0 {
+				href = htmlquery.InnerText(n)
+			}
+			if link := resolveURL(base, strings.TrimSpace(href)); link != "" {
+				return link
+			}
+		}
+	}
+	return ""
+}
+
+// resolveURL resolves an http(s) reference against the base URL
+func resolveURL(base *url.URL, ref string) string {
+	if ref == "" || strings.HasPrefix(ref, "#") {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	u.Fragment = ""
+	return u.String()
+}
+
+// extractPage extracts the content of a page with the rule, falling back to readability
+// when the rule has no body expression matching the page
+func extractPage(doc *html.Node, base *url.URL, rule *SiteRule) (string, error) {
+	if rule != nil {
+		stripNodes(doc, rule)
+		for _, expr := range rule.Body {
+			nodes, err := htmlquery.QueryAll(doc, expr)
+			if err != nil || len(nodes) == 0 {
+				continue
+			}
+			var buf bytes.Buffer
+			for _, n := range nodes {
+				absolutizeURLs(n, base)
+				if err := html.Render(&buf, n); err != nil {
+					return "", fmt.Errorf("render HTML: %w", err)
+				}
+			}
+			return buf.String(), nil
+		}
+	}
+
+	article, err := readability.FromDocument(doc, base)
+	if err != nil {
+		return "", fmt.Errorf("readability parse: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := article.RenderHTML(&buf); err != nil {
+		return "", fmt.Errorf("render HTML: %w", err)
+	}
+	if strings.TrimSpace(buf.String()) == "" {
+		return "", ErrNoContent
+	}
+	return buf.String(), nil
+}
+
+// stripNodes removes the elements matched by the strip rules from the page
+func stripNodes(doc *html.Node, rule *SiteRule) {
+	exprs := append([]string{}, rule.Strip...)
+	for _, idOrClass := range rule.StripIDOrClass {
+		exprs = append(exprs, fmt.Sprintf(
+			"//*[@id='%s' or contains(concat(' ', normalize-space(@class), ' '), ' %s ')]", idOrClass, idOrClass))
+	}
+	for _, expr := range exprs {
+		nodes, err := htmlquery.QueryAll(doc, expr)
+		if err != nil {
+			continue
+		}
+		for _, n := range nodes {
+			if n.Parent != nil {
+				n.Parent.RemoveChild(n)
+			}
+		}
+	}
+}
+
+// absolutizeURLs makes the links and image sources of the content absolute
+func absolutizeURLs(n *html.Node, base *url.URL) {
+	if n.Type == html.ElementNode {
+		for i, attr := range n.Attr {
+			if attr.Key != "href" && attr.Key != "src" {
+				continue
+			}
+			if u, err := base.Parse(strings.TrimSpace(attr.Val)); err == nil && !strings.HasPrefix(attr.Val, "#") {
+				n.Attr[i].Val = u.String()
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		absolutizeURLs(c, base)
+	}
+}
@@ -0,0 +1,139 @@
+package fulltext
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testSiteConfig = `# example.com
+body: //div[@class='article']
+strip: //aside
+strip_id_or_class: ad
+next_page_link: //a[@rel='next']/@href
+rewrite_url: /story/(\d+) => /article/$1
+author: //span[@class='byline']
+test_url: https://example.com/article/1
+`
+
+func TestParseSiteConfig(t *testing.T) {
+	rule, err := ParseSiteConfig(".www.Example.com.txt", strings.NewReader(testSiteConfig))
+	if err != nil {
+		t.Fatalf("ParseSiteConfig: %v", err)
+	}
+	if rule.Host != "example.com" {
+		t.Errorf("host = %q, want example.com", rule.Host)
+	}
+	if len(rule.Body) != 1 || len(rule.Strip) != 1 || len(rule.StripIDOrClass) != 1 || len(rule.NextPageLink) != 1 {
+		t.Errorf("unexpected rule %+v", rule)
+	}
+	if len(rule.URLRewrites) != 1 || rule.URLRewrites[0].Replacement != "/article/$1" {
+		t.Errorf("unexpected URL rewrites %+v", rule.URLRewrites)
+	}
+	if rule.TestURL != "https://example.com/article/1" {
+		t.Errorf("test URL = %q", rule.TestURL)
+	}
+
+	if _, err := ParseSiteConfig("example.com", strings.NewReader("body: //div[")); err == nil {
+		t.Error("expected an error for an invalid XPath")
+	}
+	if _, err := ParseSiteConfig("", strings.NewReader("body: //div")); err == nil {
+		t.Error("expected an error without host")
+	}
+}
+
+func TestMatchSiteRule(t *testing.T) {
+	rules := []SiteRule{{Host: "example.com"}, {Host: "blog.example.com"}, {Host: "other.org"}}
+	tests := map[string]string{
+		"https://example.com/a":          "example.com",
+		"https://www.example.com/a":      "example.com",
+		"https://blog.example.com/a":     "blog.example.com",
+		"https://notexample.com/a":       "",
+		"https://other.org.evil.net/a":   "",
+		"https://news.blog.example.com/": "blog.example.com",
+	}
+	for pageURL, want := range tests {
+		got := ""
+		if rule := MatchSiteRule(rules, pageURL); rule != nil {
+			got = rule.Host
+		}
+		if got != want {
+			t.Errorf("MatchSiteRule(%s) = %q, want %q", pageURL, got, want)
+		}
+	}
+}
+
+func TestExtract_SiteRule(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RequestURI() {
+		case "/article/1":
+			w.Write([]byte(`<html><body><div class="article"><p>Page one <a href="/about">about</a></p>` +
+				`<aside>related</aside><div class="promo ad">buy</div></div>` +
+				`<a rel="next" href="/article/1?page=2">next</a></body></html>`))
+		case "/article/1?page=2":
+			// The last page links to itself, which must not loop
+			w.Write([]byte(`<html><body><div class="article"><p>Page two</p></div>` +
+				`<a rel="next" href="/article/1?page=2">next</a></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	rule, err := ParseSiteConfig("127.0.0.1", strings.NewReader(testSiteConfig))
+	if err != nil {
+		t.Fatalf("ParseSiteConfig: %v", err)
+	}
+	result, err := Extract(context.Background(), srv.Client(), srv.URL+"/story/1", rule)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+
+	if result.URL != srv.URL+"/article/1" || result.Pages != 2 || result.RuleHost != "127.0.0.1" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	for _, want := range []string{"Page one", "Page two", `href="` + srv.URL + `/about"`} {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("content %q lacks %q", result.Content, want)
+		}
+	}
+	for _, stripped := range []string{"related", "buy"} {
+		if strings.Contains(result.Content, stripped) {
+			t.Errorf("content %q contains stripped %q", result.Content, stripped)
+		}
+	}
+}
+
+func TestExtract_SinglePageLink(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("print") == "1" {
+			w.Write([]byte(`<html><body><article>Whole story</article></body></html>`))
+			return
+		}
+		w.Write([]byte(`<html><body><article>First part</article><a class="print" href="?print=1">print</a></body></html>`))
+	}))
+	defer srv.Close()
+
+	rule := &SiteRule{Host: "127.0.0.1", Body: []string{"//article"}, SinglePageLink: []string{"//a[@class='print']"}}
+	if err := rule.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	result, err := Extract(context.Background(), srv.Client(), srv.URL+"/story", rule)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if !strings.Contains(result.Content, "Whole story") || result.URL != srv.URL+"/story?print=1" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestExtract_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	if _, err := Extract(context.Background(), srv.Client(), srv.URL, nil); err == nil {
+		t.Error("expected an error for a missing page")
+	}
+}
@@ -0,0 +1,245 @@
+package fulltext
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/store/sqlite"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// SiteRule holds the extraction rules of a site, in the spirit of the ftr-site-config files
+// of Full-Text RSS. XPath expressions are tried in order; the first one matching is used.
+type SiteRule struct {
+	ID             int64        `json:"id"`
+	Host           string       `json:"host"`                        // Host the rule applies to, including its subdomains
+	Body           []string     `json:"body,omitempty"`              // XPath of the article body
+	Strip          []string     `json:"strip,omitempty"`             // XPath of elements removed from the page
+	StripIDOrClass []string     `json:"strip_id_or_class,omitempty"` // IDs or classes of elements removed from the page
+	NextPageLink   []string     `json:"next_page_link,omitempty"`    // XPath of the link to the next page of the article
+	SinglePageLink []string     `json:"single_page_link,omitempty"`  // XPath of the link to the single-page version of the article
+	URLRewrites    []URLRewrite `json:"url_rewrites,omitempty"`      // Rewrites applied to article URLs before fetching
+	TestURL        string       `json:"test_url,omitempty"`          // Sample article URL
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// URLRewrite rewrites article URLs, e.g. to the printable version of the article
+type URLRewrite struct {
+	Pattern     string `json:"pattern"`     // Regular expression matched against the URL
+	Replacement string `json:"replacement"` // Replacement, which may refer to groups as $1
+}
+
+// siteRuleConfig is the stored form of the extraction rules of a SiteRule
+type siteRuleConfig struct {
+	Body           []string     `json:"body,omitempty"`
+	Strip          []string     `json:"strip,omitempty"`
+	StripIDOrClass []string     `json:"strip_id_or_class,omitempty"`
+	NextPageLink   []string     `json:"next_page_link,omitempty"`
+	SinglePageLink []string     `json:"single_page_link,omitempty"`
+	URLRewrites    []URLRewrite `json:"url_rewrites,omitempty"`
+}
+
+// NormalizeHost returns the host a rule is stored under: lowercase, without port,
+// "www." prefix or the leading dot of ftr-site-config file names
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	if h, _, found := strings.Cut(host, ":"); found {
+		host = h
+	}
+	host = strings.TrimSuffix(host, ".txt")
+	host = strings.TrimPrefix(host, ".")
+	return strings.TrimPrefix(host, "www.")
+}
+
+// Validate checks the host, XPath expressions and URL rewrites of a rule
+func (r *SiteRule) Validate() error {
+	r.Host = NormalizeHost(r.Host)
+	if r.Host == "" {
+		return errors.New("site rule requires a host")
+	}
+
+	empty := &html.Node{Type: html.DocumentNode}
+	for _, list := range [][]string{r.Body, r.Strip, r.NextPageLink, r.SinglePageLink} {
+		for _, expr := range list {
+			if _, err := htmlquery.QueryAll(empty, expr); err != nil {
+				return fmt.Errorf("invalid XPath %q: %w", expr, err)
+			}
+		}
+	}
+	for _, idOrClass := range r.StripIDOrClass {
+		if idOrClass == "" || strings.ContainsAny(idOrClass, `'" `) {
+			return fmt.Errorf("invalid id or class %q", idOrClass)
+		}
+	}
+	for _, rewrite := range r.URLRewrites {
+		if _, err := regexp.Compile(rewrite.Pattern); err != nil {
+			return fmt.Errorf("invalid URL rewrite pattern %q: %w", rewrite.Pattern, err)
+		}
+	}
+	if r.TestURL != "" {
+		if u, err := url.Parse(r.TestURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid test URL %q", r.TestURL)
+		}
+	}
+	return nil
+}
+
+// ParseSiteConfig parses a rule in the ftr-site-config text format, with one
+// "directive: value" per line. The supported directives are body, strip,
+// strip_id_or_class, next_page_link, single_page_link and test_url, plus
+// "rewrite_url: <regexp> => <replacement>". Other directives are ignored.
+func ParseSiteConfig(host string, r io.Reader) (*SiteRule, error) {
+	rule := &SiteRule{Host: host}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		directive, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected \"directive: value\"", lineNum)
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		switch strings.TrimSpace(directive) {
+		case "body":
+			rule.Body = append(rule.Body, value)
+		case "strip":
+			rule.Strip = append(rule.Strip, value)
+		case "strip_id_or_class":
+			rule.StripIDOrClass = append(rule.StripIDOrClass, value)
+		case "next_page_link":
+			rule.NextPageLink = append(rule.NextPageLink, value)
+		case "single_page_link":
+			rule.SinglePageLink = append(rule.SinglePageLink, value)
+		case "test_url":
+			if rule.TestURL == "" {
+				rule.TestURL = value
+			}
+		case "rewrite_url":
+			pattern, replacement, found := strings.Cut(value, "=>")
+			if !found {
+				return nil, fmt.Errorf("line %d: expected \"rewrite_url: <regexp> => <replacement>\"", lineNum)
+			}
+			rule.URLRewrites = append(rule.URLRewrites, URLRewrite{
+				Pattern:     strings.TrimSpace(pattern),
+				Replacement: strings.TrimSpace(replacement),
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// MatchSiteRule returns the rule of the most specific host matching the URL, or nil
+func MatchSiteRule(rules []SiteRule, pageURL string) *SiteRule {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+
+	var best *SiteRule
+	for i := range rules {
+		ruleHost := rules[i].Host
+		if host != ruleHost && !strings.HasSuffix(host, "."+ruleHost) {
+			continue
+		}
+		if best == nil || len(ruleHost) > len(best.Host) {
+			best = &rules[i]
+		}
+	}
+	return best
+}
+
+// LoadSiteRules returns the site rules of a user
+func LoadSiteRules(db *sqlite.DB, userID int64) ([]SiteRule, error) {
+	records, err := db.GetSiteRulesForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]SiteRule, 0, len(records))
+	for _, record := range records {
+		rule, err := siteRuleFromRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("site rule %d: %w", record.ID, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SaveSiteRule creates or updates a site rule of a user. A new rule gets its ID assigned.
+func SaveSiteRule(db *sqlite.DB, userID int64, rule *SiteRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	config, err := json.Marshal(siteRuleConfig{
+		Body:           rule.Body,
+		Strip:          rule.Strip,
+		StripIDOrClass: rule.StripIDOrClass,
+		NextPageLink:   rule.NextPageLink,
+		SinglePageLink: rule.SinglePageLink,
+		URLRewrites:    rule.URLRewrites,
+	})
+	if err != nil {
+		return err
+	}
+	record := sqlite.SiteRuleRecord{
+		UserID:  userID,
+		ID:      rule.ID,
+		Host:    rule.Host,
+		Config:  string(config),
+		TestURL: rule.TestURL,
+	}
+	if err := db.SaveSiteRuleForUser(&record); err != nil {
+		return err
+	}
+	rule.ID = record.ID
+	return nil
+}
+
+// siteRuleFromRecord converts a stored site rule
+func siteRuleFromRecord(record sqlite.SiteRuleRecord) (SiteRule, error) {
+	var config siteRuleConfig
+	if err := json.Unmarshal([]byte(record.Config), &config); err != nil {
+		return SiteRule{}, err
+	}
+	return SiteRule{
+		ID:             record.ID,
+		Host:           record.Host,
+		Body:           config.Body,
+		Strip:          config.Strip,
+		StripIDOrClass: config.StripIDOrClass,
+		NextPageLink:   config.NextPageLink,
+		SinglePageLink: config.SinglePageLink,
+		URLRewrites:    config.URLRewrites,
+		TestURL:        record.TestURL,
+		CreatedAt:      record.CreatedAt,
+		UpdatedAt:      record.UpdatedAt,
+	}, nil
+}
@@ -2,6 +2,7 @@ package jsonimport
 
 import (
 	"MavenRSS/internal/models"
+	"MavenRSS/internal/utils/urlutil"
 	"encoding/json"
 	"errors"
 	"io"
@@ -52,11 +53,20 @@ func Parse(r io.Reader) ([]models.Feed, error) {
 	return export.Feeds, nil
 }
 
-// Generate generates JSON export data from feeds
+// Generate generates JSON export data from feeds. Secrets are redacted: feed
+// credentials are replaced by models.RedactedSecret, IMAP passwords are left out
+// and URL credentials removed.
 func Generate(feeds []models.Feed) ([]byte, error) {
+	redacted := make([]models.Feed, len(feeds))
+	for i, feed := range feeds {
+		feed.URL = urlutil.StripCredentials(feed.URL)
+		feed.Auth = feed.Auth.Redacted()
+		feed.EmailPassword = ""
+		redacted[i] = feed
+	}
 	export := FeedExport{
 		Version: ExportVersion,
-		Feeds:   feeds,
+		Feeds:   redacted,
 	}
 
 	data, err := json.MarshalIndent(export, "", "  ")
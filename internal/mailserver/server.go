@@ -0,0 +1,427 @@
+// Package mailserver implements a minimal SMTP (RFC 5321) and LMTP (RFC 2033) server
+// receiving mail for local recipients. It doesn't relay mail and offers no
+// authentication; STARTTLS is left to a proxy in front of it.
+package mailserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default limits of a Server
+const (
+	DefaultMaxMessageSize = 25 << 20 // 25 MB
+	DefaultMaxRecipients  = 50
+	DefaultMaxConnections = 100
+	DefaultTimeout        = 5 * time.Minute
+
+	maxLineLength = 4096
+)
+
+// ErrServerClosed is returned by Serve after Close
+var ErrServerClosed = errors.New("mailserver: server closed")
+
+// Error is an SMTP reply returned by a Backend to refuse a recipient or a message
+type Error struct {
+	Code         int    // Reply code, e.g. 550
+	EnhancedCode string // Enhanced status code, e.g. "5.1.1"
+	Message      string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s %s", e.Code, e.EnhancedCode, e.Message)
+}
+
+// Common errors returned by backends
+var (
+	ErrUnknownRecipient = &Error{Code: 550, EnhancedCode: "5.1.1", Message: "No such recipient here"}
+	ErrRelayDenied      = &Error{Code: 550, EnhancedCode: "5.7.1", Message: "Relaying denied"}
+)
+
+// Backend accepts recipients and delivers messages
+type Backend interface {
+	// CheckRecipient returns nil if mail for the address is accepted
+	CheckRecipient(address string) error
+	// Deliver delivers a message to one of the accepted recipients
+	Deliver(ctx context.Context, from, recipient string, data []byte) error
+}
+
+// Server is an SMTP or LMTP server
+type Server struct {
+	Backend        Backend
+	Domain         string // Name announced in the greeting
+	LMTP           bool   // Speak LMTP instead of SMTP
+	MaxMessageSize int64
+	MaxRecipients  int
+	MaxConnections int
+	Timeout        time.Duration // Inactivity timeout of a connection
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// Serve accepts connections on the listener until the server is closed
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	if s.listeners == nil {
+		s.listeners = make(map[net.Listener]struct{})
+		s.conns = make(map[net.Conn]struct{})
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+
+	maxConns := s.MaxConnections
+	if maxConns <= 0 {
+		maxConns = DefaultMaxConnections
+	}
+	slots := make(chan struct{}, maxConns)
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			fmt.Fprintf(conn, "421 4.3.2 %s Too many connections, try again later\r\n", s.Domain)
+			conn.Close()
+			continue
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go func() {
+			defer func() {
+				<-slots
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+				s.wg.Done()
+			}()
+			s.handleConn(conn)
+		}()
+	}
+}
+
+// Close stops the listeners and closes the open connections
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// session is the state of an SMTP transaction
+type session struct {
+	s          *Server
+	conn       net.Conn
+	r          *bufio.Reader
+	w          *bufio.Writer
+	greeted    bool
+	from       string
+	hasFrom    bool
+	recipients []string
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	sess := &session{
+		s:    s,
+		conn: conn,
+		r:    bufio.NewReaderSize(conn, maxLineLength),
+		w:    bufio.NewWriter(conn),
+	}
+	protocol := "ESMTP"
+	if s.LMTP {
+		protocol = "LMTP"
+	}
+	sess.reply(220, "", fmt.Sprintf("%s %s ready", s.Domain, protocol))
+
+	for {
+		line, err := sess.readLine()
+		if err != nil {
+			if errors.Is(err, bufio.ErrBufferFull) {
+				sess.reply(500, "5.5.2", "Line too long")
+			}
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		if !sess.handleCommand(strings.ToUpper(verb), strings.TrimSpace(arg)) {
+			return
+		}
+	}
+}
+
+// handleCommand runs a command, returning false when the connection must be closed
+func (sess *session) handleCommand(verb, arg string) bool {
+	s := sess.s
+	switch verb {
+	case "HELO", "EHLO", "LHLO":
+		if s.LMTP != (verb == "LHLO") {
+			sess.reply(500, "5.5.1", "Unknown command")
+			return true
+		}
+		sess.greeted = true
+		sess.reset()
+		if verb == "HELO" {
+			sess.reply(250, "", s.Domain)
+			return true
+		}
+		sess.replyLines(250, s.Domain, "PIPELINING", "8BITMIME", "ENHANCEDSTATUSCODES",
+			"SIZE "+strconv.FormatInt(sess.maxMessageSize(), 10))
+
+	case "MAIL":
+		if !sess.greeted {
+			sess.reply(503, "5.5.1", "Say hello first")
+			return true
+		}
+		if sess.hasFrom {
+			sess.reply(503, "5.5.1", "Sender already given")
+			return true
+		}
+		from, params, ok := parsePath(arg, "FROM:")
+		if !ok {
+			sess.reply(501, "5.5.4", "Syntax: MAIL FROM:<address>")
+			return true
+		}
+		for _, param := range strings.Fields(params) {
+			key, value, _ := strings.Cut(param, "=")
+			if strings.EqualFold(key, "SIZE") {
+				if size, err := strconv.ParseInt(value, 10, 64); err == nil && size > sess.maxMessageSize() {
+					sess.reply(552, "5.3.4", "Message too big")
+					return true
+				}
+			}
+		}
+		sess.from = from
+		sess.hasFrom = true
+		sess.reply(250, "2.1.0", "OK")
+
+	case "RCPT":
+		if !sess.hasFrom {
+			sess.reply(503, "5.5.1", "Need MAIL first")
+			return true
+		}
+		rcpt, _, ok := parsePath(arg, "TO:")
+		if !ok || rcpt == "" {
+			sess.reply(501, "5.5.4", "Syntax: RCPT TO:<address>")
+			return true
+		}
+		maxRecipients := s.MaxRecipients
+		if maxRecipients <= 0 {
+			maxRecipients = DefaultMaxRecipients
+		}
+		if len(sess.recipients) >= maxRecipients {
+			sess.reply(452, "4.5.3", "Too many recipients")
+			return true
+		}
+		if err := s.Backend.CheckRecipient(rcpt); err != nil {
+			sess.replyError(err)
+			return true
+		}
+		sess.recipients = append(sess.recipients, rcpt)
+		sess.reply(250, "2.1.5", "OK")
+
+	case "DATA":
+		if len(sess.recipients) == 0 {
+			sess.reply(503, "5.5.1", "Need RCPT first")
+			return true
+		}
+		sess.reply(354, "", "End data with <CR><LF>.<CR><LF>")
+		return sess.handleData()
+
+	case "RSET":
+		sess.reset()
+		sess.reply(250, "2.0.0", "OK")
+	case "NOOP":
+		sess.reply(250, "2.0.0", "OK")
+	case "VRFY":
+		sess.reply(252, "2.5.0", "Cannot verify, send some mail")
+	case "QUIT":
+		sess.reply(221, "2.0.0", "Bye")
+		return false
+	case "STARTTLS", "AUTH":
+		sess.reply(502, "5.5.1", "Not implemented")
+	default:
+		sess.reply(500, "5.5.1", "Unknown command")
+	}
+	return true
+}
+
+// handleData reads the message and delivers it to each recipient
+func (sess *session) handleData() bool {
+	defer sess.reset()
+
+	maxSize := sess.maxMessageSize()
+	data, err := io.ReadAll(io.LimitReader(textproto.NewReader(sess.r).DotReader(), maxSize+1))
+	if err != nil {
+		return false
+	}
+	if int64(len(data)) > maxSize {
+		sess.reply(552, "5.3.4", "Message too big")
+		// The rest of the message can't be told apart from commands
+		return false
+	}
+	if _, err := mail.ReadMessage(bytes.NewReader(data)); err != nil {
+		sess.reply(554, "5.6.0", "Malformed message")
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sess.timeout())
+	defer cancel()
+
+	// LMTP replies for each recipient; SMTP accepts the message when it could be
+	// delivered to any recipient, as there's no way to report partial failures
+	var firstErr error
+	delivered := false
+	for _, rcpt := range sess.recipients {
+		err := sess.s.Backend.Deliver(ctx, sess.from, rcpt, data)
+		if err != nil {
+			log.Printf("Mail delivery to %s failed: %v", rcpt, err)
+		}
+		if sess.s.LMTP {
+			if err != nil {
+				sess.replyError(err)
+			} else {
+				sess.reply(250, "2.0.0", "Delivered to "+rcpt)
+			}
+			continue
+		}
+		if err == nil {
+			delivered = true
+		} else if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if !sess.s.LMTP {
+		if delivered || firstErr == nil {
+			sess.reply(250, "2.0.0", "Message accepted")
+		} else {
+			sess.replyError(firstErr)
+		}
+	}
+	return true
+}
+
+func (sess *session) reset() {
+	sess.from = ""
+	sess.hasFrom = false
+	sess.recipients = nil
+}
+
+func (sess *session) maxMessageSize() int64 {
+	if sess.s.MaxMessageSize > 0 {
+		return sess.s.MaxMessageSize
+	}
+	return DefaultMaxMessageSize
+}
+
+func (sess *session) timeout() time.Duration {
+	if sess.s.Timeout > 0 {
+		return sess.s.Timeout
+	}
+	return DefaultTimeout
+}
+
+// readLine reads a command line, refusing lines longer than maxLineLength
+func (sess *session) readLine() (string, error) {
+	sess.conn.SetReadDeadline(time.Now().Add(sess.timeout()))
+	line, err := sess.r.ReadSlice('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+func (sess *session) reply(code int, enhancedCode, message string) {
+	if enhancedCode != "" {
+		message = enhancedCode + " " + message
+	}
+	sess.conn.SetWriteDeadline(time.Now().Add(sess.timeout()))
+	fmt.Fprintf(sess.w, "%d %s\r\n", code, message)
+	sess.w.Flush()
+}
+
+func (sess *session) replyLines(code int, lines ...string) {
+	sess.conn.SetWriteDeadline(time.Now().Add(sess.timeout()))
+	for i, line := range lines {
+		sep := "-"
+		if i == len(lines)-1 {
+			sep = " "
+		}
+		fmt.Fprintf(sess.w, "%d%s%s\r\n", code, sep, line)
+	}
+	sess.w.Flush()
+}
+
+// replyError replies with the SMTP error of a backend, or a temporary failure
+func (sess *session) replyError(err error) {
+	var smtpErr *Error
+	if errors.As(err, &smtpErr) {
+		sess.reply(smtpErr.Code, smtpErr.EnhancedCode, smtpErr.Message)
+		return
+	}
+	sess.reply(451, "4.3.0", "Local error in processing")
+}
+
+// parsePath parses the "FROM:<address> params" argument of MAIL and RCPT
+func parsePath(arg, prefix string) (address, params string, ok bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	arg = strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(arg, "<") {
+		return "", "", false
+	}
+	end := strings.IndexByte(arg, '>')
+	if end < 0 {
+		return "", "", false
+	}
+	address = arg[1:end]
+	// Source routes (<@a,@b:user@c>) are obsolete and ignored
+	if i := strings.LastIndexByte(address, ':'); i >= 0 && strings.HasPrefix(address, "@") {
+		address = address[i+1:]
+	}
+	return address, strings.TrimSpace(arg[end+1:]), true
+}
@@ -0,0 +1,160 @@
+package mailserver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testBackend struct {
+	mu        sync.Mutex
+	delivered map[string]string
+}
+
+func (b *testBackend) CheckRecipient(address string) error {
+	if !strings.HasSuffix(address, "@example.com") {
+		return ErrRelayDenied
+	}
+	if strings.HasPrefix(address, "unknown") {
+		return ErrUnknownRecipient
+	}
+	return nil
+}
+
+func (b *testBackend) Deliver(ctx context.Context, from, recipient string, data []byte) error {
+	if strings.HasPrefix(recipient, "broken") {
+		return errors.New("broken mailbox")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.delivered[recipient] = string(data)
+	return nil
+}
+
+func startServer(t *testing.T, lmtp bool) (*testBackend, *textproto.Conn) {
+	t.Helper()
+	backend := &testBackend{delivered: make(map[string]string)}
+	srv := &Server{Backend: backend, Domain: "example.com", LMTP: lmtp, MaxMessageSize: 1024}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	tc := textproto.NewConn(conn)
+	t.Cleanup(func() { tc.Close() })
+	expect(t, tc, 220)
+	return backend, tc
+}
+
+func cmd(t *testing.T, tc *textproto.Conn, code int, line string) string {
+	t.Helper()
+	if err := tc.PrintfLine("%s", line); err != nil {
+		t.Fatalf("write %q: %v", line, err)
+	}
+	return expect(t, tc, code)
+}
+
+func expect(t *testing.T, tc *textproto.Conn, code int) string {
+	t.Helper()
+	_, msg, err := tc.ReadResponse(code)
+	if err != nil {
+		t.Fatalf("expected %d, got %v", code, err)
+	}
+	return msg
+}
+
+const testMessage = "From: sender@news.example\r\nSubject: Hi\r\n\r\nHello\r\n..dot\r\n"
+
+func TestServer_SMTP(t *testing.T) {
+	backend, tc := startServer(t, false)
+
+	if msg := cmd(t, tc, 250, "EHLO client"); !strings.Contains(msg, "SIZE 1024") {
+		t.Errorf("EHLO doesn't advertise the size limit: %q", msg)
+	}
+	cmd(t, tc, 503, "RCPT TO:<feed-1@example.com>")
+	cmd(t, tc, 250, "MAIL FROM:<sender@news.example>")
+	cmd(t, tc, 550, "RCPT TO:<unknown@example.com>")
+	cmd(t, tc, 550, "RCPT TO:<someone@elsewhere.example>")
+	cmd(t, tc, 250, "RCPT TO:<feed-1@example.com>")
+	cmd(t, tc, 354, "DATA")
+	tc.PrintfLine("%s.", testMessage)
+	expect(t, tc, 250)
+
+	got := backend.delivered["feed-1@example.com"]
+	if !strings.Contains(got, "Hello\n.dot\n") {
+		t.Errorf("delivered message = %q", got)
+	}
+
+	// Oversized messages are refused up front
+	cmd(t, tc, 552, "MAIL FROM:<sender@news.example> SIZE=4096")
+	cmd(t, tc, 221, "QUIT")
+}
+
+func TestServer_LMTP(t *testing.T) {
+	backend, tc := startServer(t, true)
+
+	cmd(t, tc, 500, "EHLO client")
+	cmd(t, tc, 250, "LHLO client")
+	cmd(t, tc, 250, "MAIL FROM:<>")
+	cmd(t, tc, 250, "RCPT TO:<feed-1@example.com>")
+	cmd(t, tc, 250, "RCPT TO:<broken@example.com>")
+	cmd(t, tc, 354, "DATA")
+	tc.PrintfLine("%s.", testMessage)
+
+	// One reply per recipient
+	expect(t, tc, 250)
+	expect(t, tc, 451)
+	if _, ok := backend.delivered["feed-1@example.com"]; !ok {
+		t.Error("message not delivered")
+	}
+}
+
+func TestServer_MessageTooBig(t *testing.T) {
+	_, tc := startServer(t, false)
+
+	cmd(t, tc, 250, "HELO client")
+	cmd(t, tc, 250, "MAIL FROM:<sender@news.example>")
+	cmd(t, tc, 250, "RCPT TO:<feed-1@example.com>")
+	cmd(t, tc, 354, "DATA")
+	w := bufio.NewWriter(tc.W)
+	w.WriteString("Subject: big\r\n\r\n")
+	for i := 0; i < 100; i++ {
+		w.WriteString(strings.Repeat("x", 30) + "\r\n")
+	}
+	w.WriteString(".\r\n")
+	w.Flush()
+	tc.W.Flush()
+	expect(t, tc, 552)
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		arg, prefix, address, params string
+		ok                           bool
+	}{
+		{"FROM:<a@b.c>", "FROM:", "a@b.c", "", true},
+		{"from: <a@b.c> SIZE=10 BODY=8BITMIME", "FROM:", "a@b.c", "SIZE=10 BODY=8BITMIME", true},
+		{"FROM:<>", "FROM:", "", "", true},
+		{"TO:<@relay.example:a@b.c>", "TO:", "a@b.c", "", true},
+		{"TO:a@b.c", "TO:", "", "", false},
+		{"FROM:<a@b.c>", "TO:", "", "", false},
+	}
+	for _, tt := range tests {
+		address, params, ok := parsePath(tt.arg, tt.prefix)
+		if ok != tt.ok || address != tt.address || params != tt.params {
+			t.Errorf("parsePath(%q) = %q, %q, %v", tt.arg, address, params, ok)
+		}
+	}
+}
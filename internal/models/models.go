@@ -2,6 +2,18 @@ package models
 
 import "time"
 
+// Feed types (Feed.Type) fetched with a dedicated source
+const (
+	FeedTypeJSONFeed = "JSONFeed" // JSON Feed 1.0/1.1
+	FeedTypeHFeed    = "HFeed"    // HTML page with microformats2 h-feed/h-entry markup
+
+	FeedTypeMailArchive = "MailArchive" // Newsletters of a local mbox file or Maildir directory
+	FeedTypeICalendar   = "ICalendar"   // Events of an iCalendar (.ics) calendar
+
+	FeedTypeJSONPath = "JSON+JSONPath" // JSON API mapped with the JSONPath expressions of the XPath fields
+	FeedTypeWatch    = "watch"         // Web page whose region selected by XPathItem is watched for changes
+)
+
 type Feed struct {
 	ID                 int64     `json:"id"`
 	UserID             int64     `json:"user_id"`
@@ -22,7 +34,7 @@ type Feed struct {
 	RefreshInterval    int       `json:"refresh_interval"`      // Custom refresh interval in minutes (0 = use global, -1 = intelligent, -2 = never, >0 = custom minutes)
 	IsImageMode        bool      `json:"is_image_mode"`         // Whether this feed is for image gallery mode
 	// XPath support for HTML/XML scraping
-	Type                string `json:"type"`                   // "HTML+XPath" or "XML+XPath"
+	Type                string `json:"type"`                   // "HTML+XPath", "XML+XPath", "JSON+JSONPath", "JSONFeed", "HFeed", "watch" or "email"
 	XPathItem           string `json:"xpath_item"`             // XPath to extract feed items
 	XPathItemTitle      string `json:"xpath_item_title"`       // XPath to extract item title
 	XPathItemContent    string `json:"xpath_item_content"`     // XPath to extract item content
@@ -36,13 +48,16 @@ type Feed struct {
 	ArticleViewMode     string `json:"article_view_mode"`      // Article view mode override ('global', 'webpage', 'rendered')
 	AutoExpandContent   string `json:"auto_expand_content"`    // Auto expand content mode ('global', 'enabled', 'disabled')
 	// Email/Newsletter support
-	EmailAddress    string `json:"email_address,omitempty"`     // Email address for newsletter subscriptions
-	EmailIMAPServer string `json:"email_imap_server,omitempty"` // IMAP server address
-	EmailIMAPPort   int    `json:"email_imap_port"`             // IMAP server port (default 993)
-	EmailUsername   string `json:"email_username,omitempty"`    // IMAP username
-	EmailPassword   string `json:"email_password,omitempty"`    // IMAP password (encrypted)
-	EmailFolder     string `json:"email_folder"`                // IMAP folder to monitor (default INBOX)
-	EmailLastUID    int    `json:"email_last_uid"`              // Last processed email UID for incremental updates
+	EmailAddress     string `json:"email_address,omitempty"`     // Email address for newsletter subscriptions
+	EmailIMAPServer  string `json:"email_imap_server,omitempty"` // IMAP server address
+	EmailIMAPPort    int    `json:"email_imap_port"`             // IMAP server port (default 993)
+	EmailUsername    string `json:"email_username,omitempty"`    // IMAP username
+	EmailPassword    string `json:"email_password,omitempty"`    // IMAP password (encrypted)
+	EmailFolder      string `json:"email_folder"`                // IMAP folders to monitor, comma-separated (default INBOX)
+	EmailSenders     string `json:"email_senders,omitempty"`     // Only take messages from these senders, comma-separated addresses or domains (default all)
+	EmailLastUID     int    `json:"email_last_uid"`              // Last processed email UID for incremental updates
+	EmailListID      string `json:"email_list_id,omitempty"`     // List-Id of the newsletter delivered to this feed by the mail receiver
+	EmailUnsubscribe string `json:"email_unsubscribe,omitempty"` // List-Unsubscribe link of the newsletter (URL or mailto)
 	// FreshRSS integration
 	IsFreshRSSSource bool   `json:"is_freshrss_source"` // Whether this feed is from FreshRSS sync
 	FreshRSSStreamID string `json:"freshrss_stream_id"` // FreshRSS stream ID (e.g., "feed/http://...")
@@ -51,6 +66,19 @@ type Feed struct {
 	// Caching support (304 Not Modified)
 	ETag         string `json:"etag,omitempty"`
 	LastModified string `json:"last_modified,omitempty"`
+	// HTTP authentication, cookies and custom headers (encrypted at rest)
+	Auth *FeedAuth `json:"auth,omitempty"`
+	// Next pages of JSON+JSONPath and XPath feeds, and item pages of XPath feeds
+	Pagination *FeedPagination `json:"pagination,omitempty"`
+	// Regular expressions, one per line, whose matches are ignored when comparing the pages of watch feeds
+	WatchIgnore string `json:"watch_ignore,omitempty"`
+	// Full text
+	FetchFullText bool `json:"fetch_full_text"` // Whether to fetch the full text of new articles from their pages (requires global full_text_fetch_enabled)
+	// Failure handling
+	IsPaused            bool       `json:"is_paused"`               // Paused feeds are only fetched to probe whether they're back
+	PauseReason         string     `json:"pause_reason,omitempty"`  // Why the feed was paused, e.g. "gone" after HTTP 410
+	ConsecutiveFailures int        `json:"consecutive_failures"`    // Failed fetches since the last successful one
+	NextRetryAt         *time.Time `json:"next_retry_at,omitempty"` // Scheduled fetches are skipped until then
 	// Statistics
 	LatestArticleTime *time.Time `json:"latest_article_time,omitempty"` // Latest article publish time
 	ArticlesPerMonth  float64    `json:"articles_per_month,omitempty"`  // Average articles per month (last 90 days / 3)
@@ -59,6 +87,69 @@ type Feed struct {
 	Tags []Tag `json:"tags,omitempty"` // Tags assigned to this feed
 }
 
+// FeedPagination configures how the next pages of the items of a feed are read
+type FeedPagination struct {
+	Next        string `json:"next,omitempty"`         // Path of the next page URL or cursor in a page
+	CursorParam string `json:"cursor_param,omitempty"` // Query parameter the cursor is sent in; Next is a URL when empty
+	MaxPages    int    `json:"max_pages,omitempty"`    // Pages read per refresh (default 1); XPath feeds stop at a page with known items
+	// Pages of the items of XPath feeds, fetched from the item links for new items
+	DetailContent   string `json:"detail_content,omitempty"`   // XPath of the content in the item page
+	DetailTimestamp string `json:"detail_timestamp,omitempty"` // XPath of the date in the item page, read with the feed's time format
+}
+
+// HasDetails reports whether the items of a feed are completed from their own pages
+func (p *FeedPagination) HasDetails() bool {
+	return p != nil && (p.DetailContent != "" || p.DetailTimestamp != "")
+}
+
+// Feed authentication types (FeedAuth.Type)
+const (
+	FeedAuthBasic  = "basic"  // HTTP Basic authentication
+	FeedAuthBearer = "bearer" // Authorization: Bearer token
+)
+
+// RedactedSecret replaces secrets in feeds sent to the frontend or exported.
+// Secrets posted back with this value keep their stored value.
+const RedactedSecret = "********"
+
+// FeedAuth holds the credentials sent with the requests to a feed's host: when
+// fetching the feed and when fetching the full text of its articles
+type FeedAuth struct {
+	Type     string            `json:"type,omitempty"`     // "basic", "bearer" or "" for none
+	Username string            `json:"username,omitempty"` // Basic authentication username
+	Password string            `json:"password,omitempty"` // Basic authentication password
+	Token    string            `json:"token,omitempty"`    // Bearer token
+	Cookies  string            `json:"cookies,omitempty"`  // Cookies in the Netscape cookies.txt format
+	Headers  map[string]string `json:"headers,omitempty"`  // Custom request headers
+}
+
+// Redacted returns a copy of the credentials with the secrets replaced by RedactedSecret
+func (a *FeedAuth) Redacted() *FeedAuth {
+	if a == nil {
+		return nil
+	}
+	redact := func(secret string) string {
+		if secret == "" {
+			return ""
+		}
+		return RedactedSecret
+	}
+	r := &FeedAuth{
+		Type:     a.Type,
+		Username: a.Username,
+		Password: redact(a.Password),
+		Token:    redact(a.Token),
+		Cookies:  redact(a.Cookies),
+	}
+	if len(a.Headers) > 0 {
+		r.Headers = make(map[string]string, len(a.Headers))
+		for name, value := range a.Headers {
+			r.Headers[name] = redact(value)
+		}
+	}
+	return r
+}
+
 type Article struct {
 	ID                    int64     `json:"id"`
 	UserID                int64     `json:"user_id"`
@@ -74,12 +165,71 @@ type Article struct {
 	IsFavorite            bool      `json:"is_favorite"`
 	IsHidden              bool      `json:"is_hidden"`
 	IsReadLater           bool      `json:"is_read_later"`
+	IsPinned              bool      `json:"is_pinned"`            // Shown before other articles, set by the rules "pin" action
 	FeedTitle             string    `json:"feed_title,omitempty"` // Joined field
 	Author                string    `json:"author,omitempty"`     // Article author
 	TranslatedTitle       string    `json:"translated_title"`
 	Summary               string    `json:"summary"`          // Cached AI-generated summary
 	UniqueID              string    `json:"unique_id"`        // Unique identifier for deduplication (title+feed_id+published_date)
 	FreshRSSItemID        string    `json:"freshrss_item_id"` // FreshRSS/Google Reader item ID for API operations
+	// Podcast metadata extracted during feed processing, saved in post-processing
+	PodcastEpisode *PodcastEpisode `json:"podcast_episode,omitempty"`
+	// Number of near-duplicates collapsed into this article (only set when collapsing duplicates)
+	DuplicateCount int `json:"duplicate_count,omitempty"`
+}
+
+// PodcastEpisode holds the podcast metadata of an article, taken from its audio
+// enclosure and the itunes:* and podcast:* (Podcasting 2.0) tags.
+type PodcastEpisode struct {
+	ArticleID       int64            `json:"article_id"`
+	AudioURL        string           `json:"audio_url"`
+	MimeType        string           `json:"mime_type,omitempty"`
+	SizeBytes       int64            `json:"size_bytes,omitempty"`
+	DurationSeconds int              `json:"duration_seconds,omitempty"`
+	ImageURL        string           `json:"image_url,omitempty"`
+	Season          int              `json:"season,omitempty"`
+	Episode         int              `json:"episode,omitempty"`
+	EpisodeType     string           `json:"episode_type,omitempty"` // full, trailer or bonus
+	Explicit        bool             `json:"explicit"`
+	ChaptersURL     string           `json:"chapters_url,omitempty"` // podcast:chapters JSON document
+	Chapters        []PodcastChapter `json:"chapters,omitempty"`
+	TranscriptURL   string           `json:"transcript_url,omitempty"`
+	TranscriptType  string           `json:"transcript_type,omitempty"` // MIME type of the transcript
+	Transcript      string           `json:"transcript,omitempty"`      // Plain text of the fetched transcript
+	FetchedAt       *time.Time       `json:"fetched_at,omitempty"`      // When the transcript and chapters were fetched
+}
+
+// PodcastChapter is a chapter marker of an episode.
+type PodcastChapter struct {
+	StartTime float64 `json:"start_time"` // Seconds from the start of the episode
+	Title     string  `json:"title"`
+	URL       string  `json:"url,omitempty"`
+	ImageURL  string  `json:"img,omitempty"`
+}
+
+// PlaybackPosition is a user's listening progress for a podcast episode.
+type PlaybackPosition struct {
+	ArticleID       int64     `json:"article_id"`
+	PositionSeconds float64   `json:"position_seconds"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Completed       bool      `json:"completed"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ArticleHighlight is a highlighted text range in an article's content, with an optional note.
+type ArticleHighlight struct {
+	ID           int64     `json:"id"`
+	UserID       int64     `json:"user_id"`
+	ArticleID    int64     `json:"article_id"`
+	Text         string    `json:"text"`         // Highlighted text
+	StartOffset  int       `json:"start_offset"` // Character offsets in the text content of the article
+	EndOffset    int       `json:"end_offset"`
+	Color        string    `json:"color"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	ArticleTitle string    `json:"article_title,omitempty"` // Joined field
+	ArticleURL   string    `json:"article_url,omitempty"`   // Joined field
 }
 
 // SavedFilter represents a user-saved article filter
@@ -87,13 +237,13 @@ type SavedFilter struct {
 	ID         int64     `json:"id"`
 	UserID     int64     `json:"user_id"`
 	Name       string    `json:"name"`
-	Conditions string    `json:"conditions"` // JSON string of FilterCondition[]
+	Conditions string    `json:"conditions"` // Query language expression, or legacy JSON string of FilterCondition[]
 	Position   int       `json:"position"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
-// Tag represents a user-defined tag for organizing feeds
+// Tag represents a user-defined tag for organizing feeds and articles
 type Tag struct {
 	ID       int64  `json:"id"`
 	UserID   int64  `json:"user_id"`
@@ -102,6 +252,21 @@ type Tag struct {
 	Position int    `json:"position"`
 }
 
+// OutputFeed is a feed published by MavenRSS from a user's articles.
+// It is served without authentication at a URL containing its token.
+type OutputFeed struct {
+	ID                  int64     `json:"id"`
+	UserID              int64     `json:"user_id"`
+	Token               string    `json:"token"`
+	Title               string    `json:"title"`
+	SourceType          string    `json:"source_type"`  // "saved_filter", "tag", "category", "favorites", "read_later"
+	SourceValue         string    `json:"source_value"` // Saved filter ID, tag ID or category name
+	IncludeTranslations bool      `json:"include_translations"`
+	IncludeSummaries    bool      `json:"include_summaries"`
+	MaxItems            int       `json:"max_items"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
 // AIProfile represents an AI configuration profile
 type AIProfile struct {
 	ID             int64     `json:"id"`
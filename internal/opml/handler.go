@@ -3,6 +3,7 @@ package opml
 import (
 	"MavenRSS/internal/models"
 	"MavenRSS/internal/utils"
+	"MavenRSS/internal/utils/urlutil"
 	"bytes"
 	"encoding/xml"
 	"errors"
@@ -50,6 +51,8 @@ type Outline struct {
 	XPathItemThumbnail  string `xml:"xPathItemThumbnail,attr"`
 	XPathItemCategories string `xml:"xPathItemCategories,attr"`
 	XPathItemUid        string `xml:"xPathItemUid,attr"`
+	// Ignore patterns of watched pages
+	WatchIgnore string `xml:"watchIgnore,attr,omitempty"`
 }
 
 // normalizeOPMLAttributes normalizes attribute names in OPML content to handle
@@ -193,6 +196,7 @@ func Parse(r io.Reader) ([]models.Feed, error) {
 					XPathItemThumbnail:  o.XPathItemThumbnail,
 					XPathItemCategories: o.XPathItemCategories,
 					XPathItemUid:        o.XPathItemUid,
+					WatchIgnore:         o.WatchIgnore,
 				})
 			}
 
@@ -270,6 +274,7 @@ func fallbackParse(content []byte) []models.Feed {
 	return feeds
 }
 
+// Generate generates OPML from feeds. Credentials embedded in feed URLs are removed.
 func Generate(feeds []models.Feed) ([]byte, error) {
 	doc := OPML{
 		Version: "1.0",
@@ -306,7 +311,7 @@ func Generate(feeds []models.Feed) ([]byte, error) {
 			Text:   f.Title,
 			Title:  f.Title,
 			Type:   f.Type,
-			XMLURL: f.URL,
+			XMLURL: urlutil.StripCredentials(f.URL),
 			// XPath support
 			XPathItem:           f.XPathItem,
 			XPathItemTitle:      f.XPathItemTitle,
@@ -318,6 +323,7 @@ func Generate(feeds []models.Feed) ([]byte, error) {
 			XPathItemThumbnail:  f.XPathItemThumbnail,
 			XPathItemCategories: f.XPathItemCategories,
 			XPathItemUid:        f.XPathItemUid,
+			WatchIgnore:         f.WatchIgnore,
 		})
 
 		// Add tags as additional outline elements with "tag:" prefix in category
@@ -0,0 +1,303 @@
+package query
+
+import (
+	"strings"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/rsshub"
+)
+
+// Store is the data access needed to evaluate queries
+type Store interface {
+	GetFeeds() ([]models.Feed, error)
+	GetFeedTags(feedID int64) ([]models.Tag, error)
+	GetArticleContents(articleIDs []int64) (map[int64]string, error)
+	GetArticleTagNames(articleIDs []int64) (map[int64][]string, error)
+	GetArticleIDsWithHighlights(articleIDs []int64) (map[int64]bool, error)
+}
+
+// Env holds the feed and article data queries are evaluated against
+type Env struct {
+	Feeds    map[int64]models.Feed // Feeds by ID
+	FeedTags map[int64][]string    // Tag names by feed ID
+
+	// Article data, loaded by LoadArticleData only for the fields a query uses
+	ArticleContents   map[int64]string   // Cached content by article ID
+	ArticleTags       map[int64][]string // Tag names by article ID
+	ArticleHighlights map[int64]bool     // Articles with at least one highlight
+
+	Now time.Time // Reference time of relative dates
+}
+
+// LoadEnv loads the feeds and their tags
+func LoadEnv(store Store) (*Env, error) {
+	feeds, err := store.GetFeeds()
+	if err != nil {
+		return nil, err
+	}
+
+	env := &Env{
+		Feeds:    make(map[int64]models.Feed, len(feeds)),
+		FeedTags: make(map[int64][]string, len(feeds)),
+		Now:      time.Now(),
+	}
+	for _, feed := range feeds {
+		env.Feeds[feed.ID] = feed
+
+		// Build tag names list for this feed
+		tags, _ := store.GetFeedTags(feed.ID)
+		tagNames := make([]string, len(tags))
+		for i, tag := range tags {
+			tagNames[i] = tag.Name
+		}
+		env.FeedTags[feed.ID] = tagNames
+	}
+	return env, nil
+}
+
+// LoadArticleData loads the content, tags and highlights of the articles when any
+// of the expressions needs them
+func (e *Env) LoadArticleData(store Store, articles []models.Article, exprs ...Node) error {
+	var needsContent, needsTags, needsHighlights bool
+	for _, n := range exprs {
+		walkTerms(n, func(t *Term) {
+			switch t.Field {
+			case "content":
+				needsContent = true
+			case "tag":
+				needsTags = true
+			case "has_highlights":
+				needsHighlights = true
+			}
+		})
+	}
+	if !needsContent && !needsTags && !needsHighlights {
+		return nil
+	}
+
+	articleIDs := make([]int64, len(articles))
+	for i, article := range articles {
+		articleIDs[i] = article.ID
+	}
+	var err error
+	if needsContent {
+		if e.ArticleContents, err = store.GetArticleContents(articleIDs); err != nil {
+			return err
+		}
+	}
+	if needsTags {
+		if e.ArticleTags, err = store.GetArticleTagNames(articleIDs); err != nil {
+			return err
+		}
+	}
+	if needsHighlights {
+		if e.ArticleHighlights, err = store.GetArticleIDsWithHighlights(articleIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Filter returns the articles matching an expression
+func (e *Env) Filter(n Node, articles []models.Article) []models.Article {
+	var matched []models.Article
+	for _, article := range articles {
+		if e.Match(n, article) {
+			matched = append(matched, article)
+		}
+	}
+	return matched
+}
+
+// Match reports whether an article matches an expression
+func (e *Env) Match(n Node, article models.Article) bool {
+	switch n := n.(type) {
+	case *And:
+		for _, child := range n.Children {
+			if !e.Match(child, article) {
+				return false
+			}
+		}
+		return true
+	case *Or:
+		for _, child := range n.Children {
+			if e.Match(child, article) {
+				return true
+			}
+		}
+		return false
+	case *Not:
+		return !e.Match(n.Child, article)
+	case *Term:
+		return e.matchTerm(n, article)
+	}
+	return false
+}
+
+// FeedTitle returns the title of an article's feed
+func (e *Env) FeedTitle(article models.Article) string {
+	if feed, ok := e.Feeds[article.FeedID]; ok && feed.Title != "" {
+		return feed.Title
+	}
+	return article.FeedTitle
+}
+
+// FeedCategory returns the category of an article's feed
+func (e *Env) FeedCategory(article models.Article) string {
+	return e.Feeds[article.FeedID].Category
+}
+
+func (e *Env) matchTerm(t *Term, article models.Article) bool {
+	feed := e.Feeds[article.FeedID]
+
+	switch t.Field {
+	case "title":
+		return t.matchText(article.Title)
+	case "author":
+		return t.matchText(article.Author)
+	case "url":
+		return t.matchText(article.URL)
+	case "content":
+		content, ok := e.ArticleContents[article.ID]
+		// No content cached, treat as not matching
+		return ok && t.matchText(content)
+	case "feed":
+		return t.matchText(e.FeedTitle(article))
+	case "category":
+		return t.matchText(feed.Category)
+	case "feed_type":
+		return t.matchText(FeedType(&feed))
+	case "feed_status":
+		return t.matchText(feed.LastUpdateStatus)
+	case "tag":
+		return t.matchTags(e.ArticleTags[article.ID])
+	case "feed_tag":
+		return t.matchTags(e.FeedTags[article.FeedID])
+
+	case "read":
+		return t.matchFlag(article.IsRead)
+	case "favorite":
+		return t.matchFlag(article.IsFavorite)
+	case "hidden":
+		return t.matchFlag(article.IsHidden)
+	case "read_later":
+		return t.matchFlag(article.IsReadLater)
+	case "pinned":
+		return t.matchFlag(article.IsPinned)
+	case "has_summary":
+		return t.matchFlag(article.Summary != "")
+	case "has_translation":
+		return t.matchFlag(article.TranslatedTitle != "")
+	case "has_image":
+		return t.matchFlag(article.ImageURL != "")
+	case "has_audio":
+		return t.matchFlag(article.AudioURL != "")
+	case "has_video":
+		return t.matchFlag(article.VideoURL != "")
+	case "has_highlights":
+		return t.matchFlag(e.ArticleHighlights[article.ID])
+	case "freshrss":
+		return t.matchFlag(feed.IsFreshRSSSource)
+	case "image_mode":
+		return t.matchFlag(feed.IsImageMode)
+
+	case "published":
+		return t.matchDate(article.PublishedAt, e.Now)
+	case "feed_articles_per_month":
+		return t.matchNumber(feed.ArticlesPerMonth)
+	}
+	return false
+}
+
+func (t *Term) matchText(s string) bool {
+	switch t.Op {
+	case OpEqual:
+		return strings.EqualFold(s, t.Value)
+	case OpMatch:
+		// A pattern that failed to compile never matches
+		return t.re != nil && t.re.MatchString(s)
+	}
+	return strings.Contains(strings.ToLower(s), strings.ToLower(t.Value))
+}
+
+func (t *Term) matchTags(tags []string) bool {
+	for _, tag := range tags {
+		if t.matchText(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Term) matchFlag(set bool) bool {
+	if t.Op == OpIs {
+		return set
+	}
+	return set == t.flag
+}
+
+func (t *Term) matchDate(published, now time.Time) bool {
+	start, end := t.date.bounds(now)
+	switch t.Op {
+	case OpGreater:
+		return !published.Before(end)
+	case OpAtLeast:
+		return !published.Before(start)
+	case OpLess:
+		return published.Before(start)
+	case OpAtMost:
+		return published.Before(end)
+	}
+	if t.date.isRelative {
+		// published:7d means within the last 7 days
+		return !published.Before(start)
+	}
+	return !published.Before(start) && published.Before(end)
+}
+
+func (t *Term) matchNumber(n float64) bool {
+	switch t.Op {
+	case OpGreater:
+		return n > t.number
+	case OpAtLeast:
+		return n >= t.number
+	case OpLess:
+		return n < t.number
+	case OpAtMost:
+		return n <= t.number
+	}
+	return n == t.number
+}
+
+// FeedType returns the type code of a feed
+// Possible values: "regular", "freshrss", "rsshub", "script", "xpath", "email"
+func FeedType(feed *models.Feed) string {
+	// Check FreshRSS
+	if feed.IsFreshRSSSource {
+		return "freshrss"
+	}
+
+	// Check RSSHub
+	if rsshub.IsRSSHubURL(feed.URL) {
+		return "rsshub"
+	}
+
+	// Check custom script
+	if feed.ScriptPath != "" {
+		return "script"
+	}
+
+	// Check email
+	if feed.Type == "email" {
+		return "email"
+	}
+
+	// Check XPath
+	if feed.Type == "HTML+XPath" || feed.Type == "XML+XPath" {
+		return "xpath"
+	}
+
+	// Default: regular RSS/Atom feed
+	return "regular"
+}
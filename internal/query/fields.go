@@ -0,0 +1,370 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldKind determines the operators and values a field accepts
+type fieldKind int
+
+const (
+	kindText   fieldKind = iota // Text compared with :, = or ~
+	kindTags                    // List of tags, matching when any tag does
+	kindFlag                    // Boolean flag
+	kindDate                    // Date compared with :, =, >, >=, <, <=
+	kindNumber                  // Number compared with =, >, >=, <, <=
+)
+
+type fieldInfo struct {
+	kind      fieldKind
+	feedLevel bool // Depends only on the article's feed
+}
+
+// fields lists the canonical field names of the language
+var fields = map[string]fieldInfo{
+	"title":   {kind: kindText},
+	"author":  {kind: kindText},
+	"url":     {kind: kindText},
+	"content": {kind: kindText},
+	"tag":     {kind: kindTags},
+
+	"feed":        {kind: kindText, feedLevel: true},
+	"category":    {kind: kindText, feedLevel: true},
+	"feed_type":   {kind: kindText, feedLevel: true},
+	"feed_status": {kind: kindText, feedLevel: true},
+	"feed_tag":    {kind: kindTags, feedLevel: true},
+
+	"read":            {kind: kindFlag},
+	"favorite":        {kind: kindFlag},
+	"hidden":          {kind: kindFlag},
+	"read_later":      {kind: kindFlag},
+	"pinned":          {kind: kindFlag},
+	"has_summary":     {kind: kindFlag},
+	"has_translation": {kind: kindFlag},
+	"has_image":       {kind: kindFlag},
+	"has_audio":       {kind: kindFlag},
+	"has_video":       {kind: kindFlag},
+	"has_highlights":  {kind: kindFlag},
+	"freshrss":        {kind: kindFlag, feedLevel: true},
+	"image_mode":      {kind: kindFlag, feedLevel: true},
+
+	"published":               {kind: kindDate},
+	"feed_articles_per_month": {kind: kindNumber, feedLevel: true},
+}
+
+// fieldAliases maps the legacy condition field names and common synonyms to canonical names
+var fieldAliases = map[string]string{
+	"article_title":           "title",
+	"article_content":         "content",
+	"article_tags":            "tag",
+	"tags":                    "tag",
+	"feed_name":               "feed",
+	"feed_category":           "category",
+	"feed_tags":               "feed_tag",
+	"feed_last_update_status": "feed_status",
+	"is_read":                 "read",
+	"is_favorite":             "favorite",
+	"is_hidden":               "hidden",
+	"is_read_later":           "read_later",
+	"is_pinned":               "pinned",
+	"is_freshrss_feed":        "freshrss",
+	"is_image_mode_feed":      "image_mode",
+}
+
+// canonicalField resolves a field name or alias, case-insensitively
+func canonicalField(name string) (string, bool) {
+	name = strings.ToLower(name)
+	if alias, ok := fieldAliases[name]; ok {
+		name = alias
+	}
+	_, ok := fields[name]
+	return name, ok
+}
+
+// dateValue is an absolute day or a duration relative to the time of evaluation
+type dateValue struct {
+	day        time.Time // Midnight UTC of an absolute date
+	relative   time.Duration
+	isRelative bool
+}
+
+// bounds returns the time range a date value denotes: a whole day for absolute
+// dates, or the instant now-duration for relative ones
+func (d dateValue) bounds(now time.Time) (start, end time.Time) {
+	if d.isRelative {
+		t := now.Add(-d.relative)
+		return t, t
+	}
+	return d.day, d.day.Add(24 * time.Hour)
+}
+
+// parseDateValue parses YYYY-MM-DD or a relative duration like 12h, 7d or 2w
+func parseDateValue(value string) (dateValue, error) {
+	if day, err := time.Parse("2006-01-02", value); err == nil {
+		return dateValue{day: day}, nil
+	}
+	if len(value) >= 2 {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && n >= 0 {
+			unit := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[value[len(value)-1]]
+			if unit > 0 {
+				return dateValue{relative: time.Duration(n) * unit, isRelative: true}, nil
+			}
+		}
+	}
+	return dateValue{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or a duration like 7d", value)
+}
+
+// parseFlagValue parses the value of a flag term
+func parseFlagValue(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "1":
+		return true, nil
+	case "false", "no", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid value %q, expected true or false", value)
+}
+
+// newTerm validates a term and prepares its value for evaluation
+func newTerm(field string, op Op, value string) (*Term, error) {
+	name, ok := canonicalField(field)
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q", field)
+	}
+	t := &Term{Field: name, Op: op, Value: value}
+
+	switch info := fields[name]; info.kind {
+	case kindText, kindTags:
+		switch op {
+		case OpContains, OpEqual:
+		case OpMatch:
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression for %s: %v", name, err)
+			}
+			t.re = re
+		default:
+			return nil, fmt.Errorf("operator %q is not supported by %s", op, name)
+		}
+
+	case kindFlag:
+		switch op {
+		case OpIs:
+		case OpContains, OpEqual:
+			flag, err := parseFlagValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", name, err)
+			}
+			t.flag = flag
+		default:
+			return nil, fmt.Errorf("operator %q is not supported by %s", op, name)
+		}
+
+	case kindDate:
+		if op == OpIs || op == OpMatch {
+			return nil, fmt.Errorf("%s needs a date, e.g. %s>7d", name, name)
+		}
+		date, err := parseDateValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		t.date = date
+
+	case kindNumber:
+		if op == OpIs || op == OpContains || op == OpMatch {
+			return nil, fmt.Errorf("%s needs a comparison, e.g. %s>=10", name, name)
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid number %q", name, value)
+		}
+		t.number = number
+	}
+	return t, nil
+}
+
+// Condition is a condition of the legacy JSON filter format, a flat list
+// chained with "and"/"or" from left to right without grouping
+type Condition struct {
+	ID       int64    `json:"id"`
+	Logic    string   `json:"logic"`    // "and", "or" (null for first condition)
+	Negate   bool     `json:"negate"`   // NOT modifier for this condition
+	Field    string   `json:"field"`    // "feed_name", "feed_category", "article_title", "article_tags", "has_highlights", "published_after", "published_before", ...
+	Operator string   `json:"operator"` // "contains", "exact", "regex" (null for date fields and multi-select)
+	Value    string   `json:"value"`    // Single value for text/date fields
+	Values   []string `json:"values"`   // Multiple values for multi-select fields
+}
+
+// FromConditions converts legacy conditions to an expression with the same meaning.
+// Conditions are combined left to right, each with the result of the previous ones;
+// conditions without a valid logic after the first are ignored, unknown fields and
+// empty values match everything, as they always have.
+func FromConditions(conditions []Condition) Node {
+	if len(conditions) == 0 {
+		return MatchAll()
+	}
+
+	result := convertCondition(conditions[0])
+	for _, c := range conditions[1:] {
+		switch c.Logic {
+		case "and":
+			result = joinAnd(result, convertCondition(c))
+		case "or":
+			result = joinOr(result, convertCondition(c))
+		}
+	}
+	return result
+}
+
+// ParseStored parses a stored filter: a query, or a JSON array of legacy conditions
+func ParseStored(s string) (Node, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") {
+		var conditions []Condition
+		if err := json.Unmarshal([]byte(s), &conditions); err != nil {
+			return nil, fmt.Errorf("invalid conditions: %w", err)
+		}
+		return FromConditions(conditions), nil
+	}
+	return Parse(s)
+}
+
+// convertCondition converts a single legacy condition
+func convertCondition(c Condition) Node {
+	n := convertConditionValue(c)
+	if c.Negate {
+		return &Not{Child: n}
+	}
+	return n
+}
+
+func convertConditionValue(c Condition) Node {
+	switch c.Field {
+	case "feed_name", "feed_category", "feed_type", "feed_tags", "article_tags":
+		// Multi-select: any of the values, contained in the field
+		name, _ := canonicalField(c.Field)
+		if len(c.Values) > 0 {
+			or := &Or{}
+			for _, v := range c.Values {
+				or.Children = append(or.Children, legacyTerm(name, OpContains, v))
+			}
+			if len(or.Children) == 1 {
+				return or.Children[0]
+			}
+			return or
+		}
+		if c.Value == "" {
+			return MatchAll()
+		}
+		return legacyTerm(name, OpContains, c.Value)
+
+	case "article_title", "author", "url", "article_content":
+		if c.Value == "" {
+			return MatchAll()
+		}
+		name, _ := canonicalField(c.Field)
+		switch c.Operator {
+		case "exact":
+			return legacyTerm(name, OpEqual, c.Value)
+		case "regex":
+			return legacyTerm(name, OpMatch, c.Value)
+		}
+		return legacyTerm(name, OpContains, c.Value)
+
+	case "feed_last_update_status":
+		if c.Value == "" {
+			return MatchAll()
+		}
+		return legacyTerm("feed_status", OpEqual, c.Value)
+
+	case "is_read", "is_favorite", "is_hidden", "is_read_later", "is_pinned", "is_freshrss_feed", "is_image_mode_feed",
+		"has_summary", "has_translation", "has_image", "has_audio", "has_video", "has_highlights":
+		if c.Value == "" {
+			return MatchAll()
+		}
+		name, _ := canonicalField(c.Field)
+		term := &Term{Field: name, Op: OpIs}
+		if c.Value != "true" {
+			return &Not{Child: term}
+		}
+		return term
+
+	case "published_after", "published_before":
+		if _, err := time.Parse("2006-01-02", c.Value); err != nil {
+			return MatchAll()
+		}
+		op := OpAtLeast
+		if c.Field == "published_before" {
+			op = OpAtMost
+		}
+		return legacyTerm("published", op, c.Value)
+
+	case "published_after_hours", "published_after_days":
+		n, err := strconv.Atoi(c.Value)
+		if err != nil || n < 0 {
+			return MatchAll()
+		}
+		unit := "h"
+		if c.Field == "published_after_days" {
+			unit = "d"
+		}
+		return legacyTerm("published", OpAtLeast, strconv.Itoa(n)+unit)
+
+	case "feed_articles_per_month":
+		if _, err := strconv.ParseFloat(c.Value, 64); err != nil {
+			return MatchAll()
+		}
+		return legacyTerm("feed_articles_per_month", OpAtLeast, c.Value)
+	}
+
+	return MatchAll()
+}
+
+// legacyTerm creates a term for a converted condition. Values are validated
+// beforehand, except regular expressions: an invalid pattern never matches.
+func legacyTerm(field string, op Op, value string) Node {
+	t, err := newTerm(field, op, value)
+	if err != nil {
+		return &Term{Field: field, Op: op, Value: value}
+	}
+	return t
+}
+
+// joinAnd combines two expressions with AND, flattening nested ANDs
+func joinAnd(a, b Node) Node {
+	children := []Node{}
+	for _, n := range []Node{a, b} {
+		if and, ok := n.(*And); ok {
+			children = append(children, and.Children...)
+		} else {
+			children = append(children, n)
+		}
+	}
+	return &And{Children: children}
+}
+
+// joinOr combines two expressions with OR, flattening nested ORs.
+// An operand matching everything makes the whole expression match everything.
+func joinOr(a, b Node) Node {
+	children := []Node{}
+	for _, n := range []Node{a, b} {
+		switch n := n.(type) {
+		case *Or:
+			children = append(children, n.Children...)
+		case *And:
+			if len(n.Children) == 0 {
+				return MatchAll()
+			}
+			children = append(children, n)
+		default:
+			children = append(children, n)
+		}
+	}
+	return &Or{Children: children}
+}
@@ -0,0 +1,309 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ParseError describes a syntax error in a query
+type ParseError struct {
+	Pos int // Byte offset in the query
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("query: %s at position %d", e.Msg, e.Pos)
+}
+
+// Parse parses a query. An empty query matches every article.
+func Parse(input string) (Node, error) {
+	p := &parser{input: input}
+	p.skipSpace()
+	if p.eof() {
+		return MatchAll(), nil
+	}
+
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); !p.eof() {
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+	return n, nil
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &ParseError{Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// acceptKeyword consumes the keyword (case-insensitive) if it comes next as a whole word
+func (p *parser) acceptKeyword(keyword string) bool {
+	p.skipSpace()
+	end := p.pos + len(keyword)
+	if end > len(p.input) || !strings.EqualFold(p.input[p.pos:end], keyword) {
+		return false
+	}
+	if end < len(p.input) && !isDelimiter(p.input[end]) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+// parseOr parses: and { OR and }
+func (p *parser) parseOr() (Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []Node{first}
+	for p.acceptKeyword("OR") {
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &Or{Children: children}, nil
+}
+
+// parseAnd parses: unary { [AND] unary }
+func (p *parser) parseAnd() (Node, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []Node{first}
+	for {
+		p.skipSpace()
+		if p.eof() || p.peek() == ')' {
+			break
+		}
+		// Leave OR to parseOr
+		start := p.pos
+		if p.acceptKeyword("OR") {
+			p.pos = start
+			break
+		}
+		p.acceptKeyword("AND")
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &And{Children: children}, nil
+}
+
+// parseUnary parses: NOT unary | -unary | primary
+func (p *parser) parseUnary() (Node, error) {
+	if p.acceptKeyword("NOT") {
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Child: child}, nil
+	}
+	if p.peek() == '-' {
+		p.pos++
+		child, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses: ( or ) | "text" | field op value | flag | word
+func (p *parser) parsePrimary() (Node, error) {
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorf("unexpected end of query, expected a term")
+	}
+
+	switch p.peek() {
+	case '(':
+		p.pos++
+		if p.skipSpace(); p.peek() == ')' {
+			p.pos++
+			return MatchAll(), nil
+		}
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpace(); p.peek() != ')' {
+			return nil, p.errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return n, nil
+	case ')':
+		return nil, p.errorf("unexpected closing parenthesis")
+	case '"':
+		text, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return &Term{Field: "title", Op: OpContains, Value: text}, nil
+	}
+
+	start := p.pos
+	word := p.readWord()
+	if word == "" {
+		return nil, p.errorf("unexpected %q", p.peek())
+	}
+	if isKeyword(word) {
+		return nil, &ParseError{Pos: start, Msg: fmt.Sprintf("expected a term before %s", strings.ToUpper(word))}
+	}
+
+	op := p.readOp()
+	if op == OpIs {
+		// A bare flag, or a word of the title
+		if name, ok := canonicalField(word); ok && fields[name].kind == kindFlag {
+			return &Term{Field: name, Op: OpIs}, nil
+		}
+		return &Term{Field: "title", Op: OpContains, Value: word}, nil
+	}
+
+	valueStart := p.pos
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	field := word
+	switch strings.ToLower(word) {
+	case "has", "is":
+		// has:image and is:read shorthands
+		if op != OpContains {
+			return nil, &ParseError{Pos: start, Msg: fmt.Sprintf("%s needs ':', e.g. %s:%s", word, word, value)}
+		}
+		field = value
+		if strings.EqualFold(word, "has") {
+			field = "has_" + value
+		}
+		negate := false
+		if strings.EqualFold(field, "unread") {
+			field, negate = "read", true
+		}
+		name, ok := canonicalField(field)
+		if !ok || fields[name].kind != kindFlag {
+			return nil, &ParseError{Pos: valueStart, Msg: fmt.Sprintf("unknown flag %q", value)}
+		}
+		var n Node = &Term{Field: name, Op: OpIs}
+		if negate {
+			n = &Not{Child: n}
+		}
+		return n, nil
+	}
+
+	t, err := newTerm(field, op, value)
+	if err != nil {
+		return nil, &ParseError{Pos: start, Msg: err.Error()}
+	}
+	return t, nil
+}
+
+// isDelimiter reports whether a byte ends a word
+func isDelimiter(c byte) bool {
+	return unicode.IsSpace(rune(c)) || strings.IndexByte("()\":=~<>", c) >= 0
+}
+
+// readWord reads a field name or bare word
+func (p *parser) readWord() string {
+	start := p.pos
+	for !p.eof() && !isDelimiter(p.peek()) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// readOp reads the operator following a field name, if any
+func (p *parser) readOp() Op {
+	if p.eof() {
+		return OpIs
+	}
+	switch c := p.peek(); c {
+	case ':', '=', '~':
+		p.pos++
+		return Op(c)
+	case '>', '<':
+		p.pos++
+		if p.peek() == '=' {
+			p.pos++
+			return Op(string(c) + "=")
+		}
+		return Op(c)
+	}
+	return OpIs
+}
+
+// parseValue reads the value of a term: a quoted string, or everything up to the
+// next space or closing parenthesis, so URLs and times need no quoting
+func (p *parser) parseValue() (string, error) {
+	if p.peek() == '"' {
+		return p.parseQuoted()
+	}
+	start := p.pos
+	for !p.eof() && !unicode.IsSpace(rune(p.peek())) && p.peek() != ')' && p.peek() != '(' {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("missing value")
+	}
+	return p.input[start:p.pos], nil
+}
+
+// parseQuoted reads a double-quoted string. Only \" and \\ are escapes, so
+// regular expressions keep their backslashes.
+func (p *parser) parseQuoted() (string, error) {
+	start := p.pos
+	p.pos++
+	var sb strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case c == '\\' && p.pos+1 < len(p.input) && (p.input[p.pos+1] == '"' || p.input[p.pos+1] == '\\'):
+			sb.WriteByte(p.input[p.pos+1])
+			p.pos += 2
+			continue
+		case c == '"':
+			p.pos++
+			return sb.String(), nil
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+	return "", &ParseError{Pos: start, Msg: "unterminated quoted string"}
+}
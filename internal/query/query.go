@@ -0,0 +1,169 @@
+// Package query implements the article query language shared by the filter API,
+// saved filters and automation rules.
+//
+// A query combines terms with AND, OR, NOT and parentheses, for example:
+//
+//	feed:"Go Blog" AND (title~"generics" OR tag:lang) AND NOT read AND published>7d
+//
+// NOT binds tighter than AND, which binds tighter than OR. Terms written next to
+// each other are joined with AND. A term is one of:
+//
+//	field:value    contains (case-insensitive); for flags, value is true or false
+//	field=value    equals (case-insensitive)
+//	field~value    matches the regular expression
+//	field>value    comparisons (>, >=, <, <=) for published and feed_articles_per_month
+//	flag           a flag such as read, favorite or has_image is set
+//	has:image      shorthand for has_image, likewise is:read for read
+//	word           the title contains word
+//
+// Values are bare words or double-quoted strings. Dates are YYYY-MM-DD or
+// relative durations such as 12h, 7d or 2w (published>7d: within the last week).
+//
+// Expressions are evaluated against articles in Go (Env.Match) and can be
+// compiled to a SQL prefilter (CompileSQL). The legacy JSON condition lists are
+// converted with FromConditions.
+package query
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Op is the comparison operator of a term
+type Op string
+
+const (
+	OpIs       Op = ""   // Flag is set
+	OpContains Op = ":"  // Contains, case-insensitive
+	OpEqual    Op = "="  // Equals, case-insensitive
+	OpMatch    Op = "~"  // Regular expression
+	OpGreater  Op = ">"  // Greater than (after, for dates)
+	OpAtLeast  Op = ">=" // Greater than or equal
+	OpLess     Op = "<"  // Less than (before, for dates)
+	OpAtMost   Op = "<=" // Less than or equal
+)
+
+// Node is a node of a parsed query expression
+type Node interface {
+	// String formats the node in the query language
+	String() string
+}
+
+// And matches when all of its children match. An empty And matches everything.
+type And struct {
+	Children []Node
+}
+
+// Or matches when any of its children matches. An empty Or matches nothing.
+type Or struct {
+	Children []Node
+}
+
+// Not matches when its child doesn't
+type Not struct {
+	Child Node
+}
+
+// Term compares a single field of an article
+type Term struct {
+	Field string // Canonical field name
+	Op    Op
+	Value string
+
+	// Value prepared by the parser, depending on the kind of field
+	re     *regexp.Regexp // Compiled pattern for OpMatch
+	flag   bool
+	date   dateValue
+	number float64
+}
+
+// MatchAll returns an expression that matches every article
+func MatchAll() Node {
+	return &And{}
+}
+
+func (n *And) String() string {
+	if len(n.Children) == 0 {
+		return ""
+	}
+	parts := make([]string, len(n.Children))
+	for i, child := range n.Children {
+		parts[i] = child.String()
+		if _, isOr := child.(*Or); isOr {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " AND ")
+}
+
+func (n *Or) String() string {
+	if len(n.Children) == 0 {
+		return "NOT ()"
+	}
+	parts := make([]string, len(n.Children))
+	for i, child := range n.Children {
+		parts[i] = child.String()
+	}
+	return strings.Join(parts, " OR ")
+}
+
+func (n *Not) String() string {
+	switch n.Child.(type) {
+	case *Term, *Not:
+		return "NOT " + n.Child.String()
+	}
+	return "NOT (" + n.Child.String() + ")"
+}
+
+func (n *Term) String() string {
+	if n.Op == OpIs {
+		return n.Field
+	}
+	return n.Field + string(n.Op) + quoteValue(n.Value)
+}
+
+// quoteValue quotes a value unless it is a plain word
+func quoteValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\r\n\"\\():=~<>") && !isKeyword(value) {
+		return value
+	}
+	// Backslashes only need escaping before a quote, another backslash or the end
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '"' || (c == '\\' && (i+1 == len(value) || value[i+1] == '"' || value[i+1] == '\\')) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(c)
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// isKeyword reports whether a word is an operator of the language
+func isKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "AND", "OR", "NOT":
+		return true
+	}
+	return false
+}
+
+// walkTerms calls fn for every term of an expression
+func walkTerms(n Node, fn func(*Term)) {
+	switch n := n.(type) {
+	case *And:
+		for _, child := range n.Children {
+			walkTerms(child, fn)
+		}
+	case *Or:
+		for _, child := range n.Children {
+			walkTerms(child, fn)
+		}
+	case *Not:
+		walkTerms(n.Child, fn)
+	case *Term:
+		fn(n)
+	}
+}
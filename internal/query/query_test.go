@@ -0,0 +1,201 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{``, ``},
+		{`golang`, `title:golang`},
+		{`"Go 1.22"`, `title:"Go 1.22"`},
+		{`feed:"Go Blog" AND (title~"generics" OR tag:lang) AND NOT read AND published>7d`,
+			`feed:"Go Blog" AND (title~generics OR tag:lang) AND NOT read AND published>7d`},
+		{`a OR b c`, `title:a OR title:b AND title:c`},
+		{`(a OR b) c`, `(title:a OR title:b) AND title:c`},
+		{`NOT (favorite or is:unread)`, `NOT (favorite OR NOT read)`},
+		{`-has:image feed_name:x`, `NOT has_image AND feed:x`},
+		{`url:https://go.dev/blog?x=1`, `url:"https://go.dev/blog?x=1"`},
+		{`title~"\d+\.\d+" author="A \"B\""`, `title~"\d+\.\d+" AND author="A \"B\""`},
+		{`published>=2024-01-02 feed_articles_per_month<3.5`, `published>=2024-01-02 AND feed_articles_per_month<3.5`},
+		{`read:false`, `read:false`},
+	}
+
+	for _, tt := range tests {
+		n, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.input, err)
+			continue
+		}
+		if got := n.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+		again, err := Parse(n.String())
+		if err != nil || again.String() != tt.want {
+			t.Errorf("round trip of %q: %v, %v", tt.want, again, err)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{
+		`(read`,
+		`read)`,
+		`read AND`,
+		`OR read`,
+		`title:`,
+		`title:"open`,
+		`color:red`,
+		`title~"("`,
+		`published>soon`,
+		`published~2024`,
+		`read:maybe`,
+		`has:wings`,
+		`feed_articles_per_month:x`,
+	} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q): expected an error", input)
+		} else if _, ok := err.(*ParseError); !ok {
+			t.Errorf("Parse(%q): expected a ParseError, got %T", input, err)
+		}
+	}
+}
+
+func TestFromConditions(t *testing.T) {
+	tests := []struct {
+		conditions []Condition
+		want       string
+	}{
+		{nil, ``},
+		{[]Condition{{Field: "article_title", Operator: "contains", Value: "go"}}, `title:go`},
+		{[]Condition{
+			{Field: "feed_name", Values: []string{"A", "B"}},
+			{Logic: "and", Field: "is_read", Value: "false"},
+			{Logic: "or", Field: "author", Operator: "exact", Value: "Ann", Negate: true},
+		}, `(feed:A OR feed:B) AND NOT read OR NOT author=Ann`},
+		{[]Condition{
+			{Field: "published_after_days", Value: "7"},
+			{Logic: "and", Field: "published_before", Value: "2024-05-01"},
+			{Logic: "and", Field: "feed_articles_per_month", Value: "10"},
+		}, `published>=7d AND published<=2024-05-01 AND feed_articles_per_month>=10`},
+		// Unknown fields and empty values match everything; conditions without logic are ignored
+		{[]Condition{{Field: "article_tags", Values: []string{"x"}}, {Logic: "or", Field: "unknown"}}, ``},
+		{[]Condition{{Field: "has_image", Value: "true"}, {Field: "is_read", Value: "true"}}, `has_image`},
+	}
+
+	for _, tt := range tests {
+		if got := FromConditions(tt.conditions).String(); got != tt.want {
+			t.Errorf("FromConditions(%+v) = %s, want %s", tt.conditions, got, tt.want)
+		}
+	}
+
+	n, err := ParseStored(`[{"field":"article_title","operator":"regex","value":"^Go"}]`)
+	if err != nil || n.String() != `title~^Go` {
+		t.Errorf("ParseStored: %v, %v", n, err)
+	}
+}
+
+// TestCompileSQL checks that the SQL prefilter followed by Match selects exactly
+// the articles Match selects on its own
+func TestCompileSQL(t *testing.T) {
+	db, err := sqlite.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+
+	goBlog, _ := db.AddFeed(&models.Feed{Title: "Go Blog", URL: "http://example.com/go", Category: "Tech"})
+	news, _ := db.AddFeed(&models.Feed{Title: "News", URL: "http://example.com/news", Category: "World", IsImageMode: true})
+	now := time.Now().UTC()
+	err = db.SaveArticles(context.Background(), []*models.Article{
+		{FeedID: goBlog, Title: "Generics in Go", URL: "http://example.com/go/1", Author: "Ann", PublishedAt: now.Add(-2 * time.Hour)},
+		{FeedID: goBlog, Title: "Go 1.22 is released", URL: "http://example.com/go/2", Author: "Bob", ImageURL: "http://example.com/a.png", PublishedAt: now.Add(-10 * 24 * time.Hour)},
+		{FeedID: news, Title: "Élections: résultats", URL: "http://example.com/news/1", PublishedAt: now.Add(-30 * time.Hour), IsRead: true},
+		{FeedID: news, Title: "100% of 50_50 votes", URL: "http://example.com/news/2", PublishedAt: now.Add(-400 * 24 * time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+	all, err := db.GetArticles("", 0, "", true, 100, 0)
+	if err != nil || len(all) != 4 {
+		t.Fatalf("GetArticles: %d articles (%v)", len(all), err)
+	}
+	byTitle := make(map[string]models.Article)
+	for _, a := range all {
+		byTitle[a.Title] = a
+	}
+	generics, release := byTitle["Generics in Go"], byTitle["Go 1.22 is released"]
+	if err := db.SetArticleContent(generics.ID, "Type parameters are here"); err != nil {
+		t.Fatalf("SetArticleContent: %v", err)
+	}
+	result, err := db.Exec(`INSERT INTO tags (user_id, name, color, position) VALUES (1, 'lang', '#000000', 1)`)
+	if err != nil {
+		t.Fatalf("insert tag: %v", err)
+	}
+	tagID, _ := result.LastInsertId()
+	if err := db.SetArticleTagsForUser(1, release.ID, []int64{tagID}); err != nil {
+		t.Fatalf("SetArticleTagsForUser: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{``, 4},
+		{`feed:"Go Blog" AND (title~"Generics" OR tag:lang) AND NOT read AND published>7d`, 1},
+		{`feed:"go blog" (title~"(?i)generics" OR tag:lang)`, 2},
+		{`NOT feed:news`, 2},
+		{`image_mode OR has:image`, 3},
+		{`title:élections`, 1},
+		{`NOT title:ÉLECTIONS`, 3},
+		{`title:"100%"`, 1},
+		{`title:0_5`, 1},
+		{`title="go 1.22 is released"`, 1},
+		{`content:parameters`, 1},
+		{`NOT content:parameters`, 3},
+		{`published<1w`, 2},
+		{`NOT published>=2d`, 2},
+		{`published<=` + now.AddDate(-1, 0, 0).Format("2006-01-02"), 1},
+		{`published:` + generics.PublishedAt.UTC().Format("2006-01-02"), 1},
+		{`category=tech AND author:ann`, 1},
+		{`read OR -tag:lang`, 3},
+	}
+
+	env, err := LoadEnv(db)
+	if err != nil {
+		t.Fatalf("LoadEnv: %v", err)
+	}
+	for _, tt := range tests {
+		n, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.query, err)
+			continue
+		}
+		if err := env.LoadArticleData(db, all, n); err != nil {
+			t.Fatalf("LoadArticleData: %v", err)
+		}
+		if got := len(env.Filter(n, all)); got != tt.want {
+			t.Errorf("%s: matched %d articles, want %d", tt.query, got, tt.want)
+		}
+
+		where, args := CompileSQL(n, env)
+		selected, err := db.GetArticlesWhereForUser(0, where, args, true, 100, 0)
+		if err != nil {
+			t.Errorf("%s: %v (WHERE %s)", tt.query, err, where)
+			continue
+		}
+		if got := len(env.Filter(n, selected)); got != tt.want {
+			t.Errorf("%s: prefiltered match of %d articles, want %d (WHERE %s)", tt.query, got, tt.want, where)
+		}
+	}
+}
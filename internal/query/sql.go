@@ -0,0 +1,229 @@
+package query
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/models"
+)
+
+// publishedDay is the SQL expression of an article's publication time in days.
+// The stored timezone offset is ignored, so date bounds get a margin of a day.
+const publishedDay = "COALESCE(julianday(substr(a.published_at, 1, 19)), 0)"
+
+// flagColumns are the SQL conditions of the article flags
+var flagColumns = map[string]string{
+	"read":            "COALESCE(a.is_read, 0) = 1",
+	"favorite":        "COALESCE(a.is_favorite, 0) = 1",
+	"hidden":          "COALESCE(a.is_hidden, 0) = 1",
+	"read_later":      "COALESCE(a.is_read_later, 0) = 1",
+	"pinned":          "COALESCE(a.is_pinned, 0) = 1",
+	"has_summary":     "COALESCE(a.summary, '') != ''",
+	"has_translation": "COALESCE(a.translated_title, '') != ''",
+	"has_image":       "COALESCE(a.image_url, '') != ''",
+	"has_audio":       "COALESCE(a.audio_url, '') != ''",
+	"has_video":       "COALESCE(a.video_url, '') != ''",
+	"has_highlights":  "EXISTS (SELECT 1 FROM article_highlights ah WHERE ah.article_id = a.id)",
+}
+
+// textColumns are the SQL expressions of the article text fields
+var textColumns = map[string]string{
+	"title":  "COALESCE(a.title, '')",
+	"author": "COALESCE(a.author, '')",
+	"url":    "COALESCE(a.url, '')",
+}
+
+// CompileSQL compiles an expression to a WHERE condition over "articles a".
+// The condition selects every article the expression matches, but may select
+// more: regular expressions and non-ASCII text can't be compared exactly in
+// SQL, and dates are compared with a margin. Selected articles must still be
+// checked with Env.Match.
+func CompileSQL(n Node, env *Env) (string, []interface{}) {
+	c := &sqlCompiler{env: env}
+	where := c.compile(n, true)
+	return where, c.args
+}
+
+type sqlCompiler struct {
+	env  *Env
+	args []interface{}
+}
+
+// compile compiles a node to a condition selecting a superset of its matches
+// when widen is set, or a subset otherwise, as needed below a NOT
+func (c *sqlCompiler) compile(n Node, widen bool) string {
+	switch n := n.(type) {
+	case *And:
+		return c.join(n.Children, " AND ", "1", widen)
+	case *Or:
+		return c.join(n.Children, " OR ", "0", widen)
+	case *Not:
+		return "NOT " + c.compile(n.Child, !widen)
+	case *Term:
+		if where, ok := c.compileTerm(n, widen); ok {
+			return where
+		}
+	}
+	if widen {
+		return "1"
+	}
+	return "0"
+}
+
+func (c *sqlCompiler) join(children []Node, sep, empty string, widen bool) string {
+	if len(children) == 0 {
+		return empty
+	}
+	parts := make([]string, len(children))
+	for i, child := range children {
+		parts[i] = c.compile(child, widen)
+	}
+	return "(" + strings.Join(parts, sep) + ")"
+}
+
+// compileTerm compiles a term, reporting false when it can't be expressed in SQL
+func (c *sqlCompiler) compileTerm(t *Term, widen bool) (string, bool) {
+	info := fields[t.Field]
+	if info.feedLevel {
+		return c.compileFeedTerm(t), true
+	}
+
+	switch info.kind {
+	case kindText:
+		if t.Field == "content" {
+			pattern, ok := c.likeArg(t)
+			if !ok {
+				return "", false
+			}
+			return "EXISTS (SELECT 1 FROM article_contents ac WHERE ac.article_id = a.id AND ac.content LIKE " + pattern + " ESCAPE '\\')", true
+		}
+		pattern, ok := c.likeArg(t)
+		if !ok {
+			return "", false
+		}
+		return textColumns[t.Field] + " LIKE " + pattern + " ESCAPE '\\'", true
+
+	case kindTags:
+		pattern, ok := c.likeArg(t)
+		if !ok {
+			return "", false
+		}
+		return "EXISTS (SELECT 1 FROM article_tags at JOIN tags t ON t.id = at.tag_id WHERE at.article_id = a.id AND t.name LIKE " + pattern + " ESCAPE '\\')", true
+
+	case kindFlag:
+		if t.Op != OpIs && !t.flag {
+			return "NOT " + flagColumns[t.Field], true
+		}
+		return flagColumns[t.Field], true
+
+	case kindDate:
+		return c.compileDate(t, widen), true
+	}
+	return "", false
+}
+
+// likeArg adds the LIKE pattern of a text term. SQLite only folds ASCII letters,
+// so other values are left to Env.Match.
+func (c *sqlCompiler) likeArg(t *Term) (string, bool) {
+	if t.Op == OpMatch || !isASCII(t.Value) {
+		return "", false
+	}
+	pattern := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(t.Value)
+	if t.Op == OpContains {
+		pattern = "%" + pattern + "%"
+	}
+	c.args = append(c.args, pattern)
+	return "?", true
+}
+
+// compileFeedTerm evaluates a term depending only on the feed against every feed,
+// and compiles it to the list of matching feed IDs
+func (c *sqlCompiler) compileFeedTerm(t *Term) string {
+	var matching, other []int64
+	for id, feed := range c.env.Feeds {
+		if c.env.matchTerm(t, models.Article{FeedID: id, FeedTitle: feed.Title}) {
+			matching = append(matching, id)
+		} else {
+			other = append(other, id)
+		}
+	}
+
+	// Articles of unknown feeds are matched like articles of an empty feed
+	if c.env.matchTerm(t, models.Article{FeedID: -1}) {
+		return notInList("a.feed_id", other)
+	}
+	return inList("a.feed_id", matching)
+}
+
+// compileDate compiles a date comparison with a margin of a day
+func (c *sqlCompiler) compileDate(t *Term, widen bool) string {
+	start, end := t.date.bounds(c.env.Now)
+	margin := 24 * time.Hour
+	if !widen {
+		margin = -margin
+	}
+
+	atLeast := func(bound time.Time) string {
+		c.args = append(c.args, sqlTime(bound.Add(-margin)))
+		return publishedDay + " >= julianday(?)"
+	}
+	before := func(bound time.Time) string {
+		c.args = append(c.args, sqlTime(bound.Add(margin)))
+		return publishedDay + " < julianday(?)"
+	}
+
+	switch t.Op {
+	case OpGreater:
+		return atLeast(end)
+	case OpAtLeast:
+		return atLeast(start)
+	case OpLess:
+		return before(start)
+	case OpAtMost:
+		return before(end)
+	}
+	if t.date.isRelative {
+		return atLeast(start)
+	}
+	return "(" + atLeast(start) + " AND " + before(end) + ")"
+}
+
+// sqlTime formats a time for julianday()
+func sqlTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+func inList(column string, ids []int64) string {
+	if len(ids) == 0 {
+		return "0"
+	}
+	return column + " IN (" + joinIDs(ids) + ")"
+}
+
+func notInList(column string, ids []int64) string {
+	if len(ids) == 0 {
+		return "1"
+	}
+	return column + " NOT IN (" + joinIDs(ids) + ")"
+}
+
+// joinIDs formats IDs as a sorted, comma-separated SQL list
+func joinIDs(ids []int64) string {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -21,6 +21,7 @@ func registerArticleRoutes(mux *http.ServeMux, h *core.Handler, cfg Config) {
 	registerProtectedRoute(mux, "/api/articles", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleArticles(h, w, r) })
 	registerProtectedRoute(mux, "/api/articles/images", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleImageGalleryArticles(h, w, r) })
 	registerProtectedRoute(mux, "/api/articles/filter", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleFilteredArticles(h, w, r) })
+	registerProtectedRoute(mux, "/api/articles/search", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleSearchArticles(h, w, r) })
 	registerProtectedRoute(mux, "/api/articles/read", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleMarkReadWithImmediateSync(h, w, r) })
 	registerProtectedRoute(mux, "/api/articles/favorite", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleToggleFavoriteWithImmediateSync(h, w, r) })
 	registerProtectedRoute(mux, "/api/articles/mark-relative", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleMarkRelativeToArticle(h, w, r) })
@@ -29,6 +30,11 @@ func registerArticleRoutes(mux *http.ServeMux, h *core.Handler, cfg Config) {
 	registerProtectedRoute(mux, "/api/articles/mark-all-read", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleMarkAllAsRead(h, w, r) })
 	registerProtectedRoute(mux, "/api/articles/clear-read-later", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleClearReadLater(h, w, r) })
 
+	// Article tags and highlights
+	registerProtectedRoute(mux, "/api/articles/tags", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleArticleTags(h, w, r) })
+	registerProtectedRoute(mux, "/api/articles/highlights", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleArticleHighlights(h, w, r) })
+	registerProtectedRoute(mux, "/api/articles/duplicates", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleArticleDuplicates(h, w, r) })
+
 	// Article content
 	registerProtectedRoute(mux, "/api/articles/content", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleGetArticleContent(h, w, r) })
 	registerProtectedRoute(mux, "/api/articles/fetch-full", authMiddleware, func(w http.ResponseWriter, r *http.Request) { article.HandleFetchFullArticle(h, w, r) })
@@ -27,6 +27,15 @@ func registerFeedRoutes(mux *http.ServeMux, h *core.Handler, cfg Config) {
 	registerProtectedRoute(mux, "/api/feeds/refresh", authMiddleware, func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleRefreshFeed(h, w, r) })
 	registerProtectedRoute(mux, "/api/feeds/reorder", authMiddleware, func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleReorderFeed(h, w, r) })
 	registerProtectedRoute(mux, "/api/feeds/test-imap", authMiddleware, func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleTestIMAPConnection(h, w, r) })
+	registerProtectedRoute(mux, "/api/feeds/newsletter", authMiddleware, func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleAddNewsletter(h, w, r) })
+	registerProtectedRoute(mux, "/api/feeds/mail-archive", authMiddleware, func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleImportMailArchive(h, w, r) })
+	registerProtectedRoute(mux, "/api/feeds/jsonpath/preview", authMiddleware, func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandlePreviewJSONPathFeed(h, w, r) })
+	registerProtectedRoute(mux, "/api/feeds/health", authMiddleware, func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleFeedHealth(h, w, r) })
+	registerProtectedRoute(mux, "/api/feeds/fetch-log", authMiddleware, func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleFeedFetchLog(h, w, r) })
+	registerProtectedRoute(mux, "/api/feeds/rediscover", authMiddleware, func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleRediscoverFeed(h, w, r) })
+	registerProtectedRoute(mux, "/api/feeds/url-changes", authMiddleware, func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleFeedURLChanges(h, w, r) })
+	registerProtectedRoute(mux, "/api/feeds/auth", authMiddleware, func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleFeedAuth(h, w, r) })
+	registerProtectedRoute(mux, "/api/feeds/auth/cookies", authMiddleware, func(w http.ResponseWriter, r *http.Request) { feedhandlers.HandleImportFeedCookies(h, w, r) })
 
 	// Discovery routes
 	registerProtectedRoute(mux, "/api/feeds/discover", authMiddleware, func(w http.ResponseWriter, r *http.Request) { discovery.HandleDiscoverBlogs(h, w, r) })
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"net/http"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/fever"
+	"MavenRSS/internal/middleware"
+)
+
+// registerFeverRoutes registers the Fever API and its credential management route.
+// Fever keys are per user, so the API is only available in server mode.
+func registerFeverRoutes(mux *http.ServeMux, h *core.Handler, cfg Config) {
+	if !cfg.EnableAuth || cfg.JWTManager == nil {
+		return
+	}
+	authMiddleware := middleware.AuthMiddleware(cfg.JWTManager)
+
+	// The Fever endpoint authenticates with its own api_key parameter
+	registerPublicRoute(mux, "/api/fever.php", func(w http.ResponseWriter, r *http.Request) { fever.HandleFever(h, w, r) })
+	registerProtectedRoute(mux, "/api/fever/credentials", authMiddleware, func(w http.ResponseWriter, r *http.Request) { fever.HandleFeverCredentials(h, w, r) })
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/greader"
+	"MavenRSS/internal/middleware"
+)
+
+// registerGReaderRoutes registers the Google Reader compatible API.
+// It is only available in server mode, where users authenticate with a username and password.
+func registerGReaderRoutes(mux *http.ServeMux, h *core.Handler, cfg Config) {
+	if !cfg.EnableAuth || cfg.JWTManager == nil {
+		return
+	}
+
+	loginRateLimiter := middleware.RateLimiter(middleware.RateLimiterConfig{
+		RequestsPerSecond: 2,
+		BurstSize:         5,
+		CleanupInterval:   time.Minute,
+	})
+
+	greaderHandler := greader.NewHandler(h, cfg.JWTManager)
+	mux.Handle("/api/greader.php/accounts/ClientLogin", loginRateLimiter(http.StripPrefix("/api/greader.php", greaderHandler)))
+	mux.Handle("/api/greader.php/", http.StripPrefix("/api/greader.php", greaderHandler))
+}
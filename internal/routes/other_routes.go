@@ -11,6 +11,7 @@ import (
 	opml "MavenRSS/internal/api/opml"
 	rules "MavenRSS/internal/api/rules"
 	script "MavenRSS/internal/api/script"
+	siterules "MavenRSS/internal/api/siterules"
 	update "MavenRSS/internal/api/update"
 	window "MavenRSS/internal/api/window"
 	"MavenRSS/internal/middleware"
@@ -43,7 +44,16 @@ func registerOtherRoutes(mux *http.ServeMux, h *core.Handler, cfg Config) {
 	registerPublicRoute(mux, "/api/version", func(w http.ResponseWriter, r *http.Request) { update.HandleVersion(h, w, r) })
 
 	// Rules
+	rules.RegisterActionServices(h)
 	registerProtectedRoute(mux, "/api/rules/apply", authMiddleware, func(w http.ResponseWriter, r *http.Request) { rules.HandleApplyRule(h, w, r) })
+	registerProtectedRoute(mux, "/api/rules", authMiddleware, func(w http.ResponseWriter, r *http.Request) { rules.HandleRules(h, w, r) })
+	registerProtectedRoute(mux, "/api/rules/executions", authMiddleware, func(w http.ResponseWriter, r *http.Request) { rules.HandleRuleExecutions(h, w, r) })
+	registerProtectedRoute(mux, "/api/rules/webhooks/deliveries", authMiddleware, func(w http.ResponseWriter, r *http.Request) { rules.HandleWebhookDeliveries(h, w, r) })
+
+	// Full-text site rules
+	registerProtectedRoute(mux, "/api/site-rules", authMiddleware, func(w http.ResponseWriter, r *http.Request) { siterules.HandleSiteRules(h, w, r) })
+	registerProtectedRoute(mux, "/api/site-rules/import", authMiddleware, func(w http.ResponseWriter, r *http.Request) { siterules.HandleImportSiteConfig(h, w, r) })
+	registerProtectedRoute(mux, "/api/site-rules/test", authMiddleware, func(w http.ResponseWriter, r *http.Request) { siterules.HandleTestSiteRule(h, w, r) })
 
 	// Scripts
 	registerProtectedRoute(mux, "/api/scripts/dir", authMiddleware, func(w http.ResponseWriter, r *http.Request) { script.HandleGetScriptsDir(h, w, r) })
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"net/http"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/output"
+	"MavenRSS/internal/middleware"
+)
+
+// registerOutputRoutes registers the output feed endpoints.
+// Output feeds publish a user's articles, so they are only available in server mode.
+func registerOutputRoutes(mux *http.ServeMux, h *core.Handler, cfg Config) {
+	if !cfg.EnableAuth || cfg.JWTManager == nil {
+		return
+	}
+	authMiddleware := middleware.AuthMiddleware(cfg.JWTManager)
+
+	// Published feeds are authenticated by the token in their URL
+	registerPublicRoute(mux, "/api/output/{token}/{format}", func(w http.ResponseWriter, r *http.Request) { output.HandleOutputFeed(h, w, r) })
+	registerProtectedRoute(mux, "/api/output-feeds", authMiddleware, func(w http.ResponseWriter, r *http.Request) { output.HandleOutputFeeds(h, w, r) })
+}
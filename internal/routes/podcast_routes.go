@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"net/http"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/podcast"
+	"MavenRSS/internal/middleware"
+)
+
+// registerPodcastRoutes registers podcast episode and playback position routes
+func registerPodcastRoutes(mux *http.ServeMux, h *core.Handler, cfg Config) {
+	var authMiddleware middleware.Middleware
+	if cfg.EnableAuth && cfg.JWTManager != nil {
+		authMiddleware = middleware.AuthMiddleware(cfg.JWTManager)
+	}
+
+	registerProtectedRoute(mux, "/api/podcast/episode", authMiddleware, func(w http.ResponseWriter, r *http.Request) { podcast.HandleEpisode(h, w, r) })
+	registerProtectedRoute(mux, "/api/podcast/playback", authMiddleware, func(w http.ResponseWriter, r *http.Request) { podcast.HandlePlayback(h, w, r) })
+}
@@ -80,6 +80,11 @@ func RegisterAPIRoutesWithConfig(mux *http.ServeMux, h *core.Handler, cfg Config
 	registerAIRoutes(mux, h, cfg)
 	registerSettingsRoutes(mux, h, cfg)
 	registerOtherRoutes(mux, h, cfg)
+	registerGReaderRoutes(mux, h, cfg)
+	registerFeverRoutes(mux, h, cfg)
+	registerWebSubRoutes(mux, h, cfg)
+	registerOutputRoutes(mux, h, cfg)
+	registerPodcastRoutes(mux, h, cfg)
 }
 
 // WrapWithMiddleware wraps an http.Handler with the standard middleware chain.
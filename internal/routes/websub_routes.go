@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"net/http"
+
+	"MavenRSS/internal/api/core"
+	"MavenRSS/internal/api/websub"
+)
+
+// registerWebSubRoutes registers the WebSub subscriber callback.
+// Hubs must be able to reach the server, so push subscriptions are only available in server mode.
+func registerWebSubRoutes(mux *http.ServeMux, h *core.Handler, cfg Config) {
+	if !cfg.EnableAuth || cfg.JWTManager == nil {
+		return
+	}
+
+	// Callbacks are authenticated by their unguessable token and the X-Hub-Signature
+	registerPublicRoute(mux, "/api/websub/callback/{token}", func(w http.ResponseWriter, r *http.Request) { websub.HandleCallback(h, w, r) })
+}
@@ -2,69 +2,47 @@ package rules
 
 import (
 	"context"
-	"encoding/json"
 	"log"
-	"regexp"
 	"strings"
-	"time"
 
 	"MavenRSS/internal/store/sqlite"
 	"MavenRSS/internal/freshrss"
 	"MavenRSS/internal/models"
-	"MavenRSS/internal/rsshub"
+	"MavenRSS/internal/query"
 )
 
-// getFeedType returns the type code of a feed
-// Possible values: "regular", "freshrss", "rsshub", "script", "xpath", "email"
-func getFeedType(feed *models.Feed) string {
-	// Check FreshRSS
-	if feed.IsFreshRSSSource {
-		return "freshrss"
-	}
-
-	// Check RSSHub
-	if rsshub.IsRSSHubURL(feed.URL) {
-		return "rsshub"
-	}
-
-	// Check custom script
-	if feed.ScriptPath != "" {
-		return "script"
-	}
-
-	// Check email
-	if feed.Type == "email" {
-		return "email"
-	}
+// Condition represents a condition in a rule.
+// Conditions are the legacy rule format, converted to a query with query.FromConditions.
+type Condition = query.Condition
 
-	// Check XPath
-	if feed.Type == "HTML+XPath" || feed.Type == "XML+XPath" {
-		return "xpath"
-	}
-
-	// Default: regular RSS/Atom feed
-	return "regular"
+// Rule represents an automation rule
+type Rule struct {
+	ID         int64          `json:"id"`
+	Name       string         `json:"name"`
+	Enabled    bool           `json:"enabled"`
+	Query      string         `json:"query,omitempty"` // Query language expression; takes precedence over Conditions
+	Conditions []Condition    `json:"conditions"`
+	Actions    []string       `json:"actions"`           // "favorite", "unfavorite", "hide", "unhide", "mark_read", "mark_unread", "read_later", "remove_read_later", "pin", "unpin", "webhook", "summarize", "translate", "fetch_full_text", "export_obsidian", "export_notion"
+	Position   int            `json:"position"`          // Execution order (0 = first)
+	Webhook    *WebhookConfig `json:"webhook,omitempty"` // Configuration of the "webhook" action
+	Schedule   string         `json:"schedule,omitempty"` // "nightly" to re-apply the rule to all articles every night
+	UserID     int64          `json:"-"`                  // Owner of the rule; 0 applies it to the articles of all users
 }
 
-// Condition represents a condition in a rule
-type Condition struct {
-	ID       int64    `json:"id"`
-	Logic    string   `json:"logic"`    // "and", "or" (null for first condition)
-	Negate   bool     `json:"negate"`   // NOT modifier for this condition
-	Field    string   `json:"field"`    // "feed_name", "feed_category", "article_title", etc.
-	Operator string   `json:"operator"` // "contains", "exact"
-	Value    string   `json:"value"`    // Single value for text/date fields
-	Values   []string `json:"values"`   // Multiple values for feed_name and feed_category
-}
+// Rule execution sources
+const (
+	SourceFetch    = sqlite.RuleSourceFetch
+	SourceManual   = sqlite.RuleSourceManual
+	SourceSchedule = sqlite.RuleSourceSchedule
+)
 
-// Rule represents an automation rule
-type Rule struct {
-	ID         int64       `json:"id"`
-	Name       string      `json:"name"`
-	Enabled    bool        `json:"enabled"`
-	Conditions []Condition `json:"conditions"`
-	Actions    []string    `json:"actions"`  // "favorite", "unfavorite", "hide", "unhide", "mark_read", "mark_unread"
-	Position   int         `json:"position"` // Execution order (0 = first)
+// Expression returns the condition expression of the rule: the parsed query,
+// or else the converted legacy conditions
+func (r Rule) Expression() (query.Node, error) {
+	if strings.TrimSpace(r.Query) != "" {
+		return query.Parse(r.Query)
+	}
+	return query.FromConditions(r.Conditions), nil
 }
 
 // Engine handles rule application
@@ -77,334 +55,189 @@ func NewEngine(db *sqlite.DB) *Engine {
 	return &Engine{db: db}
 }
 
-// ApplyRulesToArticles applies all enabled rules to a batch of articles.
+// ApplyRulesToArticles applies the enabled rules of the articles' owners to a batch of articles.
 // Each article is matched against rules in order, and only the first matching rule is applied.
 // This prevents conflicting actions from multiple rules being applied to the same article.
 func (e *Engine) ApplyRulesToArticles(articles []models.Article) (int, error) {
-	// Load rules from settings
-	rulesJSON, _ := e.db.GetSetting("rules")
-	if rulesJSON == "" {
-		return 0, nil
+	// Group articles by owner, as each user has their own rules
+	var userIDs []int64
+	byUser := make(map[int64][]models.Article)
+	for _, article := range articles {
+		userID := article.UserID
+		if userID == 0 {
+			userID = defaultUserID
+		}
+		if _, ok := byUser[userID]; !ok {
+			userIDs = append(userIDs, userID)
+		}
+		byUser[userID] = append(byUser[userID], article)
 	}
 
-	var rules []Rule
-	if err := json.Unmarshal([]byte(rulesJSON), &rules); err != nil {
-		log.Printf("Error parsing rules: %v", err)
-		return 0, err
+	affected := 0
+	for _, userID := range userIDs {
+		n, err := e.applyUserRules(userID, byUser[userID])
+		if err != nil {
+			return affected, err
+		}
+		affected += n
 	}
+	return affected, nil
+}
 
-	// Sort rules by position (ascending) to ensure execution order
-	// Rules without a position field (backward compatibility) are treated as position 0
-	sortRulesByPosition(rules)
-
-	// Get feeds for category and title lookup
-	feeds, err := e.db.GetFeeds()
+// applyUserRules applies the enabled rules of a user to the user's articles
+func (e *Engine) applyUserRules(userID int64, articles []models.Article) (int, error) {
+	rules, err := e.LoadRules(userID)
 	if err != nil {
+		log.Printf("Error loading rules of user %d: %v", userID, err)
 		return 0, err
 	}
 
-	// Create maps of feed ID to feed data
-	feedCategories := make(map[int64]string)
-	feedTitles := make(map[int64]string)
-	feedTypes := make(map[int64]string)
-	feedIsImageMode := make(map[int64]bool)
-	feedIsFreshRSS := make(map[int64]bool)
-	feedTags := make(map[int64][]string)
-
-	for _, feed := range feeds {
-		feedCategories[feed.ID] = feed.Category
-		feedTitles[feed.ID] = feed.Title
-		feedTypes[feed.ID] = getFeedType(&feed)
-		feedIsImageMode[feed.ID] = feed.IsImageMode
-		feedIsFreshRSS[feed.ID] = feed.IsFreshRSSSource
-
-		// Build tag names list for this feed
-		tags, _ := e.db.GetFeedTags(feed.ID)
-		tagNames := make([]string, len(tags))
-		for i, tag := range tags {
-			tagNames[i] = tag.Name
+	// Parse rule expressions, skipping rules that can't be evaluated
+	var enabled []Rule
+	var exprs []query.Node
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		expr, err := rule.Expression()
+		if err != nil {
+			log.Printf("Skipping rule %q: %v", rule.Name, err)
+			continue
 		}
-		feedTags[feed.ID] = tagNames
+		enabled = append(enabled, rule)
+		exprs = append(exprs, expr)
+	}
+	if len(enabled) == 0 {
+		return 0, nil
+	}
+
+	env, err := query.LoadEnv(e.db)
+	if err != nil {
+		return 0, err
+	}
+	if err := env.LoadArticleData(e.db, articles, exprs...); err != nil {
+		return 0, err
 	}
 
 	affected := 0
+	var executions []sqlite.RuleExecution
 	for _, article := range articles {
-		for _, rule := range rules {
-			if !rule.Enabled {
-				continue
-			}
-
+		for i, rule := range enabled {
 			// Check if article matches conditions
-			if matchesConditions(article, rule.Conditions, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, feedTags) {
+			if env.Match(exprs[i], article) {
 				// Apply actions
-				for _, action := range rule.Actions {
-					if err := e.applyAction(article.ID, action); err != nil {
-						log.Printf("Error applying action %s to article %d: %v", action, article.ID, err)
-						continue
-					}
-				}
+				executions = append(executions, e.applyActions(rule, article, env, SourceFetch)...)
 				affected++
 				break // Only apply first matching rule per article to prevent conflicts
 			}
 		}
 	}
+	e.logExecutions(executions)
 
 	return affected, nil
 }
 
-// ApplyRule applies a single rule to all matching articles.
-// Uses batch processing with a reasonable limit to avoid memory issues.
+// ApplyRule applies a single rule to all matching articles of its owner.
+// Articles are prefiltered by the database, with a reasonable limit to avoid memory issues.
 func (e *Engine) ApplyRule(rule Rule) (int, error) {
-	// Get articles in batches to avoid memory issues with large datasets
-	const batchSize = 10000
-	articles, err := e.db.GetArticles("", 0, "", true, batchSize, 0)
+	return e.applyRule(rule, SourceManual)
+}
+
+// PreviewRule returns the IDs of the articles a rule would act on, without applying it
+func (e *Engine) PreviewRule(rule Rule) ([]int64, error) {
+	articles, _, err := e.matchingArticles(rule)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	// Get feeds for category and title lookup
-	feeds, err := e.db.GetFeeds()
-	if err != nil {
-		return 0, err
+	ids := make([]int64, len(articles))
+	for i, article := range articles {
+		ids[i] = article.ID
 	}
+	return ids, nil
+}
 
-	// Create maps of feed ID to feed data
-	feedCategories := make(map[int64]string)
-	feedTitles := make(map[int64]string)
-	feedTypes := make(map[int64]string)
-	feedIsImageMode := make(map[int64]bool)
-	feedIsFreshRSS := make(map[int64]bool)
-	feedTags := make(map[int64][]string)
-
-	for _, feed := range feeds {
-		feedCategories[feed.ID] = feed.Category
-		feedTitles[feed.ID] = feed.Title
-		feedTypes[feed.ID] = getFeedType(&feed)
-		feedIsImageMode[feed.ID] = feed.IsImageMode
-		feedIsFreshRSS[feed.ID] = feed.IsFreshRSSSource
-
-		// Build tag names list for this feed
-		tags, _ := e.db.GetFeedTags(feed.ID)
-		tagNames := make([]string, len(tags))
-		for i, tag := range tags {
-			tagNames[i] = tag.Name
-		}
-		feedTags[feed.ID] = tagNames
+func (e *Engine) applyRule(rule Rule, source string) (int, error) {
+	articles, env, err := e.matchingArticles(rule)
+	if err != nil {
+		return 0, err
 	}
 
-	affected := 0
+	var executions []sqlite.RuleExecution
 	for _, article := range articles {
-		if matchesConditions(article, rule.Conditions, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, feedTags) {
-			for _, action := range rule.Actions {
-				if err := e.applyAction(article.ID, action); err != nil {
-					log.Printf("Error applying action %s to article %d: %v", action, article.ID, err)
-					continue
-				}
-			}
-			affected++
-		}
+		executions = append(executions, e.applyActions(rule, article, env, source)...)
 	}
+	e.logExecutions(executions)
 
-	return affected, nil
+	return len(articles), nil
 }
 
-// matchesConditions checks if an article matches the rule conditions
-func matchesConditions(article models.Article, conditions []Condition, feedCategories map[int64]string, feedTitles map[int64]string, feedTypes map[int64]string, feedIsImageMode map[int64]bool, feedIsFreshRSS map[int64]bool, feedTags map[int64][]string) bool {
-	// If no conditions, apply to all articles
-	if len(conditions) == 0 {
-		return true
+// matchingArticles returns the articles of the rule's owner matching the rule
+func (e *Engine) matchingArticles(rule Rule) ([]models.Article, *query.Env, error) {
+	expr, err := rule.Expression()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	result := evaluateCondition(article, conditions[0], feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, feedTags)
-
-	for i := 1; i < len(conditions); i++ {
-		condition := conditions[i]
-		conditionResult := evaluateCondition(article, condition, feedCategories, feedTitles, feedTypes, feedIsImageMode, feedIsFreshRSS, feedTags)
-
-		switch condition.Logic {
-		case "and":
-			result = result && conditionResult
-		case "or":
-			result = result || conditionResult
-		}
+	env, err := query.LoadEnv(e.db)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return result
-}
-
-// evaluateCondition evaluates a single rule condition
-func evaluateCondition(article models.Article, condition Condition, feedCategories map[int64]string, feedTitles map[int64]string, feedTypes map[int64]string, feedIsImageMode map[int64]bool, feedIsFreshRSS map[int64]bool, feedTags map[int64][]string) bool {
-	var result bool
-
-	switch condition.Field {
-	case "feed_name":
-		feedTitle := feedTitles[article.FeedID]
-		if feedTitle == "" {
-			feedTitle = article.FeedTitle
-		}
-		result = matchMultiSelect(feedTitle, condition.Values, condition.Value)
-
-	case "feed_category":
-		feedCategory := feedCategories[article.FeedID]
-		result = matchMultiSelect(feedCategory, condition.Values, condition.Value)
-
-	case "article_title":
-		if condition.Value == "" {
-			result = true
-		} else {
-			lowerValue := strings.ToLower(condition.Value)
-			lowerTitle := strings.ToLower(article.Title)
-			switch condition.Operator {
-			case "exact":
-				result = lowerTitle == lowerValue
-			case "regex":
-				matched, err := regexp.MatchString(condition.Value, article.Title)
-				if err != nil {
-					log.Printf("Invalid regex pattern: %v", err)
-					result = false
-				} else {
-					result = matched
-				}
-			default:
-				result = strings.Contains(lowerTitle, lowerValue)
-			}
-		}
-
-	case "feed_type":
-		feedType := feedTypes[article.FeedID]
-		result = matchMultiSelect(feedType, condition.Values, condition.Value)
-
-	case "feed_tags":
-		articleTags := feedTags[article.FeedID]
-		// Check if any tag matches
-		result = matchMultiSelectTags(articleTags, condition.Values, condition.Value)
-
-	case "is_freshrss_feed":
-		if condition.Value == "" {
-			result = true
-		} else {
-			wantFreshRSS := condition.Value == "true"
-			result = feedIsFreshRSS[article.FeedID] == wantFreshRSS
-		}
-
-	case "is_image_mode_feed":
-		if condition.Value == "" {
-			result = true
-		} else {
-			wantImageMode := condition.Value == "true"
-			result = feedIsImageMode[article.FeedID] == wantImageMode
-		}
-
-	case "published_after":
-		if condition.Value == "" {
-			result = true
-		} else {
-			afterDate, err := time.Parse("2006-01-02", condition.Value)
-			if err != nil {
-				result = true
-			} else {
-				result = article.PublishedAt.After(afterDate) || article.PublishedAt.Equal(afterDate)
-			}
-		}
-
-	case "published_before":
-		if condition.Value == "" {
-			result = true
-		} else {
-			beforeDate, err := time.Parse("2006-01-02", condition.Value)
-			if err != nil {
-				result = true
-			} else {
-				articleDateOnly := article.PublishedAt.UTC().Truncate(24 * time.Hour)
-				beforeDateOnly := beforeDate.Truncate(24 * time.Hour)
-				result = !articleDateOnly.After(beforeDateOnly)
-			}
-		}
-
-	case "is_read":
-		if condition.Value == "" {
-			result = true
-		} else {
-			wantRead := condition.Value == "true"
-			result = article.IsRead == wantRead
-		}
-
-	case "is_favorite":
-		if condition.Value == "" {
-			result = true
-		} else {
-			wantFavorite := condition.Value == "true"
-			result = article.IsFavorite == wantFavorite
-		}
-
-	case "is_hidden":
-		if condition.Value == "" {
-			result = true
-		} else {
-			wantHidden := condition.Value == "true"
-			result = article.IsHidden == wantHidden
-		}
-
-	case "is_read_later":
-		if condition.Value == "" {
-			result = true
-		} else {
-			wantReadLater := condition.Value == "true"
-			result = article.IsReadLater == wantReadLater
-		}
-
-	default:
-		result = true
+	// Get articles in batches to avoid memory issues with large datasets
+	const batchSize = 10000
+	where, args := query.CompileSQL(expr, env)
+	articles, err := e.db.GetArticlesWhereForUser(rule.UserID, where, args, true, batchSize, 0)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Apply NOT modifier
-	if condition.Negate {
-		return !result
+	if err := env.LoadArticleData(e.db, articles, expr); err != nil {
+		return nil, nil, err
 	}
-	return result
+	return env.Filter(expr, articles), env, nil
 }
 
-// matchMultiSelect checks if fieldValue matches any of the selected values
-func matchMultiSelect(fieldValue string, values []string, singleValue string) bool {
-	if len(values) > 0 {
-		lowerField := strings.ToLower(fieldValue)
-		for _, val := range values {
-			if strings.Contains(lowerField, strings.ToLower(val)) {
-				return true
-			}
-		}
-		return false
-	} else if singleValue != "" {
-		return strings.Contains(strings.ToLower(fieldValue), strings.ToLower(singleValue))
+// applyActions applies all actions of a matching rule to an article,
+// and returns the executions to log
+func (e *Engine) applyActions(rule Rule, article models.Article, env *query.Env, source string) []sqlite.RuleExecution {
+	userID := rule.UserID
+	if userID == 0 {
+		userID = article.UserID
 	}
-	return true
-}
 
-// matchMultiSelectTags checks if any of the article's tags match the selected values
-func matchMultiSelectTags(articleTags []string, values []string, singleValue string) bool {
-	if len(values) > 0 {
-		// Check if any of the selected values match any of the article's tags
-		for _, val := range values {
-			lowerVal := strings.ToLower(val)
-			for _, tag := range articleTags {
-				if strings.Contains(strings.ToLower(tag), lowerVal) {
-					return true
-				}
-			}
+	var executions []sqlite.RuleExecution
+	for _, action := range rule.Actions {
+		var err error
+		switch {
+		case action == ActionWebhook:
+			err = e.queueWebhook(userID, rule, article, env.FeedTitle(article), env.FeedCategory(article), env.FeedTags[article.FeedID])
+		case isServiceAction(action):
+			err = queueServiceAction(userID, article.ID, action)
+		default:
+			err = e.applyAction(article.ID, action)
 		}
-		return false
-	} else if singleValue != "" {
-		// Check if the single value matches any tag
-		lowerVal := strings.ToLower(singleValue)
-		for _, tag := range articleTags {
-			if strings.Contains(strings.ToLower(tag), lowerVal) {
-				return true
-			}
+		if err != nil {
+			log.Printf("Error applying action %s to article %d: %v", action, article.ID, err)
+			continue
 		}
-		return false
+		executions = append(executions, sqlite.RuleExecution{
+			UserID:    userID,
+			RuleID:    rule.ID,
+			RuleName:  rule.Name,
+			ArticleID: article.ID,
+			Action:    action,
+			Source:    source,
+		})
+	}
+	return executions
+}
+
+// logExecutions adds executions to the rule execution log
+func (e *Engine) logExecutions(executions []sqlite.RuleExecution) {
+	if err := e.db.AddRuleExecutions(executions); err != nil {
+		log.Printf("Error logging rule executions: %v", err)
 	}
-	// No filter specified, match all
-	return true
 }
 
 // applyAction applies an action to an article with FreshRSS sync if enabled
@@ -430,6 +263,10 @@ func (e *Engine) applyAction(articleID int64, action string) error {
 		err = e.db.SetArticleReadLater(articleID, true)
 	case "remove_read_later":
 		err = e.db.SetArticleReadLater(articleID, false)
+	case "pin":
+		err = e.db.SetArticlePinned(articleID, true)
+	case "unpin":
+		err = e.db.SetArticlePinned(articleID, false)
 	default:
 		log.Printf("Unknown action: %s", action)
 		return nil
@@ -476,19 +313,3 @@ func (e *Engine) performImmediateSync(syncReq *sqlite.SyncRequest) {
 		log.Printf("[Rule Sync] Success for article %d: %s", syncReq.ArticleID, syncReq.Action)
 	}
 }
-
-// sortRulesByPosition sorts rules by their position field in ascending order
-func sortRulesByPosition(rules []Rule) {
-	// Use the built-in sort package with a custom comparator
-	for i := 0; i < len(rules); i++ {
-		for j := i + 1; j < len(rules); j++ {
-			// Compare positions, defaulting to 0 for backward compatibility
-			posI := rules[i].Position
-			posJ := rules[j].Position
-			if posI > posJ {
-				// Swap
-				rules[i], rules[j] = rules[j], rules[i]
-			}
-		}
-	}
-}
@@ -1,9 +1,10 @@
 package rules
 
 import (
-	"encoding/json"
+	"context"
 	"os"
 	"testing"
+	"time"
 
 	"MavenRSS/internal/store/sqlite"
 	"MavenRSS/internal/models"
@@ -45,9 +46,9 @@ func TestEngine_ApplyRulesToArticles(t *testing.T) {
 		Actions: []string{"favorite", "mark_read"},
 	}
 
-	rules := []Rule{rule}
-	rulesJSON, _ := json.Marshal(rules)
-	engine.db.SetSetting("rules", string(rulesJSON))
+	if err := engine.SaveRules(1, []Rule{rule}); err != nil {
+		t.Fatalf("SaveRules failed: %v", err)
+	}
 
 	// Create test articles
 	articles := []models.Article{
@@ -72,6 +73,53 @@ func TestEngine_ApplyRulesToArticles(t *testing.T) {
 	}
 }
 
+func TestEngine_ApplyRulesToArticlesPerUser(t *testing.T) {
+	engine := setupTestEngine(t)
+
+	other, err := engine.db.CreateUser(&models.User{Username: "other", Email: "other@example.com", Role: models.RoleUser, Status: "active"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	for _, userID := range []int64{1, other} {
+		feedID, err := engine.db.AddFeedForUser(userID, &models.Feed{Title: "F", URL: "http://example.com/feed"})
+		if err != nil {
+			t.Fatalf("AddFeedForUser(%d): %v", userID, err)
+		}
+		if err := engine.db.SaveArticles(context.Background(), []*models.Article{
+			{UserID: userID, FeedID: feedID, Title: "news", URL: "http://example.com/1", PublishedAt: time.Now()},
+		}); err != nil {
+			t.Fatalf("SaveArticles(%d): %v", userID, err)
+		}
+	}
+	if err := engine.SaveRules(1, []Rule{{Name: "Star", Enabled: true, Query: "news", Actions: []string{"favorite"}}}); err != nil {
+		t.Fatalf("SaveRules(1): %v", err)
+	}
+	if err := engine.SaveRules(other, []Rule{{Name: "Read", Enabled: true, Query: "news", Actions: []string{"mark_read"}}}); err != nil {
+		t.Fatalf("SaveRules(%d): %v", other, err)
+	}
+
+	// Articles loaded from the database keep their owner, so each gets its owner's rules
+	articles, err := engine.db.GetArticles("", 0, "", false, 10, 0)
+	if err != nil || len(articles) != 2 {
+		t.Fatalf("expected 2 articles, got %d (%v)", len(articles), err)
+	}
+	if _, err := engine.ApplyRulesToArticles(articles); err != nil {
+		t.Fatalf("ApplyRulesToArticles failed: %v", err)
+	}
+	for _, a := range articles {
+		article, err := engine.db.GetArticleByID(a.ID)
+		if err != nil || article == nil {
+			t.Fatalf("GetArticleByID(%d): %v", a.ID, err)
+		}
+		if article.UserID != a.UserID {
+			t.Errorf("article %d: expected owner %d, got %d", a.ID, a.UserID, article.UserID)
+		}
+		if want := article.UserID == 1; article.IsFavorite != want || article.IsRead == want {
+			t.Errorf("user %d article: favorite=%v read=%v", article.UserID, article.IsFavorite, article.IsRead)
+		}
+	}
+}
+
 func TestEngine_ApplyRule(t *testing.T) {
 	engine := setupTestEngine(t)
 
@@ -100,3 +148,107 @@ func TestEngine_ApplyRule(t *testing.T) {
 		t.Errorf("Expected 0 articles to be processed, got %d", count)
 	}
 }
+
+func TestEngine_ArticleAnnotationConditions(t *testing.T) {
+	engine := setupTestEngine(t)
+
+	feedID, err := engine.db.AddFeed(&models.Feed{Title: "F", URL: "http://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed: %v", err)
+	}
+	if err := engine.db.SaveArticles(context.Background(), []*models.Article{
+		{FeedID: feedID, Title: "first", URL: "http://example.com/1", PublishedAt: time.Now()},
+		{FeedID: feedID, Title: "second", URL: "http://example.com/2", PublishedAt: time.Now().Add(-time.Hour)},
+	}); err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+	articles, err := engine.db.GetArticles("", feedID, "", false, 10, 0)
+	if err != nil || len(articles) != 2 {
+		t.Fatalf("expected 2 articles, got %d (%v)", len(articles), err)
+	}
+
+	result, err := engine.db.Exec(`INSERT INTO tags (user_id, name, color, position) VALUES (1, 'Later', '#000000', 1)`)
+	if err != nil {
+		t.Fatalf("insert tag: %v", err)
+	}
+	tagID, _ := result.LastInsertId()
+	if err := engine.db.SetArticleTagsForUser(1, articles[0].ID, []int64{tagID}); err != nil {
+		t.Fatalf("SetArticleTagsForUser: %v", err)
+	}
+	if _, err := engine.db.CreateHighlight(&models.ArticleHighlight{UserID: 1, ArticleID: articles[1].ID, Text: "quote"}); err != nil {
+		t.Fatalf("CreateHighlight: %v", err)
+	}
+
+	for _, cond := range []Condition{
+		{Field: "article_tags", Values: []string{"Later"}},
+		{Field: "has_highlights", Value: "true"},
+	} {
+		count, err := engine.ApplyRule(Rule{Name: cond.Field, Enabled: true, Conditions: []Condition{cond}, Actions: []string{"favorite"}})
+		if err != nil {
+			t.Fatalf("ApplyRule(%s): %v", cond.Field, err)
+		}
+		if count != 1 {
+			t.Errorf("%s: expected 1 matching article, got %d", cond.Field, count)
+		}
+	}
+}
+
+func TestEngine_PreviewAndSchedule(t *testing.T) {
+	engine := setupTestEngine(t)
+
+	feedID, err := engine.db.AddFeed(&models.Feed{Title: "F", URL: "http://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed: %v", err)
+	}
+	now := time.Now()
+	if err := engine.db.SaveArticles(context.Background(), []*models.Article{
+		{FeedID: feedID, Title: "fresh", URL: "http://example.com/1", PublishedAt: now},
+		{FeedID: feedID, Title: "stale", URL: "http://example.com/2", PublishedAt: now.AddDate(0, 0, -40)},
+	}); err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+
+	rule := Rule{Name: "Old news", Enabled: true, Query: "published<30d", Actions: []string{"mark_read"}, Schedule: ScheduleNightly, UserID: 1}
+	if err := engine.SaveRule(&rule); err != nil {
+		t.Fatalf("SaveRule: %v", err)
+	}
+
+	// A dry run returns the matching articles without acting on them
+	ids, err := engine.PreviewRule(rule)
+	if err != nil || len(ids) != 1 {
+		t.Fatalf("PreviewRule = %v, %v", ids, err)
+	}
+	if article, _ := engine.db.GetArticleByID(ids[0]); article == nil || article.IsRead {
+		t.Fatalf("dry run modified article: %+v", article)
+	}
+	if executions, _ := engine.db.GetRuleExecutions(1, rule.ID, 10, 0); len(executions) != 0 {
+		t.Fatalf("dry run logged executions: %+v", executions)
+	}
+
+	// The scheduled rule waits for the next night, then runs once per night
+	noon := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, now.Location())
+	if n, err := engine.ApplyScheduledRules(noon); err != nil || n != 0 {
+		t.Fatalf("ApplyScheduledRules of a new rule = %d, %v", n, err)
+	}
+	night := time.Date(now.Year(), now.Month(), now.Day()+1, nightlyHour, 30, 0, 0, now.Location())
+	if n, err := engine.ApplyScheduledRules(night.Add(-time.Hour)); err != nil || n != 0 {
+		t.Fatalf("ApplyScheduledRules before the night = %d, %v", n, err)
+	}
+	if n, err := engine.ApplyScheduledRules(night); err != nil || n != 1 {
+		t.Fatalf("ApplyScheduledRules = %d, %v", n, err)
+	}
+	if n, err := engine.ApplyScheduledRules(night.Add(time.Hour)); err != nil || n != 0 {
+		t.Fatalf("second ApplyScheduledRules = %d, %v", n, err)
+	}
+	if article, _ := engine.db.GetArticleByID(ids[0]); article == nil || !article.IsRead {
+		t.Fatalf("scheduled rule not applied: %+v", article)
+	}
+
+	executions, err := engine.db.GetRuleExecutions(1, rule.ID, 10, 0)
+	if err != nil || len(executions) != 1 {
+		t.Fatalf("GetRuleExecutions = %+v, %v", executions, err)
+	}
+	if e := executions[0]; e.ArticleID != ids[0] || e.Action != "mark_read" || e.Source != SourceSchedule || e.RuleName != "Old news" {
+		t.Errorf("unexpected execution %+v", e)
+	}
+}
@@ -0,0 +1,89 @@
+package rules
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ScheduleNightly re-applies a rule to all articles of its owner every night,
+// so that time-based conditions such as "published<30d" catch up with articles
+// that only match as they age.
+const ScheduleNightly = "nightly"
+
+const (
+	// nightlyHour is the local hour from which nightly rules are due
+	nightlyHour = 3
+	// scheduleCheckInterval is how often due rules are looked for
+	scheduleCheckInterval = time.Hour
+)
+
+// RunSchedule applies due scheduled rules until the context is cancelled
+func (e *Engine) RunSchedule(ctx context.Context) {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if affected, err := e.ApplyScheduledRules(time.Now()); err != nil {
+			log.Printf("Error applying scheduled rules: %v", err)
+		} else if affected > 0 {
+			log.Printf("Scheduled rules applied to %d articles", affected)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ApplyScheduledRules applies the scheduled rules of all users that haven't run since
+// the last nightly run time before now. Rules seen for the first time wait for the next one.
+func (e *Engine) ApplyScheduledRules(now time.Time) (int, error) {
+	records, err := e.db.GetScheduledRules()
+	if err != nil {
+		return 0, err
+	}
+
+	due := lastNightlyRun(now)
+	affected := 0
+	for _, record := range records {
+		if record.LastScheduledAt.IsZero() {
+			// A newly scheduled rule first runs at the next nightly run time
+			if err := e.db.SetRuleScheduledAt(record.UserID, record.ID, now); err != nil {
+				return affected, err
+			}
+			continue
+		}
+		if !record.LastScheduledAt.Before(due) {
+			continue
+		}
+		rule, err := ruleFromRecord(record)
+		if err != nil {
+			log.Printf("Skipping scheduled rule %d of user %d: %v", record.ID, record.UserID, err)
+			continue
+		}
+
+		n, err := e.applyRule(rule, SourceSchedule)
+		if err != nil {
+			log.Printf("Error applying scheduled rule %q: %v", rule.Name, err)
+			continue
+		}
+		affected += n
+
+		if err := e.db.SetRuleScheduledAt(record.UserID, record.ID, now); err != nil {
+			return affected, err
+		}
+	}
+	return affected, nil
+}
+
+// lastNightlyRun returns the most recent nightly run time at or before now
+func lastNightlyRun(now time.Time) time.Time {
+	run := time.Date(now.Year(), now.Month(), now.Day(), nightlyHour, 0, 0, 0, now.Location())
+	if now.Before(run) {
+		run = run.AddDate(0, 0, -1)
+	}
+	return run
+}
@@ -0,0 +1,109 @@
+package rules
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// Rule actions performed by the application services
+const (
+	ActionSummarize      = "summarize"       // Generate the article summary with the summary provider
+	ActionTranslate      = "translate"       // Translate the article title to the target language
+	ActionFetchFullText  = "fetch_full_text" // Fetch the full text of the original page into the content cache
+	ActionExportObsidian = "export_obsidian" // Export the article to the Obsidian vault
+	ActionExportNotion   = "export_notion"   // Export the article to Notion
+)
+
+// serviceQueueSize is the number of service actions that can wait to be performed
+const serviceQueueSize = 1000
+
+// Services performs the rule actions that need more than the database: AI and
+// translation providers, page fetching and note-taking integrations. It is
+// implemented by the API layer and registered at startup with SetServices.
+// Requests to AI providers must be made with background priority and respect
+// the usage limit of the user.
+type Services interface {
+	Summarize(userID, articleID int64) error
+	Translate(userID, articleID int64) error
+	FetchFullText(userID, articleID int64) error
+	Export(userID, articleID int64, target string) error // target is "obsidian" or "notion"
+}
+
+type serviceTask struct {
+	userID    int64
+	articleID int64
+	action    string
+}
+
+var (
+	servicesMu    sync.RWMutex
+	services      Services
+	serviceQueue  = make(chan serviceTask, serviceQueueSize)
+	serviceWorker sync.Once
+)
+
+// SetServices registers the services performing the summarize, translate,
+// fetch_full_text and export actions
+func SetServices(s Services) {
+	servicesMu.Lock()
+	defer servicesMu.Unlock()
+	services = s
+}
+
+func getServices() Services {
+	servicesMu.RLock()
+	defer servicesMu.RUnlock()
+	return services
+}
+
+// isServiceAction reports whether an action is performed by the services
+func isServiceAction(action string) bool {
+	switch action {
+	case ActionSummarize, ActionTranslate, ActionFetchFullText, ActionExportObsidian, ActionExportNotion:
+		return true
+	}
+	return false
+}
+
+// queueServiceAction queues a service action for an article. Service actions are
+// slow, and rules run while feeds are refreshed, so they are performed one at a
+// time in the background.
+func queueServiceAction(userID, articleID int64, action string) error {
+	if getServices() == nil {
+		return fmt.Errorf("action %s is not available", action)
+	}
+
+	serviceWorker.Do(func() { go runServiceActions() })
+	select {
+	case serviceQueue <- serviceTask{userID: userID, articleID: articleID, action: action}:
+		return nil
+	default:
+		return fmt.Errorf("too many queued actions")
+	}
+}
+
+// runServiceActions performs the queued service actions
+func runServiceActions() {
+	for task := range serviceQueue {
+		if err := performServiceAction(getServices(), task); err != nil {
+			log.Printf("Error performing rule action %s on article %d: %v", task.action, task.articleID, err)
+		}
+	}
+}
+
+func performServiceAction(s Services, task serviceTask) error {
+	switch task.action {
+	case ActionSummarize:
+		return s.Summarize(task.userID, task.articleID)
+	case ActionTranslate:
+		return s.Translate(task.userID, task.articleID)
+	case ActionFetchFullText:
+		return s.FetchFullText(task.userID, task.articleID)
+	case ActionExportObsidian:
+		return s.Export(task.userID, task.articleID, "obsidian")
+	case ActionExportNotion:
+		return s.Export(task.userID, task.articleID, "notion")
+	}
+	return fmt.Errorf("unknown action %s", task.action)
+}
@@ -0,0 +1,89 @@
+package rules
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/models"
+)
+
+type fakeServices struct {
+	mu    sync.Mutex
+	calls []string
+	done  chan struct{}
+}
+
+func (f *fakeServices) record(call string) error {
+	f.mu.Lock()
+	f.calls = append(f.calls, call)
+	f.mu.Unlock()
+	f.done <- struct{}{}
+	return nil
+}
+
+func (f *fakeServices) Summarize(userID, articleID int64) error { return f.record("summarize") }
+func (f *fakeServices) Translate(userID, articleID int64) error { return f.record("translate") }
+func (f *fakeServices) FetchFullText(userID, articleID int64) error {
+	return f.record("fetch_full_text")
+}
+func (f *fakeServices) Export(userID, articleID int64, target string) error {
+	return f.record("export_" + target)
+}
+
+func TestEngine_ServiceAndPinActions(t *testing.T) {
+	engine := setupTestEngine(t)
+
+	feedID, err := engine.db.AddFeed(&models.Feed{Title: "F", URL: "http://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed: %v", err)
+	}
+	if err := engine.db.SaveArticles(context.Background(), []*models.Article{
+		{FeedID: feedID, Title: "important", URL: "http://example.com/1", PublishedAt: time.Now().Add(-time.Hour)},
+		{FeedID: feedID, Title: "other", URL: "http://example.com/2", PublishedAt: time.Now()},
+	}); err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+
+	// Without registered services, service actions are not performed
+	SetServices(nil)
+	rule := Rule{Name: "Pin", Enabled: true, UserID: 1, Query: "title:important", Actions: []string{"pin", ActionSummarize}}
+	if _, err := engine.ApplyRule(rule); err != nil {
+		t.Fatalf("ApplyRule: %v", err)
+	}
+	executions, err := engine.db.GetRuleExecutions(1, 0, 10, 0)
+	if err != nil || len(executions) != 1 || executions[0].Action != "pin" {
+		t.Fatalf("executions without services: %+v (%v)", executions, err)
+	}
+
+	// Pinned articles are listed first
+	articles, err := engine.db.GetArticles("", feedID, "", false, 10, 0)
+	if err != nil || len(articles) != 2 {
+		t.Fatalf("GetArticles: %d (%v)", len(articles), err)
+	}
+	if !articles[0].IsPinned || articles[0].Title != "important" {
+		t.Errorf("pinned article not first: %+v", articles[0])
+	}
+
+	fake := &fakeServices{done: make(chan struct{}, 2)}
+	SetServices(fake)
+	t.Cleanup(func() { SetServices(nil) })
+
+	rule.Actions = []string{ActionSummarize, ActionExportObsidian}
+	if _, err := engine.ApplyRule(rule); err != nil {
+		t.Fatalf("ApplyRule: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-fake.done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("service actions not performed: %v", fake.calls)
+		}
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.calls) != 2 || fake.calls[0] != "summarize" || fake.calls[1] != "export_obsidian" {
+		t.Errorf("unexpected service calls %v", fake.calls)
+	}
+}
@@ -0,0 +1,183 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"MavenRSS/internal/store/sqlite"
+)
+
+// defaultUserID owns the rules of single-user installations and of articles without an owner
+const defaultUserID = 1
+
+// LoadRules returns the rules of a user in execution order
+func (e *Engine) LoadRules(userID int64) ([]Rule, error) {
+	records, err := e.db.GetRulesForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(records))
+	for _, record := range records {
+		rule, err := ruleFromRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", record.ID, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SaveRules replaces all rules of a user
+func (e *Engine) SaveRules(userID int64, rules []Rule) error {
+	records := make([]sqlite.RuleRecord, len(rules))
+	for i, rule := range rules {
+		rule.UserID = userID
+		record, err := rule.record()
+		if err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		records[i] = record
+	}
+	return e.db.ReplaceRulesForUser(userID, records)
+}
+
+// SaveRulesJSON replaces all rules of a user with rules in the JSON format of the "rules" setting
+func (e *Engine) SaveRulesJSON(userID int64, rulesJSON string) error {
+	rules, err := ParseRulesJSON(rulesJSON)
+	if err != nil {
+		return err
+	}
+	return e.SaveRules(userID, rules)
+}
+
+// ParseRulesJSON decodes rules in the JSON format of the "rules" setting and validates them
+func ParseRulesJSON(rulesJSON string) ([]Rule, error) {
+	var rules []Rule
+	if strings.TrimSpace(rulesJSON) != "" {
+		if err := json.Unmarshal([]byte(rulesJSON), &rules); err != nil {
+			return nil, err
+		}
+	}
+	for _, rule := range rules {
+		if err := ValidateRule(rule); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+	}
+	return rules, nil
+}
+
+// SaveRule creates or updates a rule of rule.UserID. A new rule gets its ID assigned.
+func (e *Engine) SaveRule(rule *Rule) error {
+	record, err := rule.record()
+	if err != nil {
+		return err
+	}
+	if err := e.db.SaveRuleForUser(&record); err != nil {
+		return err
+	}
+	rule.ID = record.ID
+	return nil
+}
+
+// DeleteRule deletes a rule of a user. Returns sql.ErrNoRows if the user has no such rule.
+func (e *Engine) DeleteRule(userID, id int64) error {
+	return e.db.DeleteRuleForUser(userID, id)
+}
+
+// ValidateRule checks that a rule has actions, a valid expression, webhook and schedule
+func ValidateRule(rule Rule) error {
+	if len(rule.Actions) == 0 {
+		return errors.New("rule has no actions")
+	}
+	if _, err := rule.Expression(); err != nil {
+		return err
+	}
+	for _, action := range rule.Actions {
+		if action == ActionWebhook {
+			if err := ValidateWebhookConfig(rule.Webhook); err != nil {
+				return err
+			}
+		}
+	}
+	return ValidateSchedule(rule.Schedule)
+}
+
+// ValidateSchedule checks that a rule schedule is supported
+func ValidateSchedule(schedule string) error {
+	if schedule != "" && schedule != ScheduleNightly {
+		return fmt.Errorf("unsupported schedule %q", schedule)
+	}
+	return nil
+}
+
+// record converts a rule to its stored form
+func (r Rule) record() (sqlite.RuleRecord, error) {
+	if err := ValidateSchedule(r.Schedule); err != nil {
+		return sqlite.RuleRecord{}, err
+	}
+
+	conditions := r.Conditions
+	if conditions == nil {
+		conditions = []Condition{}
+	}
+	conditionsJSON, err := json.Marshal(conditions)
+	if err != nil {
+		return sqlite.RuleRecord{}, err
+	}
+	actions := r.Actions
+	if actions == nil {
+		actions = []string{}
+	}
+	actionsJSON, err := json.Marshal(actions)
+	if err != nil {
+		return sqlite.RuleRecord{}, err
+	}
+	var webhookJSON []byte
+	if r.Webhook != nil {
+		if webhookJSON, err = json.Marshal(r.Webhook); err != nil {
+			return sqlite.RuleRecord{}, err
+		}
+	}
+
+	return sqlite.RuleRecord{
+		UserID:     r.UserID,
+		ID:         r.ID,
+		Name:       r.Name,
+		Enabled:    r.Enabled,
+		Position:   r.Position,
+		Query:      r.Query,
+		Conditions: string(conditionsJSON),
+		Actions:    string(actionsJSON),
+		Webhook:    string(webhookJSON),
+		Schedule:   r.Schedule,
+	}, nil
+}
+
+// ruleFromRecord converts a stored rule
+func ruleFromRecord(record sqlite.RuleRecord) (Rule, error) {
+	rule := Rule{
+		ID:       record.ID,
+		Name:     record.Name,
+		Enabled:  record.Enabled,
+		Position: record.Position,
+		Query:    record.Query,
+		Schedule: record.Schedule,
+		UserID:   record.UserID,
+	}
+	if err := json.Unmarshal([]byte(record.Conditions), &rule.Conditions); err != nil {
+		return Rule{}, err
+	}
+	if err := json.Unmarshal([]byte(record.Actions), &rule.Actions); err != nil {
+		return Rule{}, err
+	}
+	if record.Webhook != "" {
+		rule.Webhook = &WebhookConfig{}
+		if err := json.Unmarshal([]byte(record.Webhook), rule.Webhook); err != nil {
+			return Rule{}, err
+		}
+	}
+	return rule, nil
+}
@@ -0,0 +1,244 @@
+package rules
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+	"MavenRSS/internal/utils/httputil"
+)
+
+// ActionWebhook is the rule action that posts matching articles to a webhook
+const ActionWebhook = "webhook"
+
+const (
+	defaultWebhookRetries = 3
+	maxWebhookRetries     = 10
+	webhookTimeout        = 15 * time.Second
+	// Response bodies are truncated to this size in the delivery log
+	maxWebhookResponseLog = 1024
+)
+
+// Backoff between delivery attempts, variables so tests can shorten them
+var (
+	webhookInitialBackoff = 2 * time.Second
+	webhookMaxBackoff     = 5 * time.Minute
+)
+
+// WebhookConfig configures the webhook action of a rule
+type WebhookConfig struct {
+	URL         string            `json:"url"`
+	Method      string            `json:"method,omitempty"`       // HTTP method (default: POST)
+	Headers     map[string]string `json:"headers,omitempty"`      // Extra request headers
+	Template    string            `json:"template,omitempty"`     // Go text/template for the body (default: JSON payload)
+	ContentType string            `json:"content_type,omitempty"` // Body content type (default: application/json)
+	Secret      string            `json:"secret,omitempty"`       // Signs the body with HMAC-SHA256 in X-MavenRSS-Signature
+	MaxRetries  int               `json:"max_retries,omitempty"`  // Retries after the first attempt (default: 3, max: 10)
+}
+
+// WebhookPayload is the data sent for a matching article.
+// It is the default JSON body and the data available to body templates.
+type WebhookPayload struct {
+	Event        string    `json:"event"`
+	RuleID       int64     `json:"rule_id"`
+	RuleName     string    `json:"rule_name"`
+	ArticleID    int64     `json:"article_id"`
+	Title        string    `json:"title"`
+	URL          string    `json:"url"`
+	Author       string    `json:"author,omitempty"`
+	Summary      string    `json:"summary,omitempty"`
+	ImageURL     string    `json:"image_url,omitempty"`
+	PublishedAt  time.Time `json:"published_at"`
+	FeedID       int64     `json:"feed_id"`
+	FeedTitle    string    `json:"feed_title"`
+	FeedCategory string    `json:"feed_category,omitempty"`
+	Tags         []string  `json:"tags"`
+}
+
+// webhookTemplateFuncs are the functions available in body templates
+var webhookTemplateFuncs = template.FuncMap{
+	// json encodes a value, so it can be embedded safely in JSON templates
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// ValidateWebhookConfig checks that a webhook configuration can be used
+func ValidateWebhookConfig(cfg *WebhookConfig) error {
+	if cfg == nil || cfg.URL == "" {
+		return fmt.Errorf("webhook URL is required")
+	}
+	if !strings.HasPrefix(cfg.URL, "http://") && !strings.HasPrefix(cfg.URL, "https://") {
+		return fmt.Errorf("webhook URL must use http or https")
+	}
+	if cfg.Template != "" {
+		if _, err := template.New("webhook").Funcs(webhookTemplateFuncs).Parse(cfg.Template); err != nil {
+			return fmt.Errorf("invalid webhook template: %w", err)
+		}
+	}
+	return nil
+}
+
+// renderWebhookBody renders the request body for a payload
+func renderWebhookBody(cfg *WebhookConfig, payload WebhookPayload) ([]byte, error) {
+	if cfg.Template == "" {
+		return json.Marshal(payload)
+	}
+
+	tmpl, err := template.New("webhook").Funcs(webhookTemplateFuncs).Parse(cfg.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("failed to render webhook template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// signWebhookBody returns the X-MavenRSS-Signature value for a body
+func signWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// queueWebhook renders the payload for an article, records it in the delivery
+// log of the user and delivers it in the background.
+func (e *Engine) queueWebhook(userID int64, rule Rule, article models.Article, feedTitle, feedCategory string, tags []string) error {
+	if err := ValidateWebhookConfig(rule.Webhook); err != nil {
+		return err
+	}
+
+	if feedTitle == "" {
+		feedTitle = article.FeedTitle
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	payload := WebhookPayload{
+		Event:        "rule.matched",
+		RuleID:       rule.ID,
+		RuleName:     rule.Name,
+		ArticleID:    article.ID,
+		Title:        article.Title,
+		URL:          article.URL,
+		Author:       article.Author,
+		Summary:      article.Summary,
+		ImageURL:     article.ImageURL,
+		PublishedAt:  article.PublishedAt,
+		FeedID:       article.FeedID,
+		FeedTitle:    feedTitle,
+		FeedCategory: feedCategory,
+		Tags:         tags,
+	}
+
+	body, err := renderWebhookBody(rule.Webhook, payload)
+	if err != nil {
+		return err
+	}
+
+	if userID == 0 {
+		userID = defaultUserID
+	}
+	deliveryID, err := e.db.CreateWebhookDelivery(&sqlite.WebhookDelivery{
+		UserID:    userID,
+		RuleID:    rule.ID,
+		RuleName:  rule.Name,
+		ArticleID: article.ID,
+		URL:       rule.Webhook.URL,
+		Payload:   string(body),
+	})
+	if err != nil {
+		return err
+	}
+
+	go e.deliverWebhook(deliveryID, *rule.Webhook, body)
+	return nil
+}
+
+// deliverWebhook sends a webhook, retrying with exponential backoff, and
+// records every attempt in the delivery log.
+func (e *Engine) deliverWebhook(deliveryID int64, cfg WebhookConfig, body []byte) {
+	retries := cfg.MaxRetries
+	if retries <= 0 {
+		retries = defaultWebhookRetries
+	}
+	if retries > maxWebhookRetries {
+		retries = maxWebhookRetries
+	}
+
+	client := httputil.GetPooledHTTPClient("", webhookTimeout)
+	for attempt := 1; attempt <= retries+1; attempt++ {
+		code, respBody, err := sendWebhook(client, deliveryID, cfg, body)
+		if err == nil {
+			e.db.UpdateWebhookDelivery(deliveryID, sqlite.WebhookStatusDelivered, attempt, code, respBody, "")
+			return
+		}
+
+		retryable := code == 0 || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+		if !retryable || attempt > retries {
+			log.Printf("[Webhook] Delivery %d to %s failed after %d attempts: %v", deliveryID, cfg.URL, attempt, err)
+			e.db.UpdateWebhookDelivery(deliveryID, sqlite.WebhookStatusFailed, attempt, code, respBody, err.Error())
+			return
+		}
+
+		e.db.UpdateWebhookDelivery(deliveryID, sqlite.WebhookStatusPending, attempt, code, respBody, err.Error())
+		time.Sleep(httputil.CalculateBackoff(attempt-1, webhookInitialBackoff, webhookMaxBackoff))
+	}
+}
+
+// sendWebhook performs a single delivery attempt.
+// It returns the response status code (0 if no response was received) and the truncated response body.
+func sendWebhook(client *http.Client, deliveryID int64, cfg WebhookConfig, body []byte) (int, string, error) {
+	method := strings.ToUpper(cfg.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequest(method, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+
+	contentType := cfg.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "MavenRSS-Webhook/1.0")
+	req.Header.Set("X-MavenRSS-Delivery", strconv.FormatInt(deliveryID, 10))
+	for key, value := range cfg.Headers {
+		req.Header.Set(key, value)
+	}
+	if cfg.Secret != "" {
+		req.Header.Set("X-MavenRSS-Signature", signWebhookBody(cfg.Secret, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseLog))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(respBody), fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(respBody), nil
+}
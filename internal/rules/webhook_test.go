@@ -0,0 +1,126 @@
+package rules
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/store/sqlite"
+)
+
+func TestRenderWebhookBody(t *testing.T) {
+	payload := WebhookPayload{Title: `Say "hi"`, URL: "https://example.com/1", FeedTitle: "Blog", Tags: []string{"go", "rust"}}
+
+	body, err := renderWebhookBody(&WebhookConfig{}, payload)
+	if err != nil {
+		t.Fatalf("default body: %v", err)
+	}
+	var decoded WebhookPayload
+	if err := json.Unmarshal(body, &decoded); err != nil || decoded.Title != payload.Title {
+		t.Fatalf("expected JSON payload, got %s (%v)", body, err)
+	}
+
+	cfg := &WebhookConfig{Template: `{"text": {{json (printf "%s: %s" .FeedTitle .Title)}}, "tags": "{{join .Tags ","}}"}`}
+	body, err = renderWebhookBody(cfg, payload)
+	if err != nil {
+		t.Fatalf("template body: %v", err)
+	}
+	want := `{"text": "Blog: Say \"hi\"", "tags": "go,rust"}`
+	if string(body) != want {
+		t.Errorf("got %s, want %s", body, want)
+	}
+
+	if err := ValidateWebhookConfig(&WebhookConfig{URL: "https://example.com", Template: "{{.Title"}); err == nil {
+		t.Error("expected invalid template to be rejected")
+	}
+	if err := ValidateWebhookConfig(&WebhookConfig{URL: "ftp://example.com"}); err == nil {
+		t.Error("expected non-HTTP URL to be rejected")
+	}
+}
+
+func TestEngine_WebhookAction(t *testing.T) {
+	webhookInitialBackoff = 10 * time.Millisecond
+	defer func() { webhookInitialBackoff = 2 * time.Second }()
+
+	var calls int32
+	received := make(chan *http.Request, 1)
+	var receivedBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first attempt to exercise retries
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		receivedBody, _ = io.ReadAll(r.Body)
+		received <- r
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	engine := setupTestEngine(t)
+	rule := Rule{
+		ID:         7,
+		Name:       "Ship it",
+		Enabled:    true,
+		Conditions: []Condition{{Field: "article_title", Operator: "contains", Value: "release"}},
+		Actions:    []string{ActionWebhook},
+		Webhook: &WebhookConfig{
+			URL:     srv.URL,
+			Headers: map[string]string{"X-Team": "infra"},
+			Secret:  "s3cret",
+		},
+	}
+	if err := engine.SaveRules(1, []Rule{rule}); err != nil {
+		t.Fatalf("SaveRules: %v", err)
+	}
+
+	articles := []models.Article{
+		{ID: 1, Title: "New release 1.0", URL: "https://example.com/release"},
+		{ID: 2, Title: "Unrelated"},
+	}
+	if count, err := engine.ApplyRulesToArticles(articles); err != nil || count != 1 {
+		t.Fatalf("ApplyRulesToArticles = %d, %v", count, err)
+	}
+
+	var req *http.Request
+	select {
+	case req = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	if req.Header.Get("X-Team") != "infra" {
+		t.Errorf("missing custom header")
+	}
+	if req.Header.Get("X-MavenRSS-Signature") != signWebhookBody("s3cret", receivedBody) {
+		t.Errorf("unexpected signature %q", req.Header.Get("X-MavenRSS-Signature"))
+	}
+
+	// The delivery log records the retry and the final outcome
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		deliveries, err := engine.db.GetWebhookDeliveries(1, 7, 10, 0)
+		if err != nil {
+			t.Fatalf("GetWebhookDeliveries: %v", err)
+		}
+		if len(deliveries) == 1 && deliveries[0].Status == sqlite.WebhookStatusDelivered {
+			if deliveries[0].Attempts != 2 || deliveries[0].ArticleID != 1 || deliveries[0].ResponseBody != "ok" || deliveries[0].UserID != 1 {
+				t.Errorf("unexpected delivery %+v", deliveries[0])
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivery was not logged as delivered: %+v", deliveries)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Other users don't see the deliveries of the rule with the same ID
+	if deliveries, err := engine.db.GetWebhookDeliveries(2, 7, 10, 0); err != nil || len(deliveries) != 0 {
+		t.Errorf("expected no deliveries for another user, got %+v (%v)", deliveries, err)
+	}
+}
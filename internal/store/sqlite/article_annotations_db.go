@@ -0,0 +1,314 @@
+package sqlite
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/models"
+)
+
+// DefaultHighlightColor is used for highlights created without a color
+const DefaultHighlightColor = "#FACC15"
+
+// InitArticleAnnotationTables creates the article_tags and article_highlights tables
+// if they don't exist. Triggers remove annotations with their article or tag, as
+// foreign keys are not enforced on every connection.
+func InitArticleAnnotationTables(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS article_tags (
+		user_id INTEGER NOT NULL,
+		article_id INTEGER NOT NULL,
+		tag_id INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (article_id, tag_id),
+		FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE,
+		FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_article_tags_user_tag ON article_tags(user_id, tag_id);
+
+	CREATE TABLE IF NOT EXISTS article_highlights (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		article_id INTEGER NOT NULL,
+		text TEXT NOT NULL,
+		start_offset INTEGER NOT NULL DEFAULT 0,
+		end_offset INTEGER NOT NULL DEFAULT 0,
+		color TEXT NOT NULL DEFAULT '` + DefaultHighlightColor + `',
+		note TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_article_highlights_article ON article_highlights(article_id, start_offset);
+	CREATE INDEX IF NOT EXISTS idx_article_highlights_user ON article_highlights(user_id, created_at DESC);
+
+	CREATE TRIGGER IF NOT EXISTS article_annotations_article_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM article_tags WHERE article_id = old.id;
+		DELETE FROM article_highlights WHERE article_id = old.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS article_tags_tag_delete AFTER DELETE ON tags BEGIN
+		DELETE FROM article_tags WHERE tag_id = old.id;
+	END;
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// articleBelongsToUser returns ErrArticleNotFound if the article doesn't belong to the user.
+func (db *DB) articleBelongsToUser(userID, articleID int64) error {
+	var exists int
+	err := db.QueryRow(`SELECT 1 FROM articles WHERE id = ? AND user_id = ?`, articleID, userID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return ErrArticleNotFound
+	}
+	return err
+}
+
+// GetArticleTagsForUser retrieves the tags of an article, ordered by tag position.
+func (db *DB) GetArticleTagsForUser(userID, articleID int64) ([]models.Tag, error) {
+	db.WaitForReady()
+
+	rows, err := db.Query(`
+		SELECT t.id, t.name, t.color, t.position
+		FROM tags t
+		JOIN article_tags at ON at.tag_id = t.id
+		WHERE at.article_id = ? AND at.user_id = ?
+		ORDER BY t.position ASC, t.id ASC`, articleID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Color, &tag.Position); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// SetArticleTagsForUser replaces the tags of an article. Unknown tag IDs are ignored.
+// Returns ErrArticleNotFound if the article doesn't belong to the user.
+func (db *DB) SetArticleTagsForUser(userID, articleID int64, tagIDs []int64) error {
+	db.WaitForReady()
+
+	if err := db.articleBelongsToUser(userID, articleID); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM article_tags WHERE article_id = ? AND user_id = ?`, articleID, userID); err != nil {
+		return err
+	}
+	for _, tagID := range tagIDs {
+		_, err := tx.Exec(`INSERT OR IGNORE INTO article_tags (user_id, article_id, tag_id)
+			SELECT ?, ?, id FROM tags WHERE id = ?`, userID, articleID, tagID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetArticleTagNames returns the tag names of the given articles, keyed by article ID.
+func (db *DB) GetArticleTagNames(articleIDs []int64) (map[int64][]string, error) {
+	db.WaitForReady()
+
+	names := make(map[int64][]string)
+	err := queryByArticleIDs(db, articleIDs, `
+		SELECT at.article_id, t.name
+		FROM article_tags at
+		JOIN tags t ON t.id = at.tag_id
+		WHERE at.article_id IN (%s)
+		ORDER BY t.position ASC`, func(rows *sql.Rows) error {
+		var articleID int64
+		var name string
+		if err := rows.Scan(&articleID, &name); err != nil {
+			return err
+		}
+		names[articleID] = append(names[articleID], name)
+		return nil
+	})
+	return names, err
+}
+
+// GetArticleIDsWithHighlights returns which of the given articles have highlights.
+func (db *DB) GetArticleIDsWithHighlights(articleIDs []int64) (map[int64]bool, error) {
+	db.WaitForReady()
+
+	highlighted := make(map[int64]bool)
+	err := queryByArticleIDs(db, articleIDs, `
+		SELECT DISTINCT article_id FROM article_highlights WHERE article_id IN (%s)`, func(rows *sql.Rows) error {
+		var articleID int64
+		if err := rows.Scan(&articleID); err != nil {
+			return err
+		}
+		highlighted[articleID] = true
+		return nil
+	})
+	return highlighted, err
+}
+
+// queryByArticleIDs runs a query with an "IN (%s)" placeholder for batches of article IDs
+func queryByArticleIDs(db *DB, articleIDs []int64, query string, scan func(*sql.Rows) error) error {
+	const batchSize = 500
+	for i := 0; i < len(articleIDs); i += batchSize {
+		end := i + batchSize
+		if end > len(articleIDs) {
+			end = len(articleIDs)
+		}
+		batch := articleIDs[i:end]
+
+		placeholders := make([]string, len(batch))
+		args := make([]interface{}, len(batch))
+		for j, id := range batch {
+			placeholders[j] = "?"
+			args[j] = id
+		}
+
+		rows, err := db.Query(strings.Replace(query, "%s", strings.Join(placeholders, ","), 1), args...)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			if err := scan(rows); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateHighlight adds a highlight to an article and returns its ID.
+// Returns ErrArticleNotFound if the article doesn't belong to the user.
+func (db *DB) CreateHighlight(hl *models.ArticleHighlight) (int64, error) {
+	db.WaitForReady()
+
+	if err := db.articleBelongsToUser(hl.UserID, hl.ArticleID); err != nil {
+		return 0, err
+	}
+	if hl.Color == "" {
+		hl.Color = DefaultHighlightColor
+	}
+
+	now := time.Now()
+	result, err := db.Exec(`
+		INSERT INTO article_highlights (user_id, article_id, text, start_offset, end_offset, color, note, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		hl.UserID, hl.ArticleID, hl.Text, hl.StartOffset, hl.EndOffset, hl.Color, hl.Note, now, now)
+	if err != nil {
+		return 0, err
+	}
+	hl.CreatedAt = now
+	hl.UpdatedAt = now
+	return result.LastInsertId()
+}
+
+const highlightColumns = `h.id, h.user_id, h.article_id, h.text, h.start_offset, h.end_offset, h.color, h.note,
+	h.created_at, h.updated_at, COALESCE(a.title, ''), COALESCE(a.url, '')`
+
+func scanHighlights(rows *sql.Rows) ([]models.ArticleHighlight, error) {
+	defer rows.Close()
+
+	highlights := []models.ArticleHighlight{}
+	for rows.Next() {
+		var hl models.ArticleHighlight
+		var createdAt, updatedAt sql.NullTime
+		if err := rows.Scan(&hl.ID, &hl.UserID, &hl.ArticleID, &hl.Text, &hl.StartOffset, &hl.EndOffset, &hl.Color, &hl.Note,
+			&createdAt, &updatedAt, &hl.ArticleTitle, &hl.ArticleURL); err != nil {
+			return nil, err
+		}
+		hl.CreatedAt = createdAt.Time
+		hl.UpdatedAt = updatedAt.Time
+		highlights = append(highlights, hl)
+	}
+	return highlights, rows.Err()
+}
+
+// GetArticleHighlights retrieves the highlights of an article in reading order.
+// A userID of 0 returns the highlights of every user.
+func (db *DB) GetArticleHighlights(userID, articleID int64) ([]models.ArticleHighlight, error) {
+	db.WaitForReady()
+
+	query := `SELECT ` + highlightColumns + `
+		FROM article_highlights h
+		LEFT JOIN articles a ON a.id = h.article_id
+		WHERE h.article_id = ?`
+	args := []interface{}{articleID}
+	if userID > 0 {
+		query += " AND h.user_id = ?"
+		args = append(args, userID)
+	}
+	rows, err := db.Query(query+" ORDER BY h.start_offset ASC, h.id ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanHighlights(rows)
+}
+
+// GetHighlightsForUser retrieves a user's highlights across all articles, newest first.
+func (db *DB) GetHighlightsForUser(userID int64, limit, offset int) ([]models.ArticleHighlight, error) {
+	db.WaitForReady()
+
+	rows, err := db.Query(`SELECT `+highlightColumns+`
+		FROM article_highlights h
+		LEFT JOIN articles a ON a.id = h.article_id
+		WHERE h.user_id = ?
+		ORDER BY h.created_at DESC, h.id DESC
+		LIMIT ? OFFSET ?`, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanHighlights(rows)
+}
+
+// UpdateHighlight changes the color and note of a highlight.
+// Returns sql.ErrNoRows if the user has no such highlight.
+func (db *DB) UpdateHighlight(userID, id int64, color, note string) error {
+	db.WaitForReady()
+
+	if color == "" {
+		color = DefaultHighlightColor
+	}
+	result, err := db.Exec(`UPDATE article_highlights SET color = ?, note = ?, updated_at = ? WHERE id = ? AND user_id = ?`,
+		color, note, time.Now(), id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteHighlight removes a highlight.
+// Returns sql.ErrNoRows if the user has no such highlight.
+func (db *DB) DeleteHighlight(userID, id int64) error {
+	db.WaitForReady()
+
+	result, err := db.Exec(`DELETE FROM article_highlights WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
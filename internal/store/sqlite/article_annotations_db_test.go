@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/models"
+)
+
+func TestArticleTagsAndHighlights(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "F", URL: "http://example.com/feed"})
+	if err != nil {
+		t.Fatalf("AddFeed: %v", err)
+	}
+	if err := db.SaveArticles(context.Background(), []*models.Article{
+		{FeedID: feedID, Title: "tagged", URL: "http://example.com/1", PublishedAt: time.Now()},
+		{FeedID: feedID, Title: "plain", URL: "http://example.com/2", PublishedAt: time.Now().Add(-time.Hour)},
+	}); err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+	articles, err := db.GetArticles("", feedID, "", false, 10, 0)
+	if err != nil || len(articles) != 2 {
+		t.Fatalf("expected 2 articles, got %d (%v)", len(articles), err)
+	}
+	articleID, otherID := articles[0].ID, articles[1].ID
+
+	result, err := db.Exec(`INSERT INTO tags (user_id, name, color, position) VALUES (1, 'Research', '#000000', 1)`)
+	if err != nil {
+		t.Fatalf("insert tag: %v", err)
+	}
+	tagID, _ := result.LastInsertId()
+
+	// Unknown tag IDs are ignored
+	if err := db.SetArticleTagsForUser(1, articleID, []int64{tagID, 9999}); err != nil {
+		t.Fatalf("SetArticleTagsForUser: %v", err)
+	}
+	tags, err := db.GetArticleTagsForUser(1, articleID)
+	if err != nil || len(tags) != 1 || tags[0].Name != "Research" {
+		t.Fatalf("unexpected tags: %+v (%v)", tags, err)
+	}
+	if err := db.SetArticleTagsForUser(2, articleID, []int64{tagID}); !errors.Is(err, ErrArticleNotFound) {
+		t.Fatalf("expected ErrArticleNotFound for another user's article, got %v", err)
+	}
+	names, err := db.GetArticleTagNames([]int64{articleID, otherID})
+	if err != nil || len(names) != 1 || names[articleID][0] != "Research" {
+		t.Fatalf("unexpected tag names: %+v (%v)", names, err)
+	}
+
+	hl := &models.ArticleHighlight{UserID: 1, ArticleID: articleID, Text: "key point", StartOffset: 10, EndOffset: 19}
+	id, err := db.CreateHighlight(hl)
+	if err != nil {
+		t.Fatalf("CreateHighlight: %v", err)
+	}
+	if hl.Color != DefaultHighlightColor {
+		t.Errorf("expected default color, got %q", hl.Color)
+	}
+	if err := db.UpdateHighlight(1, id, "#22C55E", "follow up"); err != nil {
+		t.Fatalf("UpdateHighlight: %v", err)
+	}
+	if err := db.UpdateHighlight(2, id, "", "not mine"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows updating another user's highlight, got %v", err)
+	}
+
+	highlights, err := db.GetHighlightsForUser(1, 10, 0)
+	if err != nil || len(highlights) != 1 {
+		t.Fatalf("expected 1 highlight, got %d (%v)", len(highlights), err)
+	}
+	if highlights[0].Note != "follow up" || highlights[0].Color != "#22C55E" || highlights[0].ArticleTitle != "tagged" {
+		t.Errorf("unexpected highlight: %+v", highlights[0])
+	}
+	highlighted, err := db.GetArticleIDsWithHighlights([]int64{articleID, otherID})
+	if err != nil || !highlighted[articleID] || highlighted[otherID] {
+		t.Fatalf("unexpected highlighted articles: %+v (%v)", highlighted, err)
+	}
+
+	// Annotations are removed with their tag and article
+	if err := db.DeleteTag(tagID); err != nil {
+		t.Fatalf("DeleteTag: %v", err)
+	}
+	if tags, _ := db.GetArticleTagsForUser(1, articleID); len(tags) != 0 {
+		t.Errorf("expected article tags to be removed with the tag, got %+v", tags)
+	}
+	if _, err := db.Exec(`DELETE FROM articles WHERE id = ?`, articleID); err != nil {
+		t.Fatalf("delete article: %v", err)
+	}
+	if highlights, _ := db.GetArticleHighlights(0, articleID); len(highlights) != 0 {
+		t.Errorf("expected highlights to be removed with the article, got %+v", highlights)
+	}
+	if err := db.DeleteHighlight(1, id); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows deleting a removed highlight, got %v", err)
+	}
+}
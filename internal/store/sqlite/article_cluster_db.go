@@ -0,0 +1,205 @@
+package sqlite
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/utils/textutil"
+)
+
+const (
+	// MaxClusterDistance is the largest SimHash Hamming distance between two
+	// articles considered near-duplicates
+	MaxClusterDistance = 6
+	// fingerprintBandCount is the number of 8-bit bands a fingerprint is split into.
+	// Two fingerprints within MaxClusterDistance (< fingerprintBandCount) bits always
+	// share at least one band, so candidates are found with indexed equality lookups.
+	fingerprintBandCount = 8
+	// clusterWindow limits candidates to articles published around the same time
+	clusterWindow = 72 * time.Hour
+)
+
+// InitArticleClusterTable creates the article_fingerprints and article_fingerprint_bands
+// tables if they don't exist.
+func InitArticleClusterTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS article_fingerprints (
+		article_id INTEGER PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		simhash INTEGER NOT NULL,
+		cluster_id INTEGER NOT NULL,
+		published_at DATETIME,
+		FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_article_fingerprints_cluster ON article_fingerprints(cluster_id);
+
+	CREATE TABLE IF NOT EXISTS article_fingerprint_bands (
+		user_id INTEGER NOT NULL,
+		band_key INTEGER NOT NULL,
+		article_id INTEGER NOT NULL,
+		PRIMARY KEY (user_id, band_key, article_id)
+	) WITHOUT ROWID;
+	CREATE INDEX IF NOT EXISTS idx_article_fingerprint_bands_article ON article_fingerprint_bands(article_id);
+
+	CREATE TRIGGER IF NOT EXISTS article_fingerprints_article_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM article_fingerprint_bands WHERE article_id = old.id;
+		DELETE FROM article_fingerprints WHERE article_id = old.id;
+	END;
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// fingerprintBandKeys splits a fingerprint into its bands, each keyed by band index and value
+func fingerprintBandKeys(hash uint64) []interface{} {
+	keys := make([]interface{}, fingerprintBandCount)
+	for i := range keys {
+		keys[i] = int64(i)<<8 | int64((hash>>(8*uint(i)))&0xFF)
+	}
+	return keys
+}
+
+// AssignArticleCluster stores the fingerprint of an article and assigns it to the
+// cluster of its closest near-duplicate of another feed published within clusterWindow.
+// Similar articles of a feed (e.g. templated posts) aren't duplicates. An article
+// without near-duplicates starts its own cluster, identified by its article ID.
+// Returns the cluster ID.
+func (db *DB) AssignArticleCluster(userID, articleID int64, fingerprint uint64, publishedAt time.Time) (int64, error) {
+	db.WaitForReady()
+
+	publishedAt = publishedAt.UTC()
+	bandKeys := fingerprintBandKeys(fingerprint)
+	args := append([]interface{}{userID}, bandKeys...)
+	args = append(args, articleID, publishedAt.Add(-clusterWindow), publishedAt.Add(clusterWindow), articleID)
+	rows, err := db.Query(`
+		SELECT DISTINCT fp.simhash, fp.cluster_id
+		FROM article_fingerprint_bands b
+		JOIN article_fingerprints fp ON fp.article_id = b.article_id
+		JOIN articles a ON a.id = fp.article_id
+		WHERE b.user_id = ? AND b.band_key IN (?`+strings.Repeat(", ?", fingerprintBandCount-1)+`)
+		AND fp.article_id != ? AND fp.published_at BETWEEN ? AND ?
+		AND a.feed_id != (SELECT feed_id FROM articles WHERE id = ?)`, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	clusterID := articleID
+	bestDistance := MaxClusterDistance + 1
+	for rows.Next() {
+		var simhash, candidateCluster int64
+		if err := rows.Scan(&simhash, &candidateCluster); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		distance := textutil.HammingDistance(uint64(simhash), fingerprint)
+		if distance < bestDistance || (distance == bestDistance && candidateCluster < clusterID) {
+			bestDistance = distance
+			clusterID = candidateCluster
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO article_fingerprints (article_id, user_id, simhash, cluster_id, published_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(article_id) DO UPDATE SET
+			simhash = excluded.simhash, cluster_id = excluded.cluster_id, published_at = excluded.published_at`,
+		articleID, userID, int64(fingerprint), clusterID, publishedAt)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`DELETE FROM article_fingerprint_bands WHERE article_id = ?`, articleID); err != nil {
+		return 0, err
+	}
+	for _, key := range bandKeys {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO article_fingerprint_bands (user_id, band_key, article_id) VALUES (?, ?, ?)`,
+			userID, key, articleID); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return clusterID, nil
+}
+
+// GetArticleClusterIDs returns the cluster IDs of the given articles, keyed by
+// article ID. Articles that were never fingerprinted are absent from the map.
+func (db *DB) GetArticleClusterIDs(articleIDs []int64) (map[int64]int64, error) {
+	db.WaitForReady()
+
+	clusters := make(map[int64]int64)
+	err := queryByArticleIDs(db, articleIDs, `
+		SELECT article_id, cluster_id FROM article_fingerprints WHERE article_id IN (%s)`, func(rows *sql.Rows) error {
+		var articleID, clusterID int64
+		if err := rows.Scan(&articleID, &clusterID); err != nil {
+			return err
+		}
+		clusters[articleID] = clusterID
+		return nil
+	})
+	return clusters, err
+}
+
+// GetClusterArticlesForUser retrieves the near-duplicates of an article, oldest first.
+// The article itself is not included.
+func (db *DB) GetClusterArticlesForUser(userID, articleID int64) ([]models.Article, error) {
+	db.WaitForReady()
+
+	query := `
+		SELECT a.id, a.feed_id, a.title, a.url, COALESCE(a.image_url, ''), a.published_at, a.is_read, a.is_favorite,
+			COALESCE(f.title, ''), COALESCE(a.author, '')
+		FROM article_fingerprints af
+		JOIN article_fingerprints self ON self.cluster_id = af.cluster_id AND self.article_id = ?
+		JOIN articles a ON a.id = af.article_id
+		LEFT JOIN feeds f ON f.id = a.feed_id
+		WHERE af.article_id != ?`
+	args := []interface{}{articleID, articleID}
+	if userID > 0 {
+		query += " AND a.user_id = ?"
+		args = append(args, userID)
+	}
+	rows, err := db.Query(query+" ORDER BY a.published_at ASC, a.id ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	articles := []models.Article{}
+	for rows.Next() {
+		var a models.Article
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &a.ImageURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.FeedTitle, &a.Author); err != nil {
+			return nil, err
+		}
+		a.PublishedAt = publishedAt.Time
+		articles = append(articles, a)
+	}
+	return articles, rows.Err()
+}
+
+// markClusterRead marks the other members of an article's cluster as read, so a
+// story syndicated by several feeds only needs to be read once.
+func (db *DB) markClusterRead(articleID int64) error {
+	_, err := db.Exec(`
+		UPDATE articles SET is_read = 1, is_read_later = 0
+		WHERE is_read = 0 AND id IN (
+			SELECT af.article_id FROM article_fingerprints af
+			JOIN article_fingerprints self ON self.cluster_id = af.cluster_id
+			WHERE self.article_id = ? AND af.article_id != ?
+		)`, articleID, articleID)
+	return err
+}
@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/utils/textutil"
+)
+
+func TestArticleClusters(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+
+	const story = "The central bank raised its benchmark interest rate by a quarter point on Wednesday, the third increase this year, citing persistent inflation in housing and services."
+	now := time.Now().Truncate(time.Second)
+	texts := []struct{ feed, title, content string }{
+		{"Wire", "Central bank raises interest rates again", story},
+		{"Daily", "Central Bank Raises Interest Rates Again", story + " Reporting by Jane Doe."},
+		{"Tech", "New telescope captures distant galaxy", "Astronomers released the first images from the new space telescope, showing a galaxy whose light left it more than thirteen billion years ago."},
+	}
+
+	ids := make([]int64, len(texts))
+	for i, text := range texts {
+		feedID, err := db.AddFeed(&models.Feed{Title: text.feed, URL: "http://example.com/" + text.feed})
+		if err != nil {
+			t.Fatalf("AddFeed: %v", err)
+		}
+		published := now.Add(time.Duration(i) * time.Hour)
+		if err := db.SaveArticles(context.Background(), []*models.Article{{FeedID: feedID, Title: text.title, URL: "http://example.com/" + text.feed + "/1", PublishedAt: published}}); err != nil {
+			t.Fatalf("SaveArticles: %v", err)
+		}
+		saved, err := db.GetArticles("", feedID, "", false, 1, 0)
+		if err != nil || len(saved) != 1 {
+			t.Fatalf("GetArticles: %v", err)
+		}
+		ids[i] = saved[0].ID
+
+		fingerprint, _ := textutil.Fingerprint(text.title, text.content)
+		clusterID, err := db.AssignArticleCluster(1, ids[i], fingerprint, published)
+		if err != nil {
+			t.Fatalf("AssignArticleCluster: %v", err)
+		}
+		if want := map[int]int64{0: ids[0], 1: ids[0], 2: ids[2]}[i]; clusterID != want {
+			t.Errorf("article %d: expected cluster %d, got %d", i, want, clusterID)
+		}
+	}
+
+	articles, err := db.GetArticlesForUser(1, "", 0, "", false, true, 10, 0)
+	if err != nil {
+		t.Fatalf("GetArticlesForUser: %v", err)
+	}
+	if len(articles) != 2 || articles[0].ID != ids[2] || articles[1].ID != ids[0] || articles[1].DuplicateCount != 1 {
+		t.Fatalf("unexpected collapsed articles: %+v", articles)
+	}
+
+	duplicates, err := db.GetClusterArticlesForUser(1, ids[0])
+	if err != nil || len(duplicates) != 1 || duplicates[0].ID != ids[1] || duplicates[0].FeedTitle != "Daily" {
+		t.Fatalf("unexpected duplicates: %+v (%v)", duplicates, err)
+	}
+
+	// Reading one member reads the whole cluster
+	if err := db.MarkArticleReadForUser(1, ids[1], true); err != nil {
+		t.Fatalf("MarkArticleReadForUser: %v", err)
+	}
+	for i, id := range ids {
+		article, _ := db.GetArticleByID(id)
+		if want := i < 2; article.IsRead != want {
+			t.Errorf("article %d: expected read=%v", i, want)
+		}
+	}
+}
+
+func TestArticleClustersSameFeed(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	feedID, err := db.AddFeed(&models.Feed{Title: "Status", URL: "http://example.com/status"})
+	if err != nil {
+		t.Fatalf("AddFeed: %v", err)
+	}
+
+	// Templated posts of a feed look alike but aren't duplicates
+	const template = "Scheduled maintenance of the storage cluster will take place tonight between midnight and two in the morning. Some requests may fail during this window."
+	now := time.Now().Truncate(time.Second)
+	for i := 0; i < 2; i++ {
+		title := fmt.Sprintf("Scheduled maintenance of the storage cluster #%d", i+1)
+		published := now.Add(time.Duration(i) * time.Hour)
+		if err := db.SaveArticles(context.Background(), []*models.Article{{FeedID: feedID, Title: title, URL: fmt.Sprintf("http://example.com/status/%d", i), PublishedAt: published}}); err != nil {
+			t.Fatalf("SaveArticles: %v", err)
+		}
+		saved, err := db.GetArticles("", feedID, "", false, 1, 0)
+		if err != nil || len(saved) != 1 || saved[0].Title != title {
+			t.Fatalf("GetArticles: %+v (%v)", saved, err)
+		}
+		fingerprint, _ := textutil.Fingerprint("Scheduled maintenance of the storage cluster", template)
+		clusterID, err := db.AssignArticleCluster(1, saved[0].ID, fingerprint, published)
+		if err != nil {
+			t.Fatalf("AssignArticleCluster: %v", err)
+		}
+		if clusterID != saved[0].ID {
+			t.Errorf("article %d: expected its own cluster, got %d", i, clusterID)
+		}
+	}
+}
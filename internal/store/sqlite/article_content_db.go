@@ -28,6 +28,25 @@ func (db *DB) GetArticleContent(articleID int64) (string, bool, error) {
 	return content, true, nil
 }
 
+// GetArticleContents retrieves the cached content of the given articles, keyed by
+// article ID. Articles without cached content are absent from the map.
+func (db *DB) GetArticleContents(articleIDs []int64) (map[int64]string, error) {
+	db.WaitForReady()
+
+	contents := make(map[int64]string)
+	err := queryByArticleIDs(db, articleIDs, `
+		SELECT article_id, content FROM article_contents WHERE article_id IN (%s)`, func(rows *sql.Rows) error {
+		var articleID int64
+		var content string
+		if err := rows.Scan(&articleID, &content); err != nil {
+			return err
+		}
+		contents[articleID] = content
+		return nil
+	})
+	return contents, err
+}
+
 // SetArticleContent stores or updates content for an article
 func (db *DB) SetArticleContent(articleID int64, content string) error {
 	db.WaitForReady()
@@ -36,7 +55,44 @@ func (db *DB) SetArticleContent(articleID int64, content string) error {
 		 VALUES (?, ?, CURRENT_TIMESTAMP)`,
 		articleID, content,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	db.indexArticles(articleID)
+	return nil
+}
+
+// SetArticleFullText stores the full text fetched from an article's page as its content.
+// Full text is kept when the feed is refreshed.
+func (db *DB) SetArticleFullText(articleID int64, content string) error {
+	db.WaitForReady()
+	_, err := db.Exec(
+		`INSERT OR REPLACE INTO article_contents (article_id, content, fetched_at, is_full_text)
+		 VALUES (?, ?, CURRENT_TIMESTAMP, 1)`,
+		articleID, content,
+	)
+	if err != nil {
+		return err
+	}
+	db.indexArticles(articleID)
+	return nil
+}
+
+// GetArticleFullTextIDs returns which of the given articles have their full text stored
+func (db *DB) GetArticleFullTextIDs(articleIDs []int64) (map[int64]bool, error) {
+	db.WaitForReady()
+
+	ids := make(map[int64]bool)
+	err := queryByArticleIDs(db, articleIDs, `
+		SELECT article_id FROM article_contents WHERE is_full_text = 1 AND article_id IN (%s)`, func(rows *sql.Rows) error {
+		var articleID int64
+		if err := rows.Scan(&articleID); err != nil {
+			return err
+		}
+		ids[articleID] = true
+		return nil
+	})
+	return ids, err
 }
 
 // DeleteArticleContent removes cached content for an article
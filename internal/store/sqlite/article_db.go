@@ -65,22 +65,34 @@ func (db *DB) SaveArticle(article *models.Article) error {
 
 	// Generate unique_id for deduplication
 	query := `INSERT OR IGNORE INTO articles (user_id, feed_id, title, url, image_url, audio_url, video_url, published_at, translated_title, is_read, is_favorite, is_hidden, is_read_later, summary, unique_id, author) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	_, err = db.Exec(query, article.UserID, article.FeedID, article.Title, article.URL, article.ImageURL, article.AudioURL, article.VideoURL, article.PublishedAt, article.TranslatedTitle, article.IsRead, article.IsFavorite, article.IsHidden, article.IsReadLater, article.Summary, uniqueID, article.Author)
+	result, err := db.Exec(query, article.UserID, article.FeedID, article.Title, article.URL, article.ImageURL, article.AudioURL, article.VideoURL, article.PublishedAt, article.TranslatedTitle, article.IsRead, article.IsFavorite, article.IsHidden, article.IsReadLater, article.Summary, uniqueID, article.Author)
 	
 	// Set unique_id back to article's UniqueID field for subsequent operations
 	article.UniqueID = uniqueID
 	
-	return err
+	if err != nil {
+		return err
+	}
+	if id, ok := insertedID(result); ok {
+		db.indexArticles(id)
+	}
+	return nil
 }
 
 // SaveArticles saves multiple articles in a transaction.
 // Includes progressive cleanup check to prevent database from exceeding size limit during refresh.
 func (db *DB) SaveArticles(ctx context.Context, articles []*models.Article) error {
+	_, err := db.SaveArticlesWithCount(ctx, articles)
+	return err
+}
+
+// SaveArticlesWithCount saves articles like SaveArticles and returns the number of new articles.
+func (db *DB) SaveArticlesWithCount(ctx context.Context, articles []*models.Article) (int, error) {
 	db.WaitForReady()
 
 	// If no articles, return early
 	if len(articles) == 0 {
-		return nil
+		return 0, nil
 	}
 
 	// Determine user ID (all articles should be for the same user)
@@ -168,7 +180,7 @@ func (db *DB) SaveArticles(ctx context.Context, articles []*models.Article) erro
 
 		if newArticlesCount > 0 {
 			if ok, qErr := db.CheckArticleQuota(userID, newArticlesCount); !ok {
-				return qErr
+				return 0, qErr
 			}
 		}
 	} else {
@@ -222,44 +234,66 @@ func (db *DB) SaveArticles(ctx context.Context, articles []*models.Article) erro
 
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer tx.Rollback()
 
 	stmt, err := tx.PrepareContext(ctx, `INSERT OR IGNORE INTO articles (user_id, feed_id, title, url, image_url, audio_url, video_url, published_at, translated_title, is_read, is_favorite, is_hidden, is_read_later, summary, unique_id, author) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer stmt.Close()
 
+	var insertedIDs []int64
 	for i, article := range articles {
 		// Check context before each insert
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return 0, ctx.Err()
 		default:
 		}
 
 		// Use pre-generated unique_id for deduplication
 		uniqueID := uniqueIDs[i]
-		_, err := stmt.ExecContext(ctx, article.UserID, article.FeedID, article.Title, article.URL, article.ImageURL, article.AudioURL, article.VideoURL, article.PublishedAt, article.TranslatedTitle, article.IsRead, article.IsFavorite, article.IsHidden, article.IsReadLater, article.Summary, uniqueID, article.Author)
+		result, err := stmt.ExecContext(ctx, article.UserID, article.FeedID, article.Title, article.URL, article.ImageURL, article.AudioURL, article.VideoURL, article.PublishedAt, article.TranslatedTitle, article.IsRead, article.IsFavorite, article.IsHidden, article.IsReadLater, article.Summary, uniqueID, article.Author)
 		if err != nil {
 			log.Println("Error saving article in batch:", err)
 			// Continue even if one fails
+			continue
 		}
+		if id, ok := insertedID(result); ok {
+			insertedIDs = append(insertedIDs, id)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
 	}
 
-	return tx.Commit()
+	// Index new articles for full-text search
+	db.indexArticles(insertedIDs...)
+	return len(insertedIDs), nil
+}
+
+// insertedID returns the row ID of an INSERT OR IGNORE that actually inserted a row.
+func insertedID(result sql.Result) (int64, bool) {
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return 0, false
+	}
+	id, err := result.LastInsertId()
+	return id, err == nil
 }
 
 // GetArticles retrieves articles with filtering, pagination, and sorting.
 // Optimized to filter feeds first for category queries, reducing JOIN overhead.
 func (db *DB) GetArticles(filter string, feedID int64, category string, showHidden bool, limit, offset int) ([]models.Article, error) {
-	return db.GetArticlesForUser(0, filter, feedID, category, showHidden, limit, offset)
+	return db.GetArticlesForUser(0, filter, feedID, category, showHidden, false, limit, offset)
 }
 
 // GetArticlesForUser retrieves articles with filtering, pagination, and sorting for a specific user.
-func (db *DB) GetArticlesForUser(userID int64, filter string, feedID int64, category string, showHidden bool, limit, offset int) ([]models.Article, error) {
+// With collapseDuplicates, only the earliest matching article of each near-duplicate cluster
+// is returned, with DuplicateCount set to the number of other matching members.
+func (db *DB) GetArticlesForUser(userID int64, filter string, feedID int64, category string, showHidden, collapseDuplicates bool, limit, offset int) ([]models.Article, error) {
 	db.WaitForReady()
 
 	// Optimization: For category queries, first get the feed IDs, then query articles
@@ -306,10 +340,21 @@ func (db *DB) GetArticlesForUser(userID int64, filter string, feedID int64, cate
 
 	// Build the main query with optimized index usage
 	baseQuery := `
-		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, a.author
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, a.author, COALESCE(a.is_pinned, 0), a.user_id
+		FROM articles a
+		LEFT JOIN feeds f ON a.feed_id = f.id
+	`
+	if collapseDuplicates {
+		// Articles without a fingerprint form their own partition (negated ID can't collide with a cluster ID)
+		baseQuery = `
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, a.author, COALESCE(a.is_pinned, 0) AS is_pinned, a.user_id,
+			COUNT(*) OVER (PARTITION BY COALESCE(af.cluster_id, -a.id)) - 1 AS duplicate_count,
+			ROW_NUMBER() OVER (PARTITION BY COALESCE(af.cluster_id, -a.id) ORDER BY a.published_at ASC, a.id ASC) AS cluster_rank
 		FROM articles a
 		LEFT JOIN feeds f ON a.feed_id = f.id
+		LEFT JOIN article_fingerprints af ON af.article_id = a.id
 	`
+	}
 	var args []interface{}
 	whereClauses := []string{}
 
@@ -365,6 +410,41 @@ func (db *DB) GetArticlesForUser(userID int64, filter string, feedID int64, cate
 			query += " AND " + whereClauses[i]
 		}
 	}
+	if collapseDuplicates {
+		query = "SELECT * FROM (" + query + ") WHERE cluster_rank = 1 ORDER BY is_pinned DESC, published_at DESC LIMIT ? OFFSET ?"
+	} else {
+		// Pinned articles come first
+		query += " ORDER BY a.is_pinned DESC, a.published_at DESC LIMIT ? OFFSET ?"
+	}
+	args = append(args, limit, offset)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanArticles(rows, collapseDuplicates, limit), nil
+}
+
+// GetArticlesWhereForUser retrieves the articles matching a WHERE condition over
+// "articles a LEFT JOIN feeds f", newest first. Used with compiled queries.
+func (db *DB) GetArticlesWhereForUser(userID int64, where string, whereArgs []interface{}, showHidden bool, limit, offset int) ([]models.Article, error) {
+	db.WaitForReady()
+
+	query := `
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, a.author, COALESCE(a.is_pinned, 0), a.user_id
+		FROM articles a
+		LEFT JOIN feeds f ON a.feed_id = f.id
+		WHERE (` + where + `)`
+	args := append([]interface{}{}, whereArgs...)
+	if userID > 0 {
+		query += " AND a.user_id = ?"
+		args = append(args, userID)
+	}
+	if !showHidden {
+		query += " AND a.is_hidden = 0"
+	}
 	query += " ORDER BY a.published_at DESC LIMIT ? OFFSET ?"
 	args = append(args, limit, offset)
 
@@ -374,13 +454,24 @@ func (db *DB) GetArticlesForUser(userID int64, filter string, feedID int64, cate
 	}
 	defer rows.Close()
 
+	return scanArticles(rows, false, min(limit, 1000)), nil
+}
+
+// scanArticles scans article rows selected by GetArticlesForUser, which end with the pinned flag and owner.
+// With clusterColumns, each row ends with the duplicate count and cluster rank.
+func scanArticles(rows *sql.Rows, clusterColumns bool, capacity int) []models.Article {
 	// Pre-allocate slice with known capacity for better performance
-	articles := make([]models.Article, 0, limit)
+	articles := make([]models.Article, 0, capacity)
 	for rows.Next() {
 		var a models.Article
 		var imageURL, audioURL, videoURL, translatedTitle, summary, freshrssItemID, feedTitle, author sql.NullString
 		var publishedAt sql.NullTime
-		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &feedTitle, &author); err != nil {
+		var clusterRank int
+		dest := []interface{}{&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &feedTitle, &author, &a.IsPinned, &a.UserID}
+		if clusterColumns {
+			dest = append(dest, &a.DuplicateCount, &clusterRank)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			log.Println("Error scanning article:", err)
 			continue
 		}
@@ -399,7 +490,7 @@ func (db *DB) GetArticlesForUser(userID int64, filter string, feedID int64, cate
 		a.Author = author.String
 		articles = append(articles, a)
 	}
-	return articles, nil
+	return articles
 }
 
 // GetArticleByID retrieves a single article by its ID.
@@ -412,7 +503,7 @@ func (db *DB) GetArticleByID(id int64) (*models.Article, error) {
 func (db *DB) GetArticleByIDForUser(userID int64, id int64) (*models.Article, error) {
 	db.WaitForReady()
 	query := `
-		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, a.author
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at, a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, a.freshrss_item_id, f.title, a.author, COALESCE(a.is_pinned, 0), a.user_id
 		FROM articles a
 		LEFT JOIN feeds f ON a.feed_id = f.id
 		WHERE a.id = ?
@@ -427,7 +518,7 @@ func (db *DB) GetArticleByIDForUser(userID int64, id int64) (*models.Article, er
 	var a models.Article
 	var imageURL, audioURL, videoURL, translatedTitle, summary, freshrssItemID, feedTitle, author sql.NullString
 	var publishedAt sql.NullTime
-	if err := row.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &feedTitle, &author); err != nil {
+	if err := row.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt, &a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &freshrssItemID, &feedTitle, &author, &a.IsPinned, &a.UserID); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -0,0 +1,433 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"MavenRSS/internal/models"
+	"MavenRSS/internal/utils/textutil"
+)
+
+// ErrInvalidSearchQuery is returned when a search query cannot be translated to FTS5 syntax.
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// Snippet markers used inside SQLite; replaced by <mark> tags after HTML escaping
+const (
+	snippetMarkStart = "\x02"
+	snippetMarkEnd   = "\x03"
+)
+
+// searchColumns are the FTS5 columns that may be targeted with "column:term"
+var searchColumns = map[string]bool{
+	"title":            true,
+	"translated_title": true,
+	"summary":          true,
+	"content":          true,
+	"author":           true,
+	"transcript":       true,
+}
+
+// ArticleSearchOptions narrows a full-text search.
+type ArticleSearchOptions struct {
+	Query      string // User query (phrases, prefix*, AND/OR/NOT, -term, column:term)
+	FeedID     int64
+	Category   string
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
+
+// ArticleSearchResult is an article matched by full-text search.
+type ArticleSearchResult struct {
+	models.Article
+	Snippet string  `json:"snippet"` // HTML-escaped excerpt with matches wrapped in <mark>
+	Rank    float64 `json:"rank"`    // bm25 score, lower is more relevant
+}
+
+// InitArticleSearchTable creates the articles_fts virtual table and the triggers that
+// remove index entries when articles or cached contents are deleted, so every cleanup
+// path keeps the index in sync. Inserts and updates are indexed from Go because the
+// text needs HTML stripping first. An empty index is backfilled from existing articles.
+// The podcast tables must exist, as podcast transcripts are indexed too.
+func InitArticleSearchTable(db *sql.DB) error {
+	// Indexes created before podcast transcripts were searchable lack the transcript
+	// column; FTS5 tables can't be altered, so they are rebuilt
+	if _, err := db.Exec(`SELECT transcript FROM articles_fts LIMIT 0`); err != nil && strings.Contains(err.Error(), "no such column") {
+		if _, err := db.Exec(`DROP TABLE articles_fts`); err != nil {
+			return fmt.Errorf("drop outdated search index: %w", err)
+		}
+	}
+
+	query := `
+	CREATE VIRTUAL TABLE IF NOT EXISTS articles_fts USING fts5(
+		title,
+		translated_title,
+		summary,
+		content,
+		author,
+		transcript,
+		tokenize = 'unicode61 remove_diacritics 2'
+	);
+
+	CREATE TRIGGER IF NOT EXISTS articles_fts_article_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM articles_fts WHERE rowid = old.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS articles_fts_content_delete AFTER DELETE ON article_contents BEGIN
+		UPDATE articles_fts SET content = '' WHERE rowid = old.article_id;
+	END;
+	`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("create search index: %w", err)
+	}
+
+	var indexed, articles int64
+	_ = db.QueryRow(`SELECT COUNT(*) FROM articles_fts`).Scan(&indexed)
+	_ = db.QueryRow(`SELECT COUNT(*) FROM articles`).Scan(&articles)
+	if indexed > 0 || articles == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	if err := reindexArticles(db, ""); err != nil {
+		return fmt.Errorf("backfill search index: %w", err)
+	}
+	log.Printf("[Search] Indexed %d articles in %v", articles, time.Since(start))
+	return nil
+}
+
+// reindexArticles rebuilds search index rows for the articles matched by the given
+// WHERE clause (on alias "a"), or for every article when where is empty.
+func reindexArticles(db *sql.DB, where string, args ...interface{}) error {
+	query := `
+		SELECT a.id, a.title, COALESCE(a.translated_title, ''), COALESCE(a.summary, ''),
+			COALESCE(c.content, a.content, ''), COALESCE(a.author, ''), COALESCE(p.transcript, '')
+		FROM articles a
+		LEFT JOIN article_contents c ON c.article_id = a.id
+		LEFT JOIN podcast_episodes p ON p.article_id = a.id`
+	if where != "" {
+		query += " WHERE " + where
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+
+	type entry struct {
+		id                                                           int64
+		title, translatedTitle, summary, content, author, transcript string
+	}
+	var entries []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.id, &e.title, &e.translatedTitle, &e.summary, &e.content, &e.author, &e.transcript); err != nil {
+			rows.Close()
+			return err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, e := range entries {
+		if _, err := tx.Exec(`DELETE FROM articles_fts WHERE rowid = ?`, e.id); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`INSERT INTO articles_fts (rowid, title, translated_title, summary, content, author, transcript) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			e.id, e.title, e.translatedTitle, textutil.StripHTML(e.summary), textutil.StripHTML(e.content), e.author, e.transcript)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// indexArticles refreshes the search index for the given articles.
+// Failures are logged rather than returned: the index is secondary to the article data.
+func (db *DB) indexArticles(ids ...int64) {
+	const batchSize = 500
+	for i := 0; i < len(ids); i += batchSize {
+		end := i + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[i:end]
+
+		placeholders := make([]string, len(batch))
+		args := make([]interface{}, len(batch))
+		for j, id := range batch {
+			placeholders[j] = "?"
+			args[j] = id
+		}
+		if err := reindexArticles(db.DB, "a.id IN ("+strings.Join(placeholders, ",")+")", args...); err != nil {
+			log.Printf("[Search] Failed to index articles: %v", err)
+		}
+	}
+}
+
+// clearSearchColumn blanks a column for every indexed article.
+func (db *DB) clearSearchColumn(column string) {
+	if !searchColumns[column] {
+		return
+	}
+	if _, err := db.Exec(`UPDATE articles_fts SET ` + column + ` = ''`); err != nil {
+		log.Printf("[Search] Failed to clear %s: %v", column, err)
+	}
+}
+
+// RebuildArticleSearchIndex drops and rebuilds the whole full-text index.
+func (db *DB) RebuildArticleSearchIndex() error {
+	db.WaitForReady()
+	if _, err := db.Exec(`DELETE FROM articles_fts`); err != nil {
+		return err
+	}
+	return reindexArticles(db.DB, "")
+}
+
+// SearchArticlesForUser runs a ranked full-text search over a user's articles.
+// It returns one page of results and the total number of matches.
+func (db *DB) SearchArticlesForUser(userID int64, opts ArticleSearchOptions) ([]ArticleSearchResult, int, error) {
+	db.WaitForReady()
+
+	match, err := ParseSearchQuery(opts.Query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	where := ` WHERE articles_fts MATCH ? AND a.user_id = ? AND a.is_hidden = 0`
+	args := []interface{}{match, userID}
+	if opts.FeedID > 0 {
+		where += " AND a.feed_id = ?"
+		args = append(args, opts.FeedID)
+	}
+	if opts.Category != "" {
+		where += " AND (f.category = ? OR f.category LIKE ?)"
+		args = append(args, opts.Category, opts.Category+"/%")
+	}
+	if opts.UnreadOnly {
+		where += " AND a.is_read = 0"
+	}
+	from := `
+		FROM articles_fts
+		JOIN articles a ON a.id = articles_fts.rowid
+		LEFT JOIN feeds f ON f.id = a.feed_id`
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*)"+from+where, args...).Scan(&total); err != nil {
+		if isFTSSyntaxError(err) {
+			return nil, 0, fmt.Errorf("%w: %v", ErrInvalidSearchQuery, err)
+		}
+		return nil, 0, err
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	// Title matches weigh most, then translated titles, authors, summaries, full content and transcripts
+	query := `
+		SELECT a.id, a.feed_id, a.title, a.url, a.image_url, a.audio_url, a.video_url, a.published_at,
+			a.is_read, a.is_favorite, a.is_hidden, a.is_read_later, a.translated_title, a.summary, f.title, a.author,
+			snippet(articles_fts, -1, '` + snippetMarkStart + `', '` + snippetMarkEnd + `', '…', 24),
+			bm25(articles_fts, 10.0, 8.0, 2.0, 1.0, 3.0, 0.5) AS score` + from + where + `
+		ORDER BY score, a.published_at DESC
+		LIMIT ? OFFSET ?`
+	rows, err := db.Query(query, append(args, limit, opts.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := []ArticleSearchResult{}
+	for rows.Next() {
+		var res ArticleSearchResult
+		a := &res.Article
+		var imageURL, audioURL, videoURL, translatedTitle, summary, feedTitle, author sql.NullString
+		var publishedAt sql.NullTime
+		var snippet string
+		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &imageURL, &audioURL, &videoURL, &publishedAt,
+			&a.IsRead, &a.IsFavorite, &a.IsHidden, &a.IsReadLater, &translatedTitle, &summary, &feedTitle, &author,
+			&snippet, &res.Rank); err != nil {
+			log.Println("Error scanning search result:", err)
+			continue
+		}
+		a.UserID = userID
+		a.ImageURL = imageURL.String
+		a.AudioURL = audioURL.String
+		a.VideoURL = videoURL.String
+		if publishedAt.Valid {
+			a.PublishedAt = publishedAt.Time
+		}
+		a.TranslatedTitle = translatedTitle.String
+		a.Summary = summary.String
+		a.FeedTitle = feedTitle.String
+		a.Author = author.String
+		res.Snippet = highlightSnippet(snippet)
+		results = append(results, res)
+	}
+	return results, total, rows.Err()
+}
+
+// highlightSnippet escapes a raw FTS5 snippet and turns the match markers into <mark> tags.
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, snippetMarkStart, "<mark>")
+	return strings.ReplaceAll(escaped, snippetMarkEnd, "</mark>")
+}
+
+// isFTSSyntaxError reports whether err was caused by an FTS5 query the engine rejected.
+func isFTSSyntaxError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "fts5:") || strings.Contains(msg, "no such column")
+}
+
+// ParseSearchQuery translates a user search query into an FTS5 MATCH expression.
+//
+// Supported syntax:
+//   - words are matched as terms: rust async
+//   - "quoted phrases" are matched as phrases
+//   - a trailing * makes a prefix search: feder*
+//   - AND, OR and NOT (upper case) combine terms; adjacent terms are ANDed
+//   - -term excludes a term
+//   - parentheses group expressions
+//   - column:term restricts a term to title, translated_title, summary, content, author or transcript
+func ParseSearchQuery(input string) (string, error) {
+	var parts []string
+	var excluded []string
+	depth := 0
+	lastWasOperator := true // Nothing yet, so an operator here is an error
+
+	// Adjacent operands are joined with an explicit AND, which FTS5 requires around groups
+	appendOperand := func(operand string) {
+		if !lastWasOperator {
+			parts = append(parts, "AND")
+		}
+		parts = append(parts, operand)
+	}
+
+	runes := []rune(strings.TrimSpace(input))
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			depth++
+			appendOperand("(")
+			lastWasOperator = true
+			i++
+			continue
+		case r == ')':
+			if depth == 0 || lastWasOperator {
+				return "", fmt.Errorf("%w: unbalanced parentheses", ErrInvalidSearchQuery)
+			}
+			depth--
+			parts = append(parts, ")")
+			lastWasOperator = false
+			i++
+			continue
+		}
+
+		negate := false
+		if r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			negate = true
+			i++
+		}
+
+		// Read a phrase or a bare word
+		var term string
+		var column string
+		if runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			term = quoteSearchTerm(string(runes[i+1 : end]))
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' {
+				end++
+			}
+			word := string(runes[i:end])
+			i = end
+
+			if !negate && (word == "AND" || word == "OR" || word == "NOT") {
+				if lastWasOperator {
+					return "", fmt.Errorf("%w: %s must follow a term", ErrInvalidSearchQuery, word)
+				}
+				parts = append(parts, word)
+				lastWasOperator = true
+				continue
+			}
+
+			if idx := strings.Index(word, ":"); idx > 0 && searchColumns[strings.ToLower(word[:idx])] {
+				column = strings.ToLower(word[:idx])
+				word = word[idx+1:]
+			}
+			prefix := strings.HasSuffix(word, "*")
+			word = strings.TrimRight(word, "*")
+			if word == "" {
+				continue
+			}
+			term = quoteSearchTerm(word)
+			if prefix {
+				term += "*"
+			}
+		}
+		if term == `""` {
+			continue
+		}
+		if column != "" {
+			term = column + " : " + term
+		}
+
+		if negate {
+			excluded = append(excluded, term)
+			continue
+		}
+		appendOperand(term)
+		lastWasOperator = false
+	}
+
+	if depth != 0 {
+		return "", fmt.Errorf("%w: unbalanced parentheses", ErrInvalidSearchQuery)
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("%w: query must contain at least one term to match", ErrInvalidSearchQuery)
+	}
+	if lastWasOperator {
+		return "", fmt.Errorf("%w: query cannot end with an operator", ErrInvalidSearchQuery)
+	}
+
+	match := strings.Join(parts, " ")
+	if len(excluded) > 0 {
+		// FTS5 NOT is binary, so excluded terms are applied to the whole expression
+		match = "(" + match + ")"
+		for _, term := range excluded {
+			match += " NOT " + term
+		}
+	}
+	return match, nil
+}
+
+// quoteSearchTerm wraps a term in double quotes so FTS5 treats it as a string.
+func quoteSearchTerm(term string) string {
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+}
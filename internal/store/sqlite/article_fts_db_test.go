@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"MavenRSS/internal/models"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`rust async`, `"rust" AND "async"`},
+		{`"memory safety"`, `"memory safety"`},
+		{`feder*`, `"feder"*`},
+		{`go OR rust`, `"go" OR "rust"`},
+		{`(go OR rust) generics`, `( "go" OR "rust" ) AND "generics"`},
+		{`linux -windows`, `("linux") NOT "windows"`},
+		{`title:release`, `title : "release"`},
+		{`c++ "unterminated phrase`, `"c++" AND "unterminated phrase"`},
+	}
+	for _, tt := range tests {
+		got, err := ParseSearchQuery(tt.input)
+		if err != nil {
+			t.Errorf("ParseSearchQuery(%q) error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSearchQuery(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "AND go", "go OR", "(go", "go)", "-only"} {
+		if _, err := ParseSearchQuery(bad); !errors.Is(err, ErrInvalidSearchQuery) {
+			t.Errorf("ParseSearchQuery(%q) expected ErrInvalidSearchQuery, got %v", bad, err)
+		}
+	}
+}
+
+func TestSearchArticlesForUser(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.DB.Close()
+	if err := db.Init(); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	feedID, err := db.AddFeed(&models.Feed{Title: "Feed", URL: "http://example.com/rss"})
+	if err != nil {
+		t.Fatalf("AddFeed: %v", err)
+	}
+	articles := []*models.Article{
+		{FeedID: feedID, Title: "Federated timelines explained", URL: "http://example.com/1", Summary: "<p>How <b>ActivityPub</b> works</p>", PublishedAt: time.Now()},
+		{FeedID: feedID, Title: "Cooking pasta", URL: "http://example.com/2", Summary: "Water, salt and patience", PublishedAt: time.Now()},
+	}
+	if err := db.SaveArticles(context.Background(), articles); err != nil {
+		t.Fatalf("SaveArticles: %v", err)
+	}
+
+	search := func(q string) []ArticleSearchResult {
+		t.Helper()
+		results, total, err := db.SearchArticlesForUser(1, ArticleSearchOptions{Query: q})
+		if err != nil {
+			t.Fatalf("SearchArticlesForUser(%q): %v", q, err)
+		}
+		if total != len(results) {
+			t.Fatalf("SearchArticlesForUser(%q): total %d != %d results", q, total, len(results))
+		}
+		return results
+	}
+
+	results := search("feder*")
+	if len(results) != 1 || results[0].Title != "Federated timelines explained" {
+		t.Fatalf("prefix search: unexpected results %+v", results)
+	}
+	if !strings.Contains(results[0].Snippet, "<mark>") {
+		t.Errorf("expected highlighted snippet, got %q", results[0].Snippet)
+	}
+
+	// HTML in summaries is stripped before indexing
+	if len(search("activitypub")) != 1 {
+		t.Error("expected summary text to be searchable")
+	}
+	if len(search("b")) != 0 {
+		t.Error("expected HTML tags not to be indexed")
+	}
+
+	// Cached content and translations are indexed when they change
+	pastaID := search("pasta")[0].ID
+	if err := db.SetArticleContent(pastaID, "<div>Al dente carbonara &amp; more</div>"); err != nil {
+		t.Fatalf("SetArticleContent: %v", err)
+	}
+	if len(search("carbonara")) != 1 {
+		t.Error("expected cached content to be searchable")
+	}
+	if err := db.UpdateArticleTranslation(pastaID, "Nudeln kochen"); err != nil {
+		t.Fatalf("UpdateArticleTranslation: %v", err)
+	}
+	if len(search(`"nudeln kochen"`)) != 1 {
+		t.Error("expected translated title to be searchable")
+	}
+
+	// Podcast transcripts are indexed once fetched
+	fedID := search("feder*")[0].ID
+	if err := db.SavePodcastEpisode(&models.PodcastEpisode{ArticleID: fedID, AudioURL: "http://example.com/1.mp3", TranscriptURL: "http://example.com/1.vtt"}); err != nil {
+		t.Fatalf("SavePodcastEpisode: %v", err)
+	}
+	if err := db.SetPodcastEpisodeResources(fedID, "Today we talk about Mastodon instances", nil); err != nil {
+		t.Fatalf("SetPodcastEpisodeResources: %v", err)
+	}
+	if len(search("transcript:mastodon")) != 1 {
+		t.Error("expected transcript to be searchable")
+	}
+
+	// Deleting cached content and articles removes them from the index
+	if err := db.DeleteArticleContent(pastaID); err != nil {
+		t.Fatalf("DeleteArticleContent: %v", err)
+	}
+	if len(search("carbonara")) != 0 {
+		t.Error("expected deleted content to be removed from the index")
+	}
+	if _, err := db.Exec(`DELETE FROM articles WHERE id = ?`, pastaID); err != nil {
+		t.Fatalf("delete article: %v", err)
+	}
+	if len(search("pasta")) != 0 {
+		t.Error("expected deleted article to be removed from the index")
+	}
+
+	// Other users never see the results
+	results, _, err = db.SearchArticlesForUser(2, ArticleSearchOptions{Query: "feder*"})
+	if err != nil || len(results) != 0 {
+		t.Errorf("expected no results for another user, got %d (%v)", len(results), err)
+	}
+}
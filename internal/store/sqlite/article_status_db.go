@@ -8,15 +8,17 @@ import (
 var ErrArticleNotFound = errors.New("article not found or does not belong to user")
 
 // MarkArticleRead marks an article as read or unread.
-// When marking as read, also removes from read later list.
+// When marking as read, also removes from read later list and marks its near-duplicates read.
 func (db *DB) MarkArticleRead(id int64, read bool) error {
 	db.WaitForReady()
 	isRead := 0
 	if read {
 		isRead = 1
 		// When marking as read, also remove from read later
-		_, err := db.Exec("UPDATE articles SET is_read = 1, is_read_later = 0 WHERE id = ?", id)
-		return err
+		if _, err := db.Exec("UPDATE articles SET is_read = 1, is_read_later = 0 WHERE id = ?", id); err != nil {
+			return err
+		}
+		return db.markClusterRead(id)
 	}
 	_, err := db.Exec("UPDATE articles SET is_read = ? WHERE id = ?", isRead, id)
 	return err
@@ -104,9 +106,16 @@ func (db *DB) SetArticleReadLater(id int64, readLater bool) error {
 	return err
 }
 
+// SetArticlePinned sets whether an article is pinned to the top of article lists.
+func (db *DB) SetArticlePinned(id int64, pinned bool) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE articles SET is_pinned = ? WHERE id = ?", pinned, id)
+	return err
+}
+
 // MarkArticleReadForUser marks an article as read or unread for a specific user.
 // Returns ErrArticleNotFound if the article doesn't belong to the user.
-// When marking as read, also removes from read later list.
+// When marking as read, also removes from read later list and marks its near-duplicates read.
 func (db *DB) MarkArticleReadForUser(userID int64, id int64, read bool) error {
 	db.WaitForReady()
 	isRead := 0
@@ -120,7 +129,7 @@ func (db *DB) MarkArticleReadForUser(userID int64, id int64, read bool) error {
 		if rowsAffected == 0 {
 			return ErrArticleNotFound
 		}
-		return nil
+		return db.markClusterRead(id)
 	}
 	result, err := db.Exec("UPDATE articles SET is_read = ? WHERE id = ? AND user_id = ?", isRead, id, userID)
 	if err != nil {
@@ -1,57 +1,154 @@
 package sqlite
 
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"MavenRSS/internal/utils/urlutil"
+)
+
 // UpdateArticleContent updates the content field for an article in the articles table.
 func (db *DB) UpdateArticleContent(id int64, content string) error {
 	db.WaitForReady()
 	_, err := db.Exec("UPDATE articles SET content = ? WHERE id = ?", content, id)
-	return err
+	if err != nil {
+		return err
+	}
+	db.indexArticles(id)
+	return nil
 }
 
 // UpdateArticleTranslation updates the translated_title field for an article.
 func (db *DB) UpdateArticleTranslation(id int64, translatedTitle string) error {
 	db.WaitForReady()
 	_, err := db.Exec("UPDATE articles SET translated_title = ? WHERE id = ?", translatedTitle, id)
-	return err
+	if err != nil {
+		return err
+	}
+	db.indexArticles(id)
+	return nil
 }
 
 // UpdateArticleSummary updates the cached summary for an article.
 func (db *DB) UpdateArticleSummary(id int64, summary string) error {
 	db.WaitForReady()
 	_, err := db.Exec("UPDATE articles SET summary = ? WHERE id = ?", summary, id)
-	return err
+	if err != nil {
+		return err
+	}
+	db.indexArticles(id)
+	return nil
 }
 
 // ClearAllTranslations clears all translated titles from articles.
 func (db *DB) ClearAllTranslations() error {
 	db.WaitForReady()
 	_, err := db.Exec("UPDATE articles SET translated_title = ''")
-	return err
+	if err != nil {
+		return err
+	}
+	db.clearSearchColumn("translated_title")
+	return nil
 }
 
 // ClearArticleTranslation clears the translated title for a single article.
 func (db *DB) ClearArticleTranslation(id int64) error {
 	db.WaitForReady()
 	_, err := db.Exec("UPDATE articles SET translated_title = '' WHERE id = ?", id)
-	return err
+	if err != nil {
+		return err
+	}
+	db.indexArticles(id)
+	return nil
 }
 
 // ClearAllSummaries clears all summaries from articles.
 func (db *DB) ClearAllSummaries() error {
 	db.WaitForReady()
 	_, err := db.Exec("UPDATE articles SET summary = ''")
-	return err
+	if err != nil {
+		return err
+	}
+	db.clearSearchColumn("summary")
+	return nil
 }
 
 // ClearArticleSummary clears the summary for a single article.
 func (db *DB) ClearArticleSummary(id int64) error {
 	db.WaitForReady()
 	_, err := db.Exec("UPDATE articles SET summary = '' WHERE id = ?", id)
-	return err
+	if err != nil {
+		return err
+	}
+	db.indexArticles(id)
+	return nil
 }
 
 // ClearArticleContent clears the content for a single article.
 func (db *DB) ClearArticleContent(id int64) error {
 	db.WaitForReady()
 	_, err := db.Exec("UPDATE articles SET content = '' WHERE id = ?", id)
-	return err
+	if err != nil {
+		return err
+	}
+	db.indexArticles(id)
+	return nil
+}
+
+// UpdateArticleByURL updates the title, publication time and content of the article of a
+// feed with the given URL and regenerates its unique ID from the new title and publication
+// time, so that saving the changed article again doesn't add a duplicate. Full text fetched
+// from the article's page is kept. It reports whether an article was found.
+func (db *DB) UpdateArticleByURL(userID, feedID int64, url, title, content string, publishedAt time.Time, hasValidPublishedTime bool) (bool, error) {
+	db.WaitForReady()
+	var id int64
+	var oldTitle, oldUniqueID string
+	var oldPublishedAt sql.NullTime
+	err := db.QueryRow("SELECT id, title, unique_id, published_at FROM articles WHERE user_id = ? AND feed_id = ? AND url = ? ORDER BY id LIMIT 1",
+		userID, feedID, url).Scan(&id, &oldTitle, &oldUniqueID, &oldPublishedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	uniqueID := urlutil.GenerateArticleUniqueID(userID, title, feedID, publishedAt.UTC(), hasValidPublishedTime)
+	changed := title != oldTitle || uniqueID != oldUniqueID || !oldPublishedAt.Time.Equal(publishedAt)
+	if changed {
+		// Leave the article alone if another one already has the new unique ID
+		var otherID int64
+		err = db.QueryRow("SELECT id FROM articles WHERE user_id = ? AND unique_id = ? AND id != ?", userID, uniqueID, id).Scan(&otherID)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return true, err
+		}
+		query := "UPDATE articles SET title = ?, unique_id = ?, published_at = ? WHERE id = ?"
+		if title != oldTitle {
+			query = "UPDATE articles SET title = ?, unique_id = ?, published_at = ?, translated_title = '' WHERE id = ?"
+		}
+		if _, err := db.Exec(query, title, uniqueID, publishedAt, id); err != nil {
+			return true, err
+		}
+	}
+
+	if content != "" {
+		res, err := db.Exec(`
+			INSERT INTO article_contents (article_id, content, fetched_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(article_id) DO UPDATE SET content = excluded.content, fetched_at = excluded.fetched_at
+			WHERE article_contents.is_full_text = 0 AND article_contents.content != excluded.content`, id, content)
+		if err != nil {
+			return true, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			changed = true
+		}
+	}
+	if changed {
+		db.indexArticles(id)
+	}
+	return true, nil
 }
@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+)
+
+// EmailSyncState is the IMAP synchronization state of a folder of an email feed.
+// UIDs are only meaningful for the UIDVALIDITY they were read with.
+type EmailSyncState struct {
+	FeedID      int64
+	Folder      string
+	UIDValidity uint32 // 0 when unknown, e.g. migrated from feeds.email_last_uid
+	LastUID     uint32 // Highest UID processed
+	UpdatedAt   time.Time
+}
+
+// InitEmailSyncTable creates the email_sync_state table if it doesn't exist.
+func InitEmailSyncTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS email_sync_state (
+		feed_id INTEGER NOT NULL,
+		folder TEXT NOT NULL,
+		uid_validity INTEGER NOT NULL DEFAULT 0,
+		last_uid INTEGER NOT NULL DEFAULT 0,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (feed_id, folder)
+	);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// GetEmailSyncState returns the sync state of a folder of a feed, or nil if it was never synced.
+func (db *DB) GetEmailSyncState(feedID int64, folder string) (*EmailSyncState, error) {
+	db.WaitForReady()
+	s := &EmailSyncState{FeedID: feedID, Folder: folder}
+	err := db.QueryRow(`SELECT uid_validity, last_uid, updated_at FROM email_sync_state WHERE feed_id = ? AND folder = ?`,
+		feedID, folder).Scan(&s.UIDValidity, &s.LastUID, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SaveEmailSyncState stores the sync state of a folder of a feed.
+func (db *DB) SaveEmailSyncState(s *EmailSyncState) error {
+	db.WaitForReady()
+	s.UpdatedAt = time.Now()
+	_, err := db.Exec(`
+		INSERT INTO email_sync_state (feed_id, folder, uid_validity, last_uid, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(feed_id, folder) DO UPDATE SET
+			uid_validity = excluded.uid_validity, last_uid = excluded.last_uid, updated_at = excluded.updated_at`,
+		s.FeedID, s.Folder, s.UIDValidity, s.LastUID, s.UpdatedAt)
+	return err
+}
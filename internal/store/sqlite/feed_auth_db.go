@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"MavenRSS/internal/crypto"
+	"MavenRSS/internal/models"
+)
+
+// SetFeedAuth stores the encrypted credentials of a feed. A nil or empty auth removes them.
+func (db *DB) SetFeedAuth(id int64, auth *models.FeedAuth) error {
+	db.WaitForReady()
+
+	var encrypted string
+	if auth != nil && (auth.Type != "" || auth.Cookies != "" || len(auth.Headers) > 0) {
+		data, err := json.Marshal(auth)
+		if err != nil {
+			return fmt.Errorf("marshal feed auth: %w", err)
+		}
+		encrypted, err = crypto.Encrypt(string(data))
+		if err != nil {
+			return fmt.Errorf("encrypt feed auth: %w", err)
+		}
+	}
+
+	_, err := db.Exec("UPDATE feeds SET http_auth = ? WHERE id = ?", encrypted, id)
+	return err
+}
+
+// decodeFeedAuth decrypts the stored credentials of a feed, or returns nil when there are none
+func decodeFeedAuth(encrypted string) *models.FeedAuth {
+	if encrypted == "" {
+		return nil
+	}
+	data, err := crypto.Decrypt(encrypted)
+	if err != nil {
+		log.Printf("Failed to decrypt feed credentials: %v", err)
+		return nil
+	}
+	var auth models.FeedAuth
+	if err := json.Unmarshal([]byte(data), &auth); err != nil {
+		log.Printf("Failed to decode feed credentials: %v", err)
+		return nil
+	}
+	return &auth
+}
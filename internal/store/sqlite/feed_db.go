@@ -2,6 +2,8 @@ package sqlite
 
 import (
 	"database/sql"
+	"encoding/json"
+	"log"
 	"time"
 
 	"MavenRSS/internal/models"
@@ -259,6 +261,26 @@ func (db *DB) DeleteFeed(id int64) error {
 	if err != nil {
 		return err
 	}
+	_, err = db.Exec("DELETE FROM websub_subscriptions WHERE feed_id = ?", id)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("DELETE FROM feed_fetch_log WHERE feed_id = ?", id)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("DELETE FROM feed_url_changes WHERE feed_id = ?", id)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("DELETE FROM email_sync_state WHERE feed_id = ?", id)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("DELETE FROM feed_item_uids WHERE feed_id = ?", id)
+	if err != nil {
+		return err
+	}
 	_, err = db.Exec("DELETE FROM feeds WHERE id = ?", id)
 	return err
 }
@@ -288,6 +310,9 @@ func (db *DB) GetFeeds() ([]models.Feed, error) {
 			COALESCE(f.freshrss_stream_id, ''),
 			COALESCE(f.translate_articles, 0),
 			COALESCE(f.etag, ''), COALESCE(f.last_modified, ''),
+			COALESCE(f.is_paused, 0), COALESCE(f.pause_reason, ''),
+			COALESCE(f.consecutive_failures, 0), f.next_retry_at, COALESCE(f.http_auth, ''), COALESCE(f.fetch_full_text, 0),
+			COALESCE(f.email_list_id, ''), COALESCE(f.email_unsubscribe, ''), COALESCE(f.email_senders, ''), COALESCE(f.pagination, ''), COALESCE(f.watch_ignore, ''),
 			(SELECT MAX(a.published_at) FROM articles a WHERE a.feed_id = f.id) as latest_article_time,
 			CAST(COALESCE((
 				SELECT
@@ -319,8 +344,8 @@ func (db *DB) GetFeeds() ([]models.Feed, error) {
 	for rows.Next() {
 		var f models.Feed
 		var translateArticles bool
-		var link, category, imageURL, lastError, scriptPath, proxyURL, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailUsername, emailPassword, emailFolder, freshRSSStreamID, latestArticleTimeStr, etag, lastModified sql.NullString
-		var lastUpdated sql.NullTime
+		var link, category, imageURL, lastError, scriptPath, proxyURL, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailUsername, emailPassword, emailFolder, freshRSSStreamID, latestArticleTimeStr, etag, lastModified, pauseReason, httpAuth, pagination sql.NullString
+		var lastUpdated, nextRetryAt sql.NullTime
 		if err := rows.Scan(
 			&f.ID, &f.UserID, &f.Title, &f.URL, &link, &f.Description, &category, &imageURL,
 			&f.Position, &lastUpdated, &lastError, &f.DiscoveryCompleted, &scriptPath,
@@ -330,7 +355,10 @@ func (db *DB) GetFeeds() ([]models.Feed, error) {
 			&xpathItemThumbnail, &xpathItemCategories, &xpathItemUid, &articleViewMode,
 			&autoExpandContent, &emailAddress, &emailIMAPServer, &f.EmailIMAPPort,
 			&emailUsername, &emailPassword, &emailFolder, &f.EmailLastUID,
-			&f.IsFreshRSSSource, &freshRSSStreamID, &translateArticles, &etag, &lastModified, &latestArticleTimeStr, &f.ArticlesPerMonth,
+			&f.IsFreshRSSSource, &freshRSSStreamID, &translateArticles, &etag, &lastModified,
+			&f.IsPaused, &pauseReason, &f.ConsecutiveFailures, &nextRetryAt, &httpAuth, &f.FetchFullText,
+			&f.EmailListID, &f.EmailUnsubscribe, &f.EmailSenders, &pagination, &f.WatchIgnore,
+			&latestArticleTimeStr, &f.ArticlesPerMonth,
 		); err != nil {
 			return nil, err
 		}
@@ -387,6 +415,12 @@ func (db *DB) GetFeeds() ([]models.Feed, error) {
 			f.EmailIMAPPort = 993
 		}
 		f.FreshRSSStreamID = freshRSSStreamID.String
+		f.PauseReason = pauseReason.String
+		if nextRetryAt.Valid {
+			f.NextRetryAt = &nextRetryAt.Time
+		}
+		f.Auth = decodeFeedAuth(httpAuth.String)
+		f.Pagination = decodeFeedPagination(pagination.String)
 
 		// Set latest article time from string
 		// Format from database: "2025-11-15 18:39:02 +0000 UTC" (Go's time.String() format)
@@ -434,7 +468,7 @@ func (db *DB) GetFeedByID(id int64) (*models.Feed, error) {
 // GetFeedByIDForUser retrieves a specific feed by its ID for a specific user.
 func (db *DB) GetFeedByIDForUser(userID int64, id int64) (*models.Feed, error) {
 	db.WaitForReady()
-	baseQuery := "SELECT id, user_id, title, url, link, description, category, image_url, COALESCE(position, 0), last_updated, last_error, COALESCE(discovery_completed, 0), COALESCE(script_path, ''), COALESCE(hide_from_timeline, 0), COALESCE(proxy_url, ''), COALESCE(proxy_enabled, 0), COALESCE(refresh_interval, 0), COALESCE(is_image_mode, 0), COALESCE(type, ''), COALESCE(xpath_item, ''), COALESCE(xpath_item_title, ''), COALESCE(xpath_item_content, ''), COALESCE(xpath_item_uri, ''), COALESCE(xpath_item_author, ''), COALESCE(xpath_item_timestamp, ''), COALESCE(xpath_item_time_format, ''), COALESCE(xpath_item_thumbnail, ''), COALESCE(xpath_item_categories, ''), COALESCE(xpath_item_uid, ''), COALESCE(article_view_mode, 'global'), COALESCE(auto_expand_content, 'global'), COALESCE(email_address, ''), COALESCE(email_imap_server, ''), COALESCE(email_imap_port, 993), COALESCE(email_username, ''), COALESCE(email_password, ''), COALESCE(email_folder, 'INBOX'), COALESCE(email_last_uid, 0), COALESCE(is_freshrss_source, 0), COALESCE(freshrss_stream_id, ''), COALESCE(translate_articles, 0), COALESCE(etag, ''), COALESCE(last_modified, '') FROM feeds WHERE id = ?"
+	baseQuery := "SELECT id, user_id, title, url, link, description, category, image_url, COALESCE(position, 0), last_updated, last_error, COALESCE(discovery_completed, 0), COALESCE(script_path, ''), COALESCE(hide_from_timeline, 0), COALESCE(proxy_url, ''), COALESCE(proxy_enabled, 0), COALESCE(refresh_interval, 0), COALESCE(is_image_mode, 0), COALESCE(type, ''), COALESCE(xpath_item, ''), COALESCE(xpath_item_title, ''), COALESCE(xpath_item_content, ''), COALESCE(xpath_item_uri, ''), COALESCE(xpath_item_author, ''), COALESCE(xpath_item_timestamp, ''), COALESCE(xpath_item_time_format, ''), COALESCE(xpath_item_thumbnail, ''), COALESCE(xpath_item_categories, ''), COALESCE(xpath_item_uid, ''), COALESCE(article_view_mode, 'global'), COALESCE(auto_expand_content, 'global'), COALESCE(email_address, ''), COALESCE(email_imap_server, ''), COALESCE(email_imap_port, 993), COALESCE(email_username, ''), COALESCE(email_password, ''), COALESCE(email_folder, 'INBOX'), COALESCE(email_last_uid, 0), COALESCE(is_freshrss_source, 0), COALESCE(freshrss_stream_id, ''), COALESCE(translate_articles, 0), COALESCE(etag, ''), COALESCE(last_modified, ''), COALESCE(is_paused, 0), COALESCE(pause_reason, ''), COALESCE(consecutive_failures, 0), next_retry_at, COALESCE(http_auth, ''), COALESCE(fetch_full_text, 0), COALESCE(email_list_id, ''), COALESCE(email_unsubscribe, ''), COALESCE(email_senders, ''), COALESCE(pagination, ''), COALESCE(watch_ignore, '') FROM feeds WHERE id = ?"
 
 	var args []interface{}
 	args = append(args, id)
@@ -446,10 +480,10 @@ func (db *DB) GetFeedByIDForUser(userID int64, id int64) (*models.Feed, error) {
 	row := db.QueryRow(baseQuery, args...)
 
 	var f models.Feed
-	var link, category, imageURL, lastError, scriptPath, proxyURL, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailUsername, emailPassword, emailFolder, freshRSSStreamID, etag, lastModified sql.NullString
-	var lastUpdated sql.NullTime
+	var link, category, imageURL, lastError, scriptPath, proxyURL, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailUsername, emailPassword, emailFolder, freshRSSStreamID, etag, lastModified, pauseReason, httpAuth, pagination sql.NullString
+	var lastUpdated, nextRetryAt sql.NullTime
 	var translateArticles sql.NullBool
-	if err := row.Scan(&f.ID, &f.UserID, &f.Title, &f.URL, &link, &f.Description, &category, &imageURL, &f.Position, &lastUpdated, &lastError, &f.DiscoveryCompleted, &scriptPath, &f.HideFromTimeline, &proxyURL, &f.ProxyEnabled, &f.RefreshInterval, &f.IsImageMode, &feedType, &xpathItem, &xpathItemTitle, &xpathItemContent, &xpathItemUri, &xpathItemAuthor, &xpathItemTimestamp, &xpathItemTimeFormat, &xpathItemThumbnail, &xpathItemCategories, &xpathItemUid, &articleViewMode, &autoExpandContent, &emailAddress, &emailIMAPServer, &f.EmailIMAPPort, &emailUsername, &emailPassword, &emailFolder, &f.EmailLastUID, &f.IsFreshRSSSource, &freshRSSStreamID, &translateArticles, &etag, &lastModified); err != nil {
+	if err := row.Scan(&f.ID, &f.UserID, &f.Title, &f.URL, &link, &f.Description, &category, &imageURL, &f.Position, &lastUpdated, &lastError, &f.DiscoveryCompleted, &scriptPath, &f.HideFromTimeline, &proxyURL, &f.ProxyEnabled, &f.RefreshInterval, &f.IsImageMode, &feedType, &xpathItem, &xpathItemTitle, &xpathItemContent, &xpathItemUri, &xpathItemAuthor, &xpathItemTimestamp, &xpathItemTimeFormat, &xpathItemThumbnail, &xpathItemCategories, &xpathItemUid, &articleViewMode, &autoExpandContent, &emailAddress, &emailIMAPServer, &f.EmailIMAPPort, &emailUsername, &emailPassword, &emailFolder, &f.EmailLastUID, &f.IsFreshRSSSource, &freshRSSStreamID, &translateArticles, &etag, &lastModified, &f.IsPaused, &pauseReason, &f.ConsecutiveFailures, &nextRetryAt, &httpAuth, &f.FetchFullText, &f.EmailListID, &f.EmailUnsubscribe, &f.EmailSenders, &pagination, &f.WatchIgnore); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -499,6 +533,12 @@ func (db *DB) GetFeedByIDForUser(userID int64, id int64) (*models.Feed, error) {
 		f.EmailIMAPPort = 993
 	}
 	f.FreshRSSStreamID = freshRSSStreamID.String
+	f.PauseReason = pauseReason.String
+	if nextRetryAt.Valid {
+		f.NextRetryAt = &nextRetryAt.Time
+	}
+	f.Auth = decodeFeedAuth(httpAuth.String)
+	f.Pagination = decodeFeedPagination(pagination.String)
 	if translateArticles.Valid {
 		f.TranslateArticles = translateArticles.Bool
 	}
@@ -596,6 +636,42 @@ func (db *DB) UpdateFeedCachingInfo(id int64, etag, lastModified string) error {
 	return err
 }
 
+// IncrementFeedFailures counts a failed refresh of a feed and returns its consecutive failures.
+func (db *DB) IncrementFeedFailures(id int64) (int, error) {
+	db.WaitForReady()
+	var failures int
+	err := db.QueryRow("UPDATE feeds SET consecutive_failures = COALESCE(consecutive_failures, 0) + 1 WHERE id = ? RETURNING consecutive_failures", id).Scan(&failures)
+	return failures, err
+}
+
+// SetFeedNextRetry delays the scheduled refreshes of a feed until the given time.
+func (db *DB) SetFeedNextRetry(id int64, nextRetryAt time.Time) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE feeds SET next_retry_at = ? WHERE id = ?", nextRetryAt.UTC(), id)
+	return err
+}
+
+// PauseFeed pauses a feed until it is probed again at the given time.
+func (db *DB) PauseFeed(id int64, reason string, nextRetryAt time.Time) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE feeds SET is_paused = 1, pause_reason = ?, consecutive_failures = COALESCE(consecutive_failures, 0) + 1, next_retry_at = ? WHERE id = ?", reason, nextRetryAt.UTC(), id)
+	return err
+}
+
+// ResetFeedFailures clears the failures of a feed and resumes it if it was paused.
+func (db *DB) ResetFeedFailures(id int64) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE feeds SET is_paused = 0, pause_reason = '', consecutive_failures = 0, next_retry_at = NULL WHERE id = ?", id)
+	return err
+}
+
+// SetFeedFetchFullText sets whether the full text of a feed's new articles is fetched on ingest.
+func (db *DB) SetFeedFetchFullText(id int64, enabled bool) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE feeds SET fetch_full_text = ? WHERE id = ?", enabled, id)
+	return err
+}
+
 // UpdateFeedEmailLastUID updates a newsletter feed's last processed email UID.
 func (db *DB) UpdateFeedEmailLastUID(id int64, lastUID int) error {
 	db.WaitForReady()
@@ -603,6 +679,97 @@ func (db *DB) UpdateFeedEmailLastUID(id int64, lastUID int) error {
 	return err
 }
 
+// SetFeedEmailSenders sets the senders whose messages an IMAP newsletter feed takes.
+func (db *DB) SetFeedEmailSenders(id int64, senders string) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE feeds SET email_senders = ? WHERE id = ?", senders, id)
+	return err
+}
+
+// SetFeedPagination sets how the next pages and item pages of a feed are read. A nil pagination reads only the first page.
+func (db *DB) SetFeedPagination(id int64, pagination *models.FeedPagination) error {
+	db.WaitForReady()
+	var data []byte
+	if pagination != nil && (pagination.Next != "" || pagination.HasDetails()) {
+		var err error
+		if data, err = json.Marshal(pagination); err != nil {
+			return err
+		}
+	}
+	_, err := db.Exec("UPDATE feeds SET pagination = ? WHERE id = ?", string(data), id)
+	return err
+}
+
+// decodeFeedPagination decodes the stored pagination of a feed, or returns nil when there is none
+func decodeFeedPagination(data string) *models.FeedPagination {
+	if data == "" {
+		return nil
+	}
+	var pagination models.FeedPagination
+	if err := json.Unmarshal([]byte(data), &pagination); err != nil {
+		log.Printf("Failed to decode feed pagination: %v", err)
+		return nil
+	}
+	return &pagination
+}
+
+// SetFeedWatchIgnore sets the patterns of a watch feed whose matches are ignored when comparing its page.
+func (db *DB) SetFeedWatchIgnore(id int64, patterns string) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE feeds SET watch_ignore = ? WHERE id = ?", patterns, id)
+	return err
+}
+
+// GetFeedWatchSnapshot returns the hash and normalized content of the last snapshot of a watch feed,
+// or empty strings before the first one.
+func (db *DB) GetFeedWatchSnapshot(id int64) (hash, snapshot string, err error) {
+	db.WaitForReady()
+	err = db.QueryRow("SELECT COALESCE(watch_hash, ''), COALESCE(watch_snapshot, '') FROM feeds WHERE id = ?", id).Scan(&hash, &snapshot)
+	if err == sql.ErrNoRows {
+		return "", "", nil
+	}
+	return hash, snapshot, err
+}
+
+// SetFeedWatchSnapshot saves the last snapshot of a watch feed. Empty values reset it.
+func (db *DB) SetFeedWatchSnapshot(id int64, hash, snapshot string) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE feeds SET watch_hash = ?, watch_snapshot = ? WHERE id = ?", hash, snapshot, id)
+	return err
+}
+
+// GetReceivedEmailFeedIDs returns the IDs of the newsletter feeds delivered by the mail
+// receiver to an address (email feeds without IMAP server), lowest ID first.
+// The address is matched case-insensitively.
+func (db *DB) GetReceivedEmailFeedIDs(address string) ([]int64, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`
+		SELECT id FROM feeds
+		WHERE type = 'email' AND COALESCE(email_imap_server, '') = '' AND LOWER(email_address) = LOWER(?)
+		ORDER BY id ASC`, address)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// UpdateFeedEmailList updates the List-Id and unsubscribe link of a newsletter feed.
+func (db *DB) UpdateFeedEmailList(id int64, listID, unsubscribe string) error {
+	db.WaitForReady()
+	_, err := db.Exec("UPDATE feeds SET email_list_id = ?, email_unsubscribe = ? WHERE id = ?", listID, unsubscribe, id)
+	return err
+}
+
 // MarkFeedDiscovered marks a feed as having completed discovery.
 func (db *DB) MarkFeedDiscovered(id int64) error {
 	db.WaitForReady()
@@ -780,6 +947,9 @@ func (db *DB) GetFeedsForUser(userID int64) ([]models.Feed, error) {
 			COALESCE(f.email_last_uid, 0), COALESCE(f.is_freshrss_source, 0),
 			COALESCE(f.freshrss_stream_id, ''),
 			COALESCE(f.translate_articles, 0),
+			COALESCE(f.is_paused, 0), COALESCE(f.pause_reason, ''),
+			COALESCE(f.consecutive_failures, 0), f.next_retry_at, COALESCE(f.http_auth, ''), COALESCE(f.fetch_full_text, 0),
+			COALESCE(f.email_list_id, ''), COALESCE(f.email_unsubscribe, ''), COALESCE(f.email_senders, ''), COALESCE(f.pagination, ''), COALESCE(f.watch_ignore, ''),
 			(SELECT MAX(a.published_at) FROM articles a WHERE a.feed_id = f.id) as latest_article_time,
 			CAST(COALESCE((
 				SELECT
@@ -827,8 +997,8 @@ func (db *DB) GetFeedsForUser(userID int64) ([]models.Feed, error) {
 	for rows.Next() {
 		var f models.Feed
 		var translateArticles bool
-		var link, category, imageURL, lastError, scriptPath, proxyURL, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailUsername, emailPassword, emailFolder, freshRSSStreamID, latestArticleTimeStr sql.NullString
-		var lastUpdated sql.NullTime
+		var link, category, imageURL, lastError, scriptPath, proxyURL, feedType, xpathItem, xpathItemTitle, xpathItemContent, xpathItemUri, xpathItemAuthor, xpathItemTimestamp, xpathItemTimeFormat, xpathItemThumbnail, xpathItemCategories, xpathItemUid, articleViewMode, autoExpandContent, emailAddress, emailIMAPServer, emailUsername, emailPassword, emailFolder, freshRSSStreamID, latestArticleTimeStr, pauseReason, httpAuth, pagination sql.NullString
+		var lastUpdated, nextRetryAt sql.NullTime
 		if err := rows.Scan(
 			&f.ID, &f.UserID, &f.Title, &f.URL, &link, &f.Description, &category, &imageURL,
 			&f.Position, &lastUpdated, &lastError, &f.DiscoveryCompleted, &scriptPath,
@@ -838,7 +1008,10 @@ func (db *DB) GetFeedsForUser(userID int64) ([]models.Feed, error) {
 			&xpathItemThumbnail, &xpathItemCategories, &xpathItemUid, &articleViewMode,
 			&autoExpandContent, &emailAddress, &emailIMAPServer, &f.EmailIMAPPort,
 			&emailUsername, &emailPassword, &emailFolder, &f.EmailLastUID,
-			&f.IsFreshRSSSource, &freshRSSStreamID, &translateArticles, &latestArticleTimeStr, &f.ArticlesPerMonth,
+			&f.IsFreshRSSSource, &freshRSSStreamID, &translateArticles,
+			&f.IsPaused, &pauseReason, &f.ConsecutiveFailures, &nextRetryAt, &httpAuth, &f.FetchFullText,
+			&f.EmailListID, &f.EmailUnsubscribe, &f.EmailSenders, &pagination, &f.WatchIgnore,
+			&latestArticleTimeStr, &f.ArticlesPerMonth,
 		); err != nil {
 			return nil, err
 		}
@@ -891,6 +1064,12 @@ func (db *DB) GetFeedsForUser(userID int64) ([]models.Feed, error) {
 			f.EmailIMAPPort = 993
 		}
 		f.FreshRSSStreamID = freshRSSStreamID.String
+		f.PauseReason = pauseReason.String
+		if nextRetryAt.Valid {
+			f.NextRetryAt = &nextRetryAt.Time
+		}
+		f.Auth = decodeFeedAuth(httpAuth.String)
+		f.Pagination = decodeFeedPagination(pagination.String)
 
 		// Set latest article time from string
 		// Format from database: "2025-11-15 18:39:02 +0000 UTC" (Go's time.String() format)
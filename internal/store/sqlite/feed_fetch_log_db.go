@@ -0,0 +1,342 @@
+package sqlite
+
+import (
+	"database/sql"
+	"math"
+	"sort"
+	"time"
+)
+
+// Error classes of failed fetches
+const (
+	FetchErrorTimeout    = "timeout"    // The fetch timed out or was cancelled
+	FetchErrorDNS        = "dns"        // The host name could not be resolved
+	FetchErrorConnection = "connection" // The connection failed or was reset
+	FetchErrorTLS        = "tls"        // The TLS handshake or certificate check failed
+	FetchErrorHTTP       = "http"       // The server answered with an error status
+	FetchErrorParse      = "parse"      // The response is not a valid feed
+	FetchErrorScript     = "script"     // The custom script failed
+	FetchErrorOther      = "other"      // Any other error
+)
+
+// FeedFetchLogRetention is how long fetches are kept in the fetch log
+const FeedFetchLogRetention = 30 * 24 * time.Hour
+
+// FeedFetchLog is an entry of the fetch log, recorded for every fetch of a feed
+type FeedFetchLog struct {
+	ID           int64     `json:"id"`
+	FeedID       int64     `json:"feed_id"`
+	UserID       int64     `json:"user_id"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+	DurationMs   int64     `json:"duration_ms"`
+	HTTPStatus   int       `json:"http_status"`   // 0 when unknown, e.g. for scripts and email feeds
+	Bytes        int64     `json:"bytes"`         // Size of the response body
+	NotModified  bool      `json:"not_modified"`  // The server answered 304 to the ETag or Last-Modified validators
+	Items        int       `json:"items"`         // Items in the fetched feed
+	NewItems     int       `json:"new_items"`     // Items saved as new articles, not counted in eco mode
+	UpdatedItems int       `json:"updated_items"` // Items that were already saved
+	ErrorClass   string    `json:"error_class"`   // Empty for successful fetches
+	Error        string    `json:"error"`
+	Proxy        string    `json:"proxy"` // Proxy used, without credentials
+}
+
+// FeedHealth summarizes the recent fetches of a feed
+type FeedHealth struct {
+	FeedID              int64      `json:"feed_id"`
+	FeedTitle           string     `json:"feed_title"`
+	Fetches             int        `json:"fetches"`
+	Failures            int        `json:"failures"`
+	SuccessRate         float64    `json:"success_rate"` // Between 0 and 1, or 1 without fetches
+	P95LatencyMs        int64      `json:"p95_latency_ms"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastFetchAt         *time.Time `json:"last_fetch_at"`
+	LastSuccessAt       *time.Time `json:"last_success_at"`
+	LastErrorClass      string     `json:"last_error_class"`
+	LastError           string     `json:"last_error"`
+	LastHTTPStatus      int        `json:"last_http_status"`
+}
+
+// FeedHealthSummary aggregates the health of feeds
+type FeedHealthSummary struct {
+	Feeds        int       `json:"feeds"`
+	FailingFeeds int       `json:"failing_feeds"` // Feeds whose last fetch failed
+	Fetches      int       `json:"fetches"`
+	Failures     int       `json:"failures"`
+	SuccessRate  float64   `json:"success_rate"`
+	P95LatencyMs int64     `json:"p95_latency_ms"`
+	Since        time.Time `json:"since"`
+}
+
+// InitFeedFetchLogTable creates the feed_fetch_log table if it doesn't exist.
+func InitFeedFetchLogTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS feed_fetch_log (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		feed_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL DEFAULT 1,
+		started_at DATETIME NOT NULL,
+		finished_at DATETIME NOT NULL,
+		duration_ms INTEGER NOT NULL DEFAULT 0,
+		http_status INTEGER NOT NULL DEFAULT 0,
+		bytes INTEGER NOT NULL DEFAULT 0,
+		not_modified BOOLEAN NOT NULL DEFAULT 0,
+		items INTEGER NOT NULL DEFAULT 0,
+		new_items INTEGER NOT NULL DEFAULT 0,
+		updated_items INTEGER NOT NULL DEFAULT 0,
+		error_class TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		proxy TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_feed_fetch_log_feed ON feed_fetch_log(feed_id, id);
+	CREATE INDEX IF NOT EXISTS idx_feed_fetch_log_user ON feed_fetch_log(user_id, started_at);
+	CREATE INDEX IF NOT EXISTS idx_feed_fetch_log_started ON feed_fetch_log(started_at);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// AddFeedFetchLog records a fetch. Entries older than FeedFetchLogRetention are pruned.
+func (db *DB) AddFeedFetchLog(l *FeedFetchLog) error {
+	db.WaitForReady()
+	if l.UserID == 0 {
+		l.UserID = 1
+	}
+	res, err := db.Exec(`
+		INSERT INTO feed_fetch_log (feed_id, user_id, started_at, finished_at, duration_ms, http_status, bytes,
+			not_modified, items, new_items, updated_items, error_class, error, proxy)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, l.FeedID, l.UserID, l.StartedAt.UTC(), l.FinishedAt.UTC(), l.DurationMs, l.HTTPStatus, l.Bytes,
+		l.NotModified, l.Items, l.NewItems, l.UpdatedItems, l.ErrorClass, l.Error, l.Proxy)
+	if err != nil {
+		return err
+	}
+	if l.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	_, _ = db.Exec(`DELETE FROM feed_fetch_log WHERE started_at < ?`, time.Now().UTC().Add(-FeedFetchLogRetention))
+	return nil
+}
+
+// GetFeedFetchLogs returns the most recent fetches of a user, newest first.
+// userID 0 returns the fetches of all users, and feedID 0 those of all feeds.
+func (db *DB) GetFeedFetchLogs(userID, feedID int64, limit, offset int) ([]FeedFetchLog, error) {
+	db.WaitForReady()
+	query := `
+		SELECT id, feed_id, user_id, started_at, finished_at, duration_ms, http_status, bytes,
+			not_modified, items, new_items, updated_items, error_class, error, proxy
+		FROM feed_fetch_log WHERE 1 = 1`
+	var args []interface{}
+	if userID > 0 {
+		query += ` AND user_id = ?`
+		args = append(args, userID)
+	}
+	if feedID > 0 {
+		query += ` AND feed_id = ?`
+		args = append(args, feedID)
+	}
+	query += ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := make([]FeedFetchLog, 0)
+	for rows.Next() {
+		var l FeedFetchLog
+		if err := rows.Scan(&l.ID, &l.FeedID, &l.UserID, &l.StartedAt, &l.FinishedAt, &l.DurationMs, &l.HTTPStatus, &l.Bytes,
+			&l.NotModified, &l.Items, &l.NewItems, &l.UpdatedItems, &l.ErrorClass, &l.Error, &l.Proxy); err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, rows.Err()
+}
+
+// GetFeedHealth returns the health of the feeds of a user, computed from the fetches since
+// the given time. Consecutive failures and the last successful fetch are computed from the
+// whole fetch log. userID 0 returns the feeds of all users, and feedID 0 all feeds.
+func (db *DB) GetFeedHealth(userID, feedID int64, since time.Time) ([]FeedHealth, FeedHealthSummary, error) {
+	db.WaitForReady()
+	summary := FeedHealthSummary{Since: since, SuccessRate: 1}
+
+	feedQuery := `SELECT id, title FROM feeds WHERE 1 = 1`
+	var feedArgs []interface{}
+	if userID > 0 {
+		feedQuery += ` AND user_id = ?`
+		feedArgs = append(feedArgs, userID)
+	}
+	if feedID > 0 {
+		feedQuery += ` AND id = ?`
+		feedArgs = append(feedArgs, feedID)
+	}
+	feedQuery += ` ORDER BY id`
+
+	rows, err := db.Query(feedQuery, feedArgs...)
+	if err != nil {
+		return nil, summary, err
+	}
+	health := make([]FeedHealth, 0)
+	index := make(map[int64]int)
+	for rows.Next() {
+		var h FeedHealth
+		if err := rows.Scan(&h.FeedID, &h.FeedTitle); err != nil {
+			rows.Close()
+			return nil, summary, err
+		}
+		h.SuccessRate = 1
+		index[h.FeedID] = len(health)
+		health = append(health, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, summary, err
+	}
+	if len(health) == 0 {
+		return health, summary, nil
+	}
+
+	filter, filterArgs := fetchLogFilter(userID, feedID)
+
+	// Fetches of the window, newest first
+	rows, err = db.Query(`
+		SELECT feed_id, started_at, duration_ms, http_status, error_class, error
+		FROM feed_fetch_log l WHERE l.started_at >= ?`+filter+` ORDER BY l.id DESC`,
+		append([]interface{}{since.UTC()}, filterArgs...)...)
+	if err != nil {
+		return nil, summary, err
+	}
+	latencies := make(map[int64][]int64)
+	var allLatencies []int64
+	for rows.Next() {
+		var id int64
+		var startedAt time.Time
+		var durationMs int64
+		var httpStatus int
+		var errorClass, errMsg string
+		if err := rows.Scan(&id, &startedAt, &durationMs, &httpStatus, &errorClass, &errMsg); err != nil {
+			rows.Close()
+			return nil, summary, err
+		}
+		i, ok := index[id]
+		if !ok {
+			continue
+		}
+		h := &health[i]
+		if h.Fetches == 0 {
+			h.LastFetchAt = &startedAt
+			h.LastErrorClass = errorClass
+			h.LastError = errMsg
+			h.LastHTTPStatus = httpStatus
+		}
+		h.Fetches++
+		if errorClass != "" {
+			h.Failures++
+		}
+		latencies[id] = append(latencies[id], durationMs)
+		allLatencies = append(allLatencies, durationMs)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, summary, err
+	}
+
+	// Last successful fetches and failures since then, over the whole log
+	lastSuccess := `SELECT feed_id, MAX(id) AS id FROM feed_fetch_log l WHERE l.error_class = ''` + filter + ` GROUP BY feed_id`
+	rows, err = db.Query(`
+		SELECT l.feed_id, l.started_at FROM feed_fetch_log l
+		JOIN (`+lastSuccess+`) s ON s.id = l.id`, filterArgs...)
+	if err != nil {
+		return nil, summary, err
+	}
+	for rows.Next() {
+		var id int64
+		var startedAt time.Time
+		if err := rows.Scan(&id, &startedAt); err != nil {
+			rows.Close()
+			return nil, summary, err
+		}
+		if i, ok := index[id]; ok {
+			health[i].LastSuccessAt = &startedAt
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, summary, err
+	}
+
+	rows, err = db.Query(`
+		SELECT l.feed_id, COUNT(*) FROM feed_fetch_log l
+		LEFT JOIN (`+lastSuccess+`) s ON s.feed_id = l.feed_id
+		WHERE l.id > COALESCE(s.id, 0)`+filter+` GROUP BY l.feed_id`, append(filterArgs, filterArgs...)...)
+	if err != nil {
+		return nil, summary, err
+	}
+	for rows.Next() {
+		var id int64
+		var consecutive int
+		if err := rows.Scan(&id, &consecutive); err != nil {
+			rows.Close()
+			return nil, summary, err
+		}
+		if i, ok := index[id]; ok {
+			health[i].ConsecutiveFailures = consecutive
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, summary, err
+	}
+
+	summary.Feeds = len(health)
+	for i := range health {
+		h := &health[i]
+		if h.Fetches > 0 {
+			h.SuccessRate = float64(h.Fetches-h.Failures) / float64(h.Fetches)
+			h.P95LatencyMs = percentile(latencies[h.FeedID], 0.95)
+		}
+		if h.ConsecutiveFailures > 0 {
+			summary.FailingFeeds++
+		}
+		summary.Fetches += h.Fetches
+		summary.Failures += h.Failures
+	}
+	if summary.Fetches > 0 {
+		summary.SuccessRate = float64(summary.Fetches-summary.Failures) / float64(summary.Fetches)
+		summary.P95LatencyMs = percentile(allLatencies, 0.95)
+	}
+	return health, summary, nil
+}
+
+// fetchLogFilter returns the conditions restricting fetch log entries, aliased l, to a user and feed
+func fetchLogFilter(userID, feedID int64) (string, []interface{}) {
+	var filter string
+	var args []interface{}
+	if userID > 0 {
+		filter += ` AND l.user_id = ?`
+		args = append(args, userID)
+	}
+	if feedID > 0 {
+		filter += ` AND l.feed_id = ?`
+		args = append(args, feedID)
+	}
+	return filter, args
+}
+
+// percentile returns the p-th percentile of values using the nearest-rank method
+func percentile(values []int64, p float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
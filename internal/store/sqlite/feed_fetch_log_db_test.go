@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"MavenRSS/internal/models"
+)
+
+func TestFeedHealth(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+
+	healthyID, _ := db.AddFeed(&models.Feed{Title: "Healthy", URL: "http://example.com/a"})
+	brokenID, _ := db.AddFeed(&models.Feed{Title: "Broken", URL: "http://example.com/b"})
+
+	now := time.Now()
+	add := func(feedID int64, ago time.Duration, durationMs int64, errorClass string) {
+		t.Helper()
+		started := now.Add(-ago)
+		if err := db.AddFeedFetchLog(&FeedFetchLog{FeedID: feedID, StartedAt: started,
+			FinishedAt: started.Add(time.Duration(durationMs) * time.Millisecond), DurationMs: durationMs, ErrorClass: errorClass}); err != nil {
+			t.Fatalf("AddFeedFetchLog: %v", err)
+		}
+	}
+	for i := 1; i <= 20; i++ {
+		add(healthyID, time.Duration(21-i)*time.Hour, int64(i*100), "")
+	}
+	add(brokenID, 10*24*time.Hour, 50, "")
+	add(brokenID, 3*time.Hour, 500, FetchErrorHTTP)
+	add(brokenID, 2*time.Hour, 700, FetchErrorTimeout)
+	// Entries older than the retention are pruned
+	add(brokenID, 40*24*time.Hour, 50, "")
+
+	feeds, summary, err := db.GetFeedHealth(1, 0, now.Add(-7*24*time.Hour))
+	if err != nil || len(feeds) != 2 {
+		t.Fatalf("GetFeedHealth = %+v, %v", feeds, err)
+	}
+	healthy, broken := feeds[0], feeds[1]
+	if healthy.Fetches != 20 || healthy.SuccessRate != 1 || healthy.P95LatencyMs != 1900 || healthy.ConsecutiveFailures != 0 {
+		t.Errorf("unexpected healthy feed %+v", healthy)
+	}
+	if broken.Fetches != 2 || broken.SuccessRate != 0 || broken.ConsecutiveFailures != 2 || broken.LastErrorClass != FetchErrorTimeout {
+		t.Errorf("unexpected broken feed %+v", broken)
+	}
+	if broken.LastSuccessAt == nil || now.Sub(*broken.LastSuccessAt) < 9*24*time.Hour {
+		t.Errorf("unexpected last success %v", broken.LastSuccessAt)
+	}
+	if summary.Feeds != 2 || summary.FailingFeeds != 1 || summary.Fetches != 22 || summary.Failures != 2 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	logs, err := db.GetFeedFetchLogs(1, brokenID, 10, 0)
+	if err != nil || len(logs) != 3 || logs[0].ErrorClass != FetchErrorTimeout {
+		t.Fatalf("GetFeedFetchLogs = %+v, %v", logs, err)
+	}
+}
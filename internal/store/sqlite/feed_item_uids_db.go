@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// maxUIDsPerQuery keeps the UIDs of a query under the SQLite variable limit
+const maxUIDsPerQuery = 500
+
+// InitFeedItemUIDsTable creates the feed_item_uids table if it doesn't exist.
+// It holds the UIDs of the items already read from paginated XPath feeds, whose
+// pagination stops at the first page with a known item, and of the messages imported
+// into mail archive feeds.
+func InitFeedItemUIDsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS feed_item_uids (
+		feed_id INTEGER NOT NULL,
+		uid TEXT NOT NULL,
+		seen_at DATETIME NOT NULL,
+		PRIMARY KEY (feed_id, uid)
+	);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// GetKnownItemUIDs returns which of the given item UIDs of a feed were already read.
+func (db *DB) GetKnownItemUIDs(feedID int64, uids []string) (map[string]bool, error) {
+	db.WaitForReady()
+	known := make(map[string]bool)
+	for start := 0; start < len(uids); start += maxUIDsPerQuery {
+		batch := uids[start:min(start+maxUIDsPerQuery, len(uids))]
+		args := make([]interface{}, 0, len(batch)+1)
+		args = append(args, feedID)
+		for _, uid := range batch {
+			args = append(args, uid)
+		}
+		rows, err := db.Query(`SELECT uid FROM feed_item_uids WHERE feed_id = ? AND uid IN (?`+strings.Repeat(", ?", len(batch)-1)+`)`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var uid string
+			if err := rows.Scan(&uid); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			known[uid] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return known, nil
+}
+
+// AddKnownItemUIDs records item UIDs of a feed as read.
+func (db *DB) AddKnownItemUIDs(feedID int64, uids []string) error {
+	db.WaitForReady()
+	if len(uids) == 0 {
+		return nil
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO feed_item_uids (feed_id, uid, seen_at) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, uid := range uids {
+		if _, err := stmt.Exec(feedID, uid, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Reasons of feed URL changes
+const (
+	FeedURLChangeMoved      = "moved"      // The server answered with a permanent redirect (301 or 308)
+	FeedURLChangeDiscovered = "discovered" // The feed was found again on the website's homepage
+)
+
+// FeedURLChange is an audit entry recorded whenever the URL of a feed is changed automatically
+type FeedURLChange struct {
+	ID         int64     `json:"id"`
+	FeedID     int64     `json:"feed_id"`
+	UserID     int64     `json:"user_id"`
+	OldURL     string    `json:"old_url"`
+	NewURL     string    `json:"new_url"`
+	Reason     string    `json:"reason"`
+	HTTPStatus int       `json:"http_status"` // Status of the redirect, 0 for discovered URLs
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// InitFeedURLChangesTable creates the feed_url_changes table if it doesn't exist.
+func InitFeedURLChangesTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS feed_url_changes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		feed_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL DEFAULT 1,
+		old_url TEXT NOT NULL,
+		new_url TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		http_status INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_feed_url_changes_feed ON feed_url_changes(feed_id, id);
+	CREATE INDEX IF NOT EXISTS idx_feed_url_changes_user ON feed_url_changes(user_id, id);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// ChangeFeedURL replaces the URL of a feed and records the change in the audit log.
+// The caching validators of the old URL are cleared.
+func (db *DB) ChangeFeedURL(id int64, newURL, reason string, httpStatus int) error {
+	db.WaitForReady()
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var userID int64
+	var oldURL string
+	if err := tx.QueryRow("SELECT user_id, url FROM feeds WHERE id = ?", id).Scan(&userID, &oldURL); err != nil {
+		return err
+	}
+	if oldURL == newURL {
+		return nil
+	}
+	if _, err := tx.Exec("UPDATE feeds SET url = ?, etag = '', last_modified = '' WHERE id = ?", newURL, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO feed_url_changes (feed_id, user_id, old_url, new_url, reason, http_status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, id, userID, oldURL, newURL, reason, httpStatus, time.Now().UTC()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetFeedURLChanges returns the URL changes of the feeds of a user, newest first.
+// userID 0 returns the changes of all users, and feedID 0 those of all feeds.
+func (db *DB) GetFeedURLChanges(userID, feedID int64, limit int) ([]FeedURLChange, error) {
+	db.WaitForReady()
+	query := `
+		SELECT id, feed_id, user_id, old_url, new_url, reason, http_status, created_at
+		FROM feed_url_changes WHERE 1 = 1`
+	var args []interface{}
+	if userID > 0 {
+		query += ` AND user_id = ?`
+		args = append(args, userID)
+	}
+	if feedID > 0 {
+		query += ` AND feed_id = ?`
+		args = append(args, feedID)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]FeedURLChange, 0)
+	for rows.Next() {
+		var c FeedURLChange
+		if err := rows.Scan(&c.ID, &c.FeedID, &c.UserID, &c.OldURL, &c.NewURL, &c.Reason, &c.HTTPStatus, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+
+	"MavenRSS/internal/models"
+)
+
+// FeverItemQuery describes a Fever "items" request.
+// At most one of SinceID, MaxID and WithIDs is expected to be set.
+type FeverItemQuery struct {
+	SinceID int64   // Items with an ID greater than this, oldest first (since_id)
+	MaxID   int64   // Items with an ID lower than this, newest first (max_id)
+	WithIDs []int64 // Explicit list of item IDs (with_ids)
+	Limit   int
+}
+
+// InitFeverTable creates the fever_api_keys table if it doesn't exist.
+// API keys live in their own table rather than user_settings so they are never
+// copied when a user inherits settings from the template user.
+func InitFeverTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS fever_api_keys (
+		user_id INTEGER PRIMARY KEY,
+		api_key TEXT NOT NULL UNIQUE,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// SetFeverAPIKey stores (or replaces) the Fever API key of a user.
+func (db *DB) SetFeverAPIKey(userID int64, apiKey string) error {
+	db.WaitForReady()
+	_, err := db.Exec(`INSERT OR REPLACE INTO fever_api_keys (user_id, api_key, created_at) VALUES (?, ?, ?)`,
+		userID, strings.ToLower(apiKey), time.Now())
+	return err
+}
+
+// DeleteFeverAPIKey removes the Fever API key of a user, disabling Fever access.
+func (db *DB) DeleteFeverAPIKey(userID int64) error {
+	db.WaitForReady()
+	_, err := db.Exec(`DELETE FROM fever_api_keys WHERE user_id = ?`, userID)
+	return err
+}
+
+// HasFeverAPIKey reports whether the user has Fever access configured.
+func (db *DB) HasFeverAPIKey(userID int64) (bool, error) {
+	db.WaitForReady()
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM fever_api_keys WHERE user_id = ?`, userID).Scan(&count)
+	return count > 0, err
+}
+
+// GetUserIDByFeverAPIKey returns the active user owning the given Fever API key.
+// Returns sql.ErrNoRows if the key is unknown or the user is not active.
+func (db *DB) GetUserIDByFeverAPIKey(apiKey string) (int64, error) {
+	db.WaitForReady()
+	var userID int64
+	err := db.QueryRow(`
+		SELECT k.user_id FROM fever_api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.api_key = ? AND u.status = 'active'
+	`, strings.ToLower(apiKey)).Scan(&userID)
+	return userID, err
+}
+
+// GetFeverItemsForUser returns articles for a Fever "items" request.
+func (db *DB) GetFeverItemsForUser(userID int64, q FeverItemQuery) ([]models.Article, error) {
+	db.WaitForReady()
+
+	query := `
+		SELECT a.id, a.feed_id, a.title, a.url, a.published_at, a.is_read, a.is_favorite, a.summary, a.author
+		FROM articles a
+		WHERE a.user_id = ? AND a.is_hidden = 0`
+	args := []interface{}{userID}
+
+	switch {
+	case len(q.WithIDs) > 0:
+		placeholders := make([]string, len(q.WithIDs))
+		for i, id := range q.WithIDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		query += " AND a.id IN (" + strings.Join(placeholders, ",") + ") ORDER BY a.id ASC"
+	case q.MaxID > 0:
+		query += " AND a.id < ? ORDER BY a.id DESC"
+		args = append(args, q.MaxID)
+	default:
+		query += " AND a.id > ? ORDER BY a.id ASC"
+		args = append(args, q.SinceID)
+	}
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	articles := []models.Article{}
+	for rows.Next() {
+		var a models.Article
+		var summary, author sql.NullString
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &publishedAt, &a.IsRead, &a.IsFavorite, &summary, &author); err != nil {
+			log.Println("Error scanning fever item:", err)
+			continue
+		}
+		if publishedAt.Valid {
+			a.PublishedAt = publishedAt.Time
+		}
+		a.Summary = summary.String
+		a.Author = author.String
+		a.UserID = userID
+		articles = append(articles, a)
+	}
+	return articles, rows.Err()
+}
+
+// GetTotalArticleCount returns the number of visible articles of a user.
+func (db *DB) GetTotalArticleCount(userID int64) (int, error) {
+	db.WaitForReady()
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM articles WHERE user_id = ? AND is_hidden = 0`, userID).Scan(&count)
+	return count, err
+}
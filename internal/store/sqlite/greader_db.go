@@ -0,0 +1,220 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// StreamQuery describes a Google Reader stream request translated into article filters.
+// Zero values mean "no constraint" for every field.
+type StreamQuery struct {
+	FeedID      int64     // Restrict to a single feed ("feed/<id>")
+	Category    string    // Restrict to a category and its sub-categories ("user/-/label/<name>")
+	OnlyStarred bool      // Only favorite articles ("user/-/state/com.google/starred")
+	OnlyRead    bool      // Only read articles ("user/-/state/com.google/read")
+	ExcludeRead bool      // Exclude read articles (xt=user/-/state/com.google/read)
+	ExcludeStar bool      // Exclude favorite articles (xt=user/-/state/com.google/starred)
+	OlderThan   time.Time // Only articles published before this time (nt)
+	NewerThan   time.Time // Only articles published after this time (ot)
+	OldestFirst bool      // Sort ascending instead of newest first (r=o)
+	Limit       int
+	Offset      int
+}
+
+// StreamItemRef is a lightweight reference to an article in a stream.
+type StreamItemRef struct {
+	ID          int64
+	FeedID      int64
+	PublishedAt time.Time
+}
+
+// buildStreamWhere builds the WHERE clause and arguments for a stream query.
+func buildStreamWhere(userID int64, q StreamQuery) (string, []interface{}) {
+	whereClauses := []string{"a.user_id = ?", "a.is_hidden = 0"}
+	args := []interface{}{userID}
+
+	if q.FeedID > 0 {
+		whereClauses = append(whereClauses, "a.feed_id = ?")
+		args = append(args, q.FeedID)
+	}
+	if q.Category != "" {
+		whereClauses = append(whereClauses, "a.feed_id IN (SELECT id FROM feeds WHERE user_id = ? AND (category = ? OR category LIKE ?))")
+		args = append(args, userID, q.Category, q.Category+"/%")
+	}
+	if q.OnlyStarred {
+		whereClauses = append(whereClauses, "a.is_favorite = 1")
+	}
+	if q.OnlyRead {
+		whereClauses = append(whereClauses, "a.is_read = 1")
+	}
+	if q.ExcludeRead {
+		whereClauses = append(whereClauses, "a.is_read = 0")
+	}
+	if q.ExcludeStar {
+		whereClauses = append(whereClauses, "a.is_favorite = 0")
+	}
+	if !q.OlderThan.IsZero() {
+		whereClauses = append(whereClauses, "a.published_at < ?")
+		args = append(args, q.OlderThan.UTC())
+	}
+	if !q.NewerThan.IsZero() {
+		whereClauses = append(whereClauses, "a.published_at > ?")
+		args = append(args, q.NewerThan.UTC())
+	}
+
+	return " WHERE " + strings.Join(whereClauses, " AND "), args
+}
+
+// GetStreamItemRefsForUser returns article references matching a Google Reader stream query.
+func (db *DB) GetStreamItemRefsForUser(userID int64, q StreamQuery) ([]StreamItemRef, error) {
+	db.WaitForReady()
+
+	where, args := buildStreamWhere(userID, q)
+	query := "SELECT a.id, a.feed_id, a.published_at FROM articles a" + where
+	if q.OldestFirst {
+		query += " ORDER BY a.published_at ASC, a.id ASC"
+	} else {
+		query += " ORDER BY a.published_at DESC, a.id DESC"
+	}
+	if q.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, q.Limit, q.Offset)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query stream items: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []StreamItemRef
+	for rows.Next() {
+		var ref StreamItemRef
+		var feedID sql.NullInt64
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&ref.ID, &feedID, &publishedAt); err != nil {
+			log.Println("Error scanning stream item:", err)
+			continue
+		}
+		ref.FeedID = feedID.Int64
+		if publishedAt.Valid {
+			ref.PublishedAt = publishedAt.Time
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// FilterArticleIDsForUser returns the subset of ids that belong to the given user.
+// Order of the input slice is preserved.
+func (db *DB) FilterArticleIDsForUser(userID int64, ids []int64) ([]int64, error) {
+	db.WaitForReady()
+	if len(ids) == 0 {
+		return []int64{}, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, 0, len(ids)+1)
+	args = append(args, userID)
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+
+	rows, err := db.Query("SELECT id FROM articles WHERE user_id = ? AND id IN ("+strings.Join(placeholders, ",")+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	owned := make(map[int64]bool, len(ids))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		owned[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]int64, 0, len(owned))
+	for _, id := range ids {
+		if owned[id] {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+// GetArticleContentsByIDs returns cached article contents keyed by article ID.
+// Articles without cached content are omitted from the map.
+func (db *DB) GetArticleContentsByIDs(ids []int64) (map[int64]string, error) {
+	db.WaitForReady()
+	contents := make(map[int64]string, len(ids))
+	if len(ids) == 0 {
+		return contents, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	rows, err := db.Query("SELECT article_id, content FROM article_contents WHERE article_id IN ("+strings.Join(placeholders, ",")+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var content string
+		if err := rows.Scan(&id, &content); err != nil {
+			return nil, err
+		}
+		contents[id] = content
+	}
+	return contents, rows.Err()
+}
+
+// MarkStreamAsReadWithSyncForUser marks every unread article in a stream as read for a user
+// and returns sync requests for FreshRSS-sourced articles.
+func (db *DB) MarkStreamAsReadWithSyncForUser(userID int64, q StreamQuery) ([]SyncRequest, error) {
+	db.WaitForReady()
+
+	q.ExcludeRead = true
+	where, args := buildStreamWhere(userID, q)
+	rows, err := db.Query("SELECT a.id, a.url, a.feed_id FROM articles a"+where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var articles []articleInfo
+	for rows.Next() {
+		var info articleInfo
+		if err := rows.Scan(&info.id, &info.url, &info.feedID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		articles = append(articles, info)
+	}
+	rows.Close()
+
+	if len(articles) == 0 {
+		return nil, nil
+	}
+
+	_, err = db.Exec("UPDATE articles SET is_read = 1 WHERE id IN (SELECT a.id FROM articles a"+where+")", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.collectSyncRequests(articles), nil
+}
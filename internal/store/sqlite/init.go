@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"fmt"
+	"log"
 
 	"MavenRSS/internal/config"
 
@@ -36,6 +37,76 @@ func (db *DB) Init() error {
 			return
 		}
 
+		// Initialize article tags and highlights tables
+		if err = InitArticleAnnotationTables(db.DB); err != nil {
+			return
+		}
+
+		// Initialize near-duplicate fingerprints table
+		if err = InitArticleClusterTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize podcast episode and playback tables (indexed by the search index)
+		if err = InitPodcastTables(db.DB); err != nil {
+			return
+		}
+
+		// Initialize full-text search index
+		if err = InitArticleSearchTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize Fever API keys table
+		if err = InitFeverTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize WebSub subscriptions table
+		if err = InitWebSubTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize webhook delivery log table
+		if err = InitWebhookDeliveriesTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize output feeds table
+		if err = InitOutputFeedsTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize rules and rule executions tables
+		if err = InitRulesTables(db.DB); err != nil {
+			return
+		}
+
+		// Initialize full-text site rules table
+		if err = InitSiteRulesTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize feed fetch log table
+		if err = InitFeedFetchLogTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize feed URL changes table
+		if err = InitFeedURLChangesTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize email sync state table
+		if err = InitEmailSyncTable(db.DB); err != nil {
+			return
+		}
+
+		// Initialize known item UIDs table
+		if err = InitFeedItemUIDsTable(db.DB); err != nil {
+			return
+		}
+
 		// Create settings table if not exists
 		_, _ = db.Exec(`CREATE TABLE IF NOT EXISTS settings (
 			key TEXT PRIMARY KEY,
@@ -56,6 +127,11 @@ func (db *DB) Init() error {
 			_, _ = db.Exec(`INSERT INTO users (id, username, email, password_hash, role, status) VALUES (1, 'admin', 'admin@example.com', 'hash', 'admin', 'active')`)
 		}
 
+		// Move rules from the "rules" setting to the rules table
+		if migrateErr := migrateRulesFromSettings(db); migrateErr != nil {
+			log.Printf("Failed to migrate rules from settings: %v", migrateErr)
+		}
+
 		// Apply additional migrations
 		if err = applyAdditionalMigrations(db); err != nil {
 			return
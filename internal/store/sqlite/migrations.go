@@ -162,6 +162,37 @@ func runMigrations(db *sql.DB) error {
 	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN etag TEXT DEFAULT ''`)
 	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN last_modified TEXT DEFAULT ''`)
 
+	// Migration: Add is_pinned column to articles table for the rules "pin" action
+	_, _ = db.Exec(`ALTER TABLE articles ADD COLUMN is_pinned BOOLEAN DEFAULT 0`)
+
+	// Migration: Add failure tracking columns to feeds table for backoff and pausing gone feeds
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN is_paused BOOLEAN DEFAULT 0`)
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN pause_reason TEXT DEFAULT ''`)
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN consecutive_failures INTEGER DEFAULT 0`)
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN next_retry_at DATETIME`)
+
+	// Migration: Add http_auth column to feeds table for per-feed encrypted credentials
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN http_auth TEXT DEFAULT ''`)
+
+	// Migration: Add full text columns for fetching the full text of articles on ingest
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN fetch_full_text BOOLEAN DEFAULT 0`)
+	_, _ = db.Exec(`ALTER TABLE article_contents ADD COLUMN is_full_text BOOLEAN DEFAULT 0`)
+
+	// Migration: Add newsletter columns for feeds delivered by the built-in mail receiver
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN email_list_id TEXT DEFAULT ''`)
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN email_unsubscribe TEXT DEFAULT ''`)
+
+	// Migration: Add email_senders column for routing the messages of a mailbox to several feeds
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN email_senders TEXT DEFAULT ''`)
+
+	// Migration: Add pagination column for reading the next pages of JSON+JSONPath feeds
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN pagination TEXT DEFAULT ''`)
+
+	// Migration: Add watch columns for the ignore patterns and last snapshot of watched pages
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN watch_ignore TEXT DEFAULT ''`)
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN watch_hash TEXT DEFAULT ''`)
+	_, _ = db.Exec(`ALTER TABLE feeds ADD COLUMN watch_snapshot TEXT DEFAULT ''`)
+
 	return nil
 }
 
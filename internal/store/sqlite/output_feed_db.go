@@ -0,0 +1,165 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"MavenRSS/internal/models"
+)
+
+// Output feed sources
+const (
+	OutputSourceSavedFilter = "saved_filter"
+	OutputSourceTag         = "tag"
+	OutputSourceCategory    = "category"
+	OutputSourceFavorites   = "favorites"
+	OutputSourceReadLater   = "read_later"
+)
+
+// InitOutputFeedsTable creates the output_feeds table if it doesn't exist.
+func InitOutputFeedsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS output_feeds (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		token TEXT NOT NULL UNIQUE,
+		title TEXT NOT NULL DEFAULT '',
+		source_type TEXT NOT NULL,
+		source_value TEXT NOT NULL DEFAULT '',
+		include_translations BOOLEAN NOT NULL DEFAULT 0,
+		include_summaries BOOLEAN NOT NULL DEFAULT 0,
+		max_items INTEGER NOT NULL DEFAULT 50,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_output_feeds_user ON output_feeds(user_id);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+const outputFeedColumns = `id, user_id, token, title, source_type, source_value,
+	include_translations, include_summaries, max_items, created_at`
+
+func scanOutputFeed(scanner interface{ Scan(...interface{}) error }) (*models.OutputFeed, error) {
+	var f models.OutputFeed
+	var createdAt sql.NullTime
+	if err := scanner.Scan(&f.ID, &f.UserID, &f.Token, &f.Title, &f.SourceType, &f.SourceValue,
+		&f.IncludeTranslations, &f.IncludeSummaries, &f.MaxItems, &createdAt); err != nil {
+		return nil, err
+	}
+	f.CreatedAt = createdAt.Time
+	return &f, nil
+}
+
+// CreateOutputFeed stores a new output feed and returns its ID.
+func (db *DB) CreateOutputFeed(f *models.OutputFeed) (int64, error) {
+	db.WaitForReady()
+	if f.CreatedAt.IsZero() {
+		f.CreatedAt = time.Now()
+	}
+	res, err := db.Exec(`
+		INSERT INTO output_feeds (user_id, token, title, source_type, source_value,
+			include_translations, include_summaries, max_items, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, f.UserID, f.Token, f.Title, f.SourceType, f.SourceValue,
+		f.IncludeTranslations, f.IncludeSummaries, f.MaxItems, f.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// GetOutputFeedsForUser returns the output feeds of a user.
+func (db *DB) GetOutputFeedsForUser(userID int64) ([]models.OutputFeed, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT `+outputFeedColumns+` FROM output_feeds WHERE user_id = ? ORDER BY id ASC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := make([]models.OutputFeed, 0)
+	for rows.Next() {
+		f, err := scanOutputFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, *f)
+	}
+	return feeds, rows.Err()
+}
+
+// GetOutputFeedByToken returns the output feed with the given token.
+// Returns nil if the token is unknown or its owner is not active.
+func (db *DB) GetOutputFeedByToken(token string) (*models.OutputFeed, error) {
+	db.WaitForReady()
+	row := db.QueryRow(`
+		SELECT o.id, o.user_id, o.token, o.title, o.source_type, o.source_value,
+			o.include_translations, o.include_summaries, o.max_items, o.created_at
+		FROM output_feeds o
+		JOIN users u ON u.id = o.user_id
+		WHERE o.token = ? AND u.status = 'active'
+	`, token)
+	f, err := scanOutputFeed(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return f, err
+}
+
+// UpdateOutputFeedToken replaces the token of an output feed, revoking the old URL.
+// Returns sql.ErrNoRows if the feed doesn't belong to the user.
+func (db *DB) UpdateOutputFeedToken(userID, id int64, token string) error {
+	db.WaitForReady()
+	res, err := db.Exec(`UPDATE output_feeds SET token = ? WHERE id = ? AND user_id = ?`, token, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteOutputFeed deletes an output feed of a user.
+// Returns sql.ErrNoRows if the feed doesn't belong to the user.
+func (db *DB) DeleteOutputFeed(userID, id int64) error {
+	db.WaitForReady()
+	res, err := db.Exec(`DELETE FROM output_feeds WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetArticleIDsByTagForUser returns the IDs of a user's most recent visible
+// articles from feeds with the given tag, newest first.
+func (db *DB) GetArticleIDsByTagForUser(userID, tagID int64, limit int) ([]int64, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`
+		SELECT a.id FROM articles a
+		INNER JOIN feed_tags ft ON ft.feed_id = a.feed_id
+		WHERE ft.tag_id = ? AND a.user_id = ? AND a.is_hidden = 0
+		ORDER BY a.published_at DESC
+		LIMIT ?
+	`, tagID, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
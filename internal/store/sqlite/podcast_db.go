@@ -0,0 +1,259 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"MavenRSS/internal/models"
+)
+
+// InitPodcastTables creates the podcast_episodes and podcast_playback tables if they
+// don't exist. Both are keyed by article; a trigger removes episodes and listening
+// progress with their article, as foreign keys are not enforced on every connection.
+func InitPodcastTables(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS podcast_episodes (
+		article_id INTEGER PRIMARY KEY,
+		audio_url TEXT NOT NULL,
+		mime_type TEXT DEFAULT '',
+		size_bytes INTEGER DEFAULT 0,
+		duration_seconds INTEGER DEFAULT 0,
+		image_url TEXT DEFAULT '',
+		season INTEGER DEFAULT 0,
+		episode INTEGER DEFAULT 0,
+		episode_type TEXT DEFAULT '',
+		explicit BOOLEAN DEFAULT 0,
+		chapters_url TEXT DEFAULT '',
+		chapters TEXT DEFAULT '',
+		transcript_url TEXT DEFAULT '',
+		transcript_type TEXT DEFAULT '',
+		transcript TEXT DEFAULT '',
+		fetched_at DATETIME,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS podcast_playback (
+		user_id INTEGER NOT NULL,
+		article_id INTEGER NOT NULL,
+		position_seconds REAL NOT NULL DEFAULT 0,
+		duration_seconds REAL NOT NULL DEFAULT 0,
+		completed BOOLEAN DEFAULT 0,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, article_id),
+		FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_podcast_playback_updated ON podcast_playback(user_id, updated_at);
+
+	CREATE TRIGGER IF NOT EXISTS podcast_article_delete AFTER DELETE ON articles BEGIN
+		DELETE FROM podcast_episodes WHERE article_id = old.id;
+		DELETE FROM podcast_playback WHERE article_id = old.id;
+	END;
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// SavePodcastEpisode inserts or updates the podcast metadata of an article.
+// The fetched transcript and chapters are kept unless their URLs changed, in which
+// case the episode is marked for fetching again.
+func (db *DB) SavePodcastEpisode(ep *models.PodcastEpisode) error {
+	db.WaitForReady()
+
+	chapters, err := encodeChapters(ep.Chapters)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO podcast_episodes (article_id, audio_url, mime_type, size_bytes, duration_seconds, image_url,
+			season, episode, episode_type, explicit, chapters_url, chapters, transcript_url, transcript_type, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(article_id) DO UPDATE SET
+			audio_url = excluded.audio_url,
+			mime_type = excluded.mime_type,
+			size_bytes = excluded.size_bytes,
+			duration_seconds = excluded.duration_seconds,
+			image_url = excluded.image_url,
+			season = excluded.season,
+			episode = excluded.episode,
+			episode_type = excluded.episode_type,
+			explicit = excluded.explicit,
+			chapters = CASE
+				WHEN excluded.chapters != '' THEN excluded.chapters
+				WHEN podcast_episodes.chapters_url != excluded.chapters_url THEN ''
+				ELSE podcast_episodes.chapters END,
+			transcript = CASE
+				WHEN podcast_episodes.transcript_url != excluded.transcript_url THEN ''
+				ELSE podcast_episodes.transcript END,
+			fetched_at = CASE
+				WHEN podcast_episodes.transcript_url != excluded.transcript_url
+					OR podcast_episodes.chapters_url != excluded.chapters_url THEN NULL
+				ELSE podcast_episodes.fetched_at END,
+			chapters_url = excluded.chapters_url,
+			transcript_url = excluded.transcript_url,
+			transcript_type = excluded.transcript_type,
+			updated_at = CURRENT_TIMESTAMP`,
+		ep.ArticleID, ep.AudioURL, ep.MimeType, ep.SizeBytes, ep.DurationSeconds, ep.ImageURL,
+		ep.Season, ep.Episode, ep.EpisodeType, ep.Explicit, ep.ChaptersURL, chapters, ep.TranscriptURL, ep.TranscriptType)
+	return err
+}
+
+// GetPodcastEpisode returns the podcast metadata of an article, or nil if it has none.
+func (db *DB) GetPodcastEpisode(articleID int64) (*models.PodcastEpisode, error) {
+	db.WaitForReady()
+
+	var ep models.PodcastEpisode
+	var chapters string
+	var fetchedAt sql.NullTime
+	err := db.QueryRow(`
+		SELECT article_id, audio_url, COALESCE(mime_type, ''), COALESCE(size_bytes, 0), COALESCE(duration_seconds, 0),
+			COALESCE(image_url, ''), COALESCE(season, 0), COALESCE(episode, 0), COALESCE(episode_type, ''),
+			COALESCE(explicit, 0), COALESCE(chapters_url, ''), COALESCE(chapters, ''), COALESCE(transcript_url, ''),
+			COALESCE(transcript_type, ''), COALESCE(transcript, ''), fetched_at
+		FROM podcast_episodes WHERE article_id = ?`, articleID).Scan(
+		&ep.ArticleID, &ep.AudioURL, &ep.MimeType, &ep.SizeBytes, &ep.DurationSeconds,
+		&ep.ImageURL, &ep.Season, &ep.Episode, &ep.EpisodeType,
+		&ep.Explicit, &ep.ChaptersURL, &chapters, &ep.TranscriptURL,
+		&ep.TranscriptType, &ep.Transcript, &fetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if chapters != "" {
+		_ = json.Unmarshal([]byte(chapters), &ep.Chapters)
+	}
+	if fetchedAt.Valid {
+		ep.FetchedAt = &fetchedAt.Time
+	}
+	return &ep, nil
+}
+
+// GetPodcastTranscript returns the fetched transcript of an article, or "" if there is none.
+func (db *DB) GetPodcastTranscript(articleID int64) (string, error) {
+	db.WaitForReady()
+	var transcript string
+	err := db.QueryRow(`SELECT COALESCE(transcript, '') FROM podcast_episodes WHERE article_id = ?`, articleID).Scan(&transcript)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return transcript, err
+}
+
+// SetPodcastEpisodeResources stores the fetched transcript and chapters of an episode
+// and marks it as fetched. Nil chapters keep the current ones. The transcript is added
+// to the search index so episodes can be found by what is said in them.
+func (db *DB) SetPodcastEpisodeResources(articleID int64, transcript string, chapters []models.PodcastChapter) error {
+	db.WaitForReady()
+
+	query := `UPDATE podcast_episodes SET transcript = ?, fetched_at = ?`
+	args := []interface{}{transcript, time.Now()}
+	if chapters != nil {
+		encoded, err := encodeChapters(chapters)
+		if err != nil {
+			return err
+		}
+		query += `, chapters = ?`
+		args = append(args, encoded)
+	}
+	query += ` WHERE article_id = ?`
+	if _, err := db.Exec(query, append(args, articleID)...); err != nil {
+		return err
+	}
+
+	if transcript != "" {
+		db.indexArticles(articleID)
+	}
+	return nil
+}
+
+// SavePlaybackPosition records a user's listening progress for an episode.
+// Returns ErrArticleNotFound if the article doesn't belong to the user.
+func (db *DB) SavePlaybackPosition(userID int64, pos *models.PlaybackPosition) error {
+	db.WaitForReady()
+
+	var exists int
+	err := db.QueryRow(`SELECT 1 FROM articles WHERE id = ? AND user_id = ?`, pos.ArticleID, userID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return ErrArticleNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO podcast_playback (user_id, article_id, position_seconds, duration_seconds, completed, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(user_id, article_id) DO UPDATE SET
+			position_seconds = excluded.position_seconds,
+			duration_seconds = excluded.duration_seconds,
+			completed = excluded.completed,
+			updated_at = CURRENT_TIMESTAMP`,
+		userID, pos.ArticleID, pos.PositionSeconds, pos.DurationSeconds, pos.Completed)
+	return err
+}
+
+// GetPlaybackPosition returns a user's listening progress for an episode, or nil if
+// the user never played it.
+func (db *DB) GetPlaybackPosition(userID, articleID int64) (*models.PlaybackPosition, error) {
+	db.WaitForReady()
+	pos, err := scanPlaybackPosition(db.QueryRow(`
+		SELECT article_id, position_seconds, duration_seconds, completed, updated_at
+		FROM podcast_playback WHERE user_id = ? AND article_id = ?`, userID, articleID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return pos, err
+}
+
+// GetInProgressPlayback returns the episodes a user started but did not finish,
+// most recently played first.
+func (db *DB) GetInProgressPlayback(userID int64, limit int) ([]models.PlaybackPosition, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`
+		SELECT article_id, position_seconds, duration_seconds, completed, updated_at
+		FROM podcast_playback
+		WHERE user_id = ? AND completed = 0 AND position_seconds > 0
+		ORDER BY updated_at DESC
+		LIMIT ?`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	positions := []models.PlaybackPosition{}
+	for rows.Next() {
+		pos, err := scanPlaybackPosition(rows)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, *pos)
+	}
+	return positions, rows.Err()
+}
+
+func scanPlaybackPosition(row interface{ Scan(...interface{}) error }) (*models.PlaybackPosition, error) {
+	var pos models.PlaybackPosition
+	var updatedAt sql.NullTime
+	if err := row.Scan(&pos.ArticleID, &pos.PositionSeconds, &pos.DurationSeconds, &pos.Completed, &updatedAt); err != nil {
+		return nil, err
+	}
+	pos.UpdatedAt = updatedAt.Time
+	return &pos, nil
+}
+
+// encodeChapters serializes chapters for storage, "" when there are none
+func encodeChapters(chapters []models.PodcastChapter) (string, error) {
+	if len(chapters) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(chapters)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
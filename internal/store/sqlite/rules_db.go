@@ -0,0 +1,397 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+)
+
+// Rule execution sources
+const (
+	RuleSourceFetch    = "fetch"    // Applied to newly fetched articles
+	RuleSourceManual   = "manual"   // Applied on request
+	RuleSourceSchedule = "schedule" // Re-applied by the nightly schedule
+)
+
+// maxRuleExecutions is the number of executions kept in the log
+const maxRuleExecutions = 20000
+
+// RuleRecord is an automation rule as stored in the rules table.
+// The conditions, actions and webhook are JSON documents interpreted by the rules package.
+type RuleRecord struct {
+	UserID          int64
+	ID              int64
+	Name            string
+	Enabled         bool
+	Position        int
+	Query           string
+	Conditions      string // JSON array of conditions
+	Actions         string // JSON array of action names
+	Webhook         string // JSON webhook configuration, empty if none
+	Schedule        string // "" or "nightly"
+	LastScheduledAt time.Time
+}
+
+// RuleExecution is an entry of the rule execution log
+type RuleExecution struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	RuleID    int64     `json:"rule_id"`
+	RuleName  string    `json:"rule_name"`
+	ArticleID int64     `json:"article_id"`
+	Action    string    `json:"action"`
+	Source    string    `json:"source"` // "fetch", "manual" or "schedule"
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InitRulesTables creates the rules and rule_executions tables if they don't exist.
+func InitRulesTables(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS rules (
+		user_id INTEGER NOT NULL,
+		id INTEGER NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		position INTEGER NOT NULL DEFAULT 0,
+		query TEXT NOT NULL DEFAULT '',
+		conditions TEXT NOT NULL DEFAULT '[]',
+		actions TEXT NOT NULL DEFAULT '[]',
+		webhook TEXT NOT NULL DEFAULT '',
+		schedule TEXT NOT NULL DEFAULT '',
+		last_scheduled_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, id)
+	);
+
+	CREATE TABLE IF NOT EXISTS rule_executions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		rule_id INTEGER NOT NULL,
+		rule_name TEXT NOT NULL DEFAULT '',
+		article_id INTEGER NOT NULL,
+		action TEXT NOT NULL,
+		source TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_rule_executions_rule ON rule_executions(user_id, rule_id, id);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// migrateRulesFromSettings moves the rules stored as a JSON setting to the rules table.
+// A user's own "rules" setting takes precedence over the global one, which used to
+// apply to everyone. The settings are cleared afterwards.
+func migrateRulesFromSettings(db *DB) error {
+	var global string
+	_ = db.QueryRow(`SELECT value FROM settings WHERE key = 'rules'`).Scan(&global)
+
+	perUser := make(map[int64]string)
+	rows, err := db.Query(`SELECT user_id, value FROM user_settings WHERE key = 'rules' AND value != ''`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var userID int64
+		var value string
+		if err := rows.Scan(&userID, &value); err != nil {
+			rows.Close()
+			return err
+		}
+		perUser[userID] = value
+	}
+	rows.Close()
+
+	if strings.TrimSpace(global) == "" && len(perUser) == 0 {
+		return nil
+	}
+
+	users, err := db.Query(`SELECT id FROM users`)
+	if err != nil {
+		return err
+	}
+	var userIDs []int64
+	for users.Next() {
+		var id int64
+		if err := users.Scan(&id); err == nil {
+			userIDs = append(userIDs, id)
+		}
+	}
+	users.Close()
+
+	for _, userID := range userIDs {
+		value, ok := perUser[userID]
+		if !ok {
+			value = global
+		}
+		records, err := parseRuleRecords(userID, value)
+		if err != nil {
+			log.Printf("Skipping rules of user %d: %v", userID, err)
+			continue
+		}
+		if len(records) == 0 {
+			continue
+		}
+		if err := db.replaceRules(userID, records); err != nil {
+			return err
+		}
+		log.Printf("Migrated %d rules of user %d to the rules table", len(records), userID)
+	}
+
+	_, _ = db.Exec(`UPDATE settings SET value = '' WHERE key = 'rules'`)
+	_, _ = db.Exec(`DELETE FROM user_settings WHERE key = 'rules'`)
+	return nil
+}
+
+// parseRuleRecords converts rules in the JSON format of the "rules" setting to records
+func parseRuleRecords(userID int64, rulesJSON string) ([]RuleRecord, error) {
+	if strings.TrimSpace(rulesJSON) == "" {
+		return nil, nil
+	}
+
+	var rules []struct {
+		ID         int64           `json:"id"`
+		Name       string          `json:"name"`
+		Enabled    bool            `json:"enabled"`
+		Position   int             `json:"position"`
+		Query      string          `json:"query"`
+		Conditions json.RawMessage `json:"conditions"`
+		Actions    json.RawMessage `json:"actions"`
+		Webhook    json.RawMessage `json:"webhook"`
+		Schedule   string          `json:"schedule"`
+	}
+	if err := json.Unmarshal([]byte(rulesJSON), &rules); err != nil {
+		return nil, err
+	}
+
+	records := make([]RuleRecord, len(rules))
+	for i, r := range rules {
+		records[i] = RuleRecord{
+			UserID:     userID,
+			ID:         r.ID,
+			Name:       r.Name,
+			Enabled:    r.Enabled,
+			Position:   r.Position,
+			Query:      r.Query,
+			Conditions: rawJSONOr(r.Conditions, "[]"),
+			Actions:    rawJSONOr(r.Actions, "[]"),
+			Webhook:    rawJSONOr(r.Webhook, ""),
+			Schedule:   r.Schedule,
+		}
+	}
+	return records, nil
+}
+
+// rawJSONOr returns a raw JSON value as a string, or def when it's missing or null
+func rawJSONOr(raw json.RawMessage, def string) string {
+	if s := strings.TrimSpace(string(raw)); s != "" && s != "null" {
+		return s
+	}
+	return def
+}
+
+// GetRulesForUser retrieves the rules of a user in execution order.
+func (db *DB) GetRulesForUser(userID int64) ([]RuleRecord, error) {
+	db.WaitForReady()
+	return db.queryRules(`WHERE user_id = ? ORDER BY position ASC, id ASC`, userID)
+}
+
+// GetScheduledRules retrieves the enabled rules of all users that have a schedule.
+func (db *DB) GetScheduledRules() ([]RuleRecord, error) {
+	db.WaitForReady()
+	return db.queryRules(`WHERE schedule != '' AND enabled = 1 ORDER BY user_id ASC, position ASC, id ASC`)
+}
+
+func (db *DB) queryRules(where string, args ...interface{}) ([]RuleRecord, error) {
+	rows, err := db.Query(`
+		SELECT user_id, id, name, enabled, position, query, conditions, actions, webhook, schedule, last_scheduled_at
+		FROM rules `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]RuleRecord, 0)
+	for rows.Next() {
+		var r RuleRecord
+		var lastScheduledAt sql.NullTime
+		if err := rows.Scan(&r.UserID, &r.ID, &r.Name, &r.Enabled, &r.Position, &r.Query, &r.Conditions, &r.Actions,
+			&r.Webhook, &r.Schedule, &lastScheduledAt); err != nil {
+			return nil, err
+		}
+		r.LastScheduledAt = lastScheduledAt.Time
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// SaveRuleForUser creates or updates a rule. A rule without an ID gets the next free ID
+// of the user, which is stored in r.ID.
+func (db *DB) SaveRuleForUser(r *RuleRecord) error {
+	db.WaitForReady()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if r.ID == 0 {
+		if err := tx.QueryRow(`SELECT COALESCE(MAX(id), 0) + 1 FROM rules WHERE user_id = ?`, r.UserID).Scan(&r.ID); err != nil {
+			return err
+		}
+	}
+	if err := upsertRule(tx, r); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplaceRulesForUser replaces all rules of a user. Rules keeping their ID keep their
+// schedule state.
+func (db *DB) ReplaceRulesForUser(userID int64, records []RuleRecord) error {
+	db.WaitForReady()
+	return db.replaceRules(userID, records)
+}
+
+// replaceRules replaces all rules of a user without waiting for the database to be
+// ready, for the migrations run by Init
+func (db *DB) replaceRules(userID int64, records []RuleRecord) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	keep := make([]string, 0, len(records))
+	args := []interface{}{userID}
+	for i := range records {
+		r := &records[i]
+		r.UserID = userID
+		if r.ID == 0 {
+			if err := tx.QueryRow(`SELECT COALESCE(MAX(id), 0) + 1 FROM rules WHERE user_id = ?`, userID).Scan(&r.ID); err != nil {
+				return err
+			}
+		}
+		if err := upsertRule(tx, r); err != nil {
+			return err
+		}
+		keep = append(keep, "?")
+		args = append(args, r.ID)
+	}
+
+	query := `DELETE FROM rules WHERE user_id = ?`
+	if len(keep) > 0 {
+		query += ` AND id NOT IN (` + strings.Join(keep, ",") + `)`
+	}
+	if _, err := tx.Exec(query, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func upsertRule(tx *sql.Tx, r *RuleRecord) error {
+	_, err := tx.Exec(`
+		INSERT INTO rules (user_id, id, name, enabled, position, query, conditions, actions, webhook, schedule)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, id) DO UPDATE SET
+			name = excluded.name, enabled = excluded.enabled, position = excluded.position, query = excluded.query,
+			conditions = excluded.conditions, actions = excluded.actions, webhook = excluded.webhook,
+			schedule = excluded.schedule, updated_at = CURRENT_TIMESTAMP`,
+		r.UserID, r.ID, r.Name, r.Enabled, r.Position, r.Query, r.Conditions, r.Actions, r.Webhook, r.Schedule)
+	return err
+}
+
+// DeleteRuleForUser deletes a rule. Returns sql.ErrNoRows if the user has no such rule.
+func (db *DB) DeleteRuleForUser(userID, id int64) error {
+	db.WaitForReady()
+	res, err := db.Exec(`DELETE FROM rules WHERE user_id = ? AND id = ?`, userID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetRuleScheduledAt records when a scheduled rule was last applied.
+func (db *DB) SetRuleScheduledAt(userID, id int64, at time.Time) error {
+	db.WaitForReady()
+	_, err := db.Exec(`UPDATE rules SET last_scheduled_at = ? WHERE user_id = ? AND id = ?`, at, userID, id)
+	return err
+}
+
+// AddRuleExecutions adds entries to the execution log.
+// The oldest entries are pruned so the log stays bounded.
+func (db *DB) AddRuleExecutions(executions []RuleExecution) error {
+	if len(executions) == 0 {
+		return nil
+	}
+	db.WaitForReady()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO rule_executions (user_id, rule_id, rule_name, article_id, action, source, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	var lastID int64
+	for _, e := range executions {
+		res, err := stmt.Exec(e.UserID, e.RuleID, e.RuleName, e.ArticleID, e.Action, e.Source, now)
+		if err != nil {
+			return err
+		}
+		lastID, _ = res.LastInsertId()
+	}
+	if _, err := tx.Exec(`DELETE FROM rule_executions WHERE id <= ?`, lastID-maxRuleExecutions); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetRuleExecutions returns the most recent executions of a user, optionally restricted to a rule.
+func (db *DB) GetRuleExecutions(userID, ruleID int64, limit, offset int) ([]RuleExecution, error) {
+	db.WaitForReady()
+	query := `
+		SELECT id, user_id, rule_id, rule_name, article_id, action, source, created_at
+		FROM rule_executions WHERE user_id = ?`
+	args := []interface{}{userID}
+	if ruleID > 0 {
+		query += ` AND rule_id = ?`
+		args = append(args, ruleID)
+	}
+	query += ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	executions := make([]RuleExecution, 0)
+	for rows.Next() {
+		var e RuleExecution
+		var createdAt sql.NullTime
+		if err := rows.Scan(&e.ID, &e.UserID, &e.RuleID, &e.RuleName, &e.ArticleID, &e.Action, &e.Source, &createdAt); err != nil {
+			return nil, err
+		}
+		e.CreatedAt = createdAt.Time
+		executions = append(executions, e)
+	}
+	return executions, rows.Err()
+}
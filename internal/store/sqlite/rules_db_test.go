@@ -0,0 +1,137 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRulesStorage(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+
+	// Rules of the legacy setting are moved to the table of every user
+	if _, err := db.Exec(`INSERT INTO users (id, username, email, password_hash, role, status) VALUES (2, 'bob', 'bob@example.com', 'hash', 'user', 'active')`); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if err := db.SetSetting("rules", `[{"id":5,"name":"Old","enabled":true,"conditions":[{"field":"article_title","value":"x"}],"actions":["favorite"]}]`); err != nil {
+		t.Fatalf("SetSetting: %v", err)
+	}
+	if err := db.SetSettingForUser(2, "rules", `[{"id":9,"name":"Own","enabled":false,"query":"read","actions":["hide"],"schedule":"nightly"}]`); err != nil {
+		t.Fatalf("SetSettingForUser: %v", err)
+	}
+	if err := migrateRulesFromSettings(db); err != nil {
+		t.Fatalf("migrateRulesFromSettings: %v", err)
+	}
+	if value, _ := db.GetSetting("rules"); value != "" {
+		t.Errorf("rules setting not cleared: %q", value)
+	}
+
+	rules, err := db.GetRulesForUser(1)
+	if err != nil || len(rules) != 1 || rules[0].ID != 5 || rules[0].Actions != `["favorite"]` || rules[0].Webhook != "" {
+		t.Fatalf("rules of user 1: %+v (%v)", rules, err)
+	}
+	rules, err = db.GetRulesForUser(2)
+	if err != nil || len(rules) != 1 || rules[0].Name != "Own" || rules[0].Schedule != "nightly" || rules[0].Conditions != "[]" {
+		t.Fatalf("rules of user 2: %+v (%v)", rules, err)
+	}
+
+	// Only enabled rules are scheduled
+	scheduled, err := db.GetScheduledRules()
+	if err != nil || len(scheduled) != 0 {
+		t.Fatalf("GetScheduledRules: %+v (%v)", scheduled, err)
+	}
+
+	// Replacing keeps the schedule state of kept rules, and deletes the others
+	at := time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC)
+	if err := db.SetRuleScheduledAt(1, 5, at); err != nil {
+		t.Fatalf("SetRuleScheduledAt: %v", err)
+	}
+	if err := db.ReplaceRulesForUser(1, []RuleRecord{
+		{ID: 5, Name: "Renamed", Enabled: true, Conditions: "[]", Actions: `["hide"]`, Schedule: "nightly"},
+		{Name: "New", Enabled: true, Position: 1, Conditions: "[]", Actions: "[]"},
+	}); err != nil {
+		t.Fatalf("ReplaceRulesForUser: %v", err)
+	}
+	rules, err = db.GetRulesForUser(1)
+	if err != nil || len(rules) != 2 {
+		t.Fatalf("rules after replace: %+v (%v)", rules, err)
+	}
+	if rules[0].Name != "Renamed" || !rules[0].LastScheduledAt.Equal(at) {
+		t.Errorf("kept rule: %+v", rules[0])
+	}
+	if rules[1].ID != 6 {
+		t.Errorf("new rule got ID %d, want 6", rules[1].ID)
+	}
+	scheduled, err = db.GetScheduledRules()
+	if err != nil || len(scheduled) != 1 || scheduled[0].ID != 5 {
+		t.Fatalf("GetScheduledRules: %+v (%v)", scheduled, err)
+	}
+
+	if err := db.DeleteRuleForUser(2, 5); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("deleting another user's rule: %v", err)
+	}
+	if err := db.DeleteRuleForUser(1, 6); err != nil {
+		t.Errorf("DeleteRuleForUser: %v", err)
+	}
+
+	// Executions are listed newest first, per user and rule
+	if err := db.AddRuleExecutions([]RuleExecution{
+		{UserID: 1, RuleID: 5, RuleName: "Renamed", ArticleID: 10, Action: "hide", Source: RuleSourceFetch},
+		{UserID: 1, RuleID: 6, RuleName: "New", ArticleID: 11, Action: "hide", Source: RuleSourceManual},
+		{UserID: 2, RuleID: 9, RuleName: "Own", ArticleID: 12, Action: "hide", Source: RuleSourceSchedule},
+	}); err != nil {
+		t.Fatalf("AddRuleExecutions: %v", err)
+	}
+	executions, err := db.GetRuleExecutions(1, 0, 10, 0)
+	if err != nil || len(executions) != 2 || executions[0].ArticleID != 11 || executions[0].CreatedAt.IsZero() {
+		t.Fatalf("executions of user 1: %+v (%v)", executions, err)
+	}
+	executions, err = db.GetRuleExecutions(1, 5, 10, 0)
+	if err != nil || len(executions) != 1 || executions[0].Source != RuleSourceFetch {
+		t.Fatalf("executions of rule 5: %+v (%v)", executions, err)
+	}
+}
+
+func TestInitMigratesRulesSetting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+	if err := db.SetSetting("rules", `[{"id":1,"name":"r","enabled":true,"conditions":[],"actions":["favorite"]}]`); err != nil {
+		t.Fatalf("SetSetting: %v", err)
+	}
+	db.Close()
+
+	// Reopening an install with rules in its settings migrates them during Init
+	db, err = NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	defer db.Close()
+	done := make(chan error, 1)
+	go func() { done <- db.Init() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Init error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Init did not return")
+	}
+	rules, err := db.GetRulesForUser(1)
+	if err != nil || len(rules) != 1 || rules[0].Name != "r" {
+		t.Fatalf("migrated rules: %+v (%v)", rules, err)
+	}
+}
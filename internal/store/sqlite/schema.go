@@ -95,6 +95,7 @@ func initSchema(db *sql.DB) error {
 		is_favorite BOOLEAN DEFAULT 0,
 		is_hidden BOOLEAN DEFAULT 0,
 		is_read_later BOOLEAN DEFAULT 0,
+		is_pinned BOOLEAN DEFAULT 0,
 		summary TEXT DEFAULT '',
 		unique_id TEXT,
 		UNIQUE(user_id, unique_id),
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SiteRuleRecord is a full-text extraction rule of a site as stored in the site_rules table.
+// The config is a JSON document interpreted by the fulltext package.
+type SiteRuleRecord struct {
+	UserID    int64
+	ID        int64
+	Host      string
+	Config    string // JSON extraction rules
+	TestURL   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// InitSiteRulesTable creates the site_rules table if it doesn't exist.
+func InitSiteRulesTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS site_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		host TEXT NOT NULL,
+		config TEXT NOT NULL DEFAULT '{}',
+		test_url TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(user_id, host)
+	);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// GetSiteRulesForUser retrieves the site rules of a user, ordered by host.
+func (db *DB) GetSiteRulesForUser(userID int64) ([]SiteRuleRecord, error) {
+	db.WaitForReady()
+	return db.querySiteRules(`WHERE user_id = ? ORDER BY host ASC`, userID)
+}
+
+func (db *DB) querySiteRules(where string, args ...interface{}) ([]SiteRuleRecord, error) {
+	rows, err := db.Query(`
+		SELECT user_id, id, host, config, test_url, created_at, updated_at
+		FROM site_rules `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]SiteRuleRecord, 0)
+	for rows.Next() {
+		var r SiteRuleRecord
+		var createdAt, updatedAt sql.NullTime
+		if err := rows.Scan(&r.UserID, &r.ID, &r.Host, &r.Config, &r.TestURL, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		r.CreatedAt = createdAt.Time
+		r.UpdatedAt = updatedAt.Time
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// SaveSiteRuleForUser creates or updates a site rule. A rule without an ID replaces the
+// rule of the same host, if any; its ID is stored in r.ID. Returns sql.ErrNoRows if the
+// user has no rule with the given ID.
+func (db *DB) SaveSiteRuleForUser(r *SiteRuleRecord) error {
+	db.WaitForReady()
+
+	if r.ID == 0 {
+		return db.QueryRow(`
+			INSERT INTO site_rules (user_id, host, config, test_url)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(user_id, host) DO UPDATE SET
+				config = excluded.config, test_url = excluded.test_url, updated_at = CURRENT_TIMESTAMP
+			RETURNING id`,
+			r.UserID, r.Host, r.Config, r.TestURL).Scan(&r.ID)
+	}
+
+	res, err := db.Exec(`
+		UPDATE site_rules SET host = ?, config = ?, test_url = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = ? AND id = ?`,
+		r.Host, r.Config, r.TestURL, r.UserID, r.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteSiteRuleForUser deletes a site rule. Returns sql.ErrNoRows if the user has no such rule.
+func (db *DB) DeleteSiteRuleForUser(userID, id int64) error {
+	db.WaitForReady()
+	res, err := db.Exec(`DELETE FROM site_rules WHERE user_id = ? AND id = ?`, userID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
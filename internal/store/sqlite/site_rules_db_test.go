@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestSiteRulesStorage(t *testing.T) {
+	db, err := NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("NewDB error: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init error: %v", err)
+	}
+
+	first := SiteRuleRecord{UserID: 1, Host: "example.com", Config: `{"body":["//article"]}`}
+	if err := db.SaveSiteRuleForUser(&first); err != nil || first.ID == 0 {
+		t.Fatalf("SaveSiteRuleForUser: %v (id %d)", err, first.ID)
+	}
+
+	// A new rule of the same host replaces the existing one
+	again := SiteRuleRecord{UserID: 1, Host: "example.com", Config: `{"body":["//main"]}`, TestURL: "https://example.com/a"}
+	if err := db.SaveSiteRuleForUser(&again); err != nil || again.ID != first.ID {
+		t.Fatalf("SaveSiteRuleForUser of the same host: %v (id %d, want %d)", err, again.ID, first.ID)
+	}
+	other := SiteRuleRecord{UserID: 2, Host: "example.com", Config: `{}`}
+	if err := db.SaveSiteRuleForUser(&other); err != nil || other.ID == first.ID {
+		t.Fatalf("SaveSiteRuleForUser of another user: %v", err)
+	}
+
+	rules, err := db.GetSiteRulesForUser(1)
+	if err != nil || len(rules) != 1 || rules[0].Config != `{"body":["//main"]}` || rules[0].TestURL != "https://example.com/a" {
+		t.Fatalf("GetSiteRulesForUser: %+v (%v)", rules, err)
+	}
+
+	// Rules are only updated and deleted by their owner
+	other.UserID = 1
+	if err := db.SaveSiteRuleForUser(&other); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("update of another user's rule: %v, want sql.ErrNoRows", err)
+	}
+	if err := db.DeleteSiteRuleForUser(1, other.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("delete of another user's rule: %v, want sql.ErrNoRows", err)
+	}
+	if err := db.DeleteSiteRuleForUser(1, first.ID); err != nil {
+		t.Errorf("DeleteSiteRuleForUser: %v", err)
+	}
+	if rules, _ := db.GetSiteRulesForUser(1); len(rules) != 0 {
+		t.Errorf("rules left after delete: %+v", rules)
+	}
+}
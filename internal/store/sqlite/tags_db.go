@@ -85,7 +85,8 @@ func (db *DB) UpdateTag(id int64, name, color string, position int) error {
 }
 
 // DeleteTag deletes a tag by ID.
-// Note: ON DELETE CASCADE will automatically remove feed_tags associations.
+// Note: ON DELETE CASCADE will automatically remove feed_tags associations,
+// article_tags associations are removed by a trigger.
 func (db *DB) DeleteTag(id int64) error {
 	db.WaitForReady()
 
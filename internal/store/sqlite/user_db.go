@@ -107,6 +107,8 @@ func (db *DB) DeleteUser(id int64) error {
 	_, _ = tx.Exec(`DELETE FROM chat_sessions WHERE user_id = ?`, id)
 	_, _ = tx.Exec(`DELETE FROM article_contents WHERE article_id IN (SELECT id FROM articles WHERE user_id = ?)`, id)
 	_, _ = tx.Exec(`DELETE FROM articles WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM email_sync_state WHERE feed_id IN (SELECT id FROM feeds WHERE user_id = ?)`, id)
+	_, _ = tx.Exec(`DELETE FROM feed_item_uids WHERE feed_id IN (SELECT id FROM feeds WHERE user_id = ?)`, id)
 	_, _ = tx.Exec(`DELETE FROM feeds WHERE user_id = ?`, id)
 	_, _ = tx.Exec(`DELETE FROM saved_filters WHERE user_id = ?`, id)
 	_, _ = tx.Exec(`DELETE FROM tags WHERE user_id = ?`, id)
@@ -114,6 +116,19 @@ func (db *DB) DeleteUser(id int64) error {
 	_, _ = tx.Exec(`DELETE FROM user_settings WHERE user_id = ?`, id)
 	_, _ = tx.Exec(`DELETE FROM user_quota WHERE user_id = ?`, id)
 	_, _ = tx.Exec(`DELETE FROM user_sessions WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM fever_api_keys WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM output_feeds WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM podcast_playback WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM article_tags WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM article_highlights WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM article_fingerprint_bands WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM article_fingerprints WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM rules WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM rule_executions WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM webhook_deliveries WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM site_rules WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM feed_fetch_log WHERE user_id = ?`, id)
+	_, _ = tx.Exec(`DELETE FROM feed_url_changes WHERE user_id = ?`, id)
 
 	// Finally delete the user
 	_, err = tx.Exec(`DELETE FROM users WHERE id = ?`, id)
@@ -0,0 +1,132 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Webhook delivery states
+const (
+	WebhookStatusPending   = "pending"   // Queued or waiting for a retry
+	WebhookStatusDelivered = "delivered" // The endpoint answered with a 2xx status
+	WebhookStatusFailed    = "failed"    // All attempts failed
+)
+
+// maxWebhookDeliveries is the number of deliveries kept in the log
+const maxWebhookDeliveries = 5000
+
+// WebhookDelivery is an entry of the webhook delivery log
+type WebhookDelivery struct {
+	ID           int64     `json:"id"`
+	UserID       int64     `json:"user_id"`
+	RuleID       int64     `json:"rule_id"`
+	RuleName     string    `json:"rule_name"`
+	ArticleID    int64     `json:"article_id"`
+	URL          string    `json:"url"`
+	Payload      string    `json:"payload"`
+	Status       string    `json:"status"`
+	Attempts     int       `json:"attempts"`
+	ResponseCode int       `json:"response_code"`
+	ResponseBody string    `json:"response_body"`
+	Error        string    `json:"error"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// InitWebhookDeliveriesTable creates the webhook_deliveries table if it doesn't exist.
+func InitWebhookDeliveriesTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL DEFAULT 1,
+		rule_id INTEGER NOT NULL DEFAULT 0,
+		rule_name TEXT NOT NULL DEFAULT '',
+		article_id INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		payload TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		response_code INTEGER NOT NULL DEFAULT 0,
+		response_body TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+	// Deliveries logged before they had an owner belong to the default user
+	_, _ = db.Exec(`ALTER TABLE webhook_deliveries ADD COLUMN user_id INTEGER NOT NULL DEFAULT 1`)
+	_, _ = db.Exec(`DROP INDEX IF EXISTS idx_webhook_deliveries_rule`)
+	_, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_user ON webhook_deliveries(user_id, rule_id, id)`)
+	return err
+}
+
+// CreateWebhookDelivery adds a pending delivery to the log and returns its ID.
+// The oldest entries are pruned so the log stays bounded.
+func (db *DB) CreateWebhookDelivery(d *WebhookDelivery) (int64, error) {
+	db.WaitForReady()
+	now := time.Now()
+	res, err := db.Exec(`
+		INSERT INTO webhook_deliveries (user_id, rule_id, rule_name, article_id, url, payload, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, d.UserID, d.RuleID, d.RuleName, d.ArticleID, d.URL, d.Payload, WebhookStatusPending, now, now)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	_, _ = db.Exec(`DELETE FROM webhook_deliveries WHERE id <= ?`, id-maxWebhookDeliveries)
+	return id, nil
+}
+
+// UpdateWebhookDelivery records the outcome of a delivery attempt.
+func (db *DB) UpdateWebhookDelivery(id int64, status string, attempts int, responseCode int, responseBody string, errMsg string) error {
+	db.WaitForReady()
+	_, err := db.Exec(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, response_code = ?, response_body = ?, error = ?, updated_at = ?
+		WHERE id = ?
+	`, status, attempts, responseCode, responseBody, errMsg, time.Now(), id)
+	return err
+}
+
+// GetWebhookDeliveries returns the most recent deliveries of a user, optionally restricted to a rule.
+func (db *DB) GetWebhookDeliveries(userID, ruleID int64, limit, offset int) ([]WebhookDelivery, error) {
+	db.WaitForReady()
+	query := `
+		SELECT id, user_id, rule_id, rule_name, article_id, url, payload, status, attempts,
+			response_code, response_body, error, created_at, updated_at
+		FROM webhook_deliveries WHERE user_id = ?`
+	args := []interface{}{userID}
+	if ruleID > 0 {
+		query += ` AND rule_id = ?`
+		args = append(args, ruleID)
+	}
+	query += ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]WebhookDelivery, 0)
+	for rows.Next() {
+		var d WebhookDelivery
+		var createdAt, updatedAt sql.NullTime
+		if err := rows.Scan(&d.ID, &d.UserID, &d.RuleID, &d.RuleName, &d.ArticleID, &d.URL, &d.Payload, &d.Status, &d.Attempts,
+			&d.ResponseCode, &d.ResponseBody, &d.Error, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		d.CreatedAt = createdAt.Time
+		d.UpdatedAt = updatedAt.Time
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
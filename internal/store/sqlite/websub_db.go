@@ -0,0 +1,211 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+)
+
+// WebSub subscription states
+const (
+	WebSubStatePending       = "pending"       // Subscription requested, waiting for the hub to verify it
+	WebSubStateActive        = "active"        // Verified by the hub, content is pushed until the lease expires
+	WebSubStateDenied        = "denied"        // The hub refused the subscription
+	WebSubStateFailed        = "failed"        // The subscription request failed or was never verified
+	WebSubStateExpired       = "expired"       // The lease lapsed without being renewed
+	WebSubStateUnsubscribing = "unsubscribing" // Unsubscription requested, waiting for the hub to verify it
+)
+
+// WebSubSubscription is the WebSub (PubSubHubbub) subscription of a feed.
+type WebSubSubscription struct {
+	FeedID        int64
+	HubURL        string
+	TopicURL      string
+	CallbackToken string // Random path segment identifying the callback URL
+	Secret        string // Shared secret used to sign pushed content (hub.secret)
+	State         string
+	LeaseSeconds  int
+	ExpiresAt     time.Time
+	LastPushAt    time.Time
+	LastError     string
+	UpdatedAt     time.Time
+}
+
+// InitWebSubTable creates the websub_subscriptions table if it doesn't exist.
+func InitWebSubTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS websub_subscriptions (
+		feed_id INTEGER PRIMARY KEY,
+		hub_url TEXT NOT NULL,
+		topic_url TEXT NOT NULL,
+		callback_token TEXT NOT NULL UNIQUE,
+		secret TEXT NOT NULL,
+		state TEXT NOT NULL DEFAULT 'pending',
+		lease_seconds INTEGER NOT NULL DEFAULT 0,
+		expires_at DATETIME,
+		last_push_at DATETIME,
+		last_error TEXT DEFAULT '',
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_websub_state_expires ON websub_subscriptions(state, expires_at);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+const webSubColumns = `feed_id, hub_url, topic_url, callback_token, secret, state, lease_seconds,
+	expires_at, last_push_at, COALESCE(last_error, ''), updated_at`
+
+func scanWebSubSubscription(row interface{ Scan(...interface{}) error }) (*WebSubSubscription, error) {
+	var sub WebSubSubscription
+	var expiresAt, lastPushAt, updatedAt sql.NullTime
+	if err := row.Scan(&sub.FeedID, &sub.HubURL, &sub.TopicURL, &sub.CallbackToken, &sub.Secret, &sub.State,
+		&sub.LeaseSeconds, &expiresAt, &lastPushAt, &sub.LastError, &updatedAt); err != nil {
+		return nil, err
+	}
+	sub.ExpiresAt = expiresAt.Time
+	sub.LastPushAt = lastPushAt.Time
+	sub.UpdatedAt = updatedAt.Time
+	return &sub, nil
+}
+
+// GetWebSubSubscription returns the subscription of a feed, or nil if there is none.
+func (db *DB) GetWebSubSubscription(feedID int64) (*WebSubSubscription, error) {
+	db.WaitForReady()
+	sub, err := scanWebSubSubscription(db.QueryRow(`SELECT `+webSubColumns+` FROM websub_subscriptions WHERE feed_id = ?`, feedID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return sub, err
+}
+
+// GetWebSubSubscriptionByToken returns the subscription owning a callback token, or nil if there is none.
+func (db *DB) GetWebSubSubscriptionByToken(token string) (*WebSubSubscription, error) {
+	db.WaitForReady()
+	sub, err := scanWebSubSubscription(db.QueryRow(`SELECT `+webSubColumns+` FROM websub_subscriptions WHERE callback_token = ?`, token))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return sub, err
+}
+
+// SaveWebSubSubscription creates or replaces the subscription of a feed.
+func (db *DB) SaveWebSubSubscription(sub *WebSubSubscription) error {
+	db.WaitForReady()
+	var expiresAt interface{}
+	if !sub.ExpiresAt.IsZero() {
+		expiresAt = sub.ExpiresAt
+	}
+	_, err := db.Exec(`
+		INSERT INTO websub_subscriptions (feed_id, hub_url, topic_url, callback_token, secret, state, lease_seconds, expires_at, last_error, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(feed_id) DO UPDATE SET
+			hub_url = excluded.hub_url,
+			topic_url = excluded.topic_url,
+			callback_token = excluded.callback_token,
+			secret = excluded.secret,
+			state = excluded.state,
+			lease_seconds = excluded.lease_seconds,
+			expires_at = excluded.expires_at,
+			last_error = excluded.last_error,
+			updated_at = excluded.updated_at
+	`, sub.FeedID, sub.HubURL, sub.TopicURL, sub.CallbackToken, sub.Secret, sub.State, sub.LeaseSeconds, expiresAt, sub.LastError, time.Now())
+	return err
+}
+
+// ActivateWebSubSubscription marks a subscription as verified for the given lease.
+func (db *DB) ActivateWebSubSubscription(feedID int64, leaseSeconds int) error {
+	db.WaitForReady()
+	now := time.Now()
+	_, err := db.Exec(`
+		UPDATE websub_subscriptions
+		SET state = ?, lease_seconds = ?, expires_at = ?, last_error = '', updated_at = ?
+		WHERE feed_id = ?
+	`, WebSubStateActive, leaseSeconds, now.Add(time.Duration(leaseSeconds)*time.Second), now, feedID)
+	return err
+}
+
+// UpdateWebSubSubscriptionState changes the state of a subscription and records the last error.
+func (db *DB) UpdateWebSubSubscriptionState(feedID int64, state, lastError string) error {
+	db.WaitForReady()
+	_, err := db.Exec(`UPDATE websub_subscriptions SET state = ?, last_error = ?, updated_at = ? WHERE feed_id = ?`,
+		state, lastError, time.Now(), feedID)
+	return err
+}
+
+// UpdateWebSubLastPush records that content was pushed for a feed.
+func (db *DB) UpdateWebSubLastPush(feedID int64) error {
+	db.WaitForReady()
+	_, err := db.Exec(`UPDATE websub_subscriptions SET last_push_at = ? WHERE feed_id = ?`, time.Now(), feedID)
+	return err
+}
+
+// DeleteWebSubSubscription removes the subscription of a feed.
+func (db *DB) DeleteWebSubSubscription(feedID int64) error {
+	db.WaitForReady()
+	_, err := db.Exec(`DELETE FROM websub_subscriptions WHERE feed_id = ?`, feedID)
+	return err
+}
+
+// GetWebSubSubscriptionsExpiringBefore returns active subscriptions whose lease ends before the given time.
+func (db *DB) GetWebSubSubscriptionsExpiringBefore(before time.Time) ([]WebSubSubscription, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT `+webSubColumns+` FROM websub_subscriptions WHERE state = ? AND expires_at < ?`,
+		WebSubStateActive, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []WebSubSubscription
+	for rows.Next() {
+		sub, err := scanWebSubSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+	return subs, rows.Err()
+}
+
+// ExpireWebSubSubscriptions marks lapsed leases as expired and pending requests
+// the hub never verified as failed, so the affected feeds are polled again.
+func (db *DB) ExpireWebSubSubscriptions(now time.Time, pendingTimeout time.Duration) (int64, error) {
+	db.WaitForReady()
+	res, err := db.Exec(`UPDATE websub_subscriptions SET state = ?, updated_at = ? WHERE state = ? AND expires_at < ?`,
+		WebSubStateExpired, now, WebSubStateActive, now)
+	if err != nil {
+		return 0, err
+	}
+	expired, _ := res.RowsAffected()
+
+	res, err = db.Exec(`UPDATE websub_subscriptions SET state = ?, last_error = ?, updated_at = ? WHERE state IN (?, ?) AND updated_at < ?`,
+		WebSubStateFailed, "hub did not verify the request", now, WebSubStatePending, WebSubStateUnsubscribing, now.Add(-pendingTimeout))
+	if err != nil {
+		return expired, err
+	}
+	failed, _ := res.RowsAffected()
+	return expired + failed, nil
+}
+
+// GetPushActiveFeedIDs returns the IDs of feeds with an active, unexpired subscription.
+func (db *DB) GetPushActiveFeedIDs() (map[int64]bool, error) {
+	db.WaitForReady()
+	rows, err := db.Query(`SELECT feed_id FROM websub_subscriptions WHERE state = ? AND expires_at > ?`,
+		WebSubStateActive, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
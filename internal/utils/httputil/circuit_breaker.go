@@ -0,0 +1,170 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCircuitFailureThreshold is the number of consecutive failures opening the circuit of a host
+	DefaultCircuitFailureThreshold = 5
+	// DefaultCircuitOpenDuration is how long requests to a host fail fast once its circuit is open
+	DefaultCircuitOpenDuration = 5 * time.Minute
+)
+
+// ErrCircuitOpen is returned for requests to a host whose circuit is open
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitOpenError is returned instead of sending a request to a failing host
+type CircuitOpenError struct {
+	Host  string
+	Until time.Time // When a request will be let through again to probe the host
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%v: %s is failing, retrying after %s", ErrCircuitOpen, e.Host, e.Until.Format(time.RFC3339))
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// CircuitBreaker tracks the consecutive failures of hosts (host:port). After FailureThreshold
+// failures the circuit of a host opens and requests fail fast for OpenDuration,
+// then a single probe request is let through: its success closes the circuit,
+// its failure opens it again.
+type CircuitBreaker struct {
+	FailureThreshold int
+	OpenDuration     time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostCircuit
+}
+
+type hostCircuit struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// NewCircuitBreaker creates a circuit breaker
+func NewCircuitBreaker(failureThreshold int, openDuration time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureThreshold: failureThreshold,
+		OpenDuration:     openDuration,
+		hosts:            make(map[string]*hostCircuit),
+	}
+}
+
+var (
+	hostCircuitBreaker     *CircuitBreaker
+	hostCircuitBreakerOnce sync.Once
+)
+
+// GetCircuitBreaker returns the circuit breaker shared by the pooled clients
+func GetCircuitBreaker() *CircuitBreaker {
+	hostCircuitBreakerOnce.Do(func() {
+		hostCircuitBreaker = NewCircuitBreaker(DefaultCircuitFailureThreshold, DefaultCircuitOpenDuration)
+	})
+	return hostCircuitBreaker
+}
+
+// Allow returns a *CircuitOpenError when requests to the host must fail fast
+func (b *CircuitBreaker) Allow(host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.hosts[host]
+	if c == nil || c.failures < b.FailureThreshold {
+		return nil
+	}
+	if time.Now().Before(c.openUntil) || c.probing {
+		return &CircuitOpenError{Host: host, Until: c.openUntil}
+	}
+	// Half open: let a single request through to probe the host
+	c.probing = true
+	return nil
+}
+
+// Record records the outcome of a request to the host
+func (b *CircuitBreaker) Record(host string, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.hosts[host]
+	if success {
+		if c != nil {
+			if c.failures >= b.FailureThreshold {
+				log.Printf("[CircuitBreaker] Closing circuit of %s", host)
+			}
+			delete(b.hosts, host)
+		}
+		return
+	}
+
+	if c == nil {
+		c = &hostCircuit{}
+		b.hosts[host] = c
+	}
+	c.failures++
+	c.probing = false
+	if c.failures >= b.FailureThreshold {
+		c.openUntil = time.Now().Add(b.OpenDuration)
+		log.Printf("[CircuitBreaker] Opening circuit of %s after %d consecutive failures", host, c.failures)
+	}
+}
+
+// releaseProbe lets another request probe the host after a cancelled probe
+func (b *CircuitBreaker) releaseProbe(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c := b.hosts[host]; c != nil {
+		c.probing = false
+	}
+}
+
+// OpenUntil returns when the circuit of a host lets requests through again,
+// or the zero time when it is closed
+func (b *CircuitBreaker) OpenUntil(host string) time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c := b.hosts[host]; c != nil && c.failures >= b.FailureThreshold {
+		return c.openUntil
+	}
+	return time.Time{}
+}
+
+// CircuitBreakerTransport wraps http.RoundTripper to fail fast for failing hosts.
+// Transport errors and 5xx responses count as failures, cancelled requests don't.
+type CircuitBreakerTransport struct {
+	Original http.RoundTripper
+	Breaker  *CircuitBreaker
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *CircuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.ToLower(req.URL.Host)
+	if err := t.Breaker.Allow(host); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.Original.RoundTrip(req)
+	switch {
+	case err != nil && (errors.Is(err, context.Canceled) || req.Context().Err() == context.Canceled):
+		// Not the host's fault
+		t.Breaker.releaseProbe(host)
+	case err != nil:
+		t.Breaker.Record(host, false)
+	default:
+		t.Breaker.Record(host, resp.StatusCode < http.StatusInternalServerError)
+	}
+	return resp, err
+}
@@ -97,7 +97,7 @@ func (p *ClientPool) GetClient(proxyURL string, timeout time.Duration) *http.Cli
 		}
 		p.mu.Unlock()
 		return &http.Client{
-			Transport: pc.transport,
+			Transport: withCircuitBreaker(pc.transport),
 			Timeout:   timeout,
 		}
 	}
@@ -111,7 +111,7 @@ func (p *ClientPool) GetClient(proxyURL string, timeout time.Duration) *http.Cli
 			p.clientLRU.MoveToFront(elem)
 		}
 		return &http.Client{
-			Transport: pc.transport,
+			Transport: withCircuitBreaker(pc.transport),
 			Timeout:   timeout,
 		}
 	}
@@ -129,7 +129,7 @@ func (p *ClientPool) GetClient(proxyURL string, timeout time.Duration) *http.Cli
 	elem := p.clientLRU.PushFront(key)
 	p.clientKeys[key] = elem
 	return &http.Client{
-		Transport: transport,
+		Transport: withCircuitBreaker(transport),
 		Timeout:   timeout,
 	}
 }
@@ -196,7 +196,7 @@ func (p *ClientPool) GetUserAgentClient(proxyURL string, timeout time.Duration,
 		p.mu.Unlock()
 		return &http.Client{
 			Transport: &UserAgentTransport{
-				Original:  pc.transport,
+				Original:  withCircuitBreaker(pc.transport),
 				userAgent: userAgent,
 			},
 			Timeout: timeout,
@@ -213,7 +213,7 @@ func (p *ClientPool) GetUserAgentClient(proxyURL string, timeout time.Duration,
 		}
 		return &http.Client{
 			Transport: &UserAgentTransport{
-				Original:  pc.transport,
+				Original:  withCircuitBreaker(pc.transport),
 				userAgent: userAgent,
 			},
 			Timeout: timeout,
@@ -231,7 +231,7 @@ func (p *ClientPool) GetUserAgentClient(proxyURL string, timeout time.Duration,
 	}
 	client := &http.Client{
 		Transport: &UserAgentTransport{
-			Original:  transport,
+			Original:  withCircuitBreaker(transport),
 			userAgent: userAgent,
 		},
 		Timeout: timeout,
@@ -330,6 +330,11 @@ func (p *ClientPool) createTransport(proxyURL string, config TransportConfig) *h
 	return transport
 }
 
+// withCircuitBreaker wraps a pooled transport so requests to failing hosts fail fast
+func withCircuitBreaker(transport http.RoundTripper) http.RoundTripper {
+	return &CircuitBreakerTransport{Original: transport, Breaker: GetCircuitBreaker()}
+}
+
 func (p *ClientPool) buildKey(proxyURL string) string {
 	return proxyURL
 }
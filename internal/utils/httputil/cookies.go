@@ -0,0 +1,113 @@
+package httputil
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoCookies is returned when a cookies.txt file contains no cookie
+var ErrNoCookies = errors.New("no cookies found")
+
+// NetscapeCookie is a cookie read from a Netscape cookies.txt file, as exported
+// by browser extensions and curl
+type NetscapeCookie struct {
+	Domain            string
+	IncludeSubdomains bool
+	Path              string
+	Secure            bool
+	HTTPOnly          bool
+	Expires           time.Time // Zero for session cookies
+	Name              string
+	Value             string
+}
+
+// ParseNetscapeCookies parses a Netscape cookies.txt file. Expired cookies are skipped.
+func ParseNetscapeCookies(r io.Reader) ([]NetscapeCookie, error) {
+	var cookies []NetscapeCookie
+	now := time.Now()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := false
+		// curl marks HttpOnly cookies with a prefix on the domain
+		if strings.HasPrefix(line, "#HttpOnly_") {
+			line = strings.TrimPrefix(line, "#HttpOnly_")
+			httpOnly = true
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("line %d: expected 7 tab-separated fields, got %d", lineNum, len(fields))
+		}
+		expiresUnix, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiration %q", lineNum, fields[4])
+		}
+		cookie := NetscapeCookie{
+			Domain:            strings.ToLower(fields[0]),
+			IncludeSubdomains: strings.EqualFold(fields[1], "TRUE"),
+			Path:              fields[2],
+			Secure:            strings.EqualFold(fields[3], "TRUE"),
+			HTTPOnly:          httpOnly,
+			Name:              fields[5],
+			Value:             strings.Join(fields[6:], "\t"),
+		}
+		if expiresUnix > 0 {
+			cookie.Expires = time.Unix(expiresUnix, 0)
+			if cookie.Expires.Before(now) {
+				continue
+			}
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(cookies) == 0 {
+		return nil, ErrNoCookies
+	}
+	return cookies, nil
+}
+
+// NewCookieJar returns a cookie jar holding the cookies of a Netscape cookies.txt file
+func NewCookieJar(cookiesTxt string) (http.CookieJar, error) {
+	cookies, err := ParseNetscapeCookies(strings.NewReader(cookiesTxt))
+	if err != nil {
+		return nil, err
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cookies {
+		host := strings.TrimPrefix(c.Domain, ".")
+		cookie := &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HTTPOnly,
+			Expires:  c.Expires,
+		}
+		if c.IncludeSubdomains {
+			cookie.Domain = host
+		}
+		// The jar only sends secure cookies over HTTPS, whatever the URL they are set from
+		jar.SetCookies(&url.URL{Scheme: "https", Host: host, Path: "/"}, []*http.Cookie{cookie})
+	}
+	return jar, nil
+}
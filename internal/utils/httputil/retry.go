@@ -8,6 +8,7 @@ import (
 	"log"
 	"math/rand"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -215,3 +216,22 @@ func NewRetryableTransport(transport http.RoundTripper, config RetryConfig) *Ret
 		Config:    config,
 	}
 }
+
+// ParseRetryAfter parses a Retry-After header, either delay seconds or an HTTP date.
+// It returns 0 when the header is missing or invalid.
+func ParseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
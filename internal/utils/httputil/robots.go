@@ -0,0 +1,222 @@
+package httputil
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RobotsUserAgent is the product token matched against the User-agent lines of robots.txt
+const RobotsUserAgent = "MavenRSS"
+
+const (
+	robotsCacheTTL      = 24 * time.Hour
+	robotsErrorCacheTTL = time.Hour
+	robotsMaxSize       = 512 * 1024
+)
+
+// ErrDisallowedByRobots is returned when robots.txt disallows fetching a page
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+// RobotsChecker fetches and caches the robots.txt rules of hosts
+type RobotsChecker struct {
+	mu    sync.Mutex
+	hosts map[string]*robotsEntry
+}
+
+type robotsEntry struct {
+	rules   *robotsRules
+	expires time.Time
+}
+
+var (
+	robotsChecker     *RobotsChecker
+	robotsCheckerOnce sync.Once
+)
+
+// GetRobotsChecker returns the shared robots.txt checker
+func GetRobotsChecker() *RobotsChecker {
+	robotsCheckerOnce.Do(func() {
+		robotsChecker = &RobotsChecker{hosts: make(map[string]*robotsEntry)}
+	})
+	return robotsChecker
+}
+
+// Check returns ErrDisallowedByRobots when the robots.txt of the page's host disallows
+// fetching it. A missing robots.txt allows everything, and a robots.txt that can't be
+// fetched because of a server or network error disallows everything, as per RFC 9309.
+func (c *RobotsChecker) Check(ctx context.Context, client *http.Client, pageURL string) error {
+	u, err := url.Parse(pageURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil
+	}
+	origin := u.Scheme + "://" + strings.ToLower(u.Host)
+
+	c.mu.Lock()
+	entry := c.hosts[origin]
+	c.mu.Unlock()
+
+	if entry == nil || time.Now().After(entry.expires) {
+		rules, fetchErr := fetchRobots(ctx, client, origin)
+		if fetchErr != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		ttl := robotsCacheTTL
+		if fetchErr != nil {
+			ttl = robotsErrorCacheTTL
+		}
+		entry = &robotsEntry{rules: rules, expires: time.Now().Add(ttl)}
+		c.mu.Lock()
+		c.hosts[origin] = entry
+		c.mu.Unlock()
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if !entry.rules.allowed(path) {
+		return ErrDisallowedByRobots
+	}
+	return nil
+}
+
+// fetchRobots fetches the rules of an origin. On errors the returned rules disallow everything.
+func fetchRobots(ctx context.Context, client *http.Client, origin string) (*robotsRules, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return &robotsRules{disallowAll: true}, err
+	}
+	req.Header.Set("User-Agent", RobotsUserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return &robotsRules{disallowAll: true}, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return &robotsRules{disallowAll: true}, errors.New(resp.Status)
+	case resp.StatusCode >= 400:
+		// Unavailable robots.txt: no restrictions
+		return &robotsRules{}, nil
+	case resp.StatusCode != http.StatusOK:
+		return &robotsRules{}, nil
+	}
+	return parseRobots(io.LimitReader(resp.Body, robotsMaxSize), RobotsUserAgent), nil
+}
+
+// robotsRules are the Allow and Disallow rules of the group matching our user agent
+type robotsRules struct {
+	disallowAll bool
+	rules       []robotsRule
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// parseRobots parses robots.txt and keeps the rules of the groups matching the user agent,
+// or of the "*" groups when none matches
+func parseRobots(r io.Reader, userAgent string) *robotsRules {
+	userAgent = strings.ToLower(userAgent)
+	var matched, wildcard []robotsRule
+	var groupAgents []string
+	inRules := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A User-agent line after rules starts a new group
+			if inRules {
+				groupAgents = nil
+				inRules = false
+			}
+			groupAgents = append(groupAgents, strings.ToLower(value))
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				// An empty Disallow allows everything
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", pattern: value}
+			for _, agent := range groupAgents {
+				if agent == "*" {
+					wildcard = append(wildcard, rule)
+				} else if strings.Contains(userAgent, agent) {
+					matched = append(matched, rule)
+				}
+			}
+		}
+	}
+
+	if matched != nil {
+		return &robotsRules{rules: matched}
+	}
+	return &robotsRules{rules: wildcard}
+}
+
+// allowed applies the most specific (longest) matching rule, Allow winning ties
+func (r *robotsRules) allowed(path string) bool {
+	if r.disallowAll {
+		return path == "/robots.txt"
+	}
+	best := -1
+	allow := true
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > best || (n == best && rule.allow) {
+			best = n
+			allow = rule.allow
+		}
+	}
+	return allow
+}
+
+// robotsMatch matches a path against a robots.txt pattern supporting "*" and a trailing "$"
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for i, part := range parts[1:] {
+		if i == len(parts)-2 && anchored {
+			return strings.HasSuffix(path[pos:], part)
+		}
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	return !anchored || pos == len(path)
+}
@@ -0,0 +1,77 @@
+package textutil
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+const (
+	// fingerprintShingleSize is the number of consecutive words hashed together
+	fingerprintShingleSize = 3
+	// fingerprintMaxWords caps the content considered, so a full-text copy and a
+	// teaser of the same story still fingerprint alike
+	fingerprintMaxWords = 300
+	// fingerprintMinWords is the minimum text needed for a meaningful fingerprint
+	fingerprintMinWords = 8
+	// fingerprintTitleWeight makes the title count more than any single content shingle
+	fingerprintTitleWeight = 3
+)
+
+// NormalizeWords lowercases text and splits it into words of letters and digits,
+// dropping punctuation and markup leftovers.
+func NormalizeWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Fingerprint returns the SimHash of an article's normalised title and plain-text
+// content. Near-duplicates (the same story syndicated with small edits) produce
+// fingerprints that differ in only a few bits, see HammingDistance.
+// ok is false when there is too little text to fingerprint reliably.
+func Fingerprint(title, content string) (hash uint64, ok bool) {
+	titleWords := NormalizeWords(title)
+	contentWords := NormalizeWords(content)
+	if len(contentWords) > fingerprintMaxWords {
+		contentWords = contentWords[:fingerprintMaxWords]
+	}
+	if len(titleWords)+len(contentWords) < fingerprintMinWords {
+		return 0, false
+	}
+
+	var weights [64]int
+	add := func(words []string, weight int) {
+		n := fingerprintShingleSize
+		if len(words) < n {
+			n = len(words)
+		}
+		for i := 0; i+n <= len(words); i++ {
+			h := fnv.New64a()
+			h.Write([]byte(strings.Join(words[i:i+n], " ")))
+			sum := h.Sum64()
+			for bit := 0; bit < 64; bit++ {
+				if sum&(1<<uint(bit)) != 0 {
+					weights[bit] += weight
+				} else {
+					weights[bit] -= weight
+				}
+			}
+		}
+	}
+	add(titleWords, fingerprintTitleWeight)
+	add(contentWords, 1)
+
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			hash |= 1 << uint(bit)
+		}
+	}
+	return hash, true
+}
+
+// HammingDistance returns the number of differing bits between two fingerprints
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
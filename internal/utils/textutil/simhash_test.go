@@ -0,0 +1,30 @@
+package textutil
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	original, ok := Fingerprint("Central bank raises interest rates again",
+		"The central bank raised its benchmark interest rate by a quarter point on Wednesday, the third increase this year, citing persistent inflation in housing and services.")
+	if !ok {
+		t.Fatal("expected a fingerprint")
+	}
+	syndicated, _ := Fingerprint("Central Bank Raises Interest Rates Again",
+		StripHTML("<p>The central bank raised its benchmark interest rate by a quarter point on Wednesday &mdash; the third increase this year, citing persistent inflation in housing and services.</p>"))
+	edited, _ := Fingerprint("Central bank raises interest rates again",
+		"The central bank raised its key interest rate by a quarter point on Wednesday, the third increase this year, citing persistent inflation in housing and services. Reporting by Jane Doe.")
+	unrelated, _ := Fingerprint("New telescope captures distant galaxy",
+		"Astronomers released the first images from the new space telescope, showing a galaxy whose light left it more than thirteen billion years ago.")
+
+	if d := HammingDistance(original, syndicated); d != 0 {
+		t.Errorf("expected markup and case to be ignored, got distance %d", d)
+	}
+	if d := HammingDistance(original, edited); d > 6 {
+		t.Errorf("expected lightly edited copy within 6 bits, got %d", d)
+	}
+	if d := HammingDistance(original, unrelated); d <= 6 {
+		t.Errorf("expected unrelated story to differ, got distance %d", d)
+	}
+	if _, ok := Fingerprint("Short", ""); ok {
+		t.Error("expected no fingerprint for too little text")
+	}
+}
@@ -3,6 +3,7 @@
 package textutil
 
 import (
+	stdhtml "html"
 	"regexp"
 	"strings"
 
@@ -42,6 +43,12 @@ var (
 
 	// Matches <script> tags and their content
 	scriptTagRegex = regexp.MustCompile(`(?i)<script[^>]*>.*?</script>`)
+
+	// Matches non-visible elements (script, style, noscript) including multi-line content
+	invisibleElementRegex = regexp.MustCompile(`(?is)<(script|style|noscript)[^>]*>.*?</(script|style|noscript)>`)
+
+	// Matches any HTML tag or comment
+	anyTagRegex = regexp.MustCompile(`(?s)<!--.*?-->|<[^>]*>`)
 )
 
 // CleanHTML sanitizes HTML content by fixing common malformed patterns
@@ -221,3 +228,17 @@ func ConvertMarkdownToHTML(markdownText string) string {
 	htmlContent := RenderMarkdown(markdownText)
 	return SanitizeHTML(htmlContent)
 }
+
+// StripHTML converts HTML to plain text by removing tags and non-visible elements,
+// decoding entities and collapsing whitespace. It is intended for indexing and snippets,
+// not for display.
+func StripHTML(htmlContent string) string {
+	if htmlContent == "" || !strings.ContainsAny(htmlContent, "<&") {
+		return strings.Join(strings.Fields(htmlContent), " ")
+	}
+
+	text := invisibleElementRegex.ReplaceAllString(htmlContent, " ")
+	text = anyTagRegex.ReplaceAllString(text, " ")
+	text = stdhtml.UnescapeString(text)
+	return strings.Join(strings.Fields(text), " ")
+}
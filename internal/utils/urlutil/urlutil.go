@@ -22,6 +22,7 @@ func NormalizeFeedURL(feedURL string) string {
 	protocols := []string{
 		"http://", "https://", "rsshub://", "script://",
 		"email://", "feed://", "ftp://", "file://",
+		"webcal://", "webcals://",
 	}
 
 	for _, protocol := range protocols {
@@ -48,6 +49,16 @@ func NormalizeURLForComparison(rawURL string) string {
 	return parsed.Scheme + "://" + parsed.Host + parsed.Path
 }
 
+// StripCredentials removes the username and password embedded in a URL.
+func StripCredentials(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.User == nil {
+		return rawURL
+	}
+	parsed.User = nil
+	return parsed.String()
+}
+
 // URLsMatch checks if two URLs refer to the same article.
 func URLsMatch(url1, url2 string) bool {
 	if url1 == url2 {
@@ -383,6 +383,22 @@ func main() {
 	translator.SetProfileProvider(profileProvider)
 
 	fetcher := feed.NewFetcher(db)
+	// New mail of email feeds is announced by IMAP IDLE
+	fetcher.EnableEmailIdle()
+	// WebSub push subscriptions need a URL hubs can reach
+	if publicURL := os.Getenv("MRRSS_PUBLIC_URL"); publicURL != "" {
+		fetcher.EnableWebSub(publicURL)
+	}
+	// Newsletters sent to feed-<token>@MRRSS_MAIL_DOMAIN, received over SMTP and/or LMTP
+	if mailDomain := os.Getenv("MRRSS_MAIL_DOMAIN"); mailDomain != "" {
+		if err := fetcher.EnableMailReceiver(mailDomain, os.Getenv("MRRSS_SMTP_ADDR"), os.Getenv("MRRSS_LMTP_ADDR")); err != nil {
+			log.Printf("Failed to start mail receiver: %v", err)
+		}
+	}
+	// Newsletter archives (mbox/Maildir) can only be imported from MRRSS_MAIL_ARCHIVE_DIR
+	if archiveDir := os.Getenv("MRRSS_MAIL_ARCHIVE_DIR"); archiveDir != "" {
+		fetcher.EnableMailArchives(archiveDir)
+	}
 	h := handlers.NewHandler(db, fetcher, translator, profileProvider)
 
 	// API Routes
@@ -158,6 +158,10 @@ func main() {
 	translator.SetProfileProvider(profileProvider)
 
 	fetcher := feed.NewFetcher(db)
+	// New mail of email feeds is announced by IMAP IDLE
+	fetcher.EnableEmailIdle()
+	// Newsletter archives (mbox/Maildir) can be imported from any local path
+	fetcher.EnableMailArchives("")
 	h := handlers.NewHandler(db, fetcher, translator, profileProvider)
 
 	var quitRequested atomic.Bool